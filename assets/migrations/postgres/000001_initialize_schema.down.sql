@@ -1,7 +1,7 @@
-DROP TABLE IF EXISTS role_permission CASCADE;
-DROP TABLE IF EXISTS attribute_to_roles CASCADE;
-DROP TABLE IF EXISTS role CASCADE;
-DROP TABLE IF EXISTS proxy_oauth CASCADE;
-DROP TABLE IF EXISTS proxy_header CASCADE;
-DROP TABLE IF EXISTS proxy CASCADE;
-DROP SCHEMA IF EXISTS mcp_gateway CASCADE;
\ No newline at end of file
+DROP TABLE IF EXISTS {{.Schema}}.role_permission CASCADE;
+DROP TABLE IF EXISTS {{.Schema}}.attribute_to_roles CASCADE;
+DROP TABLE IF EXISTS {{.Schema}}.role CASCADE;
+DROP TABLE IF EXISTS {{.Schema}}.proxy_oauth CASCADE;
+DROP TABLE IF EXISTS {{.Schema}}.proxy_header CASCADE;
+DROP TABLE IF EXISTS {{.Schema}}.proxy CASCADE;
+DROP SCHEMA IF EXISTS {{.Schema}} CASCADE;
\ No newline at end of file
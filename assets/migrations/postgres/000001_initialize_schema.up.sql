@@ -1,8 +1,8 @@
--- Create the mcp_gateway schema if it doesn't exist
-CREATE SCHEMA IF NOT EXISTS mcp_gateway;
+-- Create the schema of the tables if it doesn't exist, {{.Schema}} being replaced by the configured schema
+CREATE SCHEMA IF NOT EXISTS {{.Schema}};
 
--- Set the search path to use the mcp_gateway schema
-SET search_path TO mcp_gateway, public;
+-- Set the search path to use the schema
+SET search_path TO {{.Schema}}, public;
 
 -- Create the proxy table
 CREATE TABLE proxy (
@@ -59,20 +59,20 @@ CREATE TABLE attribute_to_roles (
 
 -- accelerate GetAttributeToRoles
 CREATE INDEX IF NOT EXISTS idx_attr_roles_key_value
-    ON mcp_gateway.attribute_to_roles (attributekey, attributevalue);
+    ON {{.Schema}}.attribute_to_roles (attributekey, attributevalue);
 
 -- protect frequent role_permission ↔ proxy joins
 CREATE INDEX IF NOT EXISTS idx_role_permission_proxyname
-    ON mcp_gateway.role_permission (proxyname);
+    ON {{.Schema}}.role_permission (proxyname);
 
 -- allow fast search by header
 CREATE INDEX IF NOT EXISTS idx_proxy_header_key
-    ON mcp_gateway.proxy_header (proxyname, headerkey);
+    ON {{.Schema}}.proxy_header (proxyname, headerkey);
 
 -- allow fast search by role name
 CREATE INDEX IF NOT EXISTS idx_role_permission_rolename
-    ON mcp_gateway.role_permission (rolename);
+    ON {{.Schema}}.role_permission (rolename);
 
 -- allow fast search by object type and proxy name
 CREATE INDEX IF NOT EXISTS idx_role_permission_object
-    ON mcp_gateway.role_permission (objecttype, proxyname);
\ No newline at end of file
+    ON {{.Schema}}.role_permission (objecttype, proxyname);
\ No newline at end of file
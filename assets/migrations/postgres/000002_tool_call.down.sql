@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{.Schema}}.tool_call CASCADE;
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- Create the tool_call table, used by the usage statistics
+CREATE TABLE IF NOT EXISTS {{.Schema}}.tool_call (
+    Id BIGSERIAL PRIMARY KEY,
+    ProxyName TEXT NOT NULL,
+    ToolName TEXT NOT NULL,
+    Identity TEXT NOT NULL,
+    IsError BOOLEAN NOT NULL,
+    CalledAt TIMESTAMPTZ NOT NULL
+);
+
+-- allow fast search by time window
+CREATE INDEX IF NOT EXISTS idx_tool_call_calledat
+    ON {{.Schema}}.tool_call (calledat);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{.Schema}}.quota_usage CASCADE;
+DROP TABLE IF EXISTS {{.Schema}}.quota CASCADE;
@@ -0,0 +1,20 @@
+-- Create the quota table, the limits of tool calls per window
+CREATE TABLE IF NOT EXISTS {{.Schema}}.quota (
+    Name TEXT PRIMARY KEY,
+    SubjectType VARCHAR(255) NOT NULL,
+    Subject TEXT NOT NULL,
+    ProxyName TEXT NOT NULL,
+    ToolName TEXT NOT NULL,
+    QuotaWindow VARCHAR(255) NOT NULL,
+    QuotaLimit BIGINT NOT NULL
+);
+
+-- Create the quota_usage table, the calls of each subject in the current window of a quota
+CREATE TABLE IF NOT EXISTS {{.Schema}}.quota_usage (
+    QuotaName TEXT NOT NULL,
+    Subject TEXT NOT NULL,
+    WindowStart TIMESTAMPTZ NOT NULL,
+    Calls BIGINT NOT NULL,
+    PRIMARY KEY (QuotaName, Subject),
+    FOREIGN KEY (QuotaName) REFERENCES {{.Schema}}.quota(Name) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{.Schema}}.usage_daily CASCADE;
+ALTER TABLE {{.Schema}}.tool_call DROP COLUMN IF EXISTS DurationMs;
@@ -0,0 +1,21 @@
+-- Record the duration of the tool calls
+ALTER TABLE {{.Schema}}.tool_call ADD COLUMN IF NOT EXISTS DurationMs BIGINT NOT NULL DEFAULT 0;
+
+-- Create the usage_daily table, the daily rollups of the tool calls used for the chargeback
+CREATE TABLE IF NOT EXISTS {{.Schema}}.usage_daily (
+    Day DATE NOT NULL,
+    Identity TEXT NOT NULL,
+    ProxyName TEXT NOT NULL,
+    ToolName TEXT NOT NULL,
+    Calls BIGINT NOT NULL,
+    Errors BIGINT NOT NULL,
+    DurationMs BIGINT NOT NULL,
+    PRIMARY KEY (Day, Identity, ProxyName, ToolName)
+);
+
+-- Roll up the tool calls recorded before
+INSERT INTO {{.Schema}}.usage_daily (day, identity, proxyname, toolname, calls, errors, durationms)
+SELECT (calledat AT TIME ZONE 'UTC')::date, identity, proxyname, toolname, COUNT(*), COUNT(*) FILTER (WHERE iserror), 0
+FROM {{.Schema}}.tool_call
+GROUP BY 1, 2, 3, 4
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS {{.Schema}}.budget_usage CASCADE;
+DROP TABLE IF EXISTS {{.Schema}}.budget CASCADE;
+DROP TABLE IF EXISTS {{.Schema}}.tool_cost CASCADE;
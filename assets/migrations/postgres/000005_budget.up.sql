@@ -0,0 +1,24 @@
+-- Create the tool_cost table, the cost weight of the calls of a tool or of the tools of a proxy
+CREATE TABLE IF NOT EXISTS {{.Schema}}.tool_cost (
+    ProxyName TEXT NOT NULL,
+    ToolName TEXT NOT NULL,
+    Cost BIGINT NOT NULL,
+    PRIMARY KEY (ProxyName, ToolName)
+);
+
+-- Create the budget table, the cost a role can consume per window
+CREATE TABLE IF NOT EXISTS {{.Schema}}.budget (
+    Name TEXT PRIMARY KEY,
+    RoleName TEXT NOT NULL,
+    BudgetWindow VARCHAR(255) NOT NULL,
+    BudgetLimit BIGINT NOT NULL,
+    Action VARCHAR(255) NOT NULL
+);
+
+-- Create the budget_usage table, the cost consumed from a budget in its current window
+CREATE TABLE IF NOT EXISTS {{.Schema}}.budget_usage (
+    BudgetName TEXT PRIMARY KEY,
+    WindowStart TIMESTAMPTZ NOT NULL,
+    Consumed BIGINT NOT NULL,
+    FOREIGN KEY (BudgetName) REFERENCES {{.Schema}}.budget(Name) ON DELETE CASCADE
+);
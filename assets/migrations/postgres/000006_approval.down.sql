@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{.Schema}}.approval CASCADE;
+DROP TABLE IF EXISTS {{.Schema}}.tool_policy CASCADE;
@@ -0,0 +1,26 @@
+-- Create the tool_policy table, the tools whose calls must be approved
+CREATE TABLE IF NOT EXISTS {{.Schema}}.tool_policy (
+    ProxyName TEXT NOT NULL,
+    ToolName TEXT NOT NULL,
+    RequiresApproval BOOLEAN NOT NULL,
+    PRIMARY KEY (ProxyName, ToolName)
+);
+
+-- Create the approval table, the parked tool calls and their decisions
+CREATE TABLE IF NOT EXISTS {{.Schema}}.approval (
+    Id TEXT PRIMARY KEY,
+    ProxyName TEXT NOT NULL,
+    ToolName TEXT NOT NULL,
+    Identity TEXT NOT NULL,
+    Arguments TEXT NOT NULL,
+    Status VARCHAR(255) NOT NULL,
+    RequestedAt TIMESTAMPTZ NOT NULL,
+    ExpiresAt TIMESTAMPTZ NOT NULL,
+    DecidedAt TIMESTAMPTZ,
+    DecidedBy TEXT NOT NULL,
+    Reason TEXT NOT NULL
+);
+
+-- allow fast listing of the pending approvals
+CREATE INDEX IF NOT EXISTS idx_approval_status_requestedat
+    ON {{.Schema}}.approval (status, requestedat);
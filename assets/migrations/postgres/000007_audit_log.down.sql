@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{.Schema}}.audit_log CASCADE;
@@ -0,0 +1,9 @@
+-- Create the audit_log table, the hash chained audit entries. The data is kept as TEXT so it hashes as recorded.
+CREATE TABLE IF NOT EXISTS {{.Schema}}.audit_log (
+    Seq BIGINT PRIMARY KEY,
+    Type VARCHAR(255) NOT NULL,
+    Data TEXT NOT NULL,
+    RecordedAt TIMESTAMPTZ NOT NULL,
+    PrevHash VARCHAR(64) NOT NULL,
+    Hash VARCHAR(64) NOT NULL
+);
@@ -0,0 +1,6 @@
+-- Create the proxy_hmac table, the shared secrets signing the requests sent to the proxies with the hmac auth type
+CREATE TABLE IF NOT EXISTS {{.Schema}}.proxy_hmac (
+    ProxyName TEXT PRIMARY KEY,
+    Secret TEXT NOT NULL,
+    FOREIGN KEY (ProxyName) REFERENCES {{.Schema}}.proxy(Name) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{.Schema}}.classification CASCADE;
+ALTER TABLE {{.Schema}}.role DROP COLUMN IF EXISTS Clearance;
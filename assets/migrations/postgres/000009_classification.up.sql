@@ -0,0 +1,10 @@
+-- Add the clearance of the roles, the highest classification level of the tools they may call
+ALTER TABLE {{.Schema}}.role ADD COLUMN IF NOT EXISTS Clearance VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Create the classification table, the classification levels of the tools
+CREATE TABLE IF NOT EXISTS {{.Schema}}.classification (
+    ProxyName TEXT NOT NULL,
+    ToolName TEXT NOT NULL,
+    Level VARCHAR(255) NOT NULL,
+    PRIMARY KEY (ProxyName, ToolName)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{.Schema}}.proxy_tools CASCADE;
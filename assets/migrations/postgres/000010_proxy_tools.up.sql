@@ -0,0 +1,8 @@
+-- Create the proxy_tools table, the tools listed by the leader of the proxy sync loop and shared with the other replicas
+CREATE TABLE IF NOT EXISTS {{.Schema}}.proxy_tools (
+    ProxyName TEXT PRIMARY KEY,
+    Tools TEXT NOT NULL,
+    ResourceTemplates TEXT NOT NULL DEFAULT '',
+    SyncedAt TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (ProxyName) REFERENCES {{.Schema}}.proxy(Name) ON DELETE CASCADE
+);
@@ -0,0 +1,12 @@
+-- Create the proxy_label table, the free-form labels of the proxies
+CREATE TABLE IF NOT EXISTS {{.Schema}}.proxy_label (
+    ProxyName TEXT NOT NULL,
+    LabelKey TEXT NOT NULL,
+    LabelValue TEXT NOT NULL,
+    PRIMARY KEY (ProxyName, LabelKey),
+    FOREIGN KEY (ProxyName) REFERENCES {{.Schema}}.proxy(Name) ON DELETE CASCADE
+);
+
+-- allow fast search of the proxies by label
+CREATE INDEX IF NOT EXISTS idx_proxy_label_key_value
+    ON {{.Schema}}.proxy_label (labelkey, labelvalue);
@@ -0,0 +1,3 @@
+ALTER TABLE {{.Schema}}.proxy DROP COLUMN IF EXISTS Description;
+ALTER TABLE {{.Schema}}.proxy DROP COLUMN IF EXISTS Owner;
+ALTER TABLE {{.Schema}}.proxy DROP COLUMN IF EXISTS DocsURL;
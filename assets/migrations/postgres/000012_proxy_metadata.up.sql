@@ -0,0 +1,4 @@
+-- Add the description, owner and documentation link of the proxies
+ALTER TABLE {{.Schema}}.proxy ADD COLUMN IF NOT EXISTS Description TEXT NOT NULL DEFAULT '';
+ALTER TABLE {{.Schema}}.proxy ADD COLUMN IF NOT EXISTS Owner TEXT NOT NULL DEFAULT '';
+ALTER TABLE {{.Schema}}.proxy ADD COLUMN IF NOT EXISTS DocsURL TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{.Schema}}.tool_call_trace CASCADE;
@@ -0,0 +1,19 @@
+-- Create the tool_call_trace table, the detailed traces of the tool calls kept for the postmortems
+CREATE TABLE IF NOT EXISTS {{.Schema}}.tool_call_trace (
+    Id TEXT PRIMARY KEY,
+    ProxyName TEXT NOT NULL,
+    ToolName TEXT NOT NULL,
+    Identity TEXT NOT NULL,
+    IsError BOOLEAN NOT NULL,
+    CalledAt TIMESTAMPTZ NOT NULL,
+    DurationNs BIGINT NOT NULL,
+    AuthNs BIGINT NOT NULL,
+    StorageNs BIGINT NOT NULL,
+    UpstreamNs BIGINT NOT NULL,
+    Arguments TEXT NOT NULL,
+    ResultSize BIGINT NOT NULL
+);
+
+-- allow fast listing and purge by time
+CREATE INDEX IF NOT EXISTS idx_tool_call_trace_calledat
+    ON {{.Schema}}.tool_call_trace (calledat);
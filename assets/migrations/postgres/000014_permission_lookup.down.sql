@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_role_permission_rolename
+    ON {{.Schema}}.role_permission (rolename);
+
+DROP INDEX IF EXISTS {{.Schema}}.idx_role_permission_lookup;
@@ -0,0 +1,6 @@
+-- cover the permissions of the roles fetched at once, in the order of the lookups
+CREATE INDEX IF NOT EXISTS idx_role_permission_lookup
+    ON {{.Schema}}.role_permission (rolename, objecttype, proxyname, objectname);
+
+-- superseded by idx_role_permission_lookup
+DROP INDEX IF EXISTS {{.Schema}}.idx_role_permission_rolename;
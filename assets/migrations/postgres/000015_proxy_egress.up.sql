@@ -0,0 +1,2 @@
+-- Add the egress proxy through which the gateway connects to the upstream server
+ALTER TABLE {{.Schema}}.proxy ADD COLUMN IF NOT EXISTS EgressProxy TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE {{.Schema}}.proxy DROP COLUMN IF EXISTS MaxResponseBytes;
+ALTER TABLE {{.Schema}}.proxy DROP COLUMN IF EXISTS ResponseSizePolicy;
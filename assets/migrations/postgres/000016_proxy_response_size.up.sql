@@ -0,0 +1,3 @@
+-- Add the maximum size of the tool results of the proxies, and the policy of the larger ones
+ALTER TABLE {{.Schema}}.proxy ADD COLUMN IF NOT EXISTS MaxResponseBytes BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE {{.Schema}}.proxy ADD COLUMN IF NOT EXISTS ResponseSizePolicy TEXT NOT NULL DEFAULT '';
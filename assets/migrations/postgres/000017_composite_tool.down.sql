@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{.Schema}}.composite_tool CASCADE;
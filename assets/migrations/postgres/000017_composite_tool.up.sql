@@ -0,0 +1,7 @@
+-- Create the composite_tool table, the tools of the gateway calling a sequence of upstream tools
+CREATE TABLE IF NOT EXISTS {{.Schema}}.composite_tool (
+    Name TEXT PRIMARY KEY,
+    Description TEXT NOT NULL DEFAULT '',
+    InputSchema TEXT NOT NULL DEFAULT '',
+    Steps TEXT NOT NULL
+);
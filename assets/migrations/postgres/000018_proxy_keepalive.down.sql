@@ -0,0 +1 @@
+ALTER TABLE {{.Schema}}.proxy DROP COLUMN IF EXISTS KeepaliveInterval;
@@ -0,0 +1,2 @@
+-- Add the keepalive interval of the upstream sessions of the proxies, in seconds
+ALTER TABLE {{.Schema}}.proxy ADD COLUMN IF NOT EXISTS KeepaliveInterval BIGINT NOT NULL DEFAULT 0;
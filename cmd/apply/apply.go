@@ -0,0 +1,101 @@
+// Package apply provides a command to reconcile the MCP Gateway backend with a declarative manifest.
+package apply
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/matthisholleville/mcp-gateway/cmd/serve"
+	"github.com/matthisholleville/mcp-gateway/cmd/util"
+	"github.com/matthisholleville/mcp-gateway/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+const (
+	fileFlag   = "file"
+	pruneFlag  = "prune"
+	dryRunFlag = "dry-run"
+)
+
+// NewApplyCommand creates a new apply command.
+func NewApplyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Reconcile the backend with a declarative manifest",
+		Long: "Reconcile the proxies, roles and attribute-to-roles mappings of the backend with a declarative manifest. " +
+			"The objects missing from the manifest are deleted only with --prune.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         run,
+	}
+	flags := cmd.Flags()
+
+	flags.StringSliceP(fileFlag, "f", nil, "The manifest files to apply, in YAML or JSON")
+	_ = cmd.MarkFlagRequired(fileFlag)
+
+	flags.Bool(pruneFlag, false, "Delete the objects of the backend missing from the manifest")
+
+	flags.Bool(dryRunFlag, false, "Print the changes without applying them")
+
+	util.AddBackendFlags(flags)
+	cmd.PreRun = func(_ *cobra.Command, _ []string) {
+		util.BindBackendFlags(flags)
+	}
+
+	return cmd
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	files, _ := cmd.Flags().GetStringSlice(fileFlag)
+	prune, _ := cmd.Flags().GetBool(pruneFlag)
+	dryRun, _ := cmd.Flags().GetBool(dryRunFlag)
+
+	m, err := manifest.Load(files...)
+	if err != nil {
+		return err
+	}
+
+	config, err := serve.ReadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := util.NewBackendStorage(cmd.Context(), cmd.Name(), config)
+	if err != nil {
+		return err
+	}
+
+	changes, err := manifest.Plan(cmd.Context(), store, m, prune)
+	if err != nil {
+		return err
+	}
+	PrintChanges(cmd.OutOrStdout(), changes, dryRun)
+	if dryRun || len(changes) == 0 {
+		return nil
+	}
+	return manifest.Apply(cmd.Context(), store, changes)
+}
+
+// PrintChanges prints the diff of the changes and its summary.
+func PrintChanges(w io.Writer, changes []manifest.Change, dryRun bool) {
+	symbols := map[manifest.Action]string{
+		manifest.ActionCreate: "+",
+		manifest.ActionUpdate: "~",
+		manifest.ActionDelete: "-",
+	}
+	counts := make(map[manifest.Action]int)
+	for _, change := range changes {
+		counts[change.Action]++
+		fmt.Fprintf(w, "%s %s %s\n", symbols[change.Action], change.Kind, change.Name)
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes: the backend matches the manifest")
+		return
+	}
+	summary := fmt.Sprintf("%d to create, %d to update, %d to delete",
+		counts[manifest.ActionCreate], counts[manifest.ActionUpdate], counts[manifest.ActionDelete])
+	if dryRun {
+		summary += " (dry run, nothing applied)"
+	}
+	fmt.Fprintln(w, summary)
+}
@@ -0,0 +1,77 @@
+// Package audit provides the commands to check the tamper-evident audit log.
+package audit
+
+import (
+	"fmt"
+
+	"github.com/matthisholleville/mcp-gateway/cmd/serve"
+	"github.com/matthisholleville/mcp-gateway/cmd/util"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+const (
+	batchSizeFlag = "batch-size"
+
+	defaultBatchSize = 1000
+)
+
+// NewAuditCommand creates a new audit command.
+func NewAuditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Check the tamper-evident audit log",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newVerifyCommand())
+	return cmd
+}
+
+func newVerifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify that the audit log was not altered",
+		Long: "Read the audit log from the backend, without going through the gateway, and check that each entry " +
+			"chains to the previous one and matches its hash. Compare the last hash printed with one recorded " +
+			"earlier to also prove that no entry was removed from the end of the log.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runVerify,
+	}
+	flags := cmd.Flags()
+
+	flags.Int(batchSizeFlag, defaultBatchSize, "The number of entries read at once")
+
+	util.AddBackendFlags(flags)
+	cmd.PreRun = func(_ *cobra.Command, _ []string) {
+		util.BindBackendFlags(flags)
+	}
+
+	return cmd
+}
+
+func runVerify(cmd *cobra.Command, _ []string) error {
+	batchSize, _ := cmd.Flags().GetInt(batchSizeFlag)
+	if batchSize <= 0 {
+		return fmt.Errorf("--%s must be positive", batchSizeFlag)
+	}
+
+	config, err := serve.ReadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := util.NewBackendStorage(cmd.Context(), cmd.CommandPath(), config)
+	if err != nil {
+		return err
+	}
+	verification, err := storage.VerifyAuditLog(cmd.Context(), store, batchSize)
+	if err != nil {
+		return err
+	}
+	if !verification.Valid {
+		return fmt.Errorf("the audit log was altered at the entry %d: %s (%d entries verified before it)",
+			verification.BrokenAt, verification.Reason, verification.Entries)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%d entries verified, last hash %s\n", verification.Entries, verification.LastHash)
+	return nil
+}
@@ -0,0 +1,117 @@
+// Package config provides the commands to check and inspect the MCP Gateway configuration.
+package config
+
+import (
+	"fmt"
+
+	"github.com/matthisholleville/mcp-gateway/cmd/serve"
+	"github.com/matthisholleville/mcp-gateway/cmd/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	fileFlag  = "file"
+	localFlag = "local"
+)
+
+// NewConfigCommand creates a new config command.
+func NewConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Check and inspect the MCP Gateway configuration",
+		Long:  "Check and inspect the MCP Gateway configuration.",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newValidateCommand())
+	cmd.AddCommand(newShowCommand())
+	return cmd
+}
+
+func newValidateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the configuration",
+		Long: "Validate the configuration the server would run with, read from the config file, the environment " +
+			"and the flags. All the errors are reported, and the command fails if any is found.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runValidate,
+	}
+	// The same flags as the serve command, so a configuration can be validated as the server would read it.
+	serve.AddFlags(cmd)
+	cmd.Flags().StringP(fileFlag, "f", "", "The config file to validate, instead of the config file found in the default paths")
+	return cmd
+}
+
+func runValidate(cmd *cobra.Command, _ []string) error {
+	file, _ := cmd.Flags().GetString(fileFlag)
+	if file != "" {
+		viper.SetConfigFile(file)
+	}
+
+	config, err := serve.ReadConfig()
+	if err != nil {
+		return err
+	}
+
+	err = config.Verify()
+	if err == nil {
+		fmt.Fprintln(cmd.OutOrStdout(), "The configuration is valid")
+		return nil
+	}
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	fmt.Fprintln(cmd.ErrOrStderr(), "The configuration is invalid:")
+	for _, err := range errs {
+		fmt.Fprintf(cmd.ErrOrStderr(), "  - %s\n", err)
+	}
+	return fmt.Errorf("%d configuration error(s) found", len(errs))
+}
+
+func newShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective configuration",
+		Long: "Show the configuration a running MCP Gateway uses, resolved from its flags, environment, config file " +
+			"and defaults, with the secrets redacted. With --local, the configuration is resolved as the server " +
+			"would read it on this host instead.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runShow,
+	}
+	// The same flags as the serve command, so a local configuration is resolved as the server would read it.
+	serve.AddFlags(cmd)
+	bindServeFlags := cmd.PreRun
+	util.AddAdminFlags(cmd.Flags())
+	cmd.PreRun = func(cmd *cobra.Command, args []string) {
+		bindServeFlags(cmd, args)
+		util.BindAdminFlags(cmd.Flags())
+	}
+	cmd.Flags().Bool(localFlag, false, "Resolve the configuration locally instead of reading it from the MCP Gateway")
+	cmd.Flags().StringP(fileFlag, "f", "", "The config file to resolve with --local, instead of the config file found in the default paths")
+	return cmd
+}
+
+func runShow(cmd *cobra.Command, _ []string) error {
+	local, _ := cmd.Flags().GetBool(localFlag)
+	if !local {
+		config, err := util.NewAdminClient().GetConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+		return util.Print(cmd.OutOrStdout(), config)
+	}
+
+	if file, _ := cmd.Flags().GetString(fileFlag); file != "" {
+		viper.SetConfigFile(file)
+	}
+	config, err := serve.ReadConfig()
+	if err != nil {
+		return err
+	}
+	return util.Print(cmd.OutOrStdout(), config.Redacted())
+}
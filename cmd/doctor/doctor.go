@@ -0,0 +1,59 @@
+// Package doctor provides a command to diagnose the MCP Gateway connectivity.
+package doctor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/cmd/serve"
+	"github.com/matthisholleville/mcp-gateway/internal/doctor"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	timeoutFlag = "timeout"
+	dirFlag     = "dir"
+
+	defaultTimeout = 10 * time.Second
+	defaultDir     = "assets/migrations/postgres"
+)
+
+// NewDoctorCommand creates a new doctor command.
+func NewDoctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the MCP Gateway connectivity",
+		Long: "Check the storage connectivity, the migration version, the auth provider metadata and JWKS, " +
+			"and dial every configured proxy, with the configuration the server would run with.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         run,
+	}
+	// The same flags as the serve command, so the diagnostics use the configuration of the server.
+	serve.AddFlags(cmd)
+	cmd.Flags().Duration(timeoutFlag, defaultTimeout, "The timeout of each check")
+	cmd.Flags().String(dirFlag, defaultDir, "The directory of the migrations the database is compared with")
+	return cmd
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	timeout, _ := cmd.Flags().GetDuration(timeoutFlag)
+	dir, _ := cmd.Flags().GetString(dirFlag)
+
+	config, err := serve.ReadConfig()
+	if err != nil {
+		return err
+	}
+	// The report is the output: the logs of the checks are only shown on errors.
+	log := logger.MustNewLogger(config.Log.Format, "error", config.Log.TimestampFormat)
+
+	results := doctor.New(config, log, doctor.Options{MigrationDir: dir, Timeout: timeout}).Run(cmd.Context())
+	for _, result := range results {
+		fmt.Fprintf(cmd.OutOrStdout(), "[%s] %s: %s\n", result.Status, result.Check, result.Detail)
+	}
+	if doctor.Failed(results) {
+		return fmt.Errorf("some checks failed")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Package genkey provides a command to generate a backend encryption key.
+package genkey
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/matthisholleville/mcp-gateway/pkg/aescipher"
+	"github.com/spf13/cobra"
+)
+
+const (
+	sizeFlag       = "size"
+	outputFileFlag = "output-file"
+	forceFlag      = "force"
+
+	defaultKeySize = 32
+	// keyFileMode makes the key file readable by its owner only.
+	keyFileMode = 0o600
+)
+
+// NewGenKeyCommand creates a new gen-key command.
+func NewGenKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen-key",
+		Short: "Generate a backend encryption key",
+		Long: "Generate a random hex-encoded AES key for backendConfig.encryptionKey (--backend-encryption-key). " +
+			"The key is printed, or written to a file readable by its owner only.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         run,
+	}
+	flags := cmd.Flags()
+
+	flags.Int(sizeFlag, defaultKeySize, "The key size in bytes: 16 (AES-128), 24 (AES-192) or 32 (AES-256)")
+
+	flags.String(outputFileFlag, "", "The file to write the key to, instead of printing it")
+
+	flags.Bool(forceFlag, false, "Overwrite the output file if it exists")
+
+	return cmd
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	size, _ := cmd.Flags().GetInt(sizeFlag)
+	outputFile, _ := cmd.Flags().GetString(outputFileFlag)
+	force, _ := cmd.Flags().GetBool(forceFlag)
+
+	key, err := aescipher.GenerateKey(size)
+	if err != nil {
+		return err
+	}
+
+	if outputFile == "" {
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), key)
+		return err
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(outputFile, flag, keyFileMode) //nolint:gosec // the path is given by the user
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists, use --%s to overwrite it", outputFile, forceFlag)
+	}
+	if err != nil {
+		return err
+	}
+	// The permissions of an existing file are not changed by OpenFile.
+	if err := file.Chmod(keyFileMode); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if _, err := fmt.Fprintln(file, key); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Wrote a %d-byte key to %s\n", size, outputFile)
+	return nil
+}
@@ -0,0 +1,69 @@
+// Package hashkey provides a command to hash an admin API key.
+package hashkey
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/matthisholleville/mcp-gateway/pkg/aescipher"
+	"github.com/matthisholleville/mcp-gateway/pkg/apikey"
+	"github.com/spf13/cobra"
+)
+
+const (
+	generateFlag = "generate"
+
+	// generatedKeySize is the size in bytes of the generated keys.
+	generatedKeySize = 32
+)
+
+// NewHashKeyCommand creates a new hash-api-key command.
+func NewHashKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hash-api-key",
+		Short: "Hash an admin API key",
+		Long: "Print the Argon2id hash of the admin API key read from the standard input, for " +
+			"http.adminApiKeyHash (--http-admin-api-key-hash). With --generate, a random key is generated " +
+			"and printed with its hash.",
+		Example: "  echo -n \"$API_KEY\" | mcp-gateway hash-api-key\n" +
+			"  mcp-gateway hash-api-key --generate",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         run,
+	}
+
+	cmd.Flags().Bool(generateFlag, false, "Generate a random key instead of reading it from the standard input")
+
+	return cmd
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	generate, _ := cmd.Flags().GetBool(generateFlag)
+
+	var key string
+	if generate {
+		var err error
+		if key, err = aescipher.GenerateKey(generatedKeySize); err != nil {
+			return err
+		}
+	} else {
+		line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && line == "" {
+			return errors.New("no key read from the standard input")
+		}
+		key = strings.TrimRight(line, "\r\n")
+	}
+
+	hash, err := apikey.Hash(key)
+	if err != nil {
+		return err
+	}
+	if generate {
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "API key: %s\nHash:    %s\n", key, hash)
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), hash)
+	return err
+}
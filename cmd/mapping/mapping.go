@@ -0,0 +1,156 @@
+// Package mapping provides the commands to manage the MCP Gateway attribute-to-roles mappings.
+package mapping
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/matthisholleville/mcp-gateway/cmd/util"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+const (
+	roleFlag     = "role"
+	fromFileFlag = "from-file"
+)
+
+// NewMappingCommand creates a new mapping command.
+func NewMappingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mapping",
+		Short: "Manage the MCP Gateway attribute-to-roles mappings",
+		Long:  "Manage the MCP Gateway attribute-to-roles mappings through the admin API.",
+		Args:  cobra.NoArgs,
+	}
+	util.AddAdminFlags(cmd.PersistentFlags())
+	cmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		util.BindAdminFlags(cmd.PersistentFlags())
+	}
+
+	cmd.AddCommand(newListCommand())
+	cmd.AddCommand(newSetCommand())
+	cmd.AddCommand(newDeleteCommand())
+	cmd.AddCommand(newPlanCommand())
+	return cmd
+}
+
+func newListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the attribute-to-roles mappings",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			mappings, err := util.NewAdminClient().ListAttributeToRoles(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return util.Print(cmd.OutOrStdout(), mappings)
+		},
+	}
+}
+
+func newSetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set [ATTRIBUTE_KEY ATTRIBUTE_VALUE --role ROLE...] [--from-file FILE...]",
+		Short: "Create or update attribute-to-roles mappings",
+		Long: "Map an attribute to roles, or create or update the mappings of YAML or JSON files. " +
+			"A file holds a single mapping or a list of mappings, with the format of the admin API.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			files, _ := cmd.Flags().GetStringSlice(fromFileFlag)
+			if len(files) > 0 {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args) //nolint:mnd // the attribute key and value
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mappings, err := mappingsFromFlags(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			client := util.NewAdminClient()
+			for _, mapping := range mappings {
+				if mapping.AttributeKey == "" || mapping.AttributeValue == "" {
+					return errors.New("a mapping has no attribute key or value")
+				}
+				if err := client.UpsertAttributeToRoles(cmd.Context(), mapping); err != nil {
+					return fmt.Errorf("failed to set mapping %s=%s: %w", mapping.AttributeKey, mapping.AttributeValue, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "mapping %s=%s set\n", mapping.AttributeKey, mapping.AttributeValue)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSlice(roleFlag, nil, "The roles granted to the attribute")
+	cmd.Flags().StringSlice(fromFileFlag, nil, "The YAML or JSON files holding the mappings")
+	cmd.MarkFlagsMutuallyExclusive(roleFlag, fromFileFlag)
+	return cmd
+}
+
+// mappingsFromFlags returns the mappings of the files, or the mapping of the arguments.
+func mappingsFromFlags(cmd *cobra.Command, args []string) ([]storage.AttributeToRolesConfig, error) {
+	files, err := cmd.Flags().GetStringSlice(fromFileFlag)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		roles, err := cmd.Flags().GetStringSlice(roleFlag)
+		if err != nil {
+			return nil, err
+		}
+		if len(roles) == 0 {
+			return nil, errors.New("at least one --role is required")
+		}
+		return []storage.AttributeToRolesConfig{{AttributeKey: args[0], AttributeValue: args[1], Roles: roles}}, nil
+	}
+
+	var mappings []storage.AttributeToRolesConfig
+	for _, file := range files {
+		fileMappings, err := util.ReadObjects[storage.AttributeToRolesConfig](file)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, fileMappings...)
+	}
+	return mappings, nil
+}
+
+func newDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete ATTRIBUTE_KEY ATTRIBUTE_VALUE",
+		Short: "Delete an attribute-to-roles mapping",
+		Args:  cobra.ExactArgs(2), //nolint:mnd // the attribute key and value
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := util.NewAdminClient().DeleteAttributeToRoles(cmd.Context(), args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "mapping %s=%s deleted\n", args[0], args[1])
+			return nil
+		},
+	}
+}
+
+func newPlanCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan --from-file FILE...",
+		Short: "Preview the replacement of all the attribute-to-roles mappings",
+		Long: "Print the roles that replacing all the attribute-to-roles mappings with the mappings of YAML or JSON " +
+			"files would add and remove, without applying anything. The mappings missing from the files would be deleted.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mappings, err := mappingsFromFlags(cmd, args)
+			if err != nil {
+				return err
+			}
+			changes, err := util.NewAdminClient().PlanAttributeToRoles(cmd.Context(), mappings)
+			if err != nil {
+				return err
+			}
+			return util.Print(cmd.OutOrStdout(), changes)
+		},
+	}
+	cmd.Flags().StringSlice(fromFileFlag, nil, "The YAML or JSON files holding the desired mappings")
+	_ = cmd.MarkFlagRequired(fromFileFlag)
+	return cmd
+}
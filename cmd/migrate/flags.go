@@ -21,6 +21,9 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag(backendPasswordFlag, flags.Lookup(backendPasswordFlag))
 		util.MustBindEnv(backendPasswordFlag, "MCP_GATEWAY_BACKEND_PASSWORD")
 
+		util.MustBindPFlag(backendSchemaFlag, flags.Lookup(backendSchemaFlag))
+		util.MustBindEnv(backendSchemaFlag, "MCP_GATEWAY_BACKEND_SCHEMA")
+
 		util.MustBindPFlag(verboseMigrationFlag, flags.Lookup(verboseMigrationFlag))
 		util.MustBindEnv(verboseMigrationFlag, "MCP_GATEWAY_VERBOSE")
 
@@ -42,6 +45,13 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag(dropFlag, flags.Lookup(dropFlag))
 		util.MustBindEnv(dropFlag, "MCP_GATEWAY_DROP")
 
+		util.MustBindPFlag(downFlag, flags.Lookup(downFlag))
+
+		util.MustBindPFlag(stepsFlag, flags.Lookup(stepsFlag))
+		util.MustBindEnv(stepsFlag, "MCP_GATEWAY_MIGRATION_STEPS")
+
+		util.MustBindPFlag(yesFlag, flags.Lookup(yesFlag))
+
 		util.MustBindPFlag(dirFlag, flags.Lookup(dirFlag))
 		util.MustBindEnv(dirFlag, "MCP_GATEWAY_MIGRATION_DIR")
 	}
@@ -2,6 +2,11 @@
 package migrate
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/matthisholleville/mcp-gateway/internal/cfg"
@@ -16,6 +21,7 @@ const (
 	backendURIFlag       = "backend-uri"
 	backendUsernameFlag  = "backend-username"
 	backendPasswordFlag  = "backend-password"
+	backendSchemaFlag    = "backend-schema"
 	logFormatFlag        = "log-format"
 	logLevelFlag         = "log-level"
 	logTimestampFlag     = "log-timestamp-format"
@@ -23,6 +29,9 @@ const (
 	verboseMigrationFlag = "verbose"
 	timeoutFlag          = "timeout"
 	dropFlag             = "drop"
+	downFlag             = "down"
+	stepsFlag            = "steps"
+	yesFlag              = "yes"
 	dirFlag              = "dir"
 
 	defaultTimeout = 30 * time.Second
@@ -49,6 +58,8 @@ func NewMigrateCommand() *cobra.Command {
 
 	flags.String(backendPasswordFlag, defaultConfig.BackendConfig.Password, "The password to use for the auth backend")
 
+	flags.String(backendSchemaFlag, defaultConfig.BackendConfig.Schema, "The Postgres schema of the gateway tables, created by the migrations")
+
 	flags.Bool(verboseMigrationFlag, false, "enable verbose migration logs (default false)")
 
 	flags.String(logFormatFlag, defaultConfig.Log.Format, "The format to use for logging")
@@ -63,6 +74,12 @@ func NewMigrateCommand() *cobra.Command {
 
 	flags.Bool(dropFlag, false, "Drop all migrations")
 
+	flags.Bool(downFlag, false, "Roll back all migrations, or --steps of them, keeping the schema. Asks for a confirmation unless --yes is set")
+
+	flags.Int(stepsFlag, 0, "The number of migrations to apply, or to roll back with --down (default all)")
+
+	flags.Bool(yesFlag, false, "Roll back without asking for a confirmation")
+
 	flags.String(dirFlag, "", "The directory to use for the migrations")
 
 	cmd.PreRun = bindRunFlagsFunc(flags)
@@ -70,11 +87,12 @@ func NewMigrateCommand() *cobra.Command {
 	return cmd
 }
 
-func runMigration(_ *cobra.Command, _ []string) error {
+func runMigration(cmd *cobra.Command, _ []string) error {
 	engine := viper.GetString(backendEngineFlag)
 	uri := viper.GetString(backendURIFlag)
 	username := viper.GetString(backendUsernameFlag)
 	password := viper.GetString(backendPasswordFlag)
+	schema := viper.GetString(backendSchemaFlag)
 	verbose := viper.GetBool(verboseMigrationFlag)
 	logFormat := viper.GetString(logFormatFlag)
 	logLevel := viper.GetString(logLevelFlag)
@@ -82,6 +100,8 @@ func runMigration(_ *cobra.Command, _ []string) error {
 	targetVersion := viper.GetInt(targetVersionFlag)
 	timeout := viper.GetDuration(timeoutFlag)
 	drop := viper.GetBool(dropFlag)
+	down := viper.GetBool(downFlag)
+	steps := viper.GetInt(stepsFlag)
 
 	log := logger.MustNewLogger(logFormat, logLevel, logTimestamp)
 
@@ -90,12 +110,42 @@ func runMigration(_ *cobra.Command, _ []string) error {
 		URI:      uri,
 		Username: username,
 		Password: password,
+		Schema:   schema,
 		Version:  targetVersion,
 		Timeout:  timeout,
 		Logger:   log,
 		Verbose:  verbose,
+		Steps:    steps,
+		Down:     down,
 		Drop:     drop,
 	}
 
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if down && !viper.GetBool(yesFlag) {
+		if err := confirmDown(cmd.InOrStdin(), cmd.ErrOrStderr(), steps); err != nil {
+			return err
+		}
+	}
+
 	return migrate.RunMigrations(&config)
 }
+
+// confirmDown asks the operator to type 'yes' before rolling back migrations, which deletes their data.
+func confirmDown(in io.Reader, out io.Writer, steps int) error {
+	target := "all migrations"
+	if steps > 0 {
+		target = fmt.Sprintf("%d migration(s)", steps)
+	}
+	fmt.Fprintf(out, "This rolls back %s and deletes their data. Type 'yes' to continue: ", target)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if strings.TrimSpace(answer) != "yes" {
+		return fmt.Errorf("roll back aborted")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Package reencrypt provides a command to re-encrypt the backend data with the current encryption key.
+package reencrypt
+
+import (
+	"fmt"
+
+	"github.com/matthisholleville/mcp-gateway/cmd/serve"
+	"github.com/matthisholleville/mcp-gateway/cmd/util"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+const (
+	dryRunFlag    = "dry-run"
+	batchSizeFlag = "batch-size"
+
+	defaultBatchSize = 100
+)
+
+// NewReencryptCommand creates a new reencrypt command.
+func NewReencryptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reencrypt",
+		Short: "Re-encrypt the backend data with the current encryption key",
+		Long: "Decrypt every encrypted value of the backend with the configured keys, including the previous ones " +
+			"(--backend-previous-encryption-keys), and encrypt it again with the current key. Each value is checked " +
+			"to decrypt back to its plaintext before it is written, and left untouched if it changed meanwhile, so the " +
+			"gateway can keep running. Once it succeeds, the previous keys can be removed.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         run,
+	}
+	flags := cmd.Flags()
+
+	flags.Bool(dryRunFlag, false, "Check that every value can be decrypted, without writing")
+
+	flags.Int(batchSizeFlag, defaultBatchSize, "The number of values read and written at once")
+
+	util.AddBackendFlags(flags)
+	cmd.PreRun = func(_ *cobra.Command, _ []string) {
+		util.BindBackendFlags(flags)
+	}
+
+	return cmd
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	dryRun, _ := cmd.Flags().GetBool(dryRunFlag)
+	batchSize, _ := cmd.Flags().GetInt(batchSizeFlag)
+	if batchSize <= 0 {
+		return fmt.Errorf("--%s must be positive", batchSizeFlag)
+	}
+
+	config, err := serve.ReadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := util.NewBackendStorage(cmd.Context(), cmd.Name(), config)
+	if err != nil {
+		return err
+	}
+	reencrypter, ok := store.(storage.Reencrypter)
+	if !ok {
+		return fmt.Errorf("the %s backend does not encrypt its data", config.BackendConfig.Engine)
+	}
+
+	out := cmd.OutOrStdout()
+	result, err := reencrypter.Reencrypt(cmd.Context(), storage.ReencryptOptions{
+		DryRun:    dryRun,
+		BatchSize: batchSize,
+		Progress: func(done, total int) {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%d/%d values processed\n", done, total)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, failure := range result.Failed {
+		fmt.Fprintf(out, "! %s\n", failure)
+	}
+	summary := fmt.Sprintf("%d values: %d re-encrypted, %d plaintext encrypted, %d failed",
+		result.Total, result.Reencrypted, result.Encrypted, len(result.Failed))
+	if dryRun {
+		summary = fmt.Sprintf("%d values: %d to re-encrypt, %d plaintext to encrypt, %d failed (dry run, nothing written)",
+			result.Total, result.Reencrypted, result.Encrypted, len(result.Failed))
+	}
+	if result.Changed > 0 {
+		summary += fmt.Sprintf(", %d changed meanwhile (run the command again)", result.Changed)
+	}
+	fmt.Fprintln(out, summary)
+
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d values cannot be decrypted: configure the keys they were encrypted with", len(result.Failed))
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Package role provides the commands to manage the MCP Gateway roles.
+package role
+
+import (
+	"fmt"
+
+	"github.com/matthisholleville/mcp-gateway/cmd/util"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+const (
+	fileFlag     = "file"
+	templateFlag = "template"
+	proxyFlag    = "proxy"
+)
+
+// NewRoleCommand creates a new role command.
+func NewRoleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "role",
+		Short: "Manage the MCP Gateway roles",
+		Long:  "Manage the MCP Gateway roles through the admin API.",
+		Args:  cobra.NoArgs,
+	}
+	util.AddAdminFlags(cmd.PersistentFlags())
+	cmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		util.BindAdminFlags(cmd.PersistentFlags())
+	}
+
+	cmd.AddCommand(newListCommand())
+	cmd.AddCommand(newGetCommand())
+	cmd.AddCommand(newApplyCommand())
+	cmd.AddCommand(newCreateCommand())
+	cmd.AddCommand(newDeleteCommand())
+	return cmd
+}
+
+func newListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the roles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			roles, err := util.NewAdminClient().ListRoles(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return util.Print(cmd.OutOrStdout(), roles)
+		},
+	}
+}
+
+func newGetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get NAME",
+		Short: "Get a role",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			role, err := util.NewAdminClient().GetRole(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return util.Print(cmd.OutOrStdout(), role)
+		},
+	}
+}
+
+func newApplyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply -f FILE...",
+		Short: "Create or update roles from YAML files",
+		Long: "Create or update roles from YAML or JSON files. " +
+			"A file holds a single role or a list of roles, with the format of the admin API.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			files, err := cmd.Flags().GetStringSlice(fileFlag)
+			if err != nil {
+				return err
+			}
+			var roles []storage.RoleConfig
+			for _, file := range files {
+				fileRoles, err := util.ReadObjects[storage.RoleConfig](file)
+				if err != nil {
+					return err
+				}
+				roles = append(roles, fileRoles...)
+			}
+
+			client := util.NewAdminClient()
+			for _, role := range roles {
+				if role.Name == "" {
+					return fmt.Errorf("a role has no name")
+				}
+				if err := client.UpsertRole(cmd.Context(), role); err != nil {
+					return fmt.Errorf("failed to apply role %q: %w", role.Name, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "role %q applied\n", role.Name)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceP(fileFlag, "f", nil, "(required) The YAML or JSON files holding the roles")
+	_ = cmd.MarkFlagRequired(fileFlag)
+	return cmd
+}
+
+func newCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create NAME --template TEMPLATE",
+		Short: "Create a role from a template",
+		Long: "Create a role from a built-in template: admin, operator, read-only or per-proxy-user. " +
+			"The operator, read-only and per-proxy-user roles can be restricted to a proxy, which per-proxy-user requires.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			template, err := cmd.Flags().GetString(templateFlag)
+			if err != nil {
+				return err
+			}
+			proxy, err := cmd.Flags().GetString(proxyFlag)
+			if err != nil {
+				return err
+			}
+			role, err := util.NewAdminClient().CreateRoleFromTemplate(cmd.Context(), template, args[0], proxy)
+			if err != nil {
+				return fmt.Errorf("failed to create role %q: %w", args[0], err)
+			}
+			return util.Print(cmd.OutOrStdout(), role)
+		},
+	}
+	cmd.Flags().String(templateFlag, "", "(required) The template of the role: admin, operator, read-only or per-proxy-user")
+	cmd.Flags().String(proxyFlag, "", "The proxy the role is restricted to")
+	_ = cmd.MarkFlagRequired(templateFlag)
+	return cmd
+}
+
+func newDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete NAME...",
+		Short: "Delete roles",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := util.NewAdminClient()
+			for _, name := range args {
+				if err := client.DeleteRole(cmd.Context(), name); err != nil {
+					return fmt.Errorf("failed to delete role %q: %w", name, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "role %q deleted\n", name)
+			}
+			return nil
+		},
+	}
+}
@@ -2,13 +2,21 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// configFlag is the path of the config file, instead of the config file searched in the default paths.
+const configFlag = "config"
+
+// profileFlag selects the overlay of the config file merged over it, e.g. config.prod.yaml for prod.
+const profileFlag = "profile"
+
 // NewRootCommand creates a new root command.
 func NewRootCommand() *cobra.Command {
 	programName := "MCP Gateway"
@@ -24,14 +32,42 @@ func NewRootCommand() *cobra.Command {
 		viper.AddConfigPath(path)
 	}
 
-	err := viper.ReadInConfig()
-	if err != nil {
-		panic(fmt.Sprintf("unable to read config file: %s", err))
-	}
+	// The config is read before the persistent hooks of the subcommands, which read it.
+	cobra.EnableTraverseRunHooks = true
 
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   programName,
 		Short: "A proxy gateway for MCP servers",
 		Long:  `MCP Gateway is a flexible and extensible proxy gateway for MCP servers, with built-in support for middleware, permissions, rate limiting, and observability.`,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := viper.BindPFlag(profileFlag, cmd.Flags().Lookup(profileFlag)); err != nil {
+				return err
+			}
+			path, _ := cmd.Flags().GetString(configFlag)
+			return readConfig(path)
+		},
+	}
+	cmd.PersistentFlags().String(configFlag, "", "The config file to use, instead of the config.yaml searched in /etc/mcp-gateway, $HOME/.mcp-gateway and ./config (env: MCP_GATEWAY_CONFIG)")
+	cmd.PersistentFlags().String(profileFlag, "", "The profile whose config file, e.g. config.prod.yaml for prod, is merged over the config file (env: MCP_GATEWAY_PROFILE)")
+	return cmd
+}
+
+// readConfig reads the config file at path, or searched in the default paths if empty. A missing config file
+// is only an error when its path is given: the config then comes from the environment and the flags.
+func readConfig(path string) error {
+	if path == "" {
+		path = os.Getenv("MCP_GATEWAY_CONFIG")
+	}
+	if path != "" {
+		viper.SetConfigFile(path)
+	}
+
+	err := viper.ReadInConfig()
+	if err == nil {
+		return nil
+	}
+	if path == "" && errors.As(err, &viper.ConfigFileNotFoundError{}) {
+		return nil
 	}
+	return fmt.Errorf("unable to read config file: %w", err)
 }
@@ -0,0 +1,104 @@
+// Package seed provides a command to bootstrap a fresh MCP Gateway backend.
+package seed
+
+import (
+	"fmt"
+
+	"github.com/matthisholleville/mcp-gateway/cmd/serve"
+	"github.com/matthisholleville/mcp-gateway/cmd/util"
+	"github.com/matthisholleville/mcp-gateway/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+const (
+	adminRoleFlag      = "admin-role"
+	attributeKeyFlag   = "attribute-key"
+	attributeValueFlag = "attribute-value"
+	templateFlag       = "template"
+	dryRunFlag         = "dry-run"
+
+	defaultAdminRole      = "admin"
+	defaultAttributeKey   = "groups"
+	defaultAttributeValue = "admins"
+)
+
+// NewSeedCommand creates a new seed command.
+func NewSeedCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Bootstrap a fresh backend",
+		Long: "Create an admin role granted all the permissions, the attribute mapping granting it, " +
+			"and the objects of the template files (e.g. example proxies), in the manifest format of the apply command. " +
+			"The objects which already exist are left untouched, so seeding is safe to repeat.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         run,
+	}
+	flags := cmd.Flags()
+
+	flags.String(adminRoleFlag, defaultAdminRole, "The name of the admin role")
+
+	flags.String(attributeKeyFlag, defaultAttributeKey, "The user attribute mapped to the admin role")
+
+	flags.String(attributeValueFlag, defaultAttributeValue, "The value of the user attribute mapped to the admin role")
+
+	flags.StringSliceP(templateFlag, "f", nil, "The template files of the objects to create, in YAML or JSON")
+
+	flags.Bool(dryRunFlag, false, "Print the objects to create without creating them")
+
+	util.AddBackendFlags(flags)
+	cmd.PreRun = func(_ *cobra.Command, _ []string) {
+		util.BindBackendFlags(flags)
+	}
+
+	return cmd
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	adminRole, _ := cmd.Flags().GetString(adminRoleFlag)
+	attributeKey, _ := cmd.Flags().GetString(attributeKeyFlag)
+	attributeValue, _ := cmd.Flags().GetString(attributeValueFlag)
+	templates, _ := cmd.Flags().GetStringSlice(templateFlag)
+	dryRun, _ := cmd.Flags().GetBool(dryRunFlag)
+
+	m, err := manifest.Seed(manifest.SeedOptions{
+		AdminRole:      adminRole,
+		AttributeKey:   attributeKey,
+		AttributeValue: attributeValue,
+	}, templates...)
+	if err != nil {
+		return err
+	}
+
+	config, err := serve.ReadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := util.NewBackendStorage(cmd.Context(), cmd.Name(), config)
+	if err != nil {
+		return err
+	}
+
+	changes, err := manifest.Plan(cmd.Context(), store, m, false)
+	if err != nil {
+		return err
+	}
+	creations := manifest.Creations(changes)
+	out := cmd.OutOrStdout()
+	for _, change := range creations {
+		fmt.Fprintf(out, "+ %s %s\n", change.Kind, change.Name)
+	}
+	if len(creations) == 0 {
+		fmt.Fprintln(out, "Nothing to seed: the objects already exist")
+		return nil
+	}
+	if dryRun {
+		fmt.Fprintf(out, "%d to create (dry run, nothing applied)\n", len(creations))
+		return nil
+	}
+	if err := manifest.Apply(cmd.Context(), store, creations); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%d created\n", len(creations))
+	return nil
+}
@@ -9,6 +9,9 @@ import (
 // bindServeFlagsFunc binds the serve flags to the command.
 func bindServeFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 	return func(cmd *cobra.Command, _ []string) {
+		util.MustBindPFlag("dev.enabled", flags.Lookup("dev"))
+		util.MustBindEnv("dev.enabled", "MCP_GATEWAY_DEV")
+
 		util.MustBindPFlag("http-addr", flags.Lookup("http-addr"))
 		util.MustBindEnv("http-addr", "MCP_GATEWAY_HTTP_ADDR")
 
@@ -21,12 +24,104 @@ func bindServeFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("log.timestamp-format", flags.Lookup("log-timestamp-format"))
 		util.MustBindEnv("log.timestamp-format", "MCP_GATEWAY_LOG_TIMESTAMP_FORMAT")
 
+		util.MustBindPFlag("log.redaction.enabled", flags.Lookup("log-redaction-enabled"))
+		util.MustBindEnv("log.redaction.enabled", "MCP_GATEWAY_LOG_REDACTION_ENABLED")
+
+		util.MustBindPFlag("log.redaction.keyPatterns", flags.Lookup("log-redaction-key-patterns"))
+		util.MustBindEnv("log.redaction.keyPatterns", "MCP_GATEWAY_LOG_REDACTION_KEY_PATTERNS")
+
+		util.MustBindPFlag("log.redaction.maxLength", flags.Lookup("log-redaction-max-length"))
+		util.MustBindEnv("log.redaction.maxLength", "MCP_GATEWAY_LOG_REDACTION_MAX_LENGTH")
+
+		util.MustBindPFlag("log.redaction.excludedProxies", flags.Lookup("log-redaction-excluded-proxies"))
+		util.MustBindEnv("log.redaction.excludedProxies", "MCP_GATEWAY_LOG_REDACTION_EXCLUDED_PROXIES")
+
+		util.MustBindPFlag("log.sampling.initial", flags.Lookup("log-sampling-initial"))
+		util.MustBindEnv("log.sampling.initial", "MCP_GATEWAY_LOG_SAMPLING_INITIAL")
+
+		util.MustBindPFlag("log.sampling.thereafter", flags.Lookup("log-sampling-thereafter"))
+		util.MustBindEnv("log.sampling.thereafter", "MCP_GATEWAY_LOG_SAMPLING_THEREAFTER")
+
+		util.MustBindPFlag("log.outputPaths", flags.Lookup("log-output-paths"))
+		util.MustBindEnv("log.outputPaths", "MCP_GATEWAY_LOG_OUTPUT_PATHS")
+
+		util.MustBindPFlag("log.rotation.maxSize", flags.Lookup("log-rotation-max-size"))
+		util.MustBindEnv("log.rotation.maxSize", "MCP_GATEWAY_LOG_ROTATION_MAX_SIZE")
+
+		util.MustBindPFlag("log.rotation.maxAge", flags.Lookup("log-rotation-max-age"))
+		util.MustBindEnv("log.rotation.maxAge", "MCP_GATEWAY_LOG_ROTATION_MAX_AGE")
+
+		util.MustBindPFlag("log.rotation.maxBackups", flags.Lookup("log-rotation-max-backups"))
+		util.MustBindEnv("log.rotation.maxBackups", "MCP_GATEWAY_LOG_ROTATION_MAX_BACKUPS")
+
+		util.MustBindPFlag("log.rotation.compress", flags.Lookup("log-rotation-compress"))
+		util.MustBindEnv("log.rotation.compress", "MCP_GATEWAY_LOG_ROTATION_COMPRESS")
+
+		util.MustBindPFlag("log.syslog.enabled", flags.Lookup("log-syslog-enabled"))
+		util.MustBindEnv("log.syslog.enabled", "MCP_GATEWAY_LOG_SYSLOG_ENABLED")
+
+		util.MustBindPFlag("log.syslog.network", flags.Lookup("log-syslog-network"))
+		util.MustBindEnv("log.syslog.network", "MCP_GATEWAY_LOG_SYSLOG_NETWORK")
+
+		util.MustBindPFlag("log.syslog.address", flags.Lookup("log-syslog-address"))
+		util.MustBindEnv("log.syslog.address", "MCP_GATEWAY_LOG_SYSLOG_ADDRESS")
+
+		util.MustBindPFlag("log.syslog.tag", flags.Lookup("log-syslog-tag"))
+		util.MustBindEnv("log.syslog.tag", "MCP_GATEWAY_LOG_SYSLOG_TAG")
+
+		util.MustBindPFlag("log.syslog.facility", flags.Lookup("log-syslog-facility"))
+		util.MustBindEnv("log.syslog.facility", "MCP_GATEWAY_LOG_SYSLOG_FACILITY")
+
 		util.MustBindPFlag("proxy.cache-ttl", flags.Lookup("proxy-cache-ttl"))
 		util.MustBindEnv("proxy.cache-ttl", "MCP_GATEWAY_PROXY_CACHE_TTL")
 
 		util.MustBindPFlag("proxy.heartbeat.interval", flags.Lookup("proxy-heartbeat-interval"))
 		util.MustBindEnv("proxy.heartbeat.interval", "MCP_GATEWAY_PROXY_HEARTBEAT_INTERVAL")
 
+		util.MustBindPFlag("proxy.callTimeout", flags.Lookup("proxy-call-timeout"))
+		util.MustBindEnv("proxy.callTimeout", "MCP_GATEWAY_PROXY_CALL_TIMEOUT")
+
+		util.MustBindPFlag("proxy.maxConcurrentCalls", flags.Lookup("proxy-max-concurrent-calls"))
+		util.MustBindEnv("proxy.maxConcurrentCalls", "MCP_GATEWAY_PROXY_MAX_CONCURRENT_CALLS")
+
+		util.MustBindPFlag("proxy.queueTimeout", flags.Lookup("proxy-queue-timeout"))
+		util.MustBindEnv("proxy.queueTimeout", "MCP_GATEWAY_PROXY_QUEUE_TIMEOUT")
+
+		util.MustBindPFlag("proxy.leaderElection", flags.Lookup("proxy-leader-election"))
+		util.MustBindEnv("proxy.leaderElection", "MCP_GATEWAY_PROXY_LEADER_ELECTION")
+
+		util.MustBindPFlag("proxy.readyAfterSync", flags.Lookup("proxy-ready-after-sync"))
+		util.MustBindEnv("proxy.readyAfterSync", "MCP_GATEWAY_PROXY_READY_AFTER_SYNC")
+
+		util.MustBindPFlag("proxy.upstreamSessions", flags.Lookup("proxy-upstream-sessions"))
+		util.MustBindEnv("proxy.upstreamSessions", "MCP_GATEWAY_PROXY_UPSTREAM_SESSIONS")
+
+		util.MustBindPFlag("proxy.upstreamSessionIdleTimeout", flags.Lookup("proxy-upstream-session-idle-timeout"))
+		util.MustBindEnv("proxy.upstreamSessionIdleTimeout", "MCP_GATEWAY_PROXY_UPSTREAM_SESSION_IDLE_TIMEOUT")
+
+		util.MustBindPFlag("proxy.maxUpstreamSessions", flags.Lookup("proxy-max-upstream-sessions"))
+		util.MustBindEnv("proxy.maxUpstreamSessions", "MCP_GATEWAY_PROXY_MAX_UPSTREAM_SESSIONS")
+
+		util.MustBindPFlag("proxy.upstreamKeepaliveInterval", flags.Lookup("proxy-upstream-keepalive-interval"))
+		util.MustBindEnv("proxy.upstreamKeepaliveInterval", "MCP_GATEWAY_PROXY_UPSTREAM_KEEPALIVE_INTERVAL")
+
+		util.MustBindPFlag("proxy.describeTools", flags.Lookup("proxy-describe-tools"))
+		util.MustBindEnv("proxy.describeTools", "MCP_GATEWAY_PROXY_DESCRIBE_TOOLS")
+		util.MustBindPFlag("proxy.metaTools", flags.Lookup("proxy-meta-tools"))
+		util.MustBindEnv("proxy.metaTools", "MCP_GATEWAY_PROXY_META_TOOLS")
+
+		util.MustBindPFlag("proxy.maxResponseBytes", flags.Lookup("proxy-max-response-bytes"))
+		util.MustBindEnv("proxy.maxResponseBytes", "MCP_GATEWAY_PROXY_MAX_RESPONSE_BYTES")
+
+		util.MustBindPFlag("proxy.responseSizePolicy", flags.Lookup("proxy-response-size-policy"))
+		util.MustBindEnv("proxy.responseSizePolicy", "MCP_GATEWAY_PROXY_RESPONSE_SIZE_POLICY")
+
+		util.MustBindPFlag("proxy.reconnectOnDNSChange", flags.Lookup("proxy-reconnect-on-dns-change"))
+		util.MustBindEnv("proxy.reconnectOnDNSChange", "MCP_GATEWAY_PROXY_RECONNECT_ON_DNS_CHANGE")
+
+		util.MustBindPFlag("proxy.egressProxy", flags.Lookup("proxy-egress-url"))
+		util.MustBindEnv("proxy.egressProxy", "MCP_GATEWAY_PROXY_EGRESS_URL")
+
 		util.MustBindPFlag("oauth.enabled", flags.Lookup("oauth-enabled"))
 		util.MustBindEnv("oauth.enabled", "MCP_GATEWAY_OAUTH_ENABLED")
 
@@ -42,6 +137,12 @@ func bindServeFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("oauth.scopesSupported", flags.Lookup("oauth-scopes-supported"))
 		util.MustBindEnv("oauth.scopesSupported", "MCP_GATEWAY_OAUTH_SCOPES_SUPPORTED")
 
+		util.MustBindPFlag("oauth.audiences", flags.Lookup("oauth-audiences"))
+		util.MustBindEnv("oauth.audiences", "MCP_GATEWAY_OAUTH_AUDIENCES")
+
+		util.MustBindPFlag("oauth.proxyAuthorizationServerMetadata", flags.Lookup("oauth-proxy-authorization-server-metadata"))
+		util.MustBindEnv("oauth.proxyAuthorizationServerMetadata", "MCP_GATEWAY_OAUTH_PROXY_AUTHORIZATION_SERVER_METADATA")
+
 		util.MustBindPFlag("authProvider.enabled", flags.Lookup("auth-provider-enabled"))
 		util.MustBindEnv("authProvider.enabled", "MCP_GATEWAY_AUTH_PROVIDER_ENABLED")
 
@@ -50,6 +151,18 @@ func bindServeFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("authProvider.name", flags.Lookup("auth-provider-name"))
 		util.MustBindEnv("authProvider.name", "MCP_GATEWAY_AUTH_PROVIDER_NAME")
 
+		util.MustBindPFlag("authProvider.healthCheckInterval", flags.Lookup("auth-provider-health-check-interval"))
+		util.MustBindEnv("authProvider.healthCheckInterval", "MCP_GATEWAY_AUTH_PROVIDER_HEALTH_CHECK_INTERVAL")
+
+		util.MustBindPFlag("authProvider.clockSkew", flags.Lookup("auth-provider-clock-skew"))
+		util.MustBindEnv("authProvider.clockSkew", "MCP_GATEWAY_AUTH_PROVIDER_CLOCK_SKEW")
+
+		util.MustBindPFlag("authProvider.maxTokenAge", flags.Lookup("auth-provider-max-token-age"))
+		util.MustBindEnv("authProvider.maxTokenAge", "MCP_GATEWAY_AUTH_PROVIDER_MAX_TOKEN_AGE")
+
+		util.MustBindPFlag("authProvider.requiredClaims", flags.Lookup("auth-provider-required-claims"))
+		util.MustBindEnv("authProvider.requiredClaims", "MCP_GATEWAY_AUTH_PROVIDER_REQUIRED_CLAIMS")
+
 		util.MustBindPFlag("backendConfig.engine", flags.Lookup("backend-engine"))
 		util.MustBindEnv("backendConfig.engine", "MCP_GATEWAY_BACKEND_ENGINE")
 
@@ -62,6 +175,12 @@ func bindServeFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("backendConfig.password", flags.Lookup("backend-password"))
 		util.MustBindEnv("backendConfig.password", "MCP_GATEWAY_BACKEND_PASSWORD")
 
+		util.MustBindPFlag("backendConfig.schema", flags.Lookup("backend-schema"))
+		util.MustBindEnv("backendConfig.schema", "MCP_GATEWAY_BACKEND_SCHEMA")
+
+		util.MustBindPFlag("backendConfig.pgBouncer", flags.Lookup("backend-pgbouncer"))
+		util.MustBindEnv("backendConfig.pgBouncer", "MCP_GATEWAY_BACKEND_PGBOUNCER")
+
 		util.MustBindPFlag("backendConfig.maxOpenConns", flags.Lookup("backend-max-open-conns"))
 		util.MustBindEnv("backendConfig.maxOpenConns", "MCP_GATEWAY_BACKEND_MAX_OPEN_CONNS")
 
@@ -74,9 +193,78 @@ func bindServeFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("backendConfig.connMaxLifetime", flags.Lookup("backend-conn-max-lifetime"))
 		util.MustBindEnv("backendConfig.connMaxLifetime", "MCP_GATEWAY_BACKEND_CONN_MAX_LIFETIME")
 
+		util.MustBindPFlag("backendConfig.connectRetries", flags.Lookup("backend-connect-retries"))
+		util.MustBindEnv("backendConfig.connectRetries", "MCP_GATEWAY_BACKEND_CONNECT_RETRIES")
+
+		util.MustBindPFlag("backendConfig.connectBackoff", flags.Lookup("backend-connect-backoff"))
+		util.MustBindEnv("backendConfig.connectBackoff", "MCP_GATEWAY_BACKEND_CONNECT_BACKOFF")
+
 		util.MustBindPFlag("backendConfig.encryptionKey", flags.Lookup("backend-encryption-key"))
 		util.MustBindEnv("backendConfig.encryptionKey", "MCP_GATEWAY_BACKEND_ENCRYPTION_KEY")
 
+		util.MustBindPFlag("backendConfig.encryptionKeyID", flags.Lookup("backend-encryption-key-id"))
+		util.MustBindEnv("backendConfig.encryptionKeyID", "MCP_GATEWAY_BACKEND_ENCRYPTION_KEY_ID")
+
+		util.MustBindPFlag("backendConfig.previousEncryptionKeys", flags.Lookup("backend-previous-encryption-keys"))
+		util.MustBindEnv("backendConfig.previousEncryptionKeys", "MCP_GATEWAY_BACKEND_PREVIOUS_ENCRYPTION_KEYS")
+
+		util.MustBindPFlag("backendConfig.encryption.provider", flags.Lookup("backend-encryption-provider"))
+		util.MustBindEnv("backendConfig.encryption.provider", "MCP_GATEWAY_BACKEND_ENCRYPTION_PROVIDER")
+
+		util.MustBindPFlag("backendConfig.encryption.kmsKey", flags.Lookup("backend-encryption-kms-key"))
+		util.MustBindEnv("backendConfig.encryption.kmsKey", "MCP_GATEWAY_BACKEND_ENCRYPTION_KMS_KEY")
+
+		util.MustBindPFlag("backendConfig.encryption.kmsTimeout", flags.Lookup("backend-encryption-kms-timeout"))
+		util.MustBindEnv("backendConfig.encryption.kmsTimeout", "MCP_GATEWAY_BACKEND_ENCRYPTION_KMS_TIMEOUT")
+
+		util.MustBindPFlag("vault.address", flags.Lookup("vault-address"))
+		util.MustBindEnv("vault.address", "MCP_GATEWAY_VAULT_ADDRESS")
+
+		util.MustBindPFlag("vault.namespace", flags.Lookup("vault-namespace"))
+		util.MustBindEnv("vault.namespace", "MCP_GATEWAY_VAULT_NAMESPACE")
+
+		util.MustBindPFlag("vault.authMethod", flags.Lookup("vault-auth-method"))
+		util.MustBindEnv("vault.authMethod", "MCP_GATEWAY_VAULT_AUTH_METHOD")
+
+		util.MustBindPFlag("vault.token", flags.Lookup("vault-token"))
+		util.MustBindEnv("vault.token", "MCP_GATEWAY_VAULT_TOKEN")
+
+		util.MustBindPFlag("vault.kubernetesRole", flags.Lookup("vault-kubernetes-role"))
+		util.MustBindEnv("vault.kubernetesRole", "MCP_GATEWAY_VAULT_KUBERNETES_ROLE")
+
+		util.MustBindPFlag("vault.kubernetesMount", flags.Lookup("vault-kubernetes-mount"))
+		util.MustBindEnv("vault.kubernetesMount", "MCP_GATEWAY_VAULT_KUBERNETES_MOUNT")
+
+		util.MustBindPFlag("vault.cacheTTL", flags.Lookup("vault-cache-ttl"))
+		util.MustBindEnv("vault.cacheTTL", "MCP_GATEWAY_VAULT_CACHE_TTL")
+
+		util.MustBindPFlag("vault.timeout", flags.Lookup("vault-timeout"))
+		util.MustBindEnv("vault.timeout", "MCP_GATEWAY_VAULT_TIMEOUT")
+
+		util.MustBindPFlag("secrets.allowedEnv", flags.Lookup("secrets-allowed-env"))
+		util.MustBindEnv("secrets.allowedEnv", "MCP_GATEWAY_SECRETS_ALLOWED_ENV")
+
+		util.MustBindPFlag("secrets.allowedDirs", flags.Lookup("secrets-allowed-dirs"))
+		util.MustBindEnv("secrets.allowedDirs", "MCP_GATEWAY_SECRETS_ALLOWED_DIRS")
+
+		util.MustBindPFlag("metrics.roleLabel", flags.Lookup("metrics-role-label"))
+		util.MustBindEnv("metrics.roleLabel", "MCP_GATEWAY_METRICS_ROLE_LABEL")
+
+		util.MustBindPFlag("metrics.subjectLabel", flags.Lookup("metrics-subject-label"))
+		util.MustBindEnv("metrics.subjectLabel", "MCP_GATEWAY_METRICS_SUBJECT_LABEL")
+
+		util.MustBindPFlag("metrics.maxLabelValues", flags.Lookup("metrics-max-label-values"))
+		util.MustBindEnv("metrics.maxLabelValues", "MCP_GATEWAY_METRICS_MAX_LABEL_VALUES")
+
+		util.MustBindPFlag("metrics.otlpEndpoint", flags.Lookup("metrics-otlp-endpoint"))
+		util.MustBindEnv("metrics.otlpEndpoint", "MCP_GATEWAY_METRICS_OTLP_ENDPOINT")
+
+		util.MustBindPFlag("metrics.otlpHeaders", flags.Lookup("metrics-otlp-headers"))
+		util.MustBindEnv("metrics.otlpHeaders", "MCP_GATEWAY_METRICS_OTLP_HEADERS")
+
+		util.MustBindPFlag("metrics.otlpInterval", flags.Lookup("metrics-otlp-interval"))
+		util.MustBindEnv("metrics.otlpInterval", "MCP_GATEWAY_METRICS_OTLP_INTERVAL")
+
 		util.MustBindPFlag("authProvider.okta.issuer", flags.Lookup("okta-issuer"))
 		util.MustBindEnv("authProvider.okta.issuer", "MCP_GATEWAY_OKTA_ISSUER")
 
@@ -92,9 +280,231 @@ func bindServeFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("authProvider.okta.privateKeyId", flags.Lookup("okta-private-key-id"))
 		util.MustBindEnv("authProvider.okta.privateKeyId", "MCP_GATEWAY_OKTA_PRIVATE_KEY_ID")
 
+		util.MustBindPFlag("authProvider.okta.audience", flags.Lookup("okta-audience"))
+		util.MustBindEnv("authProvider.okta.audience", "MCP_GATEWAY_OKTA_AUDIENCE")
+
+		util.MustBindPFlag("authProvider.okta.tokenClientId", flags.Lookup("okta-token-client-id"))
+		util.MustBindEnv("authProvider.okta.tokenClientId", "MCP_GATEWAY_OKTA_TOKEN_CLIENT_ID")
+
+		util.MustBindPFlag("authProvider.okta.requiredClaims", flags.Lookup("okta-required-claims"))
+		util.MustBindEnv("authProvider.okta.requiredClaims", "MCP_GATEWAY_OKTA_REQUIRED_CLAIMS")
+
 		cmd.MarkFlagsRequiredTogether("okta-private-key", "okta-private-key-id", "okta-client-id", "okta-org-url", "okta-issuer")
 
+		util.MustBindPFlag("authProvider.firebase.projectId", flags.Lookup("firebase-project-id"))
+		util.MustBindEnv("authProvider.firebase.projectId", "MCP_GATEWAY_FIREBASE_PROJECT_ID")
+
+		util.MustBindPFlag("authProvider.firebase.appCheck", flags.Lookup("firebase-app-check"))
+		util.MustBindEnv("authProvider.firebase.appCheck", "MCP_GATEWAY_FIREBASE_APP_CHECK")
+
+		util.MustBindPFlag("authProvider.firebase.projectNumber", flags.Lookup("firebase-project-number"))
+		util.MustBindEnv("authProvider.firebase.projectNumber", "MCP_GATEWAY_FIREBASE_PROJECT_NUMBER")
+
 		util.MustBindPFlag("http.adminApiKey", flags.Lookup("http-admin-api-key"))
 		util.MustBindEnv("http.adminApiKey", "MCP_GATEWAY_HTTP_ADMIN_API_KEY")
+
+		util.MustBindPFlag("http.adminApiKeyHash", flags.Lookup("http-admin-api-key-hash"))
+		util.MustBindEnv("http.adminApiKeyHash", "MCP_GATEWAY_HTTP_ADMIN_API_KEY_HASH")
+
+		util.MustBindPFlag("http.adminIPAccess.allowedCIDRs", flags.Lookup("http-admin-allowed-cidrs"))
+		util.MustBindEnv("http.adminIPAccess.allowedCIDRs", "MCP_GATEWAY_HTTP_ADMIN_ALLOWED_CIDRS")
+
+		util.MustBindPFlag("http.adminIPAccess.deniedCIDRs", flags.Lookup("http-admin-denied-cidrs"))
+		util.MustBindEnv("http.adminIPAccess.deniedCIDRs", "MCP_GATEWAY_HTTP_ADMIN_DENIED_CIDRS")
+
+		util.MustBindPFlag("http.adminIPAccess.protectMetrics", flags.Lookup("http-admin-protect-metrics"))
+		util.MustBindEnv("http.adminIPAccess.protectMetrics", "MCP_GATEWAY_HTTP_ADMIN_PROTECT_METRICS")
+
+		util.MustBindPFlag("http.adminIPAccess.protectSwagger", flags.Lookup("http-admin-protect-swagger"))
+		util.MustBindEnv("http.adminIPAccess.protectSwagger", "MCP_GATEWAY_HTTP_ADMIN_PROTECT_SWAGGER")
+
+		util.MustBindPFlag("http.adminUI", flags.Lookup("http-admin-ui-enabled"))
+		util.MustBindEnv("http.adminUI", "MCP_GATEWAY_HTTP_ADMIN_UI_ENABLED")
+
+		util.MustBindPFlag("http.trustedProxies", flags.Lookup("http-trusted-proxies"))
+		util.MustBindEnv("http.trustedProxies", "MCP_GATEWAY_HTTP_TRUSTED_PROXIES")
+
+		util.MustBindPFlag("http.timeouts.readHeader", flags.Lookup("http-read-header-timeout"))
+		util.MustBindEnv("http.timeouts.readHeader", "MCP_GATEWAY_HTTP_READ_HEADER_TIMEOUT")
+
+		util.MustBindPFlag("http.timeouts.idle", flags.Lookup("http-idle-timeout"))
+		util.MustBindEnv("http.timeouts.idle", "MCP_GATEWAY_HTTP_IDLE_TIMEOUT")
+
+		util.MustBindPFlag("http.timeouts.mcp", flags.Lookup("http-mcp-timeout"))
+		util.MustBindEnv("http.timeouts.mcp", "MCP_GATEWAY_HTTP_MCP_TIMEOUT")
+
+		util.MustBindPFlag("http.timeouts.admin", flags.Lookup("http-admin-timeout"))
+		util.MustBindEnv("http.timeouts.admin", "MCP_GATEWAY_HTTP_ADMIN_TIMEOUT")
+
+		util.MustBindPFlag("http.timeouts.drain", flags.Lookup("http-drain-timeout"))
+		util.MustBindEnv("http.timeouts.drain", "MCP_GATEWAY_HTTP_DRAIN_TIMEOUT")
+
+		util.MustBindPFlag("http.timeouts.keepalive", flags.Lookup("http-keepalive-interval"))
+		util.MustBindEnv("http.timeouts.keepalive", "MCP_GATEWAY_HTTP_KEEPALIVE_INTERVAL")
+
+		util.MustBindPFlag("grpc.enabled", flags.Lookup("grpc-enabled"))
+		util.MustBindEnv("grpc.enabled", "MCP_GATEWAY_GRPC_ENABLED")
+
+		util.MustBindPFlag("grpc.addr", flags.Lookup("grpc-addr"))
+		util.MustBindEnv("grpc.addr", "MCP_GATEWAY_GRPC_ADDR")
+
+		util.MustBindPFlag("debug.enabled", flags.Lookup("debug-enabled"))
+		util.MustBindEnv("debug.enabled", "MCP_GATEWAY_DEBUG_ENABLED")
+
+		util.MustBindPFlag("debug.addr", flags.Lookup("debug-addr"))
+		util.MustBindEnv("debug.addr", "MCP_GATEWAY_DEBUG_ADDR")
+
+		util.MustBindPFlag("eventExport.sink", flags.Lookup("event-export-sink"))
+		util.MustBindEnv("eventExport.sink", "MCP_GATEWAY_EVENT_EXPORT_SINK")
+
+		util.MustBindPFlag("eventExport.url", flags.Lookup("event-export-url"))
+		util.MustBindEnv("eventExport.url", "MCP_GATEWAY_EVENT_EXPORT_URL")
+
+		util.MustBindPFlag("eventExport.topic", flags.Lookup("event-export-topic"))
+		util.MustBindEnv("eventExport.topic", "MCP_GATEWAY_EVENT_EXPORT_TOPIC")
+
+		util.MustBindPFlag("eventExport.format", flags.Lookup("event-export-format"))
+		util.MustBindEnv("eventExport.format", "MCP_GATEWAY_EVENT_EXPORT_FORMAT")
+
+		util.MustBindPFlag("eventExport.types", flags.Lookup("event-export-types"))
+		util.MustBindEnv("eventExport.types", "MCP_GATEWAY_EVENT_EXPORT_TYPES")
+
+		util.MustBindPFlag("eventExport.source", flags.Lookup("event-export-source"))
+		util.MustBindEnv("eventExport.source", "MCP_GATEWAY_EVENT_EXPORT_SOURCE")
+
+		util.MustBindPFlag("eventExport.username", flags.Lookup("event-export-username"))
+		util.MustBindEnv("eventExport.username", "MCP_GATEWAY_EVENT_EXPORT_USERNAME")
+
+		util.MustBindPFlag("eventExport.password", flags.Lookup("event-export-password"))
+		util.MustBindEnv("eventExport.password", "MCP_GATEWAY_EVENT_EXPORT_PASSWORD")
+
+		util.MustBindPFlag("eventExport.token", flags.Lookup("event-export-token"))
+		util.MustBindEnv("eventExport.token", "MCP_GATEWAY_EVENT_EXPORT_TOKEN")
+
+		util.MustBindPFlag("eventExport.timeout", flags.Lookup("event-export-timeout"))
+		util.MustBindEnv("eventExport.timeout", "MCP_GATEWAY_EVENT_EXPORT_TIMEOUT")
+
+		util.MustBindPFlag("eventExport.bufferSize", flags.Lookup("event-export-buffer-size"))
+		util.MustBindEnv("eventExport.bufferSize", "MCP_GATEWAY_EVENT_EXPORT_BUFFER_SIZE")
+
+		util.MustBindPFlag("approval.timeout", flags.Lookup("approval-timeout"))
+		util.MustBindEnv("approval.timeout", "MCP_GATEWAY_APPROVAL_TIMEOUT")
+
+		util.MustBindPFlag("approval.webhookURL", flags.Lookup("approval-webhook-url"))
+		util.MustBindEnv("approval.webhookURL", "MCP_GATEWAY_APPROVAL_WEBHOOK_URL")
+
+		util.MustBindPFlag("approval.slackWebhookURL", flags.Lookup("approval-slack-webhook-url"))
+		util.MustBindEnv("approval.slackWebhookURL", "MCP_GATEWAY_APPROVAL_SLACK_WEBHOOK_URL")
+
+		util.MustBindPFlag("maintenance.enabled", flags.Lookup("maintenance-enabled"))
+		util.MustBindEnv("maintenance.enabled", "MCP_GATEWAY_MAINTENANCE_ENABLED")
+
+		util.MustBindPFlag("maintenance.message", flags.Lookup("maintenance-message"))
+		util.MustBindEnv("maintenance.message", "MCP_GATEWAY_MAINTENANCE_MESSAGE")
+
+		util.MustBindPFlag("screening.enabled", flags.Lookup("screening-enabled"))
+		util.MustBindEnv("screening.enabled", "MCP_GATEWAY_SCREENING_ENABLED")
+
+		util.MustBindPFlag("screening.rules", flags.Lookup("screening-rules"))
+		util.MustBindEnv("screening.rules", "MCP_GATEWAY_SCREENING_RULES")
+
+		util.MustBindPFlag("screening.denyPatterns", flags.Lookup("screening-deny-patterns"))
+		util.MustBindEnv("screening.denyPatterns", "MCP_GATEWAY_SCREENING_DENY_PATTERNS")
+
+		util.MustBindPFlag("screening.action", flags.Lookup("screening-action"))
+		util.MustBindEnv("screening.action", "MCP_GATEWAY_SCREENING_ACTION")
+
+		util.MustBindPFlag("screening.excludedProxies", flags.Lookup("screening-excluded-proxies"))
+		util.MustBindEnv("screening.excludedProxies", "MCP_GATEWAY_SCREENING_EXCLUDED_PROXIES")
+
+		util.MustBindPFlag("guardrail.url", flags.Lookup("guardrail-url"))
+		util.MustBindEnv("guardrail.url", "MCP_GATEWAY_GUARDRAIL_URL")
+
+		util.MustBindPFlag("guardrail.token", flags.Lookup("guardrail-token"))
+		util.MustBindEnv("guardrail.token", "MCP_GATEWAY_GUARDRAIL_TOKEN")
+
+		util.MustBindPFlag("guardrail.stages", flags.Lookup("guardrail-stages"))
+		util.MustBindEnv("guardrail.stages", "MCP_GATEWAY_GUARDRAIL_STAGES")
+
+		util.MustBindPFlag("guardrail.timeout", flags.Lookup("guardrail-timeout"))
+		util.MustBindEnv("guardrail.timeout", "MCP_GATEWAY_GUARDRAIL_TIMEOUT")
+
+		util.MustBindPFlag("guardrail.failOpen", flags.Lookup("guardrail-fail-open"))
+		util.MustBindEnv("guardrail.failOpen", "MCP_GATEWAY_GUARDRAIL_FAIL_OPEN")
+
+		util.MustBindPFlag("guardrail.excludedProxies", flags.Lookup("guardrail-excluded-proxies"))
+		util.MustBindEnv("guardrail.excludedProxies", "MCP_GATEWAY_GUARDRAIL_EXCLUDED_PROXIES")
+
+		util.MustBindPFlag("masking.enabled", flags.Lookup("masking-enabled"))
+		util.MustBindEnv("masking.enabled", "MCP_GATEWAY_MASKING_ENABLED")
+
+		util.MustBindPFlag("masking.patterns", flags.Lookup("masking-patterns"))
+		util.MustBindEnv("masking.patterns", "MCP_GATEWAY_MASKING_PATTERNS")
+
+		util.MustBindPFlag("masking.excludedProxies", flags.Lookup("masking-excluded-proxies"))
+		util.MustBindEnv("masking.excludedProxies", "MCP_GATEWAY_MASKING_EXCLUDED_PROXIES")
+
+		util.MustBindPFlag("auditLog.enabled", flags.Lookup("audit-log-enabled"))
+		util.MustBindEnv("auditLog.enabled", "MCP_GATEWAY_AUDIT_LOG_ENABLED")
+
+		util.MustBindPFlag("trace.enabled", flags.Lookup("trace-enabled"))
+		util.MustBindEnv("trace.enabled", "MCP_GATEWAY_TRACE_ENABLED")
+
+		util.MustBindPFlag("trace.retention", flags.Lookup("trace-retention"))
+		util.MustBindEnv("trace.retention", "MCP_GATEWAY_TRACE_RETENTION")
+
+		util.MustBindPFlag("slo.enabled", flags.Lookup("slo-enabled"))
+		util.MustBindEnv("slo.enabled", "MCP_GATEWAY_SLO_ENABLED")
+
+		util.MustBindPFlag("slo.availability", flags.Lookup("slo-availability"))
+		util.MustBindEnv("slo.availability", "MCP_GATEWAY_SLO_AVAILABILITY")
+
+		util.MustBindPFlag("slo.latency", flags.Lookup("slo-latency"))
+		util.MustBindEnv("slo.latency", "MCP_GATEWAY_SLO_LATENCY")
+
+		util.MustBindPFlag("slo.latencyThreshold", flags.Lookup("slo-latency-threshold"))
+		util.MustBindEnv("slo.latencyThreshold", "MCP_GATEWAY_SLO_LATENCY_THRESHOLD")
+
+		util.MustBindPFlag("slo.window", flags.Lookup("slo-window"))
+		util.MustBindEnv("slo.window", "MCP_GATEWAY_SLO_WINDOW")
+
+		util.MustBindPFlag("slo.objectives", flags.Lookup("slo-objectives"))
+		util.MustBindEnv("slo.objectives", "MCP_GATEWAY_SLO_OBJECTIVES")
+
+		util.MustBindPFlag("crypto.backend", flags.Lookup("crypto-backend"))
+		util.MustBindEnv("crypto.backend", "MCP_GATEWAY_CRYPTO_BACKEND")
+
+		util.MustBindPFlag("authCache.url", flags.Lookup("auth-cache-url"))
+		util.MustBindEnv("authCache.url", "MCP_GATEWAY_AUTH_CACHE_URL")
+
+		util.MustBindPFlag("authCache.password", flags.Lookup("auth-cache-password"))
+		util.MustBindEnv("authCache.password", "MCP_GATEWAY_AUTH_CACHE_PASSWORD")
+
+		util.MustBindPFlag("authCache.prefix", flags.Lookup("auth-cache-prefix"))
+		util.MustBindEnv("authCache.prefix", "MCP_GATEWAY_AUTH_CACHE_PREFIX")
+
+		util.MustBindPFlag("authCache.ttl", flags.Lookup("auth-cache-ttl"))
+		util.MustBindEnv("authCache.ttl", "MCP_GATEWAY_AUTH_CACHE_TTL")
+
+		util.MustBindPFlag("authCache.tokenTtl", flags.Lookup("auth-cache-token-ttl"))
+		util.MustBindEnv("authCache.tokenTtl", "MCP_GATEWAY_AUTH_CACHE_TOKEN_TTL")
+
+		util.MustBindPFlag("authCache.timeout", flags.Lookup("auth-cache-timeout"))
+		util.MustBindEnv("authCache.timeout", "MCP_GATEWAY_AUTH_CACHE_TIMEOUT")
+
+		util.MustBindPFlag("session.stateful", flags.Lookup("session-stateful"))
+		util.MustBindEnv("session.stateful", "MCP_GATEWAY_SESSION_STATEFUL")
+
+		util.MustBindPFlag("session.store", flags.Lookup("session-store"))
+		util.MustBindEnv("session.store", "MCP_GATEWAY_SESSION_STORE")
+
+		util.MustBindPFlag("session.redisUrl", flags.Lookup("session-redis-url"))
+		util.MustBindEnv("session.redisUrl", "MCP_GATEWAY_SESSION_REDIS_URL")
+
+		util.MustBindPFlag("session.redisPassword", flags.Lookup("session-redis-password"))
+		util.MustBindEnv("session.redisPassword", "MCP_GATEWAY_SESSION_REDIS_PASSWORD")
+
+		util.MustBindPFlag("session.idleTimeout", flags.Lookup("session-idle-timeout"))
+		util.MustBindEnv("session.idleTimeout", "MCP_GATEWAY_SESSION_IDLE_TIMEOUT")
 	}
 }
@@ -2,15 +2,33 @@
 package serve
 
 import (
+	"context"
+	"errors"
 	"fmt"
-
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/cmd/util"
 	"github.com/matthisholleville/mcp-gateway/internal/cfg"
 	"github.com/matthisholleville/mcp-gateway/internal/server"
 	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/matthisholleville/mcp-gateway/pkg/signals"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
+// profileKey is the key of the profile selecting the overlay of the config file, set with the --profile flag of
+// the root command.
+const profileKey = "profile"
+
+// shutdownGracePeriod is the time left to stop the servers once the in-flight tool calls are drained.
+const shutdownGracePeriod = 10 * time.Second
+
 // NewRunCommand creates a new run command.
 func NewRunCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -20,10 +38,17 @@ func NewRunCommand() *cobra.Command {
 		Run:   run,
 		Args:  cobra.NoArgs,
 	}
+	AddFlags(cmd)
+	return cmd
+}
 
+// AddFlags adds the server configuration flags to the command, bound to the config before it runs.
+func AddFlags(cmd *cobra.Command) {
 	defaultConfig := cfg.DefaultConfig()
 	flags := cmd.Flags()
 
+	flags.Bool("dev", defaultConfig.Dev.Enabled, "Start a sample MCP server in the process and register it as the 'dev' proxy, to try the gateway without external servers (memory backend only)")
+
 	flags.String("http-addr", defaultConfig.HTTP.Addr, "The address to listen on for HTTP requests")
 
 	flags.String("log-format", defaultConfig.Log.Format, "The format to use for logging")
@@ -32,10 +57,71 @@ func NewRunCommand() *cobra.Command {
 
 	flags.String("log-timestamp-format", defaultConfig.Log.TimestampFormat, "The format to use for logging timestamps")
 
+	flags.Bool("log-redaction-enabled", defaultConfig.Log.Redaction.Enabled, "Whether to redact sensitive tool call arguments and results in the logs")
+
+	flags.StringSlice("log-redaction-key-patterns", defaultConfig.Log.Redaction.KeyPatterns, "The case-insensitive regular expressions matching argument keys to redact")
+
+	flags.Int("log-redaction-max-length", defaultConfig.Log.Redaction.MaxLength, "The maximum length of logged argument and result values (0 disables truncation)")
+
+	flags.StringSlice("log-redaction-excluded-proxies", defaultConfig.Log.Redaction.ExcludedProxies, "The proxies whose arguments and results are logged without redaction")
+
+	flags.Int("log-sampling-initial", defaultConfig.Log.Sampling.Initial, "The number of log entries with the same level and message logged each second before sampling (0 disables the sampling)")
+
+	flags.Int("log-sampling-thereafter", defaultConfig.Log.Sampling.Thereafter, "Once the initial entries are logged, log one entry out of this number each second (0 drops all of them)")
+
+	flags.StringSlice("log-output-paths", defaultConfig.Log.OutputPaths, "Where the logs are written: 'stdout', 'stderr' or file paths")
+
+	flags.Int("log-rotation-max-size", defaultConfig.Log.Rotation.MaxSize, "The size in megabytes at which a log file is rotated (0 disables the rotation)")
+
+	flags.Int("log-rotation-max-age", defaultConfig.Log.Rotation.MaxAge, "The number of days the rotated log files are kept (0 keeps them regardless of their age)")
+
+	flags.Int("log-rotation-max-backups", defaultConfig.Log.Rotation.MaxBackups, "The number of rotated log files kept (0 keeps them all)")
+
+	flags.Bool("log-rotation-compress", defaultConfig.Log.Rotation.Compress, "Gzip the rotated log files")
+
+	flags.Bool("log-syslog-enabled", defaultConfig.Log.Syslog.Enabled, "Also send the logs to syslog, or to journald through its syslog socket")
+
+	flags.String("log-syslog-network", defaultConfig.Log.Syslog.Network, "The network of the syslog daemon: 'udp', 'tcp' or 'unix'")
+
+	flags.String("log-syslog-address", defaultConfig.Log.Syslog.Address, "The address of the syslog daemon (e.g. syslog:514), the local daemon when empty")
+
+	flags.String("log-syslog-tag", defaultConfig.Log.Syslog.Tag, "The tag prefixing the syslog messages")
+
+	flags.String("log-syslog-facility", defaultConfig.Log.Syslog.Facility, "The syslog facility of the messages (e.g. daemon or local0)")
+
 	flags.Duration("proxy-cache-ttl", defaultConfig.Proxy.CacheTTL, "The TTL for the proxy cache")
 
 	flags.Duration("proxy-heartbeat-interval", defaultConfig.Proxy.Heartbeat.Interval, "The interval for the proxy heartbeat")
 
+	flags.Duration("proxy-call-timeout", defaultConfig.Proxy.CallTimeout, "The maximum duration of a proxied tool call")
+
+	flags.Int("proxy-max-concurrent-calls", defaultConfig.Proxy.MaxConcurrentCalls, "The maximum number of tool calls in flight in the gateway, the calls beyond it are rejected with 429 (0 disables the cap)")
+
+	flags.Duration("proxy-queue-timeout", defaultConfig.Proxy.QueueTimeout, "How long a tool call waits for a slot when the maximum number of concurrent calls is reached")
+
+	flags.Bool("proxy-leader-election", defaultConfig.Proxy.LeaderElection, "Whether a single replica, elected among the gateways sharing the postgres backend, lists the tools of the upstream servers")
+
+	flags.Bool("proxy-ready-after-sync", defaultConfig.Proxy.ReadyAfterSync, "Whether /ready returns 503 until the backend is reachable and the first refresh of the proxies completed")
+
+	flags.Bool("proxy-upstream-sessions", defaultConfig.Proxy.UpstreamSessions, "Whether each client of the gateway gets its own session on the stateful upstream servers")
+
+	flags.Duration("proxy-upstream-session-idle-timeout", defaultConfig.Proxy.UpstreamSessionIdleTimeout, "How long an unused upstream session of a client is kept")
+
+	flags.Int("proxy-max-upstream-sessions", defaultConfig.Proxy.MaxUpstreamSessions, "The maximum number of upstream sessions of the clients, the least recently used are closed beyond it")
+
+	flags.Duration("proxy-upstream-keepalive-interval", defaultConfig.Proxy.UpstreamKeepaliveInterval, "How long an upstream session of a client is unused before it is pinged, unless a proxy sets its own (0 for no ping)")
+
+	flags.Bool("proxy-describe-tools", defaultConfig.Proxy.DescribeTools, "Whether to append the description, owner and documentation link of the proxies to the descriptions of their tools")
+	flags.Bool("proxy-meta-tools", defaultConfig.Proxy.MetaTools, "Whether to expose the gateway:list_proxies, gateway:whoami and gateway:describe_tool tools, granted like the tools of the proxies")
+
+	flags.Int("proxy-max-response-bytes", defaultConfig.Proxy.MaxResponseBytes, "The maximum size of the tool results, unless a proxy sets its own (0 for no limit)")
+
+	flags.String("proxy-response-size-policy", defaultConfig.Proxy.ResponseSizePolicy, "What to do with the tool results larger than the maximum size: 'truncate' or 'reject'")
+
+	flags.Bool("proxy-reconnect-on-dns-change", defaultConfig.Proxy.ReconnectOnDNSChange, "Whether to resolve the hostnames of the upstream servers on each refresh, and reconnect to the ones whose addresses changed")
+
+	flags.String("proxy-egress-url", defaultConfig.Proxy.EgressProxy, "The URL of the HTTP, HTTPS or SOCKS5 proxy through which to connect to the upstream servers, unless a proxy sets its own")
+
 	flags.Bool("oauth-enabled", defaultConfig.OAuth.Enabled, "Whether to enable OAuth")
 
 	flags.StringSlice("oauth-authorization-servers", defaultConfig.OAuth.AuthorizationServers, "The authorization servers for OAuth")
@@ -46,10 +132,22 @@ func NewRunCommand() *cobra.Command {
 
 	flags.StringSlice("oauth-scopes-supported", defaultConfig.OAuth.ScopesSupported, "The scopes supported for OAuth")
 
+	flags.StringSlice("oauth-audiences", defaultConfig.OAuth.Audiences, "The audiences accepted in the tokens, the OAuth resource if empty, '*' for any")
+
+	flags.Bool("oauth-proxy-authorization-server-metadata", defaultConfig.OAuth.ProxyAuthorizationServerMetadata, "Whether to serve the metadata of the authorization server on the gateway")
+
 	flags.Bool("auth-provider-enabled", defaultConfig.AuthProvider.Enabled, "Whether to enable the auth provider")
 
 	flags.String("auth-provider-name", defaultConfig.AuthProvider.Name, "The name of the auth provider")
 
+	flags.Duration("auth-provider-health-check-interval", defaultConfig.AuthProvider.HealthCheckInterval, "The interval between two checks that the auth provider can verify the tokens (issuer metadata, JWKS), whose failures make the gateway not ready. Disabled when 0")
+
+	flags.Duration("auth-provider-clock-skew", defaultConfig.AuthProvider.ClockSkew, "The tolerance on the exp, nbf and iat claims of the tokens, for the clock of the issuer not matching the clock of the gateway")
+
+	flags.Duration("auth-provider-max-token-age", defaultConfig.AuthProvider.MaxTokenAge, "The maximum age of the tokens, per their iat claim, even if they have not expired. Disabled when 0")
+
+	flags.StringSlice("auth-provider-required-claims", defaultConfig.AuthProvider.RequiredClaims, "The claims the tokens must have, whatever the provider, written as CLAIM=VALUE, e.g. email_verified=true or amr=mfa")
+
 	flags.String("backend-engine", defaultConfig.BackendConfig.Engine, "The engine to use for the auth backend")
 
 	flags.String("backend-uri", defaultConfig.BackendConfig.URI, "The URI to use for the auth backend")
@@ -58,6 +156,10 @@ func NewRunCommand() *cobra.Command {
 
 	flags.String("backend-password", defaultConfig.BackendConfig.Password, "The password to use for the auth backend. It will override the password in the URI if provided.")
 
+	flags.String("backend-schema", defaultConfig.BackendConfig.Schema, "The Postgres schema of the gateway tables, created by the migrations")
+
+	flags.Bool("backend-pgbouncer", defaultConfig.BackendConfig.PgBouncer, "Send the queries without prepared statements, to work behind a PgBouncer in transaction pooling mode")
+
 	flags.Int("backend-max-open-conns", defaultConfig.BackendConfig.MaxOpenConns, "The maximum number of open connections to the database")
 
 	flags.Int("backend-max-idle-conns", defaultConfig.BackendConfig.MaxIdleConns, "The maximum number of connections to the datastore in the idle connection pool")
@@ -66,8 +168,54 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("backend-conn-max-lifetime", defaultConfig.BackendConfig.ConnMaxLifetime, "The maximum amount of time a connection to the datastore may be reused")
 
+	flags.Int("backend-connect-retries", defaultConfig.BackendConfig.ConnectRetries, "How many times to retry connecting to the backend unreachable at startup, the gateway not being ready meanwhile, before exiting (0 exits at once)")
+
+	flags.Duration("backend-connect-backoff", defaultConfig.BackendConfig.ConnectBackoff, "The delay before the first retry to connect to the backend, doubled at each retry up to 30s")
+
 	flags.String("backend-encryption-key", defaultConfig.BackendConfig.EncryptionKey, "The key used to encrypt and decrypt data")
 
+	flags.String("backend-encryption-key-id", defaultConfig.BackendConfig.EncryptionKeyID, "The ID of the encryption key, embedded in the data it encrypts so the key can be rotated")
+
+	flags.StringSlice("backend-previous-encryption-keys", defaultConfig.BackendConfig.PreviousEncryptionKeys, "The rotated keys still used to decrypt data, written as ID:HEXKEY")
+
+	flags.String("backend-encryption-provider", defaultConfig.BackendConfig.Encryption.Provider, "The provider protecting the data keys: 'local', 'aws-kms', 'gcp-kms' or 'azure-keyvault'")
+
+	flags.String("backend-encryption-kms-key", defaultConfig.BackendConfig.Encryption.KMSKey, "The KMS key wrapping the data keys: an AWS KMS key ARN, a GCP KMS CryptoKey resource name or an Azure Key Vault key URL")
+
+	flags.Duration("backend-encryption-kms-timeout", defaultConfig.BackendConfig.Encryption.KMSTimeout, "The timeout of the calls to the KMS")
+
+	flags.String("vault-address", defaultConfig.Vault.Address, "The address of the Vault server resolving the vault:PATH#FIELD secret references of the proxy headers")
+
+	flags.String("vault-namespace", defaultConfig.Vault.Namespace, "The Vault namespace")
+
+	flags.String("vault-auth-method", defaultConfig.Vault.AuthMethod, "The method used to authenticate to Vault: 'token' or 'kubernetes'")
+
+	flags.String("vault-token", defaultConfig.Vault.Token, "The Vault token of the token auth method")
+
+	flags.String("vault-kubernetes-role", defaultConfig.Vault.KubernetesRole, "The Vault role of the kubernetes auth method")
+
+	flags.String("vault-kubernetes-mount", defaultConfig.Vault.KubernetesMount, "The mount path of the kubernetes auth method")
+
+	flags.Duration("vault-cache-ttl", defaultConfig.Vault.CacheTTL, "How long the Vault secrets without lease are cached")
+
+	flags.Duration("vault-timeout", defaultConfig.Vault.Timeout, "The timeout of the requests to Vault")
+
+	flags.StringSlice("secrets-allowed-env", defaultConfig.Secrets.AllowedEnv, "The patterns of the environment variables the ${env:NAME} references of the proxy headers may read (e.g. GITHUB_TOKEN,SLACK_*)")
+
+	flags.StringSlice("secrets-allowed-dirs", defaultConfig.Secrets.AllowedDirs, "The directories of the files the ${file:PATH} references of the proxy headers may read")
+
+	flags.Bool("metrics-role-label", defaultConfig.Metrics.RoleLabel, "Count the tool calls by the role which allowed them")
+
+	flags.Bool("metrics-subject-label", defaultConfig.Metrics.SubjectLabel, "Count the tool calls by a hash of the caller's subject")
+
+	flags.Int("metrics-max-label-values", defaultConfig.Metrics.MaxLabelValues, "The maximum distinct values of each identity label of the metrics, the next ones are counted as \"other\"")
+
+	flags.String("metrics-otlp-endpoint", defaultConfig.Metrics.OTLPEndpoint, "The URL to push the metrics to with OTLP over HTTP (e.g. http://otel-collector:4318/v1/metrics), in addition to /metrics")
+
+	flags.StringSlice("metrics-otlp-headers", defaultConfig.Metrics.OTLPHeaders, "The headers sent with each OTLP push, written as NAME=VALUE")
+
+	flags.Duration("metrics-otlp-interval", defaultConfig.Metrics.OTLPInterval, "The interval between two OTLP pushes")
+
 	flags.String("okta-issuer", defaultConfig.AuthProvider.Okta.Issuer, "The issuer for the Okta auth provider")
 
 	flags.String("okta-org-url", defaultConfig.AuthProvider.Okta.OrgURL, "The org URL for the Okta auth provider")
@@ -78,11 +226,157 @@ func NewRunCommand() *cobra.Command {
 
 	flags.String("okta-private-key-id", defaultConfig.AuthProvider.Okta.PrivateKeyID, "The private key ID for the Okta auth provider")
 
+	flags.String("okta-audience", defaultConfig.AuthProvider.Okta.Audience, "The audience the aud claim of the Okta tokens must contain (e.g. api://default)")
+
+	flags.String("okta-token-client-id", defaultConfig.AuthProvider.Okta.TokenClientID, "The client ID the cid claim of the Okta tokens must name, the client the tokens are issued to")
+
+	flags.StringSlice("okta-required-claims", defaultConfig.AuthProvider.Okta.RequiredClaims, "The other claims the Okta tokens must have, written as CLAIM=VALUE, checked with --auth-provider-required-claims")
+
+	flags.String("firebase-project-id", defaultConfig.AuthProvider.Firebase.ProjectID, "The Firebase project whose ID tokens are accepted")
+
+	flags.Bool("firebase-app-check", defaultConfig.AuthProvider.Firebase.AppCheck, "Require a Firebase App Check token in the X-Firebase-AppCheck header alongside the ID token")
+
+	flags.String("firebase-project-number", defaultConfig.AuthProvider.Firebase.ProjectNumber, "The number of the Firebase project the App Check tokens must be issued for")
+
 	flags.String("http-admin-api-key", defaultConfig.HTTP.AdminAPIKey, "The admin API key for the HTTP server. Using to configure the MCP Gateway API.")
 
-	cmd.PreRun = bindServeFlagsFunc(flags)
+	flags.String("http-admin-api-key-hash", defaultConfig.HTTP.AdminAPIKeyHash, "The Argon2id or bcrypt hash of the admin API key, taking precedence over the admin API key (see the hash-api-key command)")
 
-	return cmd
+	flags.StringSlice("http-admin-allowed-cidrs", defaultConfig.HTTP.AdminIPAccess.AllowedCIDRs, "The networks allowed to reach the admin API. Empty allows every network.")
+
+	flags.StringSlice("http-admin-denied-cidrs", defaultConfig.HTTP.AdminIPAccess.DeniedCIDRs, "The networks denied access to the admin API")
+
+	flags.Bool("http-admin-protect-metrics", defaultConfig.HTTP.AdminIPAccess.ProtectMetrics, "Whether to apply the admin IP access list to the metrics endpoint")
+
+	flags.Bool("http-admin-protect-swagger", defaultConfig.HTTP.AdminIPAccess.ProtectSwagger, "Whether to apply the admin IP access list to the Swagger endpoint")
+
+	flags.Bool("http-admin-ui-enabled", defaultConfig.HTTP.AdminUI, "Whether to serve the web admin UI on /ui")
+
+	flags.StringSlice("http-trusted-proxies", defaultConfig.HTTP.TrustedProxies, "The networks of the load balancers allowed to set X-Forwarded-For and X-Real-IP")
+
+	flags.Duration("http-read-header-timeout", defaultConfig.HTTP.Timeouts.ReadHeader, "The maximum duration to read the request headers")
+
+	flags.Duration("http-idle-timeout", defaultConfig.HTTP.Timeouts.Idle, "The maximum duration a keep-alive connection waits for the next request")
+
+	flags.Duration("http-mcp-timeout", defaultConfig.HTTP.Timeouts.MCP, "The maximum duration of a /mcp request or an admin event stream")
+
+	flags.Duration("http-admin-timeout", defaultConfig.HTTP.Timeouts.Admin, "The maximum duration of an admin API request")
+
+	flags.Duration("http-drain-timeout", defaultConfig.HTTP.Timeouts.Drain, "How long the in-flight tool calls may run on shutdown before they are aborted")
+
+	flags.Duration("http-keepalive-interval", defaultConfig.HTTP.Timeouts.Keepalive, "The interval of the pings sent on the /mcp streams of the clients, 0 to disable them")
+
+	flags.Bool("grpc-enabled", defaultConfig.GRPC.Enabled, "Whether to expose the management API over gRPC")
+
+	flags.String("grpc-addr", defaultConfig.GRPC.Addr, "The address to listen on for gRPC requests")
+
+	flags.Bool("debug-enabled", defaultConfig.Debug.Enabled, "Whether to expose the pprof profiles, goroutine dump and GC statistics under /v1/debug of the admin API")
+
+	flags.String("debug-addr", defaultConfig.Debug.Addr, "The address to serve the debug endpoints on instead of the admin API, without authentication (e.g. 127.0.0.1:6060)")
+
+	flags.String("event-export-sink", defaultConfig.EventExport.Sink, "The sink the gateway events are exported to: kafka (through a Kafka REST Proxy) or nats. Disabled when empty")
+
+	flags.String("event-export-url", defaultConfig.EventExport.URL, "The URL of the Kafka REST Proxy (http, https) or of the NATS server (nats, tls)")
+
+	flags.String("event-export-topic", defaultConfig.EventExport.Topic, "The Kafka topic, or the prefix of the NATS subjects followed by the event type")
+
+	flags.String("event-export-format", defaultConfig.EventExport.Format, "The format of the exported events: json or cloudevents")
+
+	flags.StringSlice("event-export-types", defaultConfig.EventExport.Types, "The exported event types (tool_call, proxy_health, admin_mutation, approval), all when empty")
+
+	flags.String("event-export-source", defaultConfig.EventExport.Source, "The source attribute of the exported CloudEvents")
+
+	flags.String("event-export-username", defaultConfig.EventExport.Username, "The username authenticating to the event export sink")
+
+	flags.String("event-export-password", defaultConfig.EventExport.Password, "The password authenticating to the event export sink")
+
+	flags.String("event-export-token", defaultConfig.EventExport.Token, "The token authenticating to the event export sink")
+
+	flags.Duration("event-export-timeout", defaultConfig.EventExport.Timeout, "The maximum duration to publish an exported event")
+
+	flags.Int("event-export-buffer-size", defaultConfig.EventExport.BufferSize, "The number of events waiting to be exported, the next ones are dropped when full")
+
+	flags.Duration("approval-timeout", defaultConfig.Approval.Timeout, "How long a tool call requiring approval waits for the decision before it fails, lower than --http-mcp-timeout")
+
+	flags.String("approval-webhook-url", defaultConfig.Approval.WebhookURL, "The URL receiving each approval request as JSON")
+
+	flags.String("approval-slack-webhook-url", defaultConfig.Approval.SlackWebhookURL, "The Slack incoming webhook notified of each approval request")
+
+	flags.Bool("maintenance-enabled", defaultConfig.Maintenance.Enabled, "Whether to reject the tool calls for a maintenance, tools/list and the admin APIs keep working")
+
+	flags.String("maintenance-message", defaultConfig.Maintenance.Message, "The error returned to the tool calls rejected during a maintenance")
+
+	flags.Bool("screening-enabled", defaultConfig.Screening.Enabled, "Whether to screen the tool call arguments for deny patterns before they are forwarded to the upstream servers")
+
+	flags.StringSlice("screening-rules", defaultConfig.Screening.Rules, "The built-in deny pattern sets (secrets, sql-injection, shell-injection, jailbreak)")
+
+	flags.StringSlice("screening-deny-patterns", defaultConfig.Screening.DenyPatterns, "Additional regular expressions denied in the tool call argument keys and string values")
+
+	flags.String("screening-action", defaultConfig.Screening.Action, "What to do with the matching tool calls: 'block' rejects them, 'flag' logs and counts them")
+
+	flags.StringSlice("screening-excluded-proxies", defaultConfig.Screening.ExcludedProxies, "The proxies whose tool calls are not screened")
+
+	flags.String("guardrail-url", defaultConfig.Guardrail.URL, "The URL of the external policy service checking the tool calls and their results, disabled when empty")
+
+	flags.String("guardrail-token", defaultConfig.Guardrail.Token, "The bearer token sent to the guardrail policy service")
+
+	flags.StringSlice("guardrail-stages", defaultConfig.Guardrail.Stages, "The guardrail checks: 'request' before forwarding the tool calls, 'response' after receiving their results")
+
+	flags.Duration("guardrail-timeout", defaultConfig.Guardrail.Timeout, "The timeout of each call to the guardrail policy service")
+
+	flags.Bool("guardrail-fail-open", defaultConfig.Guardrail.FailOpen, "Whether to forward the tool calls and return their results when the guardrail policy service fails, instead of rejecting them")
+
+	flags.StringSlice("guardrail-excluded-proxies", defaultConfig.Guardrail.ExcludedProxies, "The proxies whose tool calls are not checked by the guardrail")
+
+	flags.Bool("masking-enabled", defaultConfig.Masking.Enabled, "Whether to mask the secrets in the tool results before they are returned to the clients")
+
+	flags.StringSlice("masking-patterns", defaultConfig.Masking.Patterns, "The regular expressions matching the secrets to mask in the tool results")
+
+	flags.StringSlice("masking-excluded-proxies", defaultConfig.Masking.ExcludedProxies, "The proxies whose tool results are returned without masking")
+
+	flags.Bool("audit-log-enabled", defaultConfig.AuditLog.Enabled, "Whether to chain the tool calls, admin mutations and approvals with hashes in the tamper-evident audit log")
+
+	flags.Bool("trace-enabled", defaultConfig.Trace.Enabled, "Whether to keep the detailed traces of the tool calls (timings, redacted arguments, result size) in the storage backend")
+
+	flags.Duration("trace-retention", defaultConfig.Trace.Retention, "How long the traces of the tool calls are kept before they are purged")
+
+	flags.Bool("slo-enabled", defaultConfig.SLO.Enabled, "Whether to export the availability and latency indicators, error budgets and burn rates of each proxy with the metrics")
+
+	flags.Float64("slo-availability", defaultConfig.SLO.Availability, "The objective of the ratio of the tool calls of a proxy which did not fail")
+
+	flags.Float64("slo-latency", defaultConfig.SLO.Latency, "The objective of the ratio of the tool calls of a proxy which completed within the latency threshold")
+
+	flags.Duration("slo-latency-threshold", defaultConfig.SLO.LatencyThreshold, "The duration under which a tool call is fast enough for the latency objective")
+
+	flags.Duration("slo-window", defaultConfig.SLO.Window, "The period over which the indicators and the error budgets are computed")
+
+	flags.StringSlice("slo-objectives", defaultConfig.SLO.Objectives, "The objectives of some proxies, written as PROXY:SLI=OBJECTIVE (e.g. github:availability=0.995)")
+
+	flags.String("crypto-backend", defaultConfig.Crypto.Backend, "The cryptographic implementation: 'standard', or 'fips' for the FIPS 140-3 validated Go Cryptographic Module, also restricting the TLS connections")
+
+	flags.String("auth-cache-url", defaultConfig.AuthCache.URL, "The URL of the Redis server caching the roles, mappings and verified tokens for all the replicas (redis, rediss). Disabled when empty")
+
+	flags.String("auth-cache-password", defaultConfig.AuthCache.Password, "The password of the Redis server, instead of the one of the URL")
+
+	flags.String("auth-cache-prefix", defaultConfig.AuthCache.Prefix, "The prefix of the Redis keys and of the invalidation channel")
+
+	flags.Duration("auth-cache-ttl", defaultConfig.AuthCache.TTL, "How long the roles and the attribute-to-roles mappings are cached")
+
+	flags.Duration("auth-cache-token-ttl", defaultConfig.AuthCache.TokenTTL, "How long a verified token is cached, at most until it expires (0 disables the cache of the tokens)")
+
+	flags.Duration("auth-cache-timeout", defaultConfig.AuthCache.Timeout, "The maximum duration of a Redis command, after which the storage or the auth provider is used")
+
+	flags.Bool("session-stateful", defaultConfig.Session.Stateful, "Whether to issue an Mcp-Session-Id on initialize, required by the following MCP requests and echoed on every response for the load balancers")
+
+	flags.String("session-store", defaultConfig.Session.Store, "The store of the MCP sessions: 'memory', only known by the replica which created them, or 'redis', resumable by any replica")
+
+	flags.String("session-redis-url", defaultConfig.Session.RedisURL, "The URL of the Redis server of the redis session store (redis, rediss)")
+
+	flags.String("session-redis-password", defaultConfig.Session.RedisPassword, "The password of the Redis server of the session store, instead of the one of the URL")
+
+	flags.Duration("session-idle-timeout", defaultConfig.Session.IdleTimeout, "How long an MCP session lasts without requests")
+
+	cmd.PreRun = bindServeFlagsFunc(flags)
 }
 
 // ReadConfig reads the config from the file.
@@ -95,6 +389,18 @@ func ReadConfig() (*cfg.Config, error) {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("failed to load server config: %w", err)
 		}
+	} else if err := mergeConfigFile(viper.ConfigFileUsed()); err != nil {
+		return nil, fmt.Errorf("failed to load server config: %w", err)
+	}
+
+	if profile := viper.GetString(profileKey); profile != "" {
+		if err := mergeProfile(profile); err != nil {
+			return nil, fmt.Errorf("failed to load server config: %w", err)
+		}
+	}
+
+	if err := util.ReadEnvFiles(); err != nil {
+		return nil, fmt.Errorf("failed to load server config: %w", err)
 	}
 
 	if err := viper.Unmarshal(config); err != nil {
@@ -104,6 +410,44 @@ func ReadConfig() (*cfg.Config, error) {
 	return config, nil
 }
 
+// mergeConfigFile merges a config file into the config, with its ${NAME} references to environment variables
+// replaced. The file is read on its own, so the values of the flags and the environment bindings are not expanded.
+func mergeConfigFile(file string) error {
+	fileConfig := viper.New()
+	// As the global config, the file is read as YAML whatever its extension.
+	fileConfig.SetConfigType("yaml")
+	fileConfig.SetConfigFile(file)
+	if err := fileConfig.ReadInConfig(); err != nil {
+		return err
+	}
+	settings, err := cfg.ExpandEnv(fileConfig.AllSettings(), os.LookupEnv)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return viper.MergeConfigMap(settings)
+}
+
+// profileName matches the names of the profiles, which are part of a file name.
+var profileName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// mergeProfile merges the overlay of a profile, config.PROFILE.yaml next to config.yaml, into the config: its
+// settings override those of the config file, the maps being merged and the lists replaced.
+func mergeProfile(profile string) error {
+	if !profileName.MatchString(profile) {
+		return fmt.Errorf("invalid profile %q, must only contain letters, digits, '-' and '_'", profile)
+	}
+	file := viper.ConfigFileUsed()
+	if file == "" {
+		return fmt.Errorf("the profile %q requires a config file", profile)
+	}
+	ext := filepath.Ext(file)
+	overlay := strings.TrimSuffix(file, ext) + "." + profile + ext
+	if _, err := os.Stat(overlay); err != nil {
+		return fmt.Errorf("the config file of the profile %q: %w", profile, err)
+	}
+	return mergeConfigFile(overlay)
+}
+
 func run(_ *cobra.Command, _ []string) {
 	config, err := ReadConfig()
 	if err != nil {
@@ -113,13 +457,57 @@ func run(_ *cobra.Command, _ []string) {
 	if err := config.Verify(); err != nil {
 		panic(err)
 	}
-	log := logger.MustNewLogger(config.Log.Format, config.Log.Level, config.Log.TimestampFormat)
-	serverClient, err := server.NewServer(log, config)
+	logOptions := []logger.OptionLogger{
+		logger.WithFormat(config.Log.Format),
+		logger.WithLevel(config.Log.Level),
+		logger.WithTimestampFormat(config.Log.TimestampFormat),
+		logger.WithSampling(config.Log.Sampling.Initial, config.Log.Sampling.Thereafter),
+		logger.WithOutputPaths(config.Log.OutputPaths...),
+		logger.WithRotation(logger.RotationOptions{
+			MaxSizeMB:  config.Log.Rotation.MaxSize,
+			MaxAgeDays: config.Log.Rotation.MaxAge,
+			MaxBackups: config.Log.Rotation.MaxBackups,
+			Compress:   config.Log.Rotation.Compress,
+		}),
+	}
+	if config.Log.Syslog.Enabled {
+		logOptions = append(logOptions, logger.WithSyslog(logger.SyslogOptions{
+			Network:  config.Log.Syslog.Network,
+			Address:  config.Log.Syslog.Address,
+			Tag:      config.Log.Syslog.Tag,
+			Facility: config.Log.Syslog.Facility,
+		}))
+	}
+	log, err := logger.NewLogger(logOptions...)
 	if err != nil {
 		panic(err)
 	}
-	err = serverClient.ListenAndServe()
+	serverClient, err := server.NewServer(log, config)
 	if err != nil {
 		panic(err)
 	}
+	serverClient.SetConfigLoader(ReadConfig)
+	stopCh := signals.SetupSignalHandler()
+	reloadCh := signals.SetupReloadHandler()
+	go func() {
+		if err := serverClient.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			panic(err)
+		}
+	}()
+	go func() {
+		for range reloadCh {
+			log.Info("Reloading the configuration")
+			if _, err := serverClient.ReloadConfig(); err != nil {
+				log.Error("Failed to reload the configuration", zap.Error(err))
+			}
+		}
+	}()
+
+	<-stopCh
+	log.Info("Shutting down MCP Gateway")
+	ctx, cancel := context.WithTimeout(context.Background(), config.HTTP.Timeouts.Drain+shutdownGracePeriod)
+	defer cancel()
+	if err := serverClient.Shutdown(ctx); err != nil {
+		log.Warn("MCP Gateway graceful shutdown failed", zap.Error(err))
+	}
 }
@@ -0,0 +1,84 @@
+// Package snapshot provides the commands to export and import the MCP Gateway objects through the admin API.
+package snapshot
+
+import (
+	"github.com/matthisholleville/mcp-gateway/cmd/apply"
+	"github.com/matthisholleville/mcp-gateway/cmd/util"
+	"github.com/matthisholleville/mcp-gateway/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+const (
+	includeSecretsFlag = "include-secrets"
+	fileFlag           = "file"
+	pruneFlag          = "prune"
+	dryRunFlag         = "dry-run"
+)
+
+// NewExportCommand creates a new export command.
+func NewExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a snapshot of the proxies, roles and mappings",
+		Long: "Export the proxies, roles and attribute-to-roles mappings through the admin API, " +
+			"in the manifest format of the apply command. The header values, OAuth client secrets and HMAC " +
+			"secrets of the proxies are emptied unless --include-secrets is set.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			includeSecrets, _ := cmd.Flags().GetBool(includeSecretsFlag)
+			snapshot, err := util.NewAdminClient().Export(cmd.Context(), includeSecrets)
+			if err != nil {
+				return err
+			}
+			return util.Print(cmd.OutOrStdout(), snapshot)
+		},
+	}
+	flags := cmd.Flags()
+	util.AddAdminFlags(flags)
+	flags.Bool(includeSecretsFlag, false, "Include the header values, OAuth client secrets and HMAC secrets of the proxies")
+	cmd.PreRun = func(_ *cobra.Command, _ []string) {
+		util.BindAdminFlags(flags)
+	}
+	return cmd
+}
+
+// NewImportCommand creates a new import command.
+func NewImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import -f FILE...",
+		Short: "Import a snapshot of the proxies, roles and mappings",
+		Long: "Create and update the objects of snapshot files through the admin API, and print the diff. " +
+			"An empty header value or client secret keeps the value of the target gateway, " +
+			"and the ${VAR} references are expanded from the environment. " +
+			"The objects missing from the snapshot are deleted only with --prune.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			files, _ := cmd.Flags().GetStringSlice(fileFlag)
+			prune, _ := cmd.Flags().GetBool(pruneFlag)
+			dryRun, _ := cmd.Flags().GetBool(dryRunFlag)
+
+			snapshot, err := manifest.Load(files...)
+			if err != nil {
+				return err
+			}
+			changes, err := util.NewAdminClient().Import(cmd.Context(), snapshot, prune, dryRun)
+			if err != nil {
+				return err
+			}
+			apply.PrintChanges(cmd.OutOrStdout(), changes, dryRun)
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	util.AddAdminFlags(flags)
+	flags.StringSliceP(fileFlag, "f", nil, "The snapshot files to import, in YAML or JSON")
+	_ = cmd.MarkFlagRequired(fileFlag)
+	flags.Bool(pruneFlag, false, "Delete the objects missing from the snapshot")
+	flags.Bool(dryRunFlag, false, "Print the changes without applying them")
+	cmd.PreRun = func(_ *cobra.Command, _ []string) {
+		util.BindAdminFlags(flags)
+	}
+	return cmd
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/matthisholleville/mcp-gateway/internal/adminclient"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	// ServerFlag is the URL of the MCP Gateway reached by the admin commands.
+	ServerFlag = "server"
+	// APIKeyFlag is the admin API key used by the admin commands.
+	APIKeyFlag = "api-key"
+	// OutputFlag is the output format of the admin commands.
+	OutputFlag = "output"
+
+	defaultServer = "http://localhost:8082"
+)
+
+// AddAdminFlags adds the flags to reach the admin API.
+func AddAdminFlags(flags *pflag.FlagSet) {
+	flags.String(ServerFlag, defaultServer, "The URL of the MCP Gateway")
+
+	flags.String(APIKeyFlag, "", "The admin API key of the MCP Gateway")
+
+	flags.StringP(OutputFlag, "o", "yaml", "The output format (yaml or json)")
+}
+
+// BindAdminFlags binds the flags to reach the admin API.
+func BindAdminFlags(flags *pflag.FlagSet) {
+	MustBindPFlag(ServerFlag, flags.Lookup(ServerFlag))
+	MustBindEnv(ServerFlag, "MCP_GATEWAY_SERVER")
+
+	MustBindPFlag(APIKeyFlag, flags.Lookup(APIKeyFlag))
+	MustBindEnv(APIKeyFlag, "MCP_GATEWAY_HTTP_ADMIN_API_KEY")
+
+	MustBindPFlag(OutputFlag, flags.Lookup(OutputFlag))
+}
+
+// NewAdminClient creates a client of the admin API from the bound flags.
+func NewAdminClient() *adminclient.Client {
+	return adminclient.NewClient(viper.GetString(ServerFlag), viper.GetString(APIKeyFlag))
+}
+
+// Print writes the object in the output format of the bound flags.
+func Print(w io.Writer, object any) error {
+	var (
+		out []byte
+		err error
+	)
+	switch output := viper.GetString(OutputFlag); output {
+	case "json":
+		out, err = json.MarshalIndent(object, "", "  ")
+		out = append(out, '\n')
+	case "yaml":
+		out, err = yaml.Marshal(object)
+	default:
+		return fmt.Errorf("unsupported output format %q", output)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// ReadObjects reads a YAML or JSON file holding a single object or a list of objects.
+func ReadObjects[T any](path string) ([]T, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // the path is given by the user
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var objects []T
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		err = json.Unmarshal(data, &objects)
+	} else {
+		var object T
+		err = json.Unmarshal(data, &object)
+		objects = append(objects, object)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return objects, nil
+}
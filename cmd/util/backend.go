@@ -0,0 +1,97 @@
+package util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/fips"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/spf13/pflag"
+)
+
+// AddBackendFlags adds the flags to reach the backend directly.
+func AddBackendFlags(flags *pflag.FlagSet) {
+	defaultConfig := cfg.DefaultConfig()
+
+	flags.String("backend-engine", defaultConfig.BackendConfig.Engine, "The engine to use for the auth backend")
+
+	flags.String("backend-uri", defaultConfig.BackendConfig.URI, "The URI to use for the auth backend")
+
+	flags.String("backend-username", defaultConfig.BackendConfig.Username, "The username to use for the auth backend. It will override the username in the URI if provided.")
+
+	flags.String("backend-password", defaultConfig.BackendConfig.Password, "The password to use for the auth backend. It will override the password in the URI if provided.")
+
+	flags.String("backend-schema", defaultConfig.BackendConfig.Schema, "The Postgres schema of the gateway tables, created by the migrations")
+
+	flags.Bool("backend-pgbouncer", defaultConfig.BackendConfig.PgBouncer, "Send the queries without prepared statements, to work behind a PgBouncer in transaction pooling mode")
+
+	flags.String("backend-encryption-key", defaultConfig.BackendConfig.EncryptionKey, "The key used to encrypt and decrypt data")
+
+	flags.String("backend-encryption-key-id", defaultConfig.BackendConfig.EncryptionKeyID, "The ID of the encryption key, embedded in the data it encrypts so the key can be rotated")
+
+	flags.StringSlice("backend-previous-encryption-keys", defaultConfig.BackendConfig.PreviousEncryptionKeys, "The rotated keys still used to decrypt data, written as ID:HEXKEY")
+
+	flags.String("backend-encryption-provider", defaultConfig.BackendConfig.Encryption.Provider, "The provider protecting the data keys: 'local', 'aws-kms', 'gcp-kms' or 'azure-keyvault'")
+
+	flags.String("backend-encryption-kms-key", defaultConfig.BackendConfig.Encryption.KMSKey, "The KMS key wrapping the data keys: an AWS KMS key ARN, a GCP KMS CryptoKey resource name or an Azure Key Vault key URL")
+
+	flags.Duration("backend-encryption-kms-timeout", defaultConfig.BackendConfig.Encryption.KMSTimeout, "The timeout of the calls to the KMS")
+}
+
+// BindBackendFlags binds the backend flags to the same config keys as the serve command.
+func BindBackendFlags(flags *pflag.FlagSet) {
+	MustBindPFlag("backendConfig.engine", flags.Lookup("backend-engine"))
+	MustBindEnv("backendConfig.engine", "MCP_GATEWAY_BACKEND_ENGINE")
+
+	MustBindPFlag("backendConfig.uri", flags.Lookup("backend-uri"))
+	MustBindEnv("backendConfig.uri", "MCP_GATEWAY_BACKEND_URI")
+
+	MustBindPFlag("backendConfig.username", flags.Lookup("backend-username"))
+	MustBindEnv("backendConfig.username", "MCP_GATEWAY_BACKEND_USERNAME")
+
+	MustBindPFlag("backendConfig.password", flags.Lookup("backend-password"))
+	MustBindEnv("backendConfig.password", "MCP_GATEWAY_BACKEND_PASSWORD")
+
+	MustBindPFlag("backendConfig.schema", flags.Lookup("backend-schema"))
+	MustBindEnv("backendConfig.schema", "MCP_GATEWAY_BACKEND_SCHEMA")
+
+	MustBindPFlag("backendConfig.pgBouncer", flags.Lookup("backend-pgbouncer"))
+	MustBindEnv("backendConfig.pgBouncer", "MCP_GATEWAY_BACKEND_PGBOUNCER")
+
+	MustBindPFlag("backendConfig.encryptionKey", flags.Lookup("backend-encryption-key"))
+	MustBindEnv("backendConfig.encryptionKey", "MCP_GATEWAY_BACKEND_ENCRYPTION_KEY")
+
+	MustBindPFlag("backendConfig.encryptionKeyID", flags.Lookup("backend-encryption-key-id"))
+	MustBindEnv("backendConfig.encryptionKeyID", "MCP_GATEWAY_BACKEND_ENCRYPTION_KEY_ID")
+
+	MustBindPFlag("backendConfig.previousEncryptionKeys", flags.Lookup("backend-previous-encryption-keys"))
+	MustBindEnv("backendConfig.previousEncryptionKeys", "MCP_GATEWAY_BACKEND_PREVIOUS_ENCRYPTION_KEYS")
+
+	MustBindPFlag("backendConfig.encryption.provider", flags.Lookup("backend-encryption-provider"))
+	MustBindEnv("backendConfig.encryption.provider", "MCP_GATEWAY_BACKEND_ENCRYPTION_PROVIDER")
+
+	MustBindPFlag("backendConfig.encryption.kmsKey", flags.Lookup("backend-encryption-kms-key"))
+	MustBindEnv("backendConfig.encryption.kmsKey", "MCP_GATEWAY_BACKEND_ENCRYPTION_KMS_KEY")
+
+	MustBindPFlag("backendConfig.encryption.kmsTimeout", flags.Lookup("backend-encryption-kms-timeout"))
+	MustBindEnv("backendConfig.encryption.kmsTimeout", "MCP_GATEWAY_BACKEND_ENCRYPTION_KMS_TIMEOUT")
+}
+
+// NewBackendStorage opens the backend of the configuration for the command.
+// The memory backend is refused: it is local to each gateway process.
+func NewBackendStorage(ctx context.Context, command string, config *cfg.Config) (storage.Interface, error) {
+	if config.BackendConfig.Engine == "memory" {
+		return nil, fmt.Errorf("%s requires a persistent backend: the memory backend is local to each gateway process", command)
+	}
+	if config.Crypto.FIPSMode() {
+		fips.Enable()
+	}
+	encryptor, err := config.NewCryptor()
+	if err != nil {
+		return nil, fmt.Errorf("encryption: %w", err)
+	}
+	log := logger.MustNewLogger(config.Log.Format, "error", config.Log.TimestampFormat)
+	return storage.NewStorage(ctx, config.BackendConfig.Engine, "", log, config, encryptor)
+}
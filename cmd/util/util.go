@@ -4,10 +4,29 @@
 package util
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// fileSuffix is the suffix of the environment variables holding the path of a file to read the value of a bound
+// environment variable from, e.g. MCP_GATEWAY_BACKEND_URI_FILE, as mounted by the Docker and Kubernetes secrets.
+const fileSuffix = "_FILE"
+
+var (
+	envsMu sync.Mutex
+	// boundEnvs are the environment variables bound to a viper key.
+	boundEnvs = map[string]bool{}
+	// fileEnvs are the environment variables set by ReadEnvFiles, read again on each call.
+	fileEnvs = map[string]bool{}
+)
+
 // MustBindPFlag attempts to bind a specific key to a pflag (as used by cobra) and panics
 // if the binding fails with a non-nil error.
 func MustBindPFlag(key string, flag *pflag.Flag) {
@@ -21,4 +40,45 @@ func MustBindEnv(input ...string) {
 	if err := viper.BindEnv(input...); err != nil {
 		panic("failed to bind env key: " + err.Error())
 	}
+	envsMu.Lock()
+	defer envsMu.Unlock()
+	for _, env := range input[1:] {
+		boundEnvs[env] = true
+	}
+}
+
+// ReadEnvFiles sets the bound environment variables from the files referenced by their _FILE variable, without
+// the trailing newline. The flags keep precedence over them. Setting both a variable and its _FILE variable is an
+// error. The files are read again on each call, so a reload picks up the rotated secrets.
+func ReadEnvFiles() error {
+	envsMu.Lock()
+	defer envsMu.Unlock()
+	envs := make([]string, 0, len(boundEnvs))
+	for env := range boundEnvs {
+		envs = append(envs, env)
+	}
+	slices.Sort(envs)
+
+	var errs []error
+	for _, env := range envs {
+		path := os.Getenv(env + fileSuffix)
+		if path == "" {
+			continue
+		}
+		if _, ok := os.LookupEnv(env); ok && !fileEnvs[env] {
+			errs = append(errs, fmt.Errorf("%s and %s%s are both set, only one is allowed", env, env, fileSuffix))
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read %s%s: %w", env, fileSuffix, err))
+			continue
+		}
+		if err := os.Setenv(env, strings.TrimRight(string(content), "\r\n")); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set %s: %w", env, err))
+			continue
+		}
+		fileEnvs[env] = true
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,33 @@
+// Package version provides a command to print the MCP Gateway build metadata.
+package version
+
+import (
+	"fmt"
+
+	"github.com/matthisholleville/mcp-gateway/cmd/util"
+	"github.com/matthisholleville/mcp-gateway/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+// NewVersionCommand creates a new version command.
+func NewVersionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the MCP Gateway version",
+		Long:  "Print the version, git revision, build date and Go version of the MCP Gateway.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			output, _ := cmd.Flags().GetString(util.OutputFlag)
+			if output == "" {
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), version.Get())
+				return err
+			}
+			return util.Print(cmd.OutOrStdout(), version.Get())
+		},
+	}
+	cmd.Flags().StringP(util.OutputFlag, "o", "", "The output format: yaml or json (default: a single line)")
+	cmd.PreRun = func(cmd *cobra.Command, _ []string) {
+		util.MustBindPFlag(util.OutputFlag, cmd.Flags().Lookup(util.OutputFlag))
+	}
+	return cmd
+}
@@ -0,0 +1,176 @@
+// Package adminclient provides a client of the MCP Gateway admin API, used by the CLI.
+package adminclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/manifest"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Client calls the admin API of a MCP Gateway.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient creates a client of the admin API served at baseURL, authenticated with the admin API key.
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// ListRoles returns all the roles.
+func (c *Client) ListRoles(ctx context.Context) ([]storage.RoleConfig, error) {
+	var roles []storage.RoleConfig
+	err := c.do(ctx, http.MethodGet, "/v1/admin/roles", nil, &roles)
+	return roles, err
+}
+
+// GetRole returns a role by name.
+func (c *Client) GetRole(ctx context.Context, name string) (storage.RoleConfig, error) {
+	roles, err := c.ListRoles(ctx)
+	if err != nil {
+		return storage.RoleConfig{}, err
+	}
+	for _, role := range roles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+	return storage.RoleConfig{}, fmt.Errorf("role %q not found", name)
+}
+
+// UpsertRole creates or updates a role.
+func (c *Client) UpsertRole(ctx context.Context, role storage.RoleConfig) error {
+	return c.do(ctx, http.MethodPut, "/v1/admin/roles", role, nil)
+}
+
+// CreateRoleFromTemplate creates a role from a built-in template, for a proxy if not empty.
+func (c *Client) CreateRoleFromTemplate(ctx context.Context, template, name, proxy string) (storage.RoleConfig, error) {
+	var role storage.RoleConfig
+	request := map[string]string{"template": template, "name": name, "proxy": proxy}
+	err := c.do(ctx, http.MethodPost, "/v1/admin/roles/from-template", request, &role)
+	return role, err
+}
+
+// DeleteRole deletes a role.
+func (c *Client) DeleteRole(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/v1/admin/roles/"+url.PathEscape(name), nil, nil)
+}
+
+// ListAttributeToRoles returns all the attribute-to-roles mappings.
+func (c *Client) ListAttributeToRoles(ctx context.Context) ([]storage.AttributeToRolesConfig, error) {
+	var mappings []storage.AttributeToRolesConfig
+	err := c.do(ctx, http.MethodGet, "/v1/admin/attribute-to-roles", nil, &mappings)
+	return mappings, err
+}
+
+// UpsertAttributeToRoles creates or updates an attribute-to-roles mapping.
+func (c *Client) UpsertAttributeToRoles(ctx context.Context, mapping storage.AttributeToRolesConfig) error {
+	return c.do(ctx, http.MethodPut, "/v1/admin/attribute-to-roles", mapping, nil)
+}
+
+// DeleteAttributeToRoles deletes an attribute-to-roles mapping.
+func (c *Client) DeleteAttributeToRoles(ctx context.Context, attributeKey, attributeValue string) error {
+	return c.do(ctx, http.MethodDelete,
+		"/v1/admin/attribute-to-roles/"+url.PathEscape(attributeKey)+"/"+url.PathEscape(attributeValue), nil, nil)
+}
+
+// PlanAttributeToRoles returns the changes replacing all the attribute-to-roles mappings with the given ones would make.
+func (c *Client) PlanAttributeToRoles(ctx context.Context, mappings []storage.AttributeToRolesConfig) ([]manifest.MappingChange, error) {
+	var changes []manifest.MappingChange
+	err := c.do(ctx, http.MethodPost, "/v1/admin/attribute-to-roles:plan", mappings, &changes)
+	return changes, err
+}
+
+// Export returns a snapshot of the proxies, roles and attribute-to-roles mappings.
+// The secrets of the proxies are emptied unless includeSecrets is true.
+func (c *Client) Export(ctx context.Context, includeSecrets bool) (*manifest.Manifest, error) {
+	snapshot := &manifest.Manifest{}
+	err := c.do(ctx, http.MethodGet, "/v1/admin/export?secrets="+strconv.FormatBool(includeSecrets), nil, snapshot)
+	return snapshot, err
+}
+
+// Import creates and updates the objects of a snapshot, and returns the changes.
+// The objects missing from the snapshot are deleted only when prune is true, and nothing is applied on a dry run.
+func (c *Client) Import(ctx context.Context, snapshot *manifest.Manifest, prune, dryRun bool) ([]manifest.Change, error) {
+	query := url.Values{}
+	query.Set("prune", strconv.FormatBool(prune))
+	query.Set("dryRun", strconv.FormatBool(dryRun))
+	var response struct {
+		Changes []manifest.Change `json:"changes"`
+	}
+	err := c.do(ctx, http.MethodPost, "/v1/admin/import?"+query.Encode(), snapshot, &response)
+	return response.Changes, err
+}
+
+// GetConfig returns the effective configuration of the server, with the secrets redacted.
+func (c *Client) GetConfig(ctx context.Context) (map[string]any, error) {
+	var config map[string]any
+	err := c.do(ctx, http.MethodGet, "/v1/admin/config", nil, &config)
+	return config, err
+}
+
+// do sends a request to the admin API and decodes its JSON response in out, if not nil.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		var apiErr struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		message := apiErr.Error
+		if message == "" {
+			message = apiErr.Message
+		}
+		if message == "" {
+			message = http.StatusText(resp.StatusCode)
+		}
+		return fmt.Errorf("%s %s: %d %s", method, path, resp.StatusCode, message)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,152 @@
+package adminclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/manifest"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Roles(t *testing.T) {
+	roles := []storage.RoleConfig{{Name: "admin", Permissions: []storage.PermissionConfig{
+		{ObjectType: storage.ObjectTypeAll, Proxy: "*", ObjectName: "*"},
+	}}}
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"Unauthorized"}`))
+			return
+		}
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		switch {
+		case r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(roles)
+		case r.Method == http.MethodPost:
+			var request map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(storage.RoleConfig{Name: request["name"], Permissions: []storage.PermissionConfig{
+				{ObjectType: storage.ObjectTypeTools, Proxy: request["proxy"], ObjectName: "*"},
+			}})
+		case r.Method == http.MethodPut:
+			var role storage.RoleConfig
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&role))
+			if role.Name == "invalid" {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"error":"invalid object type"}`))
+			}
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL+"/", "secret")
+	listed, err := client.ListRoles(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, roles, listed)
+
+	role, err := client.GetRole(t.Context(), "admin")
+	require.NoError(t, err)
+	assert.Equal(t, roles[0], role)
+	_, err = client.GetRole(t.Context(), "unknown")
+	assert.EqualError(t, err, `role "unknown" not found`)
+
+	require.NoError(t, client.UpsertRole(t.Context(), roles[0]))
+	err = client.UpsertRole(t.Context(), storage.RoleConfig{Name: "invalid"})
+	assert.EqualError(t, err, "PUT /v1/admin/roles: 500 invalid object type")
+	created, err := client.CreateRoleFromTemplate(t.Context(), "per-proxy-user", "github-users", "github")
+	require.NoError(t, err)
+	assert.Equal(t, storage.RoleConfig{Name: "github-users", Permissions: []storage.PermissionConfig{
+		{ObjectType: storage.ObjectTypeTools, Proxy: "github", ObjectName: "*"},
+	}}, created)
+	assert.Equal(t, "POST /v1/admin/roles/from-template", requests[len(requests)-1])
+	require.NoError(t, client.DeleteRole(t.Context(), "read only"))
+	assert.Equal(t, "DELETE /v1/admin/roles/read%20only", requests[len(requests)-1])
+
+	_, err = NewClient(ts.URL, "wrong").ListRoles(t.Context())
+	assert.EqualError(t, err, "GET /v1/admin/roles: 401 Unauthorized")
+}
+
+func TestClient_AttributeToRoles(t *testing.T) {
+	mappings := []storage.AttributeToRolesConfig{{AttributeKey: "groups", AttributeValue: "dev", Roles: []string{"developer"}}}
+	changes := []manifest.MappingChange{{
+		AttributeKey: "groups", AttributeValue: "dev", Action: manifest.ActionCreate, AddedRoles: []string{"developer"}, RemovedRoles: []string{},
+	}}
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(mappings)
+		case http.MethodPost:
+			_ = json.NewEncoder(w).Encode(changes)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, "secret")
+	listed, err := client.ListAttributeToRoles(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, mappings, listed)
+	require.NoError(t, client.UpsertAttributeToRoles(t.Context(), mappings[0]))
+	require.NoError(t, client.DeleteAttributeToRoles(t.Context(), "groups", "platform/sre"))
+	planned, err := client.PlanAttributeToRoles(t.Context(), mappings)
+	require.NoError(t, err)
+	assert.Equal(t, changes, planned)
+
+	assert.Equal(t, []string{
+		"GET /v1/admin/attribute-to-roles",
+		"PUT /v1/admin/attribute-to-roles",
+		"DELETE /v1/admin/attribute-to-roles/groups/platform%2Fsre",
+		"POST /v1/admin/attribute-to-roles:plan",
+	}, requests)
+}
+
+func TestClient_Snapshot(t *testing.T) {
+	snapshot := &manifest.Manifest{Roles: []storage.RoleConfig{{Name: "developer"}}}
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(snapshot)
+		case http.MethodPost:
+			var imported manifest.Manifest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&imported))
+			assert.Equal(t, *snapshot, imported)
+			_, _ = w.Write([]byte(`{"changes":[{"kind":"role","name":"developer","action":"create"}],"dryRun":true}`))
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, "secret")
+	exported, err := client.Export(t.Context(), true)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, exported)
+
+	changes, err := client.Import(t.Context(), snapshot, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, []manifest.Change{{Kind: manifest.KindRole, Name: "developer", Action: manifest.ActionCreate}}, changes)
+
+	assert.Equal(t, []string{
+		"GET /v1/admin/export?secrets=true",
+		"POST /v1/admin/import?dryRun=true&prune=false",
+	}, requests)
+}
+
+func TestClient_GetConfig(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET /v1/admin/config", r.Method+" "+r.URL.Path)
+		_, _ = w.Write([]byte(`{"backendConfig":{"engine":"postgres","uri":"[REDACTED]"}}`))
+	}))
+	defer ts.Close()
+
+	config, err := NewClient(ts.URL, "secret").GetConfig(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"backendConfig": map[string]any{"engine": "postgres", "uri": "[REDACTED]"}}, config)
+}
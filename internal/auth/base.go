@@ -3,12 +3,14 @@ package auth
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/matthisholleville/mcp-gateway/internal/storage"
 	"github.com/matthisholleville/mcp-gateway/pkg/logger"
 	"go.uber.org/zap"
-	"golang.org/x/sync/errgroup"
 )
 
 // BaseProvider is the base provider for the MCP Gateway
@@ -17,68 +19,101 @@ type BaseProvider struct {
 	storage storage.Interface
 }
 
+// PermissionDecision explains the outcome of a permission check
+type PermissionDecision struct {
+	Allowed bool `json:"allowed"`
+	// Roles are the roles resolved from the claims.
+	Roles []string `json:"roles"`
+	// MatchedRole and MatchedPermission are the role and rule that granted the access, if any.
+	MatchedRole       string                    `json:"matchedRole,omitempty"`
+	MatchedPermission *storage.PermissionConfig `json:"matchedPermission,omitempty"`
+	// Classification is the classification level of the object, which the clearance of the matched role covers.
+	Classification storage.Classification `json:"classification,omitempty"`
+	Reason         string                 `json:"reason"`
+}
+
 // VerifyPermissions verifies the permissions of a user for a tool
 func (b *BaseProvider) VerifyPermissions(
 	ctx context.Context,
 	objectType, proxy, objectName string,
 	claims map[string]interface{},
 ) bool {
+	return b.ExplainPermissions(ctx, objectType, proxy, objectName, claims).Allowed
+}
+
+// ExplainPermissions checks the permissions of a user for a tool and returns the role and rule behind the decision
+func (b *BaseProvider) ExplainPermissions(
+	ctx context.Context,
+	objectType, proxy, objectName string,
+	claims map[string]interface{},
+) PermissionDecision {
 	b.logger.Debug("Verifying permissions",
 		zap.String("objectType", objectType),
 		zap.String("proxy", proxy),
 		zap.String("objectName", objectName),
 		zap.Any("claims", claims))
 	roles := b.attributeToRoles(ctx, claims)
+	sort.Strings(roles)
+	decision := PermissionDecision{Roles: roles}
 
 	if len(roles) == 0 {
 		b.logger.Debug("No roles found for claims", zap.Any("claims", claims))
-		return false
+		decision.Reason = "no role mapped to the claims"
+		return decision
 	}
 
 	b.logger.Debug("Found roles for claims", zap.Strings("roles", roles))
 
-	// Resolve all roles in parallel ‑ stored in a thread‑safe slice.
-	type rolePerm struct {
-		name        string
-		permissions []storage.PermissionConfig
+	// Resolve all the roles at once, sorted by name
+	list, err := b.storage.GetRoles(ctx, roles)
+	if err == nil && len(list) < len(roles) {
+		err = fmt.Errorf("role not found: %s", strings.Join(missingRoles(roles, list), ", "))
 	}
-	var (
-		mu   sync.Mutex
-		list []rolePerm
-	)
-	g, ctx := errgroup.WithContext(ctx)
-
-	for _, roleName := range roles {
-		g.Go(func() error {
-			role, err := b.storage.GetRole(ctx, roleName)
-			if err != nil {
-				return fmt.Errorf("GetRole(%s): %w", roleName, err)
-			}
-			mu.Lock()
-			list = append(list, rolePerm{roleName, role.Permissions})
-			mu.Unlock()
-			return nil
-		})
+	if err != nil {
+		b.logger.Error("role fetch failed", zap.Error(err))
+		decision.Reason = fmt.Sprintf("role fetch failed: %s", err)
+		return decision
 	}
 
-	if err := g.Wait(); err != nil {
-		b.logger.Error("role fetch failed", zap.Error(err))
-		return false
+	// Fail closed: without the labels, the classification of the object is unknown
+	labels, err := b.storage.ListClassifications(ctx)
+	if err != nil {
+		b.logger.Error("classification fetch failed", zap.Error(err))
+		decision.Reason = fmt.Sprintf("classification fetch failed: %s", err)
+		return decision
 	}
+	decision.Classification = storage.ClassificationOf(labels, proxy, objectName)
 
-	// Check if the user has the permission for the object type, object name and proxy
+	// Check if the user has the permission for the object type, object name and proxy, with the clearance for
+	// its classification
+	lacksClearance := false
+	proxyLabels := b.proxyLabels(ctx, proxy)
 	for _, r := range list {
-		for _, p := range r.permissions {
+		for _, p := range r.Permissions {
 			if b.match(string(p.ObjectType), objectType) &&
-				b.match(p.Proxy, proxy) &&
+				b.matchProxy(p.Proxy, proxy, proxyLabels) &&
 				b.match(p.ObjectName, objectName) {
-				b.logger.Debug("permission OK", zap.String("role", r.name))
-				return true
+				if !r.Clearance.Allows(decision.Classification) {
+					b.logger.Debug("clearance too low", zap.String("role", r.Name))
+					lacksClearance = true
+					break
+				}
+				b.logger.Debug("permission OK", zap.String("role", r.Name))
+				decision.Allowed = true
+				decision.MatchedRole = r.Name
+				decision.MatchedPermission = &p
+				decision.Reason = "granted by role " + r.Name
+				return decision
 			}
 		}
 	}
 
-	return false
+	if lacksClearance {
+		decision.Reason = fmt.Sprintf("the classification %s exceeds the clearance of the roles", decision.Classification)
+		return decision
+	}
+	decision.Reason = "no permission of the roles matches the request"
+	return decision
 }
 
 // match handles the wildcard "*"
@@ -86,29 +121,56 @@ func (b *BaseProvider) match(pattern, value string) bool {
 	return pattern == "*" || pattern == value
 }
 
-// attributeToRoles converts the claims into attribute to roles
+// matchProxy handles the wildcard "*" and the permissions granted on the proxies having some labels
+func (b *BaseProvider) matchProxy(pattern, proxy string, labels func() map[string]string) bool {
+	selector, ok := strings.CutPrefix(pattern, storage.ProxyLabelPrefix)
+	if !ok {
+		return b.match(pattern, proxy)
+	}
+	parsed, err := storage.ParseLabelSelector(selector)
+	if err != nil {
+		b.logger.Debug("invalid label selector", zap.String("selector", selector), zap.Error(err))
+		return false
+	}
+	return storage.MatchLabels(labels(), parsed)
+}
+
+// proxyLabels returns a function fetching the labels of a proxy once, when a permission granted on labels is checked.
+// The labels of an unknown proxy are empty.
+func (b *BaseProvider) proxyLabels(ctx context.Context, proxy string) func() map[string]string {
+	return sync.OnceValue(func() map[string]string {
+		config, err := b.storage.GetProxy(ctx, proxy, false)
+		if err != nil {
+			b.logger.Debug("proxy labels fetch failed", zap.String("proxy", proxy), zap.Error(err))
+			return nil
+		}
+		return config.Labels
+	})
+}
+
+// attributeToRoles converts the claims into attribute to roles, looking up all their values at once
 func (b *BaseProvider) attributeToRoles(
 	ctx context.Context,
 	claims map[string]interface{},
 ) []string {
-	out := make(map[string]struct{}) // set
+	var pairs []storage.AttributePair
 
 	for claim, raw := range claims {
 		switch v := raw.(type) {
 		case string:
-			b.appendRoles(out, b.lookup(ctx, claim, v))
+			pairs = append(pairs, storage.AttributePair{Key: claim, Value: v})
 
 		case bool: // true/false become "true"/"false"
-			b.appendRoles(out, b.lookup(ctx, claim, fmt.Sprintf("%t", v)))
+			pairs = append(pairs, storage.AttributePair{Key: claim, Value: fmt.Sprintf("%t", v)})
 
 		case []string:
 			for _, s := range v {
-				b.appendRoles(out, b.lookup(ctx, claim, s))
+				pairs = append(pairs, storage.AttributePair{Key: claim, Value: s})
 			}
 
 		case []interface{}:
 			for _, any := range v {
-				b.appendRoles(out, b.lookup(ctx, claim, fmt.Sprint(any)))
+				pairs = append(pairs, storage.AttributePair{Key: claim, Value: fmt.Sprint(any)})
 			}
 
 		default:
@@ -118,36 +180,39 @@ func (b *BaseProvider) attributeToRoles(
 		}
 	}
 
-	roles := make([]string, 0, len(out))
-	for r := range out {
-		roles = append(roles, r)
+	roles := []string{}
+	if len(pairs) == 0 {
+		return roles
 	}
-	return roles
-}
-
-// TODO: Actually we query the DB so multiple times (1 call perm), we could cache the results and search in memory
-func (b *BaseProvider) lookup(
-	ctx context.Context,
-	claim, value string,
-) []string {
-	mapping, err := b.storage.GetAttributeToRoles(ctx, claim, value)
+	mappings, err := b.storage.GetAttributeToRolesForClaims(ctx, pairs)
 	b.logger.Debug("looking up attribute to roles",
-		zap.String("claim", claim),
-		zap.String("value", value),
-		zap.Any("mapping", mapping),
+		zap.Int("pairs", len(pairs)),
+		zap.Any("mappings", mappings),
 		zap.Error(err))
-	if err != nil || len(mapping.Roles) == 0 {
-		b.logger.Debug("GetAttributeToRoles failed",
-			zap.String("claim", claim),
-			zap.String("value", value),
-			zap.Error(err))
-		return []string{}
+	if err != nil {
+		b.logger.Debug("GetAttributeToRolesForClaims failed", zap.Error(err))
+		return roles
 	}
-	return mapping.Roles
+
+	out := make(map[string]struct{}) // set
+	for _, mapping := range mappings {
+		for _, r := range mapping.Roles {
+			if _, ok := out[r]; !ok {
+				out[r] = struct{}{}
+				roles = append(roles, r)
+			}
+		}
+	}
+	return roles
 }
 
-func (b *BaseProvider) appendRoles(dst map[string]struct{}, roles []string) {
-	for _, r := range roles {
-		dst[r] = struct{}{}
+// missingRoles returns the names of the roles not found.
+func missingRoles(names []string, found []storage.RoleConfig) []string {
+	missing := []string{}
+	for _, name := range names {
+		if !slices.ContainsFunc(found, func(role storage.RoleConfig) bool { return role.Name == name }) {
+			missing = append(missing, name)
+		}
 	}
+	return missing
 }
@@ -201,3 +201,109 @@ func TestBaseProvider_VerifyPermissions(t *testing.T) {
 		})
 	}
 }
+
+func TestBaseProvider_ExplainPermissions(t *testing.T) {
+	engine := initData(t, []storage.AttributeToRolesConfig{
+		{AttributeKey: "Groups", AttributeValue: "dev", Roles: []string{"Reader", "Writer"}},
+	}, []storage.RoleConfig{
+		{Name: "Reader", Permissions: []storage.PermissionConfig{{ObjectType: "tools", Proxy: "*", ObjectName: "search"}}},
+		{Name: "Writer", Permissions: []storage.PermissionConfig{{ObjectType: "tools", Proxy: "*", ObjectName: "*"}}},
+	})
+	provider := BaseProvider{
+		storage: engine,
+		logger:  initLogger(),
+	}
+	claims := map[string]interface{}{"Groups": []string{"dev"}}
+
+	decision := provider.ExplainPermissions(context.Background(), "tools", "github", "search", claims)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, []string{"Reader", "Writer"}, decision.Roles)
+	assert.Equal(t, "Reader", decision.MatchedRole)
+	assert.Equal(t, &storage.PermissionConfig{ObjectType: "tools", Proxy: "*", ObjectName: "search"}, decision.MatchedPermission)
+
+	decision = provider.ExplainPermissions(context.Background(), "prompts", "github", "search", claims)
+	assert.False(t, decision.Allowed)
+	assert.Empty(t, decision.MatchedRole)
+	assert.Nil(t, decision.MatchedPermission)
+
+	decision = provider.ExplainPermissions(context.Background(), "tools", "github", "search", map[string]interface{}{"Groups": "ops"})
+	assert.False(t, decision.Allowed)
+	assert.Empty(t, decision.Roles)
+	assert.Equal(t, "no role mapped to the claims", decision.Reason)
+}
+
+func TestBaseProvider_ExplainPermissionsClassification(t *testing.T) {
+	engine := initData(t, []storage.AttributeToRolesConfig{
+		{AttributeKey: "Groups", AttributeValue: "dev", Roles: []string{"Reader"}},
+		{AttributeKey: "Groups", AttributeValue: "ops", Roles: []string{"Reader", "Security"}},
+	}, []storage.RoleConfig{
+		{Name: "Reader", Permissions: []storage.PermissionConfig{{ObjectType: "tools", Proxy: "*", ObjectName: "*"}}},
+		{
+			Name:        "Security",
+			Permissions: []storage.PermissionConfig{{ObjectType: "tools", Proxy: "*", ObjectName: "*"}},
+			Clearance:   storage.ClassificationConfidential,
+		},
+	})
+	for _, label := range []storage.ClassificationConfig{
+		{Proxy: "github", Tool: "*", Level: storage.ClassificationInternal},
+		{Proxy: "github", Tool: "search", Level: storage.ClassificationPublic},
+	} {
+		assert.NoError(t, engine.SetClassification(context.Background(), label))
+	}
+	provider := BaseProvider{
+		storage: engine,
+		logger:  initLogger(),
+	}
+	dev := map[string]interface{}{"Groups": "dev"}
+	ops := map[string]interface{}{"Groups": "ops"}
+
+	// The most specific label declassifies the tool
+	decision := provider.ExplainPermissions(context.Background(), "tools", "github", "search", dev)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, storage.ClassificationPublic, decision.Classification)
+
+	decision = provider.ExplainPermissions(context.Background(), "tools", "github", "delete_repo", dev)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, storage.ClassificationInternal, decision.Classification)
+	assert.Equal(t, "the classification internal exceeds the clearance of the roles", decision.Reason)
+
+	// The role with the clearance grants the access, even if sorted after a role lacking it
+	decision = provider.ExplainPermissions(context.Background(), "tools", "github", "delete_repo", ops)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "Security", decision.MatchedRole)
+
+	// Unlabelled tools are public
+	decision = provider.ExplainPermissions(context.Background(), "tools", "slack", "post", dev)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, storage.ClassificationPublic, decision.Classification)
+}
+
+func TestBaseProvider_ExplainPermissionsProxyLabels(t *testing.T) {
+	engine := initData(t, []storage.AttributeToRolesConfig{
+		{AttributeKey: "Groups", AttributeValue: "dev", Roles: []string{"Staging"}},
+	}, []storage.RoleConfig{
+		{Name: "Staging", Permissions: []storage.PermissionConfig{
+			{ObjectType: "tools", Proxy: storage.ProxyLabelPrefix + "env=staging,team=payments", ObjectName: "*"},
+		}},
+	})
+	for _, proxy := range []storage.ProxyConfig{
+		{Name: "billing-staging", Labels: map[string]string{"env": "staging", "team": "payments"}},
+		{Name: "billing", Labels: map[string]string{"env": "production", "team": "payments"}},
+		{Name: "search-staging", Labels: map[string]string{"env": "staging"}},
+	} {
+		proxy.Type, proxy.AuthType = storage.ProxyTypeStreamableHTTP, storage.ProxyAuthTypeHeader
+		assert.NoError(t, engine.SetProxy(context.Background(), &proxy, false))
+	}
+	provider := BaseProvider{
+		storage: engine,
+		logger:  initLogger(),
+	}
+	claims := map[string]interface{}{"Groups": "dev"}
+
+	decision := provider.ExplainPermissions(context.Background(), "tools", "billing-staging", "refund", claims)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "Staging", decision.MatchedRole)
+	assert.False(t, provider.VerifyPermissions(context.Background(), "tools", "billing", "refund", claims))
+	assert.False(t, provider.VerifyPermissions(context.Background(), "tools", "search-staging", "search", claims))
+	assert.False(t, provider.VerifyPermissions(context.Background(), "tools", "unknown", "search", claims))
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+)
+
+// The reasons of the failures of the checks of the claims, after the verification by the provider.
+const (
+	// TokenFailureNotYetValid is a token whose nbf or iat claim is in the future, beyond the clock skew.
+	TokenFailureNotYetValid = "not_yet_valid"
+	// TokenFailureTooOld is a token issued longer ago than the maximum token age.
+	TokenFailureTooOld = "too_old"
+	// TokenFailureClaims is a token missing a required claim, or whose required claim does not match.
+	TokenFailureClaims = "claims"
+)
+
+// ClaimsProvider checks the claims of the tokens verified by an auth provider, whatever the provider: their
+// expiration and not-before times with the clock skew, their age and the required claims. It wraps the token
+// cache, so the cached tokens are checked on each request too.
+type ClaimsProvider struct {
+	Provider
+	clockSkew   time.Duration
+	maxTokenAge time.Duration
+	required    map[string]string
+	now         func() time.Time
+}
+
+// NewClaimsProvider wraps an auth provider with the checks of the claims.
+func NewClaimsProvider(p Provider, config *cfg.AuthProviderConfig) *ClaimsProvider {
+	return &ClaimsProvider{
+		Provider:    p,
+		clockSkew:   config.ClockSkew,
+		maxTokenAge: config.MaxTokenAge,
+		required:    config.RequiredClaimMap(),
+		now:         time.Now,
+	}
+}
+
+// VerifyToken verifies a token with the auth provider, then checks its claims.
+func (p *ClaimsProvider) VerifyToken(token string) (*Jwt, error) {
+	jwt, err := p.Provider.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.verifyClaims(jwt.Claims); err != nil {
+		return nil, err
+	}
+	return jwt, nil
+}
+
+// verifyClaims returns a TokenError telling the client why the claims are rejected, if they are.
+func (p *ClaimsProvider) verifyClaims(claims map[string]interface{}) error {
+	now := p.now()
+	if exp, ok := numericDate(claims, "exp"); ok && now.After(exp.Add(p.clockSkew)) {
+		return &TokenError{Reason: TokenFailureExpired, Description: "Token expired",
+			Err: fmt.Errorf("token expired at %s", exp.UTC().Format(time.RFC3339))}
+	}
+	if nbf, ok := numericDate(claims, "nbf"); ok && now.Add(p.clockSkew).Before(nbf) {
+		return &TokenError{Reason: TokenFailureNotYetValid, Description: "Token not yet valid",
+			Err: fmt.Errorf("token not valid before %s", nbf.UTC().Format(time.RFC3339))}
+	}
+	iat, ok := numericDate(claims, "iat")
+	if ok && now.Add(p.clockSkew).Before(iat) {
+		return &TokenError{Reason: TokenFailureNotYetValid, Description: "Token issued in the future",
+			Err: fmt.Errorf("token issued in the future, at %s", iat.UTC().Format(time.RFC3339))}
+	}
+	if p.maxTokenAge > 0 {
+		if !ok {
+			return &TokenError{Reason: TokenFailureTooOld, Description: "Token without issue time",
+				Err: fmt.Errorf("claim iat is missing, the age of the token cannot be checked")}
+		}
+		if age := now.Sub(iat); age > p.maxTokenAge+p.clockSkew {
+			return &TokenError{Reason: TokenFailureTooOld, Description: "Token older than the maximum token age",
+				Err: fmt.Errorf("token issued %s ago, more than the maximum token age of %s", age.Truncate(time.Second), p.maxTokenAge)}
+		}
+	}
+	if err := verifyRequiredClaims(claims, p.required); err != nil {
+		// The expected values are not disclosed to the client
+		return &TokenError{Reason: TokenFailureClaims, Description: "Missing or invalid required claim", Err: err}
+	}
+	return nil
+}
+
+// verifyRequiredClaims checks that each required claim is its expected value, or a list containing it. The values
+// which are not strings, e.g. email_verified, are compared as formatted, e.g. true.
+func verifyRequiredClaims(claims map[string]interface{}, required map[string]string) error {
+	for name, expected := range required {
+		value, ok := claims[name]
+		if !ok || value == nil {
+			return fmt.Errorf("claim %s is missing", name)
+		}
+		if !claimMatches(value, expected) {
+			return fmt.Errorf("claim %s does not match %s", name, expected)
+		}
+	}
+	return nil
+}
+
+func claimMatches(value interface{}, expected string) bool {
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			if _, ok := item.([]interface{}); !ok && claimMatches(item, expected) {
+				return true
+			}
+		}
+		return false
+	case []string:
+		for _, item := range value {
+			if item == expected {
+				return true
+			}
+		}
+		return false
+	default:
+		return fmt.Sprint(value) == expected
+	}
+}
+
+// numericDate returns the time of a NumericDate claim, the seconds since the epoch, false if it is missing or not
+// a number.
+func numericDate(claims map[string]interface{}, name string) (time.Time, bool) {
+	var seconds float64
+	switch value := claims[name].(type) {
+	case float64:
+		seconds = value
+	case int64:
+		seconds = float64(value)
+	case int:
+		seconds = float64(value)
+	case json.Number:
+		parsed, err := value.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds = parsed
+	default:
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticProvider struct {
+	Provider
+	claims map[string]interface{}
+}
+
+func (p *staticProvider) VerifyToken(string) (*Jwt, error) {
+	return &Jwt{Claims: p.claims}, nil
+}
+
+func TestClaimsProvider(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	at := func(offset time.Duration) float64 { return float64(now.Add(offset).Unix()) }
+	required := []string{"email_verified=true", "amr=mfa"}
+
+	for _, test := range []struct {
+		name        string
+		claims      map[string]interface{}
+		required    []string
+		reason      string
+		description string
+	}{
+		{name: "valid token", claims: map[string]interface{}{
+			"iat": at(-time.Minute), "exp": at(time.Hour), "email_verified": true, "amr": []interface{}{"pwd", "mfa"},
+		}, required: required},
+		{name: "expired within the clock skew", claims: map[string]interface{}{
+			"iat": at(-time.Minute), "exp": at(-20 * time.Second),
+		}},
+		{name: "expired", claims: map[string]interface{}{"iat": at(-time.Minute), "exp": at(-time.Minute)},
+			reason: TokenFailureExpired, description: "Token expired"},
+		{name: "not yet valid", claims: map[string]interface{}{"iat": at(-time.Minute), "nbf": at(time.Minute)},
+			reason: TokenFailureNotYetValid, description: "Token not yet valid"},
+		{name: "issued in the future", claims: map[string]interface{}{"iat": at(time.Minute)},
+			reason: TokenFailureNotYetValid, description: "Token issued in the future"},
+		{name: "too old", claims: map[string]interface{}{"iat": at(-2 * time.Hour), "exp": at(time.Hour)},
+			reason: TokenFailureTooOld, description: "Token older than the maximum token age"},
+		{name: "without issue time", claims: map[string]interface{}{"exp": at(time.Hour)},
+			reason: TokenFailureTooOld, description: "Token without issue time"},
+		{name: "missing claim", claims: map[string]interface{}{"iat": at(0), "email_verified": true},
+			required: required, reason: TokenFailureClaims, description: "Missing or invalid required claim"},
+		{name: "unverified email", claims: map[string]interface{}{"iat": at(0), "email_verified": false, "amr": []interface{}{"mfa"}},
+			required: required, reason: TokenFailureClaims, description: "Missing or invalid required claim"},
+		{name: "without mfa", claims: map[string]interface{}{"iat": at(0), "email_verified": true, "amr": []interface{}{"pwd"}},
+			required: required, reason: TokenFailureClaims, description: "Missing or invalid required claim"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			provider := NewClaimsProvider(&staticProvider{claims: test.claims}, &cfg.AuthProviderConfig{
+				ClockSkew:      30 * time.Second,
+				MaxTokenAge:    time.Hour,
+				RequiredClaims: test.required,
+			})
+			provider.now = func() time.Time { return now }
+
+			jwt, err := provider.VerifyToken("token")
+			if test.reason == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.claims, jwt.Claims)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, test.reason, TokenFailureReason(err))
+			assert.Equal(t, test.description, TokenFailureDescription(err))
+		})
+	}
+
+	assert.Equal(t, "Invalid token", TokenFailureDescription(errors.New("signature is invalid")),
+		"the provider failures are not disclosed")
+}
+
+func TestVerifyRequiredClaims(t *testing.T) {
+	required := (&cfg.AuthProviderConfig{RequiredClaims: []string{"scp=mcp", " tenant = acme ", "email_verified=true"}}).RequiredClaimMap()
+	for _, test := range []struct {
+		name   string
+		claims map[string]interface{}
+		err    string
+	}{
+		{name: "matching claims", claims: map[string]interface{}{"scp": []interface{}{"openid", "mcp"}, "tenant": "acme", "email_verified": true}},
+		{name: "missing claim", claims: map[string]interface{}{"scp": []interface{}{"mcp"}, "email_verified": true}, err: "claim tenant is missing"},
+		{name: "other value", claims: map[string]interface{}{"scp": "mcp", "tenant": "globex", "email_verified": true},
+			err: "claim tenant does not match acme"},
+		{name: "list without the value", claims: map[string]interface{}{"scp": []interface{}{"openid"}, "tenant": "acme", "email_verified": true},
+			err: "claim scp does not match mcp"},
+		{name: "unverified email", claims: map[string]interface{}{"scp": "mcp", "tenant": "acme", "email_verified": false},
+			err: "claim email_verified does not match true"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := verifyRequiredClaims(test.claims, required)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
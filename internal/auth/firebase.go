@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// FirebaseIDTokenKeysURL is the JWKS of the Firebase Auth ID tokens.
+	FirebaseIDTokenKeysURL = "https://www.googleapis.com/service_accounts/v1/jwk/securetoken@system.gserviceaccount.com"
+	// FirebaseAppCheckKeysURL is the JWKS of the Firebase App Check tokens.
+	FirebaseAppCheckKeysURL = "https://firebaseappcheck.googleapis.com/v1/jwks"
+)
+
+// FirebaseProvider is a provider for the ID tokens of Firebase Auth. It verifies the Firebase App Check tokens
+// of the requests too, if required.
+type FirebaseProvider struct {
+	BaseProvider
+	cfg *cfg.FirebaseConfig
+	// clockSkew is the leeway on the exp claim
+	clockSkew time.Duration
+	idTokens  *jwksVerifier
+	appCheck  *jwksVerifier
+	now       func() time.Time
+	logger    logger.Logger
+}
+
+// Init initializes the Firebase provider
+func (p *FirebaseProvider) Init() error {
+	client := &http.Client{Timeout: jwksFetchTimeout}
+	p.idTokens = newJWKSVerifier(FirebaseIDTokenKeysURL, client)
+	p.appCheck = newJWKSVerifier(FirebaseAppCheckKeysURL, client)
+	p.now = time.Now
+	return nil
+}
+
+// VerifyToken verifies a Firebase ID token: its signature, its issuer and audience, which are the project, its
+// subject and its expiration. Its required claims are checked by the ClaimsProvider wrapping the provider.
+func (p *FirebaseProvider) VerifyToken(token string) (*Jwt, error) {
+	claims, err := p.idTokens.verify(token)
+	if err != nil {
+		p.logger.Error("Error verifying the Firebase ID token", zap.Error(err))
+		return nil, err
+	}
+	issuer := "https://securetoken.google.com/" + p.cfg.ProjectID
+	if claims["iss"] != issuer {
+		return nil, &TokenError{Reason: TokenFailureInvalid, Err: fmt.Errorf("unexpected issuer %v, expected %s", claims["iss"], issuer)}
+	}
+	if !hasAudience(claims, p.cfg.ProjectID) {
+		return nil, &TokenError{Reason: TokenFailureAudience, Description: "Invalid token audience",
+			Err: fmt.Errorf("unexpected audience %v, expected %s", claims["aud"], p.cfg.ProjectID)}
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, &TokenError{Reason: TokenFailureInvalid, Err: errors.New("the token has no subject")}
+	}
+	if err := p.verifyExpiration(claims); err != nil {
+		return nil, err
+	}
+	return &Jwt{Claims: claims}, nil
+}
+
+// AppCheckRequired tells whether the requests must carry an App Check token.
+func (p *FirebaseProvider) AppCheckRequired() bool {
+	return p.cfg.AppCheck
+}
+
+// VerifyAppCheckToken verifies a Firebase App Check token: its signature, its issuer and audience, which are the
+// project number, and its expiration.
+func (p *FirebaseProvider) VerifyAppCheckToken(token string) error {
+	if token == "" {
+		return &TokenError{Reason: TokenFailureAppCheck, Err: errors.New("missing App Check token")}
+	}
+	claims, err := p.appCheck.verify(token)
+	if err != nil {
+		return &TokenError{Reason: TokenFailureAppCheck, Err: fmt.Errorf("verifying the App Check token: %w", err)}
+	}
+	issuer := "https://firebaseappcheck.googleapis.com/" + p.cfg.ProjectNumber
+	if claims["iss"] != issuer {
+		return &TokenError{Reason: TokenFailureAppCheck, Err: fmt.Errorf("unexpected App Check issuer %v, expected %s", claims["iss"], issuer)}
+	}
+	if !hasAudience(claims, "projects/"+p.cfg.ProjectNumber) {
+		return &TokenError{Reason: TokenFailureAppCheck, Err: fmt.Errorf("unexpected App Check audience %v", claims["aud"])}
+	}
+	if err := p.verifyExpiration(claims); err != nil {
+		return &TokenError{Reason: TokenFailureAppCheck, Err: fmt.Errorf("verifying the App Check token: %w", err)}
+	}
+	return nil
+}
+
+func (p *FirebaseProvider) verifyExpiration(claims map[string]interface{}) error {
+	exp, ok := numericDate(claims, "exp")
+	if !ok {
+		return &TokenError{Reason: TokenFailureInvalid, Err: errors.New("the token has no expiration")}
+	}
+	if p.now().After(exp.Add(p.clockSkew)) {
+		return &TokenError{Reason: TokenFailureExpired, Description: "Token expired",
+			Err: fmt.Errorf("token expired at %s", exp.UTC().Format(time.RFC3339))}
+	}
+	return nil
+}
+
+// CheckHealth checks that the signing keys of the ID tokens, and of the App Check tokens if required, can be
+// fetched.
+func (p *FirebaseProvider) CheckHealth(ctx context.Context) error {
+	if _, err := fetchJWKS(ctx, p.idTokens.client, p.idTokens.url); err != nil {
+		return fmt.Errorf("fetching the JWKS of the ID tokens: %w", err)
+	}
+	if p.cfg.AppCheck {
+		if _, err := fetchJWKS(ctx, p.appCheck.client, p.appCheck.url); err != nil {
+			return fmt.Errorf("fetching the JWKS of the App Check tokens: %w", err)
+		}
+	}
+	return nil
+}
+
+// hasAudience tells whether the aud claim, a string or an array, contains an audience.
+func hasAudience(claims map[string]interface{}, audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, value := range aud {
+			if value == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigner signs the test JWTs with an RSA key served by a JWKS server.
+type testSigner struct {
+	key    *rsa.PrivateKey
+	kid    string
+	server *httptest.Server
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer := &testSigner{key: key, kid: "key-1"}
+	signer.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": signer.kid,
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	t.Cleanup(signer.server.Close)
+	return signer
+}
+
+func (s *testSigner) sign(t *testing.T, header, claims map[string]any) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestFirebaseProvider_VerifyToken(t *testing.T) {
+	signer := newTestSigner(t)
+	now := time.Unix(1_700_000_000, 0)
+	provider := &FirebaseProvider{
+		cfg:       &cfg.FirebaseConfig{ProjectID: "my-project"},
+		clockSkew: time.Minute,
+		idTokens:  newJWKSVerifier(signer.server.URL, signer.server.Client()),
+		now:       func() time.Time { return now },
+		logger:    logger.NewNoopLogger(),
+	}
+	header := map[string]any{"alg": "RS256", "kid": signer.kid}
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss": "https://securetoken.google.com/my-project",
+			"aud": "my-project",
+			"sub": "user-1",
+			"iat": now.Add(-time.Minute).Unix(),
+			"exp": now.Add(time.Hour).Unix(),
+		}
+	}
+
+	jwt, err := provider.VerifyToken(signer.sign(t, header, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", jwt.Claims["sub"])
+
+	for _, test := range []struct {
+		name   string
+		token  func() string
+		reason string
+	}{
+		{name: "another issuer", reason: TokenFailureInvalid, token: func() string {
+			claims := validClaims()
+			claims["iss"] = "https://securetoken.google.com/another-project"
+			return signer.sign(t, header, claims)
+		}},
+		{name: "another audience", reason: TokenFailureAudience, token: func() string {
+			claims := validClaims()
+			claims["aud"] = "another-project"
+			return signer.sign(t, header, claims)
+		}},
+		{name: "no subject", reason: TokenFailureInvalid, token: func() string {
+			claims := validClaims()
+			delete(claims, "sub")
+			return signer.sign(t, header, claims)
+		}},
+		{name: "expired", reason: TokenFailureExpired, token: func() string {
+			claims := validClaims()
+			claims["exp"] = now.Add(-2 * time.Minute).Unix()
+			return signer.sign(t, header, claims)
+		}},
+		{name: "unknown key", reason: TokenFailureInvalid, token: func() string {
+			return signer.sign(t, map[string]any{"alg": "RS256", "kid": "key-2"}, validClaims())
+		}},
+		{name: "other algorithm", reason: TokenFailureInvalid, token: func() string {
+			return signer.sign(t, map[string]any{"alg": "none", "kid": signer.kid}, validClaims())
+		}},
+		{name: "bad signature", reason: TokenFailureInvalid, token: func() string {
+			claims := validClaims()
+			claims["sub"] = "user-2"
+			forged := signer.sign(t, header, claims)
+			valid := signer.sign(t, header, validClaims())
+			return forged[:len(forged)-10] + valid[len(valid)-10:]
+		}},
+		{name: "malformed", reason: TokenFailureMalformed, token: func() string { return "not-a-jwt" }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := provider.VerifyToken(test.token())
+			require.Error(t, err)
+			assert.Equal(t, test.reason, TokenFailureReason(err))
+		})
+	}
+
+	signer.server.Close()
+	provider.idTokens = newJWKSVerifier(signer.server.URL, signer.server.Client())
+	_, err = provider.VerifyToken(signer.sign(t, header, validClaims()))
+	assert.Equal(t, TokenFailureUnavailable, TokenFailureReason(err), "the keys cannot be fetched")
+}
+
+func TestFirebaseProvider_VerifyAppCheckToken(t *testing.T) {
+	signer := newTestSigner(t)
+	now := time.Unix(1_700_000_000, 0)
+	provider := &FirebaseProvider{
+		cfg:       &cfg.FirebaseConfig{ProjectID: "my-project", AppCheck: true, ProjectNumber: "123456"},
+		clockSkew: time.Minute,
+		appCheck:  newJWKSVerifier(signer.server.URL, signer.server.Client()),
+		now:       func() time.Time { return now },
+		logger:    logger.NewNoopLogger(),
+	}
+	assert.True(t, provider.AppCheckRequired())
+	header := map[string]any{"alg": "RS256", "typ": "JWT", "kid": signer.kid}
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss": "https://firebaseappcheck.googleapis.com/123456",
+			"aud": []string{"projects/123456", "projects/my-project"},
+			"sub": "1:123456:web:abc",
+			"exp": now.Add(time.Hour).Unix(),
+		}
+	}
+
+	assert.NoError(t, provider.VerifyAppCheckToken(signer.sign(t, header, validClaims())))
+
+	anotherProject := validClaims()
+	anotherProject["iss"] = "https://firebaseappcheck.googleapis.com/654321"
+	anotherAudience := validClaims()
+	anotherAudience["aud"] = []string{"projects/654321"}
+	expired := validClaims()
+	expired["exp"] = now.Add(-2 * time.Minute).Unix()
+	for name, token := range map[string]string{
+		"missing":          "",
+		"malformed":        "not-a-jwt",
+		"another project":  signer.sign(t, header, anotherProject),
+		"another audience": signer.sign(t, header, anotherAudience),
+		"expired":          signer.sign(t, header, expired),
+	} {
+		err := provider.VerifyAppCheckToken(token)
+		require.Error(t, err, name)
+		assert.Equal(t, TokenFailureAppCheck, TokenFailureReason(err), name)
+	}
+}
+
+func TestJWKSVerifier_Refresh(t *testing.T) {
+	signer := newTestSigner(t)
+	now := time.Unix(1_700_000_000, 0)
+	verifier := newJWKSVerifier(signer.server.URL, signer.server.Client())
+	verifier.now = func() time.Time { return now }
+	header := map[string]any{"alg": "RS256", "kid": "key-2"}
+
+	_, err := verifier.verify(signer.sign(t, header, map[string]any{}))
+	assert.ErrorContains(t, err, "unknown signing key")
+
+	// The keys are rotated, but not fetched again right away
+	signer.kid = "key-2"
+	_, err = verifier.verify(signer.sign(t, header, map[string]any{}))
+	assert.ErrorContains(t, err, "unknown signing key")
+
+	now = now.Add(jwksMinRefresh + time.Second)
+	_, err = verifier.verify(signer.sign(t, header, map[string]any{}))
+	assert.NoError(t, err)
+}
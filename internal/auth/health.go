@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxHealthDocumentSize bounds the discovery documents and key sets read by the health checks.
+const maxHealthDocumentSize = 1 << 20
+
+// HealthChecker is implemented by the providers which can check that their dependencies work.
+type HealthChecker interface {
+	// CheckHealth returns an error if the provider cannot verify the tokens.
+	CheckHealth(ctx context.Context) error
+}
+
+// checkIssuer checks that the OpenID Connect discovery document of an issuer and its signing keys can be
+// fetched, which the verification of the tokens requires.
+func checkIssuer(ctx context.Context, client *http.Client, issuer string) error {
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := getJSON(ctx, client, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return fmt.Errorf("fetching the issuer metadata: %w", err)
+	}
+	if discovery.JWKSURI == "" {
+		return errors.New("the issuer metadata has no jwks_uri")
+	}
+	var keySet struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := getJSON(ctx, client, discovery.JWKSURI, &keySet); err != nil {
+		return fmt.Errorf("fetching the JWKS: %w", err)
+	}
+	if len(keySet.Keys) == 0 {
+		return errors.New("the JWKS has no key")
+	}
+	return nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // nothing interesting to do with the error
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxHealthDocumentSize)).Decode(v)
+}
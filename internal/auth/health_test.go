@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckIssuer(t *testing.T) {
+	keys := `{"keys":[{"kty":"RSA","kid":"key"}]}`
+	mux := http.NewServeMux()
+	issuer := httptest.NewServer(mux)
+	defer issuer.Close()
+	mux.HandleFunc("/oauth2/default/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"issuer":"` + issuer.URL + `/oauth2/default","jwks_uri":"` + issuer.URL + `/oauth2/default/v1/keys"}`))
+	})
+	mux.HandleFunc("/oauth2/default/v1/keys", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(keys))
+	})
+
+	assert.NoError(t, checkIssuer(t.Context(), issuer.Client(), issuer.URL+"/oauth2/default"))
+	assert.NoError(t, checkIssuer(t.Context(), issuer.Client(), issuer.URL+"/oauth2/default/"))
+
+	keys = `{"keys":[]}`
+	assert.ErrorContains(t, checkIssuer(t.Context(), issuer.Client(), issuer.URL+"/oauth2/default"), "no key")
+	assert.ErrorContains(t, checkIssuer(t.Context(), issuer.Client(), issuer.URL+"/oauth2/unknown"), "unexpected status 404")
+	assert.Error(t, checkIssuer(t.Context(), issuer.Client(), "http://127.0.0.1:1"))
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksFetchTimeout bounds the fetches of the signing keys during the verification of a token.
+	jwksFetchTimeout = 10 * time.Second
+	// jwksMaxAge is how long the signing keys are cached before being fetched again.
+	jwksMaxAge = time.Hour
+	// jwksMinRefresh rate-limits the fetches of the signing keys on the tokens signed with an unknown key.
+	jwksMinRefresh = time.Minute
+)
+
+// jwksVerifier verifies the RS256 signatures of the JWTs with the keys of a JWKS, which are cached and fetched
+// again when they are too old or a token is signed with an unknown key.
+type jwksVerifier struct {
+	url    string
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func newJWKSVerifier(url string, client *http.Client) *jwksVerifier {
+	return &jwksVerifier{url: url, client: client, now: time.Now}
+}
+
+// verify checks the signature of a token and returns its claims, or a TokenError.
+func (v *jwksVerifier) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, &TokenError{Reason: TokenFailureMalformed, Err: errors.New("the token is not a signed JWT")}
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, &TokenError{Reason: TokenFailureMalformed, Err: fmt.Errorf("decoding the token header: %w", err)}
+	}
+	if header.Alg != "RS256" {
+		return nil, &TokenError{Reason: TokenFailureInvalid, Err: fmt.Errorf("unexpected signing algorithm %q", header.Alg)}
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, &TokenError{Reason: TokenFailureMalformed, Err: fmt.Errorf("decoding the token signature: %w", err)}
+	}
+
+	key, err := v.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, &TokenError{Reason: TokenFailureInvalid, Err: fmt.Errorf("verifying the token signature: %w", err)}
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, &TokenError{Reason: TokenFailureMalformed, Err: fmt.Errorf("decoding the token claims: %w", err)}
+	}
+	return claims, nil
+}
+
+// key returns the signing key of a key ID, fetching the keys if they are too old or the key is unknown.
+func (v *jwksVerifier) key(kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	key, known := v.keys[kid]
+	stale := now.Sub(v.fetchedAt) > jwksMaxAge
+	if known && !stale {
+		return key, nil
+	}
+	if !stale && now.Sub(v.fetchedAt) < jwksMinRefresh {
+		return nil, &TokenError{Reason: TokenFailureInvalid, Err: fmt.Errorf("unknown signing key %q", kid)}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	keys, err := fetchJWKS(ctx, v.client, v.url)
+	if err != nil {
+		return nil, &TokenError{Reason: TokenFailureUnavailable, Err: fmt.Errorf("fetching the JWKS: %w", err)}
+	}
+	v.keys = keys
+	v.fetchedAt = now
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, &TokenError{Reason: TokenFailureInvalid, Err: fmt.Errorf("unknown signing key %q", kid)}
+}
+
+// fetchJWKS returns the RSA signing keys of a JWKS by key ID, ignoring the other keys.
+func fetchJWKS(ctx context.Context, client *http.Client, url string) (map[string]*rsa.PublicKey, error) {
+	var keySet struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, client, url, &keySet); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("decoding the modulus of the key %q: %w", jwk.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid exponent of the key %q", jwk.Kid)
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("the JWKS has no RSA key")
+	}
+	return keys, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
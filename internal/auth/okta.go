@@ -1,7 +1,11 @@
 package auth
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/matthisholleville/mcp-gateway/internal/cfg"
 	"github.com/matthisholleville/mcp-gateway/pkg/logger"
@@ -15,8 +19,10 @@ type OktaProvider struct {
 	BaseProvider
 	cfg      *cfg.OktaConfig
 	oauthCfg *cfg.OAuthConfig
-	client   *okta.APIClient
-	logger   logger.Logger
+	// clockSkew is the leeway of the verifier on the exp, nbf and iat claims
+	clockSkew time.Duration
+	client    *okta.APIClient
+	logger    logger.Logger
 }
 
 // Init initializes the Okta provider
@@ -37,23 +43,63 @@ func (p *OktaProvider) Init() error {
 	return nil
 }
 
-// VerifyToken verifies a JWT token
+// VerifyToken verifies a JWT token: its signature, its issuer, its expiration, and its audience and client, if
+// configured. Its required claims are checked by the ClaimsProvider wrapping the provider.
 func (p *OktaProvider) VerifyToken(token string) (*Jwt, error) {
 	verifierSetup := jwtverifier.JwtVerifier{
-		Issuer: p.cfg.Issuer,
+		Issuer:           p.cfg.Issuer,
+		ClaimsToValidate: p.claimsToValidate(),
 	}
 
 	verifier, err := verifierSetup.New()
 	if err != nil {
 		p.logger.Error("Error setting up JWT verifier", zap.Error(err))
-		return nil, fmt.Errorf("error setting up JWT verifier: %w", err)
+		return nil, &TokenError{Reason: TokenFailureUnavailable, Err: fmt.Errorf("error setting up JWT verifier: %w", err)}
 	}
+	verifier.SetLeeway(p.clockSkew.String())
 
 	jwtToken, err := verifier.VerifyAccessToken(token)
 	if err != nil {
 		p.logger.Error("Error verifying JWT", zap.Error(err))
-		return nil, fmt.Errorf("error verifying JWT: %w", err)
+		return nil, &TokenError{Reason: oktaFailureReason(err), Err: fmt.Errorf("error verifying JWT: %w", err)}
 	}
 
 	return &Jwt{Claims: jwtToken.Claims}, nil
 }
+
+// claimsToValidate returns the claims the Okta verifier checks besides the issuer: the audience and the client
+// of the tokens, if configured.
+func (p *OktaProvider) claimsToValidate() map[string]string {
+	claims := map[string]string{}
+	if p.cfg.Audience != "" {
+		claims["aud"] = p.cfg.Audience
+	}
+	if p.cfg.TokenClientID != "" {
+		claims["cid"] = p.cfg.TokenClientID
+	}
+	return claims
+}
+
+// oktaFailureReason returns the reason of an error of the Okta JWT verifier, which are only told apart by their
+// messages.
+func oktaFailureReason(err error) string {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "token is expired"):
+		return TokenFailureExpired
+	case strings.Contains(message, "the `Audience`"):
+		return TokenFailureAudience
+	case strings.Contains(message, "request for metadata"), strings.Contains(message, "jwks_uri"):
+		return TokenFailureUnavailable
+	case strings.Contains(message, "token must contain"), strings.Contains(message, "tokens header"),
+		strings.Contains(message, "could not decode token"):
+		return TokenFailureMalformed
+	default:
+		return TokenFailureInvalid
+	}
+}
+
+// CheckHealth checks that the metadata and the signing keys of the Okta authorization server can be fetched.
+func (p *OktaProvider) CheckHealth(ctx context.Context) error {
+	return checkIssuer(ctx, http.DefaultClient, p.cfg.Issuer)
+}
@@ -3,6 +3,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/matthisholleville/mcp-gateway/internal/cfg"
@@ -15,6 +16,19 @@ type Provider interface {
 	Init() error
 	VerifyToken(token string) (*Jwt, error)
 	VerifyPermissions(ctx context.Context, objectType, objectName, proxy string, claims map[string]interface{}) bool
+	ExplainPermissions(ctx context.Context, objectType, proxy, objectName string, claims map[string]interface{}) PermissionDecision
+}
+
+// AppCheckHeader is the header of the Firebase App Check tokens.
+const AppCheckHeader = "X-Firebase-AppCheck"
+
+// AppCheckVerifier is implemented by the providers which can verify the App Check tokens attesting the client
+// apps, alongside the tokens of their users.
+type AppCheckVerifier interface {
+	// AppCheckRequired tells whether the requests must carry an App Check token.
+	AppCheckRequired() bool
+	// VerifyAppCheckToken returns a TokenError if the App Check token is missing or not valid.
+	VerifyAppCheckToken(token string) error
 }
 
 // Jwt is the struct for the JWT token
@@ -22,6 +36,56 @@ type Jwt struct {
 	Claims map[string]interface{}
 }
 
+// The reasons of the token verification failures.
+const (
+	TokenFailureMissing   = "missing"
+	TokenFailureMalformed = "malformed"
+	TokenFailureExpired   = "expired"
+	TokenFailureAudience  = "audience"
+	// TokenFailureInvalid is a token whose signature, issuer or claims are not valid.
+	TokenFailureInvalid = "invalid"
+	// TokenFailureUnavailable is a token which could not be verified, the metadata or the keys of the issuer
+	// being unavailable.
+	TokenFailureUnavailable = "unavailable"
+	// TokenFailureAppCheck is a request whose App Check token is missing or not valid.
+	TokenFailureAppCheck = "app_check"
+)
+
+// TokenError is a token verification failure, with its reason.
+type TokenError struct {
+	Reason string
+	// Description tells the client why the token is rejected, if it may be disclosed.
+	Description string
+	Err         error
+}
+
+func (e *TokenError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+// TokenFailureReason returns the reason of a token verification failure, TokenFailureInvalid if unknown.
+func TokenFailureReason(err error) string {
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		return tokenErr.Reason
+	}
+	return TokenFailureInvalid
+}
+
+// TokenFailureDescription returns the description of a token verification failure for the client, "Invalid
+// token" if it may not be disclosed.
+func TokenFailureDescription(err error) string {
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) && tokenErr.Description != "" {
+		return tokenErr.Description
+	}
+	return "Invalid token"
+}
+
 // NewProvider creates a new provider
 //
 //nolint:gocritic // we need to keep logger as a parameter for the function
@@ -33,9 +97,20 @@ func NewProvider(provider string, cfg *cfg.Config, logger logger.Logger, storage
 				logger:  logger,
 				storage: storage,
 			},
-			cfg:      cfg.AuthProvider.Okta,
-			oauthCfg: cfg.OAuth,
-			logger:   logger,
+			cfg:       cfg.AuthProvider.Okta,
+			oauthCfg:  cfg.OAuth,
+			clockSkew: cfg.AuthProvider.ClockSkew,
+			logger:    logger,
+		}, nil
+	case "firebase":
+		return &FirebaseProvider{
+			BaseProvider: BaseProvider{
+				logger:  logger,
+				storage: storage,
+			},
+			cfg:       cfg.AuthProvider.Firebase,
+			clockSkew: cfg.AuthProvider.ClockSkew,
+			logger:    logger,
 		}, nil
 	default:
 		return nil, fmt.Errorf("provider %s not found", provider)
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenFailureReason(t *testing.T) {
+	for _, test := range []struct {
+		err      error
+		expected string
+	}{
+		{err: errors.New("the token is expired"), expected: TokenFailureExpired},
+		{err: errors.New(`request for metadata "https://example.okta.com" was not HTTP 2xx OK, it was: 503`), expected: TokenFailureUnavailable},
+		{err: errors.New("token must contain at least 1 period ('.') and only characters 'a-Z 0-9 _'"), expected: TokenFailureMalformed},
+		{err: errors.New("the `Issuer` was not able to be validated. iss: a does not match b"), expected: TokenFailureInvalid},
+		{err: errors.New("the `Audience` was not able to be validated. aud: a does not match b"), expected: TokenFailureAudience},
+		{err: errors.New("the `Client Id` was not able to be validated. cid: a does not match b"), expected: TokenFailureInvalid},
+	} {
+		err := fmt.Errorf("verifying: %w", &TokenError{Reason: oktaFailureReason(test.err), Err: test.err})
+		assert.Equal(t, test.expected, TokenFailureReason(err), test.err.Error())
+	}
+	assert.Equal(t, TokenFailureInvalid, TokenFailureReason(errors.New("unknown")))
+}
+
+func TestOktaProvider_Claims(t *testing.T) {
+	provider := &OktaProvider{cfg: &cfg.OktaConfig{Audience: "api://gateway", TokenClientID: "0oa-agents"}}
+	assert.Equal(t, map[string]string{"aud": "api://gateway", "cid": "0oa-agents"}, provider.claimsToValidate())
+	assert.Empty(t, (&OktaProvider{cfg: &cfg.OktaConfig{}}).claimsToValidate(), "any audience and client are accepted by default")
+}
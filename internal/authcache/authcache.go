@@ -0,0 +1,173 @@
+// Package authcache caches the authorization data read on each MCP request, the roles, the attribute-to-roles
+// mappings and the verified tokens, in Redis so they are shared by the replicas of the gateway. Each replica also
+// keeps them in memory: the writes are published on a Redis channel, so every replica drops the stale entries.
+package authcache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/matthisholleville/mcp-gateway/pkg/redis"
+	"go.uber.org/zap"
+)
+
+// resubscribeDelay is the delay before subscribing again to the invalidation channel after an error.
+const resubscribeDelay = time.Second
+
+// Cache caches values on two tiers, the memory of the replica then Redis. Redis being unreachable is not an
+// error: the values are read from their source.
+type Cache struct {
+	redis    *redis.Client
+	prefix   string
+	ttl      time.Duration
+	tokenTTL time.Duration
+	timeout  time.Duration
+	logger   logger.Logger
+	now      func() time.Time
+
+	mu    sync.Mutex
+	local map[string]localEntry
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type localEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// New creates the cache and subscribes to the invalidation channel, until Close.
+//
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func New(config *cfg.AuthCacheConfig, logger logger.Logger) (*Cache, error) {
+	client, err := redis.NewClient(config.URL, config.Password, config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Cache{
+		redis:    client,
+		prefix:   config.Prefix,
+		ttl:      config.TTL,
+		tokenTTL: config.TokenTTL,
+		timeout:  config.Timeout,
+		logger:   logger,
+		now:      time.Now,
+		local:    make(map[string]localEntry),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go c.subscribe(ctx)
+	return c, nil
+}
+
+// Close stops the subscription and closes the connections to Redis.
+func (c *Cache) Close() error {
+	c.cancel()
+	<-c.done
+	return c.redis.Close()
+}
+
+// get returns the value of a key, from the memory or from Redis, where it is kept in memory for ttl.
+func (c *Cache) get(key string, ttl time.Duration) ([]byte, bool) {
+	c.mu.Lock()
+	entry, ok := c.local[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expiresAt) {
+		return entry.value, true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	reply, err := c.redis.Do(ctx, "GET", c.key(key))
+	if err != nil {
+		c.logger.Warn("Failed to read the auth cache", zap.String("key", key), zap.Error(err))
+		return nil, false
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false
+	}
+	c.setLocal(key, value, ttl)
+	return value, true
+}
+
+// set stores the value of a key for ttl, in memory and in Redis.
+func (c *Cache) set(key string, value []byte, ttl time.Duration) {
+	c.setLocal(key, value, ttl)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	if _, err := c.redis.Do(ctx, "SET", c.key(key), string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10)); err != nil {
+		c.logger.Warn("Failed to write the auth cache", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (c *Cache) setLocal(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.local[key] = localEntry{value: value, expiresAt: c.now().Add(ttl)}
+}
+
+// invalidate deletes keys from Redis and publishes them, so every replica drops them from its memory.
+func (c *Cache) invalidate(keys ...string) {
+	c.dropLocal(keys...)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	args := []string{"DEL"}
+	for _, key := range keys {
+		args = append(args, c.key(key))
+	}
+	if _, err := c.redis.Do(ctx, args...); err != nil {
+		c.logger.Warn("Failed to invalidate the auth cache", zap.Strings("keys", keys), zap.Error(err))
+	}
+	for _, key := range keys {
+		if _, err := c.redis.Do(ctx, "PUBLISH", c.channel(), key); err != nil {
+			c.logger.Warn("Failed to publish the auth cache invalidation", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+func (c *Cache) dropLocal(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.local, key)
+	}
+}
+
+// flushLocal drops every entry of the memory, as the invalidations published while unsubscribed are lost.
+func (c *Cache) flushLocal() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.local)
+}
+
+func (c *Cache) subscribe(ctx context.Context) {
+	defer close(c.done)
+	for ctx.Err() == nil {
+		err := c.redis.Receive(ctx, c.channel(), c.flushLocal, func(key string) { c.dropLocal(key) })
+		if ctx.Err() != nil {
+			return
+		}
+		c.logger.Warn("Lost the subscription to the auth cache invalidations", zap.Error(err))
+		c.flushLocal()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+func (c *Cache) key(key string) string {
+	return c.prefix + ":" + key
+}
+
+func (c *Cache) channel() string {
+	return c.prefix + ":invalidations"
+}
@@ -0,0 +1,181 @@
+package authcache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/auth"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/matthisholleville/mcp-gateway/pkg/redis/redistest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStorage counts the reads of the authorization data.
+type countingStorage struct {
+	storage.Interface
+	roleReads    atomic.Int32
+	mappingReads atomic.Int32
+}
+
+func (s *countingStorage) GetRole(ctx context.Context, name string) (storage.RoleConfig, error) {
+	s.roleReads.Add(1)
+	return s.Interface.GetRole(ctx, name)
+}
+
+func (s *countingStorage) GetRoles(ctx context.Context, names []string) ([]storage.RoleConfig, error) {
+	s.roleReads.Add(1)
+	return s.Interface.GetRoles(ctx, names)
+}
+
+func (s *countingStorage) ListAttributeToRoles(ctx context.Context) ([]storage.AttributeToRolesConfig, error) {
+	s.mappingReads.Add(1)
+	return s.Interface.ListAttributeToRoles(ctx)
+}
+
+func newTestCache(t *testing.T, url string) *Cache {
+	config := cfg.DefaultConfig().AuthCache
+	config.URL = url
+	cache, err := New(config, logger.MustNewLogger("json", "debug", "test"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cache.Close() })
+	return cache
+}
+
+func TestStorage(t *testing.T) {
+	url := redistest.NewServer(t, "secret").URL
+	ctx := context.Background()
+	backend := &countingStorage{Interface: storage.NewMemoryStorage("")}
+	require.NoError(t, backend.SetRole(ctx, storage.RoleConfig{Name: "reader", Clearance: storage.ClassificationPublic}))
+	require.NoError(t, backend.SetAttributeToRoles(ctx, storage.AttributeToRolesConfig{
+		AttributeKey: "groups", AttributeValue: "dev", Roles: []string{"reader"},
+	}))
+	replica1 := NewStorage(backend, newTestCache(t, url))
+	replica2 := NewStorage(backend, newTestCache(t, url))
+
+	t.Run("the roles are shared by the replicas", func(t *testing.T) {
+		role, err := replica1.GetRole(ctx, "reader")
+		require.NoError(t, err)
+		assert.Equal(t, storage.ClassificationPublic, role.Clearance)
+		role, err = replica2.GetRole(ctx, "reader")
+		require.NoError(t, err)
+		assert.Equal(t, storage.ClassificationPublic, role.Clearance)
+		assert.Equal(t, int32(1), backend.roleReads.Load())
+	})
+
+	t.Run("a write invalidates the role of every replica", func(t *testing.T) {
+		// The memory storage does not update the roles, which are replaced.
+		require.NoError(t, replica1.DeleteRole(ctx, "reader"))
+		require.NoError(t, replica1.SetRole(ctx, storage.RoleConfig{Name: "reader", Clearance: storage.ClassificationInternal}))
+		assert.Eventually(t, func() bool {
+			role, err := replica2.GetRole(ctx, "reader")
+			return err == nil && role.Clearance == storage.ClassificationInternal
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("the mappings are looked up in the cached list", func(t *testing.T) {
+		mapping, err := replica1.GetAttributeToRoles(ctx, "groups", "dev")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"reader"}, mapping.Roles)
+		_, err = replica2.GetAttributeToRoles(ctx, "email", "alice@example.com")
+		assert.Error(t, err)
+		mappings, err := replica2.GetAttributeToRolesForClaims(ctx, []storage.AttributePair{
+			{Key: "groups", Value: "dev"}, {Key: "email", Value: "alice@example.com"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []storage.AttributeToRolesConfig{{AttributeKey: "groups", AttributeValue: "dev", Roles: []string{"reader"}}}, mappings)
+		assert.Equal(t, int32(1), backend.mappingReads.Load())
+
+		require.NoError(t, replica2.DeleteAttributeToRoles(ctx, "groups", "dev"))
+		assert.Eventually(t, func() bool {
+			_, err := replica1.GetAttributeToRoles(ctx, "groups", "dev")
+			return err != nil
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("the roles missing from the cache are read at once", func(t *testing.T) {
+		require.NoError(t, backend.SetRole(ctx, storage.RoleConfig{Name: "writer"}))
+		require.NoError(t, backend.SetRole(ctx, storage.RoleConfig{Name: "admin"}))
+		_, err := replica1.GetRole(ctx, "reader")
+		require.NoError(t, err)
+		backend.roleReads.Store(0)
+
+		roles, err := replica1.GetRoles(ctx, []string{"writer", "reader", "admin", "unknown"})
+		require.NoError(t, err)
+		names := []string{}
+		for _, role := range roles {
+			names = append(names, role.Name)
+		}
+		assert.Equal(t, []string{"admin", "reader", "writer"}, names)
+		assert.Equal(t, int32(1), backend.roleReads.Load())
+
+		roles, err = replica2.GetRoles(ctx, []string{"writer", "reader", "admin"})
+		require.NoError(t, err)
+		assert.Len(t, roles, 3)
+		assert.Equal(t, int32(1), backend.roleReads.Load())
+	})
+}
+
+func TestStorageWithoutRedis(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "redis://" + listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ctx := context.Background()
+	backend := storage.NewMemoryStorage("")
+	require.NoError(t, backend.SetRole(ctx, storage.RoleConfig{Name: "reader"}))
+	cached := NewStorage(backend, newTestCache(t, url))
+	role, err := cached.GetRole(ctx, "reader")
+	require.NoError(t, err)
+	assert.Equal(t, "reader", role.Name)
+	require.NoError(t, cached.DeleteRole(ctx, "reader"))
+	_, err = cached.GetRole(ctx, "reader")
+	assert.Error(t, err)
+}
+
+// fakeProvider verifies the tokens "valid" and "expired".
+type fakeProvider struct {
+	auth.Provider
+	verified atomic.Int32
+}
+
+func (p *fakeProvider) VerifyToken(token string) (*auth.Jwt, error) {
+	p.verified.Add(1)
+	switch token {
+	case "valid":
+		return &auth.Jwt{Claims: map[string]interface{}{"sub": "alice", "exp": float64(time.Now().Add(time.Hour).Unix())}}, nil
+	case "expired":
+		return &auth.Jwt{Claims: map[string]interface{}{"sub": "bob", "exp": float64(time.Now().Add(-time.Minute).Unix())}}, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+func TestProvider(t *testing.T) {
+	url := redistest.NewServer(t, "").URL
+	upstream := &fakeProvider{}
+	replica1 := NewProvider(upstream, newTestCache(t, url))
+	replica2 := NewProvider(upstream, newTestCache(t, url))
+
+	jwt, err := replica1.VerifyToken("valid")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", jwt.Claims["sub"])
+	jwt, err = replica2.VerifyToken("valid")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", jwt.Claims["sub"])
+	assert.Equal(t, int32(1), upstream.verified.Load())
+
+	// The invalid and expired tokens are verified each time.
+	for range 2 {
+		_, err = replica1.VerifyToken("invalid")
+		assert.Error(t, err)
+		_, _ = replica1.VerifyToken("expired")
+	}
+	assert.Equal(t, int32(5), upstream.verified.Load())
+}
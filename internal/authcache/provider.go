@@ -0,0 +1,59 @@
+package authcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/auth"
+)
+
+// Provider caches the claims of the tokens verified by an auth provider, keyed by the SHA-256 of the token.
+type Provider struct {
+	auth.Provider
+	cache *Cache
+}
+
+// NewProvider wraps an auth provider with the cache.
+func NewProvider(p auth.Provider, cache *Cache) *Provider {
+	return &Provider{Provider: p, cache: cache}
+}
+
+// VerifyToken returns the cached claims of a token which has not expired, or verifies it with the auth provider.
+func (p *Provider) VerifyToken(token string) (*auth.Jwt, error) {
+	if p.cache.tokenTTL <= 0 {
+		return p.Provider.VerifyToken(token)
+	}
+	sum := sha256.Sum256([]byte(token))
+	key := "token:" + hex.EncodeToString(sum[:])
+
+	var claims map[string]interface{}
+	if value, ok := p.cache.get(key, p.cache.tokenTTL); ok && json.Unmarshal(value, &claims) == nil {
+		if ttl := p.ttl(claims); ttl > 0 {
+			return &auth.Jwt{Claims: claims}, nil
+		}
+	}
+
+	jwt, err := p.Provider.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if ttl := p.ttl(jwt.Claims); ttl > 0 {
+		if value, err := json.Marshal(jwt.Claims); err == nil {
+			p.cache.set(key, value, ttl)
+		}
+	}
+	return jwt, nil
+}
+
+// ttl returns how long the claims of a token may be cached: the token TTL, bounded by the exp claim.
+func (p *Provider) ttl(claims map[string]interface{}) time.Duration {
+	ttl := p.cache.tokenTTL
+	if exp, ok := claims["exp"].(float64); ok {
+		if remaining := time.Unix(int64(exp), 0).Sub(p.cache.now()); remaining < ttl {
+			ttl = remaining
+		}
+	}
+	return ttl
+}
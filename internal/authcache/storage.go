@@ -0,0 +1,158 @@
+package authcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+)
+
+// mappingsKey caches all the attribute-to-roles mappings together: a request looks up each of its claims, most of
+// them mapped to no role.
+const mappingsKey = "attribute-to-roles"
+
+// Storage caches the roles and the attribute-to-roles mappings of a storage. Its writes invalidate them.
+type Storage struct {
+	storage.Interface
+	cache *Cache
+}
+
+// NewStorage wraps a storage with the cache.
+func NewStorage(s storage.Interface, cache *Cache) *Storage {
+	return &Storage{Interface: s, cache: cache}
+}
+
+func roleKey(name string) string {
+	return "role:" + name
+}
+
+// GetRole gets a role from the cache, or from the storage.
+func (s *Storage) GetRole(ctx context.Context, name string) (storage.RoleConfig, error) {
+	var role storage.RoleConfig
+	if value, ok := s.cache.get(roleKey(name), s.cache.ttl); ok && json.Unmarshal(value, &role) == nil {
+		return role, nil
+	}
+	role, err := s.Interface.GetRole(ctx, name)
+	if err != nil {
+		return role, err
+	}
+	if value, err := json.Marshal(role); err == nil {
+		s.cache.set(roleKey(name), value, s.cache.ttl)
+	}
+	return role, nil
+}
+
+// GetRoles gets the roles of the names from the cache, and the others from the storage in a single lookup.
+func (s *Storage) GetRoles(ctx context.Context, names []string) ([]storage.RoleConfig, error) {
+	roles := make([]storage.RoleConfig, 0, len(names))
+	var missing []string
+	for _, name := range names {
+		var role storage.RoleConfig
+		if value, ok := s.cache.get(roleKey(name), s.cache.ttl); ok && json.Unmarshal(value, &role) == nil {
+			roles = append(roles, role)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		fetched, err := s.Interface.GetRoles(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range fetched {
+			if value, err := json.Marshal(role); err == nil {
+				s.cache.set(roleKey(role.Name), value, s.cache.ttl)
+			}
+		}
+		roles = append(roles, fetched...)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return slices.CompactFunc(roles, func(a, b storage.RoleConfig) bool { return a.Name == b.Name }), nil
+}
+
+// SetRole sets a role in the storage and invalidates it.
+func (s *Storage) SetRole(ctx context.Context, role storage.RoleConfig) error {
+	if err := s.Interface.SetRole(ctx, role); err != nil {
+		return err
+	}
+	s.cache.invalidate(roleKey(role.Name))
+	return nil
+}
+
+// DeleteRole deletes a role from the storage and invalidates it, with the mappings which may reference it.
+func (s *Storage) DeleteRole(ctx context.Context, name string) error {
+	if err := s.Interface.DeleteRole(ctx, name); err != nil {
+		return err
+	}
+	s.cache.invalidate(roleKey(name), mappingsKey)
+	return nil
+}
+
+// GetAttributeToRoles gets an attribute-to-roles mapping from the cached mappings, or from the storage.
+func (s *Storage) GetAttributeToRoles(ctx context.Context, attributeKey, attributeValue string) (storage.AttributeToRolesConfig, error) {
+	mappings, err := s.attributeToRoles(ctx)
+	if err != nil {
+		return storage.AttributeToRolesConfig{}, err
+	}
+	for _, mapping := range mappings {
+		if mapping.AttributeKey == attributeKey && mapping.AttributeValue == attributeValue {
+			return mapping, nil
+		}
+	}
+	return storage.AttributeToRolesConfig{}, fmt.Errorf("attribute to roles not found")
+}
+
+// GetAttributeToRolesForClaims gets the mappings of the attribute pairs from the cached mappings, or from the storage.
+func (s *Storage) GetAttributeToRolesForClaims(ctx context.Context, pairs []storage.AttributePair) ([]storage.AttributeToRolesConfig, error) {
+	mappings, err := s.attributeToRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[storage.AttributePair]bool, len(pairs))
+	for _, pair := range pairs {
+		wanted[pair] = true
+	}
+	matched := []storage.AttributeToRolesConfig{}
+	for _, mapping := range mappings {
+		if wanted[storage.AttributePair{Key: mapping.AttributeKey, Value: mapping.AttributeValue}] {
+			matched = append(matched, mapping)
+		}
+	}
+	return matched, nil
+}
+
+func (s *Storage) attributeToRoles(ctx context.Context) ([]storage.AttributeToRolesConfig, error) {
+	var mappings []storage.AttributeToRolesConfig
+	if value, ok := s.cache.get(mappingsKey, s.cache.ttl); ok && json.Unmarshal(value, &mappings) == nil {
+		return mappings, nil
+	}
+	mappings, err := s.Interface.ListAttributeToRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if value, err := json.Marshal(mappings); err == nil {
+		s.cache.set(mappingsKey, value, s.cache.ttl)
+	}
+	return mappings, nil
+}
+
+// SetAttributeToRoles sets an attribute-to-roles mapping in the storage and invalidates the mappings.
+func (s *Storage) SetAttributeToRoles(ctx context.Context, attributeToRoles storage.AttributeToRolesConfig) error {
+	if err := s.Interface.SetAttributeToRoles(ctx, attributeToRoles); err != nil {
+		return err
+	}
+	s.cache.invalidate(mappingsKey)
+	return nil
+}
+
+// DeleteAttributeToRoles deletes an attribute-to-roles mapping from the storage and invalidates the mappings.
+func (s *Storage) DeleteAttributeToRoles(ctx context.Context, attributeKey, attributeValue string) error {
+	if err := s.Interface.DeleteAttributeToRoles(ctx, attributeKey, attributeValue); err != nil {
+		return err
+	}
+	s.cache.invalidate(mappingsKey)
+	return nil
+}
@@ -0,0 +1,410 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/pkg/fips"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEncryptionKey = "000102030405060708090a0b0c0d0e0f"
+
+func TestVerify(t *testing.T) {
+	// The fips crypto backend is only available when the tests run with the Go Cryptographic Module.
+	fipsErrors := []string{"requires the Go Cryptographic Module"}
+	if fips.ModuleEnabled() {
+		fipsErrors = nil
+	}
+	for _, test := range []struct {
+		name           string
+		update         func(*Config)
+		expectedErrors []string
+	}{
+		{name: "default", update: func(*Config) {}},
+		{name: "postgres", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://localhost:5432/mcp"
+			c.BackendConfig.EncryptionKey = testEncryptionKey
+		}},
+		{name: "unknown engine", update: func(c *Config) { c.BackendConfig.Engine = "mysql" },
+			expectedErrors: []string{"backend engine must be 'memory' or 'postgres'"}},
+		{name: "invalid backend", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "mysql://localhost/mcp"
+			c.BackendConfig.EncryptionKey = "abc"
+		}, expectedErrors: []string{"backend URI scheme", "encryption key must be a hex encoded key"}},
+		{name: "rotated keys", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://localhost:5432/mcp"
+			c.BackendConfig.EncryptionKey = testEncryptionKey
+			c.BackendConfig.EncryptionKeyID = "2025-10"
+			c.BackendConfig.PreviousEncryptionKeys = []string{"2025-01:" + testEncryptionKey}
+		}},
+		{name: "invalid previous key", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://localhost:5432/mcp"
+			c.BackendConfig.EncryptionKey = testEncryptionKey
+			c.BackendConfig.PreviousEncryptionKeys = []string{testEncryptionKey}
+		}, expectedErrors: []string{"--backend-previous-encryption-keys"}},
+		{name: "custom schema", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://localhost:5432/mcp"
+			c.BackendConfig.EncryptionKey = testEncryptionKey
+			c.BackendConfig.Schema = "team_ai_gateway"
+		}},
+		{name: "invalid schema", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://localhost:5432/mcp"
+			c.BackendConfig.EncryptionKey = testEncryptionKey
+			c.BackendConfig.Schema = "gateway; DROP TABLE proxy"
+		}, expectedErrors: []string{"--backend-schema"}},
+		{name: "invalid connect retries", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://localhost:5432/mcp"
+			c.BackendConfig.EncryptionKey = testEncryptionKey
+			c.BackendConfig.ConnectBackoff = 0
+		}, expectedErrors: []string{"--backend-connect-backoff"}},
+		{name: "kms encryption", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://localhost:5432/mcp"
+			c.BackendConfig.Encryption.Provider = "gcp-kms"
+			c.BackendConfig.Encryption.KMSKey = "projects/p/locations/global/keyRings/r/cryptoKeys/k"
+		}},
+		{name: "invalid kms encryption", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://localhost:5432/mcp"
+			c.BackendConfig.EncryptionKey = "abc"
+			c.BackendConfig.Encryption.Provider = "aws-kms"
+			c.BackendConfig.Encryption.KMSKey = "alias/gateway"
+			c.BackendConfig.Encryption.KMSTimeout = 0
+		}, expectedErrors: []string{"AWS key must be a KMS key ARN", "--backend-encryption-kms-timeout", "encryption key must be a hex encoded key"}},
+		{name: "unknown encryption provider", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://localhost:5432/mcp"
+			c.BackendConfig.Encryption.Provider = "vault"
+		}, expectedErrors: []string{"encryption provider must be one of 'local', 'aws-kms', 'gcp-kms', 'azure-keyvault'"}},
+		{name: "vault", update: func(c *Config) {
+			c.Vault.Address = "https://vault:8200"
+			c.Vault.AuthMethod = VaultAuthMethodKubernetes
+			c.Vault.KubernetesRole = "mcp-gateway"
+		}},
+		{name: "invalid vault", update: func(c *Config) {
+			c.Vault.Address = "vault:8200"
+			c.Vault.CacheTTL = 0
+		}, expectedErrors: []string{"--vault-address", "--vault-token", "--vault-cache-ttl"}},
+		{name: "invalid secrets", update: func(c *Config) {
+			c.Secrets.AllowedEnv = []string{"GITHUB_[TOKEN"}
+			c.Secrets.AllowedDirs = []string{"secrets"}
+		}, expectedErrors: []string{"--secrets-allowed-env", "--secrets-allowed-dirs"}},
+		{name: "identity metrics", update: func(c *Config) {
+			c.Metrics.RoleLabel = true
+			c.Metrics.SubjectLabel = true
+		}},
+		{name: "invalid identity metrics", update: func(c *Config) {
+			c.Metrics.RoleLabel = true
+			c.Metrics.MaxLabelValues = 0
+		}, expectedErrors: []string{"--metrics-max-label-values"}},
+		{name: "OTLP metrics", update: func(c *Config) {
+			c.Metrics.OTLPEndpoint = "https://otel-collector:4318/v1/metrics"
+			c.Metrics.OTLPHeaders = []string{"Authorization=Bearer token"}
+		}},
+		{name: "invalid OTLP metrics", update: func(c *Config) {
+			c.Metrics.OTLPEndpoint = "otel-collector:4318"
+			c.Metrics.OTLPHeaders = []string{"Authorization"}
+			c.Metrics.OTLPInterval = 0
+		}, expectedErrors: []string{"--metrics-otlp-endpoint", "--metrics-otlp-headers", "--metrics-otlp-interval"}},
+		{name: "event export", update: func(c *Config) {
+			c.EventExport.Sink = EventExportSinkNATS
+			c.EventExport.URL = "tls://nats:4222"
+			c.EventExport.Format = EventExportFormatCloudEvents
+			c.EventExport.Types = []string{"tool_call", "admin_mutation"}
+		}},
+		{name: "invalid event export", update: func(c *Config) {
+			c.EventExport.Sink = EventExportSinkKafka
+			c.EventExport.URL = "nats://nats:4222"
+			c.EventExport.Topic = "mcp-gateway.>"
+			c.EventExport.Format = "avro"
+			c.EventExport.Types = []string{"tool_calls"}
+			c.EventExport.BufferSize = 0
+		}, expectedErrors: []string{"--event-export-url", "--event-export-topic", "--event-export-format",
+			"--event-export-types", "--event-export-buffer-size"}},
+		{name: "unknown event export sink", update: func(c *Config) { c.EventExport.Sink = "kinesis" },
+			expectedErrors: []string{"--event-export-sink", "event export URL is required"}},
+		{name: "approval notifications", update: func(c *Config) {
+			c.Approval.WebhookURL = "https://approvals.example.com/hook"
+			c.Approval.SlackWebhookURL = "https://hooks.slack.com/services/T0/B0/x"
+		}},
+		{name: "invalid approval", update: func(c *Config) {
+			c.Approval.Timeout = c.HTTP.Timeouts.MCP
+			c.Approval.WebhookURL = "approvals.example.com"
+		}, expectedErrors: []string{"--approval-timeout", "--approval-webhook-url"}},
+		{name: "trace", update: func(c *Config) {
+			c.Trace.Enabled = true
+			c.Trace.Retention = 24 * time.Hour
+		}},
+		{name: "invalid trace retention", update: func(c *Config) { c.Trace.Retention = 0 },
+			expectedErrors: []string{"--trace-retention"}},
+		{name: "slo", update: func(c *Config) {
+			c.SLO.Enabled = true
+			c.SLO.Objectives = []string{"github:availability=0.995", "github:latency=0.9"}
+		}},
+		{name: "invalid slo", update: func(c *Config) {
+			c.SLO.Enabled = true
+			c.SLO.Availability = 1
+			c.SLO.Latency = 0
+			c.SLO.LatencyThreshold = 0
+			c.SLO.Window = time.Minute
+			c.SLO.Objectives = []string{"github:errors=0.99"}
+		}, expectedErrors: []string{"--slo-availability", "--slo-latency", "--slo-latency-threshold", "--slo-window", "--slo-objectives"}},
+		{name: "screening", update: func(c *Config) {
+			c.Screening.Enabled = true
+			c.Screening.DenyPatterns = []string{`(?i)internal-only`}
+			c.Screening.Action = ScreeningActionFlag
+		}},
+		{name: "invalid screening", update: func(c *Config) {
+			c.Screening.Rules = []string{"secrets", "xss"}
+			c.Screening.DenyPatterns = []string{"("}
+			c.Screening.Action = "drop"
+		}, expectedErrors: []string{"unknown screening rule", "--screening-deny-patterns", "--screening-action"}},
+		{name: "screening without rules", update: func(c *Config) {
+			c.Screening.Enabled = true
+			c.Screening.Rules = nil
+		}, expectedErrors: []string{"--screening-rules"}},
+		{name: "guardrail", update: func(c *Config) {
+			c.Guardrail.URL = "https://guardrail.example.com/v1/check"
+			c.Guardrail.Stages = []string{GuardrailStageRequest}
+		}},
+		{name: "invalid guardrail", update: func(c *Config) {
+			c.Guardrail.URL = "guardrail.example.com"
+			c.Guardrail.Stages = []string{"stream"}
+			c.Guardrail.Timeout = 0
+		}, expectedErrors: []string{"--guardrail-url", "--guardrail-stages", "--guardrail-timeout"}},
+		{name: "masking", update: func(c *Config) { c.Masking.Enabled = true }},
+		{name: "invalid masking", update: func(c *Config) { c.Masking.Patterns = []string{"[a-"} },
+			expectedErrors: []string{"--masking-patterns"}},
+		{name: "masking without patterns", update: func(c *Config) {
+			c.Masking.Enabled = true
+			c.Masking.Patterns = nil
+		}, expectedErrors: []string{"at least one masking pattern"}},
+		{name: "unknown crypto backend", update: func(c *Config) { c.Crypto.Backend = "boring" },
+			expectedErrors: []string{"crypto backend must be one of 'fips', 'standard'"}},
+		{name: "fips crypto backend", update: func(c *Config) { c.Crypto.Backend = "fips" }, expectedErrors: fipsErrors},
+		{name: "missing backend", update: func(c *Config) { c.BackendConfig.Engine = "postgres" },
+			expectedErrors: []string{"backend URI is required", "encryption key is required"}},
+		{name: "incomplete okta", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "okta"
+			c.AuthProvider.Okta.Issuer = "https://example.okta.com/oauth2/default"
+			c.AuthProvider.Okta.OrgURL = "example.okta.com"
+		}, expectedErrors: []string{"--okta-client-id", "--okta-private-key", "--okta-private-key-id", "invalid okta org URL"}},
+		{name: "unsupported provider", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "auth0"
+		}, expectedErrors: []string{`auth provider "auth0" is not supported`}},
+		{name: "firebase auth provider", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "firebase"
+			c.AuthProvider.Firebase = &FirebaseConfig{ProjectID: "acme", AppCheck: true, ProjectNumber: "123456789"}
+		}},
+		{name: "invalid firebase auth provider", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "firebase"
+			c.AuthProvider.Firebase = &FirebaseConfig{AppCheck: true, ProjectNumber: "acme"}
+		}, expectedErrors: []string{"--firebase-project-id", "--firebase-project-number"}},
+		{name: "negative auth provider health check interval", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "okta"
+			c.AuthProvider.HealthCheckInterval = -time.Second
+		}, expectedErrors: []string{"--auth-provider-health-check-interval"}},
+		{name: "negative auth provider clock skew", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "okta"
+			c.AuthProvider.ClockSkew = -time.Second
+		}, expectedErrors: []string{"--auth-provider-clock-skew"}},
+		{name: "negative auth provider max token age", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "okta"
+			c.AuthProvider.MaxTokenAge = -time.Hour
+		}, expectedErrors: []string{"--auth-provider-max-token-age"}},
+		{name: "invalid auth provider required claims", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "okta"
+			c.AuthProvider.RequiredClaims = []string{"email_verified"}
+		}, expectedErrors: []string{"--auth-provider-required-claims"}},
+		{name: "invalid okta required claims", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "okta"
+			c.AuthProvider.Okta = &OktaConfig{Issuer: "https://example.okta.com/oauth2/default", OrgURL: "https://example.okta.com",
+				ClientID: "client", PrivateKey: "not a pem", PrivateKeyID: "kid", RequiredClaims: []string{"tenant"}}
+		}, expectedErrors: []string{"okta private key must be a PEM", "--okta-required-claims"}},
+		{name: "inconsistent oauth", update: func(c *Config) {
+			c.OAuth.Enabled = true
+			c.OAuth.AuthorizationServers = []string{"not a url"}
+		}, expectedErrors: []string{"enabled auth provider", "OAuth resource is required", "invalid OAuth authorization server"}},
+		{name: "authorization server metadata without oauth", update: func(c *Config) {
+			c.OAuth.ProxyAuthorizationServerMetadata = true
+		}, expectedErrors: []string{"authorization server metadata proxying requires OAuth"}},
+		{name: "invalid oauth metadata", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "okta"
+			c.AuthProvider.Okta = &OktaConfig{Issuer: "https://example.okta.com/oauth2/default", OrgURL: "https://example.okta.com",
+				ClientID: "client", PrivateKey: "not a pem", PrivateKeyID: "kid"}
+			c.OAuth.Enabled = true
+			c.OAuth.Resource = "https://mcp.example.com/#mcp"
+			c.OAuth.AuthorizationServers = []string{"https://example.okta.com/oauth2/default"}
+			c.OAuth.BearerMethodsSupported = []string{"header", "Bearer"}
+			c.OAuth.ScopesSupported = []string{"openid", "mcp tools"}
+			c.OAuth.Audiences = []string{"api://default", ""}
+		}, expectedErrors: []string{"okta private key must be a PEM", "must not contain a fragment",
+			`OAuth bearer method must be 'header', 'body' or 'query', got "Bearer"`, `OAuth scope must be a non-empty value without spaces, got "mcp tools"`,
+			"OAuth audience must not be empty"}},
+		{name: "invalid oauth protected resources", update: func(c *Config) {
+			c.AuthProvider.Enabled = true
+			c.AuthProvider.Name = "okta"
+			c.AuthProvider.Okta = &OktaConfig{Issuer: "https://example.okta.com/oauth2/default", OrgURL: "https://example.okta.com",
+				ClientID: "client", PrivateKey: "not a pem", PrivateKeyID: "kid"}
+			c.OAuth.Enabled = true
+			c.OAuth.Resource = "https://mcp.example.com/mcp"
+			c.OAuth.AuthorizationServers = []string{"https://example.okta.com/oauth2/default"}
+			c.OAuth.ProtectedResources = []ProtectedResourceConfig{
+				{Resource: "https://mcp.example.com/team-a/mcp", ScopesSupported: []string{"mcp tools"}},
+				{Resource: "https://mcp.example.com/team-a/mcp/"},
+				{Resource: "https://tenant.example.com"},
+				{Resource: "tenant.example.com/mcp"},
+			}
+		}, expectedErrors: []string{"okta private key must be a PEM",
+			`OAuth scope must be a non-empty value without spaces, got "mcp tools" (oauth.protectedResources[0])`,
+			`duplicate OAuth protected resource "https://mcp.example.com/team-a/mcp/" (oauth.protectedResources[1])`,
+			`"https://tenant.example.com" must have a path for its MCP endpoint`,
+			"invalid OAuth protected resource (oauth.protectedResources[3])"}},
+		{name: "cors", update: func(c *Config) {
+			c.HTTP.CORS.AllowedOrigins = []string{"https://example.com", "https://*.example.com", "http://localhost:3000"}
+			c.HTTP.CORS.AllowedHeaders = []string{"Content-Type", "Mcp-Session-Id"}
+		}},
+		{name: "invalid cors", update: func(c *Config) {
+			c.HTTP.CORS.AllowedOrigins = []string{"https://example.com/", "example.com"}
+			c.HTTP.CORS.AllowedMethods = []string{"GET", "get"}
+			c.HTTP.CORS.AllowedHeaders = []string{"Content Type"}
+		}, expectedErrors: []string{`invalid CORS allowed origin "https://example.com/"`, `invalid CORS allowed origin "example.com"`,
+			`invalid CORS allowed method "get"`, `invalid CORS allowed header "Content Type"`}},
+		{name: "cors without origins", update: func(c *Config) { c.HTTP.CORS.AllowedOrigins = nil },
+			expectedErrors: []string{"at least one CORS allowed origin"}},
+		{name: "disabled cors", update: func(c *Config) {
+			c.HTTP.CORS.Enabled = false
+			c.HTTP.CORS.AllowedOrigins = nil
+		}},
+		{name: "invalid networks", update: func(c *Config) {
+			c.HTTP.AdminIPAccess.AllowedCIDRs = []string{"10.0.0.0/8", "10.0.0.0/33"}
+			c.HTTP.TrustedProxies = []string{"proxy"}
+		}, expectedErrors: []string{"invalid admin IP access allowed CIDR", "invalid trusted proxy"}},
+		{name: "admin API key hash", update: func(c *Config) {
+			c.HTTP.AdminAPIKey = ""
+			c.HTTP.AdminAPIKeyHash = "$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$aGFzaA"
+		}},
+		{name: "invalid admin API key hash", update: func(c *Config) {
+			c.HTTP.AdminAPIKeyHash = "5f4dcc3b5aa765d61d8327deb882cf99"
+		}, expectedErrors: []string{"--http-admin-api-key-hash"}},
+		{name: "missing admin API key", update: func(c *Config) { c.HTTP.AdminAPIKey = "" },
+			expectedErrors: []string{"admin API key or its hash is required"}},
+		{name: "invalid log", update: func(c *Config) {
+			c.Log.Level = "verbose"
+			c.Log.Redaction.KeyPatterns = []string{"("}
+			c.Log.Sampling.Thereafter = -1
+		}, expectedErrors: []string{"invalid log level", "invalid redaction key pattern", "--log-sampling-thereafter"}},
+		{name: "log sampling disabled", update: func(c *Config) { c.Log.Sampling.Initial = 0 }},
+		{name: "log to syslog only", update: func(c *Config) {
+			c.Log.OutputPaths = nil
+			c.Log.Syslog.Enabled = true
+			c.Log.Syslog.Facility = "local0"
+		}},
+		{name: "invalid log outputs", update: func(c *Config) {
+			c.Log.OutputPaths = nil
+			c.Log.Rotation.MaxBackups = -1
+		}, expectedErrors: []string{"--log-output-paths", "--log-rotation-max-backups"}},
+		{name: "invalid log syslog", update: func(c *Config) {
+			c.Log.Syslog.Enabled = true
+			c.Log.Syslog.Address = "syslog:514"
+			c.Log.Syslog.Network = "http"
+			c.Log.Syslog.Facility = "local9"
+		}, expectedErrors: []string{"unknown syslog facility", "--log-syslog-network"}},
+		{name: "invalid proxy", update: func(c *Config) { c.Proxy.CacheTTL = 0; c.Proxy.CallTimeout = 0 },
+			expectedErrors: []string{"proxy cache TTL", "proxy call timeout"}},
+		{name: "invalid proxy concurrency", update: func(c *Config) { c.Proxy.MaxConcurrentCalls = -1; c.Proxy.QueueTimeout = -time.Second },
+			expectedErrors: []string{"--proxy-max-concurrent-calls", "--proxy-queue-timeout"}},
+		{name: "proxy leader election without postgres", update: func(c *Config) { c.Proxy.LeaderElection = true },
+			expectedErrors: []string{"--proxy-leader-election"}},
+		{name: "invalid response size", update: func(c *Config) {
+			c.Proxy.MaxResponseBytes = -1
+			c.Proxy.ResponseSizePolicy = "drop"
+		}, expectedErrors: []string{"--proxy-max-response-bytes", "--proxy-response-size-policy"}},
+		{name: "socks5 egress proxy", update: func(c *Config) { c.Proxy.EgressProxy = "socks5://egress.internal:1080" }},
+		{name: "invalid egress proxy", update: func(c *Config) { c.Proxy.EgressProxy = "ftp://egress.internal" },
+			expectedErrors: []string{"--proxy-egress-url"}},
+		{name: "proxy leader election behind pgbouncer", update: func(c *Config) {
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://pgbouncer:6432/mcp"
+			c.BackendConfig.EncryptionKey = testEncryptionKey
+			c.BackendConfig.PgBouncer = true
+			c.Proxy.LeaderElection = true
+		}, expectedErrors: []string{"cannot be used behind PgBouncer"}},
+		{name: "invalid auth cache", update: func(c *Config) {
+			c.AuthCache.URL = "http://redis:6379"
+			c.AuthCache.Prefix = "mcp gateway"
+			c.AuthCache.TTL = 0
+		}, expectedErrors: []string{"--auth-cache-url", "--auth-cache-prefix", "--auth-cache-ttl"}},
+		{name: "invalid session", update: func(c *Config) {
+			c.Session.Stateful = true
+			c.Session.Store = SessionStoreRedis
+			c.Session.IdleTimeout = 0
+		}, expectedErrors: []string{"--session-redis-url", "--session-idle-timeout"}},
+		{name: "dev mode with postgres", update: func(c *Config) {
+			c.Dev.Enabled = true
+			c.BackendConfig.Engine = "postgres"
+			c.BackendConfig.URI = "postgres://localhost:5432/mcp"
+			c.BackendConfig.EncryptionKey = testEncryptionKey
+		}, expectedErrors: []string{"--dev"}},
+		{name: "unknown session store", update: func(c *Config) { c.Session.Stateful = true; c.Session.Store = "etcd" },
+			expectedErrors: []string{"--session-store"}},
+		{name: "invalid upstream sessions", update: func(c *Config) {
+			c.Proxy.UpstreamSessionIdleTimeout = 0
+			c.Proxy.MaxUpstreamSessions = -1
+			c.Proxy.UpstreamKeepaliveInterval = -time.Second
+		}, expectedErrors: []string{"--proxy-upstream-session-idle-timeout", "--proxy-max-upstream-sessions",
+			"--proxy-upstream-keepalive-interval"}},
+		{name: "negative drain timeout and keepalive interval", update: func(c *Config) {
+			c.HTTP.Timeouts.Drain = -time.Second
+			c.HTTP.Timeouts.Keepalive = -time.Second
+		}, expectedErrors: []string{"--http-drain-timeout", "--http-keepalive-interval"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultConfig()
+			test.update(config)
+
+			err := config.Verify()
+			if len(test.expectedErrors) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Len(t, strings.Split(err.Error(), "\n"), len(test.expectedErrors))
+			for _, expected := range test.expectedErrors {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
+
+func TestRequiredClaimMap(t *testing.T) {
+	config := &AuthProviderConfig{
+		Name:           "okta",
+		RequiredClaims: []string{"email_verified=true"},
+		Okta:           &OktaConfig{RequiredClaims: []string{" tenant = acme "}},
+	}
+	assert.Equal(t, map[string]string{"email_verified": "true", "tenant": "acme"}, config.RequiredClaimMap())
+
+	config.Name = "other"
+	assert.Equal(t, map[string]string{"email_verified": "true"}, config.RequiredClaimMap(), "the Okta claims only apply to the Okta provider")
+}
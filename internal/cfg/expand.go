@@ -0,0 +1,61 @@
+package cfg
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// envReference matches the ${NAME} and ${NAME:-default} references of the config file, and the $${NAME} escapes.
+var envReference = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// ExpandEnv replaces the ${NAME} references to environment variables in the string values of the settings read
+// from the config file, looked up with lookup. ${NAME:-default} falls back to default when NAME is unset or
+// empty, and $${NAME} is kept as ${NAME}. The variables referenced without default but unset are reported at once.
+func ExpandEnv(settings map[string]any, lookup func(string) (string, bool)) (map[string]any, error) {
+	var missing []string
+	expand := func(value string) string {
+		return envReference.ReplaceAllStringFunc(value, func(reference string) string {
+			if strings.HasPrefix(reference, "$$") {
+				return reference[1:]
+			}
+			match := envReference.FindStringSubmatch(reference)
+			name, fallback, hasFallback := match[1], match[2], strings.Contains(reference, ":-")
+			value, ok := lookup(name)
+			switch {
+			case hasFallback && value == "":
+				return fallback
+			case !ok:
+				if !slices.Contains(missing, name) {
+					missing = append(missing, name)
+				}
+			}
+			return value
+		})
+	}
+
+	var walk func(value any) any
+	walk = func(value any) any {
+		switch v := value.(type) {
+		case string:
+			return expand(v)
+		case map[string]any:
+			for key, item := range v {
+				v[key] = walk(item)
+			}
+		case []any:
+			for i, item := range v {
+				v[i] = walk(item)
+			}
+		}
+		return value
+	}
+	walk(settings)
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return nil, fmt.Errorf("the config file references unset environment variables: %s", strings.Join(missing, ", "))
+	}
+	return settings, nil
+}
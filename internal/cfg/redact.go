@@ -0,0 +1,105 @@
+package cfg
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// redactedValue replaces the secrets of the redacted configuration.
+const redactedValue = "[REDACTED]"
+
+// Redacted returns the configuration as a tree keyed like the config file, to show the settings in use. The
+// secrets are masked: the fields which are not logged, when set, and the passwords of the URLs.
+func (cfg *Config) Redacted() map[string]any {
+	redacted, _ := redactValue(reflect.ValueOf(cfg), false).(map[string]any)
+	return redacted
+}
+
+func redactValue(v reflect.Value, secret bool) any {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem(), secret)
+	}
+	if secret && !v.IsZero() {
+		if v.Kind() == reflect.Slice {
+			masks := make([]any, v.Len())
+			for i := range masks {
+				masks[i] = redactedValue
+			}
+			return masks
+		}
+		return redactedValue
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			out[configKey(field.Name)] = redactValue(v.Field(i), field.Tag.Get("json") == "-")
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = redactValue(v.Index(i), secret)
+		}
+		return out
+	case reflect.String:
+		return redactURL(v.String())
+	}
+	if duration, ok := v.Interface().(time.Duration); ok {
+		return duration.String()
+	}
+	return v.Interface()
+}
+
+// redactURL masks the password of a URL, e.g. of the event export URL. The other values are returned as is.
+func redactURL(value string) string {
+	if !strings.Contains(value, "://") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, ok := u.User.Password(); !ok {
+		return value
+	}
+	// The URL is not rebuilt, which would escape the mask
+	username := url.User(u.User.Username()).String()
+	if masked := strings.Replace(value, u.User.String()+"@", username+":"+redactedValue+"@", 1); masked != value {
+		return masked
+	}
+	// The user info is written in another form than its parsed value, the URL is masked as a whole
+	return redactedValue
+}
+
+// configKey returns the config file key of a field, in lower camel case: HTTP is http and OTLPEndpoint
+// otlpEndpoint.
+func configKey(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	// The last capital of an acronym starts the next word
+	if upper > 1 && upper < len(runes) {
+		upper--
+	}
+	for i := range upper {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,127 @@
+// Package devserver is the sample upstream MCP server of the dev mode. It runs in the gateway process, on a
+// loopback port, so the gateway can be tried without standing up external MCP servers.
+package devserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/pkg/version"
+)
+
+// maxSleep caps the duration of the sleep tool.
+const maxSleep = 30 * time.Second
+
+// Server is the sample MCP server, listening on the loopback interface.
+type Server struct {
+	listener   net.Listener
+	httpServer *http.Server
+}
+
+// Start starts the sample MCP server on a random loopback port.
+func Start() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	s := &Server{
+		listener: listener,
+		httpServer: &http.Server{
+			Handler:           server.NewStreamableHTTPServer(NewMCPServer(), server.WithStateLess(true)),
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+	go func() {
+		_ = s.httpServer.Serve(listener)
+	}()
+	return s, nil
+}
+
+// URL returns the URL of the MCP endpoint of the server.
+func (s *Server) URL() string {
+	return "http://" + s.listener.Addr().String() + "/mcp"
+}
+
+// Shutdown stops the server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// NewMCPServer creates the MCP server with the sample tools.
+func NewMCPServer() *server.MCPServer {
+	mcpServer := server.NewMCPServer("MCP Gateway dev server", version.VERSION, server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("echo",
+		mcp.WithDescription("Return the message"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("message", mcp.Required(), mcp.Description("The message to return")),
+	), echo)
+	mcpServer.AddTool(mcp.NewTool("add",
+		mcp.WithDescription("Add two numbers"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("a", mcp.Required(), mcp.Description("The first number")),
+		mcp.WithNumber("b", mcp.Required(), mcp.Description("The second number")),
+	), add)
+	mcpServer.AddTool(mcp.NewTool("current_time",
+		mcp.WithDescription("Return the current time in a time zone"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("timezone", mcp.Description("The IANA time zone, e.g. Europe/Paris (default: UTC)")),
+	), currentTime)
+	mcpServer.AddTool(mcp.NewTool("sleep",
+		mcp.WithDescription("Wait before returning, to try the timeouts and the graceful shutdown"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("seconds", mcp.Required(), mcp.Min(0), mcp.Max(maxSleep.Seconds()), mcp.Description("How long to wait")),
+	), sleep)
+	return mcpServer
+}
+
+func echo(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	message, err := request.RequireString("message")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(message), nil
+}
+
+func add(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	a, err := request.RequireFloat("a")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	b, err := request.RequireFloat("b")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprint(a + b)), nil
+}
+
+func currentTime(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	location, err := time.LoadLocation(strings.TrimSpace(request.GetString("timezone", "UTC")))
+	if err != nil {
+		return mcp.NewToolResultErrorf("unknown time zone: %v", err), nil
+	}
+	return mcp.NewToolResultText(time.Now().In(location).Format(time.RFC3339)), nil
+}
+
+func sleep(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	seconds, err := request.RequireFloat("seconds")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	duration := time.Duration(seconds * float64(time.Second))
+	if duration < 0 || duration > maxSleep {
+		return mcp.NewToolResultErrorf("seconds must be between 0 and %v", maxSleep.Seconds()), nil
+	}
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+		return nil, errors.New("the sleep was canceled")
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("slept %v", duration)), nil
+}
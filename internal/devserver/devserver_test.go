@@ -0,0 +1,59 @@
+package devserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	server, err := Start()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+
+	ctx := context.Background()
+	mcpClient, err := client.NewStreamableHttpClient(server.URL())
+	require.NoError(t, err)
+	defer mcpClient.Close() //nolint:errcheck // test
+	initialize := mcp.InitializeRequest{}
+	initialize.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = mcpClient.Initialize(ctx, initialize)
+	require.NoError(t, err)
+
+	tools, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	names := make([]string, 0, len(tools.Tools))
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"echo", "add", "current_time", "sleep"}, names)
+
+	call := func(name string, arguments map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = name
+		request.Params.Arguments = arguments
+		result, err := mcpClient.CallTool(ctx, request)
+		require.NoError(t, err)
+		return result
+	}
+	text := func(result *mcp.CallToolResult) string {
+		require.Len(t, result.Content, 1)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	assert.Equal(t, "hello", text(call("echo", map[string]any{"message": "hello"})))
+	assert.Equal(t, "3.5", text(call("add", map[string]any{"a": 1, "b": 2.5})))
+
+	now, err := time.Parse(time.RFC3339, text(call("current_time", map[string]any{"timezone": "Europe/Paris"})))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), now, time.Minute)
+	assert.True(t, call("current_time", map[string]any{"timezone": "Mars/Olympus"}).IsError)
+
+	assert.Equal(t, "slept 10ms", text(call("sleep", map[string]any{"seconds": 0.01})))
+	assert.True(t, call("sleep", map[string]any{"seconds": 60}).IsError)
+}
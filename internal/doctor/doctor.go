@@ -0,0 +1,265 @@
+// Package doctor diagnoses the connectivity of the MCP Gateway to its backend, auth provider and proxies.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/auth"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/proxy"
+	"github.com/matthisholleville/mcp-gateway/internal/secrets"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/internal/storage/migrate"
+	"github.com/matthisholleville/mcp-gateway/pkg/aescipher"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	StatusFail Status = "FAIL"
+	StatusSkip Status = "SKIP"
+)
+
+// Result is the outcome of a check, with a detail explaining it.
+type Result struct {
+	Check  string
+	Status Status
+	Detail string
+}
+
+// Options configures the diagnostics.
+type Options struct {
+	// MigrationDir is the directory of the migrations the database is compared with.
+	MigrationDir string
+
+	// Timeout bounds each check.
+	Timeout time.Duration
+}
+
+// Doctor runs the diagnostics of a configuration.
+type Doctor struct {
+	config     *cfg.Config
+	logger     logger.Logger
+	options    Options
+	httpClient *http.Client
+	// firebaseKeysURLs are the JWKS of the Firebase ID and App Check tokens
+	firebaseKeysURLs [2]string
+}
+
+// New creates a doctor for the configuration.
+//
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func New(config *cfg.Config, logger logger.Logger, options Options) *Doctor {
+	return &Doctor{
+		config:           config,
+		logger:           logger,
+		options:          options,
+		httpClient:       &http.Client{Timeout: options.Timeout},
+		firebaseKeysURLs: [2]string{auth.FirebaseIDTokenKeysURL, auth.FirebaseAppCheckKeysURL},
+	}
+}
+
+// Run runs all the checks. The migrations and proxies are checked only if the storage is reachable.
+func (d *Doctor) Run(ctx context.Context) []Result {
+	store, result := d.checkStorage(ctx)
+	if store == nil {
+		return []Result{
+			result,
+			{Check: "migrations", Status: StatusSkip, Detail: "the storage is not reachable"},
+			d.checkAuthProvider(ctx),
+			{Check: "proxies", Status: StatusSkip, Detail: "the storage is not reachable"},
+		}
+	}
+	results := []Result{result, d.checkMigrations(), d.checkAuthProvider(ctx)}
+	results = append(results, d.checkProxies(ctx, store)...)
+	return results
+}
+
+func (d *Doctor) checkStorage(ctx context.Context) (storage.Interface, Result) {
+	const check = "storage"
+	backend := d.config.BackendConfig
+	var encryptor aescipher.Cryptor
+	if backend.Engine != "memory" {
+		var err error
+		if encryptor, err = d.config.NewCryptor(); err != nil {
+			return nil, Result{Check: check, Status: StatusFail, Detail: fmt.Sprintf("encryption: %s", err)}
+		}
+	}
+
+	store, err := storage.NewStorage(ctx, backend.Engine, "", d.logger, d.config, encryptor)
+	if err != nil {
+		return nil, Result{Check: check, Status: StatusFail, Detail: fmt.Sprintf("%s: %s", backend.Engine, err)}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.options.Timeout)
+	defer cancel()
+	if _, err := store.ListProxies(ctx, false); err != nil {
+		return nil, Result{Check: check, Status: StatusFail, Detail: fmt.Sprintf("%s: %s", backend.Engine, err)}
+	}
+	if backend.Engine == "memory" {
+		return store, Result{Check: check, Status: StatusPass, Detail: "memory: no connectivity to check, the data is lost on restart"}
+	}
+	return store, Result{Check: check, Status: StatusPass, Detail: fmt.Sprintf("%s: reachable", backend.Engine)}
+}
+
+func (d *Doctor) checkMigrations() Result {
+	const check = "migrations"
+	backend := d.config.BackendConfig
+	status, err := migrate.GetStatus(&migrate.MigrationConfig{
+		Engine:       backend.Engine,
+		URI:          backend.URI,
+		Username:     backend.Username,
+		Password:     backend.Password,
+		Schema:       backend.Schema,
+		Logger:       d.logger,
+		Timeout:      d.options.Timeout,
+		MigrationDir: d.options.MigrationDir,
+	})
+	switch {
+	case err != nil:
+		return Result{Check: check, Status: StatusFail, Detail: err.Error()}
+	case status == nil:
+		return Result{Check: check, Status: StatusSkip, Detail: fmt.Sprintf("the %s engine has no migrations", backend.Engine)}
+	case status.Dirty:
+		return Result{Check: check, Status: StatusFail,
+			Detail: fmt.Sprintf("the database is dirty at version %d, a migration failed midway", status.Current)}
+	case status.Current < status.Latest:
+		return Result{Check: check, Status: StatusFail,
+			Detail: fmt.Sprintf("version %d, %d is available: run mcp-gateway migrate", status.Current, status.Latest)}
+	default:
+		return Result{Check: check, Status: StatusPass, Detail: fmt.Sprintf("version %d, up to date", status.Current)}
+	}
+}
+
+// checkAuthProvider fetches the signing keys of the auth provider.
+func (d *Doctor) checkAuthProvider(ctx context.Context) Result {
+	const check = "auth provider"
+	if !d.config.AuthProvider.Enabled {
+		return Result{Check: check, Status: StatusSkip, Detail: "disabled"}
+	}
+	switch d.config.AuthProvider.Name {
+	case "okta":
+		return d.checkOkta(ctx, check)
+	case "firebase":
+		return d.checkFirebase(ctx, check)
+	default:
+		return Result{Check: check, Status: StatusFail, Detail: fmt.Sprintf("provider %q is not supported", d.config.AuthProvider.Name)}
+	}
+}
+
+// checkOkta fetches the OpenID metadata of the Okta issuer, then its JWKS.
+func (d *Doctor) checkOkta(ctx context.Context, check string) Result {
+	issuer := strings.TrimSuffix(d.config.AuthProvider.Okta.Issuer, "/")
+	var metadata struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := d.getJSON(ctx, issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return Result{Check: check, Status: StatusFail, Detail: fmt.Sprintf("okta metadata: %s", err)}
+	}
+	if metadata.JWKSURI == "" {
+		return Result{Check: check, Status: StatusFail, Detail: "okta metadata: no jwks_uri"}
+	}
+
+	var jwks struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := d.getJSON(ctx, metadata.JWKSURI, &jwks); err != nil {
+		return Result{Check: check, Status: StatusFail, Detail: fmt.Sprintf("okta JWKS: %s", err)}
+	}
+	if len(jwks.Keys) == 0 {
+		return Result{Check: check, Status: StatusFail, Detail: "okta JWKS: no signing key"}
+	}
+	return Result{Check: check, Status: StatusPass, Detail: fmt.Sprintf("okta: %d signing key(s) at %s", len(jwks.Keys), metadata.JWKSURI)}
+}
+
+// checkFirebase fetches the JWKS of the Firebase ID tokens, and of the App Check tokens if they are required.
+func (d *Doctor) checkFirebase(ctx context.Context, check string) Result {
+	urls := d.firebaseKeysURLs[:1]
+	if d.config.AuthProvider.Firebase.AppCheck {
+		urls = d.firebaseKeysURLs[:]
+	}
+	details := make([]string, 0, len(urls))
+	for _, url := range urls {
+		var jwks struct {
+			Keys []json.RawMessage `json:"keys"`
+		}
+		if err := d.getJSON(ctx, url, &jwks); err != nil {
+			return Result{Check: check, Status: StatusFail, Detail: fmt.Sprintf("firebase JWKS: %s", err)}
+		}
+		if len(jwks.Keys) == 0 {
+			return Result{Check: check, Status: StatusFail, Detail: fmt.Sprintf("firebase JWKS: no signing key at %s", url)}
+		}
+		details = append(details, fmt.Sprintf("%d signing key(s) at %s", len(jwks.Keys), url))
+	}
+	return Result{Check: check, Status: StatusPass, Detail: "firebase: " + strings.Join(details, ", ")}
+}
+
+func (d *Doctor) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("GET %s: invalid JSON: %w", url, err)
+	}
+	return nil
+}
+
+// checkProxies dials every proxy of the storage and lists its tools.
+func (d *Doctor) checkProxies(ctx context.Context, store storage.Interface) []Result {
+	listCtx, cancel := context.WithTimeout(ctx, d.options.Timeout)
+	defer cancel()
+	proxies, err := store.ListProxies(listCtx, true)
+	if err != nil {
+		return []Result{{Check: "proxies", Status: StatusFail, Detail: err.Error()}}
+	}
+	if len(proxies) == 0 {
+		return []Result{{Check: "proxies", Status: StatusSkip, Detail: "no proxy configured"}}
+	}
+
+	resolver := secrets.NewResolver(d.config.Vault, d.config.Secrets)
+	results := make([]Result, 0, len(proxies))
+	for i := range proxies {
+		check := "proxy " + proxies[i].Name
+		probeCtx, cancel := context.WithTimeout(ctx, d.options.Timeout)
+		tools, err := proxy.Probe(probeCtx, &proxies[i], d.config.Proxy.EgressProxy, resolver, d.logger)
+		cancel()
+		if err != nil {
+			results = append(results, Result{Check: check, Status: StatusFail, Detail: fmt.Sprintf("%s: %s", proxies[i].URL, err)})
+			continue
+		}
+		results = append(results, Result{Check: check, Status: StatusPass, Detail: fmt.Sprintf("%s: %d tool(s)", proxies[i].URL, tools)})
+	}
+	return results
+}
+
+// Failed returns whether a check failed.
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package doctor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDoctor(config *cfg.Config) *Doctor {
+	return New(config, logger.MustNewLogger("json", "error", ""), Options{Timeout: 5 * time.Second})
+}
+
+func TestCheckAuthProvider(t *testing.T) {
+	var jwks string
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/oauth2/default/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"issuer":"` + ts.URL + `/oauth2/default","jwks_uri":"` + ts.URL + `/oauth2/default/v1/keys"}`))
+	})
+	mux.HandleFunc("/oauth2/default/v1/keys", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(jwks))
+	})
+
+	for _, test := range []struct {
+		name           string
+		enabled        bool
+		issuer         string
+		jwks           string
+		expectedStatus Status
+		expectedDetail string
+	}{
+		{name: "disabled", expectedStatus: StatusSkip},
+		{name: "reachable", enabled: true, issuer: ts.URL + "/oauth2/default/", jwks: `{"keys":[{"kid":"a"}]}`,
+			expectedStatus: StatusPass, expectedDetail: "1 signing key(s)"},
+		{name: "no key", enabled: true, issuer: ts.URL + "/oauth2/default", jwks: `{"keys":[]}`,
+			expectedStatus: StatusFail, expectedDetail: "no signing key"},
+		{name: "unknown issuer", enabled: true, issuer: ts.URL + "/oauth2/missing",
+			expectedStatus: StatusFail, expectedDetail: "404 Not Found"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			jwks = test.jwks
+			config := cfg.DefaultConfig()
+			config.AuthProvider.Enabled = test.enabled
+			config.AuthProvider.Name = "okta"
+			config.AuthProvider.Okta.Issuer = test.issuer
+
+			result := newTestDoctor(config).checkAuthProvider(t.Context())
+			assert.Equal(t, test.expectedStatus, result.Status)
+			assert.Contains(t, result.Detail, test.expectedDetail)
+		})
+	}
+}
+
+func TestCheckAuthProvider_Firebase(t *testing.T) {
+	appCheckKeys := `{"keys":[{"kid":"a"}]}`
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/securetoken", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"keys":[{"kid":"a"},{"kid":"b"}]}`))
+	})
+	mux.HandleFunc("/appcheck", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(appCheckKeys))
+	})
+
+	config := cfg.DefaultConfig()
+	config.AuthProvider.Enabled = true
+	config.AuthProvider.Name = "firebase"
+	doctor := newTestDoctor(config)
+	doctor.firebaseKeysURLs = [2]string{ts.URL + "/securetoken", ts.URL + "/appcheck"}
+
+	result := doctor.checkAuthProvider(t.Context())
+	assert.Equal(t, StatusPass, result.Status)
+	assert.Equal(t, "firebase: 2 signing key(s) at "+ts.URL+"/securetoken", result.Detail)
+
+	config.AuthProvider.Firebase.AppCheck = true
+	result = doctor.checkAuthProvider(t.Context())
+	assert.Equal(t, StatusPass, result.Status)
+	assert.Contains(t, result.Detail, "1 signing key(s) at "+ts.URL+"/appcheck")
+
+	appCheckKeys = `{"keys":[]}`
+	result = doctor.checkAuthProvider(t.Context())
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Equal(t, "firebase JWKS: no signing key at "+ts.URL+"/appcheck", result.Detail)
+}
+
+func TestCheckProxies(t *testing.T) {
+	mcpServer := server.NewMCPServer("upstream", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("search"), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(""), nil
+	})
+	upstream := server.NewTestStreamableHTTPServer(mcpServer)
+	defer upstream.Close()
+
+	ctx := t.Context()
+	store := storage.NewMemoryStorage("")
+	for name, url := range map[string]string{"github": upstream.URL + "/mcp", "down": "http://127.0.0.1:1/mcp"} {
+		require.NoError(t, store.SetProxy(ctx, &storage.ProxyConfig{
+			Name: name, URL: url, Type: storage.ProxyTypeStreamableHTTP, AuthType: storage.ProxyAuthTypeHeader,
+		}, false))
+	}
+
+	results := newTestDoctor(cfg.DefaultConfig()).checkProxies(ctx, store)
+	require.Len(t, results, 2)
+	byCheck := map[string]Result{}
+	for _, result := range results {
+		byCheck[result.Check] = result
+	}
+	assert.Equal(t, StatusPass, byCheck["proxy github"].Status)
+	assert.Contains(t, byCheck["proxy github"].Detail, "1 tool(s)")
+	assert.Equal(t, StatusFail, byCheck["proxy down"].Status)
+	assert.True(t, Failed(results))
+}
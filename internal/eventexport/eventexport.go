@@ -0,0 +1,170 @@
+// Package eventexport publishes the gateway events to Kafka or NATS, so the gateway activity can be streamed
+// into a SIEM.
+package eventexport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// cloudEventTypePrefix prefixes the event types in the type attribute of the CloudEvents.
+	cloudEventTypePrefix = "io.github.matthisholleville.mcp-gateway."
+
+	publishAttempts = 3
+	retryBackoff    = 200 * time.Millisecond
+)
+
+// Publisher publishes the encoded events to a sink.
+type Publisher interface {
+	// Publish publishes an encoded event of the given type, returning once the sink acknowledged it.
+	Publish(ctx context.Context, eventType events.Type, payload []byte) error
+	Close() error
+}
+
+// NewPublisher returns the publisher of the configured sink.
+func NewPublisher(config *cfg.EventExportConfig) (Publisher, error) {
+	switch config.Sink {
+	case cfg.EventExportSinkKafka:
+		return newKafkaPublisher(config)
+	case cfg.EventExportSinkNATS:
+		return newNATSPublisher(config)
+	default:
+		return nil, fmt.Errorf("unsupported event export sink %q", config.Sink)
+	}
+}
+
+// Exporter publishes the events of a broker. Each event is retried a few times before it is dropped, and the
+// events published faster than the sink accepts them are dropped once the buffer is full, so a slow or
+// unavailable sink never slows down the gateway.
+type Exporter struct {
+	config    *cfg.EventExportConfig
+	publisher Publisher
+	logger    logger.Logger
+	types     map[events.Type]bool
+
+	unsubscribe func()
+	cancel      context.CancelFunc
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// NewExporter returns an exporter publishing the configured event types with the publisher.
+func NewExporter(config *cfg.EventExportConfig, publisher Publisher, log logger.Logger) *Exporter {
+	types := map[events.Type]bool{}
+	for _, eventType := range config.Types {
+		types[events.Type(eventType)] = true
+	}
+	return &Exporter{
+		config:    config,
+		publisher: publisher,
+		logger:    log,
+		types:     types,
+		done:      make(chan struct{}),
+	}
+}
+
+// Start subscribes to the broker and publishes its events in the background, until Close is called.
+func (e *Exporter) Start(broker *events.Broker) {
+	ch, unsubscribe := broker.SubscribeBuffered(e.config.BufferSize)
+	ctx, cancel := context.WithCancel(context.Background())
+	e.unsubscribe, e.cancel = unsubscribe, cancel
+	go func() {
+		defer close(e.done)
+		for event := range ch {
+			if len(e.types) > 0 && !e.types[event.Type] {
+				continue
+			}
+			e.export(ctx, event)
+		}
+	}()
+}
+
+// Close stops the subscription, publishes the buffered events until ctx is done and closes the publisher.
+func (e *Exporter) Close(ctx context.Context) error {
+	var err error
+	e.closeOnce.Do(func() {
+		if e.unsubscribe != nil {
+			e.unsubscribe()
+			select {
+			case <-e.done:
+			case <-ctx.Done():
+				e.logger.Warn("Dropped the events not exported yet on shutdown")
+			}
+			e.cancel()
+		}
+		err = e.publisher.Close()
+	})
+	return err
+}
+
+func (e *Exporter) export(ctx context.Context, event events.Event) {
+	payload, err := Encode(event, e.config.Format, e.config.Source)
+	if err != nil {
+		e.logger.Error("Failed to encode the exported event", zap.Error(err))
+		metrics.EventsExportedCounter.WithLabelValues(string(event.Type), "error").Inc()
+		return
+	}
+
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		publishCtx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+		err = e.publisher.Publish(publishCtx, event.Type, payload)
+		cancel()
+		if err == nil {
+			metrics.EventsExportedCounter.WithLabelValues(string(event.Type), "success").Inc()
+			return
+		}
+		if attempt == publishAttempts || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+		}
+		backoff *= 2
+	}
+	e.logger.Warn("Failed to export the event, dropping it",
+		zap.String("type", string(event.Type)),
+		zap.String("sink", e.config.Sink),
+		zap.Error(err))
+	metrics.EventsExportedCounter.WithLabelValues(string(event.Type), "error").Inc()
+}
+
+// cloudEvent is a CloudEvents 1.0 event in structured mode.
+type cloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            any       `json:"data"`
+}
+
+// Encode encodes an event in the format: the event as streamed by /v1/admin/events for 'json', or a
+// CloudEvent of the source for 'cloudevents'.
+func Encode(event events.Event, format, source string) ([]byte, error) {
+	if format != cfg.EventExportFormatCloudEvents {
+		return json.Marshal(event)
+	}
+	return json.Marshal(cloudEvent{
+		SpecVersion:     "1.0",
+		ID:              uuid.NewString(),
+		Source:          source,
+		Type:            cloudEventTypePrefix + string(event.Type),
+		Time:            event.Time.UTC(),
+		DataContentType: "application/json",
+		Data:            event.Data,
+	})
+}
@@ -0,0 +1,107 @@
+package eventexport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublisher records the published events, failing the first failures publications.
+type fakePublisher struct {
+	mu        sync.Mutex
+	failures  int
+	attempts  int
+	published []events.Type
+	closed    bool
+}
+
+func (p *fakePublisher) Publish(_ context.Context, eventType events.Type, _ []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.attempts++
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("unavailable")
+	}
+	p.published = append(p.published, eventType)
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func TestEncode(t *testing.T) {
+	event := events.Event{
+		Type: events.TypeToolCall,
+		Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data: events.ToolCall{Proxy: "github", Tool: "search", Identity: "alice"},
+	}
+
+	payload, err := Encode(event, cfg.EventExportFormatJSON, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"tool_call","time":"2025-01-02T03:04:05Z",`+
+		`"data":{"proxy":"github","tool":"search","identity":"alice","isError":false}}`, string(payload))
+
+	payload, err = Encode(event, cfg.EventExportFormatCloudEvents, "mcp-gateway/eu-1")
+	require.NoError(t, err)
+	var cloudEvent map[string]any
+	require.NoError(t, json.Unmarshal(payload, &cloudEvent))
+	assert.NotEmpty(t, cloudEvent["id"])
+	delete(cloudEvent, "id")
+	assert.Equal(t, map[string]any{
+		"specversion":     "1.0",
+		"source":          "mcp-gateway/eu-1",
+		"type":            "io.github.matthisholleville.mcp-gateway.tool_call",
+		"time":            "2025-01-02T03:04:05Z",
+		"datacontenttype": "application/json",
+		"data":            map[string]any{"proxy": "github", "tool": "search", "identity": "alice", "isError": false},
+	}, cloudEvent)
+}
+
+func TestExporter(t *testing.T) {
+	config := cfg.DefaultConfig().EventExport
+	config.Types = []string{string(events.TypeToolCall), string(events.TypeAdminMutation)}
+	publisher := &fakePublisher{failures: 1}
+	broker := events.NewBroker(10)
+	exporter := NewExporter(config, publisher, logger.MustNewLogger("json", "debug", "test"))
+	exporter.Start(broker)
+
+	broker.Publish(events.TypeToolCall, events.ToolCall{Proxy: "github", Tool: "search"})
+	broker.Publish(events.TypeProxyHealth, events.ProxyHealth{Proxy: "github", Healthy: true})
+	broker.Publish(events.TypeAdminMutation, events.AdminMutation{API: "http", Operation: "DELETE /v1/admin/roles/:role"})
+
+	require.NoError(t, exporter.Close(t.Context()))
+	assert.Equal(t, 0, broker.Subscribers())
+	// The buffered events are published on close, the failed one once retried, and the proxy health is filtered out.
+	assert.Equal(t, []events.Type{events.TypeToolCall, events.TypeAdminMutation}, publisher.published)
+	assert.Equal(t, 3, publisher.attempts)
+	assert.True(t, publisher.closed)
+}
+
+func TestExporterDropsFailedEvents(t *testing.T) {
+	config := cfg.DefaultConfig().EventExport
+	publisher := &fakePublisher{failures: publishAttempts}
+	broker := events.NewBroker(10)
+	exporter := NewExporter(config, publisher, logger.MustNewLogger("json", "debug", "test"))
+	exporter.Start(broker)
+
+	broker.Publish(events.TypeToolCall, events.ToolCall{Proxy: "github", Tool: "search"})
+	broker.Publish(events.TypeToolCall, events.ToolCall{Proxy: "github", Tool: "create_issue"})
+
+	require.NoError(t, exporter.Close(t.Context()))
+	assert.Equal(t, []events.Type{events.TypeToolCall}, publisher.published)
+	assert.Equal(t, publishAttempts+1, publisher.attempts)
+}
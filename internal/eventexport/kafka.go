@@ -0,0 +1,106 @@
+package eventexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+)
+
+const (
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+	kafkaAccept      = "application/vnd.kafka.v2+json"
+	// maxKafkaErrorBody bounds the error responses of the REST Proxy read into the errors.
+	maxKafkaErrorBody = 1024
+)
+
+// kafkaPublisher produces the events to a Kafka topic through the Confluent REST Proxy API v2, keyed by
+// event type.
+type kafkaPublisher struct {
+	endpoint string
+	username string
+	password string
+	token    string
+	client   *http.Client
+}
+
+func newKafkaPublisher(config *cfg.EventExportConfig) (*kafkaPublisher, error) {
+	if _, err := url.Parse(config.URL); err != nil {
+		return nil, fmt.Errorf("invalid Kafka REST Proxy URL: %w", err)
+	}
+	return &kafkaPublisher{
+		endpoint: strings.TrimSuffix(config.URL, "/") + "/topics/" + url.PathEscape(config.Topic),
+		username: config.Username,
+		password: config.Password,
+		token:    config.Token,
+		client:   &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, eventType events.Type, payload []byte) error {
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: string(eventType), Value: payload}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", kafkaAccept)
+	switch {
+	case p.token != "":
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	case p.username != "":
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the Kafka REST Proxy: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxKafkaErrorBody))
+		return fmt.Errorf("the Kafka REST Proxy returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("invalid Kafka REST Proxy response: %w", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil || offset.Error != "" {
+			return fmt.Errorf("kafka rejected the event: %s", offset.Error)
+		}
+	}
+	return nil
+}
+
+func (p *kafkaPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,57 @@
+package eventexport
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaPublisher(t *testing.T) {
+	var response string
+	restProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/topics/mcp-gateway", r.URL.Path)
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "gateway", username)
+		assert.Equal(t, "secret", password)
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"records":[{"key":"tool_call","value":{"type":"tool_call"}}]}`, string(body))
+
+		w.Header().Set("Content-Type", "application/vnd.kafka.v2+json")
+		if response == "" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40401,"message":"Topic not found."}`))
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	defer restProxy.Close()
+
+	config := cfg.DefaultConfig().EventExport
+	config.Sink = cfg.EventExportSinkKafka
+	config.URL = restProxy.URL + "/"
+	config.Username = "gateway"
+	config.Password = "secret"
+	publisher, err := NewPublisher(config)
+	require.NoError(t, err)
+	defer publisher.Close()
+	payload := json.RawMessage(`{"type":"tool_call"}`)
+
+	response = `{"offsets":[{"partition":0,"offset":42,"error_code":null,"error":null}]}`
+	require.NoError(t, publisher.Publish(t.Context(), events.TypeToolCall, payload))
+
+	response = `{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"Kafka error"}]}`
+	assert.ErrorContains(t, publisher.Publish(t.Context(), events.TypeToolCall, payload), "Kafka error")
+
+	response = ""
+	assert.ErrorContains(t, publisher.Publish(t.Context(), events.TypeToolCall, payload), "Topic not found")
+}
@@ -0,0 +1,224 @@
+package eventexport
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/matthisholleville/mcp-gateway/pkg/fips"
+	"github.com/matthisholleville/mcp-gateway/pkg/version"
+)
+
+const (
+	natsDefaultPort = "4222"
+	natsClientName  = "mcp-gateway"
+)
+
+// natsPublisher publishes the events on the NATS subjects TOPIC.TYPE, with the core NATS protocol. Each
+// publication is followed by a PING, so it returns once the server processed it. The connection is dialed
+// on the first publication and again after any error.
+type natsPublisher struct {
+	address  string
+	tls      bool
+	subject  string
+	username string
+	password string
+	token    string
+	timeout  time.Duration
+
+	mu         sync.Mutex
+	conn       net.Conn
+	reader     *bufio.Reader
+	maxPayload int
+}
+
+// natsInfo is the part of the INFO message of the server used by the gateway.
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+	MaxPayload  int  `json:"max_payload"`
+}
+
+type natsConnect struct {
+	Verbose     bool   `json:"verbose"`
+	Pedantic    bool   `json:"pedantic"`
+	TLSRequired bool   `json:"tls_required"`
+	Name        string `json:"name"`
+	Lang        string `json:"lang"`
+	Version     string `json:"version"`
+	Protocol    int    `json:"protocol"`
+	User        string `json:"user,omitempty"`
+	Pass        string `json:"pass,omitempty"`
+	AuthToken   string `json:"auth_token,omitempty"`
+}
+
+func newNATSPublisher(config *cfg.EventExportConfig) (*natsPublisher, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+	return &natsPublisher{
+		address:  address,
+		tls:      u.Scheme == "tls",
+		subject:  config.Topic,
+		username: config.Username,
+		password: config.Password,
+		token:    config.Token,
+		timeout:  config.Timeout,
+	}, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, eventType events.Type, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return fmt.Errorf("failed to connect to NATS: %w", err)
+		}
+	}
+	if p.maxPayload > 0 && len(payload) > p.maxPayload {
+		return fmt.Errorf("the event of %d bytes exceeds the NATS maximum payload of %d bytes", len(payload), p.maxPayload)
+	}
+	if err := p.publish(ctx, p.subject+"."+string(eventType), payload); err != nil {
+		p.closeConn()
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+	return nil
+}
+
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeConn()
+	return nil
+}
+
+func (p *natsPublisher) closeConn() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+		p.conn, p.reader, p.maxPayload = nil, nil, 0
+	}
+}
+
+// connect dials the server, reads its INFO, upgrades the connection to TLS if needed, then authenticates
+// with CONNECT, checked by a PING.
+func (p *natsPublisher) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return err
+	}
+	p.conn, p.reader = conn, bufio.NewReader(conn)
+	p.setDeadline(ctx)
+
+	line, err := p.readLine()
+	if err != nil {
+		p.closeConn()
+		return err
+	}
+	infoJSON, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		p.closeConn()
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		p.closeConn()
+		return fmt.Errorf("invalid INFO: %w", err)
+	}
+	p.maxPayload = info.MaxPayload
+
+	if p.tls || info.TLSRequired {
+		host, _, _ := net.SplitHostPort(p.address)
+		tlsConn := tls.Client(conn, fips.TLSConfig(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}))
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			p.closeConn()
+			return fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		p.conn, p.reader = tlsConn, bufio.NewReader(tlsConn)
+		p.setDeadline(ctx)
+	}
+
+	connect, err := json.Marshal(natsConnect{
+		TLSRequired: p.tls || info.TLSRequired,
+		Name:        natsClientName,
+		Lang:        "go",
+		Version:     version.Get().Version,
+		Protocol:    1,
+		User:        p.username,
+		Pass:        p.password,
+		AuthToken:   p.token,
+	})
+	if err != nil {
+		p.closeConn()
+		return err
+	}
+	if _, err := fmt.Fprintf(p.conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		p.closeConn()
+		return err
+	}
+	if err := p.waitPong(); err != nil {
+		p.closeConn()
+		return err
+	}
+	return nil
+}
+
+func (p *natsPublisher) publish(ctx context.Context, subject string, payload []byte) error {
+	p.setDeadline(ctx)
+	if _, err := fmt.Fprintf(p.conn, "PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload); err != nil {
+		return err
+	}
+	return p.waitPong()
+}
+
+// waitPong reads the server messages until the PONG answering the last PING, answering the server's PINGs.
+func (p *natsPublisher) waitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := fmt.Fprint(p.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *natsPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// setDeadline bounds the next reads and writes by the publication timeout and the context's deadline.
+func (p *natsPublisher) setDeadline(ctx context.Context) {
+	deadline := time.Now().Add(p.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = p.conn.SetDeadline(deadline)
+}
@@ -0,0 +1,112 @@
+package eventexport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// natsMessage is a message received by the fake NATS server.
+type natsMessage struct {
+	subject string
+	payload string
+}
+
+// serveFakeNATS accepts NATS connections authenticated with the token, sending the published messages to
+// the channel. It closes each connection once closeAfter messages are acknowledged, if not zero.
+func serveFakeNATS(t *testing.T, token string, closeAfter int) (string, <-chan natsMessage) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	messages := make(chan natsMessage, 10)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveNATSConn(conn, token, closeAfter, messages)
+		}
+	}()
+	return "nats://" + listener.Addr().String(), messages
+}
+
+func serveNATSConn(conn net.Conn, token string, closeAfter int, messages chan<- natsMessage) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	fmt.Fprint(conn, `INFO {"server_id":"test","max_payload":64,"auth_required":true}`+"\r\n")
+	received := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		op, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch op {
+		case "CONNECT":
+			if !strings.Contains(args, `"auth_token":"`+token+`"`) {
+				fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+			if closeAfter > 0 && received == closeAfter {
+				return
+			}
+		case "PUB":
+			subject, size, _ := strings.Cut(args, " ")
+			n, _ := strconv.Atoi(size)
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			messages <- natsMessage{subject: subject, payload: string(payload[:n])}
+			received++
+		}
+	}
+}
+
+func newTestNATSPublisher(t *testing.T, url, token string) Publisher {
+	config := cfg.DefaultConfig().EventExport
+	config.Sink = cfg.EventExportSinkNATS
+	config.URL = url
+	config.Token = token
+	publisher, err := NewPublisher(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = publisher.Close() })
+	return publisher
+}
+
+func TestNATSPublisher(t *testing.T) {
+	url, messages := serveFakeNATS(t, "secret", 1)
+	publisher := newTestNATSPublisher(t, url, "secret")
+
+	require.NoError(t, publisher.Publish(t.Context(), events.TypeToolCall, []byte(`{"type":"tool_call"}`)))
+	assert.Equal(t, natsMessage{subject: "mcp-gateway.tool_call", payload: `{"type":"tool_call"}`}, <-messages)
+
+	// The server closed the connection after the first message: the publication fails, then the next one
+	// connects again.
+	assert.Error(t, publisher.Publish(t.Context(), events.TypeProxyHealth, []byte(`{}`)))
+	require.NoError(t, publisher.Publish(t.Context(), events.TypeProxyHealth, []byte(`{}`)))
+	assert.Equal(t, natsMessage{subject: "mcp-gateway.proxy_health", payload: `{}`}, <-messages)
+
+	assert.ErrorContains(t, publisher.Publish(t.Context(), events.TypeToolCall, []byte(strings.Repeat("x", 65))),
+		"exceeds the NATS maximum payload")
+}
+
+func TestNATSPublisherUnauthorized(t *testing.T) {
+	url, _ := serveFakeNATS(t, "secret", 0)
+	publisher := newTestNATSPublisher(t, url, "wrong")
+
+	assert.ErrorContains(t, publisher.Publish(t.Context(), events.TypeToolCall, []byte(`{}`)), "Authorization Violation")
+}
@@ -0,0 +1,133 @@
+// Package events broadcasts the gateway events to the operators watching them live.
+package events
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	TypeToolCall      Type = "tool_call"
+	TypeProxyHealth   Type = "proxy_health"
+	TypeAdminMutation Type = "admin_mutation"
+	TypeApproval      Type = "approval"
+)
+
+// Types lists the known event types.
+var Types = []Type{TypeToolCall, TypeProxyHealth, TypeAdminMutation, TypeApproval}
+
+// IsValid returns true if the type is a known event type.
+func (t Type) IsValid() bool {
+	return slices.Contains(Types, t)
+}
+
+// Event is an event emitted by the gateway.
+type Event struct {
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+}
+
+// ToolCall is the data of a TypeToolCall event. Arguments and results are never included.
+type ToolCall struct {
+	Proxy    string `json:"proxy"`
+	Tool     string `json:"tool"`
+	Identity string `json:"identity"`
+	IsError  bool   `json:"isError"`
+}
+
+// ProxyHealth is the data of a TypeProxyHealth event, emitted each time the tools of a proxy are refreshed.
+type ProxyHealth struct {
+	Proxy   string `json:"proxy"`
+	Healthy bool   `json:"healthy"`
+	Tools   int    `json:"tools"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AdminMutation is the data of a TypeAdminMutation event, emitted for each successful change made through
+// the admin APIs.
+type AdminMutation struct {
+	// API is either "http" or "grpc".
+	API string `json:"api"`
+	// Operation is the HTTP method and route, e.g. "PUT /v1/admin/proxies/:name", or the full gRPC method.
+	Operation string `json:"operation"`
+	// Path is the path of the HTTP request, e.g. "/v1/admin/proxies/github".
+	Path     string `json:"path,omitempty"`
+	ClientIP string `json:"clientIp,omitempty"`
+}
+
+// Approval is the data of a TypeApproval event, emitted when a tool call is parked for approval and when it is
+// decided. Arguments are never included.
+type Approval struct {
+	ID        string `json:"id"`
+	Proxy     string `json:"proxy"`
+	Tool      string `json:"tool"`
+	Identity  string `json:"identity"`
+	Status    string `json:"status"`
+	DecidedBy string `json:"decidedBy,omitempty"`
+}
+
+// Broker fans out the published events to the subscribers.
+// A subscriber that does not keep up misses events rather than slowing down the gateway.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+	bufferSize  int
+}
+
+func NewBroker(bufferSize int) *Broker {
+	return &Broker{
+		subscribers: make(map[chan Event]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Publish sends an event to every subscriber without blocking.
+func (b *Broker) Publish(eventType Type, data any) {
+	if b == nil {
+		return
+	}
+	event := Event{Type: eventType, Time: time.Now(), Data: data}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the published events and a function to stop the subscription.
+func (b *Broker) Subscribe() (<-chan Event, func()) {
+	return b.SubscribeBuffered(b.bufferSize)
+}
+
+// SubscribeBuffered is Subscribe with a channel buffering bufferSize events, for the subscribers slower
+// than the broker's buffer allows.
+func (b *Broker) SubscribeBuffered(bufferSize int) (<-chan Event, func()) {
+	ch := make(chan Event, bufferSize)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Subscribers returns the number of active subscribers.
+func (b *Broker) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
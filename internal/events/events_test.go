@@ -0,0 +1,34 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker(1)
+	ch, unsubscribe := broker.Subscribe()
+	assert.Equal(t, 1, broker.Subscribers())
+
+	broker.Publish(TypeToolCall, ToolCall{Proxy: "github", Tool: "search"})
+	// The buffer is full: the event is dropped instead of blocking.
+	broker.Publish(TypeToolCall, ToolCall{Proxy: "github", Tool: "create_issue"})
+
+	event := <-ch
+	assert.Equal(t, TypeToolCall, event.Type)
+	assert.Equal(t, ToolCall{Proxy: "github", Tool: "search"}, event.Data)
+	assert.Empty(t, ch)
+
+	unsubscribe()
+	unsubscribe()
+	assert.Equal(t, 0, broker.Subscribers())
+	_, ok := <-ch
+	assert.False(t, ok)
+	broker.Publish(TypeProxyHealth, ProxyHealth{Proxy: "github"})
+}
+
+func TestNilBroker(t *testing.T) {
+	var broker *Broker
+	assert.NotPanics(t, func() { broker.Publish(TypeToolCall, ToolCall{}) })
+}
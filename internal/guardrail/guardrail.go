@@ -0,0 +1,140 @@
+// Package guardrail calls an external policy service, e.g. an existing AI-safety gateway, to allow, deny or
+// transform the tool calls before they are forwarded upstream and their results before they are returned.
+package guardrail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+)
+
+// maxVerdictSize bounds the verdicts read from the policy service, which may carry a transformed result.
+const maxVerdictSize = 10 << 20
+
+// Decision is the verdict of the policy service.
+type Decision string
+
+const (
+	DecisionAllow     Decision = "allow"
+	DecisionDeny      Decision = "deny"
+	DecisionTransform Decision = "transform"
+)
+
+// Request is posted to the policy service at each stage of a tool call.
+type Request struct {
+	// Stage is 'request' before the call is forwarded, or 'response' once its result is received.
+	Stage     string         `json:"stage"`
+	Proxy     string         `json:"proxy"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	// Claims are the claims of the caller, omitted if unauthenticated.
+	Claims map[string]any `json:"claims,omitempty"`
+	// Result is the result of the call at the response stage.
+	Result *mcp.CallToolResult `json:"result,omitempty"`
+}
+
+// Verdict is the answer of the policy service.
+type Verdict struct {
+	Verdict Decision `json:"verdict"`
+	// Reason is returned to the caller of a denied tool call.
+	Reason string `json:"reason,omitempty"`
+	// Arguments replace the arguments of a transformed request.
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// Result replaces the result of a transformed response.
+	Result *json.RawMessage `json:"result,omitempty"`
+
+	result *mcp.CallToolResult
+}
+
+// Client calls the policy service.
+type Client struct {
+	url             string
+	token           string
+	stages          []string
+	failOpen        bool
+	excludedProxies []string
+	httpClient      *http.Client
+}
+
+// New creates a client of the policy service from the guardrail configuration.
+func New(config *cfg.GuardrailConfig) *Client {
+	return &Client{
+		url:             config.URL,
+		token:           config.Token,
+		stages:          config.Stages,
+		failOpen:        config.FailOpen,
+		excludedProxies: config.ExcludedProxies,
+		httpClient:      &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Checks reports whether the tool calls of the proxy are checked at the stage.
+func (c *Client) Checks(stage, proxy string) bool {
+	return slices.Contains(c.stages, stage) && !slices.Contains(c.excludedProxies, proxy)
+}
+
+// FailOpen reports whether the tool calls go on when the policy service fails.
+func (c *Client) FailOpen() bool {
+	return c.failOpen
+}
+
+// Check posts the request to the policy service and returns its verdict. A verdict which cannot be applied to
+// the stage is an error.
+func (c *Client) Check(ctx context.Context, request Request) (Verdict, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return Verdict{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Verdict{}, fmt.Errorf("guardrail returned status %d", resp.StatusCode)
+	}
+
+	verdict := Verdict{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxVerdictSize)).Decode(&verdict); err != nil {
+		return Verdict{}, fmt.Errorf("invalid guardrail verdict: %w", err)
+	}
+	switch verdict.Verdict {
+	case DecisionAllow, DecisionDeny:
+	case DecisionTransform:
+		if request.Stage == cfg.GuardrailStageRequest && verdict.Arguments == nil {
+			return Verdict{}, errors.New("invalid guardrail verdict: a transformed request requires the arguments")
+		}
+		if request.Stage == cfg.GuardrailStageResponse {
+			if verdict.Result == nil {
+				return Verdict{}, errors.New("invalid guardrail verdict: a transformed response requires the result")
+			}
+			if verdict.result, err = mcp.ParseCallToolResult(verdict.Result); err != nil {
+				return Verdict{}, fmt.Errorf("invalid guardrail result: %w", err)
+			}
+		}
+	default:
+		return Verdict{}, fmt.Errorf("invalid guardrail verdict: %q", verdict.Verdict)
+	}
+	return verdict, nil
+}
+
+// TransformedResult is the result replacing the result of a transformed response.
+func (v Verdict) TransformedResult() *mcp.CallToolResult {
+	return v.result
+}
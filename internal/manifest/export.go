@@ -0,0 +1,120 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/matthisholleville/mcp-gateway/internal/secrets"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+)
+
+// Export returns the manifest of the objects of the backend, sorted by name.
+// The secrets of the proxies (header values, OAuth client secrets and HMAC secrets) are emptied unless includeSecrets is true.
+func Export(ctx context.Context, store storage.Interface, includeSecrets bool) (*Manifest, error) {
+	proxies, err := store.ListProxies(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the proxies: %w", err)
+	}
+	roles, err := store.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the roles: %w", err)
+	}
+	mappings, err := store.ListAttributeToRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the attribute-to-roles mappings: %w", err)
+	}
+
+	m := &Manifest{
+		Proxies:          make([]Proxy, 0, len(proxies)),
+		Roles:            make([]storage.RoleConfig, 0, len(roles)),
+		AttributeToRoles: make([]storage.AttributeToRolesConfig, 0, len(mappings)),
+	}
+	for _, proxy := range proxies {
+		config := normalizeProxy(proxy)
+		if !includeSecrets {
+			redactSecrets(config)
+		}
+		exported := Proxy{ProxyConfig: *config}
+		if config.Timeout > 0 {
+			exported.Timeout = config.Timeout.String()
+		}
+		if config.KeepaliveInterval > 0 {
+			exported.KeepaliveInterval = config.KeepaliveInterval.String()
+		}
+		m.Proxies = append(m.Proxies, exported)
+	}
+	for _, role := range roles {
+		m.Roles = append(m.Roles, *normalizeRole(role))
+	}
+	for _, mapping := range mappings {
+		m.AttributeToRoles = append(m.AttributeToRoles, *normalizeMapping(mapping))
+	}
+
+	sort.Slice(m.Proxies, func(i, j int) bool { return m.Proxies[i].Name < m.Proxies[j].Name })
+	sort.Slice(m.Roles, func(i, j int) bool { return m.Roles[i].Name < m.Roles[j].Name })
+	sort.Slice(m.AttributeToRoles, func(i, j int) bool {
+		return mappingName(m.AttributeToRoles[i]) < mappingName(m.AttributeToRoles[j])
+	})
+	return m, nil
+}
+
+// KeepSecrets fills the empty secrets of the proxies of the manifest with the secrets of the backend,
+// so importing a snapshot exported without secrets does not erase them.
+func KeepSecrets(ctx context.Context, store storage.Interface, m *Manifest) error {
+	proxies, err := store.ListProxies(ctx, true)
+	if err != nil {
+		return fmt.Errorf("failed to list the proxies: %w", err)
+	}
+	current := make(map[string]storage.ProxyConfig, len(proxies))
+	for _, proxy := range proxies {
+		current[proxy.Name] = proxy
+	}
+
+	for i := range m.Proxies {
+		proxy := &m.Proxies[i]
+		existing, ok := current[proxy.Name]
+		if !ok {
+			continue
+		}
+		proxy.Headers = slices.Clone(proxy.Headers)
+		for j := range proxy.Headers {
+			if proxy.Headers[j].Value != "" {
+				continue
+			}
+			for _, header := range existing.Headers {
+				if header.Key == proxy.Headers[j].Key {
+					proxy.Headers[j].Value = header.Value
+				}
+			}
+		}
+		if proxy.OAuth != nil && proxy.OAuth.ClientSecret == "" && existing.OAuth != nil {
+			oauth := *proxy.OAuth
+			oauth.ClientSecret = existing.OAuth.ClientSecret
+			proxy.OAuth = &oauth
+		}
+		if proxy.HMAC != nil && proxy.HMAC.Secret == "" && existing.HMAC != nil {
+			proxy.HMAC = &storage.ProxyHMAC{Secret: existing.HMAC.Secret}
+		}
+	}
+	return nil
+}
+
+// redactSecrets empties the header values, the OAuth client secret and the HMAC secret of the proxy. The secret
+// references are kept, they do not contain the secrets.
+func redactSecrets(proxy *storage.ProxyConfig) {
+	for i := range proxy.Headers {
+		if !secrets.IsReference(proxy.Headers[i].Value) {
+			proxy.Headers[i].Value = ""
+		}
+	}
+	if proxy.OAuth != nil && !secrets.IsReference(proxy.OAuth.ClientSecret) {
+		oauth := *proxy.OAuth
+		oauth.ClientSecret = ""
+		proxy.OAuth = &oauth
+	}
+	if proxy.HMAC != nil && !secrets.IsReference(proxy.HMAC.Secret) {
+		proxy.HMAC = &storage.ProxyHMAC{}
+	}
+}
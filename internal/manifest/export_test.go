@@ -0,0 +1,42 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportAndKeepSecrets(t *testing.T) {
+	ctx := t.Context()
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+	m, err := Load(writeManifest(t, testManifest))
+	require.NoError(t, err)
+	store := storage.NewMemoryStorage("")
+	changes, err := Plan(ctx, store, m, false)
+	require.NoError(t, err)
+	require.NoError(t, Apply(ctx, store, changes))
+
+	exported, err := Export(ctx, store, true)
+	require.NoError(t, err)
+	assert.Equal(t, "30s", exported.Proxies[0].Timeout)
+	assert.Equal(t, "1m0s", exported.Proxies[0].KeepaliveInterval)
+	assert.Equal(t, "Bearer secret", exported.Proxies[0].Headers[0].Value)
+	// The export matches the backend it was exported from.
+	changes, err = Plan(ctx, store, exported, true)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	redacted, err := Export(ctx, store, false)
+	require.NoError(t, err)
+	assert.Empty(t, redacted.Proxies[0].Headers[0].Value)
+	assert.Equal(t, m.Roles, redacted.Roles)
+	assert.Equal(t, m.AttributeToRoles, redacted.AttributeToRoles)
+
+	require.NoError(t, KeepSecrets(ctx, store, redacted))
+	assert.Equal(t, "Bearer secret", redacted.Proxies[0].Headers[0].Value)
+	changes, err = Plan(ctx, store, redacted, true)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,352 @@
+// Package manifest reconciles the proxies, roles and attribute-to-roles mappings of the backend
+// with a declarative manifest.
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+)
+
+// Manifest is the desired state of the gateway.
+type Manifest struct {
+	Proxies          []Proxy                          `json:"proxies"`
+	Roles            []storage.RoleConfig             `json:"roles"`
+	AttributeToRoles []storage.AttributeToRolesConfig `json:"attributeToRoles"`
+}
+
+// Proxy is a proxy of the manifest. Its timeout and keepalive interval are duration strings (e.g. '30s').
+type Proxy struct {
+	storage.ProxyConfig
+	Timeout           string `json:"timeout"`
+	KeepaliveInterval string `json:"keepaliveInterval,omitempty"`
+}
+
+// Kind is the kind of object reconciled.
+type Kind string
+
+const (
+	KindProxy            Kind = "proxy"
+	KindRole             Kind = "role"
+	KindAttributeToRoles Kind = "mapping"
+)
+
+// Action is the change made to an object.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is a change of an object to apply.
+type Change struct {
+	Kind   Kind   `json:"kind"`
+	Name   string `json:"name"`
+	Action Action `json:"action"`
+
+	proxy   *storage.ProxyConfig
+	role    *storage.RoleConfig
+	mapping *storage.AttributeToRolesConfig
+}
+
+// Load reads and merges the manifest files, in YAML or JSON.
+// The environment variables referenced as ${VAR} are expanded, so the secrets can be kept out of the files.
+func Load(paths ...string) (*Manifest, error) {
+	manifest := &Manifest{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path) //nolint:gosec // the path is given by the user
+		if err != nil {
+			return nil, err
+		}
+		data, err := yaml.YAMLToJSON([]byte(os.ExpandEnv(string(content))))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		var file Manifest
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		manifest.Proxies = append(manifest.Proxies, file.Proxies...)
+		manifest.Roles = append(manifest.Roles, file.Roles...)
+		manifest.AttributeToRoles = append(manifest.AttributeToRoles, file.AttributeToRoles...)
+	}
+	return manifest, manifest.Validate()
+}
+
+// Validate checks the objects are valid and declared once.
+func (m *Manifest) Validate() error {
+	proxies := make(map[string]bool, len(m.Proxies))
+	for _, proxy := range m.Proxies {
+		if proxy.Name == "" {
+			return fmt.Errorf("proxy name is required")
+		}
+		if proxies[proxy.Name] {
+			return fmt.Errorf("proxy %q is declared more than once", proxy.Name)
+		}
+		proxies[proxy.Name] = true
+		if !proxy.Type.IsValid() {
+			return fmt.Errorf("proxy %q: invalid type %q", proxy.Name, proxy.Type)
+		}
+		if !proxy.AuthType.IsValid() {
+			return fmt.Errorf("proxy %q: invalid auth type %q", proxy.Name, proxy.AuthType)
+		}
+		if _, err := proxy.timeout(); err != nil {
+			return fmt.Errorf("proxy %q: invalid timeout %q", proxy.Name, proxy.Timeout)
+		}
+		if _, err := proxy.keepaliveInterval(); err != nil {
+			return fmt.Errorf("proxy %q: invalid keepalive interval %q", proxy.Name, proxy.KeepaliveInterval)
+		}
+	}
+
+	roles := make(map[string]bool, len(m.Roles))
+	for _, role := range m.Roles {
+		if role.Name == "" {
+			return fmt.Errorf("role name is required")
+		}
+		if roles[role.Name] {
+			return fmt.Errorf("role %q is declared more than once", role.Name)
+		}
+		roles[role.Name] = true
+		for _, permission := range role.Permissions {
+			if !permission.ObjectType.IsValid() {
+				return fmt.Errorf("role %q: invalid object type %q", role.Name, permission.ObjectType)
+			}
+		}
+		if role.Clearance != "" && !role.Clearance.IsValid() {
+			return fmt.Errorf("role %q: invalid clearance %q", role.Name, role.Clearance)
+		}
+	}
+
+	mappings := make(map[string]bool, len(m.AttributeToRoles))
+	for _, mapping := range m.AttributeToRoles {
+		if mapping.AttributeKey == "" || mapping.AttributeValue == "" {
+			return fmt.Errorf("mapping attribute key and attribute value are required")
+		}
+		name := mappingName(mapping)
+		if mappings[name] {
+			return fmt.Errorf("mapping %q is declared more than once", name)
+		}
+		mappings[name] = true
+	}
+	return nil
+}
+
+func (p *Proxy) timeout() (time.Duration, error) {
+	if p.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(p.Timeout)
+}
+
+func (p *Proxy) keepaliveInterval() (time.Duration, error) {
+	if p.KeepaliveInterval == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(p.KeepaliveInterval)
+}
+
+// config returns the storage config of the proxy, normalized as stored by the backend.
+func (p *Proxy) config() *storage.ProxyConfig {
+	config := p.ProxyConfig
+	timeout, _ := p.timeout()
+	config.Timeout = timeout
+	config.KeepaliveInterval, _ = p.keepaliveInterval()
+	return normalizeProxy(config)
+}
+
+// Plan compares the manifest with the backend and returns the changes to apply, sorted by kind and name.
+// The objects of the backend missing from the manifest are deleted only when prune is true.
+func Plan(ctx context.Context, store storage.Interface, m *Manifest, prune bool) ([]Change, error) {
+	var changes []Change
+
+	currentProxies, err := store.ListProxies(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the proxies: %w", err)
+	}
+	current := make(map[string]*storage.ProxyConfig, len(currentProxies))
+	for _, proxy := range currentProxies {
+		current[proxy.Name] = normalizeProxy(proxy)
+	}
+	for i := range m.Proxies {
+		desired := m.Proxies[i].config()
+		if action, ok := compare(current[desired.Name], desired); ok {
+			changes = append(changes, Change{Kind: KindProxy, Name: desired.Name, Action: action, proxy: desired})
+		}
+		delete(current, desired.Name)
+	}
+	for name := range current {
+		if prune {
+			changes = append(changes, Change{Kind: KindProxy, Name: name, Action: ActionDelete})
+		}
+	}
+
+	currentRoles, err := store.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the roles: %w", err)
+	}
+	roles := make(map[string]*storage.RoleConfig, len(currentRoles))
+	for _, role := range currentRoles {
+		roles[role.Name] = normalizeRole(role)
+	}
+	for _, role := range m.Roles {
+		desired := normalizeRole(role)
+		if action, ok := compare(roles[desired.Name], desired); ok {
+			changes = append(changes, Change{Kind: KindRole, Name: desired.Name, Action: action, role: desired})
+		}
+		delete(roles, desired.Name)
+	}
+	for name := range roles {
+		if prune {
+			changes = append(changes, Change{Kind: KindRole, Name: name, Action: ActionDelete})
+		}
+	}
+
+	currentMappings, err := store.ListAttributeToRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the attribute-to-roles mappings: %w", err)
+	}
+	mappings := make(map[string]*storage.AttributeToRolesConfig, len(currentMappings))
+	for _, mapping := range currentMappings {
+		mappings[mappingName(mapping)] = normalizeMapping(mapping)
+	}
+	for _, mapping := range m.AttributeToRoles {
+		desired := normalizeMapping(mapping)
+		name := mappingName(mapping)
+		if action, ok := compare(mappings[name], desired); ok {
+			changes = append(changes, Change{Kind: KindAttributeToRoles, Name: name, Action: action, mapping: desired})
+		}
+		delete(mappings, name)
+	}
+	for name, mapping := range mappings {
+		if prune {
+			changes = append(changes, Change{Kind: KindAttributeToRoles, Name: name, Action: ActionDelete, mapping: mapping})
+		}
+	}
+
+	kinds := map[Kind]int{KindProxy: 0, KindRole: 1, KindAttributeToRoles: 2}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return kinds[changes[i].Kind] < kinds[changes[j].Kind]
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes, nil
+}
+
+// Apply applies the changes to the backend. The proxies and roles are created before the mappings
+// that reference them, and the mappings are deleted before the roles.
+func Apply(ctx context.Context, store storage.Interface, changes []Change) error {
+	ordered := slices.Clone(changes)
+	rank := func(c Change) int {
+		switch {
+		case c.Action == ActionDelete && c.Kind == KindAttributeToRoles:
+			return 0
+		case c.Action == ActionDelete:
+			return 1
+		case c.Kind == KindAttributeToRoles:
+			return 3
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return rank(ordered[i]) < rank(ordered[j]) })
+
+	for _, change := range ordered {
+		if err := applyChange(ctx, store, change); err != nil {
+			return fmt.Errorf("failed to %s %s %q: %w", change.Action, change.Kind, change.Name, err)
+		}
+	}
+	return nil
+}
+
+func applyChange(ctx context.Context, store storage.Interface, change Change) error {
+	switch change.Kind {
+	case KindProxy:
+		if change.Action == ActionDelete {
+			return store.DeleteProxy(ctx, change.Name)
+		}
+		return store.SetProxy(ctx, change.proxy, true)
+	case KindRole:
+		if change.Action == ActionDelete {
+			return store.DeleteRole(ctx, change.Name)
+		}
+		return store.SetRole(ctx, *change.role)
+	case KindAttributeToRoles:
+		if change.Action == ActionDelete {
+			return store.DeleteAttributeToRoles(ctx, change.mapping.AttributeKey, change.mapping.AttributeValue)
+		}
+		return store.SetAttributeToRoles(ctx, *change.mapping)
+	default:
+		return fmt.Errorf("unknown kind %q", change.Kind)
+	}
+}
+
+// compare returns the action making current equal to desired, if any.
+func compare[T any](current, desired *T) (Action, bool) {
+	switch {
+	case current == nil:
+		return ActionCreate, true
+	case !reflect.DeepEqual(current, desired):
+		return ActionUpdate, true
+	default:
+		return "", false
+	}
+}
+
+func mappingName(mapping storage.AttributeToRolesConfig) string {
+	return mapping.AttributeKey + "=" + mapping.AttributeValue
+}
+
+// normalizeProxy makes a proxy comparable: the headers are sorted, the timeout and keepalive interval are stored
+// in seconds and empty labels are nil.
+func normalizeProxy(proxy storage.ProxyConfig) *storage.ProxyConfig {
+	proxy.Timeout = proxy.Timeout.Truncate(time.Second)
+	proxy.KeepaliveInterval = proxy.KeepaliveInterval.Truncate(time.Second)
+	proxy.Headers = slices.Clone(proxy.Headers)
+	if len(proxy.Headers) == 0 {
+		proxy.Headers = nil
+	}
+	sort.Slice(proxy.Headers, func(i, j int) bool { return proxy.Headers[i].Key < proxy.Headers[j].Key })
+	if proxy.OAuth != nil && *proxy.OAuth == (storage.ProxyOAuth{}) {
+		proxy.OAuth = nil
+	}
+	if len(proxy.Labels) == 0 {
+		proxy.Labels = nil
+	}
+	return &proxy
+}
+
+// normalizeRole makes a role comparable: the permissions are sorted.
+func normalizeRole(role storage.RoleConfig) *storage.RoleConfig {
+	role.Permissions = slices.Clone(role.Permissions)
+	if len(role.Permissions) == 0 {
+		role.Permissions = nil
+	}
+	sort.Slice(role.Permissions, func(i, j int) bool {
+		a, b := role.Permissions[i], role.Permissions[j]
+		return strings.Join([]string{string(a.ObjectType), a.Proxy, a.ObjectName}, "\x00") <
+			strings.Join([]string{string(b.ObjectType), b.Proxy, b.ObjectName}, "\x00")
+	})
+	return &role
+}
+
+// normalizeMapping makes a mapping comparable: the roles are sorted.
+func normalizeMapping(mapping storage.AttributeToRolesConfig) *storage.AttributeToRolesConfig {
+	mapping.Roles = slices.Clone(mapping.Roles)
+	if len(mapping.Roles) == 0 {
+		mapping.Roles = nil
+	}
+	sort.Strings(mapping.Roles)
+	return &mapping
+}
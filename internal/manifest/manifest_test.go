@@ -0,0 +1,163 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `
+proxies:
+  - name: github
+    type: streamable-http
+    url: https://github.example.com/mcp
+    timeout: 30s
+    keepaliveInterval: 1m
+    authType: header
+    headers:
+      - key: Authorization
+        value: Bearer ${TEST_GITHUB_TOKEN}
+roles:
+  - name: developer
+    permissions:
+      - object_type: tools
+        proxy: github
+        object_name: "*"
+attributeToRoles:
+  - attribute_key: groups
+    attribute_value: dev
+    roles: [developer]
+`
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gateway.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+	m, err := Load(writeManifest(t, testManifest))
+	require.NoError(t, err)
+	require.Len(t, m.Proxies, 1)
+	assert.Equal(t, 30*time.Second, m.Proxies[0].config().Timeout)
+	assert.Equal(t, time.Minute, m.Proxies[0].config().KeepaliveInterval)
+	assert.Equal(t, "Bearer secret", m.Proxies[0].Headers[0].Value)
+	assert.Len(t, m.Roles, 1)
+	assert.Len(t, m.AttributeToRoles, 1)
+
+	for _, test := range []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "duplicate role", content: "roles: [{name: dev}, {name: dev}]", expected: `role "dev" is declared more than once`},
+		{name: "invalid timeout", content: "proxies: [{name: github, type: streamable-http, authType: header, timeout: soon}]",
+			expected: "invalid timeout"},
+		{name: "invalid keepalive interval", content: "proxies: [{name: github, type: streamable-http, authType: header, keepaliveInterval: often}]",
+			expected: "invalid keepalive interval"},
+		{name: "invalid object type", content: "roles: [{name: dev, permissions: [{object_type: files}]}]",
+			expected: "invalid object type"},
+		{name: "invalid clearance", content: "roles: [{name: dev, clearance: secret}]", expected: "invalid clearance"},
+		{name: "incomplete mapping", content: "attributeToRoles: [{attribute_key: groups}]", expected: "are required"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Load(writeManifest(t, test.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
+
+func TestPlanAndApply(t *testing.T) {
+	ctx := t.Context()
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+	m, err := Load(writeManifest(t, testManifest))
+	require.NoError(t, err)
+
+	store := storage.NewMemoryStorage("")
+	require.NoError(t, store.SetRole(ctx, storage.RoleConfig{Name: "legacy"}))
+	require.NoError(t, store.SetAttributeToRoles(ctx, storage.AttributeToRolesConfig{
+		AttributeKey: "groups", AttributeValue: "ops", Roles: []string{"legacy"},
+	}))
+
+	changes, err := Plan(ctx, store, m, false)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: KindProxy, Name: "github", Action: ActionCreate},
+		{Kind: KindRole, Name: "developer", Action: ActionCreate},
+		{Kind: KindAttributeToRoles, Name: "groups=dev", Action: ActionCreate},
+	}, withoutObjects(changes))
+
+	changes, err = Plan(ctx, store, m, true)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: KindProxy, Name: "github", Action: ActionCreate},
+		{Kind: KindRole, Name: "developer", Action: ActionCreate},
+		{Kind: KindRole, Name: "legacy", Action: ActionDelete},
+		{Kind: KindAttributeToRoles, Name: "groups=dev", Action: ActionCreate},
+		{Kind: KindAttributeToRoles, Name: "groups=ops", Action: ActionDelete},
+	}, withoutObjects(changes))
+
+	require.NoError(t, Apply(ctx, store, changes))
+	roles, err := store.ListRoles(ctx)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "developer", roles[0].Name)
+
+	// The backend now matches the manifest.
+	changes, err = Plan(ctx, store, m, true)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	m.Roles[0].Permissions[0].ObjectName = "search"
+	changes, err = Plan(ctx, store, m, true)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{Kind: KindRole, Name: "developer", Action: ActionUpdate}}, withoutObjects(changes))
+}
+
+func withoutObjects(changes []Change) []Change {
+	out := make([]Change, 0, len(changes))
+	for _, change := range changes {
+		out = append(out, Change{Kind: change.Kind, Name: change.Name, Action: change.Action})
+	}
+	return out
+}
+
+func TestPlanMappings(t *testing.T) {
+	ctx := t.Context()
+	store := storage.NewMemoryStorage("")
+	for _, name := range []string{"developer", "reviewer", "legacy"} {
+		require.NoError(t, store.SetRole(ctx, storage.RoleConfig{Name: name}))
+	}
+	for _, mapping := range []storage.AttributeToRolesConfig{
+		{AttributeKey: "groups", AttributeValue: "dev", Roles: []string{"developer", "legacy"}},
+		{AttributeKey: "groups", AttributeValue: "ops", Roles: []string{"legacy"}},
+		{AttributeKey: "team", AttributeValue: "core", Roles: []string{"developer"}},
+	} {
+		require.NoError(t, store.SetAttributeToRoles(ctx, mapping))
+	}
+
+	changes, err := PlanMappings(ctx, store, []storage.AttributeToRolesConfig{
+		{AttributeKey: "team", AttributeValue: "core", Roles: []string{"developer"}},
+		{AttributeKey: "groups", AttributeValue: "dev", Roles: []string{"reviewer", "developer"}},
+		{AttributeKey: "groups", AttributeValue: "qa", Roles: []string{"reviewer"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []MappingChange{
+		{AttributeKey: "groups", AttributeValue: "dev", Action: ActionUpdate, AddedRoles: []string{"reviewer"}, RemovedRoles: []string{"legacy"}},
+		{AttributeKey: "groups", AttributeValue: "ops", Action: ActionDelete, AddedRoles: []string{}, RemovedRoles: []string{"legacy"}},
+		{AttributeKey: "groups", AttributeValue: "qa", Action: ActionCreate, AddedRoles: []string{"reviewer"}, RemovedRoles: []string{}},
+	}, changes)
+
+	// The plan does not change the backend.
+	mappings, err := store.ListAttributeToRoles(ctx)
+	require.NoError(t, err)
+	assert.Len(t, mappings, 3)
+}
@@ -0,0 +1,85 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+)
+
+// MappingChange is the change of an attribute-to-roles mapping, with the roles added to and removed from it.
+type MappingChange struct {
+	AttributeKey   string   `json:"attribute_key"`
+	AttributeValue string   `json:"attribute_value"`
+	Action         Action   `json:"action"`
+	AddedRoles     []string `json:"added_roles"`
+	RemovedRoles   []string `json:"removed_roles"`
+}
+
+// PlanMappings compares the full desired set of attribute-to-roles mappings with the backend and returns the
+// changes replacing them would make, sorted by attribute key and value. The mappings missing from the desired set
+// are deleted, and the unchanged ones are omitted. The desired mappings must be valid, see Manifest.Validate.
+func PlanMappings(ctx context.Context, store storage.Interface, desired []storage.AttributeToRolesConfig) ([]MappingChange, error) {
+	currentMappings, err := store.ListAttributeToRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the attribute-to-roles mappings: %w", err)
+	}
+	current := make(map[string]*storage.AttributeToRolesConfig, len(currentMappings))
+	for _, mapping := range currentMappings {
+		current[mappingName(mapping)] = normalizeMapping(mapping)
+	}
+
+	changes := []MappingChange{}
+	for _, mapping := range desired {
+		name := mappingName(mapping)
+		wanted := normalizeMapping(mapping)
+		change := MappingChange{
+			AttributeKey:   mapping.AttributeKey,
+			AttributeValue: mapping.AttributeValue,
+			Action:         ActionCreate,
+			AddedRoles:     difference(wanted.Roles, nil),
+			RemovedRoles:   []string{},
+		}
+		if existing, ok := current[name]; ok {
+			change.Action = ActionUpdate
+			change.AddedRoles = difference(wanted.Roles, existing.Roles)
+			change.RemovedRoles = difference(existing.Roles, wanted.Roles)
+			delete(current, name)
+			if len(change.AddedRoles) == 0 && len(change.RemovedRoles) == 0 {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	for _, mapping := range current {
+		changes = append(changes, MappingChange{
+			AttributeKey:   mapping.AttributeKey,
+			AttributeValue: mapping.AttributeValue,
+			Action:         ActionDelete,
+			AddedRoles:     []string{},
+			RemovedRoles:   difference(mapping.Roles, nil),
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].AttributeKey != changes[j].AttributeKey {
+			return changes[i].AttributeKey < changes[j].AttributeKey
+		}
+		return changes[i].AttributeValue < changes[j].AttributeValue
+	})
+	return changes, nil
+}
+
+// difference returns the sorted roles of a missing from b, without duplicates.
+func difference(a, b []string) []string {
+	roles := []string{}
+	for _, role := range a {
+		if !slices.Contains(b, role) && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
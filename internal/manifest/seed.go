@@ -0,0 +1,57 @@
+package manifest
+
+import (
+	"slices"
+
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+)
+
+// SeedOptions are the admin role and attribute mapping created on a fresh deployment.
+type SeedOptions struct {
+	// AdminRole is the name of the role granted all the permissions.
+	AdminRole string
+	// AttributeKey and AttributeValue are the user attribute mapped to the admin role (e.g. groups=admins).
+	AttributeKey   string
+	AttributeValue string
+}
+
+// Seed returns the manifest of a fresh deployment: the admin role, its attribute mapping, and the objects
+// of the templates (e.g. example proxies). A role or mapping declared by the templates takes precedence.
+func Seed(options SeedOptions, templates ...string) (*Manifest, error) {
+	m, err := Load(templates...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(m.Roles, func(role storage.RoleConfig) bool { return role.Name == options.AdminRole }) {
+		m.Roles = append(m.Roles, storage.RoleConfig{
+			Name: options.AdminRole,
+			Permissions: []storage.PermissionConfig{
+				{ObjectType: storage.ObjectTypeAll, Proxy: "*", ObjectName: "*"},
+			},
+		})
+	}
+
+	mapping := storage.AttributeToRolesConfig{
+		AttributeKey:   options.AttributeKey,
+		AttributeValue: options.AttributeValue,
+		Roles:          []string{options.AdminRole},
+	}
+	if !slices.ContainsFunc(m.AttributeToRoles, func(existing storage.AttributeToRolesConfig) bool {
+		return mappingName(existing) == mappingName(mapping)
+	}) {
+		m.AttributeToRoles = append(m.AttributeToRoles, mapping)
+	}
+	return m, m.Validate()
+}
+
+// Creations keeps the creations of the changes, so the objects which already exist are left untouched.
+func Creations(changes []Change) []Change {
+	var creations []Change
+	for _, change := range changes {
+		if change.Action == ActionCreate {
+			creations = append(creations, change)
+		}
+	}
+	return creations
+}
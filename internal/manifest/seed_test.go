@@ -0,0 +1,47 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeed(t *testing.T) {
+	ctx := t.Context()
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+	options := SeedOptions{AdminRole: "admin", AttributeKey: "groups", AttributeValue: "admins"}
+
+	m, err := Seed(options, writeManifest(t, testManifest))
+	require.NoError(t, err)
+	assert.Len(t, m.Proxies, 1)
+	assert.Equal(t, []string{"developer", "admin"}, []string{m.Roles[0].Name, m.Roles[1].Name})
+	assert.Equal(t, []storage.PermissionConfig{{ObjectType: storage.ObjectTypeAll, Proxy: "*", ObjectName: "*"}},
+		m.Roles[1].Permissions)
+	assert.Equal(t, []string{"admin"}, m.AttributeToRoles[1].Roles)
+
+	// The admin role of the template takes precedence.
+	m, err = Seed(options, writeManifest(t, "roles: [{name: admin}]"))
+	require.NoError(t, err)
+	require.Len(t, m.Roles, 1)
+	assert.Empty(t, m.Roles[0].Permissions)
+
+	_, err = Seed(SeedOptions{AdminRole: "admin", AttributeKey: "groups"})
+	assert.ErrorContains(t, err, "attribute value are required")
+
+	// Seeding twice keeps the existing objects.
+	m, err = Seed(options)
+	require.NoError(t, err)
+	store := storage.NewMemoryStorage("")
+	require.NoError(t, store.SetRole(ctx, storage.RoleConfig{Name: "admin"}))
+	changes, err := Plan(ctx, store, m, false)
+	require.NoError(t, err)
+	creations := Creations(changes)
+	assert.Equal(t, []Change{{Kind: KindAttributeToRoles, Name: "groups=admins", Action: ActionCreate}}, withoutObjects(creations))
+
+	require.NoError(t, Apply(ctx, store, creations))
+	role, err := store.GetRole(ctx, "admin")
+	require.NoError(t, err)
+	assert.Empty(t, role.Permissions)
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/labstack/echo-contrib/echoprometheus"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewHTTPMiddleware returns the middleware measuring the requests of all the routes in
+// mcp_gateway_http_requests_total, mcp_gateway_http_request_duration_seconds and the request and response size
+// histograms, by status code, method and route. The routes are their templates (e.g. /v1/admin/proxies/:name),
+// empty for the requests matching no route, and the host is left empty, so the callers cannot add series.
+func NewHTTPMiddleware(registerer prometheus.Registerer) (echo.MiddlewareFunc, error) {
+	return echoprometheus.MiddlewareConfig{
+		Namespace:                 defaultNamespace,
+		Subsystem:                 "http",
+		Registerer:                registerer,
+		DoNotUseRequestPathFor404: true,
+		LabelFuncs: map[string]echoprometheus.LabelValueFunc{
+			"host": func(echo.Context, error) string { return "" },
+		},
+	}.ToMiddleware()
+}
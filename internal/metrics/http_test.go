@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	middleware, err := NewHTTPMiddleware(registry)
+	require.NoError(t, err)
+	router := echo.New()
+	router.Use(middleware)
+	router.GET("/v1/admin/proxies/:name", func(c echo.Context) error {
+		if c.Param("name") == "missing" {
+			return echo.NewHTTPError(http.StatusNotFound, "proxy not found")
+		}
+		return c.String(http.StatusOK, "ok")
+	})
+
+	for _, target := range []string{"/v1/admin/proxies/github", "/v1/admin/proxies/jira", "/v1/admin/proxies/missing", "/random"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Host = "attacker.example.com"
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	expected := `
+# HELP mcp_gateway_http_requests_total How many HTTP requests processed, partitioned by status code and HTTP method.
+# TYPE mcp_gateway_http_requests_total counter
+mcp_gateway_http_requests_total{code="200",host="",method="GET",url="/v1/admin/proxies/:name"} 2
+mcp_gateway_http_requests_total{code="404",host="",method="GET",url=""} 1
+mcp_gateway_http_requests_total{code="404",host="",method="GET",url="/v1/admin/proxies/:name"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "mcp_gateway_http_requests_total"))
+	count, err := testutil.GatherAndCount(registry, "mcp_gateway_http_request_duration_seconds")
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	_, err = NewHTTPMiddleware(registry)
+	require.Error(t, err, "the metrics must not be registered twice")
+}
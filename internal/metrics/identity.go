@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// OverflowLabelValue replaces the identity label values once their maximum is reached.
+	OverflowLabelValue = "other"
+	// NoneLabelValue is the value of the disabled identity labels, and of the unknown roles and subjects.
+	NoneLabelValue = "none"
+	// UnknownLabelValue replaces the proxy names which are not registered, sent by the callers.
+	UnknownLabelValue = "unknown"
+
+	// subjectHashLength is the number of hex characters of the subject hashes.
+	subjectHashLength = 16
+)
+
+var ToolCallsByIdentityCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: defaultNamespace + "_tool_calls_by_identity_total",
+		Help: "Total tool calls by proxy, tool, role which allowed the call, hash of the caller's subject and status",
+	},
+	[]string{"proxy", "tool", "role", "subject", "status"},
+)
+
+// IdentityLabels counts the tool calls by the identity of their callers, with a bounded number of distinct
+// roles and subjects.
+type IdentityLabels struct {
+	config   *cfg.MetricsConfig
+	roles    *boundedValues
+	subjects *boundedValues
+}
+
+// NewIdentityLabels returns the IdentityLabels of the configuration.
+func NewIdentityLabels(config *cfg.MetricsConfig) *IdentityLabels {
+	return &IdentityLabels{
+		config:   config,
+		roles:    newBoundedValues(config.MaxLabelValues),
+		subjects: newBoundedValues(config.MaxLabelValues),
+	}
+}
+
+// Enabled returns true if the tool calls are counted by role or by subject. A nil IdentityLabels is disabled.
+func (l *IdentityLabels) Enabled() bool {
+	return l != nil && (l.config.RoleLabel || l.config.SubjectLabel)
+}
+
+// ObserveToolCall counts a tool call by the role which allowed it and the subject of its caller, either empty
+// if unknown.
+func (l *IdentityLabels) ObserveToolCall(proxy, tool, role, subject string, isError bool) {
+	if !l.Enabled() {
+		return
+	}
+	roleLabel, subjectLabel := NoneLabelValue, NoneLabelValue
+	if l.config.RoleLabel && role != "" {
+		roleLabel = l.roles.value(role)
+	}
+	if l.config.SubjectLabel && subject != "" {
+		subjectLabel = l.subjects.value(HashSubject(subject))
+	}
+	status := "success"
+	if isError {
+		status = "error"
+	}
+	ToolCallsByIdentityCounter.WithLabelValues(proxy, tool, roleLabel, subjectLabel, status).Inc()
+}
+
+// HashSubject returns the label value of a subject: a truncated SHA-256 hash, so the subjects do not appear
+// in the metrics but can be matched by hashing them.
+func HashSubject(subject string) string {
+	sum := sha256.Sum256([]byte(subject))
+	return hex.EncodeToString(sum[:])[:subjectHashLength]
+}
+
+// boundedValues admits label values until their maximum is reached, then replaces the new ones with
+// OverflowLabelValue.
+type boundedValues struct {
+	max    int
+	mu     sync.Mutex
+	values map[string]struct{}
+}
+
+func newBoundedValues(maxValues int) *boundedValues {
+	return &boundedValues{max: maxValues, values: make(map[string]struct{})}
+}
+
+func (b *boundedValues) value(value string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.values[value]; ok {
+		return value
+	}
+	if len(b.values) >= b.max {
+		return OverflowLabelValue
+	}
+	b.values[value] = struct{}{}
+	return value
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityLabels(t *testing.T) {
+	defer ToolCallsByIdentityCounter.Reset()
+	count := func(role, subject, status string) float64 {
+		return testutil.ToFloat64(ToolCallsByIdentityCounter.WithLabelValues("github", "search", role, subject, status))
+	}
+
+	var disabled *IdentityLabels
+	disabled.ObserveToolCall("github", "search", "admin", "alice", false)
+	NewIdentityLabels(&cfg.MetricsConfig{MaxLabelValues: 2}).ObserveToolCall("github", "search", "admin", "alice", false)
+	assert.Equal(t, 0, testutil.CollectAndCount(ToolCallsByIdentityCounter))
+
+	labels := NewIdentityLabels(&cfg.MetricsConfig{RoleLabel: true, MaxLabelValues: 2})
+	for _, role := range []string{"admin", "reader", "writer", "admin", ""} {
+		labels.ObserveToolCall("github", "search", role, "alice", false)
+	}
+	assert.Equal(t, 2.0, count("admin", NoneLabelValue, "success"))
+	assert.Equal(t, 1.0, count("reader", NoneLabelValue, "success"))
+	assert.Equal(t, 1.0, count(OverflowLabelValue, NoneLabelValue, "success"), "the roles over the maximum must be counted as other")
+	assert.Equal(t, 1.0, count(NoneLabelValue, NoneLabelValue, "success"))
+
+	ToolCallsByIdentityCounter.Reset()
+	labels = NewIdentityLabels(&cfg.MetricsConfig{SubjectLabel: true, MaxLabelValues: 10})
+	labels.ObserveToolCall("github", "search", "admin", "alice", true)
+	assert.Equal(t, 1.0, count(NoneLabelValue, HashSubject("alice"), "error"))
+	assert.Len(t, HashSubject("alice"), 16)
+	assert.NotEqual(t, HashSubject("alice"), HashSubject("bob"))
+}
@@ -40,16 +40,216 @@ var (
 		[]string{"tool", "proxy"},
 	)
 
+	UpstreamConnectedGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: defaultNamespace + "_upstream_connected",
+			Help: "Whether the gateway is connected to the upstream server of the proxy (1) or not (0)",
+		},
+		[]string{"proxy"},
+	)
+
+	UpstreamConsecutiveFailuresGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: defaultNamespace + "_upstream_consecutive_failures",
+			Help: "Current number of failed connection attempts to the upstream server of the proxy since the last success",
+		},
+		[]string{"proxy"},
+	)
+
+	AuthProviderHealthyGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: defaultNamespace + "_auth_provider_healthy",
+			Help: "Whether the last health check of the auth provider succeeded (1) or not (0)",
+		},
+		[]string{"provider"},
+	)
+
+	AuthzDecisionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_authz_decisions_total",
+			Help: "Total authorization decisions by proxy, object type and decision (allow or deny); the proxies which are not registered are counted as unknown",
+		},
+		[]string{"proxy", "object_type", "decision"},
+	)
+
+	TokenVerificationFailuresCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_token_verification_failures_total",
+			Help: "Total requests rejected because of their token by reason (missing, malformed, expired, not_yet_valid, too_old, claims, invalid, audience, unavailable or app_check)",
+		},
+		[]string{"reason"},
+	)
+
+	EventsExportedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_events_exported_total",
+			Help: "Total events published to the event export sink by type and result (success or error)",
+		},
+		[]string{"type", "result"},
+	)
+
+	QuotaExceededCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_quota_exceeded_total",
+			Help: "Total tool calls rejected because the caller exceeded a quota, by quota",
+		},
+		[]string{"quota"},
+	)
+
+	BudgetExhaustedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_budget_exhausted_total",
+			Help: "Total tool calls whose cost exceeded the budget of their role, by budget and action (deny or warn)",
+		},
+		[]string{"budget", "action"},
+	)
+
+	ApprovalsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_approvals_total",
+			Help: "Total decided approvals of tool calls, by proxy and status (approved, denied, expired or cancelled)",
+		},
+		[]string{"proxy", "status"},
+	)
+
+	ToolCallsScreenedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_tool_calls_screened_total",
+			Help: "Total tool calls matching a screening rule, by proxy, rule and action (block or flag)",
+		},
+		[]string{"proxy", "rule", "action"},
+	)
+
+	ToolResponsesOversizedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_tool_responses_oversized_total",
+			Help: "Total tool results larger than the maximum response size of the proxy, by proxy and policy (truncate or reject)",
+		},
+		[]string{"proxy", "policy"},
+	)
+
+	GuardrailVerdictsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_guardrail_verdicts_total",
+			Help: "Total verdicts of the guardrail policy service, by proxy, stage (request or response) and verdict (allow, deny, transform or error)",
+		},
+		[]string{"proxy", "stage", "verdict"},
+	)
+
+	SecretsMaskedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_secrets_masked_total",
+			Help: "Total secrets masked in the tool results before they are returned, by tool and proxy",
+		},
+		[]string{"tool", "proxy"},
+	)
+
+	ToolCallsThrottledCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_tool_calls_throttled_total",
+			Help: "Total requests rejected because the maximum number of concurrent tool calls was reached",
+		},
+	)
+
+	UpstreamSessionsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: defaultNamespace + "_upstream_sessions",
+			Help: "Number of the upstream sessions of the clients open on the stateful upstream server of the proxy",
+		},
+		[]string{"proxy"},
+	)
+
+	UpstreamReconnectsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_upstream_reconnects_total",
+			Help: "Total reconnections to the upstream server of the proxy forced by a transport error",
+		},
+		[]string{"proxy"},
+	)
+
+	UpstreamEndpointChangesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_upstream_endpoint_changes_total",
+			Help: "Total changes of the addresses to which the hostname of the upstream server of the proxy resolves, each reconnecting to it",
+		},
+		[]string{"proxy"},
+	)
+
+	UpstreamDialDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    defaultNamespace + "_upstream_dial_duration_seconds",
+			Help:    "Duration of the connection attempts to the upstream server of the proxy, including the MCP initialization, by result",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"proxy", "result"},
+	)
+
+	UpstreamPingDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    defaultNamespace + "_upstream_ping_duration_seconds",
+			Help:    "Round-trip duration of the keepalive pings of the idle upstream sessions of the proxy, by result",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"proxy", "result"},
+	)
+
+	ClientStreamsGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: defaultNamespace + "_client_streams",
+			Help: "Number of open /mcp streams of the clients, listening to the server messages",
+		},
+	)
+
+	ClientStreamsClosedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_client_streams_closed_total",
+			Help: "Total /mcp streams of the clients closed, by reason (client or timeout)",
+		},
+		[]string{"reason"},
+	)
+
 	CustomGaugeVecMetrics = []*prometheus.GaugeVec{
 		ToolsCalledGauge,
 		ToolsCallErrorsGauge,
 		ToolsCallSuccessGauge,
 		ListToolsGauge,
+		UpstreamConnectedGauge,
+		UpstreamConsecutiveFailuresGauge,
+		UpstreamSessionsGauge,
+		AuthProviderHealthyGauge,
+	}
+
+	CustomCounterMetrics = []prometheus.Counter{
+		ToolCallsThrottledCounter,
+	}
+
+	CustomGaugeMetrics = []prometheus.Collector{
+		ClientStreamsGauge,
 	}
 
-	CustomCounterMetrics = []prometheus.Counter{}
+	CustomCounterVecMetrics = []*prometheus.CounterVec{
+		UpstreamReconnectsCounter,
+		UpstreamEndpointChangesCounter,
+		ToolCallsByIdentityCounter,
+		EventsExportedCounter,
+		QuotaExceededCounter,
+		BudgetExhaustedCounter,
+		ApprovalsCounter,
+		ToolCallsScreenedCounter,
+		ToolResponsesOversizedCounter,
+		GuardrailVerdictsCounter,
+		SecretsMaskedCounter,
+		ClientStreamsClosedCounter,
+		AuthzDecisionsCounter,
+		TokenVerificationFailuresCounter,
+		SLOCallsCounter,
+		SLOBadCallsCounter,
+	}
 
-	CustomGaugeMetrics = []prometheus.Collector{}
+	CustomHistogramVecMetrics = []*prometheus.HistogramVec{
+		UpstreamDialDuration,
+		UpstreamPingDuration,
+	}
 )
 
 type Metrics struct {
@@ -78,5 +278,38 @@ func (m *Metrics) RegisterCustomMetrics() error {
 		}
 	}
 
+	for _, metric := range CustomCounterVecMetrics {
+		if err := prometheus.DefaultRegisterer.Register(metric); err != nil {
+			return err
+		}
+	}
+
+	for _, metric := range CustomHistogramVecMetrics {
+		if err := prometheus.DefaultRegisterer.Register(metric); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// RegisterSLO registers the collector of the service level indicators, if enabled.
+func (m *Metrics) RegisterSLO(slo *SLO) error {
+	if !slo.Enabled() {
+		return nil
+	}
+	return prometheus.DefaultRegisterer.Register(slo)
+}
+
+// DeleteUpstreamMetrics deletes the upstream connection metrics of a proxy, once removed.
+func DeleteUpstreamMetrics(proxy string) {
+	labels := prometheus.Labels{"proxy": proxy}
+	UpstreamConnectedGauge.DeletePartialMatch(labels)
+	UpstreamConsecutiveFailuresGauge.DeletePartialMatch(labels)
+	UpstreamSessionsGauge.DeletePartialMatch(labels)
+	UpstreamReconnectsCounter.DeletePartialMatch(labels)
+	UpstreamEndpointChangesCounter.DeletePartialMatch(labels)
+	ToolResponsesOversizedCounter.DeletePartialMatch(labels)
+	UpstreamDialDuration.DeletePartialMatch(labels)
+	UpstreamPingDuration.DeletePartialMatch(labels)
+}
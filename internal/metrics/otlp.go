@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/pkg/version"
+	otelprometheus "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// OTLPPusher pushes the Prometheus metrics of the gateway to an OpenTelemetry collector.
+type OTLPPusher struct {
+	provider *sdkmetric.MeterProvider
+}
+
+// NewOTLPPusher starts pushing the metrics registered to the default Prometheus registry to the configured OTLP
+// endpoint, at each interval.
+func NewOTLPPusher(ctx context.Context, config *cfg.MetricsConfig) (*OTLPPusher, error) {
+	exporter, err := otlpmetrichttp.New(ctx,
+		otlpmetrichttp.WithEndpointURL(config.OTLPEndpoint),
+		otlpmetrichttp.WithHeaders(config.OTLPHeaderMap()))
+	if err != nil {
+		return nil, err
+	}
+	reader := sdkmetric.NewPeriodicReader(exporter,
+		sdkmetric.WithInterval(config.OTLPInterval),
+		sdkmetric.WithProducer(otelprometheus.NewMetricProducer()))
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "mcp-gateway"),
+			attribute.String("service.version", version.Get().Version),
+		)))
+	return &OTLPPusher{provider: provider}, nil
+}
+
+// Shutdown pushes the metrics a last time and stops pushing them.
+func (p *OTLPPusher) Shutdown(ctx context.Context) error {
+	return p.provider.Shutdown(ctx)
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPPusher(t *testing.T) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "mcp_gateway_otlp_test_total", Help: "Test counter"})
+	require.NoError(t, prometheus.Register(counter))
+	defer prometheus.Unregister(counter)
+	counter.Inc()
+
+	pushes := make(chan []byte, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/metrics", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		pushes <- body
+	}))
+	defer collector.Close()
+
+	config := cfg.DefaultConfig().Metrics
+	config.OTLPEndpoint = collector.URL + "/v1/metrics"
+	config.OTLPHeaders = []string{"Authorization=Bearer token"}
+	pusher, err := NewOTLPPusher(t.Context(), config)
+	require.NoError(t, err)
+
+	// Shutting down pushes the metrics a last time, before the first interval.
+	require.NoError(t, pusher.Shutdown(t.Context()))
+	body := <-pushes
+	assert.True(t, bytes.Contains(body, []byte("mcp_gateway_otlp_test_total")))
+	assert.True(t, bytes.Contains(body, []byte("mcp-gateway")), "the service name must be sent")
+}
@@ -0,0 +1,218 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	SLOCallsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_slo_calls_total",
+			Help: "Total tool calls counted by the service level objectives, by proxy",
+		},
+		[]string{"proxy"},
+	)
+
+	SLOBadCallsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: defaultNamespace + "_slo_bad_calls_total",
+			Help: "Total tool calls which missed a service level indicator (failed, or slower than the latency threshold), by proxy and SLI",
+		},
+		[]string{"proxy", "sli"},
+	)
+)
+
+// burnRateWindows are the windows of the error ratios and burn rates: the short and long windows of the
+// multiwindow burn rate alerts.
+var burnRateWindows = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"30m", 30 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+}
+
+var (
+	sloObjectiveDesc = prometheus.NewDesc(defaultNamespace+"_slo_objective",
+		"Objective of the service level indicator of the proxy", []string{"proxy", "sli"}, nil)
+	sloRatioDesc = prometheus.NewDesc(defaultNamespace+"_slo_sli_ratio",
+		"Ratio of the tool calls of the proxy which met the service level indicator over the SLO window", []string{"proxy", "sli"}, nil)
+	sloBudgetDesc = prometheus.NewDesc(defaultNamespace+"_slo_error_budget_remaining_ratio",
+		"Ratio of the error budget of the proxy left over the SLO window, negative once exhausted", []string{"proxy", "sli"}, nil)
+	sloErrorRatioDesc = prometheus.NewDesc(defaultNamespace+"_slo_error_ratio",
+		"Ratio of the tool calls of the proxy which missed the service level indicator over the window", []string{"proxy", "sli", "window"}, nil)
+	sloBurnRateDesc = prometheus.NewDesc(defaultNamespace+"_slo_burn_rate",
+		"Rate at which the error budget of the proxy is consumed over the window, 1 consuming it exactly over the SLO window",
+		[]string{"proxy", "sli", "window"}, nil)
+)
+
+// SLO computes the service level indicators of the proxies, their error budgets and burn rates, and exports
+// them as a Prometheus collector. The calls are counted in memory by each replica.
+type SLO struct {
+	config  *cfg.SLOConfig
+	now     func() time.Time
+	mu      sync.Mutex
+	proxies map[string]*sloCalls
+}
+
+// NewSLO returns the SLO of the configuration.
+func NewSLO(config *cfg.SLOConfig) *SLO {
+	return &SLO{config: config, now: time.Now, proxies: make(map[string]*sloCalls)}
+}
+
+// Enabled returns true if the service level objectives are computed. A nil SLO is disabled.
+func (s *SLO) Enabled() bool {
+	return s != nil && s.config.Enabled
+}
+
+// ObserveToolCall counts a tool call of a proxy in its service level indicators.
+func (s *SLO) ObserveToolCall(proxy string, duration time.Duration, isError bool) {
+	if !s.Enabled() {
+		return
+	}
+	call := callCounts{total: 1}
+	SLOCallsCounter.WithLabelValues(proxy).Inc()
+	if isError {
+		call.failed = 1
+		SLOBadCallsCounter.WithLabelValues(proxy, cfg.SLIAvailability).Inc()
+	}
+	if duration > s.config.LatencyThreshold {
+		call.slow = 1
+		SLOBadCallsCounter.WithLabelValues(proxy, cfg.SLILatency).Inc()
+	}
+
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls, ok := s.proxies[proxy]
+	if !ok {
+		calls = newSLOCalls(s.config.Window)
+		s.proxies[proxy] = calls
+	}
+	calls.minutes.add(now, call)
+	calls.hours.add(now, call)
+}
+
+// DeleteProxy forgets the calls of a proxy, once removed.
+func (s *SLO) DeleteProxy(proxy string) {
+	labels := prometheus.Labels{"proxy": proxy}
+	SLOCallsCounter.DeletePartialMatch(labels)
+	SLOBadCallsCounter.DeletePartialMatch(labels)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.proxies, proxy)
+}
+
+// Describe implements prometheus.Collector.
+func (s *SLO) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sloObjectiveDesc
+	ch <- sloRatioDesc
+	ch <- sloBudgetDesc
+	ch <- sloErrorRatioDesc
+	ch <- sloBurnRateDesc
+}
+
+// Collect implements prometheus.Collector, computing the indicators at the time of the scrape.
+func (s *SLO) Collect(ch chan<- prometheus.Metric) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for proxy, calls := range s.proxies {
+		window := calls.hours.sum(now, s.config.Window)
+		for _, sli := range cfg.SLIs {
+			objective := s.config.Objective(proxy, sli)
+			errorRatio := window.errorRatio(sli)
+			ch <- prometheus.MustNewConstMetric(sloObjectiveDesc, prometheus.GaugeValue, objective, proxy, sli)
+			ch <- prometheus.MustNewConstMetric(sloRatioDesc, prometheus.GaugeValue, 1-errorRatio, proxy, sli)
+			ch <- prometheus.MustNewConstMetric(sloBudgetDesc, prometheus.GaugeValue, 1-errorRatio/(1-objective), proxy, sli)
+			for _, burnWindow := range burnRateWindows {
+				errorRatio := calls.minutes.sum(now, burnWindow.duration).errorRatio(sli)
+				ch <- prometheus.MustNewConstMetric(sloErrorRatioDesc, prometheus.GaugeValue, errorRatio, proxy, sli, burnWindow.label)
+				ch <- prometheus.MustNewConstMetric(sloBurnRateDesc, prometheus.GaugeValue, errorRatio/(1-objective), proxy, sli, burnWindow.label)
+			}
+		}
+	}
+}
+
+// callCounts are the tool calls of a proxy, and those which missed each indicator.
+type callCounts struct {
+	total  int64
+	failed int64
+	slow   int64
+}
+
+// errorRatio returns the ratio of the calls which missed an indicator, 0 without calls.
+func (c callCounts) errorRatio(sli string) float64 {
+	if c.total == 0 {
+		return 0
+	}
+	bad := c.failed
+	if sli == cfg.SLILatency {
+		bad = c.slow
+	}
+	return float64(bad) / float64(c.total)
+}
+
+// sloCalls counts the calls of a proxy by minute over the longest burn rate window, and by hour over the SLO
+// window.
+type sloCalls struct {
+	minutes *rollingCounts
+	hours   *rollingCounts
+}
+
+func newSLOCalls(window time.Duration) *sloCalls {
+	return &sloCalls{
+		minutes: newRollingCounts(time.Minute, burnRateWindows[len(burnRateWindows)-1].duration),
+		hours:   newRollingCounts(time.Hour, window),
+	}
+}
+
+// rollingCounts counts the calls in buckets of a fixed width, reused once older than the period they cover.
+type rollingCounts struct {
+	width   time.Duration
+	buckets []callCounts
+	// slots are the indexes since the epoch of the periods counted by the buckets.
+	slots []int64
+}
+
+func newRollingCounts(width, period time.Duration) *rollingCounts {
+	size := int((period + width - 1) / width)
+	return &rollingCounts{width: width, buckets: make([]callCounts, size), slots: make([]int64, size)}
+}
+
+func (r *rollingCounts) add(now time.Time, call callCounts) {
+	slot := now.UnixNano() / int64(r.width)
+	i := slot % int64(len(r.buckets))
+	if r.slots[i] != slot {
+		r.slots[i] = slot
+		r.buckets[i] = callCounts{}
+	}
+	r.buckets[i].total += call.total
+	r.buckets[i].failed += call.failed
+	r.buckets[i].slow += call.slow
+}
+
+// sum returns the calls of the buckets covering the window, the current one included.
+func (r *rollingCounts) sum(now time.Time, window time.Duration) callCounts {
+	current := now.UnixNano() / int64(r.width)
+	count := min(int64((window+r.width-1)/r.width), int64(len(r.buckets)))
+	var total callCounts
+	for slot := current - count + 1; slot <= current; slot++ {
+		i := slot % int64(len(r.buckets))
+		if r.slots[i] == slot {
+			total.total += r.buckets[i].total
+			total.failed += r.buckets[i].failed
+			total.slow += r.buckets[i].slow
+		}
+	}
+	return total
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLO(t *testing.T) {
+	defer SLOCallsCounter.Reset()
+	defer SLOBadCallsCounter.Reset()
+
+	var disabled *SLO
+	disabled.ObserveToolCall("github", time.Second, true)
+	NewSLO(&cfg.SLOConfig{}).ObserveToolCall("github", time.Second, true)
+	assert.Equal(t, 0, testutil.CollectAndCount(SLOCallsCounter))
+
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	slo := NewSLO(&cfg.SLOConfig{
+		Enabled:          true,
+		Availability:     0.5,
+		Latency:          0.75,
+		LatencyThreshold: time.Second,
+		Window:           24 * time.Hour,
+		Objectives:       []string{"jira:availability=0.75"},
+	})
+	slo.now = func() time.Time { return now }
+
+	// Two hours ago: 4 successful calls, and a failed and slow call of jira
+	now = now.Add(-2 * time.Hour)
+	for range 4 {
+		slo.ObserveToolCall("github", 100*time.Millisecond, false)
+	}
+	slo.ObserveToolCall("jira", 2*time.Second, true)
+	// Now: 4 calls, 2 failed
+	now = now.Add(2 * time.Hour)
+	for i := range 4 {
+		slo.ObserveToolCall("github", time.Second, i < 2)
+	}
+
+	assert.Equal(t, 8.0, testutil.ToFloat64(SLOCallsCounter.WithLabelValues("github")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(SLOBadCallsCounter.WithLabelValues("github", cfg.SLIAvailability)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(SLOBadCallsCounter.WithLabelValues("jira", cfg.SLILatency)))
+
+	expected := `
+# HELP mcp_gateway_slo_burn_rate Rate at which the error budget of the proxy is consumed over the window, 1 consuming it exactly over the SLO window
+# TYPE mcp_gateway_slo_burn_rate gauge
+mcp_gateway_slo_burn_rate{proxy="github",sli="availability",window="1h"} 1
+mcp_gateway_slo_burn_rate{proxy="github",sli="availability",window="30m"} 1
+mcp_gateway_slo_burn_rate{proxy="github",sli="availability",window="5m"} 1
+mcp_gateway_slo_burn_rate{proxy="github",sli="availability",window="6h"} 0.5
+mcp_gateway_slo_burn_rate{proxy="github",sli="latency",window="1h"} 0
+mcp_gateway_slo_burn_rate{proxy="github",sli="latency",window="30m"} 0
+mcp_gateway_slo_burn_rate{proxy="github",sli="latency",window="5m"} 0
+mcp_gateway_slo_burn_rate{proxy="github",sli="latency",window="6h"} 0
+mcp_gateway_slo_burn_rate{proxy="jira",sli="availability",window="1h"} 0
+mcp_gateway_slo_burn_rate{proxy="jira",sli="availability",window="30m"} 0
+mcp_gateway_slo_burn_rate{proxy="jira",sli="availability",window="5m"} 0
+mcp_gateway_slo_burn_rate{proxy="jira",sli="availability",window="6h"} 4
+mcp_gateway_slo_burn_rate{proxy="jira",sli="latency",window="1h"} 0
+mcp_gateway_slo_burn_rate{proxy="jira",sli="latency",window="30m"} 0
+mcp_gateway_slo_burn_rate{proxy="jira",sli="latency",window="5m"} 0
+mcp_gateway_slo_burn_rate{proxy="jira",sli="latency",window="6h"} 4
+# HELP mcp_gateway_slo_error_budget_remaining_ratio Ratio of the error budget of the proxy left over the SLO window, negative once exhausted
+# TYPE mcp_gateway_slo_error_budget_remaining_ratio gauge
+mcp_gateway_slo_error_budget_remaining_ratio{proxy="github",sli="availability"} 0.5
+mcp_gateway_slo_error_budget_remaining_ratio{proxy="github",sli="latency"} 1
+mcp_gateway_slo_error_budget_remaining_ratio{proxy="jira",sli="availability"} -3
+mcp_gateway_slo_error_budget_remaining_ratio{proxy="jira",sli="latency"} -3
+# HELP mcp_gateway_slo_objective Objective of the service level indicator of the proxy
+# TYPE mcp_gateway_slo_objective gauge
+mcp_gateway_slo_objective{proxy="github",sli="availability"} 0.5
+mcp_gateway_slo_objective{proxy="github",sli="latency"} 0.75
+mcp_gateway_slo_objective{proxy="jira",sli="availability"} 0.75
+mcp_gateway_slo_objective{proxy="jira",sli="latency"} 0.75
+# HELP mcp_gateway_slo_sli_ratio Ratio of the tool calls of the proxy which met the service level indicator over the SLO window
+# TYPE mcp_gateway_slo_sli_ratio gauge
+mcp_gateway_slo_sli_ratio{proxy="github",sli="availability"} 0.75
+mcp_gateway_slo_sli_ratio{proxy="github",sli="latency"} 1
+mcp_gateway_slo_sli_ratio{proxy="jira",sli="availability"} 0
+mcp_gateway_slo_sli_ratio{proxy="jira",sli="latency"} 0
+`
+	require.NoError(t, testutil.CollectAndCompare(slo, strings.NewReader(expected),
+		"mcp_gateway_slo_burn_rate", "mcp_gateway_slo_error_budget_remaining_ratio", "mcp_gateway_slo_objective", "mcp_gateway_slo_sli_ratio"))
+
+	// The calls older than the SLO window are no longer counted
+	now = now.Add(23 * time.Hour)
+	slo.DeleteProxy("jira")
+	expected = `
+# HELP mcp_gateway_slo_sli_ratio Ratio of the tool calls of the proxy which met the service level indicator over the SLO window
+# TYPE mcp_gateway_slo_sli_ratio gauge
+mcp_gateway_slo_sli_ratio{proxy="github",sli="availability"} 0.5
+mcp_gateway_slo_sli_ratio{proxy="github",sli="latency"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(slo, strings.NewReader(expected), "mcp_gateway_slo_sli_ratio"))
+	assert.Equal(t, 1, testutil.CollectAndCount(SLOCallsCounter), "the counters of the deleted proxy must be deleted")
+}
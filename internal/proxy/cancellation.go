@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// MethodCancelled is the method of the MCP cancellation notifications.
+const MethodCancelled = "notifications/cancelled"
+
+// cancelNotificationTimeout bounds the sending of a cancellation notification to the upstream server.
+const cancelNotificationTimeout = 5 * time.Second
+
+// UpstreamCancelledError is returned when the upstream server cancels a tool call.
+type UpstreamCancelledError struct {
+	Reason string
+}
+
+func (e *UpstreamCancelledError) Error() string {
+	if e.Reason == "" {
+		return "the upstream server cancelled the call"
+	}
+	return "the upstream server cancelled the call: " + e.Reason
+}
+
+// cancellableTransport tracks the tool calls sent to the upstream server, to notify the upstream server of the
+// calls canceled by the gateway, e.g. when the client cancels its call or the call times out, and to cancel the
+// calls canceled by the upstream server.
+type cancellableTransport struct {
+	*transport.StreamableHTTP
+	logger logger.Logger
+
+	mu sync.Mutex
+	// calls are the cancel functions of the in-flight tool calls, by request ID
+	calls map[string]context.CancelCauseFunc
+}
+
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func newCancellableTransport(tr *transport.StreamableHTTP, logger logger.Logger) *cancellableTransport {
+	return &cancellableTransport{
+		StreamableHTTP: tr,
+		logger:         logger,
+		calls:          make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// SendRequest sends a request to the upstream server. A tool call canceled before its response is followed by a
+// cancellation notification, as the upstream server may not notice the closed connection.
+func (t *cancellableTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if request.Method != string(mcp.MethodToolsCall) {
+		return t.StreamableHTTP.SendRequest(ctx, request)
+	}
+
+	callCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	key := request.ID.String()
+	t.mu.Lock()
+	t.calls[key] = cancel
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.calls, key)
+		t.mu.Unlock()
+	}()
+
+	response, err := t.StreamableHTTP.SendRequest(callCtx, request)
+	if err == nil {
+		return response, nil
+	}
+	var cancelled *UpstreamCancelledError
+	if errors.As(context.Cause(callCtx), &cancelled) {
+		return nil, cancelled
+	}
+	if ctx.Err() != nil {
+		go t.notifyCancelled(request.ID, context.Cause(ctx))
+	}
+	return nil, err
+}
+
+// notifyCancelled notifies the upstream server that the gateway canceled a request.
+func (t *cancellableTransport) notifyCancelled(id mcp.RequestId, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelNotificationTimeout)
+	defer cancel()
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: MethodCancelled,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{"requestId": id, "reason": cause.Error()},
+			},
+		},
+	}
+	if err := t.StreamableHTTP.SendNotification(ctx, notification); err != nil {
+		t.logger.Debug("failed to notify the upstream server of the cancellation", zap.Error(err))
+	}
+}
+
+// SetNotificationHandler sets the handler of the upstream notifications. The cancellations of the upstream server
+// cancel the tool calls, they are not passed to the handler.
+func (t *cancellableTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	t.StreamableHTTP.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+		if notification.Method != MethodCancelled {
+			handler(notification)
+			return
+		}
+		params, err := cancelledParams(notification)
+		if err != nil {
+			t.logger.Warn("invalid cancellation notification", zap.Error(err))
+			return
+		}
+		t.mu.Lock()
+		cancel, ok := t.calls[params.RequestId.String()]
+		t.mu.Unlock()
+		if ok {
+			t.logger.Info("the upstream server cancelled a tool call", zap.String("reason", params.Reason))
+			cancel(&UpstreamCancelledError{Reason: params.Reason})
+		}
+	})
+}
+
+// cancelledParams decodes the parameters of a cancellation notification.
+func cancelledParams(notification mcp.JSONRPCNotification) (mcp.CancelledNotificationParams, error) {
+	var params mcp.CancelledNotificationParams
+	raw, err := json.Marshal(notification.Params)
+	if err != nil {
+		return params, err
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return params, err
+	}
+	if params.RequestId.IsNil() {
+		return params, fmt.Errorf("the cancellation has no request ID")
+	}
+	return params, nil
+}
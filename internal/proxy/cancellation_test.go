@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy_Cancellation(t *testing.T) {
+	started := make(chan struct{}, 1)
+	mcpServer := server.NewMCPServer("upstream", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("wait"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	handler := server.NewStreamableHTTPServer(mcpServer)
+
+	var mu sync.Mutex
+	var cancellations []mcp.CancelledNotificationParams
+	aborts := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		var message struct {
+			ID     mcp.RequestId   `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		_ = json.Unmarshal(body, &message)
+		switch {
+		case message.Method == MethodCancelled:
+			var params mcp.CancelledNotificationParams
+			_ = json.Unmarshal(message.Params, &params)
+			mu.Lock()
+			cancellations = append(cancellations, params)
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		case message.Method == string(mcp.MethodToolsCall) && bytes.Contains(message.Params, []byte(`"abort"`)):
+			// The upstream server cancels the call, then keeps the stream open.
+			mu.Lock()
+			aborts++
+			mu.Unlock()
+			data, _ := json.Marshal(mcp.JSONRPCNotification{
+				JSONRPC: mcp.JSONRPC_VERSION,
+				Notification: mcp.Notification{
+					Method: MethodCancelled,
+					Params: mcp.NotificationParams{
+						AdditionalFields: map[string]any{"requestId": message.ID, "reason": "quota exceeded"},
+					},
+				},
+			})
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			handler.ServeHTTP(w, r)
+		}
+	}))
+	defer upstream.Close()
+	defer metrics.DeleteUpstreamMetrics("upstream")
+
+	p := newProxy(storage.ProxyConfig{Name: "upstream", Type: storage.ProxyTypeStreamableHTTP, URL: upstream.URL},
+		&cfg.ProxyConfig{}, nil, nil, logger.MustNewLogger("json", "error", ""), nil)
+	defer p.resetClient()
+
+	t.Run("a call cancelled by the gateway is cancelled upstream", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		go func() {
+			<-started
+			cancel(errors.New("the run was stopped"))
+		}()
+		_, err := p.CallTool(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "upstream:wait"}})
+		require.Error(t, err)
+
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(cancellations) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		assert.False(t, cancellations[0].RequestId.IsNil())
+		assert.Equal(t, "the run was stopped", cancellations[0].Reason)
+	})
+
+	t.Run("a call cancelled upstream ends with an error result", func(t *testing.T) {
+		result, err := p.CallTool(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "upstream:abort"}})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		assert.Equal(t, `tool call "abort" was cancelled by the upstream server: quota exceeded`,
+			result.Content[0].(mcp.TextContent).Text)
+		assert.Equal(t, map[string]any{"code": "cancelled", "reason": "quota exceeded"}, result.Meta["error"])
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 1, aborts, "a cancelled call is not replayed")
+		assert.Len(t, cancellations, 1, "the upstream server is not notified of its own cancellation")
+	})
+}
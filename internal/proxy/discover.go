@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/secrets"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Discovery is what an upstream server declares when the gateway connects to it, and its tools.
+type Discovery struct {
+	ServerInfo      mcp.Implementation
+	ProtocolVersion string
+	Capabilities    mcp.ServerCapabilities
+	Instructions    string
+	// Stateful is true if the server opened a session.
+	Stateful bool
+	Tools    []mcp.Tool
+}
+
+// Discover connects once to the upstream server of a proxy, without retry, and returns what the server declares
+// and its tools. egressProxy is the egress proxy of the gateway.
+//
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func Discover(
+	ctx context.Context,
+	proxyCfg *storage.ProxyConfig,
+	egressProxy string,
+	resolver *secrets.Resolver,
+	logger logger.Logger,
+) (*Discovery, error) {
+	cli, result, err := newUnregistered(proxyCfg, egressProxy, resolver, logger).initialize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close() //nolint:errcheck // nothing interesting to do with the error
+
+	discovery := &Discovery{
+		ServerInfo:      result.ServerInfo,
+		ProtocolVersion: result.ProtocolVersion,
+		Capabilities:    result.Capabilities,
+		Instructions:    result.Instructions,
+		Stateful:        cli.GetSessionId() != "",
+		Tools:           []mcp.Tool{},
+	}
+	if result.Capabilities.Tools == nil {
+		return discovery, nil
+	}
+	tools, err := cli.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	discovery.Tools = tools.Tools
+	return discovery, nil
+}
+
+// CheckConnection connects once to the upstream server of a proxy, without retry, to check that its URL and
+// credentials work. egressProxy is the egress proxy of the gateway.
+//
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func CheckConnection(
+	ctx context.Context,
+	proxyCfg *storage.ProxyConfig,
+	egressProxy string,
+	resolver *secrets.Resolver,
+	logger logger.Logger,
+) error {
+	cli, _, err := newUnregistered(proxyCfg, egressProxy, resolver, logger).initialize(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.Close()
+}
+
+// newUnregistered returns a proxy connecting to an upstream server without registering its tools.
+//
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func newUnregistered(proxyCfg *storage.ProxyConfig, egressProxy string, resolver *secrets.Resolver, logger logger.Logger) *proxy {
+	return &proxy{
+		name:        proxyCfg.Name,
+		cfg:         proxyCfg,
+		secrets:     resolver,
+		egressProxy: egressProxy,
+		logger:      logger.With(zap.String("mcp_proxy", proxyCfg.Name)),
+	}
+}
@@ -0,0 +1,45 @@
+package proxy
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscover(t *testing.T) {
+	mcpServer := server.NewMCPServer("GitHub MCP", "2.3.0", server.WithInstructions("Search the repositories"))
+	mcpServer.AddTool(mcp.NewTool("search", mcp.WithDescription("Search the repositories")), nil)
+	upstream := httptest.NewServer(server.NewStreamableHTTPServer(mcpServer))
+	defer upstream.Close()
+
+	discovery, err := Discover(t.Context(), &storage.ProxyConfig{Name: "github", Type: storage.ProxyTypeStreamableHTTP, URL: upstream.URL},
+		"", nil, logger.MustNewLogger("json", "error", ""))
+	require.NoError(t, err)
+	assert.Equal(t, mcp.Implementation{Name: "GitHub MCP", Version: "2.3.0"}, discovery.ServerInfo)
+	assert.Equal(t, mcp.LATEST_PROTOCOL_VERSION, discovery.ProtocolVersion)
+	assert.Equal(t, "Search the repositories", discovery.Instructions)
+	assert.NotNil(t, discovery.Capabilities.Tools)
+	assert.True(t, discovery.Stateful)
+	require.Len(t, discovery.Tools, 1)
+	assert.Equal(t, "search", discovery.Tools[0].Name)
+
+	_, err = Discover(t.Context(), &storage.ProxyConfig{Name: "down", Type: storage.ProxyTypeStreamableHTTP, URL: "http://127.0.0.1:1"},
+		"", nil, logger.MustNewLogger("json", "error", ""))
+	assert.Error(t, err)
+}
+
+func TestCheckConnection(t *testing.T) {
+	upstream := httptest.NewServer(server.NewStreamableHTTPServer(server.NewMCPServer("GitHub MCP", "2.3.0")))
+	defer upstream.Close()
+
+	assert.NoError(t, CheckConnection(t.Context(), &storage.ProxyConfig{Name: "github", Type: storage.ProxyTypeStreamableHTTP, URL: upstream.URL},
+		"", nil, logger.MustNewLogger("json", "error", "")))
+	assert.Error(t, CheckConnection(t.Context(), &storage.ProxyConfig{Name: "down", Type: storage.ProxyTypeStreamableHTTP, URL: "http://127.0.0.1:1"},
+		"", nil, logger.MustNewLogger("json", "error", "")))
+}
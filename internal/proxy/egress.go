@@ -0,0 +1,58 @@
+package proxy
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+)
+
+// egressTransports are the transports of the egress proxies, by egress proxy. They are shared by the proxies,
+// which are recreated on each refresh, so their connections are reused.
+var egressTransports sync.Map
+
+// egressTransport returns the transport of the requests sent to an upstream server, through the egress proxy of
+// the proxy, or else of the gateway. It returns nil without egress proxy, for http.DefaultTransport which uses
+// the proxy environment variables.
+func egressTransport(proxyEgress, gatewayEgress string) (http.RoundTripper, error) {
+	egress := proxyEgress
+	if egress == "" {
+		egress = gatewayEgress
+	}
+	if egress == "" {
+		return nil, nil //nolint:nilnil // nil is http.DefaultTransport
+	}
+	if transport, ok := egressTransports.Load(egress); ok {
+		return transport.(http.RoundTripper), nil //nolint:errcheck // only transports are stored
+	}
+
+	// The clone keeps the TLS settings of the default transport, e.g. in FIPS mode.
+	transport := &http.Transport{}
+	if base, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport = base.Clone()
+	}
+	if egress == cfg.EgressProxyDirect {
+		transport.Proxy = nil
+	} else {
+		u, err := cfg.ParseEgressProxy(egress)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	shared, _ := egressTransports.LoadOrStore(egress, transport)
+	return shared.(http.RoundTripper), nil //nolint:errcheck // only transports are stored
+}
+
+// closeIdleConnections closes the idle connections to the upstream servers, so the next requests open new ones.
+func closeIdleConnections() {
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+	egressTransports.Range(func(_, transport any) bool {
+		if t, ok := transport.(*http.Transport); ok {
+			t.CloseIdleConnections()
+		}
+		return true
+	})
+}
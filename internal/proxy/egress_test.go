@@ -0,0 +1,46 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEgressProxy(t *testing.T) {
+	upstream := httptest.NewServer(server.NewStreamableHTTPServer(server.NewMCPServer("GitHub MCP", "2.3.0")))
+	defer upstream.Close()
+
+	// The egress proxy forwards the requests to the absolute URL they target.
+	var proxied atomic.Int32
+	forward := &httputil.ReverseProxy{Rewrite: func(*httputil.ProxyRequest) {}}
+	egress := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		forward.ServeHTTP(w, r)
+	}))
+	defer egress.Close()
+
+	log := logger.MustNewLogger("json", "error", "")
+	config := storage.ProxyConfig{Name: "github", Type: storage.ProxyTypeStreamableHTTP, URL: upstream.URL}
+	assert.NoError(t, CheckConnection(t.Context(), &config, egress.URL, nil, log))
+	assert.Positive(t, proxied.Load(), "the requests must go through the egress proxy of the gateway")
+
+	proxied.Store(0)
+	config.EgressProxy = egress.URL
+	assert.NoError(t, CheckConnection(t.Context(), &config, "http://127.0.0.1:1", nil, log))
+	assert.Positive(t, proxied.Load(), "the egress proxy of the proxy must take precedence")
+
+	proxied.Store(0)
+	config.EgressProxy = "direct"
+	assert.NoError(t, CheckConnection(t.Context(), &config, egress.URL, nil, log))
+	assert.Zero(t, proxied.Load(), "a direct proxy must not use the egress proxy of the gateway")
+
+	config.EgressProxy = "ftp://egress.internal"
+	assert.ErrorContains(t, CheckConnection(t.Context(), &config, "", nil, log), "egress proxy URL scheme")
+}
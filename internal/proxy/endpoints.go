@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// endpointLookupTimeout bounds the resolution of the hostname of an upstream server.
+const endpointLookupTimeout = 5 * time.Second
+
+// Endpoints keeps the addresses to which the hostnames of the upstream servers resolve, to reconnect to the
+// upstream servers whose addresses changed, e.g. after a deploy behind a service mesh, before their calls fail
+// on the connections to the former addresses.
+type Endpoints struct {
+	lookup   func(ctx context.Context, host string) ([]string, error)
+	sessions *Sessions
+	logger   logger.Logger
+
+	mu sync.Mutex
+	// addresses are the sorted addresses of the upstream servers, by proxy
+	addresses map[string][]string
+}
+
+// NewEndpoints creates the addresses of the upstream servers, or returns nil if their changes are ignored. The
+// upstream sessions of the proxies whose addresses changed are closed.
+//
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func NewEndpoints(config *cfg.ProxyConfig, sessions *Sessions, logger logger.Logger) *Endpoints {
+	if !config.ReconnectOnDNSChange {
+		return nil
+	}
+	return &Endpoints{
+		lookup:    net.DefaultResolver.LookupHost,
+		sessions:  sessions,
+		logger:    logger,
+		addresses: make(map[string][]string),
+	}
+}
+
+// Refresh resolves the hostnames of the upstream servers of the proxies, and returns the names of the proxies whose
+// addresses changed since the previous refresh. Their upstream sessions and the idle connections to the upstream
+// servers are closed, so the next connections resolve the hostnames again. The addresses of a hostname which can
+// not be resolved are kept.
+func (e *Endpoints) Refresh(ctx context.Context, proxies []storage.ProxyConfig) []string {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var changed []string
+	names := make(map[string]bool, len(proxies))
+	for i := range proxies {
+		name := proxies[i].Name
+		names[name] = true
+		addresses, ok := e.resolve(ctx, proxies[i].URL)
+		if !ok {
+			continue
+		}
+		previous, known := e.addresses[name]
+		e.addresses[name] = addresses
+		if known && !slices.Equal(previous, addresses) {
+			e.logger.Info("upstream addresses changed, reconnecting", zap.String("mcp_proxy", name),
+				zap.Strings("previous", previous), zap.Strings("addresses", addresses))
+			metrics.UpstreamEndpointChangesCounter.WithLabelValues(name).Inc()
+			changed = append(changed, name)
+		}
+	}
+	for name := range e.addresses {
+		if !names[name] {
+			delete(e.addresses, name)
+		}
+	}
+
+	if len(changed) > 0 {
+		for _, name := range changed {
+			e.sessions.dropProxy(name)
+		}
+		closeIdleConnections()
+	}
+	return changed
+}
+
+// resolve returns the sorted addresses of the hostname of an upstream server URL, false if it is an IP address or
+// can not be resolved.
+func (e *Endpoints) resolve(ctx context.Context, rawURL string) ([]string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, false
+	}
+	host := u.Hostname()
+	if host == "" || net.ParseIP(host) != nil {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(ctx, endpointLookupTimeout)
+	defer cancel()
+	addresses, err := e.lookup(ctx, host)
+	if err != nil || len(addresses) == 0 {
+		e.logger.Debug("unable to resolve the upstream server", zap.String("host", host), zap.Error(err))
+		return nil, false
+	}
+	slices.Sort(addresses)
+	return slices.Compact(addresses), true
+}
@@ -0,0 +1,70 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpoints(t *testing.T) {
+	gatewayCfg := cfg.DefaultConfig().Proxy
+	log := logger.MustNewLogger("json", "error", "")
+	assert.Nil(t, NewEndpoints(&cfg.ProxyConfig{}, nil, log), "the endpoints are disabled")
+	defer metrics.DeleteUpstreamMetrics("mesh")
+	sessions := NewSessions(gatewayCfg, log)
+	defer sessions.Close()
+
+	addresses := map[string][]string{"localhost": {"10.0.0.2", "10.0.0.1"}, "jira.internal": {"10.0.1.1"}}
+	endpoints := NewEndpoints(gatewayCfg, sessions, log)
+	endpoints.lookup = func(_ context.Context, host string) ([]string, error) {
+		if addresses[host] == nil {
+			return nil, errors.New("no such host")
+		}
+		return append([]string(nil), addresses[host]...), nil
+	}
+
+	upstream := newSessionUpstream(t)
+	config := storage.ProxyConfig{Name: "mesh", Type: storage.ProxyTypeStreamableHTTP,
+		URL: strings.Replace(upstream.URL, "127.0.0.1", "localhost", 1)}
+	proxies := []storage.ProxyConfig{config,
+		{Name: "jira", URL: "https://jira.internal/mcp"}, {Name: "ip", URL: "http://10.0.2.1:8080/mcp"}}
+	p := newProxy(config, gatewayCfg, nil, sessions, log, nil)
+	defer p.resetClient()
+	whoami := func() string {
+		result, err := p.CallTool(WithCaller(context.Background(), "alice"),
+			mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "mesh:whoami"}})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	assert.Empty(t, endpoints.Refresh(t.Context(), proxies), "the first addresses are not a change")
+	session := whoami()
+	addresses["localhost"] = []string{"10.0.0.1", "10.0.0.2", "10.0.0.1"}
+	assert.Empty(t, endpoints.Refresh(t.Context(), proxies), "the order of the addresses does not matter")
+	assert.Equal(t, session, whoami())
+
+	addresses["jira.internal"] = nil
+	assert.Empty(t, endpoints.Refresh(t.Context(), proxies), "the addresses are kept when the lookup fails")
+	addresses["jira.internal"] = []string{"10.0.1.1"}
+
+	addresses["localhost"] = []string{"10.0.0.3"}
+	assert.Equal(t, []string{"mesh"}, endpoints.Refresh(t.Context(), proxies))
+	assert.NotEqual(t, session, whoami(), "the session on the former addresses is closed")
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.UpstreamEndpointChangesCounter.WithLabelValues("mesh")))
+
+	assert.Empty(t, endpoints.Refresh(t.Context(), proxies[1:]))
+	assert.Equal(t, []string{"jira"}, slices.Collect(maps.Keys(endpoints.addresses)), "the removed and IP address proxies are not resolved")
+}
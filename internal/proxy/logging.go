@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// MethodLoggingMessage is the method of the MCP logging notifications.
+const MethodLoggingMessage = "notifications/message"
+
+// LogHandler receives the logging notifications sent by an upstream server during a tool call.
+// The context is the one of the in-flight call.
+type LogHandler func(ctx context.Context, proxy string, notification mcp.LoggingMessageNotification)
+
+// loggingCall is a tool call receiving the upstream logging notifications of its client.
+type loggingCall struct {
+	ctx     context.Context
+	proxy   string
+	handler LogHandler
+}
+
+// loggingCalls are the in-flight tool calls receiving the upstream logging notifications, by client they use. They
+// are global as the upstream sessions outlive the proxies which opened them.
+var (
+	loggingCallsMu  sync.Mutex
+	loggingCalls    = make(map[*client.Client]map[uint64]*loggingCall)
+	nextLoggingCall uint64
+)
+
+// trackLogging routes the logging notifications received by the client to the call, to the log handler of the
+// proxy. It returns the function which stops the routing, and is a no-op if no handler is set.
+func (p *proxy) trackLogging(ctx context.Context, cli *client.Client) func() {
+	if p.onLog == nil {
+		return func() {}
+	}
+	loggingCallsMu.Lock()
+	defer loggingCallsMu.Unlock()
+	nextLoggingCall++
+	id := nextLoggingCall
+	if loggingCalls[cli] == nil {
+		loggingCalls[cli] = make(map[uint64]*loggingCall)
+	}
+	loggingCalls[cli][id] = &loggingCall{ctx: ctx, proxy: p.name, handler: p.onLog}
+	return func() {
+		loggingCallsMu.Lock()
+		defer loggingCallsMu.Unlock()
+		delete(loggingCalls[cli], id)
+		if len(loggingCalls[cli]) == 0 {
+			delete(loggingCalls, cli)
+		}
+	}
+}
+
+// handleNotification relays the upstream notifications received by a client. The progress notifications carry
+// the token of their call and are only relayed to it. The logging notifications do not name their call: they are
+// only forwarded to the call owning the client, i.e. the only call in flight on it, so the logs of a caller never
+// reach another one. They are dropped when no call or several calls share the client.
+func (p *proxy) handleNotification(cli *client.Client, notification mcp.JSONRPCNotification) {
+	if notification.Method == MethodProgress {
+		p.handleProgress(notification)
+		return
+	}
+	if notification.Method != MethodLoggingMessage {
+		return
+	}
+
+	loggingCallsMu.Lock()
+	calls := len(loggingCalls[cli])
+	var owner *loggingCall
+	for _, call := range loggingCalls[cli] {
+		owner = call
+	}
+	loggingCallsMu.Unlock()
+	if calls != 1 {
+		if calls > 1 {
+			p.logger.Debug("dropping an upstream logging notification of a connection shared by several calls",
+				zap.Int("calls", calls))
+		}
+		return
+	}
+
+	raw, err := json.Marshal(notification.Params)
+	if err != nil {
+		p.logger.Warn("invalid logging notification", zap.Error(err))
+		return
+	}
+	message := mcp.LoggingMessageNotification{Notification: notification.Notification}
+	if err := json.Unmarshal(raw, &message.Params); err != nil {
+		p.logger.Warn("invalid logging notification", zap.Error(err))
+		return
+	}
+	owner.handler(owner.ctx, owner.proxy, message)
+}
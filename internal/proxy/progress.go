@@ -0,0 +1,92 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// MethodProgress is the method of the MCP progress notifications.
+const MethodProgress = "notifications/progress"
+
+// ProgressHandler receives the progress notifications sent by an upstream server during a tool call, carrying
+// the progress token of the client. The context is the one of the in-flight call.
+type ProgressHandler func(ctx context.Context, proxy string, notification mcp.ProgressNotification)
+
+type progressKey struct{}
+
+// WithProgress returns a context whose tool calls relay to handler the progress notifications of the upstream
+// server, as they arrive, if the client requested them with a progress token.
+func WithProgress(ctx context.Context, handler ProgressHandler) context.Context {
+	return context.WithValue(ctx, progressKey{}, handler)
+}
+
+func progressFromContext(ctx context.Context) ProgressHandler {
+	handler, _ := ctx.Value(progressKey{}).(ProgressHandler)
+	return handler
+}
+
+// progressCall is a tool call relaying the upstream progress notifications to its client.
+type progressCall struct {
+	ctx     context.Context
+	proxy   string
+	token   mcp.ProgressToken
+	handler ProgressHandler
+}
+
+// progressCalls are the in-flight tool calls relaying their progress, by gateway token. The tokens of the
+// clients are replaced by tokens unique to the gateway, since several clients can use the same token on a shared
+// upstream connection. They are global as the upstream sessions outlive the proxies which opened them.
+var (
+	progressCalls     sync.Map
+	nextProgressToken atomic.Uint64
+)
+
+// trackProgress replaces the progress token of the request by a gateway token, routing the progress
+// notifications of the call to the progress handler of the context. It returns the function which stops the
+// routing, and is a no-op if the client did not request the progress or no handler is set.
+func (p *proxy) trackProgress(ctx context.Context, req *mcp.CallToolRequest) func() {
+	handler := progressFromContext(ctx)
+	if handler == nil || req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+		return func() {}
+	}
+	token := fmt.Sprintf("mcp-gateway-%d", nextProgressToken.Add(1))
+	progressCalls.Store(token, &progressCall{ctx: ctx, proxy: p.name, token: req.Params.Meta.ProgressToken, handler: handler})
+
+	// The meta is shared with the caller: copy it before setting the gateway token.
+	meta := *req.Params.Meta
+	meta.ProgressToken = token
+	req.Params.Meta = &meta
+	return func() { progressCalls.Delete(token) }
+}
+
+// handleProgress relays an upstream progress notification to the call which requested it, with the progress
+// token of its client. The notifications of unknown or finished calls are dropped.
+func (p *proxy) handleProgress(notification mcp.JSONRPCNotification) {
+	raw, err := json.Marshal(notification.Params)
+	if err != nil {
+		p.logger.Warn("invalid progress notification", zap.Error(err))
+		return
+	}
+	progress := mcp.ProgressNotification{Notification: notification.Notification}
+	if err := json.Unmarshal(raw, &progress.Params); err != nil {
+		p.logger.Warn("invalid progress notification", zap.Error(err))
+		return
+	}
+	token, ok := progress.Params.ProgressToken.(string)
+	if !ok {
+		return
+	}
+	value, ok := progressCalls.Load(token)
+	if !ok {
+		return
+	}
+	call := value.(*progressCall) //nolint:errcheck // only progress calls are stored
+	progress.Params.ProgressToken = call.token
+	call.handler(call.ctx, call.proxy, progress)
+}
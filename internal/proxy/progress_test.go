@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy_Progress(t *testing.T) {
+	relayed := make(chan mcp.ProgressNotificationParams, 1)
+	upstreamTokens := make(chan mcp.ProgressToken, 1)
+	mcpServer := server.NewMCPServer("upstream", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("export"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+			return mcp.NewToolResultText("done"), nil
+		}
+		upstreamTokens <- req.Params.Meta.ProgressToken
+		err := server.ServerFromContext(ctx).SendNotificationToClient(ctx, MethodProgress, map[string]any{
+			"progressToken": req.Params.Meta.ProgressToken,
+			"progress":      1,
+			"total":         2,
+			"message":       "first chunk",
+		})
+		if err != nil {
+			return nil, err
+		}
+		// The result is only sent once the progress has reached the client of the gateway.
+		select {
+		case <-relayed:
+		case <-time.After(5 * time.Second):
+			return mcp.NewToolResultError("the progress was not relayed"), nil
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	upstream := httptest.NewServer(server.NewStreamableHTTPServer(mcpServer))
+	defer upstream.Close()
+	defer metrics.DeleteUpstreamMetrics("exports")
+
+	p := newProxy(storage.ProxyConfig{Name: "exports", Type: storage.ProxyTypeStreamableHTTP, URL: upstream.URL},
+		&cfg.ProxyConfig{}, nil, nil, logger.MustNewLogger("json", "error", ""), nil)
+	defer p.resetClient()
+
+	request := func(token mcp.ProgressToken) mcp.CallToolRequest {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "exports:export"}}
+		if token != nil {
+			req.Params.Meta = &mcp.Meta{ProgressToken: token}
+		}
+		return req
+	}
+
+	t.Run("the progress is relayed with the token of the client", func(t *testing.T) {
+		var proxies []string
+		ctx := WithProgress(context.Background(), func(_ context.Context, proxy string, notification mcp.ProgressNotification) {
+			proxies = append(proxies, proxy)
+			relayed <- notification.Params
+		})
+		req := request("client-token")
+		result, err := p.CallTool(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, result.IsError, "the progress must be relayed before the result")
+		assert.Equal(t, []string{"exports"}, proxies)
+		assert.Equal(t, mcp.ProgressToken("client-token"), req.Params.Meta.ProgressToken, "the request of the caller is not modified")
+		assert.NotEqual(t, "client-token", <-upstreamTokens, "the upstream server receives a gateway token")
+	})
+
+	t.Run("the progress of a finished call is dropped", func(t *testing.T) {
+		var notifications []mcp.ProgressNotification
+		ctx := WithProgress(context.Background(), func(_ context.Context, _ string, notification mcp.ProgressNotification) {
+			notifications = append(notifications, notification)
+		})
+		result, err := p.CallTool(ctx, request(nil))
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+
+		p.handleNotification(nil, mcp.JSONRPCNotification{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			Notification: mcp.Notification{
+				Method: MethodProgress,
+				Params: mcp.NotificationParams{AdditionalFields: map[string]any{"progressToken": "mcp-gateway-1", "progress": 2}},
+			},
+		})
+		assert.Empty(t, notifications)
+	})
+}
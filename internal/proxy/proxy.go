@@ -3,7 +3,9 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -11,8 +13,13 @@ import (
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/secrets"
 	"github.com/matthisholleville/mcp-gateway/internal/storage"
 	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/matthisholleville/mcp-gateway/pkg/signature"
+	"github.com/yosida95/uritemplate/v3"
 	"go.uber.org/zap"
 )
 
@@ -24,37 +31,55 @@ var (
 )
 
 type proxy struct {
-	name   string
-	cfg    *storage.ProxyConfig
-	logger logger.Logger
-	client *client.Client
-	mu     sync.Mutex
+	name        string
+	cfg         *storage.ProxyConfig
+	secrets     *secrets.Resolver
+	callTimeout time.Duration
+	logger      logger.Logger
+	client      *client.Client
+	mu          sync.Mutex
+	onLog       LogHandler
+	// egressProxy is the egress proxy of the gateway, used unless the proxy sets its own.
+	egressProxy string
+	// maxResponseBytes and responseSizePolicy limit the tool results, unless the proxy sets its own.
+	maxResponseBytes   int
+	responseSizePolicy string
+	// sessions are the upstream sessions of the callers, used instead of the client if the upstream server is
+	// stateful. It is nil if they are disabled.
+	sessions *Sessions
 }
 
 type proxyInterface interface {
 	GetTools() ([]mcp.Tool, error)
 	CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	GetResourceTemplates() ([]mcp.ResourceTemplate, error)
+	ReadResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error)
+	Complete(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error)
 	GetName() string
 }
 
 var _ proxyInterface = &proxy{}
 
-// NewProxy creates a new proxy.
+// NewProxy creates a new proxy. The secret references of the proxy headers are resolved by resolver when
+// connecting. The tool calls of the callers use their own session, kept by sessions, on the stateful upstream
+// servers, unless sessions is nil. The upstream logging notifications are passed to onLog, which may be nil.
 //
 //nolint:gocritic // we need to keep logger as a parameter for the function
-func NewProxy(proxyCfg *[]storage.ProxyConfig, logger logger.Logger) (*[]proxyInterface, error) {
+func NewProxy(
+	proxyCfg *[]storage.ProxyConfig,
+	gatewayCfg *cfg.ProxyConfig,
+	resolver *secrets.Resolver,
+	sessions *Sessions,
+	logger logger.Logger,
+	onLog LogHandler,
+) (*[]proxyInterface, error) {
 	proxies := &[]proxyInterface{}
 
 	for _, srv := range *proxyCfg {
-		cfgCopy := srv
-		p := &proxy{
-			name:   cfgCopy.Name,
-			cfg:    &cfgCopy,
-			logger: logger.With(zap.String("mcp_proxy", cfgCopy.Name)),
-		}
+		p := newProxy(srv, gatewayCfg, resolver, sessions, logger, onLog)
 
 		if err := p.ensureConnected(context.Background()); err != nil {
-			logger.Error("unable to connect to MCP server", zap.String("proxy", cfgCopy.Name), zap.Error(err))
+			logger.Error("unable to connect to MCP server", zap.String("proxy", srv.Name), zap.Error(err))
 			continue
 		}
 
@@ -64,16 +89,118 @@ func NewProxy(proxyCfg *[]storage.ProxyConfig, logger logger.Logger) (*[]proxyIn
 	return proxies, nil
 }
 
+// NewLazyProxy creates a proxy without connecting it: the proxy connects to its upstream server on its first call.
+// It is used by the replicas which do not list the tools of the upstream servers themselves.
+//
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func NewLazyProxy(
+	proxyCfg storage.ProxyConfig,
+	gatewayCfg *cfg.ProxyConfig,
+	resolver *secrets.Resolver,
+	sessions *Sessions,
+	logger logger.Logger,
+	onLog LogHandler,
+) proxyInterface {
+	return newProxy(proxyCfg, gatewayCfg, resolver, sessions, logger, onLog)
+}
+
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func newProxy(
+	proxyCfg storage.ProxyConfig,
+	gatewayCfg *cfg.ProxyConfig,
+	resolver *secrets.Resolver,
+	sessions *Sessions,
+	logger logger.Logger,
+	onLog LogHandler,
+) *proxy {
+	return &proxy{
+		name:        proxyCfg.Name,
+		cfg:         &proxyCfg,
+		secrets:     resolver,
+		sessions:    sessions,
+		callTimeout: gatewayCfg.CallTimeout,
+		egressProxy: gatewayCfg.EgressProxy,
+		logger:      logger.With(zap.String("mcp_proxy", proxyCfg.Name)),
+		onLog:       onLog,
+
+		maxResponseBytes:   gatewayCfg.MaxResponseBytes,
+		responseSizePolicy: gatewayCfg.ResponseSizePolicy,
+	}
+}
+
+// Probe connects once to the upstream server of a proxy, without retry, and returns its number of tools.
+// egressProxy is the egress proxy of the gateway.
+//
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func Probe(
+	ctx context.Context,
+	proxyCfg *storage.ProxyConfig,
+	egressProxy string,
+	resolver *secrets.Resolver,
+	logger logger.Logger,
+) (int, error) {
+	p := newUnregistered(proxyCfg, egressProxy, resolver, logger)
+	if err := p.dial(ctx); err != nil {
+		return 0, err
+	}
+	defer p.client.Close() //nolint:errcheck // nothing interesting to do with the error
+
+	result, err := p.client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return 0, err
+	}
+	return len(result.Tools), nil
+}
+
 func (p *proxy) dial(ctx context.Context) error {
-	tr, err := openStreamableHTTPProxy(p.cfg, p.logger)
+	cli, err := p.newClient(ctx)
 	if err != nil {
 		return err
 	}
+	p.client = cli
+	p.logger.Info("connected")
+	return nil
+}
 
-	cli := client.NewClient(tr) // transport wrapper
+// newClient connects a new client to the upstream server, opening a session if the server is stateful.
+func (p *proxy) newClient(ctx context.Context) (*client.Client, error) {
+	cli, _, err := p.initialize(ctx)
+	return cli, err
+}
+
+// initialize connects a new client to the upstream server and returns the result of its initialization.
+func (p *proxy) initialize(ctx context.Context) (*client.Client, *mcp.InitializeResult, error) {
+	headers, err := p.resolveHeaders(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	hmacSecret, err := p.resolveHMACSecret(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	base, err := egressTransport(p.cfg.EgressProxy, p.egressProxy)
+	if err != nil {
+		return nil, nil, err
+	}
+	tr, err := openStreamableHTTPProxy(p.cfg, headers, hmacSecret, base, p.logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cli := client.NewClient(newCancellableTransport(tr, p.logger)) // transport wrapper
+	cli.OnNotification(func(notification mcp.JSONRPCNotification) {
+		p.handleNotification(cli, notification)
+	})
+	// Starting the client passes the upstream notifications to the handlers.
+	if err := cli.Start(ctx); err != nil {
+		_ = tr.Close()
+		return nil, nil, err
+	}
 
 	// handshake MCP/initialize
-	_, err = cli.Initialize(ctx, mcp.InitializeRequest{
+	// No client capability is declared: the requests of the server to the client, sampling and elicitation, can not
+	// be relayed to the clients of the gateway.
+	result, err := cli.Initialize(ctx, mcp.InitializeRequest{
 		Params: mcp.InitializeParams{
 			ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
 			ClientInfo: mcp.Implementation{
@@ -84,12 +211,41 @@ func (p *proxy) dial(ctx context.Context) error {
 	})
 	if err != nil {
 		_ = tr.Close()
-		return err
+		return nil, nil, err
 	}
+	return cli, result, nil
+}
 
-	p.client = cli
-	p.logger.Info("connected")
-	return nil
+// resolveHeaders returns the headers sent to the upstream server, with their secret references resolved.
+func (p *proxy) resolveHeaders(ctx context.Context) (map[string]string, error) {
+	headers := make(map[string]string, len(p.cfg.Headers))
+	for _, header := range p.cfg.Headers {
+		value := header.Value
+		if p.secrets != nil {
+			var err error
+			if value, err = p.secrets.Resolve(ctx, value); err != nil {
+				return nil, fmt.Errorf("header %s: %w", header.Key, err)
+			}
+		}
+		headers[header.Key] = value
+	}
+	return headers, nil
+}
+
+// resolveHMACSecret returns the secret signing the requests sent to the upstream server, with its secret
+// reference resolved, or an empty string if the proxy does not use the hmac auth type.
+func (p *proxy) resolveHMACSecret(ctx context.Context) (string, error) {
+	if p.cfg.AuthType != storage.ProxyAuthTypeHMAC || p.cfg.HMAC == nil {
+		return "", nil
+	}
+	secret := p.cfg.HMAC.Secret
+	if p.secrets != nil {
+		var err error
+		if secret, err = p.secrets.Resolve(ctx, secret); err != nil {
+			return "", fmt.Errorf("hmac secret: %w", err)
+		}
+	}
+	return secret, nil
 }
 
 func (p *proxy) ensureConnected(ctx context.Context) error {
@@ -102,7 +258,9 @@ func (p *proxy) ensureConnected(ctx context.Context) error {
 
 	b := initialBackoff
 	for i := 0; i < maxRetriesOnConnect; i++ {
+		start := time.Now()
 		err := p.dial(ctx)
+		p.observeDial(time.Since(start), err)
 		if err == nil {
 			return nil
 		}
@@ -115,28 +273,152 @@ func (p *proxy) ensureConnected(ctx context.Context) error {
 			b = maxBackoff
 		}
 	}
+	metrics.UpstreamConnectedGauge.WithLabelValues(p.name).Set(0)
 	return fmt.Errorf("unable to connect after %d attempts", maxRetriesOnConnect)
 }
 
+// observeDial records the duration and the result of a connection attempt to the upstream server.
+func (p *proxy) observeDial(duration time.Duration, err error) {
+	if err != nil {
+		metrics.UpstreamDialDuration.WithLabelValues(p.name, "failure").Observe(duration.Seconds())
+		metrics.UpstreamConsecutiveFailuresGauge.WithLabelValues(p.name).Inc()
+		return
+	}
+	metrics.UpstreamDialDuration.WithLabelValues(p.name, "success").Observe(duration.Seconds())
+	metrics.UpstreamConsecutiveFailuresGauge.WithLabelValues(p.name).Set(0)
+	metrics.UpstreamConnectedGauge.WithLabelValues(p.name).Set(1)
+}
+
+// CallTool forwards the tool call to the upstream server. The call is bound to the
+// caller's context, so a client disconnect cancels the upstream request, and to the
+// gateway call timeout, after which a timeout error result is returned to the client.
+// The results larger than the maximum response size are truncated or rejected. The upstream
+// progress notifications are relayed to the client as they arrive, if it requested them.
 func (p *proxy) CallTool(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	req.Params.Name = strings.TrimPrefix(req.Params.Name, p.name+":")
 
-	if err := p.ensureConnected(ctx); err != nil {
+	timeout := p.effectiveCallTimeout()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	defer p.trackProgress(ctx, &req)()
+
+	res, err := p.callTool(ctx, req)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		p.logger.Warn("tool call timed out",
+			zap.String("tool", req.Params.Name),
+			zap.Duration("timeout", timeout))
+		return timeoutResult(req.Params.Name, timeout), nil
+	}
+	var cancelled *UpstreamCancelledError
+	if errors.As(err, &cancelled) {
+		return cancelledResult(req.Params.Name, cancelled.Reason), nil
+	}
+	if err != nil {
 		return nil, err
 	}
+	return p.limitResponseSize(req.Params.Name, res), nil
+}
+
+// effectiveCallTimeout returns the maximum duration of a tool call. The per-proxy
+// timeout wins when it is stricter than the gateway-wide one.
+func (p *proxy) effectiveCallTimeout() time.Duration {
+	timeout := p.callTimeout
+	if p.cfg.Timeout > 0 && (timeout <= 0 || p.cfg.Timeout < timeout) {
+		timeout = p.cfg.Timeout
+	}
+	return timeout
+}
 
-	res, err := p.client.CallTool(ctx, req)
-	if err == nil || !isTransient(err) {
+func (p *proxy) callTool(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cli, release, err := p.callClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	untrack := p.trackLogging(ctx, cli)
+	res, err := cli.CallTool(ctx, req)
+	untrack()
+	release()
+	if err == nil || !isTransient(err) || errors.As(err, new(*UpstreamCancelledError)) {
 		return res, err
 	}
 
+	// The caller went away or the deadline expired: reconnecting would only
+	// replay the call for nobody.
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
 	p.logger.Warn("transient error, forcing reconnect", zap.Error(err))
-	p.resetClient()
+	if cli == p.sharedClient() {
+		p.resetClient()
+	} else {
+		p.sessions.drop(p.name, callerFromContext(ctx))
+	}
+	metrics.UpstreamReconnectsCounter.WithLabelValues(p.name).Inc()
 
-	if err := p.ensureConnected(ctx); err != nil {
+	cli, release, err = p.callClient(ctx)
+	if err != nil {
 		return nil, err
 	}
-	return p.client.CallTool(ctx, req)
+	defer release()
+	defer p.trackLogging(ctx, cli)()
+	return cli.CallTool(ctx, req)
+}
+
+// callClient returns the client of the tool calls of the caller: its own upstream session if the upstream server
+// is stateful, i.e. it issued a session ID to the shared client, the shared client otherwise. release must be
+// called once the call completes.
+func (p *proxy) callClient(ctx context.Context) (cli *client.Client, release func(), err error) {
+	if err := p.ensureConnected(ctx); err != nil {
+		return nil, nil, err
+	}
+	shared := p.sharedClient()
+	if shared == nil {
+		return nil, nil, errors.New("the connection to the upstream server was reset")
+	}
+	caller := callerFromContext(ctx)
+	if p.sessions == nil || caller == "" || shared.GetSessionId() == "" {
+		return shared, func() {}, nil
+	}
+	return p.sessions.acquire(ctx, p, caller)
+}
+
+func (p *proxy) sharedClient() *client.Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.client
+}
+
+// timeoutResult builds the error result returned when a tool call exceeds its deadline.
+func timeoutResult(toolName string, timeout time.Duration) *mcp.CallToolResult {
+	res := mcp.NewToolResultErrorf("tool call %q timed out after %s", toolName, timeout)
+	res.Meta = map[string]any{
+		"error": map[string]any{
+			"code":    "timeout",
+			"timeout": timeout.String(),
+		},
+	}
+	return res
+}
+
+// cancelledResult builds the error result returned when the upstream server cancels a tool call.
+func cancelledResult(toolName, reason string) *mcp.CallToolResult {
+	message := fmt.Sprintf("tool call %q was cancelled by the upstream server", toolName)
+	if reason != "" {
+		message += ": " + reason
+	}
+	res := mcp.NewToolResultError(message)
+	res.Meta = map[string]any{
+		"error": map[string]any{
+			"code":   "cancelled",
+			"reason": reason,
+		},
+	}
+	return res
 }
 
 func isTransient(err error) bool {
@@ -156,6 +438,7 @@ func (p *proxy) resetClient() {
 	if p.client != nil {
 		_ = p.client.Close()
 		p.client = nil
+		metrics.UpstreamConnectedGauge.WithLabelValues(p.name).Set(0)
 	}
 }
 
@@ -173,6 +456,104 @@ func (p *proxy) GetTools() ([]mcp.Tool, error) {
 	return toolsResult.Tools, nil
 }
 
+// GetResourceTemplates returns the resource templates of the upstream server, with the URIs
+// prefixed by the proxy name. A server without the resources capability has no templates.
+func (p *proxy) GetResourceTemplates() ([]mcp.ResourceTemplate, error) {
+	ctx := context.Background()
+
+	if err := p.ensureConnected(ctx); err != nil {
+		return nil, err
+	}
+	if p.client.GetServerCapabilities().Resources == nil {
+		return nil, nil
+	}
+
+	result, err := p.client.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]mcp.ResourceTemplate, 0, len(result.ResourceTemplates))
+	for _, template := range result.ResourceTemplates {
+		if template.URITemplate == nil || template.URITemplate.Template == nil {
+			continue
+		}
+		prefixed, err := uritemplate.New(p.name + ":" + template.URITemplate.Raw())
+		if err != nil {
+			p.logger.Warn("skipping invalid resource template", zap.String("uri_template", template.URITemplate.Raw()), zap.Error(err))
+			continue
+		}
+		template.URITemplate = &mcp.URITemplate{Template: prefixed}
+		template.Name = p.name + ":" + template.Name
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// ReadResource reads a resource of the upstream server. The proxy prefix is removed from the
+// requested URI and added back to the URIs of the returned contents.
+func (p *proxy) ReadResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	req.Params.URI = strings.TrimPrefix(req.Params.URI, p.name+":")
+	// The arguments are the variables matched by the gateway, the upstream server matches its own template.
+	req.Params.Arguments = nil
+
+	if timeout := p.effectiveCallTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cli, release, err := p.callClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := cli.ReadResource(ctx, req)
+	release()
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]mcp.ResourceContents, 0, len(result.Contents))
+	for _, content := range result.Contents {
+		switch c := content.(type) {
+		case mcp.TextResourceContents:
+			c.URI = p.name + ":" + c.URI
+			contents = append(contents, c)
+		case mcp.BlobResourceContents:
+			c.URI = p.name + ":" + c.URI
+			contents = append(contents, c)
+		default:
+			contents = append(contents, content)
+		}
+	}
+	return contents, nil
+}
+
+// Complete forwards an argument completion request to the upstream server. The proxy prefix
+// is removed from the name of the referenced prompt, or from the URI of the referenced resource.
+func (p *proxy) Complete(ctx context.Context, req mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	switch ref := req.Params.Ref.(type) {
+	case mcp.PromptReference:
+		ref.Name = strings.TrimPrefix(ref.Name, p.name+":")
+		req.Params.Ref = ref
+	case mcp.ResourceReference:
+		ref.URI = strings.TrimPrefix(ref.URI, p.name+":")
+		req.Params.Ref = ref
+	}
+
+	if timeout := p.effectiveCallTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cli, release, err := p.callClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return cli.Complete(ctx, req)
+}
+
 // startHeartbeat starts a heartbeat for the proxy.
 // func (p *proxy) startHeartbeat(interval time.Duration) {
 // 	ticker := time.NewTicker(interval)
@@ -192,26 +573,36 @@ func (p *proxy) GetName() string {
 	return p.name
 }
 
-func openStreamableHTTPProxy(proxyConfig *storage.ProxyConfig, log logger.Logger) (*transport.StreamableHTTP, error) {
-	log.Debug("opening streamable HTTP proxy", zap.Any("proxyConfig", proxyConfig))
+// openStreamableHTTPProxy opens the transport to the upstream server. The requests are signed with hmacSecret,
+// if not empty, and sent with base, or http.DefaultTransport if nil.
+func openStreamableHTTPProxy(
+	proxyConfig *storage.ProxyConfig,
+	headers map[string]string,
+	hmacSecret string,
+	base http.RoundTripper,
+	log logger.Logger,
+) (*transport.StreamableHTTP, error) {
+	// The config is not logged as a whole: its headers, OAuth client secret and HMAC secret would be logged in plain text.
+	log.Debug("opening streamable HTTP proxy", zap.String("proxy", proxyConfig.Name), zap.String("url", proxyConfig.URL))
 	ctx := context.Background()
 	endpoint := proxyConfig.URL
 
-	headers := map[string]string{}
-	for _, header := range proxyConfig.Headers {
-		headers[header.Key] = header.Value
-	}
-
 	timeout := defaultTimeout
 	if proxyConfig.Timeout != 0 {
 		timeout = proxyConfig.Timeout
 	}
 
-	httpTransport, err := transport.NewStreamableHTTP(
-		endpoint,
-		transport.WithHTTPTimeout(timeout),
-		transport.WithHTTPHeaders(headers),
-	)
+	var options []transport.StreamableHTTPCOption
+	if hmacSecret != "" {
+		base = signature.NewTransport(hmacSecret, base)
+	}
+	if base != nil {
+		// The client must come first, the timeout is set on it.
+		options = append(options, transport.WithHTTPBasicClient(&http.Client{Transport: base}))
+	}
+	options = append(options, transport.WithHTTPTimeout(timeout), transport.WithHTTPHeaders(headers))
+
+	httpTransport, err := transport.NewStreamableHTTP(endpoint, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -220,7 +611,7 @@ func openStreamableHTTPProxy(proxyConfig *storage.ProxyConfig, log logger.Logger
 		return nil, err
 	}
 
-	log.Debug("streamable HTTP proxy opened", zap.Any("proxyConfig", proxyConfig))
+	log.Debug("streamable HTTP proxy opened", zap.String("proxy", proxyConfig.Name), zap.String("url", proxyConfig.URL))
 
 	return httpTransport, nil
 }
@@ -0,0 +1,185 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/secrets"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/matthisholleville/mcp-gateway/pkg/signature"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy_EffectiveCallTimeout(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		callTimeout  time.Duration
+		proxyTimeout time.Duration
+		expected     time.Duration
+	}{
+		{
+			name:        "gateway timeout only",
+			callTimeout: time.Minute,
+			expected:    time.Minute,
+		},
+		{
+			name:         "stricter proxy timeout",
+			callTimeout:  time.Minute,
+			proxyTimeout: 10 * time.Second,
+			expected:     10 * time.Second,
+		},
+		{
+			name:         "looser proxy timeout",
+			callTimeout:  time.Minute,
+			proxyTimeout: time.Hour,
+			expected:     time.Minute,
+		},
+		{
+			name:         "no gateway timeout",
+			proxyTimeout: 5 * time.Second,
+			expected:     5 * time.Second,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p := &proxy{
+				cfg:         &storage.ProxyConfig{Timeout: test.proxyTimeout},
+				callTimeout: test.callTimeout,
+			}
+			assert.Equal(t, test.expected, p.effectiveCallTimeout())
+		})
+	}
+}
+
+func TestProxy_TimeoutResult(t *testing.T) {
+	res := timeoutResult("search", 30*time.Second)
+	assert.True(t, res.IsError)
+	assert.Equal(t, `tool call "search" timed out after 30s`, res.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, "timeout", res.Meta["error"].(map[string]any)["code"])
+}
+
+func TestProxy_HandleNotification(t *testing.T) {
+	type forwarded struct {
+		proxy   string
+		call    any
+		message string
+	}
+	var received []forwarded
+	p := &proxy{
+		name:   "github",
+		logger: logger.MustNewLogger("json", "debug", "test"),
+		onLog: func(ctx context.Context, proxy string, notification mcp.LoggingMessageNotification) {
+			received = append(received, forwarded{proxy: proxy, call: ctx.Value(callKey{}), message: notification.Params.Data.(string)})
+		},
+	}
+	notification := func(method string) mcp.JSONRPCNotification {
+		return mcp.JSONRPCNotification{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			Notification: mcp.Notification{
+				Method: method,
+				Params: mcp.NotificationParams{AdditionalFields: map[string]any{"level": "info", "data": "hello"}},
+			},
+		}
+	}
+	alice, bob := &client.Client{}, &client.Client{}
+
+	// No call in flight: the notification is dropped.
+	p.handleNotification(alice, notification(MethodLoggingMessage))
+	assert.Empty(t, received)
+
+	untrack := p.trackLogging(context.WithValue(context.Background(), callKey{}, "call-1"), alice)
+	p.handleNotification(alice, notification("notifications/progress"))
+	p.handleNotification(alice, notification(MethodLoggingMessage))
+	p.handleNotification(bob, notification(MethodLoggingMessage))
+	assert.Equal(t, []forwarded{{proxy: "github", call: "call-1", message: "hello"}}, received,
+		"the notifications are only forwarded to the call of the client they arrived on")
+
+	// The owner of the notifications of a client shared by several calls is ambiguous: they are dropped.
+	untrackOther := p.trackLogging(context.WithValue(context.Background(), callKey{}, "call-2"), alice)
+	p.handleNotification(alice, notification(MethodLoggingMessage))
+	untrackOther()
+	untrack()
+	p.handleNotification(alice, notification(MethodLoggingMessage))
+
+	assert.Len(t, received, 1)
+	assert.Empty(t, loggingCalls, "the finished calls are not kept")
+}
+
+type callKey struct{}
+
+func TestProxy_ResolveHeaders(t *testing.T) {
+	p := &proxy{
+		cfg: &storage.ProxyConfig{Headers: []storage.ProxyHeader{
+			{Key: "X-Team", Value: "platform"},
+			{Key: "Authorization", Value: "vault:kv/data/mcp#token"},
+		}},
+		secrets: secrets.NewResolver(&cfg.VaultConfig{}, &cfg.SecretsConfig{}),
+	}
+	_, err := p.resolveHeaders(context.Background())
+	assert.ErrorIs(t, err, secrets.ErrVaultNotConfigured)
+
+	p.cfg.Headers = p.cfg.Headers[:1]
+	headers, err := p.resolveHeaders(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Team": "platform"}, headers)
+}
+
+func TestProbe_HMAC(t *testing.T) {
+	mcpServer := server.NewMCPServer("upstream", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("search"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	handler := server.NewStreamableHTTPServer(mcpServer)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := signature.Verify(r, "s3cr3t", signature.DefaultMaxSkew); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer upstream.Close()
+
+	config := &storage.ProxyConfig{
+		Name:     "signed",
+		Type:     storage.ProxyTypeStreamableHTTP,
+		URL:      upstream.URL,
+		AuthType: storage.ProxyAuthTypeHMAC,
+		HMAC:     &storage.ProxyHMAC{Secret: "s3cr3t"},
+	}
+	log := logger.MustNewLogger("json", "error", "")
+	tools, err := Probe(context.Background(), config, "", nil, log)
+	require.NoError(t, err)
+	assert.Equal(t, 1, tools)
+
+	config.AuthType = storage.ProxyAuthTypeHeader
+	_, err = Probe(context.Background(), config, "", nil, log)
+	assert.Error(t, err, "the requests must not be signed without the hmac auth type")
+}
+
+func TestProxy_ObserveDial(t *testing.T) {
+	p := &proxy{name: "metrics-test"}
+	defer metrics.DeleteUpstreamMetrics(p.name)
+
+	p.observeDial(time.Second, errors.New("connection refused"))
+	p.observeDial(time.Second, errors.New("connection refused"))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.UpstreamConsecutiveFailuresGauge.WithLabelValues(p.name)))
+
+	p.observeDial(100*time.Millisecond, nil)
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.UpstreamConsecutiveFailuresGauge.WithLabelValues(p.name)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.UpstreamConnectedGauge.WithLabelValues(p.name)))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.UpstreamDialDuration, "mcp_gateway_upstream_dial_duration_seconds"))
+
+	metrics.DeleteUpstreamMetrics(p.name)
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.UpstreamDialDuration))
+}
@@ -0,0 +1,125 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// responseSizeLimit returns the maximum size of the tool results of the proxy, 0 if unlimited, and the policy
+// of the larger ones. The settings of the proxy win over the ones of the gateway.
+func (p *proxy) responseSizeLimit() (int, string) {
+	maxBytes, policy := p.maxResponseBytes, p.responseSizePolicy
+	if p.cfg.MaxResponseBytes > 0 {
+		maxBytes = p.cfg.MaxResponseBytes
+	}
+	if p.cfg.ResponseSizePolicy != "" {
+		policy = p.cfg.ResponseSizePolicy
+	}
+	return maxBytes, policy
+}
+
+// limitResponseSize truncates or rejects a tool result whose JSON encoding is larger than the maximum response
+// size of the proxy.
+func (p *proxy) limitResponseSize(toolName string, res *mcp.CallToolResult) *mcp.CallToolResult {
+	maxBytes, policy := p.responseSizeLimit()
+	if maxBytes <= 0 || res == nil {
+		return res
+	}
+	size := encodedSize(res)
+	if size <= maxBytes {
+		return res
+	}
+	p.logger.Warn("tool result larger than the maximum response size",
+		zap.String("tool", toolName), zap.Int("size", size), zap.Int("max_bytes", maxBytes), zap.String("policy", policy))
+	metrics.ToolResponsesOversizedCounter.WithLabelValues(p.name, policy).Inc()
+	if policy == cfg.ResponseSizePolicyReject {
+		return responseTooLargeResult(toolName, size, maxBytes)
+	}
+	return truncateResult(res, size, maxBytes)
+}
+
+// responseTooLargeResult builds the error result returned instead of a tool result larger than the maximum
+// response size.
+func responseTooLargeResult(toolName string, size, maxBytes int) *mcp.CallToolResult {
+	res := mcp.NewToolResultErrorf("the result of tool %q is %d bytes, larger than the maximum of %d bytes", toolName, size, maxBytes)
+	res.Meta = map[string]any{
+		"error": map[string]any{
+			"code":      "response_too_large",
+			"size":      size,
+			"max_bytes": maxBytes,
+		},
+	}
+	return res
+}
+
+// truncateResult returns the contents of a tool result fitting in maxBytes, ended by a truncation marker. The
+// text content crossing the limit is cut, the other contents beyond it are dropped.
+func truncateResult(res *mcp.CallToolResult, size, maxBytes int) *mcp.CallToolResult {
+	truncated := &mcp.CallToolResult{IsError: res.IsError}
+	truncated.Meta = maps.Clone(res.Meta)
+	if truncated.Meta == nil {
+		truncated.Meta = map[string]any{}
+	}
+	truncated.Meta["truncated"] = map[string]any{"size": size, "max_bytes": maxBytes}
+	marker := mcp.NewTextContent(fmt.Sprintf("[truncated: the result was %d bytes, larger than the maximum of %d bytes]", size, maxBytes))
+
+	// Each content but the first one is preceded by a comma.
+	budget := maxBytes - encodedSize(truncated) - encodedSize(marker) - 1
+	for _, content := range res.Content {
+		n := encodedSize(content) + 1
+		if n <= budget {
+			truncated.Content = append(truncated.Content, content)
+			budget -= n
+			continue
+		}
+		if text, ok := content.(mcp.TextContent); ok {
+			if cut, ok := cutText(text, budget-1); ok {
+				truncated.Content = append(truncated.Content, cut)
+			}
+		}
+		break
+	}
+	truncated.Content = append(truncated.Content, marker)
+	return truncated
+}
+
+// cutText returns the longest prefix of a text content whose JSON encoding fits in maxBytes, false if none does.
+func cutText(content mcp.TextContent, maxBytes int) (mcp.TextContent, bool) {
+	text := content.Text
+	content.Text = ""
+	available := maxBytes - encodedSize(content)
+	if available <= 0 {
+		return content, false
+	}
+	if len(text) > available {
+		text = text[:available]
+	}
+	// The escaped characters take more than a byte in JSON.
+	for {
+		for !utf8.ValidString(text) {
+			text = text[:len(text)-1]
+		}
+		content.Text = text
+		over := encodedSize(content) - maxBytes
+		if over <= 0 {
+			return content, text != ""
+		}
+		text = text[:max(len(text)-over, 0)]
+	}
+}
+
+// encodedSize returns the size of the JSON encoding of a value.
+func encodedSize(v any) int {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitResponseSize(t *testing.T) {
+	defer metrics.DeleteUpstreamMetrics("github")
+	gatewayCfg := cfg.DefaultConfig().Proxy
+	gatewayCfg.MaxResponseBytes = 400
+	log := logger.MustNewLogger("json", "error", "")
+	p := newProxy(storage.ProxyConfig{Name: "github"}, gatewayCfg, nil, nil, log, nil)
+
+	small := mcp.NewToolResultText("hello")
+	assert.Same(t, small, p.limitResponseSize("search", small))
+
+	large := &mcp.CallToolResult{Content: []mcp.Content{
+		mcp.NewTextContent("first"),
+		mcp.NewTextContent(strings.Repeat("é\"", 200)),
+		mcp.NewImageContent("aGVsbG8=", "image/png"),
+	}}
+	truncated := p.limitResponseSize("search", large)
+	assert.LessOrEqual(t, encodedSize(truncated), 400)
+	require.Len(t, truncated.Content, 3)
+	assert.Equal(t, "first", truncated.Content[0].(mcp.TextContent).Text)
+	cut := truncated.Content[1].(mcp.TextContent).Text
+	assert.NotEmpty(t, cut)
+	assert.True(t, strings.HasPrefix(strings.Repeat("é\"", 200), cut), "the text is cut on a character boundary")
+	assert.Contains(t, truncated.Content[2].(mcp.TextContent).Text, "[truncated: the result was")
+	assert.Equal(t, map[string]any{"size": encodedSize(large), "max_bytes": 400}, truncated.Meta["truncated"])
+	assert.Len(t, large.Content, 3, "the upstream result is not modified")
+
+	p.cfg.ResponseSizePolicy = cfg.ResponseSizePolicyReject
+	p.cfg.MaxResponseBytes = 1000
+	assert.Same(t, large, p.limitResponseSize("search", large), "the maximum of the proxy wins")
+	p.cfg.MaxResponseBytes = 100
+	rejected := p.limitResponseSize("search", large)
+	assert.True(t, rejected.IsError)
+	assert.Equal(t, "response_too_large", rejected.Meta["error"].(map[string]any)["code"])
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ToolResponsesOversizedCounter.WithLabelValues("github", cfg.ResponseSizePolicyTruncate)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ToolResponsesOversizedCounter.WithLabelValues("github", cfg.ResponseSizePolicyReject)))
+}
@@ -0,0 +1,319 @@
+package proxy
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type callerKey struct{}
+
+const (
+	// keepaliveCheckInterval is how often the sessions are checked for the ones to ping.
+	keepaliveCheckInterval = time.Second
+	// keepaliveTimeout is the maximum duration of a keepalive ping, after which the session is closed.
+	keepaliveTimeout = 10 * time.Second
+)
+
+// WithCaller returns a context whose tool calls are made on behalf of the caller, a client of the gateway. The
+// caller gets its own session on the stateful upstream servers.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+func callerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// Sessions keeps the upstream sessions of the clients of the gateway, toward the stateful upstream servers, i.e.
+// the servers issuing a session ID. They outlive the proxies, which are recreated on each refresh.
+type Sessions struct {
+	idleTimeout time.Duration
+	max         int
+	// keepaliveInterval is how long a session is unused before it is pinged, unless its proxy sets its own
+	keepaliveInterval time.Duration
+	logger            logger.Logger
+	now               func() time.Time
+
+	mu            sync.Mutex
+	sessions      map[sessionKey]*upstreamSession
+	stopKeepalive context.CancelFunc
+}
+
+type sessionKey struct {
+	proxy  string
+	caller string
+}
+
+type upstreamSession struct {
+	// config is the configuration of the proxy when the session was opened: the session is reopened when it changes
+	config   storage.ProxyConfig
+	client   *client.Client
+	lastUsed time.Time
+	// lastPinged is the time of the last keepalive ping, which does not keep an idle session open
+	lastPinged time.Time
+	// inFlight is the number of calls using the session, which is not closed until they complete
+	inFlight int
+}
+
+// NewSessions creates the upstream sessions of the clients, or returns nil if they are disabled.
+//
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func NewSessions(config *cfg.ProxyConfig, logger logger.Logger) *Sessions {
+	if !config.UpstreamSessions {
+		return nil
+	}
+	return &Sessions{
+		idleTimeout:       config.UpstreamSessionIdleTimeout,
+		max:               config.MaxUpstreamSessions,
+		keepaliveInterval: config.UpstreamKeepaliveInterval,
+		logger:            logger,
+		now:               time.Now,
+		sessions:          make(map[sessionKey]*upstreamSession),
+	}
+}
+
+// StartKeepalive pings the unused sessions in the background, until the sessions are closed, so the
+// intermediaries between the gateway and the upstream servers do not drop their idle connections.
+func (s *Sessions) StartKeepalive() {
+	if s == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.stopKeepalive = cancel
+	s.mu.Unlock()
+	go func() {
+		ticker := time.NewTicker(keepaliveCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.keepalive(ctx)
+			}
+		}
+	}()
+}
+
+// keepalive pings the sessions unused for the keepalive interval of their proxy, since their last call or ping,
+// and closes the ones whose ping fails: the next call of their caller opens a new session. The round-trip
+// durations of the pings are recorded as a health signal of the upstream servers.
+func (s *Sessions) keepalive(ctx context.Context) {
+	s.mu.Lock()
+	now := s.now()
+	due := make(map[sessionKey]*upstreamSession)
+	for key, session := range s.sessions {
+		interval := session.config.KeepaliveInterval
+		if interval == 0 {
+			interval = s.keepaliveInterval
+		}
+		if interval <= 0 || session.inFlight > 0 {
+			continue
+		}
+		if now.Sub(session.lastUsed) < interval || now.Sub(session.lastPinged) < interval {
+			continue
+		}
+		// The session is not closed while pinged.
+		session.inFlight++
+		session.lastPinged = now
+		due[key] = session
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for key, session := range due {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.ping(ctx, key, session)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Sessions) ping(ctx context.Context, key sessionKey, session *upstreamSession) {
+	pingCtx, cancel := context.WithTimeout(ctx, keepaliveTimeout)
+	defer cancel()
+	start := time.Now()
+	err := session.client.Ping(pingCtx)
+	duration := time.Since(start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session.inFlight--
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		metrics.UpstreamPingDuration.WithLabelValues(key.proxy, "failure").Observe(duration.Seconds())
+		s.logger.Warn("upstream keepalive ping failed, closing the session", zap.String("mcp_proxy", key.proxy),
+			zap.String("session_id", session.client.GetSessionId()), zap.Error(err))
+		if s.sessions[key] == session {
+			s.remove(key)
+		}
+		return
+	}
+	metrics.UpstreamPingDuration.WithLabelValues(key.proxy, "success").Observe(duration.Seconds())
+}
+
+// acquire returns the client of the upstream session of a caller on the upstream server of the proxy, opening
+// it if needed. release must be called once the call completes.
+func (s *Sessions) acquire(ctx context.Context, p *proxy, caller string) (cli *client.Client, release func(), err error) {
+	key := sessionKey{proxy: p.name, caller: caller}
+	if session := s.get(key, p.cfg); session != nil {
+		return session.client, s.releaser(session), nil
+	}
+
+	start := time.Now()
+	cli, err = p.newClient(ctx)
+	p.observeDial(time.Since(start), err)
+	if err != nil {
+		return nil, nil, err
+	}
+	session := s.put(key, p.cfg, cli)
+	return session.client, s.releaser(session), nil
+}
+
+// get returns the session of a caller, acquired, or nil if it has none.
+func (s *Sessions) get(key sessionKey, config *storage.ProxyConfig) *upstreamSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	session, ok := s.sessions[key]
+	if !ok {
+		return nil
+	}
+	if !reflect.DeepEqual(session.config, *config) {
+		s.remove(key)
+		return nil
+	}
+	session.inFlight++
+	session.lastUsed = s.now()
+	return session
+}
+
+// put keeps the new session of a caller, returning it acquired. The session opened by a concurrent call wins.
+func (s *Sessions) put(key sessionKey, config *storage.ProxyConfig, cli *client.Client) *upstreamSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session, ok := s.sessions[key]; ok && reflect.DeepEqual(session.config, *config) {
+		go closeClient(cli)
+		session.inFlight++
+		session.lastUsed = s.now()
+		return session
+	}
+	s.remove(key)
+	for len(s.sessions) >= s.max {
+		oldest, ok := s.leastRecentlyUsed()
+		if !ok {
+			// All the sessions are in use: they are closed once idle.
+			break
+		}
+		s.remove(oldest)
+	}
+	session := &upstreamSession{config: *config, client: cli, lastUsed: s.now(), inFlight: 1}
+	s.sessions[key] = session
+	metrics.UpstreamSessionsGauge.WithLabelValues(key.proxy).Inc()
+	s.logger.Debug("upstream session opened", zap.String("mcp_proxy", key.proxy), zap.String("session_id", cli.GetSessionId()))
+	return session
+}
+
+func (s *Sessions) releaser(session *upstreamSession) func() {
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		session.inFlight--
+		session.lastUsed = s.now()
+	}
+}
+
+// drop closes the session of a caller, e.g. after a transport error.
+func (s *Sessions) drop(proxyName, caller string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sessionKey{proxy: proxyName, caller: caller})
+}
+
+// dropProxy closes the sessions of all the callers on the upstream server of a proxy.
+func (s *Sessions) dropProxy(proxyName string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.sessions {
+		if key.proxy == proxyName {
+			s.remove(key)
+		}
+	}
+}
+
+// Close closes all the sessions, terminating them on the upstream servers.
+func (s *Sessions) Close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.stopKeepalive != nil {
+		s.stopKeepalive()
+	}
+	clients := make([]*client.Client, 0, len(s.sessions))
+	for key, session := range s.sessions {
+		clients = append(clients, session.client)
+		delete(s.sessions, key)
+		metrics.UpstreamSessionsGauge.WithLabelValues(key.proxy).Dec()
+	}
+	s.mu.Unlock()
+	for _, cli := range clients {
+		closeClient(cli)
+	}
+}
+
+// expire removes the unused sessions idle for the idle timeout. It must be called with the lock held.
+func (s *Sessions) expire() {
+	for key, session := range s.sessions {
+		if session.inFlight == 0 && s.now().Sub(session.lastUsed) >= s.idleTimeout {
+			s.remove(key)
+		}
+	}
+}
+
+// leastRecentlyUsed returns the key of the unused session used the longest ago, false if all the sessions are in
+// use. It must be called with the lock held.
+func (s *Sessions) leastRecentlyUsed() (sessionKey, bool) {
+	var oldest *upstreamSession
+	var oldestKey sessionKey
+	for key, session := range s.sessions {
+		if session.inFlight == 0 && (oldest == nil || session.lastUsed.Before(oldest.lastUsed)) {
+			oldest, oldestKey = session, key
+		}
+	}
+	return oldestKey, oldest != nil
+}
+
+// remove removes a session and closes it in the background, as closing it waits for the upstream server to
+// terminate it. The calls still using it are canceled. It must be called with the lock held.
+func (s *Sessions) remove(key sessionKey) {
+	session, ok := s.sessions[key]
+	if !ok {
+		return
+	}
+	delete(s.sessions, key)
+	metrics.UpstreamSessionsGauge.WithLabelValues(key.proxy).Dec()
+	go closeClient(session.client)
+}
+
+func closeClient(cli *client.Client) {
+	_ = cli.Close()
+}
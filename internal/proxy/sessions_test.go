@@ -0,0 +1,162 @@
+package proxy
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSessionUpstream starts an upstream server whose whoami tool returns the session of the call.
+func newSessionUpstream(t *testing.T, options ...server.StreamableHTTPOption) *httptest.Server {
+	t.Helper()
+	mcpServer := server.NewMCPServer("upstream", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(server.ClientSessionFromContext(ctx).SessionID()), nil
+	})
+	upstream := httptest.NewServer(server.NewStreamableHTTPServer(mcpServer, options...))
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+func TestProxy_UpstreamSessions(t *testing.T) {
+	gatewayCfg := cfg.DefaultConfig().Proxy
+	gatewayCfg.MaxUpstreamSessions = 2
+	log := logger.MustNewLogger("json", "error", "")
+	now := time.Now()
+	sessions := NewSessions(gatewayCfg, log)
+	sessions.now = func() time.Time { return now }
+	defer sessions.Close()
+	defer metrics.DeleteUpstreamMetrics("stateful")
+
+	upstream := newSessionUpstream(t)
+	p := newProxy(storage.ProxyConfig{Name: "stateful", Type: storage.ProxyTypeStreamableHTTP, URL: upstream.URL},
+		gatewayCfg, nil, sessions, log, nil)
+	defer p.resetClient()
+	whoami := func(caller string) string {
+		ctx := context.Background()
+		if caller != "" {
+			ctx = WithCaller(ctx, caller)
+		}
+		result, err := p.CallTool(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "stateful:whoami"}})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	alice := whoami("alice")
+	assert.Equal(t, alice, whoami("alice"), "a caller keeps its session")
+	bob := whoami("bob")
+	assert.NotEqual(t, alice, bob, "the callers do not share a session")
+	assert.NotContains(t, []string{alice, bob}, whoami(""), "the calls without caller use the shared session")
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.UpstreamSessionsGauge.WithLabelValues("stateful")))
+
+	t.Run("the least recently used session is closed beyond the maximum", func(t *testing.T) {
+		now = now.Add(time.Second)
+		whoami("alice")
+		now = now.Add(time.Second)
+		whoami("carol")
+		assert.Equal(t, alice, whoami("alice"))
+		assert.NotEqual(t, bob, whoami("bob"), "the session of bob was closed")
+		assert.Equal(t, 2.0, testutil.ToFloat64(metrics.UpstreamSessionsGauge.WithLabelValues("stateful")))
+	})
+
+	t.Run("the idle sessions are closed", func(t *testing.T) {
+		now = now.Add(gatewayCfg.UpstreamSessionIdleTimeout)
+		assert.NotEqual(t, alice, whoami("alice"))
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.UpstreamSessionsGauge.WithLabelValues("stateful")))
+	})
+
+	t.Run("a session is reopened when the proxy changes", func(t *testing.T) {
+		before := whoami("alice")
+		changed := newProxy(storage.ProxyConfig{Name: "stateful", Type: storage.ProxyTypeStreamableHTTP, URL: upstream.URL,
+			Timeout: time.Minute}, gatewayCfg, nil, sessions, log, nil)
+		defer changed.resetClient()
+		result, err := changed.CallTool(WithCaller(context.Background(), "alice"),
+			mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "stateful:whoami"}})
+		require.NoError(t, err)
+		assert.NotEqual(t, before, result.Content[0].(mcp.TextContent).Text)
+	})
+}
+
+func TestProxy_StatelessUpstream(t *testing.T) {
+	gatewayCfg := cfg.DefaultConfig().Proxy
+	log := logger.MustNewLogger("json", "error", "")
+	sessions := NewSessions(gatewayCfg, log)
+	defer sessions.Close()
+	defer metrics.DeleteUpstreamMetrics("stateless")
+
+	upstream := newSessionUpstream(t, server.WithStateLess(true))
+	p := newProxy(storage.ProxyConfig{Name: "stateless", Type: storage.ProxyTypeStreamableHTTP, URL: upstream.URL},
+		gatewayCfg, nil, sessions, log, nil)
+	defer p.resetClient()
+
+	_, err := p.CallTool(WithCaller(context.Background(), "alice"), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "stateless:whoami"}})
+	require.NoError(t, err)
+	assert.Empty(t, sessions.sessions, "the stateless upstream servers are called with the shared client")
+}
+
+func TestNewSessions_Disabled(t *testing.T) {
+	gatewayCfg := cfg.DefaultConfig().Proxy
+	gatewayCfg.UpstreamSessions = false
+	assert.Nil(t, NewSessions(gatewayCfg, logger.MustNewLogger("json", "error", "")))
+}
+
+func TestSessions_Keepalive(t *testing.T) {
+	gatewayCfg := cfg.DefaultConfig().Proxy
+	log := logger.MustNewLogger("json", "error", "")
+	now := time.Now()
+	sessions := NewSessions(gatewayCfg, log)
+	sessions.now = func() time.Time { return now }
+	defer sessions.Close()
+	defer metrics.DeleteUpstreamMetrics("keepalive")
+
+	upstream := newSessionUpstream(t)
+	p := newProxy(storage.ProxyConfig{Name: "keepalive", Type: storage.ProxyTypeStreamableHTTP, URL: upstream.URL,
+		KeepaliveInterval: time.Minute}, gatewayCfg, nil, sessions, log, nil)
+	defer p.resetClient()
+	_, err := p.CallTool(WithCaller(context.Background(), "alice"), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "keepalive:whoami"}})
+	require.NoError(t, err)
+	key := sessionKey{proxy: "keepalive", caller: "alice"}
+	session := sessions.sessions[key]
+	require.NotNil(t, session)
+	pings := func() int {
+		return testutil.CollectAndCount(metrics.UpstreamPingDuration, "mcp_gateway_upstream_ping_duration_seconds")
+	}
+
+	t.Run("a session used within the interval is not pinged", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		sessions.keepalive(context.Background())
+		assert.Zero(t, pings())
+	})
+
+	t.Run("an unused session is pinged once per interval", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		sessions.keepalive(context.Background())
+		assert.Equal(t, 1, pings(), "the successful ping is recorded")
+		assert.Equal(t, now, session.lastPinged)
+		assert.Equal(t, now.Add(-time.Minute), session.lastUsed, "a ping does not keep an idle session open")
+
+		now = now.Add(30 * time.Second)
+		sessions.keepalive(context.Background())
+		assert.Equal(t, now.Add(-30*time.Second), session.lastPinged, "the session was pinged within the interval")
+	})
+
+	t.Run("a session whose ping fails is closed", func(t *testing.T) {
+		upstream.Close()
+		now = now.Add(time.Minute)
+		sessions.keepalive(context.Background())
+		assert.Equal(t, 2, pings(), "the failed ping is recorded")
+		assert.NotContains(t, sessions.sessions, key)
+	})
+}
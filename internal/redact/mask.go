@@ -0,0 +1,117 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+)
+
+// Masker masks the secrets in the tool results before they are returned to the clients.
+type Masker struct {
+	patterns        []*regexp.Regexp
+	excludedProxies []string
+}
+
+// NewMasker creates a new masker from the masking configuration.
+func NewMasker(config *cfg.MaskingConfig) (*Masker, error) {
+	m := &Masker{excludedProxies: config.ExcludedProxies}
+	for _, pattern := range config.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid masking pattern %q: %w", pattern, err)
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m, nil
+}
+
+// Result masks the secrets in the text contents and embedded text resources of the result, in place, and returns
+// the number of secrets masked.
+func (m *Masker) Result(proxy string, result *mcp.CallToolResult) int {
+	if m == nil || result == nil || slices.Contains(m.excludedProxies, proxy) {
+		return 0
+	}
+	masked := 0
+	for i, content := range result.Content {
+		switch c := content.(type) {
+		case mcp.TextContent:
+			c.Text = m.text(c.Text, &masked)
+			result.Content[i] = c
+		case *mcp.TextContent:
+			c.Text = m.text(c.Text, &masked)
+		case mcp.EmbeddedResource:
+			c.Resource = m.resource(c.Resource, &masked)
+			result.Content[i] = c
+		case *mcp.EmbeddedResource:
+			c.Resource = m.resource(c.Resource, &masked)
+		}
+	}
+	return masked
+}
+
+// Text masks the secrets in a text sent to the clients with the results of a proxy, e.g. a progress message, and
+// returns it with the number of secrets masked.
+func (m *Masker) Text(proxy, text string) (string, int) {
+	if m == nil || slices.Contains(m.excludedProxies, proxy) {
+		return text, 0
+	}
+	masked := 0
+	return m.text(text, &masked), masked
+}
+
+// Value masks the secrets in the strings of a JSON value sent to the clients with the results of a proxy, e.g. the
+// data of a logging notification, and returns it with the number of secrets masked. The value is copied, not
+// modified.
+func (m *Masker) Value(proxy string, value any) (any, int) {
+	if m == nil || slices.Contains(m.excludedProxies, proxy) {
+		return value, 0
+	}
+	masked := 0
+	return m.value(value, &masked), masked
+}
+
+func (m *Masker) value(value any, masked *int) any {
+	switch v := value.(type) {
+	case string:
+		return m.text(v, masked)
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for key, item := range v {
+			copied[key] = m.value(item, masked)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(v))
+		for i, item := range v {
+			copied[i] = m.value(item, masked)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+func (m *Masker) resource(resource mcp.ResourceContents, masked *int) mcp.ResourceContents {
+	switch r := resource.(type) {
+	case mcp.TextResourceContents:
+		r.Text = m.text(r.Text, masked)
+		return r
+	case *mcp.TextResourceContents:
+		r.Text = m.text(r.Text, masked)
+	}
+	return resource
+}
+
+// text replaces the matches of the patterns with the mask, adding their number to masked.
+func (m *Masker) text(text string, masked *int) string {
+	for _, re := range m.patterns {
+		text = re.ReplaceAllStringFunc(text, func(string) string {
+			*masked++
+			return Mask
+		})
+	}
+	return text
+}
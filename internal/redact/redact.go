@@ -0,0 +1,107 @@
+// Package redact masks sensitive values in tool call arguments and results
+// before they are written to the logs, and the secrets in tool results before
+// they are returned to the clients.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+)
+
+const (
+	// Mask replaces the value of a sensitive key, or a masked secret.
+	Mask = "[REDACTED]"
+
+	truncatedSuffix = "...(truncated)"
+)
+
+// Redactor masks sensitive keys and truncates long values.
+type Redactor struct {
+	enabled         bool
+	keyPatterns     []*regexp.Regexp
+	maxLength       int
+	excludedProxies []string
+}
+
+// New creates a new redactor from the redaction configuration.
+func New(config *cfg.RedactionConfig) (*Redactor, error) {
+	r := &Redactor{
+		enabled:         config.Enabled,
+		maxLength:       config.MaxLength,
+		excludedProxies: config.ExcludedProxies,
+	}
+	for _, pattern := range config.KeyPatterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction key pattern %q: %w", pattern, err)
+		}
+		r.keyPatterns = append(r.keyPatterns, re)
+	}
+	return r, nil
+}
+
+// Arguments returns a copy of the tool call arguments with sensitive keys masked
+// and long strings truncated. The input is never modified.
+func (r *Redactor) Arguments(proxy string, args any) any {
+	if !r.isActive(proxy) {
+		return args
+	}
+	return r.value(args)
+}
+
+// Text truncates free-form content such as a tool result.
+func (r *Redactor) Text(proxy, text string) string {
+	if !r.isActive(proxy) {
+		return text
+	}
+	return r.truncate(text)
+}
+
+// isActive reports whether redaction applies to the given proxy.
+func (r *Redactor) isActive(proxy string) bool {
+	return r != nil && r.enabled && !slices.Contains(r.excludedProxies, proxy)
+}
+
+func (r *Redactor) value(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			if r.isSensitiveKey(k) {
+				out[k] = Mask
+				continue
+			}
+			out[k] = r.value(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = r.value(item)
+		}
+		return out
+	case string:
+		return r.truncate(val)
+	default:
+		return v
+	}
+}
+
+func (r *Redactor) isSensitiveKey(key string) bool {
+	for _, re := range r.keyPatterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) truncate(s string) string {
+	if r.maxLength <= 0 || len(s) <= r.maxLength {
+		return s
+	}
+	return s[:r.maxLength] + truncatedSuffix
+}
@@ -0,0 +1,56 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor_Arguments(t *testing.T) {
+	r, err := New(&cfg.RedactionConfig{
+		Enabled:         true,
+		KeyPatterns:     []string{"password", "token"},
+		MaxLength:       5,
+		ExcludedProxies: []string{"trusted"},
+	})
+	require.NoError(t, err)
+
+	args := map[string]any{
+		"user":     "bob",
+		"Password": "hunter2",
+		"nested": map[string]any{
+			"access_token": "abc",
+			"query":        "select * from users",
+		},
+		"list": []any{"short", "much too long", 42},
+	}
+
+	assert.Equal(t, map[string]any{
+		"user":     "bob",
+		"Password": Mask,
+		"nested": map[string]any{
+			"access_token": Mask,
+			"query":        "selec" + truncatedSuffix,
+		},
+		"list": []any{"short", "much " + truncatedSuffix, 42},
+	}, r.Arguments("github", args))
+
+	// The input must be left untouched and excluded proxies are logged as is.
+	assert.Equal(t, "hunter2", args["Password"])
+	assert.Equal(t, args, r.Arguments("trusted", args))
+}
+
+func TestRedactor_Disabled(t *testing.T) {
+	r, err := New(&cfg.RedactionConfig{Enabled: false, KeyPatterns: []string{"password"}, MaxLength: 1})
+	require.NoError(t, err)
+	args := map[string]any{"password": "hunter2"}
+	assert.Equal(t, args, r.Arguments("github", args))
+	assert.Equal(t, "hunter2", r.Text("github", "hunter2"))
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	_, err := New(&cfg.RedactionConfig{Enabled: true, KeyPatterns: []string{"("}})
+	assert.Error(t, err)
+}
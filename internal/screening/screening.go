@@ -0,0 +1,194 @@
+// Package screening inspects the tool call arguments before they are forwarded to the upstream servers, to block
+// or flag the calls carrying secrets, injection markers or jailbreak strings.
+package screening
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+)
+
+// Action is what the gateway does with a screened tool call.
+type Action string
+
+const (
+	ActionAllow Action = "allow"
+	ActionFlag  Action = cfg.ScreeningActionFlag
+	ActionBlock Action = cfg.ScreeningActionBlock
+)
+
+// DenyPatternRule is the rule of the deny patterns added to the built-in ones.
+const DenyPatternRule = "deny-pattern"
+
+// Call is a tool call to screen.
+type Call struct {
+	Proxy     string
+	Tool      string
+	Arguments map[string]any
+	// Claims are the claims of the caller, empty if unauthenticated.
+	Claims map[string]any
+}
+
+// Verdict is the outcome of the screening of a tool call.
+type Verdict struct {
+	Action Action
+	// Rule is the rule the call matched, empty if allowed.
+	Rule string
+	// Reason explains the verdict without revealing the matched value.
+	Reason string
+}
+
+// Allow is the verdict of the calls matching no rule.
+var Allow = Verdict{Action: ActionAllow}
+
+// Screener inspects a tool call before it is forwarded upstream. An error means the call could not be screened.
+type Screener interface {
+	Screen(ctx context.Context, call Call) (Verdict, error)
+}
+
+// Chain screens the calls with each screener in turn. The first block stops the chain, otherwise the first flag
+// is returned.
+type Chain []Screener
+
+func (c Chain) Screen(ctx context.Context, call Call) (Verdict, error) {
+	verdict := Allow
+	for _, screener := range c {
+		v, err := screener.Screen(ctx, call)
+		if err != nil {
+			return Verdict{}, err
+		}
+		switch {
+		case v.Action == ActionBlock:
+			return v, nil
+		case v.Action == ActionFlag && verdict.Action == ActionAllow:
+			verdict = v
+		}
+	}
+	return verdict, nil
+}
+
+// builtinRules are the deny patterns of the built-in rules.
+var builtinRules = map[string][]string{
+	cfg.ScreeningRuleSecrets: {
+		`\b(AKIA|ASIA)[0-9A-Z]{16}\b`,
+		`-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+		`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+		`\bxox[abprs]-[A-Za-z0-9-]{10,}`,
+		`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]{20,}=*`,
+		`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]+`,
+	},
+	cfg.ScreeningRuleSQLInjection: {
+		`(?i)\bunion\s+(all\s+)?select\b`,
+		`(?i);\s*(drop|truncate|alter)\s+(table|database|schema)\b`,
+		`(?i)'\s*or\s+'?\d+'?\s*=\s*'?\d+`,
+		`(?i)'\s*or\s+'[^']*'\s*=\s*'`,
+		`(?i)\b(sleep|pg_sleep|benchmark)\s*\(\s*\d+`,
+	},
+	cfg.ScreeningRuleShellInjection: {
+		`\$\([^)]*\)`,
+		"`[^`]+`",
+		`(;|&&|\|\|)\s*(rm|curl|wget|nc|ncat|bash|sh|zsh|chmod|chown|python3?|perl)\b`,
+		`\|\s*(ba|z)?sh\b`,
+		`\.\./\.\./`,
+	},
+	cfg.ScreeningRuleJailbreak: {
+		`(?i)\b(ignore|disregard|forget)\s+(all\s+|any\s+)?(the\s+)?(previous|prior|above|earlier)\s+(instructions|prompts|rules)`,
+		`(?i)\byou\s+are\s+now\s+(DAN|in\s+developer\s+mode|unrestricted)`,
+		`(?i)\b(reveal|print|show|repeat)\s+(me\s+)?(your|the)\s+(system\s+prompt|hidden\s+instructions)`,
+		`(?i)\bdo\s+anything\s+now\b`,
+	},
+}
+
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// PatternScreener matches the argument keys and string values of the tool calls against deny patterns.
+type PatternScreener struct {
+	rules           []rule
+	action          Action
+	excludedProxies []string
+}
+
+// New creates a pattern screener from the screening configuration.
+func New(config *cfg.ScreeningConfig) (*PatternScreener, error) {
+	p := &PatternScreener{action: Action(config.Action), excludedProxies: config.ExcludedProxies}
+	for _, name := range config.Rules {
+		patterns, ok := builtinRules[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown screening rule %q", name)
+		}
+		for _, pattern := range patterns {
+			p.rules = append(p.rules, rule{name: name, pattern: regexp.MustCompile(pattern)})
+		}
+	}
+	for _, pattern := range config.DenyPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid screening deny pattern %q: %w", pattern, err)
+		}
+		p.rules = append(p.rules, rule{name: DenyPatternRule, pattern: re})
+	}
+	return p, nil
+}
+
+// Screen returns the configured action if an argument key or string value matches a deny pattern.
+func (p *PatternScreener) Screen(_ context.Context, call Call) (Verdict, error) {
+	if slices.Contains(p.excludedProxies, call.Proxy) {
+		return Allow, nil
+	}
+	if verdict, ok := p.match("", call.Arguments); ok {
+		return verdict, nil
+	}
+	return Allow, nil
+}
+
+// match screens the keys and the string values under the path, recursively.
+func (p *PatternScreener) match(path string, value any) (Verdict, bool) {
+	switch val := value.(type) {
+	case map[string]any:
+		for key, item := range val {
+			itemPath := joinPath(path, key)
+			if verdict, ok := p.matchString(itemPath, key); ok {
+				return verdict, true
+			}
+			if verdict, ok := p.match(itemPath, item); ok {
+				return verdict, true
+			}
+		}
+	case []any:
+		for i, item := range val {
+			if verdict, ok := p.match(joinPath(path, strconv.Itoa(i)), item); ok {
+				return verdict, true
+			}
+		}
+	case string:
+		return p.matchString(path, val)
+	}
+	return Verdict{}, false
+}
+
+func (p *PatternScreener) matchString(path, s string) (Verdict, bool) {
+	for _, r := range p.rules {
+		if r.pattern.MatchString(s) {
+			return Verdict{
+				Action: p.action,
+				Rule:   r.name,
+				Reason: fmt.Sprintf("the argument %q matches the %s rule", path, r.name),
+			}, true
+		}
+	}
+	return Verdict{}, false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
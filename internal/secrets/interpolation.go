@@ -0,0 +1,124 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// interpolationPattern matches the ${env:NAME} and ${file:PATH} references, which may be embedded in a value.
+var interpolationPattern = regexp.MustCompile(`\$\{(env|file):([^}]*)\}`)
+
+// envNamePattern matches the names of the environment variables.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// hasInterpolation returns true if the value embeds a ${env:NAME} or ${file:PATH} reference.
+func hasInterpolation(value string) bool {
+	return interpolationPattern.MatchString(value)
+}
+
+// validateInterpolation checks the syntax of the ${env:NAME} and ${file:PATH} references of the value.
+func validateInterpolation(value string) error {
+	for _, match := range interpolationPattern.FindAllStringSubmatch(value, -1) {
+		if err := validateInterpolationReference(match[0], match[1], match[2]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateInterpolationReference(reference, kind, name string) error {
+	switch kind {
+	case "env":
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid reference %s: must be written as ${env:NAME}", reference)
+		}
+	case "file":
+		if !filepath.IsAbs(name) {
+			return fmt.Errorf("invalid reference %s: must be written as ${file:/ABSOLUTE/PATH}", reference)
+		}
+	}
+	return nil
+}
+
+// interpolate replaces the ${env:NAME} and ${file:PATH} references of the value with the environment variables
+// and the file contents, without their trailing newline.
+func (r *Resolver) interpolate(value string) (string, error) {
+	var firstErr error
+	resolved := interpolationPattern.ReplaceAllStringFunc(value, func(reference string) string {
+		if firstErr != nil {
+			return ""
+		}
+		match := interpolationPattern.FindStringSubmatch(reference)
+		if err := validateInterpolationReference(reference, match[1], match[2]); err != nil {
+			firstErr = err
+			return ""
+		}
+		var secret string
+		var err error
+		if match[1] == "env" {
+			secret, err = r.env(match[2])
+		} else {
+			secret, err = r.file(match[2])
+		}
+		if err != nil {
+			firstErr = fmt.Errorf("%s: %w", reference, err)
+		}
+		return secret
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return resolved, nil
+}
+
+// env returns the environment variable, if allowed.
+func (r *Resolver) env(name string) (string, error) {
+	allowed := false
+	for _, pattern := range r.config.AllowedEnv {
+		if ok, _ := path.Match(pattern, name); ok {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return "", fmt.Errorf("environment variable %s is not allowed (--secrets-allowed-env)", name)
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// file returns the content of the file, if it is in an allowed directory once its symbolic links are resolved:
+// the files of the Kubernetes secret volumes are links to a hidden directory of the volume.
+func (r *Resolver) file(name string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		return "", err
+	}
+	allowed := false
+	for _, dir := range r.config.AllowedDirs {
+		if resolvedDir, err := filepath.EvalSymlinks(dir); err == nil && isInDir(resolved, resolvedDir) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return "", fmt.Errorf("file %s is not in an allowed directory (--secrets-allowed-dirs)", name)
+	}
+	content, err := os.ReadFile(resolved) //nolint:gosec // G304: the path is checked against the allowed directories
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func isInDir(name, dir string) bool {
+	rel, err := filepath.Rel(dir, name)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
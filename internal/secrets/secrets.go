@@ -0,0 +1,77 @@
+// Package secrets resolves the secret references stored in place of the secrets in the proxy configurations,
+// so the secrets themselves are never persisted by the gateway. A value is either a Vault reference,
+// vault:PATH#FIELD, or may embed ${env:NAME} and ${file:PATH} references.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+)
+
+// VaultPrefix prefixes the references to a field of a Vault secret, written as vault:PATH#FIELD.
+const VaultPrefix = "vault:"
+
+// ErrVaultNotConfigured is returned when resolving a Vault reference without Vault address.
+var ErrVaultNotConfigured = errors.New("vault is not configured (--vault-address)")
+
+// Resolver resolves the secret references.
+type Resolver struct {
+	vault  *Vault
+	config *cfg.SecretsConfig
+}
+
+// NewResolver returns a Resolver of the secret references. The Vault references are refused when the
+// Vault address is empty, the ${env:NAME} and ${file:PATH} references when not allowed by config.
+func NewResolver(vault *cfg.VaultConfig, config *cfg.SecretsConfig) *Resolver {
+	r := &Resolver{config: config}
+	if config == nil {
+		r.config = &cfg.SecretsConfig{}
+	}
+	if vault != nil && vault.Address != "" {
+		r.vault = NewVault(vault)
+	}
+	return r
+}
+
+// IsReference returns true if the value is, or embeds, a secret reference.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, VaultPrefix) || hasInterpolation(value)
+}
+
+// Validate checks the syntax of the secret references of a value. The other values are valid.
+func Validate(value string) error {
+	if !strings.HasPrefix(value, VaultPrefix) {
+		return validateInterpolation(value)
+	}
+	_, _, err := parseVaultReference(value)
+	return err
+}
+
+// Resolve returns the value with its secret references resolved, and the other values unchanged.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !strings.HasPrefix(value, VaultPrefix) {
+		return r.interpolate(value)
+	}
+	path, field, err := parseVaultReference(value)
+	if err != nil {
+		return "", err
+	}
+	if r.vault == nil {
+		return "", ErrVaultNotConfigured
+	}
+	return r.vault.Get(ctx, path, field)
+}
+
+// parseVaultReference returns the path and the field of a vault:PATH#FIELD reference.
+func parseVaultReference(value string) (path, field string, err error) {
+	path, field, ok := strings.Cut(strings.TrimPrefix(value, VaultPrefix), "#")
+	path = strings.Trim(path, "/")
+	if !ok || path == "" || field == "" {
+		return "", "", fmt.Errorf("invalid vault reference %q: must be written as vault:PATH#FIELD", value)
+	}
+	return path, field, nil
+}
@@ -0,0 +1,204 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVaultConfig(address string) *cfg.VaultConfig {
+	config := cfg.DefaultConfig().Vault
+	config.Address = address
+	config.Token = "root"
+	return config
+}
+
+func TestResolveVault(t *testing.T) {
+	var reads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "root", r.Header.Get("X-Vault-Token"))
+		if r.URL.Path != "/v1/kv/data/mcp" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		reads.Add(1)
+		_, _ = w.Write([]byte(`{"lease_id":"","lease_duration":0,"renewable":false,
+			"data":{"data":{"token":"s3cr3t"},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+
+	resolver := NewResolver(newTestVaultConfig(server.URL), nil)
+	ctx := context.Background()
+	for range 2 {
+		value, err := resolver.Resolve(ctx, "vault:kv/data/mcp#token")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", value)
+	}
+	assert.Equal(t, int32(1), reads.Load(), "the secret must be cached")
+
+	value, err := resolver.Resolve(ctx, "Bearer plain")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer plain", value)
+
+	_, err = resolver.Resolve(ctx, "vault:kv/data/mcp#missing")
+	assert.ErrorContains(t, err, `field "missing" not found`)
+	_, err = resolver.Resolve(ctx, "vault:kv/data/other#token")
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	_, err = NewResolver(cfg.DefaultConfig().Vault, nil).Resolve(ctx, "vault:kv/data/mcp#token")
+	assert.ErrorIs(t, err, ErrVaultNotConfigured)
+}
+
+// TestVaultLease expects the renewable leases to be renewed before they expire, and the secrets
+// to be read again once their lease cannot be renewed.
+func TestVaultLease(t *testing.T) {
+	var reads, renewals atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/database/creds/mcp":
+			reads.Add(1)
+			_, _ = w.Write([]byte(`{"lease_id":"database/creds/mcp/abc","lease_duration":60,"renewable":true,
+				"data":{"password":"p4ss"}}`))
+		case "/v1/sys/leases/renew":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "database/creds/mcp/abc", body["lease_id"])
+			if renewals.Add(1) > 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"errors":["lease not found"]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"lease_id":"database/creds/mcp/abc","lease_duration":60,"renewable":true}`))
+		}
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	vault := NewVault(newTestVaultConfig(server.URL))
+	vault.now = func() time.Time { return now }
+	ctx := context.Background()
+	get := func() {
+		t.Helper()
+		value, err := vault.Get(ctx, "database/creds/mcp", "password")
+		require.NoError(t, err)
+		assert.Equal(t, "p4ss", value)
+	}
+
+	get()
+	now = now.Add(30 * time.Second)
+	get()
+	assert.Equal(t, int32(0), renewals.Load(), "the lease must not be renewed before two thirds of its duration")
+
+	now = now.Add(15 * time.Second)
+	get()
+	assert.Equal(t, int32(1), renewals.Load())
+	assert.Equal(t, int32(1), reads.Load())
+
+	now = now.Add(45 * time.Second)
+	get()
+	assert.Equal(t, int32(2), renewals.Load())
+	assert.Equal(t, int32(2), reads.Load(), "the secret must be read again when its lease cannot be renewed")
+}
+
+func TestVaultKubernetesAuth(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("service-account-jwt\n"), 0o600))
+
+	var logins atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			logins.Add(1)
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]string{"role": "mcp-gateway", "jwt": "service-account-jwt"}, body)
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"login-token","lease_duration":3600}}`))
+		case "/v1/secret/mcp":
+			assert.Equal(t, "login-token", r.Header.Get("X-Vault-Token"))
+			assert.Equal(t, "team", r.Header.Get("X-Vault-Namespace"))
+			_, _ = w.Write([]byte(`{"data":{"token":"kv1"}}`))
+		}
+	}))
+	defer server.Close()
+
+	config := newTestVaultConfig(server.URL)
+	config.Token = ""
+	config.Namespace = "team"
+	config.AuthMethod = cfg.VaultAuthMethodKubernetes
+	config.KubernetesRole = "mcp-gateway"
+	config.KubernetesTokenPath = tokenPath
+	resolver := NewResolver(config, nil)
+
+	value, err := resolver.Resolve(context.Background(), "vault:secret/mcp#token")
+	require.NoError(t, err)
+	assert.Equal(t, "kv1", value)
+	assert.Equal(t, int32(1), logins.Load())
+}
+
+func TestValidate(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"Bearer token":            true,
+		"vault:kv/data/mcp#token": true,
+		"vault:kv/data/mcp":       false,
+		"vault:#token":            false,
+		"vault:kv/data/mcp#":      false,
+		"Bearer ${env:TOKEN}":     true,
+		"${env:1TOKEN}":           false,
+		"${file:/etc/token}":      true,
+		"${file:token}":           false,
+	} {
+		assert.Equal(t, valid, Validate(value) == nil, value)
+		assert.Equal(t, value != "Bearer token", IsReference(value), value)
+	}
+}
+
+func TestInterpolation(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("file-secret\n"), 0o600))
+	outside := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(outside, []byte("outside"), 0o600))
+	// The files of the Kubernetes secret volumes are links to a hidden directory of the volume.
+	require.NoError(t, os.Symlink(filepath.Join(dir, "token"), filepath.Join(dir, "link")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "escape")))
+	t.Setenv("MCP_TEST_TOKEN", "env-secret")
+	t.Setenv("MCP_GATEWAY_BACKEND_ENCRYPTION_KEY", "key")
+
+	resolver := NewResolver(nil, &cfg.SecretsConfig{AllowedEnv: []string{"MCP_TEST_*"}, AllowedDirs: []string{dir}})
+	ctx := context.Background()
+	for value, expected := range map[string]string{
+		"Bearer ${env:MCP_TEST_TOKEN}":                    "Bearer env-secret",
+		"${file:" + filepath.Join(dir, "token") + "}":     "file-secret",
+		"${file:" + filepath.Join(dir, "link") + "}":      "file-secret",
+		"${env:MCP_TEST_TOKEN}:${file:" + dir + "/token}": "env-secret:file-secret",
+		"${unknown:value}":                                "${unknown:value}",
+	} {
+		resolved, err := resolver.Resolve(ctx, value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, resolved, value)
+	}
+
+	for value, expected := range map[string]string{
+		"${env:MCP_GATEWAY_BACKEND_ENCRYPTION_KEY}":    "is not allowed (--secrets-allowed-env)",
+		"${env:MCP_TEST_UNSET}":                        "is not set",
+		"${file:" + outside + "}":                      "is not in an allowed directory (--secrets-allowed-dirs)",
+		"${file:" + filepath.Join(dir, "escape") + "}": "is not in an allowed directory (--secrets-allowed-dirs)",
+		"${file:" + dir + "/../token}":                 "no such file or directory",
+	} {
+		_, err := resolver.Resolve(ctx, value)
+		assert.ErrorContains(t, err, expected, value)
+	}
+
+	_, err := NewResolver(nil, nil).Resolve(ctx, "${env:MCP_TEST_TOKEN}")
+	assert.ErrorContains(t, err, "is not allowed")
+}
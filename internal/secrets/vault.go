@@ -0,0 +1,241 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+)
+
+const (
+	vaultTokenHeader     = "X-Vault-Token"
+	vaultNamespaceHeader = "X-Vault-Namespace"
+	maxErrorBodySize     = 1024
+	// renewFraction of a lease passes before the lease is renewed.
+	renewFraction = 2.0 / 3
+)
+
+// Vault reads the secrets of a Vault server, caching them for their lease or the cache TTL.
+// The renewable leases are renewed before they expire, the other secrets are read again.
+type Vault struct {
+	config *cfg.VaultConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu sync.Mutex
+	// token authenticates the requests, until tokenRenewAt for the tokens obtained by login.
+	token        string
+	tokenRenewAt time.Time
+	cache        map[string]*vaultSecret
+}
+
+type vaultSecret struct {
+	data      map[string]any
+	leaseID   string
+	renewable bool
+	renewAt   time.Time
+	expiry    time.Time
+}
+
+// vaultResponse is the response of the secret reads, lease renewals and logins.
+type vaultResponse struct {
+	LeaseID       string         `json:"lease_id"`
+	LeaseDuration int            `json:"lease_duration"`
+	Renewable     bool           `json:"renewable"`
+	Data          map[string]any `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+}
+
+// NewVault returns a client of the Vault server of the configuration.
+func NewVault(config *cfg.VaultConfig) *Vault {
+	return &Vault{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+		cache:  map[string]*vaultSecret{},
+	}
+}
+
+// Get returns the field of the secret at path. The fields of the KV version 2 secrets are read
+// from their data.
+func (v *Vault) Get(ctx context.Context, path, field string) (string, error) {
+	secret, err := v.secret(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("vault %s: %w", path, err)
+	}
+	value, ok := secret.data[field]
+	if !ok {
+		return "", fmt.Errorf("vault %s: field %q not found", path, field)
+	}
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	default:
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	}
+}
+
+// secret returns the cached secret at path, renewing its lease or reading it again when due.
+func (v *Vault) secret(ctx context.Context, path string) (*vaultSecret, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	cached, ok := v.cache[path]
+	if ok && now.Before(cached.renewAt) {
+		return cached, nil
+	}
+	if ok && cached.renewable && now.Before(cached.expiry) {
+		if err := v.renew(ctx, cached); err == nil {
+			return cached, nil
+		}
+		// The lease cannot be renewed anymore, the secret is read again.
+	}
+
+	var response vaultResponse
+	if err := v.do(ctx, http.MethodGet, "/v1/"+path, nil, &response); err != nil {
+		return nil, err
+	}
+	data := response.Data
+	// The KV version 2 secrets are wrapped in data, next to their metadata.
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	secret := &vaultSecret{data: data, leaseID: response.LeaseID, renewable: response.Renewable && response.LeaseID != ""}
+	v.setLease(secret, response.LeaseDuration)
+	v.cache[path] = secret
+	return secret, nil
+}
+
+// renew extends the lease of a secret.
+func (v *Vault) renew(ctx context.Context, secret *vaultSecret) error {
+	var response vaultResponse
+	body := map[string]any{"lease_id": secret.leaseID}
+	if err := v.do(ctx, http.MethodPut, "/v1/sys/leases/renew", body, &response); err != nil {
+		return err
+	}
+	secret.renewable = response.Renewable
+	v.setLease(secret, response.LeaseDuration)
+	return nil
+}
+
+// setLease sets when the secret is renewed and expires, after the cache TTL without lease.
+func (v *Vault) setLease(secret *vaultSecret, leaseDuration int) {
+	now := v.now()
+	if leaseDuration <= 0 || secret.leaseID == "" {
+		secret.renewable = false
+		secret.renewAt = now.Add(v.config.CacheTTL)
+		secret.expiry = secret.renewAt
+		return
+	}
+	lease := time.Duration(leaseDuration) * time.Second
+	secret.renewAt = now.Add(time.Duration(float64(lease) * renewFraction))
+	secret.expiry = now.Add(lease)
+}
+
+// authToken returns the token authenticating the requests, logging in with the kubernetes auth method when due.
+func (v *Vault) authToken(ctx context.Context) (string, error) {
+	if v.config.AuthMethod != cfg.VaultAuthMethodKubernetes {
+		return v.config.Token, nil
+	}
+	if v.token != "" && v.now().Before(v.tokenRenewAt) {
+		return v.token, nil
+	}
+
+	jwt, err := os.ReadFile(v.config.KubernetesTokenPath)
+	if err != nil {
+		return "", fmt.Errorf("read service account token: %w", err)
+	}
+	var response vaultResponse
+	body := map[string]any{"role": v.config.KubernetesRole, "jwt": strings.TrimSpace(string(jwt))}
+	path := "/v1/auth/" + strings.Trim(v.config.KubernetesMount, "/") + "/login"
+	if err := v.send(ctx, http.MethodPost, path, "", body, &response); err != nil {
+		return "", fmt.Errorf("login: %w", err)
+	}
+	if response.Auth == nil || response.Auth.ClientToken == "" {
+		return "", errors.New("login: no client token returned")
+	}
+	v.token = response.Auth.ClientToken
+	ttl := v.config.CacheTTL
+	if response.Auth.LeaseDuration > 0 {
+		ttl = time.Duration(float64(time.Duration(response.Auth.LeaseDuration)*time.Second) * renewFraction)
+	}
+	v.tokenRenewAt = v.now().Add(ttl)
+	return v.token, nil
+}
+
+// do sends an authenticated request. The token obtained by login is dropped when refused.
+func (v *Vault) do(ctx context.Context, method, path string, body, out any) error {
+	token, err := v.authToken(ctx)
+	if err != nil {
+		return err
+	}
+	err = v.send(ctx, method, path, token, body, out)
+	var statusErr *vaultStatusError
+	if errors.As(err, &statusErr) && statusErr.code == http.StatusForbidden {
+		v.token = ""
+	}
+	return err
+}
+
+// vaultStatusError is the error response of a Vault request.
+type vaultStatusError struct {
+	code    int
+	status  string
+	message []byte
+}
+
+func (e *vaultStatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.status, e.message)
+}
+
+// send sends a request to the Vault server and decodes its JSON response into out.
+func (v *Vault) send(ctx context.Context, method, path, token string, body, out any) error {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(v.config.Address, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set(vaultTokenHeader, token)
+	}
+	if v.config.Namespace != "" {
+		req.Header.Set(vaultNamespaceHeader, v.config.Namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return &vaultStatusError{code: resp.StatusCode, status: resp.Status, message: bytes.TrimSpace(errBody)}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
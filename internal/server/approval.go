@@ -0,0 +1,415 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// approvalPollInterval is how often a parked tool call checks whether another replica decided its approval.
+	approvalPollInterval = time.Second
+	// approvalNotifyTimeout bounds the notification of the approvers.
+	approvalNotifyTimeout = 10 * time.Second
+	// approvalCloseTimeout bounds the recording of an expired or cancelled approval.
+	approvalCloseTimeout = 5 * time.Second
+)
+
+// ApprovalDecisionRequest is the body of an approval decision.
+type ApprovalDecisionRequest struct {
+	// Approver is who decided, recorded in the approval.
+	Approver string `json:"approver"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// approvalWaiters wakes up the tool calls parked on this replica when their approval is decided here.
+type approvalWaiters struct {
+	mu      sync.Mutex
+	waiters map[string]chan struct{}
+}
+
+func newApprovalWaiters() *approvalWaiters {
+	return &approvalWaiters{waiters: make(map[string]chan struct{})}
+}
+
+// add returns a channel closed when the approval is decided, and a function to stop waiting.
+func (w *approvalWaiters) add(id string) (<-chan struct{}, func()) {
+	ch := make(chan struct{})
+	w.mu.Lock()
+	w.waiters[id] = ch
+	w.mu.Unlock()
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.waiters[id] == ch {
+			delete(w.waiters, id)
+		}
+	}
+}
+
+// notify wakes up the tool call waiting for the approval, if it is parked on this replica.
+func (w *approvalWaiters) notify(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if ch, ok := w.waiters[id]; ok {
+		close(ch)
+		delete(w.waiters, id)
+	}
+}
+
+// approvalHandler parks the calls of the tools whose policy requires an approval, and only forwards them upstream
+// once approved.
+func (s *Server) approvalHandler(proxyName, toolName string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.Storage == nil {
+			return next(ctx, request)
+		}
+		policies, err := s.Storage.ListToolPolicies(ctx)
+		if err != nil {
+			// Unlike the quotas, a sensitive tool must not be called because the storage failed
+			s.Logger.Error("Failed to list the tool policies", zap.Error(err))
+			return mcp.NewToolResultError("Unable to verify whether the tool call requires an approval"), nil
+		}
+		if !storage.RequiresApproval(policies, proxyName, toolName) {
+			return next(ctx, request)
+		}
+
+		approval, err := s.awaitApproval(ctx, proxyName, toolName, request)
+		if err != nil {
+			s.Logger.Error("Failed to request the approval of the tool call", zap.Error(err))
+			return mcp.NewToolResultError("Unable to request the approval of the tool call"), nil
+		}
+		switch approval.Status {
+		case storage.ApprovalStatusApproved:
+			return next(ctx, request)
+		case storage.ApprovalStatusDenied:
+			message := "Tool call denied by " + approval.DecidedBy
+			if approval.Reason != "" {
+				message += ": " + approval.Reason
+			}
+			return mcp.NewToolResultError(message), nil
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("Tool call not approved: the approval %s is %s", approval.ID, approval.Status)), nil
+		}
+	}
+}
+
+// awaitApproval records a pending approval for the tool call, notifies the approvers and waits for the decision,
+// made on this replica or another one, until the approval timeout.
+func (s *Server) awaitApproval(ctx context.Context, proxyName, toolName string, request mcp.CallToolRequest) (storage.ApprovalRequest, error) {
+	now := time.Now().UTC()
+	approval := storage.ApprovalRequest{
+		ID:          uuid.NewString(),
+		Proxy:       proxyName,
+		Tool:        toolName,
+		Identity:    identityFromContext(ctx),
+		Status:      storage.ApprovalStatusPending,
+		RequestedAt: now,
+		ExpiresAt:   now.Add(s.Config.Approval.Timeout),
+	}
+	if args := request.GetArguments(); len(args) > 0 {
+		arguments, err := json.Marshal(s.Redactor.Arguments(proxyName, args))
+		if err != nil {
+			return approval, err
+		}
+		approval.Arguments = arguments
+	}
+
+	decided, stop := s.approvals.add(approval.ID)
+	defer stop()
+	if err := s.Storage.CreateApproval(ctx, approval); err != nil {
+		return approval, err
+	}
+	s.Logger.Info("Tool call awaiting approval",
+		zap.String("approval", approval.ID),
+		zap.String("proxy", proxyName),
+		zap.String("tool", toolName),
+		zap.String("identity", approval.Identity))
+	s.publishApproval(approval)
+	go s.notifyApprovers(approval)
+
+	timeout := time.NewTimer(time.Until(approval.ExpiresAt))
+	defer timeout.Stop()
+	poll := time.NewTicker(approvalPollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case <-decided:
+			decided = nil
+		case <-poll.C:
+		case <-timeout.C:
+			return s.closeApproval(ctx, approval.ID, storage.ApprovalStatusExpired, "the approval timed out")
+		case <-ctx.Done():
+			return s.closeApproval(ctx, approval.ID, storage.ApprovalStatusCancelled, "the tool call was cancelled")
+		}
+		current, err := s.Storage.GetApproval(ctx, approval.ID)
+		if err != nil {
+			s.Logger.Warn("Failed to get the approval", zap.String("approval", approval.ID), zap.Error(err))
+			continue
+		}
+		if current.Status != storage.ApprovalStatusPending {
+			return current, nil
+		}
+	}
+}
+
+// closeApproval records that a pending approval expired or was cancelled, unless it was decided in the meantime.
+func (s *Server) closeApproval(ctx context.Context, id string, status storage.ApprovalStatus, reason string) (storage.ApprovalRequest, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), approvalCloseTimeout)
+	defer cancel()
+	approval, err := s.Storage.DecideApproval(ctx, id, status, "", reason, time.Now().UTC())
+	if errors.Is(err, storage.ErrApprovalDecided) {
+		return approval, nil
+	}
+	if err != nil {
+		return approval, err
+	}
+	s.approvalDecided(approval)
+	return approval, nil
+}
+
+// approvalDecided publishes and counts the decision of an approval.
+func (s *Server) approvalDecided(approval storage.ApprovalRequest) {
+	s.Logger.Info("Tool call approval decided",
+		zap.String("approval", approval.ID),
+		zap.String("status", string(approval.Status)),
+		zap.String("decided_by", approval.DecidedBy))
+	metrics.ApprovalsCounter.WithLabelValues(approval.Proxy, string(approval.Status)).Inc()
+	s.publishApproval(approval)
+}
+
+func (s *Server) publishApproval(approval storage.ApprovalRequest) {
+	s.publishAudited(events.TypeApproval, events.Approval{
+		ID:        approval.ID,
+		Proxy:     approval.Proxy,
+		Tool:      approval.Tool,
+		Identity:  approval.Identity,
+		Status:    string(approval.Status),
+		DecidedBy: approval.DecidedBy,
+	})
+}
+
+// notifyApprovers sends the approval request to the configured webhooks.
+func (s *Server) notifyApprovers(approval storage.ApprovalRequest) {
+	if s.Config.Approval.WebhookURL != "" {
+		s.postApprovalNotification("webhook", s.Config.Approval.WebhookURL, approval)
+	}
+	if s.Config.Approval.SlackWebhookURL != "" {
+		s.postApprovalNotification("slack", s.Config.Approval.SlackWebhookURL, map[string]string{
+			"text": fmt.Sprintf("Tool call `%s:%s` by %s awaits approval until %s.\nApprove or deny it with POST /v1/admin/approvals/%s/approve or /deny.",
+				approval.Proxy, approval.Tool, approval.Identity, approval.ExpiresAt.Format(time.RFC3339), approval.ID),
+		})
+	}
+}
+
+// postApprovalNotification posts the payload as JSON. The URL is not logged, a Slack webhook URL being a secret.
+func (s *Server) postApprovalNotification(notifier, url string, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		s.Logger.Error("Failed to encode the approval notification", zap.String("notifier", notifier), zap.Error(err))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), approvalNotifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		s.Logger.Error("Failed to create the approval notification", zap.String("notifier", notifier), zap.Error(err))
+		return
+	}
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.Logger.Warn("Failed to notify the approvers", zap.String("notifier", notifier), zap.Error(err))
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		s.Logger.Warn("Failed to notify the approvers", zap.String("notifier", notifier), zap.Int("status", resp.StatusCode))
+	}
+}
+
+// @Summary		Get all tool policies
+// @Description	Get the policies marking the tools whose calls must be approved
+// @Tags			approvals
+// @Accept			json
+// @Produce		json
+// @Security		Authentication
+// @Success		200	{array}		storage.ToolPolicyConfig
+// @Failure		500	{object}	map[string]string
+// @Router			/v1/admin/tool-policies [get]
+func (s *Server) getToolPolicies(c echo.Context) error {
+	policies, err := s.Storage.ListToolPolicies(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, policies)
+}
+
+// @Summary		Upsert a tool policy
+// @Description	Create or update the policy of a tool, or of the tools of a proxy with the '*' tool
+// @Tags			approvals
+// @Accept			json
+// @Produce		json
+// @Param			policy	body	storage.ToolPolicyConfig	true	"Tool policy"
+// @Success		200
+// @Failure		400	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/tool-policies [put]
+func (s *Server) upsertToolPolicy(c echo.Context) error {
+	policy := storage.ToolPolicyConfig{}
+	if err := c.Bind(&policy); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := policy.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := s.Storage.SetToolPolicy(c.Request().Context(), policy); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// @Summary		Delete a tool policy
+// @Description	Delete the policy of a tool
+// @Tags			approvals
+// @Accept			json
+// @Produce		json
+// @Param			proxy	path	string	true	"Proxy name"
+// @Param			tool	path	string	true	"Tool name"
+// @Success		200
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/tool-policies/{proxy}/{tool} [delete]
+func (s *Server) deleteToolPolicy(c echo.Context) error {
+	if err := s.Storage.DeleteToolPolicy(c.Request().Context(), c.Param("proxy"), c.Param("tool")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// @Summary		Get the approvals
+// @Description	Get the approvals of the tool calls, the latest first, pending or decided
+// @Tags			approvals
+// @Accept			json
+// @Produce		json
+// @Param			status	query		string	false	"Status of the approvals (pending, approved, denied, expired or cancelled), all when empty"
+// @Success		200		{array}		storage.ApprovalRequest
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/approvals [get]
+func (s *Server) getApprovals(c echo.Context) error {
+	status := storage.ApprovalStatus(c.QueryParam("status"))
+	if status != "" && !status.IsValid() {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid approval status: " + string(status)})
+	}
+	approvals, err := s.Storage.ListApprovals(c.Request().Context(), status)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, approvals)
+}
+
+// @Summary		Get an approval
+// @Description	Get the approval of a tool call, with its redacted arguments
+// @Tags			approvals
+// @Accept			json
+// @Produce		json
+// @Param			id	path		string	true	"Approval ID"
+// @Success		200	{object}	storage.ApprovalRequest
+// @Failure		404	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/approvals/{id} [get]
+func (s *Server) getApproval(c echo.Context) error {
+	approval, err := s.Storage.GetApproval(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, approval)
+}
+
+// @Summary		Approve a tool call
+// @Description	Approve a pending tool call, which is then forwarded upstream
+// @Tags			approvals
+// @Accept			json
+// @Produce		json
+// @Param			id			path		string					true	"Approval ID"
+// @Param			decision	body		ApprovalDecisionRequest	true	"Decision"
+// @Success		200			{object}	storage.ApprovalRequest
+// @Failure		400			{object}	map[string]string
+// @Failure		404			{object}	map[string]string
+// @Failure		409			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/approvals/{id}/approve [post]
+func (s *Server) approveToolCall(c echo.Context) error {
+	return s.decideApproval(c, storage.ApprovalStatusApproved)
+}
+
+// @Summary		Deny a tool call
+// @Description	Deny a pending tool call, which then fails with the reason
+// @Tags			approvals
+// @Accept			json
+// @Produce		json
+// @Param			id			path		string					true	"Approval ID"
+// @Param			decision	body		ApprovalDecisionRequest	true	"Decision"
+// @Success		200			{object}	storage.ApprovalRequest
+// @Failure		400			{object}	map[string]string
+// @Failure		404			{object}	map[string]string
+// @Failure		409			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/approvals/{id}/deny [post]
+func (s *Server) denyToolCall(c echo.Context) error {
+	return s.decideApproval(c, storage.ApprovalStatusDenied)
+}
+
+func (s *Server) decideApproval(c echo.Context, status storage.ApprovalStatus) error {
+	decision := ApprovalDecisionRequest{}
+	if err := c.Bind(&decision); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if decision.Approver == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "approver is required"})
+	}
+	ctx := c.Request().Context()
+	approval, err := s.Storage.GetApproval(ctx, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if approval.Status == storage.ApprovalStatusPending && time.Now().After(approval.ExpiresAt) {
+		// The replica parking the call went away before closing the approval
+		if approval, err = s.closeApproval(ctx, approval.ID, storage.ApprovalStatusExpired, "the approval timed out"); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+	if approval.Status != storage.ApprovalStatusPending {
+		return c.JSON(http.StatusConflict, map[string]string{"error": fmt.Sprintf("the approval is %s", approval.Status)})
+	}
+
+	approval, err = s.Storage.DecideApproval(ctx, approval.ID, status, decision.Approver, decision.Reason, time.Now().UTC())
+	if errors.Is(err, storage.ErrApprovalDecided) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": fmt.Sprintf("the approval is %s", approval.Status)})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	s.approvalDecided(approval)
+	s.approvals.notify(approval.ID)
+	return c.JSON(http.StatusOK, approval)
+}
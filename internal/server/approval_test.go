@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/redact"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createApprovalTestServer(t *testing.T, approval *cfg.ApprovalConfig) (*Server, storage.Interface) {
+	srv := createTestServer(false, &MockProvider{})
+	store := storage.NewMemoryStorage("")
+	srv.Storage = store
+	srv.Config.Approval = approval
+	srv.approvals = newApprovalWaiters()
+	redactor, err := redact.New(&cfg.RedactionConfig{Enabled: true, KeyPatterns: []string{"token"}})
+	require.NoError(t, err)
+	srv.Redactor = redactor
+	srv.ConfigureRoutes(srv.Router.Group("/v1"))
+	require.NoError(t, store.SetToolPolicy(context.Background(), storage.ToolPolicyConfig{Proxy: "github", Tool: "*", RequiresApproval: true}))
+	require.NoError(t, store.SetToolPolicy(context.Background(), storage.ToolPolicyConfig{Proxy: "github", Tool: "search"}))
+	return srv, store
+}
+
+// callParked calls the tool through the approval handler in the background, and returns the pending approval and
+// a channel receiving the result.
+func callParked(t *testing.T, srv *Server, store storage.Interface, tool string) (storage.ApprovalRequest, <-chan *mcp.CallToolResult) {
+	results := make(chan *mcp.CallToolResult, 1)
+	handler := srv.approvalHandler("github", tool, func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("called"), nil
+	})
+	go func() {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"repository": "gateway", "token": "s3cr3t"}
+		result, err := handler(context.Background(), request)
+		assert.NoError(t, err)
+		results <- result
+	}()
+
+	var pending []storage.ApprovalRequest
+	require.Eventually(t, func() bool {
+		pending, _ = store.ListApprovals(context.Background(), storage.ApprovalStatusPending)
+		return len(pending) == 1
+	}, time.Second, 5*time.Millisecond)
+	return pending[0], results
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+func TestApprovalHandler(t *testing.T) {
+	srv, store := createApprovalTestServer(t, &cfg.ApprovalConfig{Timeout: time.Minute})
+	decide := func(id, decision, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/approvals/"+id+"/"+decision, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("tools without approval are called", func(t *testing.T) {
+		handler := srv.approvalHandler("github", "search", func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("called"), nil
+		})
+		result, err := handler(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "called", resultText(result))
+	})
+
+	t.Run("approved calls are forwarded", func(t *testing.T) {
+		approval, results := callParked(t, srv, store, "delete_repository")
+		assert.Equal(t, "anonymous", approval.Identity)
+		assert.JSONEq(t, `{"repository":"gateway","token":"[REDACTED]"}`, string(approval.Arguments))
+
+		assert.Equal(t, http.StatusBadRequest, decide(approval.ID, "approve", `{}`).Code)
+		rec := decide(approval.ID, "approve", `{"approver":"bob"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		result := <-results
+		assert.False(t, result.IsError)
+		assert.Equal(t, "called", resultText(result))
+
+		assert.Equal(t, http.StatusConflict, decide(approval.ID, "deny", `{"approver":"carol"}`).Code)
+		assert.Equal(t, http.StatusNotFound, decide("missing", "deny", `{"approver":"carol"}`).Code)
+	})
+
+	t.Run("denied calls fail with the reason", func(t *testing.T) {
+		approval, results := callParked(t, srv, store, "delete_repository")
+		rec := decide(approval.ID, "deny", `{"approver":"bob","reason":"not during the freeze"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		result := <-results
+		assert.True(t, result.IsError)
+		assert.Equal(t, "Tool call denied by bob: not during the freeze", resultText(result))
+	})
+
+	t.Run("calls decided by another replica are noticed", func(t *testing.T) {
+		approval, results := callParked(t, srv, store, "delete_repository")
+		_, err := store.DecideApproval(context.Background(), approval.ID, storage.ApprovalStatusApproved, "bob", "", time.Now())
+		require.NoError(t, err)
+		select {
+		case result := <-results:
+			assert.False(t, result.IsError)
+		case <-time.After(3 * approvalPollInterval):
+			t.Fatal("the approval decided in the storage was not noticed")
+		}
+	})
+
+	t.Run("the approvals are listed by status", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/approvals?status=denied", nil)
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		var approvals []storage.ApprovalRequest
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &approvals))
+		require.Len(t, approvals, 1)
+		assert.Equal(t, "not during the freeze", approvals[0].Reason)
+
+		req = httptest.NewRequest(http.MethodGet, "/v1/admin/approvals?status=unknown", nil)
+		rec = httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestApprovalTimeout(t *testing.T) {
+	notifications := make(chan storage.ApprovalRequest, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var approval storage.ApprovalRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&approval))
+		notifications <- approval
+	}))
+	defer webhook.Close()
+	srv, store := createApprovalTestServer(t, &cfg.ApprovalConfig{Timeout: 100 * time.Millisecond, WebhookURL: webhook.URL})
+
+	approval, results := callParked(t, srv, store, "delete_repository")
+	result := <-results
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(result), "is expired")
+
+	notified := <-notifications
+	assert.Equal(t, approval.ID, notified.ID)
+	assert.Equal(t, "delete_repository", notified.Tool)
+
+	approval, err := store.GetApproval(context.Background(), approval.ID)
+	require.NoError(t, err)
+	assert.Equal(t, storage.ApprovalStatusExpired, approval.Status)
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// appendAuditEntryTimeout bounds the append of an event to the audit log.
+	appendAuditEntryTimeout = 5 * time.Second
+	defaultAuditLimit       = 100
+	maxAuditLimit           = 1000
+	// auditVerifyBatchSize is the number of audit entries read at once by the verification.
+	auditVerifyBatchSize = 1000
+)
+
+// readOnlyAdminRoutes are the admin routes which do not change the gateway despite their method.
+var readOnlyAdminRoutes = map[string]bool{
+	"/v1/admin/authz/check": true,
+	// The route path keeps the escape of the colon, which echo would take for a parameter.
+	`/v1/admin/proxies\:discover`:        true,
+	`/v1/admin/attribute-to-roles\:plan`: true,
+}
+
+// adminMutationMiddleware publishes an admin mutation event for each successful admin API request changing
+// the gateway.
+func (s *Server) adminMutationMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+		if err != nil || c.Response().Status >= http.StatusBadRequest || !isAdminMutation(c) {
+			return err
+		}
+		s.publishAudited(events.TypeAdminMutation, events.AdminMutation{
+			API:       "http",
+			Operation: c.Request().Method + " " + c.Path(),
+			Path:      c.Request().URL.Path,
+			ClientIP:  c.RealIP(),
+		})
+		return nil
+	}
+}
+
+func isAdminMutation(c echo.Context) bool {
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	if readOnlyAdminRoutes[c.Path()] {
+		return false
+	}
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dryRun"))
+	return !dryRun
+}
+
+// publishGRPCMutation publishes an admin mutation event for a successful gRPC call changing the gateway.
+func (s *Server) publishGRPCMutation(ctx context.Context, fullMethod string) {
+	method := path.Base(fullMethod)
+	if !strings.HasPrefix(method, "Upsert") && !strings.HasPrefix(method, "Delete") {
+		return
+	}
+	mutation := events.AdminMutation{API: "grpc", Operation: fullMethod}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			mutation.ClientIP = host
+		}
+	}
+	s.publishAudited(events.TypeAdminMutation, mutation)
+}
+
+// configureAuditLog enables the chaining of the audited events to the tamper-evident audit log.
+func (s *Server) configureAuditLog() {
+	if !s.Config.AuditLog.Enabled {
+		return
+	}
+	if s.Config.BackendConfig.Engine == "memory" {
+		s.Logger.Warn("The audit log is kept in memory and lost on restart, use a persistent backend.")
+	}
+	s.auditLog = true
+}
+
+// publishAudited publishes the event, and chains it to the audit log if enabled.
+func (s *Server) publishAudited(eventType events.Type, data any) {
+	s.eventBroker.Publish(eventType, data)
+	if !s.auditLog || s.Storage == nil {
+		return
+	}
+	recordedAt := time.Now()
+	body, err := json.Marshal(data)
+	if err != nil {
+		s.Logger.Error("Failed to encode the audit entry", zap.String("type", string(eventType)), zap.Error(err))
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), appendAuditEntryTimeout)
+		defer cancel()
+		if _, err := s.Storage.AppendAuditEntry(ctx, string(eventType), body, recordedAt); err != nil {
+			s.Logger.Error("Failed to append the audit entry", zap.String("type", string(eventType)), zap.Error(err))
+		}
+	}()
+}
+
+// @Summary		Get the audit log
+// @Description	Get the entries of the tamper-evident audit log following a sequence number, in order
+// @Tags			audit
+// @Accept			json
+// @Produce		json
+// @Param			after	query		int	false	"Sequence number of the last entry already read"	default(0)
+// @Param			limit	query		int	false	"Maximum number of entries"						default(100)
+// @Success		200		{array}		storage.AuditEntry
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/audit [get]
+func (s *Server) getAuditEntries(c echo.Context) error {
+	var after int64
+	if raw := c.QueryParam("after"); raw != "" {
+		var err error
+		if after, err = strconv.ParseInt(raw, 10, 64); err != nil || after < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "after must be a sequence number, 0 or more"})
+		}
+	}
+	limit := defaultAuditLimit
+	if raw := c.QueryParam("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("limit must be between 1 and %d", maxAuditLimit)})
+		}
+	}
+
+	entries, err := s.Storage.ListAuditEntries(c.Request().Context(), after, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, entries)
+}
+
+// @Summary		Verify the audit log
+// @Description	Check that every entry of the audit log chains to the previous one and matches its hash, proving the log was not altered
+// @Tags			audit
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	storage.AuditVerification
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/audit/verify [get]
+func (s *Server) verifyAuditLog(c echo.Context) error {
+	verification, err := storage.VerifyAuditLog(c.Request().Context(), s.Storage, auditVerifyBatchSize)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, verification)
+}
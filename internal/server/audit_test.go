@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	adminv1 "github.com/matthisholleville/mcp-gateway/pkg/api/admin/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAdminMutationEvents(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config.HTTP = cfg.DefaultConfig().HTTP
+	srv.Config.HTTP.AdminAPIKey = "admin"
+	srv.Config.GRPC = &cfg.GRPCConfig{Enabled: true}
+	srv.Config.Debug = &cfg.DebugConfig{}
+	srv.Storage = storage.NewMemoryStorage("")
+	srv.eventBroker = events.NewBroker(10)
+	srv.configureV1Routes()
+	ch, unsubscribe := srv.eventBroker.Subscribe()
+	defer unsubscribe()
+
+	request := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-API-Key", "admin")
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	role := `{"name":"reader","permissions":[{"object_type":"tools","proxy":"*","object_name":"*"}]}`
+	require.Equal(t, http.StatusOK, request(http.MethodPut, "/v1/admin/roles", role))
+	// Neither the reads, the failures (the role exists), the authorization checks, the plans nor the dry runs change
+	// the gateway.
+	require.Equal(t, http.StatusOK, request(http.MethodGet, "/v1/admin/roles", ""))
+	require.NotEqual(t, http.StatusOK, request(http.MethodPut, "/v1/admin/roles", role))
+	request(http.MethodPost, "/v1/admin/authz/check", `{"proxy":"github","tool":"search","claims":{}}`)
+	require.Equal(t, http.StatusOK, request(http.MethodPost, "/v1/admin/attribute-to-roles:plan", `[]`))
+	require.Equal(t, http.StatusOK, request(http.MethodPost, "/v1/admin/import?dryRun=true", `{}`))
+
+	event := <-ch
+	assert.Equal(t, events.TypeAdminMutation, event.Type)
+	assert.Equal(t, events.AdminMutation{
+		API:       "http",
+		Operation: "PUT /v1/admin/roles",
+		Path:      "/v1/admin/roles",
+		ClientIP:  "192.0.2.1",
+	}, event.Data)
+	assert.Empty(t, ch)
+
+	client := newTestGRPCClient(t, srv)
+	ctx := metadata.AppendToOutgoingContext(t.Context(), "x-api-key", "admin")
+	_, err := client.ListRoles(ctx, &adminv1.ListRolesRequest{})
+	require.NoError(t, err)
+	_, err = client.DeleteRole(ctx, &adminv1.DeleteRoleRequest{Name: "reader"})
+	require.NoError(t, err)
+
+	event = <-ch
+	assert.Equal(t, events.AdminMutation{
+		API:       "grpc",
+		Operation: adminv1.AdminService_DeleteRole_FullMethodName,
+	}, event.Data)
+	assert.Empty(t, ch)
+}
+
+func TestAuditLog(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.ConfigureRoutes(srv.Router.Group("/v1"))
+	store := storage.NewMemoryStorage("")
+	srv.Storage = store
+	get := func(path string, v any) int {
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+		}
+		return rec.Code
+	}
+
+	// The events are only chained to the audit log when it is enabled
+	srv.publishAudited(events.TypeToolCall, events.ToolCall{Proxy: "github", Tool: "search", Identity: "alice"})
+	srv.auditLog = true
+	srv.publishAudited(events.TypeToolCall, events.ToolCall{Proxy: "github", Tool: "search", Identity: "alice"})
+	srv.publishAudited(events.TypeAdminMutation, events.AdminMutation{API: "http", Operation: "DELETE /v1/admin/roles/:name"})
+	require.Eventually(t, func() bool {
+		entries, err := store.ListAuditEntries(context.Background(), 0, 10)
+		return err == nil && len(entries) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	var entries []storage.AuditEntry
+	require.Equal(t, http.StatusOK, get("/v1/admin/audit?after=1", &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(2), entries[0].Seq)
+	assert.Equal(t, http.StatusBadRequest, get("/v1/admin/audit?limit=0", &entries))
+	assert.Equal(t, http.StatusBadRequest, get("/v1/admin/audit?after=-1", &entries))
+
+	var verification storage.AuditVerification
+	require.Equal(t, http.StatusOK, get("/v1/admin/audit/verify", &verification))
+	assert.True(t, verification.Valid)
+	assert.Equal(t, int64(2), verification.Entries)
+	assert.Equal(t, entries[0].Hash, verification.LastHash)
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/auth"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// authHealthCheckTimeout bounds a health check of the auth provider.
+const authHealthCheckTimeout = 10 * time.Second
+
+// configureAuthHealthCheck checks at each interval that the auth provider can verify the tokens. Until a check
+// succeeds, and while the checks fail, the gateway is not ready: a broken provider rejects every tool call.
+func (s *Server) configureAuthHealthCheck(checker auth.HealthChecker) {
+	interval := s.Config.AuthProvider.HealthCheckInterval
+	if interval <= 0 {
+		return
+	}
+	s.authHealthChecks = true
+	go func() {
+		for {
+			s.checkAuthProvider(context.Background(), checker)
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// checkAuthProvider checks the auth provider once and records the result in the readiness and the metrics.
+func (s *Server) checkAuthProvider(ctx context.Context, checker auth.HealthChecker) {
+	ctx, cancel := context.WithTimeout(ctx, authHealthCheckTimeout)
+	defer cancel()
+	err := checker.CheckHealth(ctx)
+	healthy := err == nil
+	gauge := metrics.AuthProviderHealthyGauge.WithLabelValues(s.Config.AuthProvider.Name)
+	if !healthy {
+		gauge.Set(0)
+		s.authProviderHealthy.Store(false)
+		s.Logger.Error("The auth provider health check failed, the gateway is not ready",
+			zap.String("provider", s.Config.AuthProvider.Name), zap.Error(err))
+		return
+	}
+	gauge.Set(1)
+	if !s.authProviderHealthy.Swap(true) {
+		s.Logger.Info("The auth provider is healthy", zap.String("provider", s.Config.AuthProvider.Name))
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// healthChecker is an auth provider health check returning err.
+type healthChecker struct {
+	err error
+}
+
+func (c *healthChecker) CheckHealth(context.Context) error {
+	return c.err
+}
+
+func TestAuthProviderHealthCheck(t *testing.T) {
+	defer metrics.AuthProviderHealthyGauge.Reset()
+	ready := func(srv *Server) int {
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return rec.Code
+	}
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config = cfg.DefaultConfig()
+	srv.Config.Proxy.ReadyAfterSync = false
+	srv.Config.AuthProvider.Name = "okta"
+	srv.registerHealthcheckRoutes()
+	srv.authHealthChecks = true
+	assert.Equal(t, http.StatusServiceUnavailable, ready(srv), "the provider was not checked yet")
+
+	checker := &healthChecker{}
+	srv.checkAuthProvider(t.Context(), checker)
+	assert.Equal(t, http.StatusOK, ready(srv))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AuthProviderHealthyGauge.WithLabelValues("okta")))
+
+	checker.err = errors.New("fetching the JWKS: connection refused")
+	srv.checkAuthProvider(t.Context(), checker)
+	assert.Equal(t, http.StatusServiceUnavailable, ready(srv))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.AuthProviderHealthyGauge.WithLabelValues("okta")))
+
+	// Without health checks, the readiness does not depend on the provider
+	srv = createTestServer(false, &MockProvider{})
+	srv.Config = cfg.DefaultConfig()
+	srv.Config.Proxy.ReadyAfterSync = false
+	srv.Config.AuthProvider.HealthCheckInterval = 0
+	srv.registerHealthcheckRoutes()
+	srv.configureAuthHealthCheck(checker)
+	assert.Equal(t, http.StatusOK, ready(srv))
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	// authMetadataTTL is how long the metadata of the authorization server is cached.
+	authMetadataTTL = 5 * time.Minute
+	// authMetadataTimeout bounds the requests to the authorization server.
+	authMetadataTimeout = 10 * time.Second
+	// maxAuthMetadataSize bounds the metadata documents.
+	maxAuthMetadataSize = 1 << 20 // 1 MiB
+)
+
+// authMetadata serves the metadata documents of the authorization server, fetched on demand and cached.
+type authMetadata struct {
+	issuer     string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	documents map[string]cachedAuthMetadata
+}
+
+type cachedAuthMetadata struct {
+	body      []byte
+	fetchedAt time.Time
+}
+
+// withAuthorizationServerMetadata serves the metadata of the first authorization server on the gateway, for the
+// clients which only look for it on the host of the resource.
+func (s *Server) withAuthorizationServerMetadata() {
+	if !s.Config.OAuth.Enabled || !s.Config.OAuth.ProxyAuthorizationServerMetadata || len(s.Config.OAuth.AuthorizationServers) == 0 {
+		return
+	}
+	metadata := &authMetadata{
+		issuer:     s.Config.OAuth.AuthorizationServers[0],
+		httpClient: &http.Client{Timeout: authMetadataTimeout},
+		now:        time.Now,
+		documents:  make(map[string]cachedAuthMetadata),
+	}
+	for _, document := range []string{"oauth-authorization-server", "openid-configuration"} {
+		handler := func(c echo.Context) error {
+			body, err := metadata.get(c.Request().Context(), document)
+			if err != nil {
+				s.Logger.Warn("Failed to fetch the authorization server metadata",
+					zap.String("document", document), zap.String("issuer", metadata.issuer), zap.Error(err))
+				return echo.NewHTTPError(http.StatusBadGateway, "authorization server metadata unavailable")
+			}
+			return c.JSONBlob(http.StatusOK, body)
+		}
+		s.Router.GET("/.well-known/"+document, handler)
+		s.Router.HEAD("/.well-known/"+document, handler)
+	}
+}
+
+// get returns a metadata document, from the cache if it is fresh. The stale document is returned if the
+// authorization server fails.
+func (m *authMetadata) get(ctx context.Context, document string) ([]byte, error) {
+	m.mu.Lock()
+	cached, ok := m.documents[document]
+	m.mu.Unlock()
+	if ok && m.now().Sub(cached.fetchedAt) < authMetadataTTL {
+		return cached.body, nil
+	}
+
+	body, err := m.fetch(ctx, document)
+	if err != nil {
+		if ok {
+			return cached.body, nil
+		}
+		return nil, err
+	}
+	m.mu.Lock()
+	m.documents[document] = cachedAuthMetadata{body: body, fetchedAt: m.now()}
+	m.mu.Unlock()
+	return body, nil
+}
+
+// fetch fetches a metadata document from the first of its candidate URLs serving it.
+func (m *authMetadata) fetch(ctx context.Context, document string) ([]byte, error) {
+	urls, err := metadataURLs(m.issuer, document)
+	if err != nil {
+		return nil, err
+	}
+	var errs []string
+	for _, u := range urls {
+		body, err := m.getDocument(ctx, u)
+		if err == nil {
+			return body, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+func (m *authMetadata) getDocument(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: status %d", u, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAuthMetadataSize))
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", u, err)
+	}
+	return body, nil
+}
+
+// metadataURLs returns the URLs of a metadata document of an issuer: the well-known path inserted between the host
+// and the path of the issuer (RFC 8414), and appended to the issuer (OIDC discovery), in the order of the
+// specification of the document.
+func metadataURLs(issuer, document string) ([]string, error) {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid authorization server %q", issuer)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	inserted := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/.well-known/" + document + path}).String()
+	if path == "" {
+		return []string{inserted}, nil
+	}
+	appended := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: path + "/.well-known/" + document}).String()
+	if document == "openid-configuration" {
+		return []string{appended, inserted}, nil
+	}
+	return []string{inserted, appended}, nil
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAuthorizationServerMetadata(t *testing.T) {
+	var fetches atomic.Int32
+	mux := http.NewServeMux()
+	// Okta serves the metadata of its custom authorization servers at the path of the issuer.
+	mux.HandleFunc("/oauth2/default/.well-known/oauth-authorization-server", func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte(`{"issuer":"https://example.okta.com/oauth2/default","token_endpoint":"https://example.okta.com/oauth2/default/v1/token"}`))
+	})
+	authServer := httptest.NewServer(mux)
+	defer authServer.Close()
+
+	s := createTestServer(true, &MockProvider{})
+	s.Config.OAuth.AuthorizationServers = []string{authServer.URL + "/oauth2/default"}
+	s.Config.OAuth.ProxyAuthorizationServerMetadata = true
+	s.withAuthorizationServerMetadata()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		return rec
+	}
+
+	rec := get("/.well-known/oauth-authorization-server")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"issuer":"https://example.okta.com/oauth2/default","token_endpoint":"https://example.okta.com/oauth2/default/v1/token"}`,
+		rec.Body.String())
+	assert.Equal(t, int32(1), fetches.Load())
+
+	assert.Equal(t, http.StatusOK, get("/.well-known/oauth-authorization-server").Code)
+	assert.Equal(t, int32(1), fetches.Load(), "the metadata is cached")
+
+	assert.Equal(t, http.StatusBadGateway, get("/.well-known/openid-configuration").Code, "the document is not served by the authorization server")
+}
+
+func TestAuthMetadata_Stale(t *testing.T) {
+	failing := atomic.Bool{}
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"issuer":"https://auth.example.com"}`))
+	}))
+	defer authServer.Close()
+	now := time.Now()
+	metadata := &authMetadata{
+		issuer:     authServer.URL,
+		httpClient: authServer.Client(),
+		now:        func() time.Time { return now },
+		documents:  make(map[string]cachedAuthMetadata),
+	}
+
+	body, err := metadata.get(t.Context(), "openid-configuration")
+	require.NoError(t, err)
+	failing.Store(true)
+	now = now.Add(authMetadataTTL)
+	stale, err := metadata.get(t.Context(), "openid-configuration")
+	require.NoError(t, err)
+	assert.Equal(t, body, stale, "the stale metadata is served when the authorization server fails")
+
+	_, err = metadata.get(t.Context(), "oauth-authorization-server")
+	assert.ErrorContains(t, err, "status 503")
+}
+
+func TestMetadataURLs(t *testing.T) {
+	urls, err := metadataURLs("https://example.okta.com/oauth2/default/", "oauth-authorization-server")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://example.okta.com/.well-known/oauth-authorization-server/oauth2/default",
+		"https://example.okta.com/oauth2/default/.well-known/oauth-authorization-server",
+	}, urls)
+
+	urls, err = metadataURLs("https://example.okta.com/oauth2/default", "openid-configuration")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://example.okta.com/oauth2/default/.well-known/openid-configuration",
+		"https://example.okta.com/.well-known/openid-configuration/oauth2/default",
+	}, urls)
+
+	urls, err = metadataURLs("https://auth.example.com", "openid-configuration")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://auth.example.com/.well-known/openid-configuration"}, urls)
+
+	_, err = metadataURLs("auth.example.com", "openid-configuration")
+	assert.Error(t, err)
+}
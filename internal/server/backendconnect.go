@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"go.uber.org/zap"
+)
+
+// backendPingTimeout bounds a check of the backend connectivity.
+const backendPingTimeout = 5 * time.Second
+
+// backendPinger is implemented by the storages connecting to a database.
+type backendPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// connectBackend checks that the backend is reachable at startup. If it is not, the gateway starts but is not
+// ready, and the connection is retried in the background with an exponential backoff, so the gateway survives a
+// restart of the database. It exits once the retries are exhausted, or at once without retries.
+func (s *Server) connectBackend(pinger backendPinger) {
+	err := pingBackend(pinger)
+	if err == nil {
+		return
+	}
+	backend := s.Config.BackendConfig
+	if backend.ConnectRetries == 0 {
+		s.Logger.Error("Failed to connect to the backend", zap.Error(err))
+		panic(err)
+	}
+	s.backendUnavailable.Store(true)
+	s.Logger.Warn("The backend is unreachable, the gateway is not ready until it connects",
+		zap.Int("retries", backend.ConnectRetries), zap.Error(err))
+	go func() {
+		if err := s.retryBackend(pinger, backend.ConnectRetries, backend.ConnectBackoff, time.Sleep); err != nil {
+			s.Logger.Error("Failed to connect to the backend", zap.Error(err))
+			panic(err)
+		}
+	}()
+}
+
+// retryBackend retries to connect to the backend, sleeping between the attempts a backoff doubled at each retry.
+// The gateway is ready once connected.
+func (s *Server) retryBackend(pinger backendPinger, retries int, backoff time.Duration, sleep func(time.Duration)) error {
+	var err error
+	for attempt := 1; attempt <= retries; attempt++ {
+		sleep(backoff)
+		if err = pingBackend(pinger); err == nil {
+			s.backendUnavailable.Store(false)
+			s.Logger.Info("Connected to the backend", zap.Int("attempt", attempt))
+			return nil
+		}
+		s.Logger.Warn("The backend is still unreachable",
+			zap.Int("attempt", attempt), zap.Int("retries", retries), zap.Error(err))
+		backoff = min(2*backoff, cfg.MaxConnectBackoff)
+	}
+	return fmt.Errorf("the backend is unreachable after %d retries: %w", retries, err)
+}
+
+func pingBackend(pinger backendPinger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), backendPingTimeout)
+	defer cancel()
+	return pinger.Ping(ctx)
+}
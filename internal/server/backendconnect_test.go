@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyPinger fails its first pings, as many as failures.
+type flakyPinger struct {
+	failures int
+	pings    int
+}
+
+func (p *flakyPinger) Ping(context.Context) error {
+	p.pings++
+	if p.pings <= p.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestConnectBackend(t *testing.T) {
+	ready := func(srv *Server) int {
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return rec.Code
+	}
+	newServer := func() *Server {
+		srv := createTestServer(false, &MockProvider{})
+		srv.Config = cfg.DefaultConfig()
+		srv.Config.Proxy.ReadyAfterSync = false
+		srv.registerHealthcheckRoutes()
+		return srv
+	}
+
+	srv := newServer()
+	srv.connectBackend(&flakyPinger{})
+	assert.Equal(t, http.StatusOK, ready(srv))
+
+	srv = newServer()
+	srv.Config.BackendConfig.ConnectRetries = 0
+	assert.Panics(t, func() { srv.connectBackend(&flakyPinger{failures: 1}) }, "the gateway exits without retries")
+
+	// The gateway is not ready until a retry connects, the backoff doubling up to its maximum
+	srv = newServer()
+	srv.backendUnavailable.Store(true)
+	assert.Equal(t, http.StatusServiceUnavailable, ready(srv))
+	var delays []time.Duration
+	sleep := func(d time.Duration) { delays = append(delays, d) }
+	require.NoError(t, srv.retryBackend(&flakyPinger{failures: 3}, 5, 10*time.Second, sleep))
+	assert.Equal(t, []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second}, delays)
+	assert.Equal(t, http.StatusOK, ready(srv))
+
+	srv = newServer()
+	srv.backendUnavailable.Store(true)
+	delays = nil
+	err := srv.retryBackend(&flakyPinger{failures: 10}, 2, time.Second, sleep)
+	assert.ErrorContains(t, err, "unreachable after 2 retries: connection refused")
+	assert.Len(t, delays, 2)
+	assert.Equal(t, http.StatusServiceUnavailable, ready(srv))
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var errEmptyBatch = errors.New("empty JSON-RPC batch")
+
+// isBatch returns true if the body is a JSON-RPC batch, i.e. a JSON array.
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// batchHandler adds the JSON-RPC batch support to the MCP handler, which only handles single messages.
+// Each entry of the batch is dispatched to the handler and the responses are returned as a JSON array.
+func batchHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeBatchError(w, mcp.PARSE_ERROR, "failed to read the request body")
+			return
+		}
+		if !isBatch(body) {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var messages []json.RawMessage
+		if err := json.Unmarshal(body, &messages); err != nil {
+			writeBatchError(w, mcp.PARSE_ERROR, "invalid JSON-RPC batch")
+			return
+		}
+		if len(messages) == 0 {
+			writeBatchError(w, mcp.INVALID_REQUEST, errEmptyBatch.Error())
+			return
+		}
+
+		responses := make([]json.RawMessage, 0, len(messages))
+		for _, message := range messages {
+			entry := r.Clone(r.Context())
+			entry.Body = io.NopCloser(bytes.NewReader(message))
+			entry.ContentLength = int64(len(message))
+
+			rec := newBatchRecorder()
+			next.ServeHTTP(rec, entry)
+			if response := rec.response(); response != nil {
+				responses = append(responses, response)
+			} else if cause := context.Cause(r.Context()); cause != nil {
+				// The entry was aborted before being answered.
+				for _, aborted := range abortedErrors(message, cause) {
+					data, _ := json.Marshal(aborted)
+					responses = append(responses, data)
+				}
+			}
+		}
+
+		// A batch made only of notifications has no response.
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(responses)
+	})
+}
+
+// writeBatchError writes a JSON-RPC error that can not be attached to a request.
+func writeBatchError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(mcp.NewJSONRPCError(mcp.NewRequestId(nil), code, message, nil))
+}
+
+// batchRecorder records the response of a single batch entry.
+type batchRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func newBatchRecorder() *batchRecorder {
+	return &batchRecorder{header: make(http.Header), code: http.StatusOK}
+}
+
+func (r *batchRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *batchRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *batchRecorder) WriteHeader(code int) {
+	r.code = code
+}
+
+func (r *batchRecorder) Flush() {}
+
+// response returns the JSON-RPC response of the entry, or nil for a notification.
+func (r *batchRecorder) response() json.RawMessage {
+	body := bytes.TrimSpace(r.body.Bytes())
+	if len(body) == 0 {
+		return nil
+	}
+	if !strings.HasPrefix(r.header.Get("Content-Type"), "text/event-stream") {
+		return body
+	}
+	// Streamed responses are sent as server-sent events: the response is the last data line.
+	var response json.RawMessage
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20) //nolint:mnd // 1 MiB, the maximum request size
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data:"); ok {
+			response = json.RawMessage(strings.TrimSpace(data))
+		}
+	}
+	return response
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchHandler(t *testing.T) {
+	// The handler echoes the request id, as a JSON response or as a server-sent event.
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &message)
+		if message.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		response, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": message.ID, "result": map[string]any{}})
+		if message.Method == "tools/call" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("event: message\ndata: " + string(response) + "\n\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(response)
+	})
+
+	for _, test := range []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "single message",
+			body:         `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":1,"jsonrpc":"2.0","result":{}}`,
+		},
+		{
+			name: "batch",
+			body: `[{"jsonrpc":"2.0","id":1,"method":"tools/list"},{"jsonrpc":"2.0","method":"notifications/initialized"},` +
+				`{"jsonrpc":"2.0","id":2,"method":"tools/call"}]`,
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"id":1,"jsonrpc":"2.0","result":{}},{"id":2,"jsonrpc":"2.0","result":{}}]`,
+		},
+		{
+			name:         "notifications only",
+			body:         `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`,
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:         "empty batch",
+			body:         `[]`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"empty JSON-RPC batch"}}`,
+		},
+		{
+			name:         "invalid batch",
+			body:         `[{"jsonrpc"`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"invalid JSON-RPC batch"}}`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(test.body))
+			rec := httptest.NewRecorder()
+
+			batchHandler(next).ServeHTTP(rec, req)
+			assert.Equal(t, test.expectedCode, rec.Code)
+			if test.expectedBody == "" {
+				assert.Empty(t, rec.Body.String())
+				return
+			}
+			require.JSONEq(t, test.expectedBody, rec.Body.String())
+		})
+	}
+}
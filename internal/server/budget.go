@@ -0,0 +1,360 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
+)
+
+// budgetRemainingHeader surfaces the cost left in the budgets charged by a request.
+const budgetRemainingHeader = "X-MCP-Gateway-Budget-Remaining"
+
+// listBudgets returns the budgets and the tool costs, false if there is no budget to enforce. Like the quotas, the
+// budgets are not enforced if the storage fails.
+func (s *Server) listBudgets(ctx context.Context) ([]storage.BudgetConfig, []storage.ToolCostConfig, bool) {
+	if s.Storage == nil {
+		return nil, nil, false
+	}
+	budgets, err := s.Storage.ListBudgets(ctx)
+	if err != nil {
+		s.Logger.Error("Failed to list the budgets, they are not enforced", zap.Error(err))
+		return nil, nil, false
+	}
+	if len(budgets) == 0 {
+		return nil, nil, false
+	}
+	costs, err := s.Storage.ListToolCosts(ctx)
+	if err != nil {
+		s.Logger.Error("Failed to list the tool costs, the budgets are not enforced", zap.Error(err))
+		return nil, nil, false
+	}
+	return budgets, costs, true
+}
+
+// checkBudgets returns the first deny budget which the cost of the tool calls would exceed and its usage, if any,
+// and the usage of the budgets once the calls are charged, without charging them: budgetHandler charges each call
+// once it is forwarded upstream, so the calls rejected on the way, e.g. by the screening, the guardrail or the
+// approval, cost nothing.
+func (s *Server) checkBudgets(ctx context.Context, calls []quotaCall) (storage.BudgetConfig, *storage.BudgetUsage, []storage.BudgetUsage) {
+	if len(calls) == 0 {
+		return storage.BudgetConfig{}, nil, nil
+	}
+	budgets, costs, ok := s.listBudgets(ctx)
+	if !ok {
+		return storage.BudgetConfig{}, nil, nil
+	}
+
+	now := time.Now()
+	var usages []storage.BudgetUsage
+	for _, call := range calls {
+		cost := storage.ToolCost(costs, call.proxy, call.tool)
+		for _, budget := range budgets {
+			if budget.Role != call.role {
+				continue
+			}
+			usage, ok := findBudgetUsage(usages, budget.Name)
+			if !ok {
+				var err error
+				if usage, err = s.Storage.GetBudgetUsage(ctx, budget, now); err != nil {
+					s.Logger.Error("Failed to get the usage of the budget", zap.String("budget", budget.Name), zap.Error(err))
+					continue
+				}
+			}
+			if usage.Consumed+cost > budget.Limit && budget.Action == storage.BudgetActionDeny {
+				metrics.BudgetExhaustedCounter.WithLabelValues(budget.Name, string(budget.Action)).Inc()
+				s.Logger.Info("Budget exhausted",
+					zap.String("budget", budget.Name),
+					zap.String("role", budget.Role),
+					zap.String("proxy", call.proxy),
+					zap.String("tool", call.tool))
+				return budget, &usage, usages
+			}
+			usage.Consumed += cost
+			usage.Remaining = max(budget.Limit-usage.Consumed, 0)
+			usages = setBudgetUsage(usages, usage)
+		}
+	}
+	return storage.BudgetConfig{}, nil, usages
+}
+
+// budgetHandler charges the tool calls to the budgets of the roles which allowed them, once they are forwarded
+// upstream. A deny budget exhausted since the calls were checked, e.g. during their approval, rejects them.
+func (s *Server) budgetHandler(proxyName, toolName string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		role := toolRoleFromContext(ctx, proxyName+":"+toolName)
+		if role == "" {
+			return next(ctx, request)
+		}
+		budget, exhausted, _ := s.consumeBudgets(ctx, []quotaCall{{proxy: proxyName, tool: toolName, role: role}})
+		if exhausted != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Budget exhausted: %s has %d of %d left per %s, resets at %s",
+				budget.Name, exhausted.Remaining, budget.Limit, budget.Window, exhausted.ResetsAt.Format(time.RFC3339))), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// consumeBudgets charges the cost of the tool calls to the budgets of the roles which allowed them. It returns the
+// first deny budget exhausted and its usage, if any, and the usage of the budgets charged.
+func (s *Server) consumeBudgets(ctx context.Context, calls []quotaCall) (storage.BudgetConfig, *storage.BudgetUsage, []storage.BudgetUsage) {
+	if len(calls) == 0 {
+		return storage.BudgetConfig{}, nil, nil
+	}
+	budgets, costs, ok := s.listBudgets(ctx)
+	if !ok {
+		return storage.BudgetConfig{}, nil, nil
+	}
+
+	now := time.Now()
+	var usages []storage.BudgetUsage
+	for _, call := range calls {
+		cost := storage.ToolCost(costs, call.proxy, call.tool)
+		for _, budget := range budgets {
+			if budget.Role != call.role {
+				continue
+			}
+			usage, covered, err := s.Storage.ConsumeBudget(ctx, budget, cost, now)
+			if err != nil {
+				s.Logger.Error("Failed to charge the tool call to the budget", zap.String("budget", budget.Name), zap.Error(err))
+				continue
+			}
+			usages = setBudgetUsage(usages, usage)
+			if covered {
+				continue
+			}
+			metrics.BudgetExhaustedCounter.WithLabelValues(budget.Name, string(budget.Action)).Inc()
+			if budget.Action == storage.BudgetActionDeny {
+				s.Logger.Info("Budget exhausted",
+					zap.String("budget", budget.Name),
+					zap.String("role", budget.Role),
+					zap.String("proxy", call.proxy),
+					zap.String("tool", call.tool))
+				return budget, &usage, usages
+			}
+			s.Logger.Warn("Budget exhausted, allowing the tool call",
+				zap.String("budget", budget.Name),
+				zap.String("role", budget.Role),
+				zap.Int64("consumed", usage.Consumed),
+				zap.Int64("limit", budget.Limit),
+				zap.String("proxy", call.proxy),
+				zap.String("tool", call.tool))
+		}
+	}
+	return storage.BudgetConfig{}, nil, usages
+}
+
+// findBudgetUsage returns the usage of a budget, false if it is not in the usages.
+func findBudgetUsage(usages []storage.BudgetUsage, name string) (storage.BudgetUsage, bool) {
+	for _, usage := range usages {
+		if usage.Budget == name {
+			return usage, true
+		}
+	}
+	return storage.BudgetUsage{}, false
+}
+
+// setBudgetUsage keeps the latest usage of each budget charged.
+func setBudgetUsage(usages []storage.BudgetUsage, usage storage.BudgetUsage) []storage.BudgetUsage {
+	for i := range usages {
+		if usages[i].Budget == usage.Budget {
+			usages[i] = usage
+			return usages
+		}
+	}
+	return append(usages, usage)
+}
+
+// setBudgetRemaining tells the caller the cost left in the budgets charged, e.g. "readers=40, writers=0".
+func setBudgetRemaining(c echo.Context, usages []storage.BudgetUsage) {
+	if len(usages) == 0 {
+		return
+	}
+	remaining := make([]string, 0, len(usages))
+	for _, usage := range usages {
+		remaining = append(remaining, fmt.Sprintf("%s=%d", usage.Budget, usage.Remaining))
+	}
+	c.Response().Header().Set(budgetRemainingHeader, strings.Join(remaining, ", "))
+}
+
+// budgetExhausted rejects a request whose cost exceeds the budget of its role, telling when to retry.
+func budgetExhausted(c echo.Context, budget storage.BudgetConfig, usage *storage.BudgetUsage) error {
+	retryAfter := int(time.Until(usage.ResetsAt).Seconds()) + 1
+	c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	c.Response().Header().Set(budgetRemainingHeader, fmt.Sprintf("%s=%d", budget.Name, usage.Remaining))
+	return echo.NewHTTPError(http.StatusTooManyRequests, fmt.Sprintf("Budget exhausted: %s has %d of %d left per %s, resets at %s",
+		budget.Name, usage.Remaining, budget.Limit, budget.Window, usage.ResetsAt.Format(time.RFC3339)))
+}
+
+// @Summary		Get all tool costs
+// @Description	Get the cost weights of the tool calls charged to the budgets. The tools without a cost cost 1.
+// @Tags			budgets
+// @Accept			json
+// @Produce		json
+// @Security		Authentication
+// @Success		200	{array}		storage.ToolCostConfig
+// @Failure		500	{object}	map[string]string
+// @Router			/v1/admin/tool-costs [get]
+func (s *Server) getToolCosts(c echo.Context) error {
+	costs, err := s.Storage.ListToolCosts(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, costs)
+}
+
+// @Summary		Upsert a tool cost
+// @Description	Create or update the cost of a tool, or of the tools of a proxy with the '*' tool
+// @Tags			budgets
+// @Accept			json
+// @Produce		json
+// @Param			cost	body	storage.ToolCostConfig	true	"Tool cost"
+// @Success		200
+// @Failure		400	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/tool-costs [put]
+func (s *Server) upsertToolCost(c echo.Context) error {
+	cost := storage.ToolCostConfig{}
+	if err := c.Bind(&cost); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := cost.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := s.Storage.SetToolCost(c.Request().Context(), cost); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// @Summary		Delete a tool cost
+// @Description	Delete the cost of a tool
+// @Tags			budgets
+// @Accept			json
+// @Produce		json
+// @Param			proxy	path	string	true	"Proxy name"
+// @Param			tool	path	string	true	"Tool name"
+// @Success		200
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/tool-costs/{proxy}/{tool} [delete]
+func (s *Server) deleteToolCost(c echo.Context) error {
+	if err := s.Storage.DeleteToolCost(c.Request().Context(), c.Param("proxy"), c.Param("tool")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// @Summary		Get all budgets
+// @Description	Get the budgets limiting the cost of the tool calls of each role per window
+// @Tags			budgets
+// @Accept			json
+// @Produce		json
+// @Security		Authentication
+// @Success		200	{array}		storage.BudgetConfig
+// @Failure		500	{object}	map[string]string
+// @Router			/v1/admin/budgets [get]
+func (s *Server) getBudgets(c echo.Context) error {
+	budgets, err := s.Storage.ListBudgets(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, budgets)
+}
+
+// @Summary		Upsert a budget
+// @Description	Create or update a budget, shared by every subject holding the role. The usage is kept on update.
+// @Tags			budgets
+// @Accept			json
+// @Produce		json
+// @Param			budget	body	storage.BudgetConfig	true	"Budget"
+// @Success		200
+// @Failure		400	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/budgets [put]
+func (s *Server) upsertBudget(c echo.Context) error {
+	budget := storage.BudgetConfig{}
+	if err := c.Bind(&budget); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := budget.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := s.Storage.SetBudget(c.Request().Context(), budget); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// @Summary		Delete a budget
+// @Description	Delete a budget and its usage
+// @Tags			budgets
+// @Accept			json
+// @Produce		json
+// @Param			name	path	string	true	"Budget name"
+// @Success		200
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/budgets/{name} [delete]
+func (s *Server) deleteBudget(c echo.Context) error {
+	if err := s.Storage.DeleteBudget(c.Request().Context(), c.Param("name")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// @Summary		Get the usage of a budget
+// @Description	Get the cost consumed and left in the current window of a budget
+// @Tags			budgets
+// @Accept			json
+// @Produce		json
+// @Param			name	path		string	true	"Budget name"
+// @Success		200		{object}	storage.BudgetUsage
+// @Failure		404		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/budgets/{name}/usage [get]
+func (s *Server) getBudgetUsage(c echo.Context) error {
+	budget, err := s.Storage.GetBudget(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	usage, err := s.Storage.GetBudgetUsage(c.Request().Context(), budget, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, usage)
+}
+
+// @Summary		Reset the usage of a budget
+// @Description	Reset the cost consumed in the current window of a budget
+// @Tags			budgets
+// @Accept			json
+// @Produce		json
+// @Param			name	path	string	true	"Budget name"
+// @Success		200
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/budgets/{name}/usage [delete]
+func (s *Server) resetBudgetUsage(c echo.Context) error {
+	name := c.Param("name")
+	if _, err := s.Storage.GetBudget(c.Request().Context(), name); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if err := s.Storage.ResetBudgetUsage(c.Request().Context(), name); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
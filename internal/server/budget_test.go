@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/screening"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthMiddleware_Budgets(t *testing.T) {
+	server := createTestServer(true, &MockProvider{shouldVerifyToken: true, shouldVerifyPermissions: true})
+	store := storage.NewMemoryStorage("")
+	server.Storage = store
+	ctx := context.Background()
+	require.NoError(t, store.SetToolCost(ctx, storage.ToolCostConfig{Proxy: "proxy1", Tool: "*", Cost: 3}))
+	require.NoError(t, store.SetBudget(ctx, storage.BudgetConfig{Name: "testers", Role: "tester",
+		Window: storage.QuotaWindowDay, Limit: 6, Action: storage.BudgetActionDeny}))
+
+	call := func(tool string) (*httptest.ResponseRecorder, error) {
+		middleware := server.authMiddleware(func(c echo.Context) error {
+			// The calls are charged once forwarded upstream
+			proxyName, toolName := server.parseToolName(tool)
+			forward := server.budgetHandler(proxyName, toolName, func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("ok"), nil
+			})
+			if _, err := forward(c.Request().Context(), mcp.CallToolRequest{}); err != nil {
+				return err
+			}
+			return c.String(http.StatusOK, "ok")
+		})
+		req := createMCPRequest("tools/call", tool)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		rec := httptest.NewRecorder()
+		return rec, middleware(createTestContext(server, req, rec, "/mcp"))
+	}
+
+	rec, err := call("proxy1:tool1")
+	assert.NoError(t, err)
+	assert.Equal(t, "testers=3", rec.Header().Get(budgetRemainingHeader))
+	rec, err = call("proxy2:tool1")
+	assert.NoError(t, err)
+	assert.Equal(t, "testers=2", rec.Header().Get(budgetRemainingHeader), "the tools without a cost cost 1")
+
+	rec, err = call("proxy1:tool1")
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusTooManyRequests, httpErr.Code)
+	assert.Contains(t, httpErr.Message, "Budget exhausted: testers has 2 of 6 left per day")
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	// A cheaper call still fits in the budget
+	_, err = call("proxy2:tool1")
+	assert.NoError(t, err)
+
+	// A warn budget lets the calls through
+	require.NoError(t, store.SetBudget(ctx, storage.BudgetConfig{Name: "testers", Role: "tester",
+		Window: storage.QuotaWindowDay, Limit: 6, Action: storage.BudgetActionWarn}))
+	rec, err = call("proxy1:tool1")
+	assert.NoError(t, err)
+	assert.Equal(t, "testers=0", rec.Header().Get(budgetRemainingHeader))
+}
+
+func TestBudgetHandler(t *testing.T) {
+	defer metrics.ToolCallsScreenedCounter.Reset()
+	srv := createTestServer(false, &MockProvider{})
+	store := storage.NewMemoryStorage("")
+	srv.Storage = store
+	ctx := context.Background()
+	budget := storage.BudgetConfig{Name: "testers", Role: "tester", Window: storage.QuotaWindowDay, Limit: 1,
+		Action: storage.BudgetActionDeny}
+	require.NoError(t, store.SetBudget(ctx, budget))
+	screener, err := screening.New(&cfg.ScreeningConfig{Rules: cfg.ScreeningRules, Action: cfg.ScreeningActionBlock})
+	require.NoError(t, err)
+	srv.Screener = screener
+
+	handler := srv.toolHandler("github", "search", func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("found"), nil
+	})
+	//nolint:staticcheck,revive // We need to use the key as a string
+	ctx = context.WithValue(ctx, "toolRoles", map[string]string{"github:search": "tester"})
+	call := func(query string) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"query": query}
+		result, err := handler(ctx, request)
+		require.NoError(t, err)
+		return result
+	}
+	consumed := func() int64 {
+		usage, err := store.GetBudgetUsage(ctx, budget, time.Now())
+		require.NoError(t, err)
+		return usage.Consumed
+	}
+
+	assert.True(t, call("1 UNION SELECT password FROM users").IsError)
+	assert.Zero(t, consumed(), "a call blocked by the screening must not be charged")
+
+	assert.False(t, call("mcp gateway").IsError)
+	assert.Equal(t, int64(1), consumed())
+
+	result := call("mcp gateway")
+	assert.True(t, result.IsError, "a deny budget exhausted since the check rejects the call")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Budget exhausted: testers has 0 of 1 left per day")
+}
+
+func TestBudgetHandlers(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Storage = storage.NewMemoryStorage("")
+	srv.ConfigureRoutes(srv.Router.Group("/v1"))
+
+	request := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodPut, "/v1/admin/tool-costs", `{"proxy":"github","tool":"search","cost":-1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = request(http.MethodPut, "/v1/admin/tool-costs", `{"proxy":"github","tool":"search","cost":5}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = request(http.MethodGet, "/v1/admin/tool-costs", "")
+	assert.JSONEq(t, `[{"proxy":"github","tool":"search","cost":5}]`, rec.Body.String())
+	rec = request(http.MethodDelete, "/v1/admin/tool-costs/github/search", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = request(http.MethodPut, "/v1/admin/budgets", `{"name":"readers","role":"reader","window":"month","limit":100,"action":"block"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = request(http.MethodPut, "/v1/admin/budgets", `{"name":"readers","role":"reader","window":"month","limit":100,"action":"deny"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = request(http.MethodGet, "/v1/admin/budgets/readers/usage", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var usage storage.BudgetUsage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &usage))
+	assert.Equal(t, int64(100), usage.Remaining)
+
+	rec = request(http.MethodDelete, "/v1/admin/budgets/readers/usage", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = request(http.MethodGet, "/v1/admin/budgets/missing/usage", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = request(http.MethodDelete, "/v1/admin/budgets/readers", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/proxy"
+	"go.uber.org/zap"
+)
+
+// clientCancelledError is the cause of the tool calls cancelled by their client.
+type clientCancelledError struct {
+	reason string
+}
+
+func (e *clientCancelledError) Error() string {
+	if e.reason == "" {
+		return "the client cancelled the call"
+	}
+	return "the client cancelled the call: " + e.reason
+}
+
+// cancellationKey identifies an in-flight tool call: the request IDs are only unique for a caller.
+type cancellationKey struct {
+	caller    string
+	requestID string
+}
+
+// cancellableCall is an in-flight tool call, which its client can cancel.
+type cancellableCall struct {
+	cancel context.CancelCauseFunc
+}
+
+// cancellations tracks the in-flight tool calls, so the cancellation notifications of the clients cancel them.
+// The calls are only known by the replica running them.
+type cancellations struct {
+	mu    sync.Mutex
+	calls map[cancellationKey]*cancellableCall
+}
+
+func newCancellations() *cancellations {
+	return &cancellations{calls: make(map[cancellationKey]*cancellableCall)}
+}
+
+func (c *cancellations) track(key cancellationKey, call *cancellableCall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[key] = call
+}
+
+func (c *cancellations) done(key cancellationKey, call *cancellableCall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// A client reusing the ID of an in-flight call replaced it.
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+}
+
+// cancel cancels an in-flight call, returning false if it is unknown or completed.
+func (c *cancellations) cancel(key cancellationKey, reason string) bool {
+	c.mu.Lock()
+	call, ok := c.calls[key]
+	c.mu.Unlock()
+	if ok {
+		call.cancel(&clientCancelledError{reason: reason})
+	}
+	return ok
+}
+
+// cancellationMessage is the part of a JSON-RPC message used to track the tool calls and their cancellations.
+type cancellationMessage struct {
+	ID     mcp.RequestId                   `json:"id"`
+	Method string                          `json:"method"`
+	Params mcp.CancelledNotificationParams `json:"params"`
+}
+
+// cancellationHandler binds the tool calls to a context cancelled by the cancellation notifications of their
+// client. The cancellation is forwarded to the upstream server by the proxy.
+func (s *Server) cancellationHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeBatchError(w, mcp.PARSE_ERROR, "failed to read the request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var message cancellationMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		switch {
+		case message.Method == string(mcp.MethodToolsCall) && !message.ID.IsNil():
+			ctx, cancel := context.WithCancelCause(r.Context())
+			defer cancel(nil)
+			key := cancellationKey{caller: s.mcpCaller(r), requestID: message.ID.String()}
+			call := &cancellableCall{cancel: cancel}
+			s.cancellations.track(key, call)
+			defer s.cancellations.done(key, call)
+			r = r.WithContext(ctx)
+		case message.Method == proxy.MethodCancelled && !message.Params.RequestId.IsNil():
+			key := cancellationKey{caller: s.mcpCaller(r), requestID: message.Params.RequestId.String()}
+			if s.cancellations.cancel(key, message.Params.Reason) {
+				s.Logger.Info("Tool call cancelled by the client",
+					zap.Any("request_id", message.Params.RequestId.Value()),
+					zap.String("reason", message.Params.Reason))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancellationHandler(t *testing.T) {
+	s := &Server{Logger: logger.MustNewLogger("json", "error", "test"), cancellations: newCancellations()}
+	started := make(chan struct{})
+	causes := make(chan error, 1)
+	handler := s.cancellationHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Call") != "" {
+			close(started)
+			<-r.Context().Done()
+			causes <- context.Cause(r.Context())
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	send := func(subject, body string, header http.Header) {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		//nolint:staticcheck,revive // The claims are stored under a string key
+		req = req.WithContext(context.WithValue(req.Context(), "claims", map[string]interface{}{"sub": subject}))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	go send("alice", `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"proxy1:tool1"}}`,
+		http.Header{"X-Call": {"1"}})
+	<-started
+
+	send("bob", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"stop"}}`, nil)
+	send("alice", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"7","reason":"stop"}}`, nil)
+	select {
+	case cause := <-causes:
+		t.Fatalf("the call was cancelled by another caller or request ID: %v", cause)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	send("alice", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"stop"}}`, nil)
+	select {
+	case cause := <-causes:
+		require.Error(t, cause)
+		assert.Equal(t, "the client cancelled the call: stop", cause.Error())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the call was not cancelled")
+	}
+
+	assert.Eventually(t, func() bool {
+		s.cancellations.mu.Lock()
+		defer s.cancellations.mu.Unlock()
+		return len(s.cancellations.calls) == 0
+	}, time.Second, 10*time.Millisecond, "the completed calls are forgotten")
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+)
+
+// @Summary		Get all classification labels
+// @Description	Get the classification levels of the tools, compared to the clearance of the roles on each call
+// @Tags			classifications
+// @Accept			json
+// @Produce		json
+// @Security		Authentication
+// @Success		200	{array}		storage.ClassificationConfig
+// @Failure		500	{object}	map[string]string
+// @Router			/v1/admin/classifications [get]
+func (s *Server) getClassifications(c echo.Context) error {
+	classifications, err := s.Storage.ListClassifications(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, classifications)
+}
+
+// @Summary		Upsert a classification label
+// @Description	Create or update the classification level of a tool, of the tools of a proxy with the '*' tool, or of every tool with the '*' proxy
+// @Tags			classifications
+// @Accept			json
+// @Produce		json
+// @Param			classification	body	storage.ClassificationConfig	true	"Classification label"
+// @Success		200
+// @Failure		400	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/classifications [put]
+func (s *Server) upsertClassification(c echo.Context) error {
+	classification := storage.ClassificationConfig{}
+	if err := c.Bind(&classification); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := classification.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := s.Storage.SetClassification(c.Request().Context(), classification); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// @Summary		Delete a classification label
+// @Description	Delete the classification label of a tool
+// @Tags			classifications
+// @Accept			json
+// @Produce		json
+// @Param			proxy	path	string	true	"Proxy name"
+// @Param			tool	path	string	true	"Tool name"
+// @Success		200
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/classifications/{proxy}/{tool} [delete]
+func (s *Server) deleteClassification(c echo.Context) error {
+	if err := s.Storage.DeleteClassification(c.Request().Context(), c.Param("proxy"), c.Param("tool")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
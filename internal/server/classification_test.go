@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassificationHandlers(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	store := storage.NewMemoryStorage("")
+	srv.Storage = store
+	srv.ConfigureRoutes(srv.Router.Group("/v1"))
+
+	request := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodPut, "/v1/admin/classifications", `{"proxy":"github","tool":"*","level":"secret"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = request(http.MethodPut, "/v1/admin/classifications", `{"proxy":"*","tool":"search","level":"internal"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "a wildcard proxy requires a wildcard tool")
+	rec = request(http.MethodPut, "/v1/admin/classifications", `{"proxy":"github","tool":"*","level":"confidential"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = request(http.MethodGet, "/v1/admin/classifications", "")
+	assert.JSONEq(t, `[{"proxy":"github","tool":"*","level":"confidential"}]`, rec.Body.String())
+
+	classifications, err := store.ListClassifications(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, storage.ClassificationConfidential, storage.ClassificationOf(classifications, "github", "search"))
+
+	rec = request(http.MethodDelete, "/v1/admin/classifications/github/*", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = request(http.MethodGet, "/v1/admin/classifications", "")
+	assert.JSONEq(t, `[]`, rec.Body.String())
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	methodComplete  = "completion/complete"
+	refTypePrompt   = "ref/prompt"
+	refTypeResource = "ref/resource"
+)
+
+// completionProxy is a proxy answering the argument completions of its upstream server.
+type completionProxy interface {
+	Complete(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error)
+}
+
+// completionRef is the prompt or resource referenced by a completion/complete request.
+type completionRef struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+}
+
+// completionRequest is a completion/complete JSON-RPC request.
+type completionRequest struct {
+	ID     mcp.RequestId `json:"id"`
+	Method string        `json:"method"`
+	Params struct {
+		Ref      completionRef `json:"ref"`
+		Argument struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"argument"`
+	} `json:"params"`
+}
+
+// completionHandler answers the completion/complete requests, which the MCP server does not support,
+// by routing them to the proxy owning the referenced prompt or resource, based on its namespace prefix.
+func (s *Server) completionHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeBatchError(w, mcp.PARSE_ERROR, "failed to read the request body")
+			return
+		}
+		var message completionRequest
+		if err := json.Unmarshal(body, &message); err != nil || message.Method != methodComplete {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		completer, request, err := s.routeCompletion(&message)
+		if err != nil {
+			writeJSONRPC(w, mcp.NewJSONRPCError(message.ID, mcp.INVALID_PARAMS, err.Error(), nil))
+			return
+		}
+		result, err := completer.Complete(r.Context(), request)
+		if err != nil {
+			// The upstream errors are not disclosed to the client
+			s.Logger.Warn("Failed to complete the argument", zap.Error(err))
+			writeJSONRPC(w, mcp.NewJSONRPCError(message.ID, mcp.INTERNAL_ERROR, "failed to complete the argument", nil))
+			return
+		}
+		writeJSONRPC(w, mcp.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: message.ID, Result: result})
+	})
+}
+
+// routeCompletion returns the proxy of the referenced prompt or resource, and the request to forward it.
+func (s *Server) routeCompletion(message *completionRequest) (completionProxy, mcp.CompleteRequest, error) {
+	request := mcp.CompleteRequest{Request: mcp.Request{Method: methodComplete}}
+	request.Params.Argument.Name = message.Params.Argument.Name
+	request.Params.Argument.Value = message.Params.Argument.Value
+
+	ref := message.Params.Ref
+	_, proxyName, _, err := ref.object()
+	if err != nil {
+		return nil, request, err
+	}
+	if ref.Type == refTypePrompt {
+		request.Params.Ref = mcp.PromptReference{Type: ref.Type, Name: ref.Name}
+	} else {
+		request.Params.Ref = mcp.ResourceReference{Type: ref.Type, URI: ref.URI}
+	}
+	registered, ok := s.tools.get(proxyName)
+	if !ok || registered.Completer == nil {
+		return nil, request, fmt.Errorf("unknown proxy %q", proxyName)
+	}
+	return registered.Completer, request, nil
+}
+
+// object returns the object type, the proxy and the name of the referenced prompt or resource, on which the
+// completions are granted.
+func (ref *completionRef) object() (objectType storage.ObjectType, proxyName, objectName string, err error) {
+	var reference string
+	switch ref.Type {
+	case refTypePrompt:
+		objectType, reference = storage.ObjectTypePrompts, ref.Name
+	case refTypeResource:
+		objectType, reference = storage.ObjectTypeResources, ref.URI
+	default:
+		return "", "", "", fmt.Errorf("unsupported reference type %q", ref.Type)
+	}
+	proxyName, objectName, ok := strings.Cut(reference, ":")
+	if !ok {
+		return "", "", "", fmt.Errorf("reference %q has no proxy prefix", reference)
+	}
+	return objectType, proxyName, objectName, nil
+}
+
+// writeJSONRPC writes a JSON-RPC response or error.
+func writeJSONRPC(w http.ResponseWriter, message any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(message)
+}
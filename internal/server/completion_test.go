@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCompleter completes with the referenced name or URI.
+type fakeCompleter struct {
+	err error
+}
+
+func (f *fakeCompleter) Complete(_ context.Context, req mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	result := &mcp.CompleteResult{}
+	switch ref := req.Params.Ref.(type) {
+	case mcp.PromptReference:
+		result.Completion.Values = []string{ref.Name + "/" + req.Params.Argument.Value}
+	case mcp.ResourceReference:
+		result.Completion.Values = []string{ref.URI + "/" + req.Params.Argument.Value}
+	}
+	return result, nil
+}
+
+func TestCompletionHandler(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.tools = newToolRegistry()
+	srv.tools.setCompleter("github", &fakeCompleter{})
+	srv.tools.setCompleter("broken", &fakeCompleter{err: errors.New("upstream unavailable")})
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("next"))
+	})
+
+	complete := func(ref string) string {
+		return `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{"ref":` + ref +
+			`,"argument":{"name":"repo","value":"he"}}}`
+	}
+	for _, test := range []struct {
+		name         string
+		body         string
+		expectedBody string
+	}{
+		{name: "not a completion", body: `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, expectedBody: "next"},
+		{name: "prompt", body: complete(`{"type":"ref/prompt","name":"github:review"}`), expectedBody: `"values":["github:review/he"]`},
+		{name: "resource", body: complete(`{"type":"ref/resource","uri":"github:repo://{name}"}`), expectedBody: `"values":["github:repo://{name}/he"]`},
+		{name: "unknown proxy", body: complete(`{"type":"ref/prompt","name":"n8n:run"}`), expectedBody: `"code":-32602`},
+		{name: "no prefix", body: complete(`{"type":"ref/prompt","name":"review"}`), expectedBody: `"code":-32602`},
+		{name: "invalid reference type", body: complete(`{"type":"ref/tool","name":"github:search"}`), expectedBody: `"code":-32602`},
+		{name: "upstream error", body: complete(`{"type":"ref/prompt","name":"broken:review"}`), expectedBody: `"code":-32603,"message":"failed to complete the argument"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(test.body))
+			rec := httptest.NewRecorder()
+
+			srv.completionHandler(next).ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), test.expectedBody)
+		})
+	}
+}
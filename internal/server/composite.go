@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
+)
+
+// defaultCompositeInputSchema is the input schema of the composite tools without one, accepting any argument.
+var defaultCompositeInputSchema = json.RawMessage(`{"type":"object"}`)
+
+// syncCompositeTools registers the composite tools of the storage on the MCP server, as the tools of the reserved
+// composite proxy. Their steps are looked up when they are called, so the composite tools do not depend on the
+// proxy sync.
+func (s *Server) syncCompositeTools(mcpServer *server.MCPServer) {
+	composites, err := s.Storage.ListCompositeTools(context.Background())
+	if err != nil {
+		s.Logger.Error("Failed to get the composite tools", zap.Error(err))
+		return
+	}
+	tools := make([]server.ServerTool, 0, len(composites))
+	for i := range composites {
+		composite := composites[i]
+		schema := composite.InputSchema
+		if len(schema) == 0 {
+			schema = defaultCompositeInputSchema
+		}
+		tool := mcp.NewToolWithRawSchema(storage.CompositeProxy+":"+composite.Name, composite.Description, schema)
+		handler := s.toolHandler(storage.CompositeProxy, composite.Name, s.compositeToolHandler(&composite))
+		tools = append(tools, server.ServerTool{Tool: tool, Handler: handler})
+	}
+	s.syncProxyTools(mcpServer, storage.CompositeProxy, tools)
+}
+
+// compositeToolHandler runs the steps of a composite tool, stage by stage, through the handlers of their tools.
+// Each step must be allowed to the caller, as if called directly. The result is the one of the last stage, its
+// contents in the order of its steps; the first failed step ends the call with its error.
+func (s *Server) compositeToolHandler(composite *storage.CompositeToolConfig) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// The time spent upstream is recorded once, for the whole composite tool
+		//nolint:staticcheck,revive // We need to use the key as a string
+		ctx = context.WithValue(ctx, "trace", nil)
+		args := request.GetArguments()
+		if args == nil {
+			args = map[string]any{}
+		}
+		outputs := make(map[string]any, len(composite.Steps))
+		scope := map[string]any{"args": args, "steps": outputs}
+
+		var results []*mcp.CallToolResult
+		for _, stage := range composite.Stages() {
+			results = make([]*mcp.CallToolResult, len(stage))
+			var wg sync.WaitGroup
+			for i := range stage {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					results[i] = s.callCompositeStep(ctx, composite.Name, &stage[i], scope)
+				}()
+			}
+			wg.Wait()
+			for i, result := range results {
+				if result.IsError {
+					return result, nil
+				}
+				outputs[stage[i].ID] = compositeOutput(result)
+			}
+		}
+
+		last := &mcp.CallToolResult{}
+		for _, result := range results {
+			last.Content = append(last.Content, result.Content...)
+		}
+		return last, nil
+	}
+}
+
+// callCompositeStep calls the tool of a step with its resolved arguments, returning an error result if the call
+// is not allowed, exceeds a quota or a budget of the tool, or fails. The scope is only read, the steps of a stage running concurrently.
+func (s *Server) callCompositeStep(
+	ctx context.Context,
+	compositeName string,
+	step *storage.CompositeStep,
+	scope map[string]any,
+) *mcp.CallToolResult {
+	failed := func(reason string) *mcp.CallToolResult {
+		s.Logger.Debug("Composite tool step failed", zap.String("composite", compositeName),
+			zap.String("step", step.ID), zap.String("reason", reason))
+		res := mcp.NewToolResultError(fmt.Sprintf("step %q of composite tool %q failed: %s", step.ID, compositeName, reason))
+		res.Meta = map[string]any{"error": map[string]any{"code": "composite_step_failed", "step": step.ID}}
+		return res
+	}
+
+	if claims, ok := ctx.Value("claims").(map[string]interface{}); ok {
+		decision := s.Provider.ExplainPermissions(ctx, "tools", step.Proxy, step.Tool, claims)
+		s.observeAuthzDecision(step.Proxy, "tools", decision.Allowed)
+		if !decision.Allowed {
+			return failed(fmt.Sprintf("tool %s:%s is not allowed", step.Proxy, step.Tool))
+		}
+		// The quotas and budgets of the tool apply as if called directly, the composite tool being only charged
+		// for itself. The budgets are charged once the step is forwarded upstream, with the role allowing it.
+		calls := []quotaCall{{proxy: step.Proxy, tool: step.Tool, roles: decision.Roles, role: decision.MatchedRole}}
+		if quota, usage := s.consumeQuotas(ctx, identityFromClaims(claims), calls); usage != nil {
+			return failed(fmt.Sprintf("quota %s exceeded, resets at %s", quota.Name, usage.ResetsAt.Format(time.RFC3339)))
+		}
+		if budget, exhausted, _ := s.checkBudgets(ctx, calls); exhausted != nil {
+			return failed(fmt.Sprintf("budget %s exhausted, resets at %s", budget.Name, exhausted.ResetsAt.Format(time.RFC3339)))
+		}
+		ctx = contextWithToolRole(ctx, step.Proxy+":"+step.Tool, decision.MatchedRole)
+	}
+	tool, ok := s.tools.getTool(step.Proxy, step.Tool)
+	if !ok {
+		return failed(fmt.Sprintf("tool %s:%s not found", step.Proxy, step.Tool))
+	}
+	arguments, err := step.ResolveArguments(scope)
+	if err != nil {
+		return failed(err.Error())
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Method = string(mcp.MethodToolsCall)
+	request.Params.Name = tool.Tool.Name
+	request.Params.Arguments = arguments
+	result, err := tool.Handler(ctx, request)
+	switch {
+	case err != nil:
+		return failed(err.Error())
+	case result == nil:
+		return failed("empty result")
+	case result.IsError:
+		return failed(compositeText(result))
+	}
+	return result
+}
+
+// compositeOutput returns the output of a step referenced by the next steps: the JSON value of the text of its
+// result if it is valid JSON, else the text.
+func compositeOutput(result *mcp.CallToolResult) any {
+	text := compositeText(result)
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		return value
+	}
+	return text
+}
+
+// compositeText returns the text contents of a result, joined by new lines.
+func compositeText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// @Summary		Get all composite tools
+// @Description	Get the composite tools, exposed to the clients as composite:<name> and calling a sequence of upstream tools
+// @Tags			composite-tools
+// @Accept			json
+// @Produce		json
+// @Security		Authentication
+// @Success		200	{array}		storage.CompositeToolConfig
+// @Failure		500	{object}	map[string]string
+// @Router			/v1/admin/composite-tools [get]
+func (s *Server) getCompositeTools(c echo.Context) error {
+	tools, err := s.Storage.ListCompositeTools(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, tools)
+}
+
+// @Summary		Upsert a composite tool
+// @Description	Create or update a composite tool. Its steps call upstream tools in order, the parallel steps together with the step before them, and their arguments reference the arguments of the tool as ${args.<name>} and the results of the earlier steps as ${steps.<id>}. It is registered with the next proxy refresh.
+// @Tags			composite-tools
+// @Accept			json
+// @Produce		json
+// @Param			tool	body	storage.CompositeToolConfig	true	"Composite tool"
+// @Success		200
+// @Failure		400	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/composite-tools [put]
+func (s *Server) upsertCompositeTool(c echo.Context) error {
+	tool := storage.CompositeToolConfig{}
+	if err := c.Bind(&tool); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := tool.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := s.Storage.SetCompositeTool(c.Request().Context(), tool); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// @Summary		Delete a composite tool
+// @Description	Delete a composite tool, unregistered with the next proxy refresh
+// @Tags			composite-tools
+// @Accept			json
+// @Produce		json
+// @Param			name	path	string	true	"Composite tool name"
+// @Success		200
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/composite-tools/{name} [delete]
+func (s *Server) deleteCompositeTool(c echo.Context) error {
+	if err := s.Storage.DeleteCompositeTool(c.Request().Context(), c.Param("name")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingCallProxy is a proxy answering its tool calls with a text result per tool, recording their arguments.
+type recordingCallProxy struct {
+	name    string
+	results map[string]string
+	mu      sync.Mutex
+	calls   map[string]map[string]any
+}
+
+func (r *recordingCallProxy) GetName() string {
+	return r.name
+}
+
+func (r *recordingCallProxy) CallTool(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	tool := strings.TrimPrefix(request.Params.Name, r.name+":")
+	r.mu.Lock()
+	r.calls[tool] = request.GetArguments()
+	r.mu.Unlock()
+	if tool == "fail" {
+		return mcp.NewToolResultError("upstream unavailable"), nil
+	}
+	return mcp.NewToolResultText(r.results[tool]), nil
+}
+
+func TestCompositeTools(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{allowedObjects: []string{"triage", "search", "labels", "comment"}})
+	srv.Config = cfg.DefaultConfig()
+	store := storage.NewMemoryStorage("")
+	srv.Storage = store
+	srv.tools = newToolRegistry()
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+
+	jira := &recordingCallProxy{name: "jira", calls: map[string]map[string]any{}, results: map[string]string{
+		"search":  `{"issues":[{"key":"OPS-1"},{"key":"OPS-2"}]}`,
+		"labels":  "p1",
+		"comment": "commented OPS-1",
+		"delete":  "deleted",
+	}}
+	tools := []mcp.Tool{mcp.NewTool("search"), mcp.NewTool("labels"), mcp.NewTool("comment"), mcp.NewTool("delete"),
+		mcp.NewTool("fail")}
+	srv.syncProxyTools(mcpServer, "jira", srv.serverTools(jira, &storage.ProxyConfig{Name: "jira"}, tools))
+
+	ctx := context.Background()
+	require.NoError(t, store.SetCompositeTool(ctx, storage.CompositeToolConfig{
+		Name:        "triage",
+		Description: "Comment the first issue matching the query",
+		Steps: []storage.CompositeStep{
+			{ID: "search", Proxy: "jira", Tool: "search", Arguments: map[string]any{"jql": "${args.query}"}},
+			{ID: "labels", Proxy: "jira", Tool: "labels", Parallel: true},
+			{ID: "comment", Proxy: "jira", Tool: "comment", Arguments: map[string]any{
+				"issue": "${steps.search.issues.0.key}",
+				"body":  "Triaged as ${steps.labels}",
+			}},
+		},
+	}))
+	require.NoError(t, store.SetCompositeTool(ctx, storage.CompositeToolConfig{Name: "cleanup", Steps: []storage.CompositeStep{
+		{ID: "delete", Proxy: "jira", Tool: "delete"},
+	}}))
+	require.NoError(t, store.SetCompositeTool(ctx, storage.CompositeToolConfig{Name: "broken", Steps: []storage.CompositeStep{
+		{ID: "search", Proxy: "jira", Tool: "search"},
+		{ID: "fail", Proxy: "jira", Tool: "fail"},
+		{ID: "comment", Proxy: "jira", Tool: "comment"},
+	}}))
+	srv.syncCompositeTools(mcpServer)
+
+	call := func(ctx context.Context, name string, arguments map[string]any) *mcp.CallToolResult {
+		tool, ok := srv.tools.getTool(storage.CompositeProxy, name)
+		require.True(t, ok)
+		request := mcp.CallToolRequest{}
+		request.Params.Name = tool.Tool.Name
+		request.Params.Arguments = arguments
+		result, err := tool.Handler(ctx, request)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("the composite tools are registered", func(t *testing.T) {
+		registered, ok := srv.tools.get(storage.CompositeProxy)
+		require.True(t, ok)
+		require.Len(t, registered.Tools, 3)
+		assert.Equal(t, "composite:broken", registered.Tools[0].Tool.Name)
+		assert.JSONEq(t, `{"type":"object"}`, string(registered.Tools[0].Tool.RawInputSchema))
+	})
+
+	t.Run("the steps are called with the mapped arguments", func(t *testing.T) {
+		result := call(ctx, "triage", map[string]any{"query": "project = OPS"})
+		require.False(t, result.IsError)
+		assert.Equal(t, "commented OPS-1", result.Content[0].(mcp.TextContent).Text)
+		assert.Equal(t, map[string]any{"jql": "project = OPS"}, jira.calls["search"])
+		assert.Equal(t, map[string]any{"issue": "OPS-1", "body": "Triaged as p1"}, jira.calls["comment"])
+	})
+
+	t.Run("a failed step ends the call", func(t *testing.T) {
+		delete(jira.calls, "comment")
+		result := call(ctx, "broken", nil)
+		require.True(t, result.IsError)
+		assert.Equal(t, `step "fail" of composite tool "broken" failed: upstream unavailable`,
+			result.Content[0].(mcp.TextContent).Text)
+		assert.Equal(t, map[string]any{"code": "composite_step_failed", "step": "fail"}, result.Meta["error"])
+		assert.NotContains(t, jira.calls, "comment")
+	})
+
+	t.Run("each step must be allowed to the caller", func(t *testing.T) {
+		//nolint:staticcheck,revive // We need to use the key as a string
+		ctx := context.WithValue(ctx, "claims", map[string]interface{}{"sub": "test-user"})
+		result := call(ctx, "triage", map[string]any{"query": "project = OPS"})
+		assert.False(t, result.IsError)
+
+		result = call(ctx, "cleanup", nil)
+		require.True(t, result.IsError)
+		assert.Equal(t, `step "delete" of composite tool "cleanup" failed: tool jira:delete is not allowed`,
+			result.Content[0].(mcp.TextContent).Text)
+		assert.NotContains(t, jira.calls, "delete")
+	})
+
+	t.Run("the quotas of the steps apply", func(t *testing.T) {
+		require.NoError(t, store.SetQuota(ctx, storage.QuotaConfig{Name: "comments", SubjectType: storage.QuotaSubjectTypeSubject,
+			Subject: "test-user", Proxy: "jira", Tool: "comment", Window: storage.QuotaWindowHour, Limit: 1}))
+		defer func() { require.NoError(t, store.DeleteQuota(ctx, "comments")) }()
+		//nolint:staticcheck,revive // We need to use the key as a string
+		ctx := context.WithValue(ctx, "claims", map[string]interface{}{"sub": "test-user"})
+
+		result := call(ctx, "triage", map[string]any{"query": "project = OPS"})
+		require.False(t, result.IsError)
+		result = call(ctx, "triage", map[string]any{"query": "project = OPS"})
+		require.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text,
+			`step "comment" of composite tool "triage" failed: quota comments exceeded`)
+	})
+
+	t.Run("the deleted composite tools are unregistered", func(t *testing.T) {
+		require.NoError(t, store.DeleteCompositeTool(ctx, "cleanup"))
+		srv.syncCompositeTools(mcpServer)
+		_, ok := srv.tools.getTool(storage.CompositeProxy, "cleanup")
+		assert.False(t, ok)
+	})
+}
+
+func TestCompositeToolHandlers(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Storage = storage.NewMemoryStorage("")
+	srv.ConfigureRoutes(srv.Router.Group("/v1"))
+
+	request := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodPut, "/v1/admin/composite-tools", `{"name":"triage","steps":[]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = request(http.MethodPut, "/v1/admin/composite-tools",
+		`{"name":"triage","steps":[{"id":"search","proxy":"jira","tool":"search","arguments":{"jql":"${steps.comment}"}}]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "a step can only reference the earlier steps")
+	rec = request(http.MethodPut, "/v1/admin/composite-tools",
+		`{"name":"triage","description":"Search","steps":[{"id":"search","proxy":"jira","tool":"search"}]}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = request(http.MethodGet, "/v1/admin/composite-tools", "")
+	assert.JSONEq(t, `[{"name":"triage","description":"Search","steps":[{"id":"search","proxy":"jira","tool":"search"}]}]`,
+		rec.Body.String())
+
+	rec = request(http.MethodDelete, "/v1/admin/composite-tools/triage", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = request(http.MethodGet, "/v1/admin/composite-tools", "")
+	assert.JSONEq(t, `[]`, rec.Body.String())
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"go.uber.org/zap"
+	"golang.org/x/sync/semaphore"
+)
+
+// concurrencyLimiter caps the tool calls in flight in the gateway. The calls beyond the cap wait up to the queue
+// timeout for a slot.
+type concurrencyLimiter struct {
+	slots        *semaphore.Weighted
+	max          int64
+	queueTimeout time.Duration
+}
+
+func newConcurrencyLimiter(maxCalls int, queueTimeout time.Duration) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		slots:        semaphore.NewWeighted(int64(maxCalls)),
+		max:          int64(maxCalls),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// acquire takes a slot for each of the calls, at most all of them, and returns the function releasing them.
+// It returns false if the slots were not freed within the queue timeout.
+func (l *concurrencyLimiter) acquire(ctx context.Context, calls int) (func(), bool) {
+	n := min(int64(calls), l.max)
+	if !l.slots.TryAcquire(n) {
+		if l.queueTimeout <= 0 {
+			return nil, false
+		}
+		ctx, cancel := context.WithTimeout(ctx, l.queueTimeout)
+		defer cancel()
+		if err := l.slots.Acquire(ctx, n); err != nil {
+			return nil, false
+		}
+	}
+	return func() { l.slots.Release(n) }, true
+}
+
+// configureConcurrencyLimit caps the tool calls in flight, before they are authorized and counted in the quotas.
+func (s *Server) configureConcurrencyLimit() {
+	if s.Config.Proxy.MaxConcurrentCalls <= 0 {
+		return
+	}
+	s.Logger.Info("Capping the concurrent tool calls",
+		zap.Int("max", s.Config.Proxy.MaxConcurrentCalls),
+		zap.Duration("queue_timeout", s.Config.Proxy.QueueTimeout))
+	s.Router.Use(concurrencyMiddleware(s, newConcurrencyLimiter(s.Config.Proxy.MaxConcurrentCalls, s.Config.Proxy.QueueTimeout)))
+}
+
+// concurrencyMiddleware holds a slot of the limiter for each tool call of the /mcp requests while they run,
+// rejecting them with 429 Too Many Requests when no slot is freed in time.
+func concurrencyMiddleware(s *Server, limiter *concurrencyLimiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !s.isMCPPath(c.Path()) || c.Request().Method != http.MethodPost {
+				return next(c)
+			}
+			messages, err := s.parseRequestBody(c)
+			if err != nil {
+				return writeRPCError(c, err)
+			}
+			calls := 0
+			for _, message := range messages {
+				if message.Method == "tools/call" {
+					calls++
+				}
+			}
+			if calls == 0 {
+				return next(c)
+			}
+
+			release, ok := limiter.acquire(c.Request().Context(), calls)
+			if !ok {
+				metrics.ToolCallsThrottledCounter.Inc()
+				s.Logger.Warn("Rejecting tool calls, the maximum number of concurrent calls is reached",
+					zap.Int("calls", calls),
+					zap.Int64("max", limiter.max))
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(limiter.queueTimeout.Seconds())+1))
+				return echo.NewHTTPError(http.StatusTooManyRequests, "Too many concurrent tool calls, retry later")
+			}
+			defer release()
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter := newConcurrencyLimiter(2, 50*time.Millisecond)
+
+	release, ok := limiter.acquire(t.Context(), 1)
+	require.True(t, ok)
+	// A batch larger than the cap takes all the slots, once they are free
+	_, ok = limiter.acquire(t.Context(), 3)
+	assert.False(t, ok)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	releaseAll, ok := limiter.acquire(t.Context(), 3)
+	require.True(t, ok, "the call waits for the slot released within the queue timeout")
+	_, ok = limiter.acquire(t.Context(), 1)
+	assert.False(t, ok)
+	releaseAll()
+}
+
+func TestConcurrencyMiddleware(t *testing.T) {
+	server := createTestServer(false, &MockProvider{})
+	limiter := newConcurrencyLimiter(1, 0)
+	started, finish := make(chan struct{}), make(chan struct{})
+	handler := concurrencyMiddleware(server, limiter)(func(c echo.Context) error {
+		close(started)
+		<-finish
+		return c.String(http.StatusOK, "ok")
+	})
+
+	done := make(chan error)
+	go func() {
+		req := createMCPRequest("tools/call", "proxy1:tool1")
+		done <- handler(createTestContext(server, req, httptest.NewRecorder(), "/mcp"))
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	err := handler(createTestContext(server, createMCPRequest("tools/call", "proxy1:tool2"), rec, "/mcp"))
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusTooManyRequests, httpErr.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	// The other requests are not limited
+	passthrough := concurrencyMiddleware(server, limiter)(func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	assert.NoError(t, passthrough(createTestContext(server, createMCPRequest("tools/list", ""), httptest.NewRecorder(), "/mcp")))
+
+	close(finish)
+	require.NoError(t, <-done)
+	_, ok := limiter.acquire(t.Context(), 1)
+	assert.True(t, ok, "the slot is released once the call completes")
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	runtimepprof "runtime/pprof"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// debugPrefix prefixes the debug endpoints of the admin API.
+const debugPrefix = "/v1"
+
+// GCStats are the garbage collector and memory statistics of the gateway process.
+type GCStats struct {
+	NumGC         int64           `json:"numGC"`
+	LastGC        time.Time       `json:"lastGC"`
+	PauseTotal    string          `json:"pauseTotal"`
+	RecentPauses  []time.Duration `json:"recentPausesNs"`
+	GCCPUFraction float64         `json:"gcCPUFraction"`
+	HeapAlloc     uint64          `json:"heapAlloc"`
+	HeapSys       uint64          `json:"heapSys"`
+	HeapObjects   uint64          `json:"heapObjects"`
+	NextGC        uint64          `json:"nextGC"`
+	Goroutines    int             `json:"goroutines"`
+	GOMAXPROCS    int             `json:"gomaxprocs"`
+}
+
+// debugHandler serves the pprof profiles under /debug/pprof/, the goroutine dump on /debug/goroutines
+// and the GC statistics on /debug/gc.
+func debugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/goroutines", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2) //nolint:mnd // 2 prints the stacks as on a panic
+	})
+	mux.HandleFunc("/debug/gc", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(readGCStats())
+	})
+	return mux
+}
+
+func readGCStats() GCStats {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	var gcStats debug.GCStats
+	debug.ReadGCStats(&gcStats)
+	return GCStats{
+		NumGC:         gcStats.NumGC,
+		LastGC:        gcStats.LastGC,
+		PauseTotal:    gcStats.PauseTotal.String(),
+		RecentPauses:  gcStats.Pause,
+		GCCPUFraction: memStats.GCCPUFraction,
+		HeapAlloc:     memStats.HeapAlloc,
+		HeapSys:       memStats.HeapSys,
+		HeapObjects:   memStats.HeapObjects,
+		NextGC:        memStats.NextGC,
+		Goroutines:    runtime.NumGoroutine(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+	}
+}
+
+// configureDebugRoutes serves the debug endpoints under /v1/debug of the admin API, unless they have their own address.
+func (s *Server) configureDebugRoutes(v1 *echo.Group) {
+	if !s.Config.Debug.Enabled || s.Config.Debug.Addr != "" {
+		return
+	}
+	s.Logger.Warn("The debug endpoints are enabled on the admin API")
+	v1.Any("/debug/*", echo.WrapHandler(http.StripPrefix(debugPrefix, debugHandler())))
+}
+
+// isLongDebugRequest returns true for the CPU profiles and traces, which last as long as their seconds parameter.
+func isLongDebugRequest(path string) bool {
+	return path == debugPrefix+"/debug/pprof/profile" || path == debugPrefix+"/debug/pprof/trace"
+}
+
+// serveDebug starts the debug endpoints on their own address in the background, if configured.
+func (s *Server) serveDebug() error {
+	if !s.Config.Debug.Enabled || s.Config.Debug.Addr == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", s.Config.Debug.Addr)
+	if err != nil {
+		return err
+	}
+	s.debugServer = &http.Server{
+		Handler:           debugHandler(),
+		ReadHeaderTimeout: s.Config.HTTP.Timeouts.ReadHeader,
+	}
+	s.Logger.Warn("Starting the debug server, without authentication", zap.String("host", s.Config.Debug.Addr))
+	go func() {
+		if err := s.debugServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.Logger.Error("Debug server stopped", zap.Error(err))
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugRoutes(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config.HTTP = cfg.DefaultConfig().HTTP
+	srv.Config.HTTP.AdminAPIKey = "admin"
+	srv.Config.Debug = &cfg.DebugConfig{Enabled: true}
+	srv.configureV1Routes()
+
+	get := func(path, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("/v1/debug/gc", "").Code)
+
+	rec := get("/v1/debug/gc", "admin")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var stats GCStats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Positive(t, stats.Goroutines)
+	assert.Positive(t, stats.HeapAlloc)
+
+	rec = get("/v1/debug/goroutines", "admin")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "goroutine ")
+
+	rec = get("/v1/debug/pprof/", "admin")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "heap")
+	assert.Equal(t, http.StatusOK, get("/v1/debug/pprof/heap?debug=1", "admin").Code)
+
+	assert.True(t, isLongDebugRequest("/v1/debug/pprof/profile"))
+	assert.False(t, isLongDebugRequest("/v1/debug/pprof/heap"))
+}
+
+func TestDebugRoutesOwnAddress(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config.HTTP = cfg.DefaultConfig().HTTP
+	srv.Config.HTTP.AdminAPIKey = "admin"
+	srv.Config.Debug = &cfg.DebugConfig{Enabled: true, Addr: "127.0.0.1:0"}
+	srv.configureV1Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/debug/gc", nil)
+	req.Header.Set("X-API-Key", "admin")
+	rec := httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "the debug endpoints must only be served on their own address")
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+
+	"github.com/matthisholleville/mcp-gateway/internal/devserver"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
+)
+
+// devProxyName is the name of the proxy of the sample MCP server of the dev mode.
+const devProxyName = "dev"
+
+// configureDevMode starts the sample MCP server of the dev mode and registers it as a proxy.
+func (s *Server) configureDevMode() {
+	if s.Config.Dev == nil || !s.Config.Dev.Enabled {
+		return
+	}
+	devServer, err := devserver.Start()
+	if err != nil {
+		s.Logger.Error("Failed to start the dev MCP server", zap.Error(err))
+		panic(err)
+	}
+	proxy := &storage.ProxyConfig{
+		Name:     devProxyName,
+		Type:     storage.ProxyTypeStreamableHTTP,
+		URL:      devServer.URL(),
+		AuthType: storage.ProxyAuthTypeHeader,
+	}
+	if err := s.Storage.SetProxy(context.Background(), proxy, true); err != nil {
+		_ = devServer.Shutdown(context.Background())
+		s.Logger.Error("Failed to register the dev proxy", zap.Error(err))
+		panic(err)
+	}
+	s.devServer = devServer
+	s.Logger.Warn("Dev mode is enabled, do not use it in production",
+		zap.String("proxy", devProxyName),
+		zap.String("url", proxy.URL))
+}
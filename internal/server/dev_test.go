@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureDevMode(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config = cfg.DefaultConfig()
+	srv.Config.Dev.Enabled = true
+	srv.Storage = storage.NewMemoryStorage("")
+	srv.tools = newToolRegistry()
+	srv.configureDevMode()
+	t.Cleanup(func() { _ = srv.devServer.Shutdown(context.Background()) })
+
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+	names, err := srv.refreshProxies(mcpServer, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{devProxyName}, names)
+
+	response, err := json.Marshal(mcpServer.HandleMessage(t.Context(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)))
+	require.NoError(t, err)
+	assert.Contains(t, string(response), `"name":"dev:echo"`)
+	assert.Contains(t, string(response), `"name":"dev:add"`)
+}
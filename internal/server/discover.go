@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/proxy"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+)
+
+// discoverTimeout bounds the discovery of an upstream server.
+const discoverTimeout = 30 * time.Second
+
+// invalidProxyNameChars are the characters replaced in the proxy names derived from the server names.
+var invalidProxyNameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// DiscoverProxyResponse is the proxy configuration pre-populated from its upstream server, to review before saving
+// it with PUT /v1/admin/proxies/{name}, and what the server declares.
+type DiscoverProxyResponse struct {
+	Proxy           storage.ProxyConfig    `json:"proxy"`
+	ServerInfo      mcp.Implementation     `json:"serverInfo"`
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    mcp.ServerCapabilities `json:"capabilities"`
+	Instructions    string                 `json:"instructions,omitempty"`
+	// Stateful is true if the server opened a session.
+	Stateful bool          `json:"stateful"`
+	Tools    []ToolSummary `json:"tools"`
+	// Warnings are the points of the configuration to review.
+	Warnings []string `json:"warnings"`
+}
+
+// @Summary		Discover a proxy
+// @Description	Connect to an MCP server given its URL and auth, and return the proxy configuration pre-populated from the server name, with its version, capabilities and tools. The proxy is not saved. The name is derived from the server name if empty, and the timeout is in seconds.
+// @Tags			proxies
+// @Accept			json
+// @Produce		json
+// @Param			proxy	body		storage.ProxyConfig	true	"URL and auth of the proxy"
+// @Success		200		{object}	DiscoverProxyResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		502		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/proxies:discover [post]
+func (s *Server) discoverProxy(c echo.Context) error {
+	discovered := storage.ProxyConfig{}
+	if err := c.Bind(&discovered); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	endpoint, err := url.Parse(discovered.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "url must be an absolute http or https URL"})
+	}
+	if discovered.Type == "" {
+		discovered.Type = storage.ProxyTypeStreamableHTTP
+	}
+	if discovered.AuthType == "" {
+		discovered.AuthType = storage.ProxyAuthTypeHeader
+	}
+	if !discovered.Type.IsValid() {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid proxy type: %s", discovered.Type)})
+	}
+	if !discovered.AuthType.IsValid() {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid proxy auth type: %s", discovered.AuthType)})
+	}
+
+	// The timeout of the request is in seconds, as for PUT /v1/admin/proxies/{name}.
+	target := discovered
+	target.Timeout *= time.Second
+	ctx, cancel := context.WithTimeout(c.Request().Context(), discoverTimeout)
+	defer cancel()
+	discovery, err := proxy.Discover(ctx, &target, s.Config.Proxy.EgressProxy, s.secrets, s.Logger)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": fmt.Sprintf("unable to connect to the MCP server: %s", err)})
+	}
+
+	response := DiscoverProxyResponse{
+		ServerInfo:      discovery.ServerInfo,
+		ProtocolVersion: discovery.ProtocolVersion,
+		Capabilities:    discovery.Capabilities,
+		Instructions:    discovery.Instructions,
+		Stateful:        discovery.Stateful,
+		Tools:           make([]ToolSummary, 0, len(discovery.Tools)),
+		Warnings:        []string{},
+	}
+	for _, tool := range discovery.Tools {
+		response.Tools = append(response.Tools, summarizeTool(tool))
+	}
+	if discovered.Name == "" {
+		discovered.Name = proxyNameOf(discovery.ServerInfo.Name, endpoint.Hostname())
+	}
+	response.Proxy = discovered
+	response.Warnings = s.discoveryWarnings(c.Request().Context(), &response, endpoint)
+	return c.JSON(http.StatusOK, response)
+}
+
+// discoveryWarnings returns the points of a discovered proxy to review before saving it.
+func (s *Server) discoveryWarnings(ctx context.Context, response *DiscoverProxyResponse, endpoint *url.URL) []string {
+	warnings := []string{}
+	if _, err := s.Storage.GetProxy(ctx, response.Proxy.Name, false); err == nil {
+		warnings = append(warnings, fmt.Sprintf("a proxy named %q already exists, saving this one replaces it", response.Proxy.Name))
+	}
+	if strings.Contains(response.Proxy.Name, ":") {
+		warnings = append(warnings, "the proxy name must not contain ':', which separates the proxy and tool names")
+	}
+	if endpoint.Scheme != "https" {
+		warnings = append(warnings, "the URL is not HTTPS: the requests and their credentials are not encrypted")
+	}
+	if response.Capabilities.Tools == nil {
+		warnings = append(warnings, "the server does not declare the tools capability")
+	} else if len(response.Tools) == 0 {
+		warnings = append(warnings, "the server has no tool")
+	}
+	if response.ProtocolVersion != mcp.LATEST_PROTOCOL_VERSION {
+		warnings = append(warnings, fmt.Sprintf("the server negotiated the protocol version %s, instead of %s",
+			response.ProtocolVersion, mcp.LATEST_PROTOCOL_VERSION))
+	}
+	return warnings
+}
+
+// checkProxyConnection connects to the upstream server of a proxy to check that its URL and credentials work.
+func (s *Server) checkProxyConnection(ctx context.Context, proxyCfg *storage.ProxyConfig) error {
+	if !proxyCfg.Type.IsValid() {
+		return fmt.Errorf("invalid proxy type: %s", proxyCfg.Type)
+	}
+	if !proxyCfg.AuthType.IsValid() {
+		return fmt.Errorf("invalid proxy auth type: %s", proxyCfg.AuthType)
+	}
+	ctx, cancel := context.WithTimeout(ctx, discoverTimeout)
+	defer cancel()
+	if err := proxy.CheckConnection(ctx, proxyCfg, s.Config.Proxy.EgressProxy, s.secrets, s.Logger); err != nil {
+		return fmt.Errorf("unable to connect to the MCP server: %w", err)
+	}
+	return nil
+}
+
+// proxyNameOf derives a proxy name from the name of a server, or from its host if the server has no usable name.
+func proxyNameOf(serverName, host string) string {
+	name := strings.Trim(invalidProxyNameChars.ReplaceAllString(strings.ToLower(serverName), "-"), "-")
+	if name != "" {
+		return name
+	}
+	return strings.Trim(invalidProxyNameChars.ReplaceAllString(strings.ToLower(strings.Split(host, ".")[0]), "-"), "-")
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverProxy(t *testing.T) {
+	mcpServer := server.NewMCPServer("GitHub MCP", "2.3.0")
+	mcpServer.AddTool(mcp.NewTool("search", mcp.WithDescription("Search the repositories"), mcp.WithString("query")), nil)
+	handler := server.NewStreamableHTTPServer(mcpServer)
+	var authorization string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			authorization = r.Header.Get("Authorization")
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer upstream.Close()
+
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config.HTTP = cfg.DefaultConfig().HTTP
+	srv.Config.HTTP.AdminAPIKey = "admin"
+	srv.Config.Debug = &cfg.DebugConfig{}
+	srv.Config.Proxy = cfg.DefaultConfig().Proxy
+	srv.Storage = storage.NewMemoryStorage("")
+	require.NoError(t, srv.Storage.SetProxy(t.Context(), &storage.ProxyConfig{
+		Name: "github-mcp", Type: storage.ProxyTypeStreamableHTTP, URL: "https://github.example.com/mcp", AuthType: storage.ProxyAuthTypeHeader,
+	}, false))
+	srv.eventBroker = events.NewBroker(10)
+	srv.configureV1Routes()
+	ch, unsubscribe := srv.eventBroker.Subscribe()
+	defer unsubscribe()
+
+	request := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/proxies:discover", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-API-Key", "admin")
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(`{"url":"` + upstream.URL + `","headers":[{"key":"Authorization","value":"Bearer token"}],"timeout":10}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response DiscoverProxyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "Bearer token", authorization)
+	assert.Equal(t, storage.ProxyConfig{
+		Name:     "github-mcp",
+		Type:     storage.ProxyTypeStreamableHTTP,
+		URL:      upstream.URL,
+		AuthType: storage.ProxyAuthTypeHeader,
+		Timeout:  10,
+		Headers:  []storage.ProxyHeader{{Key: "Authorization", Value: "Bearer token"}},
+	}, response.Proxy)
+	assert.Equal(t, mcp.Implementation{Name: "GitHub MCP", Version: "2.3.0"}, response.ServerInfo)
+	assert.True(t, response.Stateful)
+	assert.Equal(t, []ToolSummary{summarizeTool(mcp.NewTool("search", mcp.WithDescription("Search the repositories"), mcp.WithString("query")))},
+		response.Tools)
+	assert.Equal(t, []string{
+		`a proxy named "github-mcp" already exists, saving this one replaces it`,
+		"the URL is not HTTPS: the requests and their credentials are not encrypted",
+	}, response.Warnings)
+
+	rec = request(`{"name":"github","url":"` + upstream.URL + `"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "github", response.Proxy.Name)
+
+	assert.Equal(t, http.StatusBadRequest, request(`{"url":"github.example.com/mcp"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, request(`{"url":"`+upstream.URL+`","type":"websocket"}`).Code)
+	assert.Equal(t, http.StatusBadGateway, request(`{"url":"http://127.0.0.1:1/mcp"}`).Code)
+	// The discovery does not change the gateway.
+	assert.Empty(t, ch)
+}
+
+func TestProxyNameOf(t *testing.T) {
+	assert.Equal(t, "github-mcp", proxyNameOf("GitHub MCP", "api.github.com"))
+	assert.Equal(t, "my_server-v2", proxyNameOf(" my_server v2! ", "api.github.com"))
+	assert.Equal(t, "api", proxyNameOf("", "api.github.com"))
+	assert.Equal(t, "api", proxyNameOf("???", "api.github.com"))
+}
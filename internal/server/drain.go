@@ -0,0 +1,189 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// shuttingDownErrorCode is the JSON-RPC error code of the requests rejected or aborted on shutdown.
+// It is in the range reserved for implementation-defined server errors.
+const shuttingDownErrorCode = -32000
+
+var errShuttingDown = errors.New("the gateway is shutting down")
+
+// drainer tracks the in-flight /mcp requests, so the shutdown can wait for them to complete.
+// Once draining, new requests are rejected; the requests still running at the drain deadline are aborted.
+type drainer struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+	cancels  map[uint64]context.CancelCauseFunc
+	next     uint64
+}
+
+func newDrainer() *drainer {
+	return &drainer{
+		cancels: make(map[uint64]context.CancelCauseFunc),
+	}
+}
+
+// track registers a request, returning false when the gateway is draining.
+func (d *drainer) track(cancel context.CancelCauseFunc) (uint64, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return 0, false
+	}
+	d.next++
+	d.cancels[d.next] = cancel
+	d.inFlight.Add(1)
+	return d.next, true
+}
+
+func (d *drainer) done(id uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.cancels, id)
+	d.inFlight.Done()
+}
+
+// drain rejects the new requests and waits for the in-flight ones until the context is done.
+// The requests still running are then aborted, and drain waits for them to return.
+func (d *drainer) drain(ctx context.Context) (aborted int) {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	completed := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(completed)
+	}()
+
+	select {
+	case <-completed:
+		return 0
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	aborted = len(d.cancels)
+	for _, cancel := range d.cancels {
+		cancel(errShuttingDown)
+	}
+	d.mu.Unlock()
+	<-completed
+	return aborted
+}
+
+// drainHandler tracks the /mcp requests for the graceful shutdown. While draining, the new requests are
+// rejected; the aborted ones get a JSON-RPC error if the handler did not answer them.
+func (s *Server) drainHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeBatchError(w, mcp.PARSE_ERROR, "failed to read the request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx, cancel := context.WithCancelCause(r.Context())
+		defer cancel(nil)
+		id, ok := s.drainer.track(cancel)
+		if !ok {
+			w.Header().Set("Connection", "close")
+			writeShuttingDown(w, http.StatusServiceUnavailable, body)
+			return
+		}
+		defer s.drainer.done(id)
+
+		tw := &trackingResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(tw, r.WithContext(ctx))
+		if !errors.Is(context.Cause(ctx), errShuttingDown) {
+			return
+		}
+		if !tw.wroteHeader {
+			writeShuttingDown(w, http.StatusOK, body)
+			return
+		}
+		// The response was already streamed as server-sent events: the error is sent as the last event.
+		if tw.Header().Get("Content-Type") == "text/event-stream" {
+			for _, message := range abortedErrors(body, errShuttingDown) {
+				data, _ := json.Marshal(message)
+				_, _ = w.Write([]byte("event: message\ndata: " + string(data) + "\n\n"))
+			}
+			tw.Flush()
+		}
+	})
+}
+
+// abortedErrors returns the errors of the aborted requests of the body, a single message or a batch.
+// The notifications have no error.
+func abortedErrors(body []byte, cause error) []mcp.JSONRPCError {
+	var messages []struct {
+		ID *mcp.RequestId `json:"id"`
+	}
+	if isBatch(body) {
+		_ = json.Unmarshal(body, &messages)
+	} else {
+		messages = make([]struct {
+			ID *mcp.RequestId `json:"id"`
+		}, 1)
+		_ = json.Unmarshal(body, &messages[0])
+	}
+
+	errs := make([]mcp.JSONRPCError, 0, len(messages))
+	for _, message := range messages {
+		if message.ID == nil {
+			continue
+		}
+		errs = append(errs, mcp.NewJSONRPCError(*message.ID, shuttingDownErrorCode, cause.Error(), nil))
+	}
+	return errs
+}
+
+// writeShuttingDown answers the requests of the body with a shutting down error.
+func writeShuttingDown(w http.ResponseWriter, code int, body []byte) {
+	errs := abortedErrors(body, errShuttingDown)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	switch {
+	case isBatch(body):
+		_ = json.NewEncoder(w).Encode(errs)
+	case len(errs) == 1:
+		_ = json.NewEncoder(w).Encode(errs[0])
+	}
+}
+
+// trackingResponseWriter records whether the response was started.
+type trackingResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *trackingResponseWriter) WriteHeader(code int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *trackingResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *trackingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *trackingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
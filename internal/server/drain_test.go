@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainHandler(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.drainer = newDrainer()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := srv.drainHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.RawQuery, "slow") {
+			close(started)
+			// The slow call only returns once aborted, like a canceled upstream call.
+			<-r.Context().Done()
+			return
+		}
+		<-release
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":2,"result":{}}`))
+	}))
+	serve := func(query, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp?"+query, strings.NewReader(body)))
+		return rec
+	}
+
+	slow := make(chan *httptest.ResponseRecorder)
+	go func() { slow <- serve("slow", `{"jsonrpc":"2.0","id":1,"method":"tools/call"}`) }()
+	fast := make(chan *httptest.ResponseRecorder)
+	go func() { fast <- serve("", `{"jsonrpc":"2.0","id":2,"method":"tools/call"}`) }()
+	<-started
+	require.Eventually(t, func() bool {
+		srv.drainer.mu.Lock()
+		defer srv.drainer.mu.Unlock()
+		return len(srv.drainer.cancels) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	drained := make(chan int)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		drained <- srv.drainer.drain(ctx)
+	}()
+
+	// Once draining, the new requests are rejected.
+	require.Eventually(t, func() bool {
+		return serve("", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`).Code == http.StatusServiceUnavailable
+	}, time.Second, 10*time.Millisecond)
+	rejected := serve("", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":3,"error":{"code":-32000,"message":"the gateway is shutting down"}}`, rejected.Body.String())
+
+	// The call completing before the drain deadline is answered normally.
+	close(release)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{}}`, (<-fast).Body.String())
+
+	// The call still running at the deadline is aborted with a JSON-RPC error.
+	aborted := <-slow
+	assert.Equal(t, http.StatusOK, aborted.Code)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"the gateway is shutting down"}}`, aborted.Body.String())
+	assert.Equal(t, 1, <-drained)
+}
+
+func TestDrainer_NoInFlightRequests(t *testing.T) {
+	d := newDrainer()
+	assert.Equal(t, 0, d.drain(context.Background()))
+	_, ok := d.track(func(error) {})
+	assert.False(t, ok)
+}
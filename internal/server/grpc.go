@@ -0,0 +1,319 @@
+package server
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	adminv1 "github.com/matthisholleville/mcp-gateway/pkg/api/admin/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// adminGRPCServer exposes the admin API over gRPC.
+type adminGRPCServer struct {
+	adminv1.UnimplementedAdminServiceServer
+	s *Server
+}
+
+// configureGRPC configures the gRPC management API, if enabled.
+func (s *Server) configureGRPC() {
+	if !s.Config.GRPC.Enabled {
+		return
+	}
+	s.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(s.grpcAdminInterceptor))
+	adminv1.RegisterAdminServiceServer(s.grpcServer, &adminGRPCServer{s: s})
+}
+
+// serveGRPC starts the gRPC management API in the background.
+func (s *Server) serveGRPC() error {
+	if s.grpcServer == nil {
+		return nil
+	}
+	listener, err := net.Listen("tcp", s.Config.GRPC.Addr)
+	if err != nil {
+		return err
+	}
+	s.Logger.Info("Starting gRPC server", zap.String("host", s.Config.GRPC.Addr))
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			s.Logger.Error("gRPC server stopped", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// grpcAdminInterceptor applies the admin IP access list and API key to the gRPC calls, and publishes the
+// admin mutation events.
+func (s *Server) grpcAdminInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if s.adminIPAccess != nil && !s.adminIPAccess.isEmpty() {
+		p, ok := peer.FromContext(ctx)
+		var ip net.IP
+		if ok {
+			if addr, ok := p.Addr.(*net.TCPAddr); ok {
+				ip = addr.IP
+			}
+		}
+		if !s.adminIPAccess.isAllowed(ip) {
+			s.Logger.Warn("Rejected gRPC call from a non-allowed IP",
+				zap.Any("client_ip", ip),
+				zap.String("method", info.FullMethod))
+			return nil, status.Error(codes.PermissionDenied, "Forbidden")
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	apiKeys := md.Get("x-api-key")
+	if len(apiKeys) == 0 || !s.isAdminAPIKey(apiKeys[0]) {
+		return nil, status.Error(codes.Unauthenticated, "Invalid API key")
+	}
+	resp, err := handler(ctx, req)
+	if err == nil {
+		s.publishGRPCMutation(ctx, info.FullMethod)
+	}
+	return resp, err
+}
+
+func (g *adminGRPCServer) ListProxies(ctx context.Context, _ *adminv1.ListProxiesRequest) (*adminv1.ListProxiesResponse, error) {
+	proxies, err := g.s.Storage.ListProxies(ctx, false)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	out := make([]*adminv1.Proxy, 0, len(proxies))
+	for i := range proxies {
+		out = append(out, proxyToProto(&proxies[i]))
+	}
+	return &adminv1.ListProxiesResponse{Proxies: out}, nil
+}
+
+func (g *adminGRPCServer) GetProxy(ctx context.Context, req *adminv1.GetProxyRequest) (*adminv1.Proxy, error) {
+	proxy, err := g.s.Storage.GetProxy(ctx, req.GetName(), false)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return proxyToProto(&proxy), nil
+}
+
+func (g *adminGRPCServer) UpsertProxy(ctx context.Context, req *adminv1.UpsertProxyRequest) (*adminv1.Proxy, error) {
+	if req.GetProxy().GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "proxy name is required")
+	}
+	proxy := proxyFromProto(req.GetProxy())
+	if err := g.s.Storage.SetProxy(ctx, proxy, true); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return req.GetProxy(), nil
+}
+
+func (g *adminGRPCServer) DeleteProxy(ctx context.Context, req *adminv1.DeleteProxyRequest) (*adminv1.DeleteProxyResponse, error) {
+	if err := g.s.Storage.DeleteProxy(ctx, req.GetName()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &adminv1.DeleteProxyResponse{}, nil
+}
+
+func (g *adminGRPCServer) ListRoles(ctx context.Context, _ *adminv1.ListRolesRequest) (*adminv1.ListRolesResponse, error) {
+	roles, err := g.s.Storage.ListRoles(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	out := make([]*adminv1.Role, 0, len(roles))
+	for _, role := range roles {
+		out = append(out, roleToProto(role))
+	}
+	return &adminv1.ListRolesResponse{Roles: out}, nil
+}
+
+func (g *adminGRPCServer) UpsertRole(ctx context.Context, req *adminv1.UpsertRoleRequest) (*adminv1.Role, error) {
+	if req.GetRole().GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "role name is required")
+	}
+	role := roleFromProto(req.GetRole())
+	// The clearance is not part of the gRPC API, keep the stored one
+	if existing, err := g.s.Storage.GetRole(ctx, role.Name); err == nil {
+		role.Clearance = existing.Clearance
+	}
+	if err := g.s.Storage.SetRole(ctx, role); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return req.GetRole(), nil
+}
+
+func (g *adminGRPCServer) DeleteRole(ctx context.Context, req *adminv1.DeleteRoleRequest) (*adminv1.DeleteRoleResponse, error) {
+	if err := g.s.Storage.DeleteRole(ctx, req.GetName()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &adminv1.DeleteRoleResponse{}, nil
+}
+
+func (g *adminGRPCServer) ListAttributeToRoles(
+	ctx context.Context,
+	_ *adminv1.ListAttributeToRolesRequest,
+) (*adminv1.ListAttributeToRolesResponse, error) {
+	attributeToRoles, err := g.s.Storage.ListAttributeToRoles(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	out := make([]*adminv1.AttributeToRoles, 0, len(attributeToRoles))
+	for _, at := range attributeToRoles {
+		out = append(out, &adminv1.AttributeToRoles{
+			AttributeKey:   at.AttributeKey,
+			AttributeValue: at.AttributeValue,
+			Roles:          at.Roles,
+		})
+	}
+	return &adminv1.ListAttributeToRolesResponse{AttributeToRoles: out}, nil
+}
+
+func (g *adminGRPCServer) UpsertAttributeToRoles(
+	ctx context.Context,
+	req *adminv1.UpsertAttributeToRolesRequest,
+) (*adminv1.AttributeToRoles, error) {
+	at := req.GetAttributeToRoles()
+	if at.GetAttributeKey() == "" || at.GetAttributeValue() == "" {
+		return nil, status.Error(codes.InvalidArgument, "attribute key and attribute value are required")
+	}
+	if err := g.s.Storage.SetAttributeToRoles(ctx, storage.AttributeToRolesConfig{
+		AttributeKey:   at.GetAttributeKey(),
+		AttributeValue: at.GetAttributeValue(),
+		Roles:          at.GetRoles(),
+	}); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return at, nil
+}
+
+func (g *adminGRPCServer) DeleteAttributeToRoles(
+	ctx context.Context,
+	req *adminv1.DeleteAttributeToRolesRequest,
+) (*adminv1.DeleteAttributeToRolesResponse, error) {
+	if req.GetAttributeKey() == "" || req.GetAttributeValue() == "" {
+		return nil, status.Error(codes.InvalidArgument, "attribute key and attribute value are required")
+	}
+	if err := g.s.Storage.DeleteAttributeToRoles(ctx, req.GetAttributeKey(), req.GetAttributeValue()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &adminv1.DeleteAttributeToRolesResponse{}, nil
+}
+
+func (g *adminGRPCServer) GetStatus(ctx context.Context, _ *adminv1.GetStatusRequest) (*adminv1.Status, error) {
+	proxies, err := g.s.Storage.ListProxies(ctx, false)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	out := &adminv1.Status{
+		Live:    g.s.Live != nil && atomic.LoadInt32(g.s.Live) == 1,
+		Ready:   g.s.ready(),
+		Proxies: make([]*adminv1.ProxyStatus, 0, len(proxies)),
+	}
+	for _, proxy := range proxies {
+		proxyStatus := &adminv1.ProxyStatus{Name: proxy.Name}
+		if registered, ok := g.s.tools.get(proxy.Name); ok {
+			proxyStatus.Synced = true
+			proxyStatus.Tools = int32(len(registered.Tools)) //nolint:gosec // the number of tools is small
+		}
+		out.Proxies = append(out.Proxies, proxyStatus)
+	}
+	return out, nil
+}
+
+func proxyToProto(proxy *storage.ProxyConfig) *adminv1.Proxy {
+	out := &adminv1.Proxy{
+		Name:               proxy.Name,
+		Type:               string(proxy.Type),
+		Url:                proxy.URL,
+		Timeout:            durationpb.New(proxy.Timeout),
+		AuthType:           string(proxy.AuthType),
+		Labels:             proxy.Labels,
+		Description:        proxy.Description,
+		Owner:              proxy.Owner,
+		DocsUrl:            proxy.DocsURL,
+		EgressProxy:        proxy.EgressProxy,
+		MaxResponseBytes:   int64(proxy.MaxResponseBytes),
+		ResponseSizePolicy: proxy.ResponseSizePolicy,
+		KeepaliveInterval:  durationpb.New(proxy.KeepaliveInterval),
+	}
+	for _, header := range proxy.Headers {
+		out.Headers = append(out.Headers, &adminv1.ProxyHeader{Key: header.Key, Value: header.Value})
+	}
+	if proxy.OAuth != nil {
+		out.Oauth = &adminv1.ProxyOAuth{
+			ClientId:      proxy.OAuth.ClientID,
+			ClientSecret:  proxy.OAuth.ClientSecret,
+			TokenEndpoint: proxy.OAuth.TokenEndpoint,
+			Scopes:        proxy.OAuth.Scopes,
+		}
+	}
+	if proxy.HMAC != nil {
+		out.Hmac = &adminv1.ProxyHMAC{Secret: proxy.HMAC.Secret}
+	}
+	return out
+}
+
+func proxyFromProto(proxy *adminv1.Proxy) *storage.ProxyConfig {
+	out := &storage.ProxyConfig{
+		Name:               proxy.GetName(),
+		Type:               storage.ProxyType(proxy.GetType()),
+		URL:                proxy.GetUrl(),
+		Timeout:            proxy.GetTimeout().AsDuration(),
+		AuthType:           storage.ProxyAuthType(proxy.GetAuthType()),
+		Labels:             proxy.GetLabels(),
+		Description:        proxy.GetDescription(),
+		Owner:              proxy.GetOwner(),
+		DocsURL:            proxy.GetDocsUrl(),
+		EgressProxy:        proxy.GetEgressProxy(),
+		MaxResponseBytes:   int(proxy.GetMaxResponseBytes()),
+		ResponseSizePolicy: proxy.GetResponseSizePolicy(),
+		KeepaliveInterval:  proxy.GetKeepaliveInterval().AsDuration(),
+	}
+	for _, header := range proxy.GetHeaders() {
+		out.Headers = append(out.Headers, storage.ProxyHeader{Key: header.GetKey(), Value: header.GetValue()})
+	}
+	if oauth := proxy.GetOauth(); oauth != nil {
+		out.OAuth = &storage.ProxyOAuth{
+			ClientID:      oauth.GetClientId(),
+			ClientSecret:  oauth.GetClientSecret(),
+			TokenEndpoint: oauth.GetTokenEndpoint(),
+			Scopes:        oauth.GetScopes(),
+		}
+	}
+	if hmac := proxy.GetHmac(); hmac != nil {
+		out.HMAC = &storage.ProxyHMAC{Secret: hmac.GetSecret()}
+	}
+	return out
+}
+
+func roleToProto(role storage.RoleConfig) *adminv1.Role {
+	out := &adminv1.Role{Name: role.Name}
+	for _, permission := range role.Permissions {
+		out.Permissions = append(out.Permissions, &adminv1.Permission{
+			ObjectType: string(permission.ObjectType),
+			Proxy:      permission.Proxy,
+			ObjectName: permission.ObjectName,
+		})
+	}
+	return out
+}
+
+func roleFromProto(role *adminv1.Role) storage.RoleConfig {
+	out := storage.RoleConfig{Name: role.GetName()}
+	for _, permission := range role.GetPermissions() {
+		out.Permissions = append(out.Permissions, storage.PermissionConfig{
+			ObjectType: storage.ObjectType(permission.GetObjectType()),
+			Proxy:      permission.GetProxy(),
+			ObjectName: permission.GetObjectName(),
+		})
+	}
+	return out
+}
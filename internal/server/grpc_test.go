@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	adminv1 "github.com/matthisholleville/mcp-gateway/pkg/api/admin/v1"
+	"github.com/matthisholleville/mcp-gateway/pkg/apikey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// newTestGRPCClient serves the gRPC admin API of the server in memory and returns a client.
+func newTestGRPCClient(t *testing.T, srv *Server) adminv1.AdminServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	srv.configureGRPC()
+	go func() {
+		_ = srv.grpcServer.Serve(listener)
+	}()
+	t.Cleanup(srv.grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return adminv1.NewAdminServiceClient(conn)
+}
+
+func TestGRPCAdminService(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config.HTTP = &cfg.HTTPConfig{AdminAPIKey: "admin"}
+	srv.Config.GRPC = &cfg.GRPCConfig{Enabled: true}
+	srv.Storage = storage.NewMemoryStorage("")
+	srv.tools = newToolRegistry()
+	srv.tools.set("github", []server.ServerTool{{Tool: mcp.NewTool("github:search")}}, time.Now())
+	client := newTestGRPCClient(t, srv)
+
+	_, err := client.ListProxies(t.Context(), &adminv1.ListProxiesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(t.Context(), "x-api-key", "admin")
+
+	_, err = client.UpsertProxy(ctx, &adminv1.UpsertProxyRequest{Proxy: &adminv1.Proxy{
+		Name:               "github",
+		Type:               string(storage.ProxyTypeStreamableHTTP),
+		Url:                "https://example.com/mcp",
+		Timeout:            durationpb.New(10 * time.Second),
+		AuthType:           string(storage.ProxyAuthTypeHeader),
+		Headers:            []*adminv1.ProxyHeader{{Key: "Authorization", Value: "token"}},
+		Labels:             map[string]string{"team": "platform"},
+		Description:        "Search the repositories",
+		Owner:              "platform@example.com",
+		DocsUrl:            "https://docs.example.com/github",
+		EgressProxy:        "direct",
+		MaxResponseBytes:   1 << 20,
+		ResponseSizePolicy: cfg.ResponseSizePolicyReject,
+		KeepaliveInterval:  durationpb.New(time.Minute),
+	}})
+	require.NoError(t, err)
+
+	proxy, err := client.GetProxy(ctx, &adminv1.GetProxyRequest{Name: "github"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/mcp", proxy.GetUrl())
+	assert.Equal(t, 10*time.Second, proxy.GetTimeout().AsDuration())
+	assert.Len(t, proxy.GetHeaders(), 1)
+	assert.Equal(t, map[string]string{"team": "platform"}, proxy.GetLabels())
+	assert.Equal(t, "Search the repositories", proxy.GetDescription())
+	assert.Equal(t, "platform@example.com", proxy.GetOwner())
+	assert.Equal(t, "https://docs.example.com/github", proxy.GetDocsUrl())
+	assert.Equal(t, "direct", proxy.GetEgressProxy())
+	assert.Equal(t, int64(1<<20), proxy.GetMaxResponseBytes())
+	assert.Equal(t, cfg.ResponseSizePolicyReject, proxy.GetResponseSizePolicy())
+	assert.Equal(t, time.Minute, proxy.GetKeepaliveInterval().AsDuration())
+
+	_, err = client.UpsertProxy(ctx, &adminv1.UpsertProxyRequest{Proxy: &adminv1.Proxy{
+		Name:     "signed",
+		Type:     string(storage.ProxyTypeStreamableHTTP),
+		Url:      "https://example.com/mcp",
+		AuthType: string(storage.ProxyAuthTypeHMAC),
+		Hmac:     &adminv1.ProxyHMAC{Secret: "shared"},
+	}})
+	require.NoError(t, err)
+	proxy, err = client.GetProxy(ctx, &adminv1.GetProxyRequest{Name: "signed"})
+	require.NoError(t, err)
+	assert.Equal(t, "shared", proxy.GetHmac().GetSecret())
+	_, err = client.DeleteProxy(ctx, &adminv1.DeleteProxyRequest{Name: "signed"})
+	require.NoError(t, err)
+
+	_, err = client.UpsertProxy(ctx, &adminv1.UpsertProxyRequest{Proxy: &adminv1.Proxy{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.UpsertRole(ctx, &adminv1.UpsertRoleRequest{Role: &adminv1.Role{
+		Name:        "reader",
+		Permissions: []*adminv1.Permission{{ObjectType: "tools", Proxy: "github", ObjectName: "*"}},
+	}})
+	require.NoError(t, err)
+	roles, err := client.ListRoles(ctx, &adminv1.ListRolesRequest{})
+	require.NoError(t, err)
+	assert.Len(t, roles.GetRoles(), 1)
+
+	statusResponse, err := client.GetStatus(ctx, &adminv1.GetStatusRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []*adminv1.ProxyStatus{{Name: "github", Tools: 1, Synced: true}}, statusResponse.GetProxies())
+}
+
+func TestGRPCAdminAPIKeyHash(t *testing.T) {
+	hash, err := apikey.Hash("admin")
+	require.NoError(t, err)
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config.HTTP = &cfg.HTTPConfig{AdminAPIKey: "change-me", AdminAPIKeyHash: hash}
+	srv.Config.GRPC = &cfg.GRPCConfig{Enabled: true}
+	srv.Storage = storage.NewMemoryStorage("")
+	client := newTestGRPCClient(t, srv)
+
+	ctx := metadata.AppendToOutgoingContext(t.Context(), "x-api-key", "change-me")
+	_, err = client.ListProxies(ctx, &adminv1.ListProxiesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "the admin API key must be ignored when its hash is set")
+
+	ctx = metadata.AppendToOutgoingContext(t.Context(), "x-api-key", "admin")
+	_, err = client.ListProxies(ctx, &adminv1.ListProxiesRequest{})
+	require.NoError(t, err)
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/guardrail"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// configureGuardrail configures the external policy service checking the tool calls and their results
+func (s *Server) configureGuardrail() {
+	if s.Config.Guardrail.URL == "" {
+		return
+	}
+	s.guardrail = guardrail.New(s.Config.Guardrail)
+}
+
+// guardrailHandler submits the tool calls to the policy service before they are forwarded upstream, and their
+// results before they are returned, applying its allow, deny or transform verdicts.
+func (s *Server) guardrailHandler(proxyName, toolName string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.guardrail == nil {
+			return next(ctx, request)
+		}
+		claims, _ := ctx.Value("claims").(map[string]interface{})
+		check := guardrail.Request{Proxy: proxyName, Tool: toolName, Arguments: request.GetArguments(), Claims: claims}
+
+		if s.guardrail.Checks(cfg.GuardrailStageRequest, proxyName) {
+			check.Stage = cfg.GuardrailStageRequest
+			verdict, ok := s.checkGuardrail(ctx, check)
+			switch {
+			case !ok:
+				return mcp.NewToolResultError("Unable to check the tool call with the guardrail"), nil
+			case verdict.Verdict == guardrail.DecisionDeny:
+				return mcp.NewToolResultError(guardrailDenied("Tool call", verdict.Reason)), nil
+			case verdict.Verdict == guardrail.DecisionTransform:
+				request.Params.Arguments = verdict.Arguments
+				check.Arguments = verdict.Arguments
+			}
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil || !s.guardrail.Checks(cfg.GuardrailStageResponse, proxyName) {
+			return result, err
+		}
+		check.Stage = cfg.GuardrailStageResponse
+		check.Result = result
+		verdict, ok := s.checkGuardrail(ctx, check)
+		switch {
+		case !ok:
+			return mcp.NewToolResultError("Unable to check the tool result with the guardrail"), nil
+		case verdict.Verdict == guardrail.DecisionDeny:
+			return mcp.NewToolResultError(guardrailDenied("Tool result", verdict.Reason)), nil
+		case verdict.Verdict == guardrail.DecisionTransform:
+			return verdict.TransformedResult(), nil
+		}
+		return result, nil
+	}
+}
+
+// checkGuardrail returns the verdict of the policy service. When it fails, the call goes on with an allow
+// verdict if the guardrail fails open, and false is returned otherwise.
+func (s *Server) checkGuardrail(ctx context.Context, check guardrail.Request) (guardrail.Verdict, bool) {
+	verdict, err := s.guardrail.Check(ctx, check)
+	if err != nil {
+		metrics.GuardrailVerdictsCounter.WithLabelValues(check.Proxy, check.Stage, "error").Inc()
+		fields := []zap.Field{zap.String("proxy", check.Proxy), zap.String("tool", check.Tool), zap.String("stage", check.Stage), zap.Error(err)}
+		if s.guardrail.FailOpen() {
+			s.Logger.Warn("Failed to check the tool call with the guardrail, going on", fields...)
+			return guardrail.Verdict{Verdict: guardrail.DecisionAllow}, true
+		}
+		s.Logger.Error("Failed to check the tool call with the guardrail", fields...)
+		return guardrail.Verdict{}, false
+	}
+	metrics.GuardrailVerdictsCounter.WithLabelValues(check.Proxy, check.Stage, string(verdict.Verdict)).Inc()
+	if verdict.Verdict != guardrail.DecisionAllow {
+		s.Logger.Info("Guardrail verdict applied to the tool call",
+			zap.String("proxy", check.Proxy),
+			zap.String("tool", check.Tool),
+			zap.String("stage", check.Stage),
+			zap.String("verdict", string(verdict.Verdict)),
+			zap.String("reason", verdict.Reason))
+	}
+	return verdict, true
+}
+
+func guardrailDenied(subject, reason string) string {
+	message := subject + " denied by the guardrail"
+	if reason != "" {
+		message += ": " + reason
+	}
+	return message
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/guardrail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardrailHandler(t *testing.T) {
+	verdicts := map[string]string{}
+	policy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := guardrail.Request{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		answer, ok := verdicts[request.Stage]
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(answer))
+	}))
+	defer policy.Close()
+
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config.Guardrail = &cfg.GuardrailConfig{
+		URL:     policy.URL,
+		Stages:  []string{cfg.GuardrailStageRequest, cfg.GuardrailStageResponse},
+		Timeout: time.Second,
+	}
+	srv.configureGuardrail()
+	var forwarded map[string]any
+	handler := srv.guardrailHandler("github", "search", func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		forwarded = request.GetArguments()
+		return mcp.NewToolResultText("result"), nil
+	})
+	call := func() *mcp.CallToolResult {
+		forwarded = nil
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"query": "mcp"}
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	verdicts[cfg.GuardrailStageRequest] = `{"verdict":"allow"}`
+	verdicts[cfg.GuardrailStageResponse] = `{"verdict":"allow"}`
+	result := call()
+	assert.Equal(t, "result", text(result))
+	assert.Equal(t, map[string]any{"query": "mcp"}, forwarded)
+
+	verdicts[cfg.GuardrailStageRequest] = `{"verdict":"deny","reason":"unsafe query"}`
+	result = call()
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Tool call denied by the guardrail: unsafe query", text(result))
+	assert.Nil(t, forwarded)
+
+	verdicts[cfg.GuardrailStageRequest] = `{"verdict":"transform","arguments":{"query":"mcp","limit":10}}`
+	verdicts[cfg.GuardrailStageResponse] = `{"verdict":"transform","result":{"content":[{"type":"text","text":"sanitized"}]}}`
+	result = call()
+	assert.Equal(t, map[string]any{"query": "mcp", "limit": float64(10)}, forwarded)
+	assert.Equal(t, "sanitized", text(result))
+
+	verdicts[cfg.GuardrailStageResponse] = `{"verdict":"deny"}`
+	result = call()
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Tool result denied by the guardrail", text(result))
+
+	// The calls are rejected when the policy service fails, unless the guardrail fails open
+	delete(verdicts, cfg.GuardrailStageRequest)
+	result = call()
+	assert.True(t, result.IsError)
+	assert.Nil(t, forwarded)
+	srv.Config.Guardrail.FailOpen = true
+	srv.configureGuardrail()
+	verdicts[cfg.GuardrailStageResponse] = `{"verdict":"allow"}`
+	result = call()
+	assert.False(t, result.IsError)
+	assert.Equal(t, map[string]any{"query": "mcp"}, forwarded)
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ipAccessList decides whether a client IP may reach a route.
+// Denied networks always win; an empty allow list allows every other address.
+type ipAccessList struct {
+	allowed []*net.IPNet
+	denied  []*net.IPNet
+}
+
+// newIPAccessList parses the allowed and denied CIDRs. Plain IPs are accepted as single-host networks.
+func newIPAccessList(allowed, denied []string) (*ipAccessList, error) {
+	allowedNets, err := parseCIDRs(allowed)
+	if err != nil {
+		return nil, err
+	}
+	deniedNets, err := parseCIDRs(denied)
+	if err != nil {
+		return nil, err
+	}
+	return &ipAccessList{allowed: allowedNets, denied: deniedNets}, nil
+}
+
+func parseCIDRs(values []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", value)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				bits = 32
+			}
+			value = fmt.Sprintf("%s/%d", value, bits)
+		}
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", value, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// isEmpty reports whether the list restricts nothing.
+func (l *ipAccessList) isEmpty() bool {
+	return len(l.allowed) == 0 && len(l.denied) == 0
+}
+
+// isAllowed reports whether the IP may access the route.
+func (l *ipAccessList) isAllowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range l.denied {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(l.allowed) == 0 {
+		return true
+	}
+	for _, n := range l.allowed {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// middleware rejects the requests coming from a client IP outside the access list.
+func (l *ipAccessList) middleware(log func(msg string, fields ...zap.Field)) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			clientIP := c.RealIP()
+			if !l.isAllowed(net.ParseIP(clientIP)) {
+				log("Rejected request from a non-allowed IP",
+					zap.String("client_ip", clientIP),
+					zap.String("path", c.Request().URL.Path))
+				return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+			}
+			return next(c)
+		}
+	}
+}
+
+// newIPExtractor returns how the client IP is derived from a request. The forwarded headers are only
+// honored when the request comes from a trusted proxy, and X-Forwarded-For wins over X-Real-IP.
+func newIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	trusted, err := parseCIDRs(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	if len(trusted) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, n := range trusted {
+		options = append(options, echo.TrustIPRange(n))
+	}
+	fromXFF := echo.ExtractIPFromXFFHeader(options...)
+	fromRealIP := echo.ExtractIPFromRealIPHeader(options...)
+	return func(req *http.Request) string {
+		if req.Header.Get(echo.HeaderXForwardedFor) != "" {
+			return fromXFF(req)
+		}
+		return fromRealIP(req)
+	}, nil
+}
+
+// configureClientIP configures how the client IP is derived, for the logs and the IP access lists.
+func (s *Server) configureClientIP() {
+	extractor, err := newIPExtractor(s.Config.HTTP.TrustedProxies)
+	if err != nil {
+		s.Logger.Error("Failed to parse the trusted proxies", zap.Error(err))
+		panic(err)
+	}
+	s.Router.IPExtractor = extractor
+}
+
+// configureAdminIPAccess builds the IP access list protecting the admin routes.
+func (s *Server) configureAdminIPAccess() {
+	accessCfg := s.Config.HTTP.AdminIPAccess
+	list, err := newIPAccessList(accessCfg.AllowedCIDRs, accessCfg.DeniedCIDRs)
+	if err != nil {
+		s.Logger.Error("Failed to parse the admin IP access list", zap.Error(err))
+		panic(err)
+	}
+	s.adminIPAccess = list
+}
+
+// adminIPAccessMiddlewares returns the IP access middleware for a route, if the route is protected.
+func (s *Server) adminIPAccessMiddlewares(protected bool) []echo.MiddlewareFunc {
+	if !protected || s.adminIPAccess == nil || s.adminIPAccess.isEmpty() {
+		return nil
+	}
+	return []echo.MiddlewareFunc{s.adminIPAccess.middleware(s.Logger.Warn)}
+}
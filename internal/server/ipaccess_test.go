@@ -0,0 +1,104 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIPAccessList_IsAllowed(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		allowed  []string
+		denied   []string
+		ip       string
+		expected bool
+	}{
+		{name: "empty list allows everything", ip: "203.0.113.7", expected: true},
+		{name: "allowed network", allowed: []string{"10.0.0.0/8"}, ip: "10.1.2.3", expected: true},
+		{name: "outside allowed network", allowed: []string{"10.0.0.0/8"}, ip: "192.168.1.1", expected: false},
+		{name: "plain allowed IP", allowed: []string{"192.168.1.1"}, ip: "192.168.1.1", expected: true},
+		{name: "denied wins over allowed", allowed: []string{"10.0.0.0/8"}, denied: []string{"10.0.0.0/24"}, ip: "10.0.0.5", expected: false},
+		{name: "denied only", denied: []string{"203.0.113.0/24"}, ip: "203.0.113.7", expected: false},
+		{name: "ipv6", allowed: []string{"2001:db8::/32"}, ip: "2001:db8::1", expected: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			list, err := newIPAccessList(test.allowed, test.denied)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, list.isAllowed(net.ParseIP(test.ip)))
+		})
+	}
+}
+
+func TestNewIPAccessList_Invalid(t *testing.T) {
+	_, err := newIPAccessList([]string{"not-an-ip"}, nil)
+	assert.Error(t, err)
+	_, err = newIPAccessList(nil, []string{"10.0.0.0/99"})
+	assert.Error(t, err)
+}
+
+func TestIPAccessList_Middleware(t *testing.T) {
+	list, err := newIPAccessList([]string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+
+	e := echo.New()
+	handler := list.middleware(func(string, ...zap.Field) {})(func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/proxies", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	rec := httptest.NewRecorder()
+	assert.NoError(t, handler(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/admin/proxies", nil)
+	req.RemoteAddr = "192.168.1.1:1234"
+	err = handler(e.NewContext(req, httptest.NewRecorder()))
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, httpErr.Code)
+}
+
+func TestNewIPExtractor(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		trusted    []string
+		remoteAddr string
+		headers    map[string]string
+		expected   string
+	}{
+		{name: "no trusted proxy ignores the headers", remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.7", "X-Real-IP": "203.0.113.8"}, expected: "10.0.0.1"},
+		{name: "forwarded by a trusted proxy", trusted: []string{"10.0.0.0/24"}, remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.7"}, expected: "203.0.113.7"},
+		{name: "spoofed hop before the trusted proxies", trusted: []string{"10.0.0.0/24"}, remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 10.0.0.2"}, expected: "203.0.113.7"},
+		{name: "forwarded by an untrusted proxy", trusted: []string{"10.0.0.0/24"}, remoteAddr: "192.168.1.1:1234",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.7"}, expected: "192.168.1.1"},
+		{name: "real IP from a trusted proxy", trusted: []string{"10.0.0.1"}, remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{"X-Real-IP": "203.0.113.8"}, expected: "203.0.113.8"},
+		{name: "real IP from an untrusted proxy", trusted: []string{"10.0.0.1"}, remoteAddr: "10.0.0.2:1234",
+			headers: map[string]string{"X-Real-IP": "203.0.113.8"}, expected: "10.0.0.2"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			extractor, err := newIPExtractor(test.trusted)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin/proxies", nil)
+			req.RemoteAddr = test.remoteAddr
+			for key, value := range test.headers {
+				req.Header.Set(key, value)
+			}
+			assert.Equal(t, test.expected, extractor(req))
+		})
+	}
+
+	_, err := newIPExtractor([]string{"not-a-network"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// keepaliveOptions returns the options of the MCP server pinging the /mcp streams of the clients, if enabled.
+func (s *Server) keepaliveOptions() []server.StreamableHTTPOption {
+	timeouts := s.Config.HTTP.Timeouts
+	if timeouts == nil || timeouts.Keepalive <= 0 {
+		return nil
+	}
+	return []server.StreamableHTTPOption{server.WithHeartbeatInterval(timeouts.Keepalive)}
+}
+
+// clientStreamHandler tracks the /mcp streams the clients open to listen to the server messages, logging and
+// counting their disconnections.
+func (s *Server) clientStreamHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startedAt := time.Now()
+		tw := &trackingResponseWriter{ResponseWriter: w}
+		metrics.ClientStreamsGauge.Inc()
+		next.ServeHTTP(tw, r)
+		metrics.ClientStreamsGauge.Dec()
+		// The requests rejected before streaming are not streams.
+		if tw.Header().Get("Content-Type") != "text/event-stream" {
+			return
+		}
+
+		reason := "client"
+		if errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+			reason = "timeout"
+		}
+		metrics.ClientStreamsClosedCounter.WithLabelValues(reason).Inc()
+		s.Logger.Info("Client stream closed",
+			zap.String("session_id", r.Header.Get(server.HeaderKeySessionID)),
+			zap.String("reason", reason),
+			zap.Duration("duration", time.Since(startedAt)))
+	})
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientStreamKeepalive(t *testing.T) {
+	config := cfg.DefaultConfig()
+	config.HTTP.Timeouts.Keepalive = 20 * time.Millisecond
+	s := &Server{Logger: logger.MustNewLogger("json", "error", "test"), Config: config}
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	options := append([]server.StreamableHTTPOption{server.WithStateLess(true)}, s.keepaliveOptions()...)
+	gateway := httptest.NewServer(s.clientStreamHandler(server.NewStreamableHTTPServer(mcpServer, options...)))
+	defer gateway.Close()
+	closed := testutil.ToFloat64(metrics.ClientStreamsClosedCounter.WithLabelValues("client"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gateway.URL, http.NoBody)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ClientStreamsGauge))
+
+	scanner := bufio.NewScanner(resp.Body)
+	pinged := false
+	for !pinged && scanner.Scan() {
+		pinged = strings.HasPrefix(scanner.Text(), "data:") && strings.Contains(scanner.Text(), `"method":"ping"`)
+	}
+	assert.True(t, pinged, "the idle stream is pinged")
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.ClientStreamsClosedCounter.WithLabelValues("client")) == closed+1
+	}, 5*time.Second, 10*time.Millisecond, "the disconnection is counted")
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.ClientStreamsGauge))
+}
+
+func TestKeepaliveOptions(t *testing.T) {
+	config := cfg.DefaultConfig()
+	s := &Server{Config: config}
+	assert.Len(t, s.keepaliveOptions(), 1)
+	config.HTTP.Timeouts.Keepalive = 0
+	assert.Empty(t, s.keepaliveOptions(), "0 disables the pings")
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/proxy"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
+)
+
+// leadershipTimeout bounds the calls to the storage electing the leader of the proxy sync loop and sharing the
+// tools.
+const leadershipTimeout = 5 * time.Second
+
+// sharedProxy is a proxy of a replica following the proxy sync loop. It connects to its upstream server on its
+// first call and is kept as long as its config is unchanged.
+type sharedProxy interface {
+	callProxy
+	resourceProxy
+	completionProxy
+}
+
+type followerProxy struct {
+	config storage.ProxyConfig
+	proxy  sharedProxy
+}
+
+// leadsProxySync returns whether the replica lists the tools of the upstream servers, always true without leader
+// election. A replica failing to reach the storage follows, as it would not be able to share the tools.
+func (s *Server) leadsProxySync() bool {
+	if !s.Config.Proxy.LeaderElection {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), leadershipTimeout)
+	defer cancel()
+	leader, err := s.Storage.TryLeadership(ctx, storage.ProxySyncLeadership)
+	if err != nil {
+		s.Logger.Error("Failed to elect the leader of the proxy sync", zap.Error(err))
+		leader = false
+	}
+	if leader != s.proxySyncLeader {
+		if leader {
+			s.Logger.Info("Leading the proxy sync, listing the tools of the upstream servers")
+		} else {
+			s.Logger.Info("Following the proxy sync, reading the tools listed by the leader")
+		}
+		s.proxySyncLeader = leader
+	}
+	return leader
+}
+
+// shareProxyTools stores the tools listed by the leader of the proxy sync loop, read by the other replicas.
+func (s *Server) shareProxyTools(proxyName string, tools []mcp.Tool, templates []mcp.ResourceTemplate) {
+	if !s.Config.Proxy.LeaderElection {
+		return
+	}
+	shared := storage.ProxyTools{Proxy: proxyName, SyncedAt: time.Now()}
+	var err error
+	if shared.Tools, err = json.Marshal(tools); err != nil {
+		s.Logger.Error("Failed to share MCP proxy tools", zap.String("proxy", proxyName), zap.Error(err))
+		return
+	}
+	if len(templates) > 0 {
+		if shared.ResourceTemplates, err = json.Marshal(templates); err != nil {
+			s.Logger.Error("Failed to share MCP proxy tools", zap.String("proxy", proxyName), zap.Error(err))
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), leadershipTimeout)
+	defer cancel()
+	if err := s.Storage.SetProxyTools(ctx, shared); err != nil {
+		s.Logger.Error("Failed to share MCP proxy tools", zap.String("proxy", proxyName), zap.Error(err))
+	}
+}
+
+// loadSharedProxyTools registers the tools shared by the leader of the proxy sync loop, without connecting to the
+// upstream servers. The proxies the leader has not listed yet have no tools.
+func (s *Server) loadSharedProxyTools(mcpServer *server.MCPServer, proxies []storage.ProxyConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), leadershipTimeout)
+	defer cancel()
+	shared, err := s.Storage.ListProxyTools(ctx)
+	if err != nil {
+		s.Logger.Error("Failed to get the shared MCP proxy tools", zap.Error(err))
+		return
+	}
+	byProxy := make(map[string]storage.ProxyTools, len(shared))
+	for _, t := range shared {
+		byProxy[t.Proxy] = t
+	}
+
+	followerProxies := make(map[string]followerProxy, len(proxies))
+	for _, config := range proxies {
+		p, ok := s.followerProxies[config.Name]
+		if !ok || !reflect.DeepEqual(p.config, config) {
+			p = followerProxy{
+				config: config,
+				proxy:  proxy.NewLazyProxy(config, s.Config.Proxy, s.secrets, s.upstreamSessions, s.Logger, s.forwardUpstreamLog),
+			}
+		}
+		followerProxies[config.Name] = p
+
+		t, ok := byProxy[config.Name]
+		if !ok {
+			continue
+		}
+		var tools []mcp.Tool
+		if err := json.Unmarshal(t.Tools, &tools); err != nil {
+			s.Logger.Error("Invalid shared MCP proxy tools", zap.String("proxy", config.Name), zap.Error(err))
+			continue
+		}
+		var templates []mcp.ResourceTemplate
+		if len(t.ResourceTemplates) > 0 {
+			if err := json.Unmarshal(t.ResourceTemplates, &templates); err != nil {
+				s.Logger.Warn("Invalid shared MCP proxy resource templates", zap.String("proxy", config.Name), zap.Error(err))
+			}
+		}
+		s.syncProxyTools(mcpServer, config.Name, s.serverTools(p.proxy, &config, tools))
+		s.registerProxyResourceTemplates(mcpServer, p.proxy, templates)
+		s.tools.setCompleter(config.Name, p.proxy)
+	}
+	s.followerProxies = followerProxies
+}
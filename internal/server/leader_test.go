@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareProxyTools(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config = cfg.DefaultConfig()
+	srv.Storage = storage.NewMemoryStorage("")
+	templates := []mcp.ResourceTemplate{mcp.NewResourceTemplate("github:repo://{owner}/{name}/readme", "github:readme")}
+
+	// Without leader election, the tools are not shared.
+	srv.shareProxyTools("github", []mcp.Tool{mcp.NewTool("search")}, templates)
+	shared, err := srv.Storage.ListProxyTools(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, shared)
+
+	srv.Config.Proxy.LeaderElection = true
+	assert.True(t, srv.leadsProxySync())
+	srv.shareProxyTools("github", []mcp.Tool{mcp.NewTool("search")}, templates)
+	shared, err = srv.Storage.ListProxyTools(context.Background())
+	require.NoError(t, err)
+	require.Len(t, shared, 1)
+	assert.Contains(t, string(shared[0].Tools), `"name":"search"`)
+	assert.Contains(t, string(shared[0].ResourceTemplates), `"uriTemplate":"github:repo://{owner}/{name}/readme"`)
+}
+
+func TestLoadSharedProxyTools(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config = cfg.DefaultConfig()
+	srv.Storage = storage.NewMemoryStorage("")
+	srv.tools = newToolRegistry()
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithHooks(srv.mcpHooks()))
+	ctx := context.Background()
+
+	tools, _ := json.Marshal([]mcp.Tool{mcp.NewTool("search"), mcp.NewTool("create_issue")})
+	templates, _ := json.Marshal([]mcp.ResourceTemplate{mcp.NewResourceTemplate("github:repo://{owner}/{name}/readme", "github:readme")})
+	require.NoError(t, srv.Storage.SetProxyTools(ctx, storage.ProxyTools{
+		Proxy: "github", Tools: tools, ResourceTemplates: templates, SyncedAt: time.Now(),
+	}))
+	proxies := []storage.ProxyConfig{
+		{Name: "github", Type: storage.ProxyTypeStreamableHTTP, URL: "http://127.0.0.1:1/mcp"},
+		{Name: "jira", Type: storage.ProxyTypeStreamableHTTP, URL: "http://127.0.0.1:1/mcp"},
+	}
+
+	handle := func(message string) string {
+		response, err := json.Marshal(mcpServer.HandleMessage(t.Context(), []byte(message)))
+		require.NoError(t, err)
+		return string(response)
+	}
+
+	srv.loadSharedProxyTools(mcpServer, proxies)
+	listTools := handle(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Contains(t, listTools, `"name":"github:search"`)
+	assert.Contains(t, listTools, `"name":"github:create_issue"`)
+	assert.NotContains(t, listTools, "jira")
+	assert.Contains(t, handle(`{"jsonrpc":"2.0","id":2,"method":"resources/templates/list"}`), "github:readme")
+	require.Len(t, srv.followerProxies, 2)
+
+	// The proxies are kept while their config is unchanged, and replaced otherwise.
+	github := srv.followerProxies["github"].proxy
+	proxies[1].URL = "http://127.0.0.1:2/mcp"
+	jira := srv.followerProxies["jira"].proxy
+	srv.loadSharedProxyTools(mcpServer, proxies)
+	assert.Same(t, github, srv.followerProxies["github"].proxy)
+	assert.NotSame(t, jira, srv.followerProxies["jira"].proxy)
+
+	srv.loadSharedProxyTools(mcpServer, proxies[1:])
+	assert.NotContains(t, srv.followerProxies, "github")
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/proxy"
+	"go.uber.org/zap"
+)
+
+// defaultClientLogLevel is the minimum level of the upstream logs forwarded to a client
+// that did not call logging/setLevel.
+const defaultClientLogLevel = mcp.LoggingLevelError
+
+// logLevelStore keeps the minimum log level requested by each client.
+// The MCP endpoint is stateless, so the clients are identified by the subject of their token.
+type logLevelStore struct {
+	mu     sync.RWMutex
+	levels map[string]mcp.LoggingLevel
+}
+
+func newLogLevelStore() *logLevelStore {
+	return &logLevelStore{
+		levels: make(map[string]mcp.LoggingLevel),
+	}
+}
+
+func (l *logLevelStore) set(identity string, level mcp.LoggingLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.levels[identity] = level
+}
+
+func (l *logLevelStore) get(identity string) mcp.LoggingLevel {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if level, ok := l.levels[identity]; ok {
+		return level
+	}
+	return defaultClientLogLevel
+}
+
+// forwardUpstreamLog relays a logging notification of an upstream server to the client of an in-flight call,
+// if its level passes the level requested by the client, with the secrets of its data masked as in the tool
+// results. The logger name is prefixed by the proxy name.
+func (s *Server) forwardUpstreamLog(ctx context.Context, proxyName string, notification mcp.LoggingMessageNotification) {
+	if !notification.Params.Level.ShouldSendTo(s.logLevels.get(identityFromContext(ctx))) {
+		return
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return
+	}
+	loggerName := proxyName
+	if notification.Params.Logger != "" {
+		loggerName += ":" + notification.Params.Logger
+	}
+	data, _ := s.Masker.Value(proxyName, notification.Params.Data)
+	err := mcpServer.SendNotificationToClient(ctx, proxy.MethodLoggingMessage, map[string]any{
+		"level":  notification.Params.Level,
+		"logger": loggerName,
+		"data":   data,
+	})
+	if err != nil {
+		s.Logger.Debug("Failed to forward the upstream log", zap.String("proxy", proxyName), zap.Error(err))
+	}
+}
+
+// forwardUpstreamProgress relays a progress notification of an upstream server to the client of an in-flight
+// call, with the secrets of its message masked as in the tool results.
+func (s *Server) forwardUpstreamProgress(ctx context.Context, proxyName string, notification mcp.ProgressNotification) {
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return
+	}
+	params := map[string]any{
+		"progressToken": notification.Params.ProgressToken,
+		"progress":      notification.Params.Progress,
+	}
+	if notification.Params.Total > 0 {
+		params["total"] = notification.Params.Total
+	}
+	if notification.Params.Message != "" {
+		params["message"], _ = s.Masker.Text(proxyName, notification.Params.Message)
+	}
+	if err := mcpServer.SendNotificationToClient(ctx, proxy.MethodProgress, params); err != nil {
+		s.Logger.Debug("Failed to forward the upstream progress", zap.String("proxy", proxyName), zap.Error(err))
+	}
+}
@@ -0,0 +1,17 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevelStore(t *testing.T) {
+	levels := newLogLevelStore()
+	assert.Equal(t, defaultClientLogLevel, levels.get("alice"))
+
+	levels.set("alice", mcp.LoggingLevelDebug)
+	assert.Equal(t, mcp.LoggingLevelDebug, levels.get("alice"))
+	assert.Equal(t, defaultClientLogLevel, levels.get("bob"))
+}
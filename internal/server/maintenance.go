@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"go.uber.org/zap"
+)
+
+// MaintenanceStatus is the maintenance mode of the gateway. While enabled, the tool calls are rejected with the
+// message, and tools/list and the admin APIs keep working.
+type MaintenanceStatus struct {
+	Enabled bool `json:"enabled"`
+	// Message is the error returned to the rejected tool calls.
+	Message string `json:"message"`
+	// UpdatedAt is when the maintenance mode was last set.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// setMaintenance enables or disables the maintenance mode, with the default message if empty.
+func (s *Server) setMaintenance(enabled bool, message string) MaintenanceStatus {
+	if message == "" {
+		message = cfg.DefaultMaintenanceMessage
+	}
+	status := MaintenanceStatus{Enabled: enabled, Message: message, UpdatedAt: time.Now().UTC()}
+	s.reloadable.maintenance.Store(&status)
+	if enabled {
+		s.Logger.Warn("Maintenance mode enabled, the tool calls are rejected", zap.String("message", message))
+	}
+	return status
+}
+
+// maintenance returns the current maintenance mode.
+func (s *Server) maintenance() MaintenanceStatus {
+	if status := s.reloadable.maintenance.Load(); status != nil {
+		return *status
+	}
+	return MaintenanceStatus{Message: cfg.DefaultMaintenanceMessage}
+}
+
+// maintenanceHandler rejects the tool calls with the maintenance message while the maintenance mode is enabled.
+func (s *Server) maintenanceHandler(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if status := s.maintenance(); status.Enabled {
+			s.Logger.Debug("Rejecting a tool call during the maintenance", zap.String("tool", request.Params.Name))
+			return mcp.NewToolResultError(status.Message), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// @Summary		Get the maintenance mode
+// @Description	Get whether the tool calls are rejected for a maintenance
+// @Tags			config
+// @Produce		json
+// @Success		200	{object}	MaintenanceStatus
+// @Security		Authentication
+// @Router			/v1/admin/maintenance [get]
+func (s *Server) getMaintenance(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.maintenance())
+}
+
+// @Summary		Set the maintenance mode
+// @Description	Enable or disable the maintenance mode of the gateway replica serving the request. While enabled, the tool calls are rejected with the message; tools/list and the admin APIs keep working.
+// @Tags			config
+// @Accept			json
+// @Produce		json
+// @Param			maintenance	body		MaintenanceStatus	true	"Maintenance mode"
+// @Success		200			{object}	MaintenanceStatus
+// @Failure		400			{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/maintenance [put]
+func (s *Server) updateMaintenance(c echo.Context) error {
+	request := MaintenanceStatus{}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, s.setMaintenance(request.Enabled, request.Message))
+}
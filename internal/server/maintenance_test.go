@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenance(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.ConfigureRoutes(srv.Router.Group("/v1"))
+	handler := srv.maintenanceHandler(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("called"), nil
+	})
+	setMaintenance := func(body string) MaintenanceStatus {
+		req := httptest.NewRequest(http.MethodPut, "/v1/admin/maintenance", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var status MaintenanceStatus
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+		return status
+	}
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	status := setMaintenance(`{"enabled":true}`)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, cfg.DefaultMaintenanceMessage, status.Message)
+	status = setMaintenance(`{"enabled":true,"message":"Database upgrade until 10:00 UTC"}`)
+	result, err = handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Database upgrade until 10:00 UTC", result.Content[0].(mcp.TextContent).Text)
+
+	rec := httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/maintenance", nil))
+	assert.JSONEq(t, `{"enabled":true,"message":"Database upgrade until 10:00 UTC","updatedAt":"`+
+		status.UpdatedAt.Format(time.RFC3339Nano)+`"}`, rec.Body.String())
+
+	setMaintenance(`{"enabled":false}`)
+	result, err = handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+}
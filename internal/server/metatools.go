@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
+)
+
+// The built-in tools describing the gateway, exposed as the tools of the reserved gateway proxy.
+const (
+	metaToolListProxies  = "list_proxies"
+	metaToolWhoami       = "whoami"
+	metaToolDescribeTool = "describe_tool"
+)
+
+// MetaProxySummary is a proxy listed by gateway:list_proxies.
+type MetaProxySummary struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	DocsURL     string `json:"docsUrl,omitempty"`
+	// Tools is the number of tools of the proxy, AllowedTools the number the caller may call.
+	Tools        int `json:"tools"`
+	AllowedTools int `json:"allowedTools"`
+}
+
+// MetaIdentity is the caller described by gateway:whoami.
+type MetaIdentity struct {
+	Identity string   `json:"identity"`
+	Roles    []string `json:"roles"`
+	// AllowedTools are the names of the tools the caller may call.
+	AllowedTools []string `json:"allowedTools"`
+}
+
+// syncMetaTools registers the built-in tools describing the gateway, if enabled. They are granted like the tools of
+// the proxies, with permissions on the gateway proxy.
+func (s *Server) syncMetaTools(mcpServer *server.MCPServer) {
+	if !s.Config.Proxy.MetaTools {
+		return
+	}
+	s.syncProxyTools(mcpServer, storage.MetaProxy, []server.ServerTool{
+		{
+			Tool: mcp.NewTool(storage.MetaProxy+":"+metaToolListProxies,
+				mcp.WithDescription("List the proxies of the gateway, with their number of tools and of tools you may call"),
+				mcp.WithReadOnlyHintAnnotation(true)),
+			Handler: s.listProxiesTool,
+		},
+		{
+			Tool: mcp.NewTool(storage.MetaProxy+":"+metaToolWhoami,
+				mcp.WithDescription("Describe your identity, your roles and the tools you may call"),
+				mcp.WithReadOnlyHintAnnotation(true)),
+			Handler: s.whoamiTool,
+		},
+		{
+			Tool: mcp.NewTool(storage.MetaProxy+":"+metaToolDescribeTool,
+				mcp.WithDescription("Describe a tool you may call: its description, input schema and annotations"),
+				mcp.WithString("name", mcp.Required(), mcp.Description("Name of the tool, e.g. github:search")),
+				mcp.WithReadOnlyHintAnnotation(true)),
+			Handler: s.describeToolTool,
+		},
+	})
+}
+
+// listProxiesTool lists the registered proxies with a tool the caller may call, the gateway proxy aside.
+func (s *Server) listProxiesTool(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	configs, err := s.Storage.ListProxies(ctx, false)
+	if err != nil {
+		s.Logger.Error("Failed to list the proxies", zap.Error(err))
+		return mcp.NewToolResultError("Unable to list the proxies"), nil
+	}
+	byName := make(map[string]*storage.ProxyConfig, len(configs))
+	for i := range configs {
+		byName[configs[i].Name] = &configs[i]
+	}
+
+	proxies := []MetaProxySummary{}
+	for _, name := range s.tools.names() {
+		if name == storage.MetaProxy {
+			continue
+		}
+		registered, _ := s.tools.get(name)
+		summary := MetaProxySummary{Name: name, Tools: len(registered.Tools)}
+		if config, ok := byName[name]; ok {
+			summary.Description = config.Description
+			summary.Owner = config.Owner
+			summary.DocsURL = config.DocsURL
+		}
+		for _, tool := range registered.Tools {
+			if s.callerAllowed(ctx, tool.Tool.Name) {
+				summary.AllowedTools++
+			}
+		}
+		// Like the tools, the proxies the caller may not use are not disclosed
+		if summary.AllowedTools == 0 {
+			continue
+		}
+		proxies = append(proxies, summary)
+	}
+	return jsonResult(proxies)
+}
+
+// whoamiTool describes the caller, from the claims of its token.
+func (s *Server) whoamiTool(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	identity := MetaIdentity{Identity: identityFromContext(ctx), Roles: []string{}, AllowedTools: []string{}}
+	if claims, ok := ctx.Value("claims").(map[string]interface{}); ok {
+		decision := s.Provider.ExplainPermissions(ctx, "tools", storage.MetaProxy, metaToolWhoami, claims)
+		if decision.Roles != nil {
+			identity.Roles = decision.Roles
+		}
+	}
+	for _, name := range s.tools.names() {
+		registered, _ := s.tools.get(name)
+		for _, tool := range registered.Tools {
+			if s.callerAllowed(ctx, tool.Tool.Name) {
+				identity.AllowedTools = append(identity.AllowedTools, tool.Tool.Name)
+			}
+		}
+	}
+	return jsonResult(identity)
+}
+
+// describeToolTool describes a tool the caller may call. The tools it may not call are reported as not found, so
+// their existence is not disclosed.
+func (s *Server) describeToolTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	proxyName, toolName := s.parseToolName(name)
+	tool, ok := s.tools.getTool(proxyName, toolName)
+	if !ok || !s.callerAllowed(ctx, name) {
+		return mcp.NewToolResultError(fmt.Sprintf("tool %q not found", name)), nil
+	}
+	return jsonResult(tool.Tool)
+}
+
+// callerAllowed returns true if the caller may call the tool, always if the authentication is disabled.
+func (s *Server) callerAllowed(ctx context.Context, toolName string) bool {
+	claims, ok := ctx.Value("claims").(map[string]interface{})
+	if !ok {
+		return true
+	}
+	proxyName, objectName := s.parseToolName(toolName)
+	return s.Provider.ExplainPermissions(ctx, "tools", proxyName, objectName, claims).Allowed
+}
+
+// jsonResult returns a result whose text is the JSON encoding of v.
+func jsonResult(v any) (*mcp.CallToolResult, error) {
+	text, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(text)), nil
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaTools(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{allowedObjects: []string{"search", "whoami", "list_proxies", "describe_tool"}})
+	srv.Config = cfg.DefaultConfig()
+	store := storage.NewMemoryStorage("")
+	srv.Storage = store
+	srv.tools = newToolRegistry()
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+
+	ctx := context.Background()
+	require.NoError(t, store.SetProxy(ctx, &storage.ProxyConfig{
+		Name: "jira", Type: storage.ProxyTypeStreamableHTTP, URL: "http://jira.example.com/mcp",
+		AuthType: storage.ProxyAuthTypeHeader, Description: "Issue tracker", Owner: "platform",
+	}, true))
+	jira := &recordingCallProxy{name: "jira", calls: map[string]map[string]any{}}
+	tools := []mcp.Tool{mcp.NewTool("search", mcp.WithDescription("Search the issues")), mcp.NewTool("delete")}
+	srv.syncProxyTools(mcpServer, "jira", srv.serverTools(jira, &storage.ProxyConfig{Name: "jira"}, tools))
+	wiki := &recordingCallProxy{name: "wiki", calls: map[string]map[string]any{}}
+	srv.syncProxyTools(mcpServer, "wiki", srv.serverTools(wiki, &storage.ProxyConfig{Name: "wiki"}, []mcp.Tool{mcp.NewTool("edit")}))
+
+	//nolint:staticcheck,revive // We need to use the key as a string
+	userCtx := context.WithValue(ctx, "claims", map[string]interface{}{"sub": "test-user"})
+	call := func(ctx context.Context, name string, arguments map[string]any) *mcp.CallToolResult {
+		tool, ok := srv.tools.getTool(storage.MetaProxy, name)
+		require.True(t, ok)
+		request := mcp.CallToolRequest{}
+		request.Params.Name = tool.Tool.Name
+		request.Params.Arguments = arguments
+		result, err := tool.Handler(ctx, request)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("the meta tools are disabled by default", func(t *testing.T) {
+		srv.syncMetaTools(mcpServer)
+		_, ok := srv.tools.get(storage.MetaProxy)
+		assert.False(t, ok)
+	})
+
+	srv.Config.Proxy.MetaTools = true
+	srv.syncMetaTools(mcpServer)
+
+	t.Run("the proxies are listed with the tools allowed to the caller", func(t *testing.T) {
+		result := call(userCtx, "list_proxies", nil)
+		require.False(t, result.IsError)
+		assert.JSONEq(t, `[{"name":"jira","description":"Issue tracker","owner":"platform","tools":2,"allowedTools":1}]`,
+			result.Content[0].(mcp.TextContent).Text, "the proxies without an allowed tool are not disclosed")
+
+		result = call(ctx, "list_proxies", nil)
+		assert.JSONEq(t, `[{"name":"jira","description":"Issue tracker","owner":"platform","tools":2,"allowedTools":2},
+			{"name":"wiki","tools":1,"allowedTools":1}]`, result.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("the caller is described with its roles and allowed tools", func(t *testing.T) {
+		result := call(userCtx, "whoami", nil)
+		require.False(t, result.IsError)
+		assert.JSONEq(t, `{"identity":"test-user","roles":["tester"],"allowedTools":["gateway:list_proxies",
+			"gateway:whoami","gateway:describe_tool","jira:search"]}`, result.Content[0].(mcp.TextContent).Text)
+
+		result = call(ctx, "whoami", nil)
+		assert.JSONEq(t, `{"identity":"anonymous","roles":[],"allowedTools":["gateway:list_proxies","gateway:whoami",
+			"gateway:describe_tool","jira:search","jira:delete","wiki:edit"]}`, result.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("only the allowed tools are described", func(t *testing.T) {
+		result := call(userCtx, "describe_tool", map[string]any{"name": "jira:search"})
+		require.False(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"description":"Search the issues"`)
+
+		for _, name := range []string{"jira:delete", "jira:unknown"} {
+			result = call(userCtx, "describe_tool", map[string]any{"name": name})
+			require.True(t, result.IsError)
+			assert.Equal(t, `tool "`+name+`" not found`, result.Content[0].(mcp.TextContent).Text,
+				"a tool which is not allowed is reported as not found")
+		}
+	})
+}
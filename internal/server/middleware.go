@@ -2,87 +2,321 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/auth"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
 	"go.uber.org/zap"
 )
 
-// authMiddleware is the middleware that checks if the request is valid and if the user has the necessary permissions
+// authMiddleware is the middleware that checks if the request is valid and if the user has the necessary permissions.
+// Every entry of a JSON-RPC batch is authorized: the whole batch is rejected if one of its tool calls, resource
+// reads or argument completions is not allowed.
 func (s *Server) authMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		isMCPPath := c.Path() == "/mcp" && c.Request().Method == "POST"
+		isMCPPath := s.isMCPPath(c.Path()) && c.Request().Method == "POST"
 		if !isMCPPath {
 			return next(c)
 		}
 
-		message, err := s.parseRequestBody(c)
+		messages, err := s.parseRequestBody(c)
 		if err != nil {
-			return s.unauth(c, "invalid_request", "Invalid request")
+			return writeRPCError(c, err)
 		}
 
 		isOAuthEnabled := s.Config.OAuth.Enabled
-		isToolCall := message.Method == "tools/call"
-		if !isOAuthEnabled && !isToolCall {
+		needsAuthorization := false
+		for _, message := range messages {
+			if message.Method == string(mcp.MethodToolsCall) || message.Method == string(mcp.MethodResourcesRead) ||
+				message.Method == methodComplete {
+				needsAuthorization = true
+			}
+		}
+		if !isOAuthEnabled && !needsAuthorization {
 			return next(c)
 		}
 
 		token := c.Request().Header.Get("Authorization")
 		if token == "" {
-			return s.unauth(c, "missing_token", "Missing token")
+			metrics.TokenVerificationFailuresCounter.WithLabelValues(auth.TokenFailureMissing).Inc()
+			return s.unauth(c, "", "Missing token")
 		}
 		token = strings.TrimPrefix(token, "Bearer ")
 
+		authStartedAt := time.Now()
 		jwtToken, err := s.Provider.VerifyToken(token)
 		if err != nil {
-			return s.unauth(c, "invalid_token", "Invalid token")
+			metrics.TokenVerificationFailuresCounter.WithLabelValues(auth.TokenFailureReason(err)).Inc()
+			return s.unauth(c, "invalid_token", auth.TokenFailureDescription(err))
+		}
+		// RFC 8707: a token issued for another resource must not be accepted
+		if resource := s.mcpResource(c); resource != nil && !audienceAllowed(jwtToken.Claims, resource.audiences) {
+			s.Logger.Info("Rejecting a token issued for another audience", zap.Any("aud", jwtToken.Claims["aud"]))
+			metrics.TokenVerificationFailuresCounter.WithLabelValues(auth.TokenFailureAudience).Inc()
+			return s.unauth(c, "invalid_token", "Invalid token audience")
+		}
+		// The App Check token attests the client app, alongside the token of its user
+		if s.AppCheck != nil {
+			if err := s.AppCheck.VerifyAppCheckToken(c.Request().Header.Get(auth.AppCheckHeader)); err != nil {
+				s.Logger.Info("Rejecting a request without a valid App Check token", zap.Error(err))
+				metrics.TokenVerificationFailuresCounter.WithLabelValues(auth.TokenFailureAppCheck).Inc()
+				return s.unauth(c, "invalid_token", "Missing or invalid App Check token")
+			}
+		}
+
+		// toolRoles are the roles which allowed the tool calls, by tool, for the metrics
+		toolRoles := make(map[string]string, len(messages))
+		var quotaCalls []quotaCall
+		for _, message := range messages {
+			// resources/read:resources, granted on the owning proxy like the tool calls
+			if message.Method == string(mcp.MethodResourcesRead) {
+				proxyName, objectName := s.parseToolName(message.ResourceURI)
+				decision := s.Provider.ExplainPermissions(c.Request().Context(), string(storage.ObjectTypeResources),
+					proxyName, objectName, jwtToken.Claims)
+				s.observeAuthzDecision(proxyName, string(storage.ObjectTypeResources), decision.Allowed)
+				if !decision.Allowed {
+					return s.unauth(c, "insufficient_scope", "Insufficient scope")
+				}
+				continue
+			}
+			// completion/complete:resources or prompts, granted on the proxy of the referenced object
+			if message.Method == methodComplete {
+				objectType, proxyName, objectName, _ := message.CompletionRef.object()
+				decision := s.Provider.ExplainPermissions(c.Request().Context(), string(objectType),
+					proxyName, objectName, jwtToken.Claims)
+				s.observeAuthzDecision(proxyName, string(objectType), decision.Allowed)
+				if !decision.Allowed {
+					return s.unauth(c, "insufficient_scope", "Insufficient scope")
+				}
+				continue
+			}
+			if message.Method != "tools/call" {
+				continue
+			}
+			// tools/call:tools
+			s.Logger.Debug("Verifying permissions for tool call",
+				zap.String("method", message.Method),
+				zap.String("params", message.Params.Name),
+				zap.Any("claims", jwtToken.Claims))
+			objectType := strings.Split(message.Method, "/")[0]
+			proxyName, objectName := s.parseToolName(message.Params.Name)
+
+			decision := s.Provider.ExplainPermissions(c.Request().Context(), objectType, proxyName, objectName, jwtToken.Claims)
+			s.observeAuthzDecision(proxyName, objectType, decision.Allowed)
+			if !decision.Allowed {
+				if len(messages) > 1 {
+					s.Logger.Info("Rejecting a JSON-RPC batch with a non-allowed tool call",
+						zap.String("tool", message.Params.Name),
+						zap.Int("batch_size", len(messages)))
+				}
+				return s.unauth(c, "insufficient_scope", "Insufficient scope")
+			}
+			toolRoles[message.Params.Name] = decision.MatchedRole
+			quotaCalls = append(quotaCalls, quotaCall{
+				proxy: proxyName, tool: objectName, roles: decision.Roles, role: decision.MatchedRole,
+			})
 		}
 
-		// tools/call:tools
-		s.Logger.Debug("Verifying permissions for tool call",
-			zap.String("method", message.Method),
-			zap.String("params", message.Params.Name),
-			zap.Any("claims", jwtToken.Claims))
-		objectType := strings.Split(message.Method, "/")[0]
-		paramsSplit := strings.Split(message.Params.Name, ":")
-		objectName := paramsSplit[1]
-		proxyName := paramsSplit[0]
+		timings := authTimings{auth: time.Since(authStartedAt)}
 
-		hasPermission := s.Provider.VerifyPermissions(c.Request().Context(), objectType, proxyName, objectName, jwtToken.Claims)
-		if !hasPermission {
-			return s.unauth(c, "insufficient_scope", "Insufficient scope")
+		// The tool calls rejected during a maintenance are not counted
+		if !s.maintenance().Enabled {
+			storageStartedAt := time.Now()
+			if quota, usage := s.consumeQuotas(c.Request().Context(), identityFromClaims(jwtToken.Claims), quotaCalls); usage != nil {
+				return quotaExceeded(c, quota, usage)
+			}
+			// The budgets are charged once the calls are forwarded upstream, by budgetHandler
+			budget, exhausted, usages := s.checkBudgets(c.Request().Context(), quotaCalls)
+			if exhausted != nil {
+				return budgetExhausted(c, budget, exhausted)
+			}
+			setBudgetRemaining(c, usages)
+			timings.storage = time.Since(storageStartedAt)
 		}
 
 		c.Set("claims", jwtToken.Claims)
+		//nolint:staticcheck,revive // We need to use the key as a string
+		ctx := context.WithValue(c.Request().Context(), "claims", jwtToken.Claims)
+		//nolint:staticcheck,revive // We need to use the key as a string
+		ctx = context.WithValue(ctx, "toolRoles", toolRoles)
+		//nolint:staticcheck,revive // We need to use the key as a string
+		ctx = context.WithValue(ctx, "authTimings", timings)
+		c.SetRequest(c.Request().WithContext(ctx))
 		return next(c)
 	}
 }
 
-// parseRequestBody parses the request body and returns a MCP request
-func (s *Server) parseRequestBody(c echo.Context) (*mcp.CallToolRequest, error) {
-	const maxBodySize = 1 << 20 // 1 MiB
+// observeAuthzDecision counts an authorization decision. The proxy names are sent by the callers, so those which
+// are not registered are counted as unknown to bound the series.
+func (s *Server) observeAuthzDecision(proxyName, objectType string, allowed bool) {
+	proxyLabel := metrics.UnknownLabelValue
+	if s.tools != nil {
+		if _, ok := s.tools.get(proxyName); ok {
+			proxyLabel = proxyName
+		}
+	}
+	decision := "deny"
+	if allowed {
+		decision = "allow"
+	}
+	metrics.AuthzDecisionsCounter.WithLabelValues(proxyLabel, objectType, decision).Inc()
+}
 
-	req := c.Request()
-	body := req.Body
-	req.Body = http.MaxBytesReader(c.Response(), body, maxBodySize)
+// maxBodySize bounds the body of the MCP requests.
+const maxBodySize = 1 << 20 // 1 MiB
+
+// mcpMessage is an entry of an MCP request body. Only the params of the tool calls, the URI of the resource
+// reads and the reference of the argument completions are decoded, as the params of the other methods have other
+// shapes.
+type mcpMessage struct {
+	ID     mcp.RequestId
+	Method string
+	Params mcp.CallToolParams
+	// ResourceURI is the URI of a resources/read request, prefixed by the proxy name.
+	ResourceURI string
+	// CompletionRef is the prompt or resource referenced by a completion/complete request.
+	CompletionRef completionRef
+}
+
+// rpcError is a malformed MCP request, answered with a JSON-RPC error and the HTTP status.
+type rpcError struct {
+	status  int
+	id      mcp.RequestId
+	code    int
+	message string
+}
 
-	var copyBuf bytes.Buffer
-	tee := io.TeeReader(req.Body, &copyBuf)
+func (e *rpcError) Error() string {
+	return e.message
+}
 
-	dec := json.NewDecoder(tee)
+// writeRPCError answers a malformed MCP request with its JSON-RPC error.
+func writeRPCError(c echo.Context, err error) error {
+	var rpcErr *rpcError
+	if !errors.As(err, &rpcErr) {
+		rpcErr = &rpcError{status: http.StatusBadRequest, code: mcp.PARSE_ERROR, message: err.Error()}
+	}
+	return c.JSON(rpcErr.status, mcp.NewJSONRPCError(rpcErr.id, rpcErr.code, rpcErr.message, nil))
+}
 
-	message := &mcp.CallToolRequest{}
-	err := dec.Decode(message)
+// parseRequestBody parses the request body and returns its MCP messages, one per entry for a JSON-RPC batch. The
+// body is left for the next handlers. A malformed body returns an *rpcError.
+func (s *Server) parseRequestBody(c echo.Context) ([]*mcpMessage, error) {
+	req := c.Request()
+	raw, err := io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, maxBodySize))
+	req.Body = io.NopCloser(bytes.NewReader(raw))
 	if err != nil {
-		s.Logger.Error("Failed to unmarshal request body", zap.Error(err))
-		return nil, err
+		if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+			return nil, &rpcError{status: http.StatusRequestEntityTooLarge, code: mcp.INVALID_REQUEST,
+				message: fmt.Sprintf("request body exceeds %d bytes", maxBodySize)}
+		}
+		s.Logger.Error("Failed to read request body", zap.Error(err))
+		return nil, &rpcError{status: http.StatusBadRequest, code: mcp.PARSE_ERROR, message: "failed to read the request body"}
 	}
 
-	req.Body = io.NopCloser(&copyBuf)
+	entries := []json.RawMessage{raw}
+	if isBatch(raw) {
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return nil, &rpcError{status: http.StatusBadRequest, code: mcp.PARSE_ERROR, message: "invalid JSON-RPC batch"}
+		}
+		if len(entries) == 0 {
+			return nil, &rpcError{status: http.StatusBadRequest, code: mcp.INVALID_REQUEST, message: errEmptyBatch.Error()}
+		}
+	} else if !json.Valid(raw) {
+		return nil, &rpcError{status: http.StatusBadRequest, code: mcp.PARSE_ERROR, message: "invalid JSON-RPC message"}
+	}
 
+	messages := make([]*mcpMessage, 0, len(entries))
+	for _, entry := range entries {
+		message, err := s.parseMessage(entry)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// parseMessage parses an entry of an MCP request body. The tool calls must be requests naming a tool of a proxy.
+func (s *Server) parseMessage(raw json.RawMessage) (*mcpMessage, error) {
+	var envelope struct {
+		ID     mcp.RequestId   `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, &rpcError{status: http.StatusBadRequest, code: mcp.INVALID_REQUEST, message: "invalid JSON-RPC message"}
+	}
+	message := &mcpMessage{ID: envelope.ID, Method: envelope.Method}
+	if message.Method == string(mcp.MethodResourcesRead) {
+		var params mcp.ReadResourceParams
+		if err := json.Unmarshal(envelope.Params, &params); err != nil || params.URI == "" {
+			return nil, &rpcError{status: http.StatusBadRequest, id: message.ID, code: mcp.INVALID_PARAMS,
+				message: "resources/read requires the URI of the resource in its params"}
+		}
+		message.ResourceURI = params.URI
+		return message, nil
+	}
+	if message.Method == methodComplete {
+		var params struct {
+			Ref completionRef `json:"ref"`
+		}
+		if err := json.Unmarshal(envelope.Params, &params); err != nil {
+			return nil, &rpcError{status: http.StatusBadRequest, id: message.ID, code: mcp.INVALID_PARAMS,
+				message: "completion/complete requires the reference of a prompt or resource in its params"}
+		}
+		if _, _, _, err := params.Ref.object(); err != nil {
+			return nil, &rpcError{status: http.StatusBadRequest, id: message.ID, code: mcp.INVALID_PARAMS, message: err.Error()}
+		}
+		message.CompletionRef = params.Ref
+		return message, nil
+	}
+	if message.Method != string(mcp.MethodToolsCall) {
+		return message, nil
+	}
+
+	if message.ID.IsNil() {
+		return nil, &rpcError{status: http.StatusBadRequest, code: mcp.INVALID_REQUEST,
+			message: "tools/call must be a request, with an id"}
+	}
+	if err := json.Unmarshal(envelope.Params, &message.Params); err != nil || message.Params.Name == "" {
+		return nil, &rpcError{status: http.StatusBadRequest, id: message.ID, code: mcp.INVALID_PARAMS,
+			message: "tools/call requires the name of the tool in its params"}
+	}
+	if proxyName, toolName := s.parseToolName(message.Params.Name); proxyName == "" || toolName == "" {
+		return nil, &rpcError{status: http.StatusBadRequest, id: message.ID, code: mcp.INVALID_PARAMS,
+			message: fmt.Sprintf("tool %q not found, the tools are named proxy:tool", message.Params.Name)}
+	}
 	return message, nil
 }
+
+// requestTimeoutMiddleware bounds the time to read and write a request, and cancels its context, after the timeout
+func requestTimeoutMiddleware(timeout time.Duration, skipper func(c echo.Context) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if timeout <= 0 || skipper(c) {
+				return next(c)
+			}
+			deadline := time.Now().Add(timeout)
+			rc := http.NewResponseController(c.Response())
+			// Not every ResponseWriter supports deadlines (e.g. in tests); the context deadline still applies.
+			_ = rc.SetReadDeadline(deadline)
+			_ = rc.SetWriteDeadline(deadline)
+
+			ctx, cancel := context.WithDeadline(c.Request().Context(), deadline)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -7,14 +7,18 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/matthisholleville/mcp-gateway/internal/auth"
 	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
 	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,6 +28,10 @@ type MockProvider struct {
 	shouldVerifyToken       bool
 	shouldVerifyPermissions bool
 	verifyTokenError        error
+	// allowedObjects are always allowed, whatever shouldVerifyPermissions is
+	allowedObjects []string
+	// claims are the claims of the valid tokens, a subject and the audience of the test server if nil
+	claims map[string]interface{}
 }
 
 func (m *MockProvider) Init() error {
@@ -37,17 +45,31 @@ func (m *MockProvider) VerifyToken(token string) (*auth.Jwt, error) {
 	if !m.shouldVerifyToken {
 		return nil, assert.AnError
 	}
+	if m.claims != nil {
+		return &auth.Jwt{Claims: m.claims}, nil
+	}
 	return &auth.Jwt{
 		Claims: map[string]interface{}{
 			"sub": "test-user",
+			"aud": testResource,
 		},
 	}, nil
 }
 
-func (m *MockProvider) VerifyPermissions(ctx context.Context, objectType, objectName, proxy string, claims map[string]interface{}) bool {
-	return m.shouldVerifyPermissions
+func (m *MockProvider) VerifyPermissions(ctx context.Context, objectType, proxy, objectName string, claims map[string]interface{}) bool {
+	return m.shouldVerifyPermissions || slices.Contains(m.allowedObjects, objectName)
+}
+
+func (m *MockProvider) ExplainPermissions(ctx context.Context, objectType, proxy, objectName string, claims map[string]interface{}) auth.PermissionDecision {
+	if !m.VerifyPermissions(ctx, objectType, proxy, objectName, claims) {
+		return auth.PermissionDecision{}
+	}
+	return auth.PermissionDecision{Allowed: true, Roles: []string{"tester"}, MatchedRole: "tester"}
 }
 
+// testResource is the OAuth resource of the test server
+const testResource = "https://gateway.example.com/mcp"
+
 // createTestServer creates a test server with the given OAuth enabled and provider
 func createTestServer(oauthEnabled bool, provider auth.Provider) *Server {
 	log := logger.MustNewLogger("json", "debug", "test")
@@ -55,7 +77,9 @@ func createTestServer(oauthEnabled bool, provider auth.Provider) *Server {
 		Config: &cfg.Config{
 			OAuth: &cfg.OAuthConfig{
 				Enabled:              oauthEnabled,
+				Resource:             testResource,
 				AuthorizationServers: []string{"https://test.example.com"},
+				ScopesSupported:      []string{"openid", "email"},
 			},
 		},
 		Router:   echo.New(),
@@ -75,7 +99,7 @@ func createMCPRequest(method, toolName string) *http.Request {
 		},
 	}
 
-	body, _ := json.Marshal(toolRequest)
+	body, _ := json.Marshal(mcp.JSONRPCRequest{JSONRPC: mcp.JSONRPC_VERSION, ID: mcp.NewRequestId(1), Request: toolRequest.Request, Params: toolRequest.Params})
 	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	return req
@@ -159,6 +183,8 @@ func TestAuthMiddleware_MissingToken(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
 	assert.Equal(t, "Missing token", httpErr.Message)
+	assert.Equal(t, `Bearer resource_metadata="https://gateway.example.com/.well-known/oauth-protected-resource/mcp", scope="openid email"`,
+		rec.Header().Get("WWW-Authenticate"))
 }
 
 // TestAuthMiddleware_InvalidToken tests the auth middleware with a MCP request and invalid token
@@ -186,6 +212,135 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
 	assert.Equal(t, "Invalid token", httpErr.Message)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="invalid_token", error_description="Invalid token"`)
+}
+
+// TestAuthMiddleware_TokenClaims tests that the rejections of the claims of the tokens are described to the client
+func TestAuthMiddleware_TokenClaims(t *testing.T) {
+	provider := &MockProvider{verifyTokenError: &auth.TokenError{
+		Reason: auth.TokenFailureTooOld, Description: "Token older than the maximum token age", Err: assert.AnError,
+	}}
+	server := createTestServer(true, provider)
+	middleware := server.authMiddleware(func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := createMCPRequest("tools/call", "proxy1:tool1")
+	req.Header.Set("Authorization", "Bearer old-token")
+	rec := httptest.NewRecorder()
+	before := testutil.ToFloat64(metrics.TokenVerificationFailuresCounter.WithLabelValues(auth.TokenFailureTooOld))
+
+	err := middleware(createTestContext(server, req, rec, "/mcp"))
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+	assert.Equal(t, "Token older than the maximum token age", httpErr.Message)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error_description="Token older than the maximum token age"`)
+	assert.InDelta(t, before+1, testutil.ToFloat64(metrics.TokenVerificationFailuresCounter.WithLabelValues(auth.TokenFailureTooOld)), 0)
+}
+
+// TestAuthMiddleware_TokenAudience tests the auth middleware with tokens issued for the gateway or another resource
+func TestAuthMiddleware_TokenAudience(t *testing.T) {
+	tests := []struct {
+		name      string
+		audiences []string
+		aud       interface{}
+		allowed   bool
+	}{
+		{name: "resource", aud: testResource, allowed: true},
+		{name: "resource with a trailing slash", aud: testResource + "/", allowed: true},
+		{name: "resource among the audiences", aud: []interface{}{"https://other.example.com", testResource}, allowed: true},
+		{name: "other resource", aud: "https://other.example.com"},
+		{name: "without audience"},
+		{name: "configured audience", audiences: []string{"api://default"}, aud: "api://default", allowed: true},
+		{name: "resource not configured", audiences: []string{"api://default"}, aud: testResource},
+		{name: "any audience", audiences: []string{"*"}, aud: "https://other.example.com", allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := map[string]interface{}{"sub": "test-user"}
+			if tt.aud != nil {
+				claims["aud"] = tt.aud
+			}
+			server := createTestServer(true, &MockProvider{shouldVerifyToken: true, shouldVerifyPermissions: true, claims: claims})
+			server.Config.OAuth.Audiences = tt.audiences
+			middleware := server.authMiddleware(func(c echo.Context) error {
+				return c.String(http.StatusOK, "ok")
+			})
+
+			req := createMCPRequest("tools/call", "proxy1:tool1")
+			req.Header.Set("Authorization", "Bearer valid-token")
+			rec := httptest.NewRecorder()
+			err := middleware(createTestContext(server, req, rec, "/mcp"))
+
+			if tt.allowed {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+				return
+			}
+			httpErr, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+			assert.Equal(t, "Invalid token audience", httpErr.Message)
+			assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+		})
+	}
+}
+
+// mockAppCheck accepts a single App Check token
+type mockAppCheck struct{}
+
+func (mockAppCheck) AppCheckRequired() bool { return true }
+
+func (mockAppCheck) VerifyAppCheckToken(token string) error {
+	if token != "valid-app-check-token" {
+		return &auth.TokenError{Reason: auth.TokenFailureAppCheck, Err: fmt.Errorf("invalid App Check token %q", token)}
+	}
+	return nil
+}
+
+// TestAuthMiddleware_AppCheck tests that the requests must carry a valid App Check token, if required
+func TestAuthMiddleware_AppCheck(t *testing.T) {
+	metrics.TokenVerificationFailuresCounter.Reset()
+	defer metrics.TokenVerificationFailuresCounter.Reset()
+	for _, tt := range []struct {
+		name     string
+		appCheck string
+		allowed  bool
+	}{
+		{name: "valid App Check token", appCheck: "valid-app-check-token", allowed: true},
+		{name: "invalid App Check token", appCheck: "forged"},
+		{name: "missing App Check token"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(true, &MockProvider{shouldVerifyToken: true, shouldVerifyPermissions: true})
+			server.AppCheck = mockAppCheck{}
+			middleware := server.authMiddleware(func(c echo.Context) error {
+				return c.String(http.StatusOK, "ok")
+			})
+
+			req := createMCPRequest("tools/call", "proxy1:tool1")
+			req.Header.Set("Authorization", "Bearer valid-token")
+			if tt.appCheck != "" {
+				req.Header.Set(auth.AppCheckHeader, tt.appCheck)
+			}
+			rec := httptest.NewRecorder()
+			err := middleware(createTestContext(server, req, rec, "/mcp"))
+
+			if tt.allowed {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+				return
+			}
+			httpErr, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+			assert.Equal(t, "Missing or invalid App Check token", httpErr.Message)
+			assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+		})
+	}
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.TokenVerificationFailuresCounter.WithLabelValues(auth.TokenFailureAppCheck)))
 }
 
 // TestAuthMiddleware_InsufficientPermissions tests the auth middleware with a MCP request and insufficient permissions
@@ -211,8 +366,10 @@ func TestAuthMiddleware_InsufficientPermissions(t *testing.T) {
 
 	httpErr, ok := err.(*echo.HTTPError)
 	require.True(t, ok)
-	assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+	assert.Equal(t, http.StatusForbidden, httpErr.Code)
 	assert.Equal(t, "Insufficient scope", httpErr.Message)
+	assert.Equal(t, `Bearer resource_metadata="https://gateway.example.com/.well-known/oauth-protected-resource/mcp", scope="openid email", `+
+		`error="insufficient_scope", error_description="Insufficient scope"`, rec.Header().Get("WWW-Authenticate"))
 }
 
 // TestAuthMiddleware_Success tests the auth middleware with a MCP request and valid token and permissions
@@ -227,6 +384,8 @@ func TestAuthMiddleware_Success(t *testing.T) {
 		// Check that the claims are added to the context
 		claims := c.Get("claims")
 		assert.NotNil(t, claims)
+		// Check that the role which allowed the tool call is added to the request context
+		assert.Equal(t, "tester", toolRoleFromContext(c.Request().Context(), "proxy1:tool1"))
 		return c.String(http.StatusOK, "ok")
 	}
 
@@ -268,29 +427,81 @@ func TestAuthMiddleware_TokenWithBearerPrefix(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-// TestAuthMiddleware_InvalidRequestBody tests the auth middleware with a MCP request and invalid request body
+// TestAuthMiddleware_InvalidRequestBody tests the auth middleware with malformed request bodies, answered with
+// JSON-RPC errors
 func TestAuthMiddleware_InvalidRequestBody(t *testing.T) {
-	provider := &MockProvider{}
-	server := createTestServer(true, provider)
-
-	nextHandler := func(c echo.Context) error {
-		return c.String(http.StatusOK, "ok")
+	for _, test := range []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "invalid JSON", body: "invalid json", expectedStatus: http.StatusBadRequest,
+			expectedError: `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"invalid JSON-RPC message"}}`},
+		{name: "truncated body", body: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"proxy1:`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"invalid JSON-RPC message"}}`},
+		{name: "truncated batch", body: `[{"jsonrpc":"2.0","id":1,"method":"tools/list"},`, expectedStatus: http.StatusBadRequest,
+			expectedError: `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"invalid JSON-RPC batch"}}`},
+		{name: "empty batch", body: `[]`, expectedStatus: http.StatusBadRequest,
+			expectedError: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"empty JSON-RPC batch"}}`},
+		{name: "not an object", body: `[42]`, expectedStatus: http.StatusBadRequest,
+			expectedError: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"invalid JSON-RPC message"}}`},
+		{name: "tool call notification", body: `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"proxy1:tool1"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"tools/call must be a request, with an id"}}`},
+		{name: "missing params", body: `{"jsonrpc":"2.0","id":"a","method":"tools/call"}`, expectedStatus: http.StatusBadRequest,
+			expectedError: `{"jsonrpc":"2.0","id":"a","error":{"code":-32602,"message":"tools/call requires the name of the tool in its params"}}`},
+		{name: "missing name", body: `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"arguments":{}}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"tools/call requires the name of the tool in its params"}}`},
+		{name: "positional params", body: `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":["proxy1:tool1"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `{"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"tools/call requires the name of the tool in its params"}}`},
+		{name: "name without proxy", body: `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"tool1"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `{"jsonrpc":"2.0","id":4,"error":{"code":-32602,"message":"tool \"tool1\" not found, the tools are named proxy:tool"}}`},
+		{name: "oversized body", body: `{"jsonrpc":"2.0","id":5,"method":"tools/list","params":{"cursor":"` + strings.Repeat("a", maxBodySize) + `"}}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"request body exceeds 1048576 bytes"}}`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := createTestServer(true, &MockProvider{shouldVerifyToken: true})
+			called := false
+			middleware := server.authMiddleware(func(c echo.Context) error {
+				called = true
+				return c.String(http.StatusOK, "ok")
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer valid-token")
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, middleware(createTestContext(server, req, rec, "/mcp")))
+			assert.False(t, called)
+			assert.Equal(t, test.expectedStatus, rec.Code)
+			assert.JSONEq(t, test.expectedError, rec.Body.String())
+		})
 	}
+}
 
-	middleware := server.authMiddleware(nextHandler)
+// TestAuthMiddleware_Notification tests that the notifications and the responses of the client are not authorized
+func TestAuthMiddleware_Notification(t *testing.T) {
+	server := createTestServer(false, &MockProvider{})
+	called := false
+	middleware := server.authMiddleware(func(c echo.Context) error {
+		called = true
+		return c.String(http.StatusOK, "ok")
+	})
 
-	// Request with invalid JSON body
-	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("invalid json"))
+	body := `[{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":7,"result":{}}]`
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	c := createTestContext(server, req, rec, "/mcp")
-
-	err := middleware(c)
 
-	httpErr, ok := err.(*echo.HTTPError)
-	require.True(t, ok)
-	assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
-	assert.Equal(t, "Invalid request", httpErr.Message)
+	require.NoError(t, middleware(createTestContext(server, req, rec, "/mcp")))
+	assert.True(t, called)
 }
 
 // TestAuthMiddleware_OAuthDisabledButToolCall tests the auth middleware with a MCP request and OAuth disabled but tool call
@@ -317,7 +528,197 @@ func TestAuthMiddleware_OAuthDisabledButToolCall(t *testing.T) {
 	// Shouldn't pass because insufficient permissions
 	httpErr, ok := err.(*echo.HTTPError)
 	require.True(t, ok)
-	assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+	assert.Equal(t, http.StatusForbidden, httpErr.Code)
 	assert.Equal(t, "Insufficient scope", httpErr.Message)
+	assert.Empty(t, rec.Header().Get("WWW-Authenticate"), "no challenge without OAuth")
+
+}
+
+// TestAuthMiddleware_Batch tests the auth middleware with a JSON-RPC batch
+func TestAuthMiddleware_Batch(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{
+			name: "all entries allowed",
+			body: `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"proxy1:tool1"}},` +
+				`{"jsonrpc":"2.0","id":2,"method":"tools/list"}]`,
+		},
+		{
+			name: "mixed permissions",
+			body: `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"proxy1:tool1"}},` +
+				`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"proxy1:tool2"}}]`,
+			expectedError: "Insufficient scope",
+		},
+		{
+			name: "string ids and an upstream tool name with a colon",
+			body: `[{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"proxy1:tool1:v2"}},` +
+				`{"jsonrpc":"2.0","id":"b","method":"tools/list"}]`,
+			expectedError: "Insufficient scope",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := createTestServer(true, &MockProvider{shouldVerifyToken: true, allowedObjects: []string{"tool1"}})
+			called := false
+			middleware := server.authMiddleware(func(c echo.Context) error {
+				called = true
+				return c.String(http.StatusOK, "ok")
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer valid-token")
+			rec := httptest.NewRecorder()
+
+			err := middleware(createTestContext(server, req, rec, "/mcp"))
+			if test.expectedError == "" {
+				require.NoError(t, err)
+				assert.True(t, called)
+				return
+			}
+			httpErr, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			assert.Equal(t, test.expectedError, httpErr.Message)
+			assert.False(t, called)
+		})
+	}
+}
+
+// TestAuthMiddleware_ResourceRead tests that the reads of the templated resources are authorized on their proxy
+func TestAuthMiddleware_ResourceRead(t *testing.T) {
+	read := func(uri, token string) (bool, error) {
+		server := createTestServer(false, &MockProvider{shouldVerifyToken: true, allowedObjects: []string{"repo://acme/api"}})
+		called := false
+		middleware := server.authMiddleware(func(c echo.Context) error {
+			called = true
+			return c.String(http.StatusOK, "ok")
+		})
+		body := `{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"` + uri + `"}}`
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		err := middleware(createTestContext(server, req, httptest.NewRecorder(), "/mcp"))
+		return called, err
+	}
+
+	called, err := read("github:repo://acme/api", "valid-token")
+	require.NoError(t, err)
+	assert.True(t, called)
+
+	called, err = read("github:repo://acme/secrets", "valid-token")
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, httpErr.Code)
+	assert.False(t, called)
+
+	called, err = read("github:repo://acme/api", "")
+	httpErr, ok = err.(*echo.HTTPError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.Code, "a token is required even without OAuth")
+	assert.False(t, called)
+}
 
+func TestAuthMiddleware_Completion(t *testing.T) {
+	complete := func(ref, token string) (bool, error) {
+		server := createTestServer(false, &MockProvider{shouldVerifyToken: true, allowedObjects: []string{"review"}})
+		called := false
+		middleware := server.authMiddleware(func(c echo.Context) error {
+			called = true
+			return c.String(http.StatusOK, "ok")
+		})
+		body := `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{"ref":` + ref +
+			`,"argument":{"name":"owner","value":"ac"}}}`
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		err := middleware(createTestContext(server, req, httptest.NewRecorder(), "/mcp"))
+		return called, err
+	}
+
+	called, err := complete(`{"type":"ref/prompt","name":"github:review"}`, "valid-token")
+	require.NoError(t, err)
+	assert.True(t, called)
+
+	called, err = complete(`{"type":"ref/resource","uri":"github:repo://{owner}/{name}"}`, "valid-token")
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, httpErr.Code, "a denied caller must not complete the arguments")
+	assert.False(t, called)
+
+	called, err = complete(`{"type":"ref/prompt","name":"github:review"}`, "")
+	httpErr, ok = err.(*echo.HTTPError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.Code, "a token is required even without OAuth")
+	assert.False(t, called)
+
+	called, err = complete(`{"type":"ref/prompt","name":"review"}`, "valid-token")
+	require.NoError(t, err)
+	assert.False(t, called, "a reference without proxy prefix is invalid")
+}
+
+func TestRequestTimeoutMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(requestTimeoutMiddleware(50*time.Millisecond, func(c echo.Context) bool {
+		return c.Path() == "/stream"
+	}))
+	handler := func(c echo.Context) error {
+		select {
+		case <-c.Request().Context().Done():
+			return c.String(http.StatusGatewayTimeout, "timeout")
+		case <-time.After(time.Second):
+			return c.String(http.StatusOK, "ok")
+		}
+	}
+	e.GET("/admin", handler)
+	e.GET("/stream", handler)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// TestAuthMiddleware_Metrics tests the counts of the authorization decisions and token verification failures
+func TestAuthMiddleware_Metrics(t *testing.T) {
+	metrics.AuthzDecisionsCounter.Reset()
+	metrics.TokenVerificationFailuresCounter.Reset()
+	call := func(provider *MockProvider, authorization, toolName string) {
+		server := createTestServer(true, provider)
+		server.tools = newToolRegistry()
+		server.tools.set("proxy1", nil, time.Now())
+		req := createMCPRequest("tools/call", toolName)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		_ = server.authMiddleware(func(c echo.Context) error {
+			return c.String(http.StatusOK, "ok")
+		})(createTestContext(server, req, httptest.NewRecorder(), "/mcp"))
+	}
+
+	call(&MockProvider{}, "", "proxy1:tool1")
+	call(&MockProvider{verifyTokenError: &auth.TokenError{Reason: auth.TokenFailureExpired, Err: assert.AnError}}, "Bearer expired", "proxy1:tool1")
+	call(&MockProvider{}, "Bearer invalid", "proxy1:tool1")
+	call(&MockProvider{shouldVerifyToken: true, claims: map[string]interface{}{"aud": "https://other.example.com"}}, "Bearer other", "proxy1:tool1")
+	call(&MockProvider{shouldVerifyToken: true, shouldVerifyPermissions: true}, "Bearer valid", "proxy1:tool1")
+	call(&MockProvider{shouldVerifyToken: true}, "Bearer valid", "proxy1:tool1")
+	call(&MockProvider{shouldVerifyToken: true}, "Bearer valid", "random-proxy:tool1")
+
+	for reason, expected := range map[string]float64{
+		auth.TokenFailureMissing: 1, auth.TokenFailureExpired: 1, auth.TokenFailureInvalid: 1, auth.TokenFailureAudience: 1,
+	} {
+		assert.Equal(t, expected, testutil.ToFloat64(metrics.TokenVerificationFailuresCounter.WithLabelValues(reason)), reason)
+	}
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AuthzDecisionsCounter.WithLabelValues("proxy1", "tools", "allow")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AuthzDecisionsCounter.WithLabelValues("proxy1", "tools", "deny")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AuthzDecisionsCounter.WithLabelValues(metrics.UnknownLabelValue, "tools", "deny")),
+		"the proxies which are not registered must not be used as label values")
 }
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"go.uber.org/zap"
+)
+
+// wellKnownProtectedResource is the well-known path of the protected resource metadata, RFC 9728.
+const wellKnownProtectedResource = "/.well-known/oauth-protected-resource"
+
+// protectedResource is an OAuth protected resource of the gateway, with its metadata, RFC 9728.
+type protectedResource struct {
+	resource string
+	host     string
+	// mcpPath is the MCP endpoint of the resource, and metadataPaths the paths of its metadata
+	mcpPath       string
+	metadataPaths []string
+	metadata      map[string]any
+	scopes        []string
+	// audiences are the audiences the tokens must be issued for
+	audiences []string
+}
+
+// newProtectedResources returns the protected resources of the gateway, the OAuth resource first.
+func newProtectedResources(config *cfg.OAuthConfig) []*protectedResource {
+	resources := []*protectedResource{newProtectedResource(cfg.ProtectedResourceConfig{
+		Resource:               config.Resource,
+		AuthorizationServers:   config.AuthorizationServers,
+		BearerMethodsSupported: config.BearerMethodsSupported,
+		ScopesSupported:        config.ScopesSupported,
+		Audiences:              config.Audiences,
+	}, "/mcp")}
+	for _, resource := range config.ProtectedResources {
+		resource.AuthorizationServers = valuesOr(resource.AuthorizationServers, config.AuthorizationServers)
+		resource.BearerMethodsSupported = valuesOr(resource.BearerMethodsSupported, config.BearerMethodsSupported)
+		resource.ScopesSupported = valuesOr(resource.ScopesSupported, config.ScopesSupported)
+		resources = append(resources, newProtectedResource(resource, ""))
+	}
+	return resources
+}
+
+// newProtectedResource returns a protected resource whose MCP endpoint is mcpPath, the path of the resource if empty.
+func newProtectedResource(config cfg.ProtectedResourceConfig, mcpPath string) *protectedResource {
+	resource := &protectedResource{
+		resource: config.Resource,
+		mcpPath:  mcpPath,
+		// The clients fall back to the well-known path alone.
+		metadataPaths: []string{wellKnownProtectedResource},
+		metadata: map[string]any{
+			"resource":                 config.Resource,
+			"authorization_servers":    config.AuthorizationServers,
+			"bearer_methods_supported": config.BearerMethodsSupported,
+			"scopes_supported":         config.ScopesSupported,
+		},
+		scopes:    config.ScopesSupported,
+		audiences: config.Audiences,
+	}
+	if len(resource.audiences) == 0 && config.Resource != "" {
+		resource.audiences = []string{config.Resource}
+	}
+	if u, err := url.Parse(config.Resource); err == nil {
+		resource.host = u.Host
+		// The metadata of a resource with a path is at the well-known path followed by the path of the resource.
+		if path := strings.TrimSuffix(u.Path, "/"); path != "" {
+			resource.metadataPaths = append(resource.metadataPaths, wellKnownProtectedResource+path)
+			if resource.mcpPath == "" {
+				resource.mcpPath = path
+			}
+		}
+	}
+	return resource
+}
+
+func valuesOr(values, defaults []string) []string {
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
+
+// oauthResources returns the protected resources of the gateway, none if OAuth is disabled.
+func (s *Server) oauthResources() []*protectedResource {
+	s.protectedResourcesOnce.Do(func() {
+		if s.Config.OAuth != nil && s.Config.OAuth.Enabled {
+			s.protectedResources = newProtectedResources(s.Config.OAuth)
+		}
+	})
+	return s.protectedResources
+}
+
+// protectedResourceFor returns the protected resource served on a path of the gateway, preferring the resource on
+// the host of the request, or nil if there is none.
+func (s *Server) protectedResourceFor(host string, served func(*protectedResource) bool) *protectedResource {
+	var found *protectedResource
+	for _, resource := range s.oauthResources() {
+		if !served(resource) {
+			continue
+		}
+		if strings.EqualFold(resource.host, host) {
+			return resource
+		}
+		if found == nil {
+			found = resource
+		}
+	}
+	return found
+}
+
+// mcpResource returns the protected resource of an MCP request, or nil if OAuth is disabled.
+func (s *Server) mcpResource(c echo.Context) *protectedResource {
+	return s.protectedResourceFor(c.Request().Host, func(resource *protectedResource) bool {
+		return resource.mcpPath == c.Path()
+	})
+}
+
+// mcpPaths returns the paths of the MCP endpoint: /mcp, and the paths of the other protected resources.
+func (s *Server) mcpPaths() []string {
+	paths := []string{"/mcp"}
+	for _, resource := range s.oauthResources() {
+		if !slices.Contains(paths, resource.mcpPath) {
+			paths = append(paths, resource.mcpPath)
+		}
+	}
+	return paths
+}
+
+// isMCPPath checks that a route is an MCP endpoint.
+func (s *Server) isMCPPath(path string) bool {
+	return slices.Contains(s.mcpPaths(), path)
+}
+
+// withOAuthProtectedResources adds OAuth protected resources to the router
+func (s *Server) withOAuthProtectedResources() {
+	if !s.Config.OAuth.Enabled {
+		s.Logger.Warn("OAuth is disabled. Skipping OAuth protected resources.")
+		return
+	}
+
+	wellKnown := func(c echo.Context) error {
+		resource := s.protectedResourceFor(c.Request().Host, func(resource *protectedResource) bool {
+			return slices.Contains(resource.metadataPaths, c.Path())
+		})
+		c.Response().Header().Set("Content-Type", "application/json")
+		return c.JSON(http.StatusOK, resource.metadata)
+	}
+
+	var paths []string
+	for _, resource := range s.oauthResources() {
+		for _, path := range resource.metadataPaths {
+			if !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	for _, path := range paths {
+		s.Router.GET(path, wellKnown)
+		s.Router.HEAD(path, wellKnown)
+		s.Router.OPTIONS(path, func(c echo.Context) error {
+			return c.NoContent(http.StatusNoContent)
+		})
+	}
+}
+
+// resourceMetadataURL returns the URL of the protected resource metadata of a resource of the gateway.
+func resourceMetadataURL(resource string) (string, error) {
+	u, err := url.Parse(resource)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid OAuth resource %q", resource)
+	}
+	metadata := url.URL{Scheme: u.Scheme, Host: u.Host, Path: wellKnownProtectedResource + strings.TrimSuffix(u.Path, "/")}
+	return metadata.String(), nil
+}
+
+// audienceAllowed checks that the aud claim, a string or an array of strings, contains one of the accepted
+// audiences. The trailing slashes are ignored, as clients differ on the resource indicator they request.
+func audienceAllowed(claims map[string]interface{}, accepted []string) bool {
+	if len(accepted) == 0 || slices.Contains(accepted, "*") {
+		return true
+	}
+	var audiences []string
+	switch aud := claims["aud"].(type) {
+	case string:
+		audiences = []string{aud}
+	case []string:
+		audiences = aud
+	case []interface{}:
+		for _, a := range aud {
+			if value, ok := a.(string); ok {
+				audiences = append(audiences, value)
+			}
+		}
+	}
+	for _, audience := range audiences {
+		for _, value := range accepted {
+			if strings.TrimSuffix(audience, "/") == strings.TrimSuffix(value, "/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unauth rejects a request with the Bearer challenge of RFC 6750, pointing the clients to the metadata of the
+// protected resource to obtain a token. An empty code is a request without token; insufficient_scope is rejected
+// with 403.
+func (s *Server) unauth(c echo.Context, code, msg string) error {
+	status := http.StatusUnauthorized
+	if code == "insufficient_scope" {
+		status = http.StatusForbidden
+	}
+	if resource := s.mcpResource(c); resource != nil {
+		rsMetaURL, err := resourceMetadataURL(resource.resource)
+		if err != nil {
+			s.Logger.Error("OAuth is enabled but the resource is invalid", zap.String("resource", resource.resource))
+			return echo.NewHTTPError(http.StatusInternalServerError, "OAuth configuration error")
+		}
+		params := []string{fmt.Sprintf("resource_metadata=%q", rsMetaURL)}
+		if len(resource.scopes) > 0 {
+			params = append(params, fmt.Sprintf("scope=%q", strings.Join(resource.scopes, " ")))
+		}
+		if code != "" {
+			params = append(params, fmt.Sprintf("error=%q", code), fmt.Sprintf("error_description=%q", msg))
+		}
+		c.Response().Header().Set("WWW-Authenticate", "Bearer "+strings.Join(params, ", "))
+	}
+	return echo.NewHTTPError(status, msg)
+}
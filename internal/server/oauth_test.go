@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOAuthProtectedResources(t *testing.T) {
+	s := createTestServer(true, &MockProvider{})
+	s.withOAuthProtectedResources()
+
+	for _, path := range []string{"/.well-known/oauth-protected-resource", "/.well-known/oauth-protected-resource/mcp"} {
+		rec := httptest.NewRecorder()
+		s.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		var meta map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &meta))
+		assert.Equal(t, testResource, meta["resource"])
+		assert.Equal(t, []any{"openid", "email"}, meta["scopes_supported"])
+	}
+}
+
+func TestResourceMetadataURL(t *testing.T) {
+	tests := []struct {
+		resource string
+		expected string
+	}{
+		{resource: "https://gateway.example.com", expected: "https://gateway.example.com/.well-known/oauth-protected-resource"},
+		{resource: "https://gateway.example.com/", expected: "https://gateway.example.com/.well-known/oauth-protected-resource"},
+		{resource: "http://localhost:8082/mcp", expected: "http://localhost:8082/.well-known/oauth-protected-resource/mcp"},
+		{resource: "https://gateway.example.com/mcp?tenant=a", expected: "https://gateway.example.com/.well-known/oauth-protected-resource/mcp"},
+	}
+	for _, tt := range tests {
+		actual, err := resourceMetadataURL(tt.resource)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, actual, tt.resource)
+	}
+
+	_, err := resourceMetadataURL("")
+	assert.Error(t, err)
+}
+
+func TestProtectedResources(t *testing.T) {
+	s := createTestServer(true, &MockProvider{shouldVerifyToken: true, shouldVerifyPermissions: true,
+		claims: map[string]interface{}{"sub": "test-user", "aud": "https://gateway.example.com/team-a/mcp"}})
+	s.Config.OAuth.ProtectedResources = []cfg.ProtectedResourceConfig{
+		{Resource: "https://gateway.example.com/team-a/mcp", ScopesSupported: []string{"team-a"}},
+		{Resource: "https://tenant.example.com/mcp", AuthorizationServers: []string{"https://auth.tenant.example.com"}},
+	}
+	s.withOAuthProtectedResources()
+	assert.Equal(t, []string{"/mcp", "/team-a/mcp"}, s.mcpPaths())
+
+	metadata := func(host, path string) map[string]any {
+		req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		s.Router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		var meta map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &meta))
+		return meta
+	}
+	assert.Equal(t, testResource, metadata("gateway.example.com", "/.well-known/oauth-protected-resource")["resource"])
+	teamA := metadata("gateway.example.com", "/.well-known/oauth-protected-resource/team-a/mcp")
+	assert.Equal(t, "https://gateway.example.com/team-a/mcp", teamA["resource"])
+	assert.Equal(t, []any{"team-a"}, teamA["scopes_supported"])
+	assert.Equal(t, []any{"https://test.example.com"}, teamA["authorization_servers"], "the empty values are those of the OAuth configuration")
+	tenant := metadata("tenant.example.com", "/.well-known/oauth-protected-resource/mcp")
+	assert.Equal(t, "https://tenant.example.com/mcp", tenant["resource"], "the resource on the host of the request is preferred")
+	assert.Equal(t, []any{"https://auth.tenant.example.com"}, tenant["authorization_servers"])
+
+	call := func(host, path string) (*httptest.ResponseRecorder, error) {
+		req := createMCPRequest("tools/call", "proxy1:tool1")
+		req.Host = host
+		req.Header.Set("Authorization", "Bearer valid-token")
+		rec := httptest.NewRecorder()
+		return rec, s.authMiddleware(func(c echo.Context) error { return c.NoContent(http.StatusOK) })(createTestContext(s, req, rec, path))
+	}
+	_, err := call("gateway.example.com", "/team-a/mcp")
+	require.NoError(t, err, "the token is issued for the resource")
+	rec, err := call("gateway.example.com", "/mcp")
+	require.Error(t, err, "the token is issued for another resource")
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `resource_metadata="https://gateway.example.com/.well-known/oauth-protected-resource/mcp"`)
+	rec, err = call("tenant.example.com", "/mcp")
+	require.Error(t, err)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `resource_metadata="https://tenant.example.com/.well-known/oauth-protected-resource/mcp"`)
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
+)
+
+// quotaCall is a tool call of a request, counted against the quotas and charged to the budget of the role which
+// allowed it.
+type quotaCall struct {
+	proxy string
+	tool  string
+	roles []string
+	role  string
+}
+
+// consumeQuotas counts the tool calls of the subject against the quotas matching them, and returns the first quota
+// exceeded and its usage, if any. The quotas are not enforced if the storage fails, so that an outage of the
+// storage does not block every tool call.
+func (s *Server) consumeQuotas(ctx context.Context, subject string, calls []quotaCall) (storage.QuotaConfig, *storage.QuotaUsage) {
+	if s.Storage == nil || len(calls) == 0 {
+		return storage.QuotaConfig{}, nil
+	}
+	quotas, err := s.Storage.ListQuotas(ctx)
+	if err != nil {
+		s.Logger.Error("Failed to list the quotas, they are not enforced", zap.Error(err))
+		return storage.QuotaConfig{}, nil
+	}
+
+	now := time.Now()
+	for _, call := range calls {
+		for _, quota := range quotas {
+			if !quota.Matches(call.proxy, call.tool, subject, call.roles) {
+				continue
+			}
+			usage, allowed, err := s.Storage.ConsumeQuota(ctx, quota, subject, now)
+			if err != nil {
+				s.Logger.Error("Failed to count the tool call against the quota", zap.String("quota", quota.Name), zap.Error(err))
+				continue
+			}
+			if !allowed {
+				s.Logger.Info("Quota exceeded",
+					zap.String("quota", quota.Name),
+					zap.String("subject", subject),
+					zap.String("proxy", call.proxy),
+					zap.String("tool", call.tool))
+				metrics.QuotaExceededCounter.WithLabelValues(quota.Name).Inc()
+				return quota, &usage
+			}
+		}
+	}
+	return storage.QuotaConfig{}, nil
+}
+
+// quotaExceeded rejects a request whose caller exceeded a quota, telling when to retry.
+func quotaExceeded(c echo.Context, quota storage.QuotaConfig, usage *storage.QuotaUsage) error {
+	retryAfter := int(time.Until(usage.ResetsAt).Seconds()) + 1
+	c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	return echo.NewHTTPError(http.StatusTooManyRequests, fmt.Sprintf("Quota exceeded: %s allows %d calls per %s, resets at %s",
+		quota.Name, quota.Limit, quota.Window, usage.ResetsAt.Format(time.RFC3339)))
+}
+
+// @Summary		Get all quotas
+// @Description	Get the quotas limiting the tool calls of each subject per hour, day, week or month
+// @Tags			quotas
+// @Accept			json
+// @Produce		json
+// @Security		Authentication
+// @Success		200	{array}		storage.QuotaConfig
+// @Failure		500	{object}	map[string]string
+// @Router			/v1/admin/quotas [get]
+func (s *Server) getQuotas(c echo.Context) error {
+	quotas, err := s.Storage.ListQuotas(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, quotas)
+}
+
+// @Summary		Upsert a quota
+// @Description	Create or update a quota. A role quota limits every subject holding the role separately, the usage is kept on update.
+// @Tags			quotas
+// @Accept			json
+// @Produce		json
+// @Param			quota	body	storage.QuotaConfig	true	"Quota"
+// @Success		200
+// @Failure		400	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/quotas [put]
+func (s *Server) upsertQuota(c echo.Context) error {
+	quota := storage.QuotaConfig{}
+	if err := c.Bind(&quota); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := quota.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := s.Storage.SetQuota(c.Request().Context(), quota); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// @Summary		Delete a quota
+// @Description	Delete a quota and its usage
+// @Tags			quotas
+// @Accept			json
+// @Produce		json
+// @Param			name	path	string	true	"Quota name"
+// @Success		200
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/quotas/{name} [delete]
+func (s *Server) deleteQuota(c echo.Context) error {
+	if err := s.Storage.DeleteQuota(c.Request().Context(), c.Param("name")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
+
+// @Summary		Get the usage of a quota
+// @Description	Get the calls of each subject in the current window of a quota
+// @Tags			quotas
+// @Accept			json
+// @Produce		json
+// @Param			name	path		string	true	"Quota name"
+// @Success		200		{array}		storage.QuotaUsage
+// @Failure		404		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/quotas/{name}/usage [get]
+func (s *Server) getQuotaUsage(c echo.Context) error {
+	quota, err := s.Storage.GetQuota(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	usage, err := s.Storage.ListQuotaUsage(c.Request().Context(), quota, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, usage)
+}
+
+// @Summary		Reset the usage of a quota
+// @Description	Reset the calls of a subject, or of every subject, in the current window of a quota
+// @Tags			quotas
+// @Accept			json
+// @Produce		json
+// @Param			name	path	string	true	"Quota name"
+// @Param			subject	query	string	false	"Subject to reset, every subject when empty"
+// @Success		200
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/quotas/{name}/usage [delete]
+func (s *Server) resetQuotaUsage(c echo.Context) error {
+	name := c.Param("name")
+	if _, err := s.Storage.GetQuota(c.Request().Context(), name); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if err := s.Storage.ResetQuotaUsage(c.Request().Context(), name, c.QueryParam("subject")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return nil
+}
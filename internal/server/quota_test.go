@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthMiddleware_Quotas(t *testing.T) {
+	server := createTestServer(true, &MockProvider{shouldVerifyToken: true, shouldVerifyPermissions: true})
+	store := storage.NewMemoryStorage("")
+	server.Storage = store
+	require.NoError(t, store.SetQuota(context.Background(), storage.QuotaConfig{Name: "testers", SubjectType: storage.QuotaSubjectTypeRole,
+		Subject: "tester", Proxy: "proxy1", Tool: "*", Window: storage.QuotaWindowHour, Limit: 2}))
+	require.NoError(t, store.SetQuota(context.Background(), storage.QuotaConfig{Name: "other", SubjectType: storage.QuotaSubjectTypeSubject,
+		Subject: "someone-else", Proxy: "*", Tool: "*", Window: storage.QuotaWindowDay, Limit: 0}))
+
+	call := func(tool string) error {
+		middleware := server.authMiddleware(func(c echo.Context) error { return c.String(http.StatusOK, "ok") })
+		req := createMCPRequest("tools/call", tool)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		rec := httptest.NewRecorder()
+		return middleware(createTestContext(server, req, rec, "/mcp"))
+	}
+
+	assert.NoError(t, call("proxy1:tool1"))
+	assert.NoError(t, call("proxy1:tool2"))
+	err := call("proxy1:tool1")
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusTooManyRequests, httpErr.Code)
+	assert.Contains(t, httpErr.Message, "Quota exceeded: testers allows 2 calls per hour")
+	// The quota only applies to proxy1
+	assert.NoError(t, call("proxy2:tool1"))
+
+	require.NoError(t, store.ResetQuotaUsage(context.Background(), "testers", "test-user"))
+	assert.NoError(t, call("proxy1:tool1"))
+}
+
+func TestQuotaUsageHandlers(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	store := storage.NewMemoryStorage("")
+	srv.Storage = store
+	srv.ConfigureRoutes(srv.Router.Group("/v1"))
+
+	request := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodPut, "/v1/admin/quotas", `{"name":"daily","subject_type":"subject","subject":"*","proxy":"*","tool":"*","window":"year","limit":10}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = request(http.MethodPut, "/v1/admin/quotas", `{"name":"daily","subject_type":"subject","subject":"*","proxy":"*","tool":"*","window":"day","limit":10}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	quota, err := store.GetQuota(context.Background(), "daily")
+	require.NoError(t, err)
+	_, _, err = store.ConsumeQuota(context.Background(), quota, "alice", time.Now())
+	require.NoError(t, err)
+
+	rec = request(http.MethodGet, "/v1/admin/quotas/daily/usage", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var usage []storage.QuotaUsage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &usage))
+	require.Len(t, usage, 1)
+	assert.Equal(t, "alice", usage[0].Subject)
+	assert.Equal(t, int64(1), usage[0].Calls)
+
+	rec = request(http.MethodDelete, "/v1/admin/quotas/daily/usage?subject=alice", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = request(http.MethodGet, "/v1/admin/quotas/daily/usage", "")
+	assert.JSONEq(t, "[]", rec.Body.String())
+
+	rec = request(http.MethodGet, "/v1/admin/quotas/missing/usage", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
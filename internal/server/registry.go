@@ -0,0 +1,123 @@
+package server
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registeredProxy is the set of tools and resource templates registered on the MCP server for a proxy.
+type registeredProxy struct {
+	Tools             []server.ServerTool
+	ResourceTemplates []mcp.ResourceTemplate
+	// Completer answers the argument completions of the proxy prompts and resources.
+	Completer completionProxy
+	SyncedAt  time.Time
+}
+
+// toolRegistry keeps track of the tools registered on the MCP server, grouped by proxy.
+// It is refreshed by the proxy sync loop and read by the admin API.
+type toolRegistry struct {
+	mu      sync.RWMutex
+	proxies map[string]registeredProxy
+}
+
+func newToolRegistry() *toolRegistry {
+	return &toolRegistry{
+		proxies: make(map[string]registeredProxy),
+	}
+}
+
+// set replaces the tools registered for a proxy.
+func (r *toolRegistry) set(proxy string, tools []server.ServerTool, syncedAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.proxies[proxy]
+	p.Tools = tools
+	p.SyncedAt = syncedAt
+	r.proxies[proxy] = p
+}
+
+// setResourceTemplates replaces the resource templates registered for a proxy.
+func (r *toolRegistry) setResourceTemplates(proxy string, templates []mcp.ResourceTemplate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.proxies[proxy]
+	p.ResourceTemplates = templates
+	r.proxies[proxy] = p
+}
+
+// setCompleter sets the proxy answering the argument completions.
+func (r *toolRegistry) setCompleter(proxy string, completer completionProxy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.proxies[proxy]
+	p.Completer = completer
+	r.proxies[proxy] = p
+}
+
+// hasResourceTemplate returns true if a proxy currently exposes the resource template.
+func (r *toolRegistry) hasResourceTemplate(proxy, uriTemplate string) bool {
+	p, ok := r.get(proxy)
+	if !ok {
+		return false
+	}
+	return slices.ContainsFunc(p.ResourceTemplates, func(t mcp.ResourceTemplate) bool {
+		return t.URITemplate.Raw() == uriTemplate
+	})
+}
+
+// get returns the tools registered for a proxy.
+func (r *toolRegistry) get(proxy string) (registeredProxy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.proxies[proxy]
+	return p, ok
+}
+
+// names returns the names of the registered proxies, sorted.
+func (r *toolRegistry) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.proxies))
+	for name := range r.proxies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getTool returns a registered tool by proxy and tool name (without the proxy prefix).
+func (r *toolRegistry) getTool(proxy, tool string) (server.ServerTool, bool) {
+	p, ok := r.get(proxy)
+	if !ok {
+		return server.ServerTool{}, false
+	}
+	for _, t := range p.Tools {
+		if t.Tool.Name == proxy+":"+tool {
+			return t, true
+		}
+	}
+	return server.ServerTool{}, false
+}
+
+// retain drops the proxies not in the given list and returns the names of their tools.
+func (r *toolRegistry) retain(proxies []string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var removed []string
+	for name, p := range r.proxies {
+		if slices.Contains(proxies, name) {
+			continue
+		}
+		for _, t := range p.Tools {
+			removed = append(removed, t.Tool.Name)
+		}
+		delete(r.proxies, name)
+	}
+	return removed
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolRegistry(t *testing.T) {
+	registry := newToolRegistry()
+	registry.set("github", []server.ServerTool{
+		{Tool: mcp.NewTool("github:search")},
+		{Tool: mcp.NewTool("github:create_issue")},
+	}, time.Now())
+	registry.set("n8n", []server.ServerTool{{Tool: mcp.NewTool("n8n:run")}}, time.Now())
+
+	tool, ok := registry.getTool("github", "search")
+	assert.True(t, ok)
+	assert.Equal(t, "github:search", tool.Tool.Name)
+	_, ok = registry.getTool("github", "run")
+	assert.False(t, ok)
+
+	removed := registry.retain([]string{"github"})
+	assert.Equal(t, []string{"n8n:run"}, removed)
+	_, ok = registry.get("n8n")
+	assert.False(t, ok)
+
+	template := mcp.NewResourceTemplate("github:repo://{owner}/{name}", "github:repo")
+	registry.setResourceTemplates("github", []mcp.ResourceTemplate{template})
+	registry.set("github", []server.ServerTool{{Tool: mcp.NewTool("github:search")}}, time.Now())
+	assert.True(t, registry.hasResourceTemplate("github", "github:repo://{owner}/{name}"))
+	assert.False(t, registry.hasResourceTemplate("n8n", "github:repo://{owner}/{name}"))
+}
+
+func TestGetProxyTools(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.tools = newToolRegistry()
+	srv.Storage = storage.NewMemoryStorage("")
+	require.NoError(t, srv.Storage.SetProxy(t.Context(), &storage.ProxyConfig{
+		Name:     "pending",
+		Type:     storage.ProxyTypeStreamableHTTP,
+		AuthType: storage.ProxyAuthTypeHeader,
+	}, false))
+	srv.tools.set("github", []server.ServerTool{{Tool: mcp.NewTool("github:search",
+		mcp.WithDescription("Search code"),
+		mcp.WithString("query", mcp.Required()),
+		mcp.WithNumber("limit"),
+	)}}, time.Now())
+
+	for _, test := range []struct {
+		name         string
+		proxy        string
+		expectedCode int
+		expectedLen  int
+	}{
+		{name: "synced proxy", proxy: "github", expectedCode: http.StatusOK, expectedLen: 1},
+		{name: "proxy not synced yet", proxy: "pending", expectedCode: http.StatusOK, expectedLen: 0},
+		{name: "unknown proxy", proxy: "unknown", expectedCode: http.StatusNotFound},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin/proxies/"+test.proxy+"/tools", nil)
+			rec := httptest.NewRecorder()
+			c := srv.Router.NewContext(req, rec)
+			c.SetParamNames("name")
+			c.SetParamValues(test.proxy)
+
+			require.NoError(t, srv.getProxyTools(c))
+			assert.Equal(t, test.expectedCode, rec.Code)
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+			var response ProxyToolsResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Len(t, response.Tools, test.expectedLen)
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/proxies/github/tools", nil)
+	rec := httptest.NewRecorder()
+	c := srv.Router.NewContext(req, rec)
+	c.SetParamNames("name")
+	c.SetParamValues("github")
+	require.NoError(t, srv.getProxyTools(c))
+	var response ProxyToolsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.NotNil(t, response.SyncedAt)
+	assert.Equal(t, ToolSummary{
+		Name:        "github:search",
+		Description: "Search code",
+		InputSchema: InputSchemaSummary{
+			Type:       "object",
+			Properties: []string{"limit", "query"},
+			Required:   []string{"query"},
+		},
+	}, response.Tools[0])
+}
+
+// fakeResourceProxy is a proxy exposing a single resource template.
+type fakeResourceProxy struct {
+	templates []mcp.ResourceTemplate
+}
+
+func (f *fakeResourceProxy) GetName() string {
+	return "github"
+}
+
+func (f *fakeResourceProxy) GetResourceTemplates() ([]mcp.ResourceTemplate, error) {
+	return f.templates, nil
+}
+
+func (f *fakeResourceProxy) ReadResource(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, Text: "readme"}}, nil
+}
+
+func TestSyncProxyResourceTemplates(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.tools = newToolRegistry()
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithHooks(srv.mcpHooks()))
+	p := &fakeResourceProxy{templates: []mcp.ResourceTemplate{
+		mcp.NewResourceTemplate("github:repo://{owner}/{name}/readme", "github:readme"),
+	}}
+
+	handle := func(message string) string {
+		response, err := json.Marshal(mcpServer.HandleMessage(t.Context(), []byte(message)))
+		require.NoError(t, err)
+		return string(response)
+	}
+	listTemplates := `{"jsonrpc":"2.0","id":1,"method":"resources/templates/list"}`
+	readResource := `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"github:repo://octo/hello/readme"}}`
+
+	srv.syncProxyResourceTemplates(mcpServer, p)
+	assert.Contains(t, handle(listTemplates), `"uriTemplate":"github:repo://{owner}/{name}/readme"`)
+	assert.Contains(t, handle(readResource), `"text":"readme"`)
+
+	// The upstream no longer exposes the template: it is neither listed nor read.
+	p.templates = nil
+	srv.syncProxyResourceTemplates(mcpServer, p)
+	assert.NotContains(t, handle(listTemplates), "readme")
+	assert.Contains(t, handle(readResource), `"error"`)
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"go.uber.org/zap"
+)
+
+// ConfigLoader reads the current configuration, for the reloads.
+type ConfigLoader func() (*cfg.Config, error)
+
+// ReloadResult lists the settings changed by a reload.
+type ReloadResult struct {
+	// Applied are the reloadable settings that changed and were applied.
+	Applied []string `json:"applied"`
+	// RestartRequired are the settings that changed but are only applied on restart.
+	RestartRequired []string `json:"restartRequired"`
+}
+
+var errReloadUnsupported = errors.New("the configuration reload is not configured")
+
+// reloadable holds the settings that can change while the server runs.
+type reloadable struct {
+	cors        atomic.Pointer[echo.MiddlewareFunc]
+	cacheTTL    atomic.Int64
+	maintenance atomic.Pointer[MaintenanceStatus]
+}
+
+// levelSetter is implemented by the loggers whose level can be changed.
+type levelSetter interface {
+	SetLevel(level string) error
+}
+
+// SetConfigLoader sets the loader of the configuration used by the reloads.
+func (s *Server) SetConfigLoader(loader ConfigLoader) {
+	s.configLoader = loader
+}
+
+// ReloadConfig reads and verifies the configuration, then applies its reloadable settings:
+// the log level, the CORS policy, the proxy cache TTL and the maintenance mode. The MCP sessions are kept.
+func (s *Server) ReloadConfig() (ReloadResult, error) {
+	if s.configLoader == nil {
+		return ReloadResult{}, errReloadUnsupported
+	}
+	config, err := s.configLoader()
+	if err != nil {
+		return ReloadResult{}, err
+	}
+	if err := config.Verify(); err != nil {
+		return ReloadResult{}, err
+	}
+	return s.applyConfig(config)
+}
+
+// applyConfig applies the reloadable settings of the configuration.
+func (s *Server) applyConfig(config *cfg.Config) (ReloadResult, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	result := ReloadResult{Applied: []string{}, RestartRequired: restartRequired(s.Config, config)}
+
+	if config.Log.Level != s.Config.Log.Level {
+		setter, ok := s.Logger.(levelSetter)
+		if !ok {
+			return ReloadResult{}, errors.New("the log level of the logger cannot be changed")
+		}
+		if err := setter.SetLevel(config.Log.Level); err != nil {
+			return ReloadResult{}, err
+		}
+		s.Config.Log.Level = config.Log.Level
+		result.Applied = append(result.Applied, "log.level")
+	}
+
+	if !reflect.DeepEqual(config.HTTP.CORS, s.Config.HTTP.CORS) {
+		s.setCORS(config.HTTP.CORS)
+		s.Config.HTTP.CORS = config.HTTP.CORS
+		result.Applied = append(result.Applied, "http.cors")
+	}
+
+	if config.Proxy.CacheTTL != s.Config.Proxy.CacheTTL {
+		s.reloadable.cacheTTL.Store(int64(config.Proxy.CacheTTL))
+		s.Config.Proxy.CacheTTL = config.Proxy.CacheTTL
+		result.Applied = append(result.Applied, "proxy.cacheTTL")
+	}
+
+	// The maintenance mode set through the admin API is kept unless the configuration changes it
+	if !reflect.DeepEqual(config.Maintenance, s.Config.Maintenance) {
+		s.setMaintenance(config.Maintenance.Enabled, config.Maintenance.Message)
+		s.Config.Maintenance = config.Maintenance
+		result.Applied = append(result.Applied, "maintenance")
+	}
+
+	s.Logger.Info("Configuration reloaded",
+		zap.Strings("applied", result.Applied),
+		zap.Strings("restart_required", result.RestartRequired))
+	return result, nil
+}
+
+// restartRequired returns the sections of the configuration that changed outside of the reloadable settings.
+func restartRequired(current, next *cfg.Config) []string {
+	http := *next.HTTP
+	http.CORS = current.HTTP.CORS
+	log := *next.Log
+	log.Level = current.Log.Level
+	proxy := *next.Proxy
+	proxy.CacheTTL = current.Proxy.CacheTTL
+
+	changed := []string{}
+	for _, section := range []struct {
+		name          string
+		current, next any
+	}{
+		{"http", current.HTTP, &http},
+		{"grpc", current.GRPC, next.GRPC},
+		{"log", current.Log, &log},
+		{"oauth", current.OAuth, next.OAuth},
+		{"proxy", current.Proxy, &proxy},
+		{"authProvider", current.AuthProvider, next.AuthProvider},
+		{"backendConfig", current.BackendConfig, next.BackendConfig},
+		{"vault", current.Vault, next.Vault},
+		{"secrets", current.Secrets, next.Secrets},
+		{"metrics", current.Metrics, next.Metrics},
+		{"debug", current.Debug, next.Debug},
+		{"eventExport", current.EventExport, next.EventExport},
+		{"approval", current.Approval, next.Approval},
+		{"screening", current.Screening, next.Screening},
+		{"guardrail", current.Guardrail, next.Guardrail},
+		{"masking", current.Masking, next.Masking},
+		{"auditLog", current.AuditLog, next.AuditLog},
+		{"trace", current.Trace, next.Trace},
+		{"slo", current.SLO, next.SLO},
+		{"crypto", current.Crypto, next.Crypto},
+	} {
+		if !reflect.DeepEqual(section.current, section.next) {
+			changed = append(changed, section.name)
+		}
+	}
+	return changed
+}
+
+// configureReloadable initializes the reloadable settings from the configuration.
+func (s *Server) configureReloadable() {
+	s.reloadable.cacheTTL.Store(int64(s.Config.Proxy.CacheTTL))
+	s.setMaintenance(s.Config.Maintenance.Enabled, s.Config.Maintenance.Message)
+	s.setCORS(s.Config.HTTP.CORS)
+	s.Router.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cors := s.reloadable.cors.Load()
+			if cors == nil {
+				return next(c)
+			}
+			return (*cors)(next)(c)
+		}
+	})
+}
+
+// setCORS replaces the CORS middleware, removed if CORS is disabled.
+func (s *Server) setCORS(config *cfg.CORSConfig) {
+	if !config.Enabled {
+		s.Logger.Warn("CORS is disabled")
+		s.reloadable.cors.Store(nil)
+		return
+	}
+	var exposeHeaders []string
+	if s.Config.Session != nil && s.Config.Session.Stateful {
+		// The browser clients read the session ID returned by initialize.
+		exposeHeaders = append(exposeHeaders, server.HeaderKeySessionID)
+	}
+	if s.Config.OAuth != nil && s.Config.OAuth.Enabled {
+		// The browser clients discover the authorization server from the challenge.
+		exposeHeaders = append(exposeHeaders, echo.HeaderWWWAuthenticate)
+	}
+	cors := middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins:  config.AllowedOrigins,
+		AllowMethods:  config.AllowedMethods,
+		AllowHeaders:  config.AllowedHeaders,
+		ExposeHeaders: exposeHeaders,
+	})
+	s.reloadable.cors.Store(&cors)
+}
+
+// cacheTTL is the current interval of the proxy refreshes.
+func (s *Server) cacheTTL() time.Duration {
+	return time.Duration(s.reloadable.cacheTTL.Load())
+}
+
+// @Summary		Reload the configuration
+// @Description	Reload the configuration and apply its reloadable settings (log level, CORS, proxy cache TTL, maintenance mode) without restarting
+// @Tags			config
+// @Produce		json
+// @Success		200	{object}	ReloadResult
+// @Failure		400	{object}	map[string]string
+// @Failure		501	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/reload [post]
+func (s *Server) reloadConfig(c echo.Context) error {
+	result, err := s.ReloadConfig()
+	if errors.Is(err, errReloadUnsupported) {
+		return c.JSON(http.StatusNotImplemented, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// @Summary		Get the effective configuration
+// @Description	Get the configuration the server runs with, resolved from the flags, the environment, the config file and the defaults, with the secrets redacted
+// @Tags			config
+// @Produce		json
+// @Success		200	{object}	map[string]interface{}
+// @Security		Authentication
+// @Router			/v1/admin/config [get]
+func (s *Server) getConfig(c echo.Context) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	return c.JSON(http.StatusOK, s.Config.Redacted())
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadConfig(t *testing.T) {
+	srv := &Server{
+		Config: cfg.DefaultConfig(),
+		Router: echo.New(),
+		Logger: logger.MustNewLogger("json", "error", ""),
+	}
+	srv.Config.HTTP.CORS.Enabled = false
+	srv.configureReloadable()
+	srv.Router.GET("/ping", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	srv.ConfigureRoutes(srv.Router.Group("/v1"))
+
+	ping := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(echo.HeaderOrigin, "https://example.com")
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec
+	}
+	reload := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/admin/reload", nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotImplemented, reload().Code)
+	assert.Empty(t, ping().Header().Get(echo.HeaderAccessControlAllowOrigin))
+
+	next := cfg.DefaultConfig()
+	next.Log.Level = "debug"
+	next.HTTP.CORS = &cfg.CORSConfig{Enabled: true, AllowedOrigins: []string{"https://example.com"}}
+	next.Proxy.CacheTTL = time.Minute
+	next.HTTP.Addr = ":9090"
+	next.Maintenance = &cfg.MaintenanceConfig{Enabled: true, Message: "Back at 10:00 UTC"}
+	srv.SetConfigLoader(func() (*cfg.Config, error) { return next, nil })
+
+	rec := reload()
+	require.Equal(t, http.StatusOK, rec.Code)
+	var result ReloadResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, []string{"log.level", "http.cors", "proxy.cacheTTL", "maintenance"}, result.Applied)
+	assert.Equal(t, []string{"http"}, result.RestartRequired)
+	assert.Equal(t, "https://example.com", ping().Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, time.Minute, srv.cacheTTL())
+	assert.True(t, srv.maintenance().Enabled)
+	assert.Equal(t, "Back at 10:00 UTC", srv.maintenance().Message)
+
+	rec = reload()
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Empty(t, result.Applied)
+
+	invalid := cfg.DefaultConfig()
+	invalid.Log.Level = "verbose"
+	srv.SetConfigLoader(func() (*cfg.Config, error) { return invalid, nil })
+	assert.Equal(t, http.StatusBadRequest, reload().Code)
+	assert.Equal(t, "debug", srv.Config.Log.Level)
+}
+
+func TestGetConfig(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config = cfg.DefaultConfig()
+	srv.Config.BackendConfig.URI = "postgres://mcp:secret@db:5432/mcp"
+	srv.ConfigureRoutes(srv.Router.Group("/v1"))
+
+	rec := httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/config", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "mcp:secret")
+
+	var config map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &config))
+	assert.Equal(t, "[REDACTED]", config["backendConfig"].(map[string]any)["uri"])
+	assert.Equal(t, srv.Config.Proxy.CacheTTL.String(), config["proxy"].(map[string]any)["cacheTTL"])
+}
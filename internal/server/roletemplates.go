@@ -0,0 +1,166 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+)
+
+// RoleTemplate is a predefined role, instantiated with POST /v1/admin/roles/from-template.
+type RoleTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// RequiresProxy is true if the template is instantiated for a proxy, optional if false.
+	RequiresProxy bool `json:"requiresProxy"`
+	// Clearance is the default clearance of the instantiated roles.
+	Clearance storage.Classification `json:"clearance"`
+}
+
+// RoleFromTemplateRequest is the request body of POST /v1/admin/roles/from-template.
+type RoleFromTemplateRequest struct {
+	Template string `json:"template"`
+	// Name is the name of the role, which must not exist.
+	Name  string `json:"name"`
+	Proxy string `json:"proxy,omitempty"`
+	// Clearance overrides the clearance of the template.
+	Clearance storage.Classification `json:"clearance,omitempty"`
+}
+
+// roleTemplates are the built-in role templates.
+var roleTemplates = []RoleTemplate{
+	{Name: "admin", Description: "All the tools of all the proxies, up to the confidential tools",
+		Clearance: storage.ClassificationConfidential},
+	{Name: "operator", Description: "All the tools of all the proxies, or of a proxy, up to the internal tools",
+		Clearance: storage.ClassificationInternal},
+	{Name: "read-only", Description: "The tools annotated read-only of the synced proxies, or of a proxy, when the role is created",
+		Clearance: storage.ClassificationInternal},
+	{Name: "per-proxy-user", Description: "All the tools of a proxy, up to the public tools",
+		RequiresProxy: true, Clearance: storage.ClassificationPublic},
+}
+
+// @Summary		List the role templates
+// @Description	List the built-in role templates, instantiated with POST /v1/admin/roles/from-template
+// @Tags			roles
+// @Produce		json
+// @Security		Authentication
+// @Success		200	{array}	RoleTemplate
+// @Router			/v1/admin/roles/templates [get]
+func (s *Server) getRoleTemplates(c echo.Context) error {
+	return c.JSON(http.StatusOK, roleTemplates)
+}
+
+// @Summary		Create a role from a template
+// @Description	Create a role from a built-in template: admin, operator, read-only or per-proxy-user. The role must not exist.
+// @Tags			roles
+// @Accept			json
+// @Produce		json
+// @Param			request	body		RoleFromTemplateRequest	true	"Template, role name and proxy"
+// @Success		201		{object}	storage.RoleConfig
+// @Failure		400		{object}	map[string]string
+// @Failure		404		{object}	map[string]string
+// @Failure		409		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/roles/from-template [post]
+func (s *Server) createRoleFromTemplate(c echo.Context) error {
+	request := RoleFromTemplateRequest{}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if request.Name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "name is required"})
+	}
+	if request.Clearance != "" && !request.Clearance.IsValid() {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid clearance %q, must be one of public, internal or confidential", request.Clearance)})
+	}
+
+	ctx := c.Request().Context()
+	if _, err := s.Storage.GetRole(ctx, request.Name); err == nil {
+		return c.JSON(http.StatusConflict, map[string]string{"error": fmt.Sprintf("role %q already exists", request.Name)})
+	}
+	if request.Proxy != "" {
+		if _, err := s.Storage.GetProxy(ctx, request.Proxy, false); err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "proxy not found"})
+		}
+	}
+
+	role, err := s.roleFromTemplate(request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := s.Storage.SetRole(ctx, role); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, role)
+}
+
+// roleFromTemplate builds the role of a template.
+func (s *Server) roleFromTemplate(request RoleFromTemplateRequest) (storage.RoleConfig, error) {
+	var template *RoleTemplate
+	for i := range roleTemplates {
+		if roleTemplates[i].Name == request.Template {
+			template = &roleTemplates[i]
+		}
+	}
+	if template == nil {
+		names := make([]string, 0, len(roleTemplates))
+		for _, t := range roleTemplates {
+			names = append(names, t.Name)
+		}
+		return storage.RoleConfig{}, fmt.Errorf("unknown template %q, must be one of %s", request.Template, strings.Join(names, ", "))
+	}
+	if template.RequiresProxy && request.Proxy == "" {
+		return storage.RoleConfig{}, fmt.Errorf("the %s template requires a proxy", template.Name)
+	}
+	if template.Name == "admin" && request.Proxy != "" {
+		return storage.RoleConfig{}, fmt.Errorf("the admin template applies to all the proxies")
+	}
+
+	role := storage.RoleConfig{Name: request.Name, Clearance: template.Clearance}
+	if request.Clearance != "" {
+		role.Clearance = request.Clearance
+	}
+	proxy := request.Proxy
+	if proxy == "" {
+		proxy = "*"
+	}
+	switch template.Name {
+	case "admin":
+		role.Permissions = []storage.PermissionConfig{{ObjectType: storage.ObjectTypeAll, Proxy: "*", ObjectName: "*"}}
+	case "operator", "per-proxy-user":
+		role.Permissions = []storage.PermissionConfig{{ObjectType: storage.ObjectTypeTools, Proxy: proxy, ObjectName: "*"}}
+	case "read-only":
+		role.Permissions = s.readOnlyPermissions(request.Proxy)
+		if len(role.Permissions) == 0 {
+			return storage.RoleConfig{}, fmt.Errorf("no tool annotated read-only found, the proxies may not be synced yet")
+		}
+	}
+	return role, nil
+}
+
+// readOnlyPermissions returns the permissions of the tools annotated read-only of the synced proxies, or of a proxy.
+func (s *Server) readOnlyPermissions(proxy string) []storage.PermissionConfig {
+	var permissions []storage.PermissionConfig
+	for _, name := range s.tools.names() {
+		if proxy != "" && name != proxy {
+			continue
+		}
+		registered, _ := s.tools.get(name)
+		for _, tool := range registered.Tools {
+			readOnly := tool.Tool.Annotations.ReadOnlyHint
+			if readOnly == nil || !*readOnly {
+				continue
+			}
+			permissions = append(permissions, storage.PermissionConfig{
+				ObjectType: storage.ObjectTypeTools,
+				Proxy:      name,
+				ObjectName: strings.TrimPrefix(tool.Tool.Name, name+":"),
+			})
+		}
+	}
+	return permissions
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRoleFromTemplate(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Storage = storage.NewMemoryStorage("")
+	for _, name := range []string{"github", "jira"} {
+		require.NoError(t, srv.Storage.SetProxy(t.Context(), &storage.ProxyConfig{
+			Name: name, Type: storage.ProxyTypeStreamableHTTP, URL: "https://" + name + ".example.com/mcp", AuthType: storage.ProxyAuthTypeHeader,
+		}, false))
+	}
+	require.NoError(t, srv.Storage.SetRole(t.Context(), storage.RoleConfig{Name: "existing"}))
+	srv.tools = newToolRegistry()
+	srv.tools.set("github", []server.ServerTool{
+		{Tool: mcp.NewTool("github:search", mcp.WithReadOnlyHintAnnotation(true))},
+		{Tool: mcp.NewTool("github:create_issue")},
+	}, time.Now())
+	srv.tools.set("jira", []server.ServerTool{
+		{Tool: mcp.NewTool("jira:get_issue", mcp.WithReadOnlyHintAnnotation(true))},
+	}, time.Now())
+
+	for _, test := range []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedRole string
+	}{
+		{name: "admin", body: `{"template":"admin","name":"admins"}`, expectedCode: http.StatusCreated,
+			expectedRole: `{"name":"admins","permissions":[{"object_type":"*","proxy":"*","object_name":"*"}],"clearance":"confidential"}`},
+		{name: "operator of a proxy", body: `{"template":"operator","name":"github-operators","proxy":"github"}`, expectedCode: http.StatusCreated,
+			expectedRole: `{"name":"github-operators","permissions":[{"object_type":"tools","proxy":"github","object_name":"*"}],"clearance":"internal"}`},
+		{name: "read-only", body: `{"template":"read-only","name":"viewers","clearance":"public"}`, expectedCode: http.StatusCreated,
+			expectedRole: `{"name":"viewers","permissions":[{"object_type":"tools","proxy":"github","object_name":"search"},` +
+				`{"object_type":"tools","proxy":"jira","object_name":"get_issue"}],"clearance":"public"}`},
+		{name: "per-proxy-user", body: `{"template":"per-proxy-user","name":"jira-users","proxy":"jira"}`, expectedCode: http.StatusCreated,
+			expectedRole: `{"name":"jira-users","permissions":[{"object_type":"tools","proxy":"jira","object_name":"*"}],"clearance":"public"}`},
+		{name: "per-proxy-user without proxy", body: `{"template":"per-proxy-user","name":"users"}`, expectedCode: http.StatusBadRequest},
+		{name: "unknown proxy", body: `{"template":"per-proxy-user","name":"users","proxy":"slack"}`, expectedCode: http.StatusNotFound},
+		{name: "admin of a proxy", body: `{"template":"admin","name":"users","proxy":"jira"}`, expectedCode: http.StatusBadRequest},
+		{name: "unknown template", body: `{"template":"superuser","name":"users"}`, expectedCode: http.StatusBadRequest},
+		{name: "invalid clearance", body: `{"template":"operator","name":"users","clearance":"secret"}`, expectedCode: http.StatusBadRequest},
+		{name: "without name", body: `{"template":"operator"}`, expectedCode: http.StatusBadRequest},
+		{name: "existing role", body: `{"template":"operator","name":"existing"}`, expectedCode: http.StatusConflict},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/roles/from-template", strings.NewReader(test.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, srv.createRoleFromTemplate(srv.Router.NewContext(req, rec)))
+			assert.Equal(t, test.expectedCode, rec.Code, rec.Body.String())
+			if test.expectedCode != http.StatusCreated {
+				return
+			}
+			assert.JSONEq(t, test.expectedRole, rec.Body.String())
+			var role storage.RoleConfig
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &role))
+			stored, err := srv.Storage.GetRole(t.Context(), role.Name)
+			require.NoError(t, err)
+			assert.Equal(t, role, stored)
+		})
+	}
+}
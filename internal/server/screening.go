@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/screening"
+	"go.uber.org/zap"
+)
+
+// configureScreening configures the screening of the tool call arguments for deny patterns
+func (s *Server) configureScreening() {
+	if !s.Config.Screening.Enabled {
+		return
+	}
+	screener, err := screening.New(s.Config.Screening)
+	if err != nil {
+		s.Logger.Error("Failed to create the request screener", zap.Error(err))
+		panic(err)
+	}
+	s.Screener = screener
+}
+
+// screeningHandler screens the arguments of the tool calls before they are forwarded upstream, rejecting the
+// blocked calls and logging the flagged ones.
+func (s *Server) screeningHandler(proxyName, toolName string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.Screener == nil {
+			return next(ctx, request)
+		}
+		claims, _ := ctx.Value("claims").(map[string]interface{})
+		verdict, err := s.Screener.Screen(ctx, screening.Call{
+			Proxy:     proxyName,
+			Tool:      toolName,
+			Arguments: request.GetArguments(),
+			Claims:    claims,
+		})
+		if err != nil {
+			// A call which could not be screened is not forwarded
+			s.Logger.Error("Failed to screen the tool call", zap.String("proxy", proxyName), zap.String("tool", toolName), zap.Error(err))
+			return mcp.NewToolResultError("Unable to screen the tool call"), nil
+		}
+		if verdict.Action == screening.ActionAllow {
+			return next(ctx, request)
+		}
+
+		metrics.ToolCallsScreenedCounter.WithLabelValues(proxyName, verdict.Rule, string(verdict.Action)).Inc()
+		fields := []zap.Field{
+			zap.String("proxy", proxyName),
+			zap.String("tool", toolName),
+			zap.String("identity", identityFromClaims(claims)),
+			zap.String("rule", verdict.Rule),
+			zap.String("reason", verdict.Reason),
+		}
+		if verdict.Action == screening.ActionBlock {
+			s.Logger.Warn("Tool call blocked by the request screening", fields...)
+			return mcp.NewToolResultError("Tool call blocked by the request screening: " + verdict.Reason), nil
+		}
+		s.Logger.Warn("Tool call flagged by the request screening", fields...)
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/metrics"
+	"github.com/matthisholleville/mcp-gateway/internal/screening"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingScreener struct{}
+
+func (failingScreener) Screen(context.Context, screening.Call) (screening.Verdict, error) {
+	return screening.Verdict{}, errors.New("screening service unavailable")
+}
+
+func TestScreeningHandler(t *testing.T) {
+	defer metrics.ToolCallsScreenedCounter.Reset()
+	srv := createTestServer(false, &MockProvider{})
+	called := 0
+	handler := srv.screeningHandler("github", "search", func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called++
+		return mcp.NewToolResultText("called"), nil
+	})
+	call := func(arguments map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+	injection := map[string]any{"query": "1 UNION SELECT password FROM users"}
+
+	// Without a screener, the calls are forwarded as is
+	assert.False(t, call(injection).IsError)
+	assert.Equal(t, 1, called)
+
+	screener, err := screening.New(&cfg.ScreeningConfig{Rules: cfg.ScreeningRules, Action: cfg.ScreeningActionBlock})
+	require.NoError(t, err)
+	srv.Screener = screener
+	assert.False(t, call(map[string]any{"query": "mcp gateway"}).IsError)
+	result := call(injection)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "blocked by the request screening")
+	assert.Equal(t, 2, called)
+	assert.InDelta(t, 1, testutil.ToFloat64(metrics.ToolCallsScreenedCounter.WithLabelValues("github", cfg.ScreeningRuleSQLInjection, "block")), 0)
+
+	screener, err = screening.New(&cfg.ScreeningConfig{Rules: cfg.ScreeningRules, Action: cfg.ScreeningActionFlag})
+	require.NoError(t, err)
+	srv.Screener = screener
+	assert.False(t, call(injection).IsError)
+	assert.Equal(t, 3, called)
+	assert.InDelta(t, 1, testutil.ToFloat64(metrics.ToolCallsScreenedCounter.WithLabelValues("github", cfg.ScreeningRuleSQLInjection, "flag")), 0)
+
+	// A call which could not be screened is not forwarded
+	srv.Screener = failingScreener{}
+	assert.True(t, call(map[string]any{"query": "mcp gateway"}).IsError)
+	assert.Equal(t, 3, called)
+}
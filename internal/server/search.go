@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+)
+
+// The kinds of the configuration objects matched by a search.
+const (
+	searchKindProxy            = "proxy"
+	searchKindRole             = "role"
+	searchKindPermission       = "permission"
+	searchKindAttributeToRoles = "attribute_to_roles"
+)
+
+// SearchResult is a field of the configuration matching a search.
+type SearchResult struct {
+	// Kind is proxy, role, permission or attribute_to_roles.
+	Kind string `json:"kind"`
+	// Name is the proxy or role name, or the attribute_key=attribute_value of a mapping.
+	Name string `json:"name"`
+	// Field is the matching field, e.g. url, labels.team or object_name.
+	Field string `json:"field"`
+	Value string `json:"value"`
+	// Permission is the matching permission of the role, for the permission results.
+	Permission *storage.PermissionConfig `json:"permission,omitempty"`
+}
+
+// @Summary		Search the configuration
+// @Description	Search case-insensitively the proxy names, URLs, labels, descriptions and owners, the role names, the proxies and object names of their permissions, and the attributes and roles of the attribute-to-roles mappings. A permission granted with a wildcard or a label selector only matches the query if its text does: use /v1/admin/authz/check for the effective decision.
+// @Tags			search
+// @Accept			json
+// @Produce		json
+// @Param			q	query		string	true	"Text to search"
+// @Success		200	{array}		SearchResult
+// @Failure		400	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/search [get]
+func (s *Server) searchConfig(c echo.Context) error {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "q is required"})
+	}
+	results, err := searchConfig(c.Request().Context(), s.Storage, query)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, results)
+}
+
+// searchConfig returns the fields of the proxies, roles and attribute-to-roles mappings containing the query,
+// whatever its case, sorted by kind and name.
+func searchConfig(ctx context.Context, store storage.Interface, query string) ([]SearchResult, error) {
+	proxies, err := store.ListProxies(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := store.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mappings, err := store.ListAttributeToRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	results := []SearchResult{}
+	match := func(kind, name, field, value string, permission *storage.PermissionConfig) {
+		if strings.Contains(strings.ToLower(value), query) {
+			results = append(results, SearchResult{Kind: kind, Name: name, Field: field, Value: value, Permission: permission})
+		}
+	}
+	for i := range proxies {
+		p := &proxies[i]
+		match(searchKindProxy, p.Name, "name", p.Name, nil)
+		match(searchKindProxy, p.Name, "url", p.URL, nil)
+		match(searchKindProxy, p.Name, "description", p.Description, nil)
+		match(searchKindProxy, p.Name, "owner", p.Owner, nil)
+		keys := make([]string, 0, len(p.Labels))
+		for key := range p.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			match(searchKindProxy, p.Name, "labels."+key, key+"="+p.Labels[key], nil)
+		}
+	}
+	for _, role := range roles {
+		match(searchKindRole, role.Name, "name", role.Name, nil)
+		for _, permission := range role.Permissions {
+			match(searchKindPermission, role.Name, "proxy", permission.Proxy, &permission)
+			match(searchKindPermission, role.Name, "object_name", permission.ObjectName, &permission)
+		}
+	}
+	for _, mapping := range mappings {
+		name := mapping.AttributeKey + "=" + mapping.AttributeValue
+		match(searchKindAttributeToRoles, name, "attribute_key", mapping.AttributeKey, nil)
+		match(searchKindAttributeToRoles, name, "attribute_value", mapping.AttributeValue, nil)
+		for _, role := range mapping.Roles {
+			match(searchKindAttributeToRoles, name, "roles", role, nil)
+		}
+	}
+
+	kinds := []string{searchKindProxy, searchKindRole, searchKindPermission, searchKindAttributeToRoles}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Kind != results[j].Kind {
+			return slices.Index(kinds, results[i].Kind) < slices.Index(kinds, results[j].Kind)
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results, nil
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchConfig(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Storage = storage.NewMemoryStorage("")
+	ctx := t.Context()
+	require.NoError(t, srv.Storage.SetProxy(ctx, &storage.ProxyConfig{
+		Name: "github", Type: storage.ProxyTypeStreamableHTTP, URL: "https://github-mcp.example.com/mcp",
+		AuthType: storage.ProxyAuthTypeHeader, Labels: map[string]string{"team": "platform"},
+	}, false))
+	require.NoError(t, srv.Storage.SetRole(ctx, storage.RoleConfig{Name: "developer", Permissions: []storage.PermissionConfig{
+		{ObjectType: storage.ObjectTypeTools, Proxy: "github", ObjectName: "create_issue"},
+		{ObjectType: storage.ObjectTypeTools, Proxy: "*", ObjectName: "create_ticket"},
+	}}))
+	require.NoError(t, srv.Storage.SetRole(ctx, storage.RoleConfig{Name: "github-admin", Permissions: []storage.PermissionConfig{
+		{ObjectType: storage.ObjectTypeTools, Proxy: "github", ObjectName: "*"},
+	}}))
+	require.NoError(t, srv.Storage.SetAttributeToRoles(ctx, storage.AttributeToRolesConfig{
+		AttributeKey: "groups", AttributeValue: "Platform-Team", Roles: []string{"developer"},
+	}))
+
+	search := func(query string) (int, []SearchResult) {
+		rec := httptest.NewRecorder()
+		require.NoError(t, srv.searchConfig(srv.Router.NewContext(httptest.NewRequest(http.MethodGet, "/v1/admin/search"+query, nil), rec)))
+		var results []SearchResult
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+		}
+		return rec.Code, results
+	}
+
+	code, results := search("?q=CREATE_ISSUE")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, results, 1)
+	assert.Equal(t, SearchResult{Kind: "permission", Name: "developer", Field: "object_name", Value: "create_issue",
+		Permission: &storage.PermissionConfig{ObjectType: storage.ObjectTypeTools, Proxy: "github", ObjectName: "create_issue"}}, results[0])
+
+	_, results = search("?q=platform")
+	assert.Equal(t, []SearchResult{
+		{Kind: "proxy", Name: "github", Field: "labels.team", Value: "team=platform"},
+		{Kind: "attribute_to_roles", Name: "groups=Platform-Team", Field: "attribute_value", Value: "Platform-Team"},
+	}, results)
+
+	_, results = search("?q=github")
+	fields := []string{}
+	for _, result := range results {
+		fields = append(fields, result.Kind+"/"+result.Name+"/"+result.Field)
+	}
+	assert.Equal(t, []string{
+		"proxy/github/name", "proxy/github/url", "role/github-admin/name",
+		"permission/developer/proxy", "permission/github-admin/proxy",
+	}, fields)
+
+	_, results = search("?q=nothing")
+	assert.Empty(t, results)
+	code, _ = search("?q=%20")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -5,26 +5,41 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo-contrib/echoprometheus"
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/matthisholleville/mcp-gateway/internal/auth"
+	"github.com/matthisholleville/mcp-gateway/internal/authcache"
 	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/devserver"
+	"github.com/matthisholleville/mcp-gateway/internal/eventexport"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/matthisholleville/mcp-gateway/internal/guardrail"
 	"github.com/matthisholleville/mcp-gateway/internal/metrics"
 	"github.com/matthisholleville/mcp-gateway/internal/proxy"
+	"github.com/matthisholleville/mcp-gateway/internal/redact"
+	"github.com/matthisholleville/mcp-gateway/internal/screening"
+	"github.com/matthisholleville/mcp-gateway/internal/secrets"
 	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/matthisholleville/mcp-gateway/internal/ui"
 	"github.com/matthisholleville/mcp-gateway/pkg/aescipher"
+	"github.com/matthisholleville/mcp-gateway/pkg/apikey"
+	"github.com/matthisholleville/mcp-gateway/pkg/fips"
 	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/matthisholleville/mcp-gateway/pkg/version"
 	_ "github.com/matthisholleville/mcp-gateway/swagger" // We need to import the swagger documentation
+	"github.com/prometheus/client_golang/prometheus"
 	echoSwagger "github.com/swaggo/echo-swagger"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 //	@title			MCP Gateway API
@@ -50,8 +65,77 @@ type Server struct {
 	Storage   storage.Interface
 	Encryptor aescipher.Cryptor
 	Provider  auth.Provider
+	Redactor  *redact.Redactor
+	// Masker masks the secrets in the tool results, if enabled
+	Masker *redact.Masker
+	// Screener screens the tool call arguments before they are forwarded upstream, if enabled
+	Screener screening.Screener
+	// AppCheck verifies the App Check tokens of the requests, if the auth provider requires them
+	AppCheck auth.AppCheckVerifier
+
+	adminIPAccess *ipAccessList
+	tools         *toolRegistry
+	approvals     *approvalWaiters
+	// auditLog chains the audited events to the audit log of the storage, if enabled
+	auditLog bool
+	// traces keeps the detailed traces of the tool calls in the storage, if enabled
+	traces      bool
+	guardrail   *guardrail.Client
+	grpcServer  *grpc.Server
+	eventBroker *events.Broker
+	logLevels   *logLevelStore
+	drainer     *drainer
+	// protectedResources are the OAuth protected resources, built on first use
+	protectedResourcesOnce sync.Once
+	protectedResources     []*protectedResource
+	// cancellations are the in-flight tool calls, cancelled by the cancellation notifications of their client
+	cancellations *cancellations
+	// upstreamSessions are the sessions of the callers on the stateful upstream servers, if enabled
+	upstreamSessions *proxy.Sessions
+	reloadable       reloadable
+	reloadMu         sync.Mutex
+	configLoader     ConfigLoader
+	secrets          *secrets.Resolver
+	adminKeyOnce     sync.Once
+	adminKey         *apikey.Verifier
+	debugServer      *http.Server
+	eventExporter    *eventexport.Exporter
+	otlpPusher       *metrics.OTLPPusher
+	// identityLabels counts the tool calls by role and subject, if enabled
+	identityLabels *metrics.IdentityLabels
+	// slo computes the service level indicators of the proxies, if enabled
+	slo *metrics.SLO
+	// proxySyncLeader is whether the replica leads the proxy sync loop, and followerProxies its proxies while it
+	// follows. Both are only used by the loop.
+	proxySyncLeader bool
+	followerProxies map[string]followerProxy
+	// authCache caches the roles, mappings and verified tokens in Redis, if enabled
+	authCache *authcache.Cache
+	// sessions issues and checks the MCP session IDs, if the sessions are stateful
+	sessions *sessionManager
+	// devServer is the sample MCP server of the dev mode, if enabled
+	devServer *devserver.Server
+	// endpoints are the addresses of the upstream servers, to reconnect to them when they change, if enabled
+	endpoints *proxy.Endpoints
+	// proxiesSynced is whether a refresh of the proxies completed, gating the readiness if enabled
+	proxiesSynced atomic.Bool
+	// authHealthChecks is whether the auth provider is checked, and authProviderHealthy whether its last check
+	// succeeded, gating the readiness
+	authHealthChecks    bool
+	authProviderHealthy atomic.Bool
+	// backendUnavailable is whether the backend was unreachable at startup and is still being retried, gating the
+	// readiness
+	backendUnavailable atomic.Bool
 }
 
+const (
+	// anonymousIdentity is the identity of the unauthenticated callers in the usage statistics.
+	anonymousIdentity     = "anonymous"
+	recordToolCallTimeout = 5 * time.Second
+	// eventBufferSize is the number of events buffered for each operator watching the event stream.
+	eventBufferSize = 256
+)
+
 func NewServer(
 	log logger.Logger,
 	config *cfg.Config,
@@ -59,31 +143,121 @@ func NewServer(
 ) (*Server, error) {
 	router := echo.New()
 	s := &Server{
-		Logger: log,
-		Config: config,
-		Router: router,
+		Logger:        log,
+		Config:        config,
+		Router:        router,
+		tools:         newToolRegistry(),
+		approvals:     newApprovalWaiters(),
+		eventBroker:   events.NewBroker(eventBufferSize),
+		logLevels:     newLogLevelStore(),
+		drainer:       newDrainer(),
+		cancellations: newCancellations(),
+		secrets:       secrets.NewResolver(config.Vault, config.Secrets),
+		// The upstream sessions outlive the proxies, which are recreated on each refresh.
+		upstreamSessions: proxy.NewSessions(config.Proxy, log),
 	}
+	s.endpoints = proxy.NewEndpoints(config.Proxy, s.upstreamSessions, log)
+	s.upstreamSessions.StartKeepalive()
 
 	s.configureRouter()
+	s.configureClientIP()
+	s.configureAdminIPAccess()
+	s.configureRedaction()
+	s.configureMasking()
+	s.configureScreening()
+	s.configureGuardrail()
+	s.configureCrypto()
 	s.configureEncryption()
 	s.configureStorage()
+	s.configureAuthCache()
+	s.configureDevMode()
 	s.configureMetrics()
+	s.configureEventExport()
+	s.configureAuditLog()
+	s.configureTraces()
 	s.registerHealthcheckRoutes()
-	s.withCORSMiddleware()
+	s.configureReloadable()
 	s.configureSwaggerRoutes()
 	s.configureV1Routes()
+	s.configureAdminUI()
+	s.configureConcurrencyLimit()
 	s.configureAuthMiddleware()
 	s.withOAuthProtectedResources()
+	s.withAuthorizationServerMetadata()
+	s.configureSessions()
 	s.configureMCP()
+	s.configureGRPC()
 	return s, nil
 }
 
 // ListenAndServe starts the server
 func (s *Server) ListenAndServe() error {
-	s.Logger.Info("Starting server", zap.String("host", s.Config.HTTP.Addr))
+	if err := s.serveGRPC(); err != nil {
+		return err
+	}
+	if err := s.serveDebug(); err != nil {
+		return err
+	}
+	info := version.Get()
+	s.Logger.Info("Starting server",
+		zap.String("host", s.Config.HTTP.Addr),
+		zap.String("version", info.Version),
+		zap.String("revision", info.Revision),
+		zap.String("build_date", info.BuildDate),
+		zap.String("go_version", info.GoVersion))
 	return s.Router.Start(s.Config.HTTP.Addr)
 }
 
+// Shutdown stops the gateway gracefully. The gateway is marked not ready and the /mcp requests are drained:
+// the new ones are rejected and the in-flight ones are aborted after the drain timeout. The gRPC and HTTP servers
+// are then stopped.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.Ready != nil {
+		atomic.StoreInt32(s.Ready, 0)
+	}
+
+	drainCtx, cancel := context.WithTimeout(ctx, s.Config.HTTP.Timeouts.Drain)
+	defer cancel()
+	s.Logger.Info("Draining in-flight MCP requests", zap.Duration("timeout", s.Config.HTTP.Timeouts.Drain))
+	if aborted := s.drainer.drain(drainCtx); aborted > 0 {
+		s.Logger.Warn("Aborted in-flight MCP requests", zap.Int("aborted", aborted))
+	}
+
+	if s.grpcServer != nil {
+		s.grpcServer.GracefulStop()
+	}
+	if s.Config.Proxy.LeaderElection {
+		// Another replica takes over the proxy sync without waiting for the connection to be closed.
+		if err := s.Storage.ReleaseLeadership(ctx, storage.ProxySyncLeadership); err != nil {
+			s.Logger.Warn("Failed to release the leadership of the proxy sync", zap.Error(err))
+		}
+	}
+	if s.debugServer != nil {
+		_ = s.debugServer.Close()
+	}
+	if s.otlpPusher != nil {
+		if err := s.otlpPusher.Shutdown(ctx); err != nil {
+			s.Logger.Warn("Failed to push the metrics with OTLP on shutdown", zap.Error(err))
+		}
+	}
+	if s.authCache != nil {
+		_ = s.authCache.Close()
+	}
+	if s.sessions != nil {
+		_ = s.sessions.close()
+	}
+	s.upstreamSessions.Close()
+	if s.devServer != nil {
+		_ = s.devServer.Shutdown(ctx)
+	}
+	if s.eventExporter != nil {
+		if err := s.eventExporter.Close(ctx); err != nil {
+			s.Logger.Warn("Failed to close the event export sink", zap.Error(err))
+		}
+	}
+	return s.Router.Shutdown(ctx)
+}
+
 func (s *Server) GetRouter() *echo.Echo {
 	return s.Router
 }
@@ -98,6 +272,14 @@ func (s *Server) configureRouter() {
 	s.Router.HideBanner = true
 	s.Router.HidePort = true
 	s.Router.Host(s.Config.HTTP.Addr)
+
+	// The connection-level read and write timeouts are the longest ones, used by /mcp.
+	// Shorter routes narrow them with requestTimeoutMiddleware.
+	timeouts := s.Config.HTTP.Timeouts
+	s.Router.Server.ReadHeaderTimeout = timeouts.ReadHeader
+	s.Router.Server.ReadTimeout = timeouts.MCP
+	s.Router.Server.WriteTimeout = timeouts.MCP
+	s.Router.Server.IdleTimeout = timeouts.Idle
 }
 
 // registerHealthcheckRoutes registers the healthcheck routes
@@ -114,122 +296,316 @@ func (s *Server) registerHealthcheckRoutes() {
 		return echo.NewHTTPError(http.StatusServiceUnavailable, "KO")
 	}))
 	s.Router.GET("/ready", echo.HandlerFunc(func(_ echo.Context) error {
-		if atomic.LoadInt32(s.Ready) == 1 {
+		if s.ready() {
 			return echo.NewHTTPError(http.StatusOK, "OK")
 		}
 		return echo.NewHTTPError(http.StatusServiceUnavailable, "KO")
 	}))
+	s.Router.GET("/version", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, version.Get())
+	})
 }
 
-// WithCORSMiddleware adds CORS middleware to the router
-func (s *Server) withCORSMiddleware() {
-	if !s.Config.HTTP.CORS.Enabled {
-		s.Logger.Warn("CORS is disabled")
-		return
-	}
-
-	s.Router.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: s.Config.HTTP.CORS.AllowedOrigins,
-		AllowMethods: s.Config.HTTP.CORS.AllowedMethods,
-		AllowHeaders: s.Config.HTTP.CORS.AllowedHeaders,
-	}))
-}
-
-// withOAuthProtectedResources adds OAuth protected resources to the router
-func (s *Server) withOAuthProtectedResources() {
-	if !s.Config.OAuth.Enabled {
-		s.Logger.Warn("OAuth is disabled. Skipping OAuth protected resources.")
-		return
-	}
-
-	meta := map[string]any{
-		"resource":                 s.Config.OAuth.Resource,
-		"authorization_servers":    s.Config.OAuth.AuthorizationServers,
-		"bearer_methods_supported": s.Config.OAuth.BearerMethodsSupported,
-		"scopes_supported":         s.Config.OAuth.ScopesSupported,
+// ready returns whether the server accepts traffic. It waits for the backend unreachable at startup to connect. If
+// enabled, it waits for the backend to be reachable and the first refresh of the proxies to complete, so a rolling
+// deploy does not route the clients to a replica exposing no tools, and for the auth provider to be healthy.
+func (s *Server) ready() bool {
+	if s.Ready == nil || atomic.LoadInt32(s.Ready) != 1 || s.backendUnavailable.Load() {
+		return false
 	}
-	wellKnown := func(c echo.Context) error {
-		c.Response().Header().Set("Content-Type", "application/json")
-		return c.JSON(http.StatusOK, meta)
+	if s.authHealthChecks && !s.authProviderHealthy.Load() {
+		return false
 	}
-
-	s.Router.GET("/.well-known/oauth-protected-resource", wellKnown)
-	s.Router.HEAD("/.well-known/oauth-protected-resource", wellKnown)
-	s.Router.OPTIONS("/.well-known/oauth-protected-resource", func(c echo.Context) error {
-		return c.NoContent(http.StatusNoContent)
-	})
+	return !s.Config.Proxy.ReadyAfterSync || s.proxiesSynced.Load()
 }
 
 // configureMetrics configures the metrics endpoint
 func (s *Server) configureMetrics() {
+	s.identityLabels = metrics.NewIdentityLabels(s.Config.Metrics)
 	customMetrics := metrics.NewMetrics()
 	err := customMetrics.RegisterCustomMetrics()
 	if err != nil {
 		s.Logger.Error("Failed to register metrics", zap.Error(err))
 	}
-	s.Router.GET("/metrics", echoprometheus.NewHandler())
+	s.slo = metrics.NewSLO(s.Config.SLO)
+	if err := customMetrics.RegisterSLO(s.slo); err != nil {
+		s.Logger.Error("Failed to register the SLO metrics", zap.Error(err))
+	}
+	if s.Config.Metrics.OTLPEndpoint != "" {
+		pusher, err := metrics.NewOTLPPusher(context.Background(), s.Config.Metrics)
+		if err != nil {
+			s.Logger.Error("Failed to push the metrics with OTLP", zap.Error(err))
+		} else {
+			s.otlpPusher = pusher
+			s.Logger.Info("Pushing the metrics with OTLP",
+				zap.String("endpoint", s.Config.Metrics.OTLPEndpoint),
+				zap.Duration("interval", s.Config.Metrics.OTLPInterval))
+		}
+	}
+	httpMetrics, err := metrics.NewHTTPMiddleware(prometheus.DefaultRegisterer)
+	if err != nil {
+		s.Logger.Error("Failed to register the HTTP metrics", zap.Error(err))
+	} else {
+		s.Router.Use(httpMetrics)
+	}
+	s.Router.GET("/metrics", echoprometheus.NewHandler(), s.adminIPAccessMiddlewares(s.Config.HTTP.AdminIPAccess.ProtectMetrics)...)
 }
 
 // configureMCP configures the MCP endpoint
 func (s *Server) configureMCP() {
 	mcpServer := server.NewMCPServer(
 		"MCP Gateway",
-		"1.0.0",
+		version.VERSION,
 		server.WithToolCapabilities(true),
+		server.WithLogging(),
 		server.WithHooks(s.mcpHooks()),
 	)
 
-	serverConfig := server.NewStreamableHTTPServer(
-		mcpServer,
+	options := []server.StreamableHTTPOption{
 		server.WithEndpointPath("/mcp"),
 		server.WithHTTPContextFunc(s.addGlobalMCPContext),
-		server.WithStateLess(true),
-	)
+	}
+	if s.sessions != nil {
+		options = append(options, server.WithSessionIdManager(s.sessions))
+	} else {
+		options = append(options, server.WithStateLess(true))
+	}
+	options = append(options, s.keepaliveOptions()...)
+	serverConfig := server.NewStreamableHTTPServer(mcpServer, options...)
 
 	go s.addProxyTools(mcpServer)
 
-	s.Router.GET("/mcp", echo.WrapHandler(serverConfig))
-	s.Router.HEAD("/mcp", echo.WrapHandler(serverConfig))
-	s.Router.OPTIONS("/mcp", func(c echo.Context) error {
-		return c.NoContent(http.StatusNoContent)
-	})
-	s.Router.POST("/mcp", echo.WrapHandler(serverConfig))
+	var handler http.Handler = serverConfig
+	postHandler := s.drainHandler(batchHandler(s.cancellationHandler(s.completionHandler(serverConfig))))
+	if s.sessions != nil {
+		handler = echoSessionID(handler)
+		postHandler = echoSessionID(postHandler)
+	}
+
+	// The other OAuth protected resources are served on their own path.
+	for _, path := range s.mcpPaths() {
+		if s.sessions != nil {
+			s.Router.DELETE(path, echo.WrapHandler(handler))
+		}
+		s.Router.GET(path, echo.WrapHandler(s.clientStreamHandler(handler)))
+		s.Router.HEAD(path, echo.WrapHandler(handler))
+		s.Router.OPTIONS(path, func(c echo.Context) error {
+			return c.NoContent(http.StatusNoContent)
+		})
+		s.Router.POST(path, echo.WrapHandler(postHandler))
+	}
 }
 
-// addProxyTools adds the proxy tools to the MCP server.
+// addProxyTools adds the proxy tools to the MCP server, at startup then every cache TTL.
 func (s *Server) addProxyTools(mcpServer *server.MCPServer) {
+	var previousNames []string
 	for {
-		time.Sleep(s.Config.Proxy.CacheTTL)
 		s.Logger.Info("Refreshing MCP proxies")
-		proxies, err := s.Storage.ListProxies(context.Background(), true)
+		proxyNames, err := s.refreshProxies(mcpServer, previousNames)
 		if err != nil {
 			s.Logger.Error("Failed to get MCP proxies", zap.Error(err))
-			continue
+		} else {
+			previousNames = proxyNames
+			s.proxiesSynced.Store(true)
 		}
-		if len(proxies) == 0 {
-			s.Logger.Info("No MCP proxies found. Deleting all tools.")
-			mcpServer.DeleteTools()
-			continue
+		time.Sleep(s.cacheTTL())
+	}
+}
+
+// refreshProxies registers the tools of the proxies of the storage, returning their names.
+func (s *Server) refreshProxies(mcpServer *server.MCPServer, previousNames []string) ([]string, error) {
+	proxies, err := s.Storage.ListProxies(context.Background(), true)
+	if err != nil {
+		return nil, err
+	}
+	if len(proxies) == 0 {
+		s.Logger.Info("No MCP proxies found. Deleting all tools.")
+		mcpServer.DeleteTools()
+		s.tools.retain(nil)
+		s.syncMetaTools(mcpServer)
+		s.deleteRemovedProxyMetrics(previousNames, nil)
+		return nil, nil
+	}
+	proxyNames := make([]string, 0, len(proxies))
+	for _, p := range proxies {
+		proxyNames = append(proxyNames, p.Name)
+	}
+	if removed := s.tools.retain(append([]string{storage.CompositeProxy, storage.MetaProxy}, proxyNames...)); len(removed) > 0 {
+		mcpServer.DeleteTools(removed...)
+	}
+	s.syncCompositeTools(mcpServer)
+	s.syncMetaTools(mcpServer)
+	s.deleteRemovedProxyMetrics(previousNames, proxyNames)
+	// The proxies are recreated below, except the ones of a follower, recreated when their address changed.
+	for _, name := range s.endpoints.Refresh(context.Background(), proxies) {
+		delete(s.followerProxies, name)
+	}
+	if !s.leadsProxySync() {
+		s.loadSharedProxyTools(mcpServer, proxies)
+		return proxyNames, nil
+	}
+	mcpProxy, err := proxy.NewProxy(&proxies, s.Config.Proxy, s.secrets, s.upstreamSessions, s.Logger, s.forwardUpstreamLog)
+	if err != nil {
+		s.Logger.Error("Failed to create MCP proxy", zap.Error(err))
+		return proxyNames, nil
+	}
+	connected := make(map[string]bool, len(*mcpProxy))
+	for _, proxy := range *mcpProxy {
+		connected[proxy.GetName()] = true
+	}
+	for _, name := range proxyNames {
+		if !connected[name] {
+			s.eventBroker.Publish(events.TypeProxyHealth, events.ProxyHealth{Proxy: name, Error: "unable to connect to MCP server"})
 		}
-		mcpProxy, err := proxy.NewProxy(&proxies, s.Logger)
+	}
+	configs := make(map[string]*storage.ProxyConfig, len(proxies))
+	for i := range proxies {
+		configs[proxies[i].Name] = &proxies[i]
+	}
+	for _, proxy := range *mcpProxy {
+		proxyTools, err := proxy.GetTools()
 		if err != nil {
-			s.Logger.Error("Failed to create MCP proxy", zap.Error(err))
+			s.Logger.Error("Failed to get MCP proxy tools", zap.Error(err))
+			s.eventBroker.Publish(events.TypeProxyHealth, events.ProxyHealth{Proxy: proxy.GetName(), Error: err.Error()})
 			continue
 		}
-		for _, proxy := range *mcpProxy {
-			proxyTools, err := proxy.GetTools()
-			if err != nil {
-				s.Logger.Error("Failed to get MCP proxy tools", zap.Error(err))
-				continue
-			}
-			for i := range proxyTools {
-				tool := proxyTools[i]
-				toolName := proxy.GetName() + ":" + tool.Name
-				tool.Name = toolName
-				s.Logger.Debug("Adding tool", zap.String("tool", toolName))
-				mcpServer.AddTool(tool, proxy.CallTool)
+		serverTools := s.serverTools(proxy, configs[proxy.GetName()], proxyTools)
+		s.syncProxyTools(mcpServer, proxy.GetName(), serverTools)
+		templates := s.syncProxyResourceTemplates(mcpServer, proxy)
+		s.tools.setCompleter(proxy.GetName(), proxy)
+		s.shareProxyTools(proxy.GetName(), proxyTools, templates)
+		s.eventBroker.Publish(events.TypeProxyHealth, events.ProxyHealth{Proxy: proxy.GetName(), Healthy: true, Tools: len(serverTools)})
+	}
+	return proxyNames, nil
+}
+
+// callProxy is a proxy whose tools are called through the gateway.
+type callProxy interface {
+	GetName() string
+	CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+}
+
+// serverTools returns the tools of a proxy registered on the MCP server, prefixed by the proxy name, with the
+// description of the proxy appended to theirs if enabled.
+func (s *Server) serverTools(p callProxy, config *storage.ProxyConfig, tools []mcp.Tool) []server.ServerTool {
+	var about string
+	if s.Config.Proxy.DescribeTools && config != nil {
+		about = describeProxy(config)
+	}
+	serverTools := make([]server.ServerTool, 0, len(tools))
+	for i := range tools {
+		tool := tools[i]
+		handler := s.toolHandler(p.GetName(), tool.Name, p.CallTool)
+		toolName := p.GetName() + ":" + tool.Name
+		tool.Name = toolName
+		if about != "" {
+			tool.Description = strings.TrimSpace(tool.Description + "\n\n" + about)
+		}
+		s.Logger.Debug("Adding tool", zap.String("tool", toolName))
+		serverTools = append(serverTools, server.ServerTool{Tool: tool, Handler: handler})
+	}
+	return serverTools
+}
+
+// describeProxy returns the description, owner and documentation link of a proxy appended to its tool
+// descriptions, empty if it has none.
+func describeProxy(config *storage.ProxyConfig) string {
+	var parts []string
+	if config.Description != "" {
+		parts = append(parts, strings.TrimSuffix(config.Description, ".")+".")
+	}
+	if config.Owner != "" {
+		parts = append(parts, "Owner: "+config.Owner+".")
+	}
+	if config.DocsURL != "" {
+		parts = append(parts, "Documentation: "+config.DocsURL)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Provided by " + config.Name + ": " + strings.Join(parts, " ")
+}
+
+// deleteRemovedProxyMetrics deletes the upstream connection and SLO metrics of the proxies which were removed.
+func (s *Server) deleteRemovedProxyMetrics(previous, current []string) {
+	for _, name := range previous {
+		if !slices.Contains(current, name) {
+			metrics.DeleteUpstreamMetrics(name)
+			s.slo.DeleteProxy(name)
+		}
+	}
+}
+
+// syncProxyTools replaces the tools registered for a proxy, deleting the ones the upstream no longer exposes.
+func (s *Server) syncProxyTools(mcpServer *server.MCPServer, proxyName string, tools []server.ServerTool) {
+	if previous, ok := s.tools.get(proxyName); ok {
+		var stale []string
+		for _, old := range previous.Tools {
+			if !slices.ContainsFunc(tools, func(t server.ServerTool) bool { return t.Tool.Name == old.Tool.Name }) {
+				stale = append(stale, old.Tool.Name)
 			}
 		}
+		if len(stale) > 0 {
+			mcpServer.DeleteTools(stale...)
+		}
+	}
+	mcpServer.AddTools(tools...)
+	s.tools.set(proxyName, tools, time.Now())
+}
+
+// resourceProxy is a proxy exposing the resources of its upstream server.
+type resourceProxy interface {
+	GetName() string
+	GetResourceTemplates() ([]mcp.ResourceTemplate, error)
+	ReadResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error)
+}
+
+// syncProxyResourceTemplates registers the resource templates of a proxy and returns them, nil if they could not
+// be listed.
+func (s *Server) syncProxyResourceTemplates(mcpServer *server.MCPServer, p resourceProxy) []mcp.ResourceTemplate {
+	templates, err := p.GetResourceTemplates()
+	if err != nil {
+		s.Logger.Warn("Failed to get MCP proxy resource templates", zap.String("proxy", p.GetName()), zap.Error(err))
+		return nil
+	}
+	s.registerProxyResourceTemplates(mcpServer, p, templates)
+	return templates
+}
+
+// registerProxyResourceTemplates registers resource templates of a proxy.
+// The MCP server can not remove a template, so the ones no longer exposed are filtered out when listed and read.
+func (s *Server) registerProxyResourceTemplates(mcpServer *server.MCPServer, p resourceProxy, templates []mcp.ResourceTemplate) {
+	serverTemplates := make([]server.ServerResourceTemplate, 0, len(templates))
+	for _, template := range templates {
+		s.Logger.Debug("Adding resource template", zap.String("uri_template", template.URITemplate.Raw()))
+		serverTemplates = append(serverTemplates, server.ServerResourceTemplate{
+			Template: template,
+			Handler:  s.readProxyResource(p, template.URITemplate.Raw()),
+		})
+	}
+	s.tools.setResourceTemplates(p.GetName(), templates)
+	if len(serverTemplates) > 0 {
+		mcpServer.AddResourceTemplates(serverTemplates...)
+	}
+}
+
+// toolHandler chains the stages of the calls of a proxied tool, from the maintenance mode, the outermost, to the
+// approval and the budgets, the closest to the upstream.
+func (s *Server) toolHandler(proxyName, toolName string, call server.ToolHandlerFunc) server.ToolHandlerFunc {
+	handler := s.approvalHandler(proxyName, toolName, s.budgetHandler(proxyName, toolName, upstreamTimingHandler(call)))
+	handler = s.guardrailHandler(proxyName, toolName, handler)
+	handler = s.screeningHandler(proxyName, toolName, handler)
+	handler = s.maskingHandler(proxyName, toolName, handler)
+	return s.maintenanceHandler(handler)
+}
+
+// readProxyResource reads a templated resource from its proxy, if the proxy still exposes the template.
+func (s *Server) readProxyResource(p resourceProxy, uriTemplate string) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		if !s.tools.hasResourceTemplate(p.GetName(), uriTemplate) {
+			return nil, fmt.Errorf("resource %s not found", request.Params.URI)
+		}
+		return p.ReadResource(ctx, request)
 	}
 }
 
@@ -253,7 +629,7 @@ func (s *Server) mcpHooks() *server.Hooks {
 			"Tool call started",
 			zap.String("request_method", method),
 			zap.String("tool_name", params.Name),
-			zap.Any("request_arguments", args),
+			zap.Any("request_arguments", s.Redactor.Arguments(proxyName, args)),
 		)
 	})
 
@@ -263,29 +639,63 @@ func (s *Server) mcpHooks() *server.Hooks {
 			s.Logger.Error("Logger not found in context")
 			return
 		}
+		proxyName, toolName := s.parseToolName(message.Params.Name)
 		response := "N/A"
 		if len(result.Content) > 0 {
 			textContent, ok := result.Content[0].(mcp.TextContent)
 			if ok {
-				response = textContent.Text
+				response = s.Redactor.Text(proxyName, textContent.Text)
 			}
 		}
-		idFloat, ok := id.(float64)
-		if !ok {
-			ctxLogger.Error("Invalid request ID", zap.Any("request_id", id))
-		}
-		proxyName, toolName := s.parseToolName(message.Params.Name)
+		// The request ID is a number or a string
 		if result.IsError {
-			ctxLogger.Error(response, zap.String("toolName", message.Params.Name), zap.Float64("request_id", idFloat))
+			ctxLogger.Error(response, zap.String("toolName", message.Params.Name), zap.Any("request_id", id))
 			metrics.ToolsCallErrorsGauge.WithLabelValues(toolName, proxyName).Inc()
 		} else {
 			ctxLogger.Info(
 				"Tool call completed with success",
 				zap.String("toolName", message.Params.Name),
-				zap.Float64("request_id", idFloat),
+				zap.Any("request_id", id),
 			)
 			metrics.ToolsCallSuccessGauge.WithLabelValues(toolName, proxyName).Inc()
 		}
+		identity := identityFromContext(ctx)
+		subject := identity
+		if subject == anonymousIdentity {
+			subject = ""
+		}
+		s.identityLabels.ObserveToolCall(proxyName, toolName, toolRoleFromContext(ctx, message.Params.Name), subject, result.IsError)
+		s.slo.ObserveToolCall(proxyName, durationFromContext(ctx), result.IsError)
+		s.recordToolCall(ctx, storage.ToolCallRecord{
+			Proxy:    proxyName,
+			Tool:     toolName,
+			Identity: identity,
+			IsError:  result.IsError,
+			CalledAt: time.Now(),
+			Duration: durationFromContext(ctx),
+		})
+		s.recordTrace(ctx, message, result, proxyName, toolName)
+		s.publishAudited(events.TypeToolCall, events.ToolCall{
+			Proxy:    proxyName,
+			Tool:     toolName,
+			Identity: identity,
+			IsError:  result.IsError,
+		})
+	})
+
+	hooks.AddAfterSetLevel(func(ctx context.Context, _ any, message *mcp.SetLevelRequest, _ *mcp.EmptyResult) {
+		s.logLevels.set(identityFromContext(ctx), message.Params.Level)
+	})
+
+	hooks.AddAfterListResourceTemplates(func(_ context.Context, _ any, _ *mcp.ListResourceTemplatesRequest, result *mcp.ListResourceTemplatesResult) {
+		templates := make([]mcp.ResourceTemplate, 0, len(result.ResourceTemplates))
+		for _, template := range result.ResourceTemplates {
+			proxyName, _, _ := strings.Cut(template.URITemplate.Raw(), ":")
+			if s.tools.hasResourceTemplate(proxyName, template.URITemplate.Raw()) {
+				templates = append(templates, template)
+			}
+		}
+		result.ResourceTemplates = templates
 	})
 
 	hooks.AddBeforeListTools(func(ctx context.Context, id any, _ *mcp.ListToolsRequest) {
@@ -301,12 +711,76 @@ func (s *Server) mcpHooks() *server.Hooks {
 	return hooks
 }
 
+// recordToolCall stores a tool call for the usage statistics without delaying the response.
+func (s *Server) recordToolCall(ctx context.Context, record storage.ToolCallRecord) {
+	if s.Storage == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordToolCallTimeout)
+		defer cancel()
+		if err := s.Storage.RecordToolCall(ctx, record); err != nil {
+			s.Logger.Warn("Failed to record the tool call", zap.Error(err))
+		}
+	}()
+}
+
+// identityFromContext returns the subject of the caller, or "anonymous" when the call is not authenticated.
+func identityFromContext(ctx context.Context) string {
+	claims, _ := ctx.Value("claims").(map[string]interface{})
+	return identityFromClaims(claims)
+}
+
+// mcpCaller returns the caller of a request: its MCP session if the sessions are stateful, its identity otherwise.
+func (s *Server) mcpCaller(r *http.Request) string {
+	if s.sessions != nil {
+		return "session:" + r.Header.Get(server.HeaderKeySessionID)
+	}
+	return "identity:" + identityFromContext(r.Context())
+}
+
+// identityFromClaims returns the subject of the claims, or "anonymous" if they have none.
+func identityFromClaims(claims map[string]interface{}) string {
+	if sub, ok := claims["sub"].(string); ok && sub != "" {
+		return sub
+	}
+	return anonymousIdentity
+}
+
+// durationFromContext returns the time elapsed since the start of the request, 0 if unknown.
+func durationFromContext(ctx context.Context) time.Duration {
+	startedAt, ok := ctx.Value("startedAt").(time.Time)
+	if !ok {
+		return 0
+	}
+	return time.Since(startedAt)
+}
+
+// toolRoleFromContext returns the role which allowed a tool call, or an empty string if the call was not authorized.
+func toolRoleFromContext(ctx context.Context, toolName string) string {
+	toolRoles, _ := ctx.Value("toolRoles").(map[string]string)
+	return toolRoles[toolName]
+}
+
+// contextWithToolRole returns a context with the role which allowed a tool call, keeping the roles of the others.
+func contextWithToolRole(ctx context.Context, toolName, role string) context.Context {
+	toolRoles, _ := ctx.Value("toolRoles").(map[string]string)
+	roles := make(map[string]string, len(toolRoles)+1)
+	for name, toolRole := range toolRoles {
+		roles[name] = toolRole
+	}
+	roles[toolName] = role
+	//nolint:staticcheck,revive // We need to use the key as a string
+	return context.WithValue(ctx, "toolRoles", roles)
+}
+
 func (s *Server) parseToolName(toolName string) (proxyName, toolNameParsed string) {
-	parts := strings.Split(toolName, ":")
-	if len(parts) != 2 { //nolint:mnd // always return 2 parts
+	// The name of the upstream tool may contain colons, not the name of the proxy
+	proxyName, toolNameParsed, ok := strings.Cut(toolName, ":")
+	if !ok {
 		return "", ""
 	}
-	return parts[0], parts[1]
+	return proxyName, toolNameParsed
 }
 
 // addGlobalMCPContext adds the global MCP context to the context
@@ -319,8 +793,20 @@ func (s *Server) addGlobalMCPContext(ctx context.Context, r *http.Request) conte
 	}
 	correlationID := uuid.New().String()
 	ctxLogger := s.Logger.With(zap.String("correlation_id", correlationID))
+	if s.Router.IPExtractor != nil {
+		ctxLogger = ctxLogger.With(zap.String("client_ip", s.Router.IPExtractor(r)))
+	}
 	//nolint:staticcheck,revive // We need to use the key as a string
 	ctx = context.WithValue(ctx, "logger", ctxLogger)
+	// Each tool call has its own request, the entries of a JSON-RPC batch included: its start times the call.
+	//nolint:staticcheck,revive // We need to use the key as a string
+	ctx = context.WithValue(ctx, "startedAt", time.Now())
+	if s.traces {
+		//nolint:staticcheck,revive // We need to use the key as a string
+		ctx = context.WithValue(ctx, "trace", &callTrace{id: correlationID})
+	}
+	ctx = proxy.WithCaller(ctx, s.mcpCaller(r))
+	ctx = proxy.WithProgress(ctx, s.forwardUpstreamProgress)
 
 	return ctx
 }
@@ -343,28 +829,23 @@ func (s *Server) configureAuthMiddleware() {
 		s.Logger.Error("Failed to initialize provider", zap.Error(err))
 		panic(err)
 	}
+	if checker, ok := provider.(auth.HealthChecker); ok {
+		s.configureAuthHealthCheck(checker)
+	}
+	if verifier, ok := provider.(auth.AppCheckVerifier); ok && verifier.AppCheckRequired() {
+		s.AppCheck = verifier
+	}
 
 	s.Provider = provider
+	if s.authCache != nil {
+		s.Provider = authcache.NewProvider(provider, s.authCache)
+	}
+	// The claims of the cached tokens are checked on each request too, for their age
+	s.Provider = auth.NewClaimsProvider(s.Provider, s.Config.AuthProvider)
 
 	s.Router.Use(s.authMiddleware)
 }
 
-func (s *Server) unauth(c echo.Context, code, msg string) error {
-	if s.Config.OAuth.Enabled {
-		if len(s.Config.OAuth.AuthorizationServers) == 0 {
-			s.Logger.Error("OAuth is enabled but no authorization servers are configured")
-			return echo.NewHTTPError(http.StatusInternalServerError, "OAuth configuration error")
-		}
-		// Set the WWW-Authenticate header for OAuth protected resources
-		// This is used by the client to redirect to the authorization server
-		// to obtain a token
-		rsMetaURL := s.Config.OAuth.AuthorizationServers[0] + "/.well-known/oauth-protected-resource"
-		c.Response().Header().Set("WWW-Authenticate",
-			fmt.Sprintf(`Bearer resource_metadata=%q, error=%q`, rsMetaURL, code))
-	}
-	return echo.NewHTTPError(http.StatusUnauthorized, msg)
-}
-
 func (s *Server) configureStorage() {
 	if s.Config.BackendConfig.Engine == "memory" {
 		s.Logger.Warn("Using memory storage. This is not recommended for production.")
@@ -375,11 +856,114 @@ func (s *Server) configureStorage() {
 		panic(err)
 	}
 	s.Storage = storageClient
+	if pinger, ok := storageClient.(backendPinger); ok {
+		s.connectBackend(pinger)
+	}
+}
+
+// configureAuthCache caches the authorization data of the storage in Redis, shared by the replicas.
+func (s *Server) configureAuthCache() {
+	config := s.Config.AuthCache
+	if config == nil || config.URL == "" {
+		return
+	}
+	cache, err := authcache.New(config, s.Logger)
+	if err != nil {
+		s.Logger.Error("Failed to create the auth cache", zap.Error(err))
+		panic(err)
+	}
+	s.authCache = cache
+	s.Storage = authcache.NewStorage(s.Storage, cache)
+	s.Logger.Info("Caching the authorization data in Redis",
+		zap.String("prefix", config.Prefix),
+		zap.Duration("ttl", config.TTL),
+		zap.Duration("token_ttl", config.TokenTTL))
+}
+
+// configureEventExport publishes the gateway events to the configured Kafka or NATS sink.
+func (s *Server) configureEventExport() {
+	config := s.Config.EventExport
+	if config == nil || config.Sink == "" {
+		return
+	}
+	publisher, err := eventexport.NewPublisher(config)
+	if err != nil {
+		s.Logger.Error("Failed to create the event export sink", zap.Error(err))
+		panic(err)
+	}
+	s.eventExporter = eventexport.NewExporter(config, publisher, s.Logger)
+	s.eventExporter.Start(s.eventBroker)
+	s.Logger.Info("Exporting the gateway events",
+		zap.String("sink", config.Sink),
+		zap.String("topic", config.Topic),
+		zap.String("format", config.Format),
+		zap.Strings("types", config.Types))
 }
 
 func (s *Server) configureSwaggerRoutes() {
 	s.Logger.Info(fmt.Sprintf("Configuring Swagger routes. Swagger UI is available at http://%s/swagger/index.html", s.Config.HTTP.Addr))
-	s.Router.GET("/swagger/*", echoSwagger.WrapHandler)
+	s.Router.GET("/swagger/*", echoSwagger.WrapHandler, s.adminIPAccessMiddlewares(s.Config.HTTP.AdminIPAccess.ProtectSwagger)...)
+}
+
+// configureAdminUI serves the web admin UI. The UI calls the /v1 API with the admin API key entered by the operator.
+func (s *Server) configureAdminUI() {
+	if !s.Config.HTTP.AdminUI {
+		return
+	}
+	s.Logger.Info(fmt.Sprintf("Configuring admin UI. It is available at http://%s/ui/", s.Config.HTTP.Addr))
+	adminIPAccess := s.adminIPAccessMiddlewares(true)
+	s.Router.GET("/ui", func(c echo.Context) error {
+		return c.Redirect(http.StatusMovedPermanently, "/ui/")
+	}, adminIPAccess...)
+	s.Router.Group("/ui", adminIPAccess...).StaticFS("/", ui.FS())
+}
+
+// configureRedaction configures the redaction of tool call arguments and results in the logs
+func (s *Server) configureRedaction() {
+	if !s.Config.Log.Redaction.Enabled {
+		s.Logger.Warn("Log redaction is disabled. Tool call arguments will be logged as is.")
+	}
+	redactor, err := redact.New(s.Config.Log.Redaction)
+	if err != nil {
+		s.Logger.Error("Failed to create log redactor", zap.Error(err))
+		panic(err)
+	}
+	s.Redactor = redactor
+}
+
+// configureMasking configures the masking of the secrets in the tool results
+func (s *Server) configureMasking() {
+	if !s.Config.Masking.Enabled {
+		return
+	}
+	masker, err := redact.NewMasker(s.Config.Masking)
+	if err != nil {
+		s.Logger.Error("Failed to create the result masker", zap.Error(err))
+		panic(err)
+	}
+	s.Masker = masker
+}
+
+// maskingHandler masks the secrets in the results of the tool calls before they are returned.
+func (s *Server) maskingHandler(proxyName, toolName string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if masked := s.Masker.Result(proxyName, result); masked > 0 {
+			metrics.SecretsMaskedCounter.WithLabelValues(toolName, proxyName).Add(float64(masked))
+			s.Logger.Debug("Masked secrets in the tool result",
+				zap.String("proxy", proxyName), zap.String("tool", toolName), zap.Int("masked", masked))
+		}
+		return result, err
+	}
+}
+
+// configureCrypto restricts the TLS connections of the gateway to the approved algorithms in FIPS mode.
+func (s *Server) configureCrypto() {
+	if !s.Config.Crypto.FIPSMode() {
+		return
+	}
+	fips.Enable()
+	s.Logger.Info("FIPS mode enabled", zap.String("crypto_backend", s.Config.Crypto.Backend))
 }
 
 func (s *Server) configureEncryption() {
@@ -387,7 +971,7 @@ func (s *Server) configureEncryption() {
 		s.Logger.Warn("Using memory storage. Skipping encryption.")
 		return
 	}
-	encryptor, err := aescipher.New(s.Config.BackendConfig.EncryptionKey)
+	encryptor, err := s.Config.NewCryptor()
 	if err != nil {
 		s.Logger.Error("Failed to create encryptor mandatory for backend data encryption", zap.Error(err))
 		panic(err)
@@ -396,15 +980,38 @@ func (s *Server) configureEncryption() {
 }
 
 func (s *Server) configureV1Routes() {
-	v1 := s.Router.Group("/v1")
+	v1 := s.Router.Group("/v1", s.adminIPAccessMiddlewares(true)...)
 	v1.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			apiKey := c.Request().Header.Get("X-API-Key")
-			if apiKey != s.Config.HTTP.AdminAPIKey {
+			if !s.isAdminAPIKey(c.Request().Header.Get("X-API-Key")) {
 				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid API key")
 			}
 			return next(c)
 		}
 	})
+	v1.Use(requestTimeoutMiddleware(s.Config.HTTP.Timeouts.Admin, func(c echo.Context) bool {
+		// The event stream and the profiles live as long as the MCP requests.
+		return c.Path() == "/v1/admin/events" || isLongDebugRequest(c.Request().URL.Path)
+	}))
+	v1.Use(s.adminMutationMiddleware)
 	s.ConfigureRoutes(v1)
+	s.configureDebugRoutes(v1)
+}
+
+// isAdminAPIKey verifies an admin API key in constant time, against the admin API key hash if configured,
+// or against the admin API key.
+func (s *Server) isAdminAPIKey(key string) bool {
+	s.adminKeyOnce.Do(func() {
+		adminAPIKey := s.Config.HTTP.AdminAPIKey
+		if s.Config.HTTP.AdminAPIKeyHash != "" && adminAPIKey != "" && adminAPIKey != cfg.DefaultConfig().HTTP.AdminAPIKey {
+			s.Logger.Warn("Both the admin API key and its hash are configured. The admin API key is ignored.")
+		}
+		verifier, err := apikey.NewVerifier(adminAPIKey, s.Config.HTTP.AdminAPIKeyHash)
+		if err != nil {
+			s.Logger.Error("Invalid admin API key configuration. The admin API is disabled.", zap.Error(err))
+			return
+		}
+		s.adminKey = verifier
+	})
+	return s.adminKey != nil && s.adminKey.Verify(key)
 }
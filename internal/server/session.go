@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/matthisholleville/mcp-gateway/pkg/redis"
+	"go.uber.org/zap"
+)
+
+const (
+	// sessionIDPrefix prefixes the session IDs, followed by a random UUID.
+	sessionIDPrefix = "mcp-session-"
+	// sessionKeyPrefix prefixes the keys of the sessions in Redis.
+	sessionKeyPrefix = "mcp-gateway:session:"
+	// sessionStoreTimeout bounds each operation of the session store.
+	sessionStoreTimeout = time.Second
+)
+
+// sessionStore keeps the MCP sessions of the stateful mode until they are idle for their TTL.
+type sessionStore interface {
+	// create starts a session.
+	create(ctx context.Context, id string, ttl time.Duration) error
+	// touch extends a session by ttl, returning false if it does not exist or expired.
+	touch(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	// delete terminates a session.
+	delete(ctx context.Context, id string) error
+	close() error
+}
+
+// memorySessionStore keeps the sessions in the memory of the replica.
+type memorySessionStore struct {
+	mu        sync.Mutex
+	expiresAt map[string]time.Time
+	now       func() time.Time
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{expiresAt: make(map[string]time.Time), now: time.Now}
+}
+
+func (m *memorySessionStore) create(_ context.Context, id string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	// The expired sessions are dropped when a session is created, as most clients never terminate theirs.
+	for other, expiresAt := range m.expiresAt {
+		if !now.Before(expiresAt) {
+			delete(m.expiresAt, other)
+		}
+	}
+	m.expiresAt[id] = now.Add(ttl)
+	return nil
+}
+
+func (m *memorySessionStore) touch(_ context.Context, id string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	expiresAt, ok := m.expiresAt[id]
+	if !ok || !now.Before(expiresAt) {
+		delete(m.expiresAt, id)
+		return false, nil
+	}
+	m.expiresAt[id] = now.Add(ttl)
+	return true, nil
+}
+
+func (m *memorySessionStore) delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.expiresAt, id)
+	return nil
+}
+
+func (m *memorySessionStore) close() error {
+	return nil
+}
+
+// redisSessionStore keeps the sessions in Redis, so any replica can resume them. Redis expires them.
+type redisSessionStore struct {
+	client *redis.Client
+}
+
+func (r *redisSessionStore) create(ctx context.Context, id string, ttl time.Duration) error {
+	_, err := r.client.Do(ctx, "SET", sessionKeyPrefix+id, "1", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+func (r *redisSessionStore) touch(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	reply, err := r.client.Do(ctx, "PEXPIRE", sessionKeyPrefix+id, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	return reply == int64(1), nil
+}
+
+func (r *redisSessionStore) delete(ctx context.Context, id string) error {
+	_, err := r.client.Do(ctx, "DEL", sessionKeyPrefix+id)
+	return err
+}
+
+func (r *redisSessionStore) close() error {
+	return r.client.Close()
+}
+
+// sessionManager issues and checks the session IDs of the stateful mode. A request whose session expired or was
+// terminated gets a 404, after which the client initializes a new session.
+type sessionManager struct {
+	store       sessionStore
+	idleTimeout time.Duration
+	logger      logger.Logger
+}
+
+var _ server.SessionIdManager = (*sessionManager)(nil)
+
+//nolint:gocritic // we need to keep logger as a parameter for the function
+func newSessionManager(config *cfg.SessionConfig, logger logger.Logger) (*sessionManager, error) {
+	manager := &sessionManager{idleTimeout: config.IdleTimeout, logger: logger}
+	switch config.Store {
+	case cfg.SessionStoreRedis:
+		client, err := redis.NewClient(config.RedisURL, config.RedisPassword, sessionStoreTimeout)
+		if err != nil {
+			return nil, err
+		}
+		manager.store = &redisSessionStore{client: client}
+	default:
+		manager.store = newMemorySessionStore()
+	}
+	return manager, nil
+}
+
+// Generate issues the ID of a new session.
+func (m *sessionManager) Generate() string {
+	id := sessionIDPrefix + uuid.NewString()
+	ctx, cancel := context.WithTimeout(context.Background(), sessionStoreTimeout)
+	defer cancel()
+	if err := m.store.create(ctx, id, m.idleTimeout); err != nil {
+		m.logger.Error("Failed to store the MCP session", zap.Error(err))
+	}
+	return id
+}
+
+// Validate extends the session of a request, reporting it terminated if it is unknown or expired.
+func (m *sessionManager) Validate(sessionID string) (isTerminated bool, err error) {
+	if !validSessionID(sessionID) {
+		return false, fmt.Errorf("invalid session ID %q", sessionID)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sessionStoreTimeout)
+	defer cancel()
+	ok, err := m.store.touch(ctx, sessionID, m.idleTimeout)
+	if err != nil {
+		m.logger.Error("Failed to read the MCP session", zap.Error(err))
+		return false, err
+	}
+	return !ok, nil
+}
+
+// Terminate terminates the session of a DELETE request.
+func (m *sessionManager) Terminate(sessionID string) (isNotAllowed bool, err error) {
+	if !validSessionID(sessionID) {
+		return false, errors.New("invalid session ID")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sessionStoreTimeout)
+	defer cancel()
+	return false, m.store.delete(ctx, sessionID)
+}
+
+func (m *sessionManager) close() error {
+	return m.store.close()
+}
+
+func validSessionID(sessionID string) bool {
+	id, ok := strings.CutPrefix(sessionID, sessionIDPrefix)
+	if !ok {
+		return false
+	}
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// echoSessionID echoes the session ID of the MCP requests on their responses, not only on the response of
+// initialize, for the load balancers pinning the sessions from the response headers.
+func echoSessionID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sessionID := r.Header.Get(server.HeaderKeySessionID); sessionID != "" {
+			w.Header().Set(server.HeaderKeySessionID, sessionID)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// configureSessions creates the session manager of the stateful mode.
+func (s *Server) configureSessions() {
+	config := s.Config.Session
+	if config == nil || !config.Stateful {
+		return
+	}
+	manager, err := newSessionManager(config, s.Logger)
+	if err != nil {
+		s.Logger.Error("Failed to create the MCP session store", zap.Error(err))
+		panic(err)
+	}
+	s.sessions = manager
+	s.Logger.Info("MCP sessions are stateful",
+		zap.String("store", config.Store),
+		zap.Duration("idle_timeout", config.IdleTimeout))
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/pkg/logger"
+	"github.com/matthisholleville/mcp-gateway/pkg/redis/redistest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySessionStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := newMemorySessionStore()
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.create(ctx, "a", time.Minute))
+	now = now.Add(50 * time.Second)
+	ok, err := store.touch(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok, "a request extends the session")
+
+	now = now.Add(50 * time.Second)
+	ok, _ = store.touch(ctx, "a", time.Minute)
+	assert.True(t, ok)
+	now = now.Add(time.Minute)
+	ok, _ = store.touch(ctx, "a", time.Minute)
+	assert.False(t, ok, "the session expired")
+
+	require.NoError(t, store.create(ctx, "b", time.Minute))
+	require.NoError(t, store.delete(ctx, "b"))
+	ok, _ = store.touch(ctx, "b", time.Minute)
+	assert.False(t, ok, "the session was terminated")
+}
+
+func TestStatefulSessions(t *testing.T) {
+	redisServer := redistest.NewServer(t, "")
+	config := &cfg.SessionConfig{
+		Stateful:    true,
+		Store:       cfg.SessionStoreRedis,
+		RedisURL:    redisServer.URL,
+		IdleTimeout: time.Minute,
+	}
+	newReplica := func() http.Handler {
+		manager, err := newSessionManager(config, logger.MustNewLogger("json", "debug", "test"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = manager.close() })
+		mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+		return echoSessionID(server.NewStreamableHTTPServer(mcpServer, server.WithSessionIdManager(manager)))
+	}
+	replica1, replica2 := newReplica(), newReplica()
+	send := func(replica http.Handler, method, sessionID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(server.HeaderKeySessionID, sessionID)
+		}
+		rec := httptest.NewRecorder()
+		replica.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(replica1, http.MethodPost, "",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0.0"}}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	sessionID := rec.Header().Get(server.HeaderKeySessionID)
+	require.True(t, strings.HasPrefix(sessionID, sessionIDPrefix), sessionID)
+	_, ok := redisServer.Get(sessionKeyPrefix + sessionID)
+	assert.True(t, ok, "the session is stored in Redis")
+
+	t.Run("another replica resumes the session and echoes its ID", func(t *testing.T) {
+		rec := send(replica2, http.MethodPost, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, sessionID, rec.Header().Get(server.HeaderKeySessionID))
+	})
+
+	t.Run("a request without a valid session ID is rejected", func(t *testing.T) {
+		rec := send(replica2, http.MethodPost, "", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = send(replica2, http.MethodPost, "mcp-session-forged", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("a session terminated on a replica is terminated on the others", func(t *testing.T) {
+		rec := send(replica2, http.MethodDelete, sessionID, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = send(replica1, http.MethodPost, sessionID, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// recordTraceTimeout bounds the storage of the trace of a tool call.
+	recordTraceTimeout = 5 * time.Second
+	// tracePurgeInterval is how often the traces older than the retention are purged.
+	tracePurgeInterval = time.Hour
+	defaultTraceLimit  = 100
+	maxTraceLimit      = 1000
+)
+
+// authTimings are the durations of the authorization of an MCP request, shared by the tool calls of a batch.
+type authTimings struct {
+	auth    time.Duration
+	storage time.Duration
+}
+
+// callTrace collects the trace of a tool call while it is handled.
+type callTrace struct {
+	// id is the correlation ID of the call.
+	id       string
+	upstream time.Duration
+}
+
+// configureTraces enables the traces of the tool calls, and the purge of the traces older than the retention.
+func (s *Server) configureTraces() {
+	if !s.Config.Trace.Enabled {
+		return
+	}
+	if s.Config.BackendConfig.Engine == "memory" {
+		s.Logger.Warn("The tool call traces are kept in memory and lost on restart, use a persistent backend.")
+	}
+	s.traces = true
+	go func() {
+		for {
+			s.purgeTraces(context.Background(), time.Now())
+			time.Sleep(tracePurgeInterval)
+		}
+	}()
+}
+
+// purgeTraces deletes the traces of the tool calls made before the retention.
+func (s *Server) purgeTraces(ctx context.Context, now time.Time) {
+	purged, err := s.Storage.PurgeTraces(ctx, now.Add(-s.Config.Trace.Retention))
+	if err != nil {
+		s.Logger.Warn("Failed to purge the tool call traces", zap.Error(err))
+		return
+	}
+	if purged > 0 {
+		s.Logger.Info("Purged the expired tool call traces", zap.Int64("purged", purged))
+	}
+}
+
+// upstreamTimingHandler records in the trace of the call the time spent waiting for the upstream server.
+func upstreamTimingHandler(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		trace, ok := ctx.Value("trace").(*callTrace)
+		if !ok {
+			return next(ctx, request)
+		}
+		startedAt := time.Now()
+		result, err := next(ctx, request)
+		trace.upstream = time.Since(startedAt)
+		return result, err
+	}
+}
+
+// recordTrace stores the trace of a tool call without delaying the response.
+func (s *Server) recordTrace(ctx context.Context, request *mcp.CallToolRequest, result *mcp.CallToolResult, proxyName, toolName string) {
+	trace, ok := ctx.Value("trace").(*callTrace)
+	if !s.traces || s.Storage == nil || !ok {
+		return
+	}
+	record := storage.ToolCallTrace{
+		ID:               trace.id,
+		Proxy:            proxyName,
+		Tool:             toolName,
+		Identity:         identityFromContext(ctx),
+		IsError:          result.IsError,
+		CalledAt:         time.Now(),
+		Duration:         durationFromContext(ctx),
+		UpstreamDuration: trace.upstream,
+	}
+	if startedAt, ok := ctx.Value("startedAt").(time.Time); ok {
+		record.CalledAt = startedAt
+	}
+	if timings, ok := ctx.Value("authTimings").(authTimings); ok {
+		record.AuthDuration = timings.auth
+		record.StorageDuration = timings.storage
+	}
+	if args := request.GetArguments(); len(args) > 0 {
+		arguments, err := json.Marshal(s.Redactor.Arguments(proxyName, args))
+		if err != nil {
+			s.Logger.Warn("Failed to encode the tool call arguments of the trace", zap.Error(err))
+		}
+		record.Arguments = arguments
+	}
+	if body, err := json.Marshal(result); err == nil {
+		record.ResultSize = len(body)
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTraceTimeout)
+		defer cancel()
+		if err := s.Storage.RecordTrace(ctx, record); err != nil {
+			s.Logger.Warn("Failed to record the tool call trace", zap.Error(err))
+		}
+	}()
+}
+
+// @Summary		Get the tool call traces
+// @Description	Get the detailed traces of the tool calls kept for the retention, the latest first, with the timings of their authorization, storage and upstream stages, their redacted arguments and their result size. The durations are in nanoseconds.
+// @Tags			traces
+// @Accept			json
+// @Produce		json
+// @Param			proxy		query		string	false	"Proxy of the calls"
+// @Param			tool		query		string	false	"Tool of the calls"
+// @Param			identity	query		string	false	"Identity of the callers"
+// @Param			errors		query		bool	false	"Only the failed calls"
+// @Param			since		query		string	false	"RFC 3339 time of the first calls"
+// @Param			until		query		string	false	"RFC 3339 time of the end of the calls, excluded"
+// @Param			limit		query		int		false	"Maximum number of traces"	default(100)
+// @Success		200			{array}		storage.ToolCallTrace
+// @Failure		400			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/traces [get]
+func (s *Server) getTraces(c echo.Context) error {
+	filter := storage.TraceFilter{
+		Proxy:    c.QueryParam("proxy"),
+		Tool:     c.QueryParam("tool"),
+		Identity: c.QueryParam("identity"),
+		Limit:    defaultTraceLimit,
+	}
+	if raw := c.QueryParam("errors"); raw != "" {
+		var err error
+		if filter.ErrorsOnly, err = strconv.ParseBool(raw); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "errors must be true or false"})
+		}
+	}
+	for _, bound := range []struct {
+		name string
+		time *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		if raw := c.QueryParam(bound.name); raw != "" {
+			var err error
+			if *bound.time, err = time.Parse(time.RFC3339, raw); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": bound.name + " must be an RFC 3339 time"})
+			}
+		}
+	}
+	if raw := c.QueryParam("limit"); raw != "" {
+		var err error
+		filter.Limit, err = strconv.Atoi(raw)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxTraceLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("limit must be between 1 and %d", maxTraceLimit)})
+		}
+	}
+
+	traces, err := s.Storage.ListTraces(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, traces)
+}
+
+// @Summary		Get a tool call trace
+// @Description	Get the detailed trace of a tool call given its ID, the correlation_id of its logs
+// @Tags			traces
+// @Accept			json
+// @Produce		json
+// @Param			id	path		string	true	"Trace ID"
+// @Success		200	{object}	storage.ToolCallTrace
+// @Failure		404	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/traces/{id} [get]
+func (s *Server) getTrace(c echo.Context) error {
+	trace, err := s.Storage.GetTrace(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, trace)
+}
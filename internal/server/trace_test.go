@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/redact"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordTrace(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	store := storage.NewMemoryStorage("")
+	srv.Storage = store
+	redactor, err := redact.New(&cfg.RedactionConfig{Enabled: true, KeyPatterns: []string{"token"}})
+	require.NoError(t, err)
+	srv.Redactor = redactor
+
+	call := func() {
+		//nolint:staticcheck,revive // We need to use the key as a string
+		ctx := context.WithValue(t.Context(), "claims", map[string]interface{}{"sub": "alice"})
+		//nolint:staticcheck,revive // We need to use the key as a string
+		ctx = context.WithValue(ctx, "authTimings", authTimings{auth: time.Millisecond, storage: 2 * time.Millisecond})
+		ctx = srv.addGlobalMCPContext(ctx, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{
+			Name: "github:search", Arguments: map[string]any{"query": "mcp", "token": "secret"},
+		}}
+		result, err := upstreamTimingHandler(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			time.Sleep(10 * time.Millisecond)
+			return mcp.NewToolResultText("found"), nil
+		})(ctx, request)
+		require.NoError(t, err)
+		srv.recordTrace(ctx, &request, result, "github", "search")
+	}
+
+	// The traces are only recorded when they are enabled
+	call()
+	srv.traces = true
+	call()
+	require.Eventually(t, func() bool {
+		traces, err := store.ListTraces(context.Background(), storage.TraceFilter{})
+		return err == nil && len(traces) == 1
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	traces, err := store.ListTraces(context.Background(), storage.TraceFilter{})
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	trace := traces[0]
+	assert.NotEmpty(t, trace.ID)
+	assert.Equal(t, "github", trace.Proxy)
+	assert.Equal(t, "search", trace.Tool)
+	assert.Equal(t, "alice", trace.Identity)
+	assert.Equal(t, time.Millisecond, trace.AuthDuration)
+	assert.Equal(t, 2*time.Millisecond, trace.StorageDuration)
+	assert.GreaterOrEqual(t, trace.UpstreamDuration, 10*time.Millisecond)
+	assert.GreaterOrEqual(t, trace.Duration, trace.UpstreamDuration)
+	assert.JSONEq(t, `{"query":"mcp","token":"[REDACTED]"}`, string(trace.Arguments))
+	result, err := json.Marshal(mcp.NewToolResultText("found"))
+	require.NoError(t, err)
+	assert.Equal(t, len(result), trace.ResultSize)
+}
+
+func TestPurgeTraces(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config.Trace = &cfg.TraceConfig{Enabled: true, Retention: 24 * time.Hour}
+	store := storage.NewMemoryStorage("")
+	srv.Storage = store
+	now := time.Now()
+	require.NoError(t, store.RecordTrace(t.Context(), storage.ToolCallTrace{ID: "expired", CalledAt: now.Add(-25 * time.Hour)}))
+	require.NoError(t, store.RecordTrace(t.Context(), storage.ToolCallTrace{ID: "kept", CalledAt: now.Add(-23 * time.Hour)}))
+
+	srv.purgeTraces(t.Context(), now)
+	traces, err := store.ListTraces(t.Context(), storage.TraceFilter{})
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Equal(t, "kept", traces[0].ID)
+}
+
+func TestGetTraces(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Storage = storage.NewMemoryStorage("")
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	for _, trace := range []storage.ToolCallTrace{
+		{ID: "first", Proxy: "github", Tool: "search", Identity: "alice", CalledAt: now},
+		{ID: "second", Proxy: "github", Tool: "search", Identity: "bob", CalledAt: now.Add(time.Hour), IsError: true},
+		{ID: "third", Proxy: "jira", Tool: "get_issue", Identity: "alice", CalledAt: now.Add(2 * time.Hour)},
+	} {
+		require.NoError(t, srv.Storage.RecordTrace(t.Context(), trace))
+	}
+
+	for _, test := range []struct {
+		name         string
+		query        string
+		expectedCode int
+		expectedIDs  []string
+	}{
+		{name: "all", expectedCode: http.StatusOK, expectedIDs: []string{"third", "second", "first"}},
+		{name: "proxy and identity", query: "?proxy=github&identity=alice", expectedCode: http.StatusOK, expectedIDs: []string{"first"}},
+		{name: "errors", query: "?errors=true", expectedCode: http.StatusOK, expectedIDs: []string{"second"}},
+		{name: "time range", query: "?since=2025-06-01T10:30:00Z&until=2025-06-01T12:00:00Z", expectedCode: http.StatusOK,
+			expectedIDs: []string{"second"}},
+		{name: "limit", query: "?limit=2", expectedCode: http.StatusOK, expectedIDs: []string{"third", "second"}},
+		{name: "invalid errors", query: "?errors=maybe", expectedCode: http.StatusBadRequest},
+		{name: "invalid since", query: "?since=yesterday", expectedCode: http.StatusBadRequest},
+		{name: "invalid limit", query: "?limit=0", expectedCode: http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			require.NoError(t, srv.getTraces(srv.Router.NewContext(httptest.NewRequest(http.MethodGet, "/v1/admin/traces"+test.query, nil), rec)))
+			require.Equal(t, test.expectedCode, rec.Code, rec.Body.String())
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+			var traces []storage.ToolCallTrace
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &traces))
+			ids := []string{}
+			for _, trace := range traces {
+				ids = append(ids, trace.ID)
+			}
+			assert.Equal(t, test.expectedIDs, ids)
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	c := srv.Router.NewContext(httptest.NewRequest(http.MethodGet, "/v1/admin/traces/second", nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues("second")
+	require.NoError(t, srv.getTrace(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var trace storage.ToolCallTrace
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &trace))
+	assert.True(t, trace.IsError)
+
+	rec = httptest.NewRecorder()
+	c = srv.Router.NewContext(httptest.NewRequest(http.MethodGet, "/v1/admin/traces/missing", nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues("missing")
+	require.NoError(t, srv.getTrace(c))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -1,47 +1,193 @@
 package server
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/matthisholleville/mcp-gateway/internal/manifest"
 	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"go.uber.org/zap"
 )
 
+// ProxyToolsResponse is the list of tools the gateway currently exposes for a proxy.
+type ProxyToolsResponse struct {
+	Proxy string `json:"proxy"`
+	// SyncedAt is the last time the tools were fetched from the upstream server, null if never.
+	SyncedAt *time.Time    `json:"syncedAt"`
+	Tools    []ToolSummary `json:"tools"`
+}
+
+// ToolSummary describes a tool exposed by the gateway.
+type ToolSummary struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	InputSchema InputSchemaSummary `json:"inputSchema"`
+}
+
+// CallToolRequest is the body of an admin tool call.
+type CallToolRequest struct {
+	Arguments map[string]any `json:"arguments"`
+}
+
+// AuthzCheckRequest is the body of a permission simulation. Either claims or a token must be set.
+type AuthzCheckRequest struct {
+	Claims     map[string]any `json:"claims,omitempty"`
+	Token      string         `json:"token,omitempty"`
+	ObjectType string         `json:"objectType"`
+	Proxy      string         `json:"proxy"`
+	ObjectName string         `json:"objectName"`
+}
+
+// statsWindows are the windows supported by the usage statistics.
+var statsWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": storage.UsageRetention,
+}
+
+// eventsKeepAlive is the interval of the comments keeping the event stream open through idle proxies.
+var eventsKeepAlive = 15 * time.Second
+
+const (
+	defaultStatsWindow = "24h"
+	defaultStatsLimit  = 10
+	maxStatsLimit      = 100
+	// defaultUsageDays and maxUsageDays are the default and maximum number of days of a usage report.
+	defaultUsageDays = 30
+	maxUsageDays     = 366
+)
+
+// InputSchemaSummary summarizes the input schema of a tool.
+type InputSchemaSummary struct {
+	Type       string   `json:"type"`
+	Properties []string `json:"properties"`
+	Required   []string `json:"required"`
+}
+
 func (s *Server) ConfigureRoutes(c *echo.Group) {
 	admin := c.Group("/admin")
 	admin.GET("/proxies", s.getProxies)
+	admin.POST(`/proxies\:discover`, s.discoverProxy)
 	admin.GET("/proxies/:name", s.getProxy)
 	admin.PUT("/proxies/:name", s.upsertProxy)
 	admin.DELETE("/proxies/:name", s.deleteProxy)
+	admin.GET("/proxies/:name/tools", s.getProxyTools)
+	admin.POST("/proxies/:name/tools/:tool/call", s.callProxyTool)
 
 	admin.GET("/roles", s.getRoles)
 	admin.PUT("/roles", s.upsertRole)
+	admin.GET("/roles/templates", s.getRoleTemplates)
+	admin.POST("/roles/from-template", s.createRoleFromTemplate)
 	admin.DELETE("/roles/:role", s.deleteRole)
 
 	admin.GET("/attribute-to-roles", s.getAttributeToRoles)
 	admin.PUT("/attribute-to-roles", s.upsertAttributeToRole)
 	admin.DELETE("/attribute-to-roles/:attributeKey/:attributeValue", s.deleteAttributeToRole)
+	admin.POST(`/attribute-to-roles\:plan`, s.planAttributeToRoles)
+
+	admin.GET("/quotas", s.getQuotas)
+	admin.PUT("/quotas", s.upsertQuota)
+	admin.DELETE("/quotas/:name", s.deleteQuota)
+	admin.GET("/quotas/:name/usage", s.getQuotaUsage)
+	admin.DELETE("/quotas/:name/usage", s.resetQuotaUsage)
+
+	admin.GET("/tool-costs", s.getToolCosts)
+	admin.PUT("/tool-costs", s.upsertToolCost)
+	admin.DELETE("/tool-costs/:proxy/:tool", s.deleteToolCost)
+
+	admin.GET("/budgets", s.getBudgets)
+	admin.PUT("/budgets", s.upsertBudget)
+	admin.DELETE("/budgets/:name", s.deleteBudget)
+	admin.GET("/budgets/:name/usage", s.getBudgetUsage)
+	admin.DELETE("/budgets/:name/usage", s.resetBudgetUsage)
+
+	admin.GET("/tool-policies", s.getToolPolicies)
+	admin.PUT("/tool-policies", s.upsertToolPolicy)
+	admin.DELETE("/tool-policies/:proxy/:tool", s.deleteToolPolicy)
+
+	admin.GET("/classifications", s.getClassifications)
+	admin.PUT("/classifications", s.upsertClassification)
+	admin.DELETE("/classifications/:proxy/:tool", s.deleteClassification)
+
+	admin.GET("/composite-tools", s.getCompositeTools)
+	admin.PUT("/composite-tools", s.upsertCompositeTool)
+	admin.DELETE("/composite-tools/:name", s.deleteCompositeTool)
+
+	admin.GET("/approvals", s.getApprovals)
+	admin.GET("/approvals/:id", s.getApproval)
+	admin.POST("/approvals/:id/approve", s.approveToolCall)
+	admin.POST("/approvals/:id/deny", s.denyToolCall)
+
+	admin.GET("/audit", s.getAuditEntries)
+	admin.GET("/audit/verify", s.verifyAuditLog)
+
+	admin.GET("/traces", s.getTraces)
+	admin.GET("/traces/:id", s.getTrace)
+
+	admin.POST("/authz/check", s.checkAuthz)
+
+	admin.GET("/search", s.searchConfig)
+
+	admin.GET("/stats", s.getStats)
+	admin.GET("/usage", s.getUsage)
+
+	admin.GET("/events", s.streamEvents)
+
+	admin.GET("/config", s.getConfig)
+	admin.POST("/reload", s.reloadConfig)
+
+	admin.GET("/maintenance", s.getMaintenance)
+	admin.PUT("/maintenance", s.updateMaintenance)
+
+	admin.GET("/export", s.exportSnapshot)
+	admin.POST("/import", s.importSnapshot)
 }
 
 // @Summary		Get all proxies
-// @Description	Get all proxies
+// @Description	Get all proxies, or those having all the labels of the label parameters
 // @Tags			proxies
 // @Accept			json
 // @Produce		json
+// @Param			label	query	[]string	false	"key=value labels of the proxies, e.g. team=payments"	collectionFormat(multi)
 // @Security		Authentication
 // @Success		200	{array}	storage.ProxyConfig
+// @Failure		400	{object}	map[string]string
 // @Failure		500	{object}	map[string]string
 // @Router			/v1/admin/proxies [get]
 func (s *Server) getProxies(c echo.Context) error {
+	var selectors []map[string]string
+	for _, label := range c.QueryParams()["label"] {
+		selector, err := storage.ParseLabelSelector(label)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		selectors = append(selectors, selector)
+	}
+
 	proxies, err := s.Storage.ListProxies(c.Request().Context(), false)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
-	if len(proxies) == 0 {
-		proxies = []storage.ProxyConfig{}
+	filtered := []storage.ProxyConfig{}
+	for _, proxy := range proxies {
+		if !slices.ContainsFunc(selectors, func(selector map[string]string) bool {
+			return !storage.MatchLabels(proxy.Labels, selector)
+		}) {
+			filtered = append(filtered, proxy)
+		}
 	}
-	return c.JSON(http.StatusOK, proxies)
+	return c.JSON(http.StatusOK, filtered)
 }
 
 // @Summary		Get a proxy
@@ -64,11 +210,12 @@ func (s *Server) getProxy(c echo.Context) error {
 }
 
 // @Summary		Upsert a proxy
-// @Description	Upsert a proxy
+// @Description	Upsert a proxy. With validate=connect, the gateway first connects to the upstream server and rejects the proxy if it can not.
 // @Tags			proxies
 // @Accept			json
 // @Produce		json
-// @Param			proxy	body	storage.ProxyConfig	true	"Proxy"
+// @Param			proxy		body	storage.ProxyConfig	true	"Proxy"
+// @Param			validate	query	string				false	"connect to check that the URL and credentials work before saving"	Enums(connect)
 // @Success		200	{object}	storage.ProxyConfig
 // @Failure		400	{object}	map[string]string
 // @Failure		500	{object}	map[string]string
@@ -80,9 +227,19 @@ func (s *Server) upsertProxy(c echo.Context) error {
 	if err := c.Bind(&proxy); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
+	validate := c.QueryParam("validate")
+	if validate != "" && validate != "connect" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid validate %q, must be connect", validate)})
+	}
 
 	proxy.Timeout *= time.Second
 
+	if validate == "connect" {
+		if err := s.checkProxyConnection(c.Request().Context(), &proxy); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
 	err = s.Storage.SetProxy(c.Request().Context(), &proxy, true)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
@@ -109,6 +266,105 @@ func (s *Server) deleteProxy(c echo.Context) error {
 	return nil
 }
 
+// @Summary		Get the tools of a proxy
+// @Description	Get the tools currently registered by the gateway for a proxy
+// @Tags			proxies
+// @Accept			json
+// @Produce		json
+// @Param			name	path	string	true	"Proxy name"
+// @Success		200	{object}	ProxyToolsResponse
+// @Failure		404	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/proxies/{name}/tools [get]
+func (s *Server) getProxyTools(c echo.Context) error {
+	name := c.Param("name")
+	response := ProxyToolsResponse{Proxy: name, Tools: []ToolSummary{}}
+
+	registered, ok := s.tools.get(name)
+	if !ok {
+		// The proxy may exist but not have been synced yet.
+		if _, err := s.Storage.GetProxy(c.Request().Context(), name, false); err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "proxy not found"})
+		}
+		return c.JSON(http.StatusOK, response)
+	}
+
+	response.SyncedAt = &registered.SyncedAt
+	for _, t := range registered.Tools {
+		response.Tools = append(response.Tools, summarizeTool(t.Tool))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// summarizeTool builds the admin API summary of a tool.
+func summarizeTool(tool mcp.Tool) ToolSummary {
+	properties := make([]string, 0, len(tool.InputSchema.Properties))
+	for property := range tool.InputSchema.Properties {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+
+	required := tool.InputSchema.Required
+	if required == nil {
+		required = []string{}
+	}
+
+	return ToolSummary{
+		Name:        tool.Name,
+		Description: tool.Description,
+		InputSchema: InputSchemaSummary{
+			Type:       tool.InputSchema.Type,
+			Properties: properties,
+			Required:   required,
+		},
+	}
+}
+
+// @Summary		Call a tool
+// @Description	Call a tool of a proxy with the given arguments under the admin identity and return the raw result
+// @Tags			proxies
+// @Accept			json
+// @Produce		json
+// @Param			name	path	string			true	"Proxy name"
+// @Param			tool	path	string			true	"Tool name, without the proxy prefix"
+// @Param			request	body	CallToolRequest	true	"Tool arguments"
+// @Success		200	{object}	map[string]any
+// @Failure		400	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		502	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/proxies/{name}/tools/{tool}/call [post]
+func (s *Server) callProxyTool(c echo.Context) error {
+	proxyName := c.Param("name")
+	toolName := c.Param("tool")
+
+	body := CallToolRequest{}
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tool, ok := s.tools.getTool(proxyName, toolName)
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "tool not found"})
+	}
+
+	s.Logger.Info("Admin tool call",
+		zap.String("proxy", proxyName),
+		zap.String("tool", toolName),
+		zap.Any("request_arguments", s.Redactor.Arguments(proxyName, body.Arguments)))
+
+	request := mcp.CallToolRequest{}
+	request.Method = string(mcp.MethodToolsCall)
+	request.Params.Name = tool.Tool.Name
+	request.Params.Arguments = body.Arguments
+
+	result, err := tool.Handler(c.Request().Context(), request)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
 // @Summary		Get all roles
 // @Description	Get all roles
 // @Tags			roles
@@ -237,3 +493,321 @@ func (s *Server) deleteAttributeToRole(c echo.Context) error {
 	}
 	return nil
 }
+
+// @Summary		Plan attribute to roles
+// @Description	Compare a full set of attribute-to-roles mappings with the current ones and return the roles that replacing them would add and remove, without applying anything. The current mappings missing from the set are deleted, and the unchanged ones are omitted.
+// @Tags			attribute to roles
+// @Accept			json
+// @Produce		json
+// @Param			attributeToRoles	body	[]storage.AttributeToRolesConfig	true	"Desired attribute to roles"
+// @Success		200	{array}	manifest.MappingChange
+// @Failure		400	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/attribute-to-roles:plan [post]
+func (s *Server) planAttributeToRoles(c echo.Context) error {
+	attributeToRoles := []storage.AttributeToRolesConfig{}
+	if err := c.Bind(&attributeToRoles); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := (&manifest.Manifest{AttributeToRoles: attributeToRoles}).Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	changes, err := manifest.PlanMappings(c.Request().Context(), s.Storage, attributeToRoles)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, changes)
+}
+
+// @Summary		Simulate a permission check
+// @Description	Check whether the given claims (or the claims of a token) grant access to an object, and which role and rule decided it
+// @Tags			authz
+// @Accept			json
+// @Produce		json
+// @Param			request	body		AuthzCheckRequest	true	"Permission check"
+// @Success		200		{object}	auth.PermissionDecision
+// @Failure		400		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/authz/check [post]
+func (s *Server) checkAuthz(c echo.Context) error {
+	if s.Provider == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "auth provider is disabled"})
+	}
+
+	body := AuthzCheckRequest{}
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if body.ObjectType == "" || body.Proxy == "" || body.ObjectName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "objectType, proxy and objectName are required"})
+	}
+
+	claims := body.Claims
+	switch {
+	case body.Token != "" && claims != nil:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "claims and token are mutually exclusive"})
+	case body.Token != "":
+		jwtToken, err := s.Provider.VerifyToken(strings.TrimPrefix(body.Token, "Bearer "))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		claims = jwtToken.Claims
+	case claims == nil:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "claims or token is required"})
+	}
+
+	decision := s.Provider.ExplainPermissions(c.Request().Context(), body.ObjectType, body.Proxy, body.ObjectName, claims)
+	return c.JSON(http.StatusOK, decision)
+}
+
+// @Summary		Get usage statistics
+// @Description	Summarize the tool calls, error rates, top tools and top identities over a window
+// @Tags			stats
+// @Accept			json
+// @Produce		json
+// @Param			window	query		string	false	"Window (1h, 24h, 7d, 30d)"	default(24h)
+// @Param			limit	query		int		false	"Number of top tools and identities"	default(10)
+// @Success		200		{object}	storage.UsageStats
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/stats [get]
+func (s *Server) getStats(c echo.Context) error {
+	window := c.QueryParam("window")
+	if window == "" {
+		window = defaultStatsWindow
+	}
+	duration, ok := statsWindows[window]
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "window must be one of 1h, 24h, 7d, 30d"})
+	}
+
+	limit := defaultStatsLimit
+	if raw := c.QueryParam("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxStatsLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("limit must be between 1 and %d", maxStatsLimit)})
+		}
+	}
+
+	stats, err := s.Storage.GetUsageStats(c.Request().Context(), time.Now().Add(-duration), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// @Summary		Get the daily usage
+// @Description	Get the tool call counts and durations rolled up by UTC day, identity, proxy and tool, for the chargeback
+// @Tags			stats
+// @Accept			json
+// @Produce		json,text/csv
+// @Param			from	query	string	false	"First day, YYYY-MM-DD (default 29 days before to)"
+// @Param			to		query	string	false	"Last day, YYYY-MM-DD (default today)"
+// @Param			format	query	string	false	"Response format (json, csv)"	default(json)
+// @Success		200		{array}		storage.DailyUsage
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/usage [get]
+func (s *Server) getUsage(c echo.Context) error {
+	to := time.Now().UTC()
+	if raw := c.QueryParam("to"); raw != "" {
+		var err error
+		if to, err = time.Parse(storage.DayFormat, raw); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "to must be a day formatted as YYYY-MM-DD"})
+		}
+	}
+	from := to.AddDate(0, 0, 1-defaultUsageDays)
+	if raw := c.QueryParam("from"); raw != "" {
+		var err error
+		if from, err = time.Parse(storage.DayFormat, raw); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "from must be a day formatted as YYYY-MM-DD"})
+		}
+	}
+	if from.After(to) || to.Sub(from) >= maxUsageDays*24*time.Hour {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("from must be before to, at most %d days apart", maxUsageDays)})
+	}
+
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "format must be json or csv"})
+	}
+
+	usage, err := s.Storage.GetDailyUsage(c.Request().Context(), from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if format != "csv" {
+		return c.JSON(http.StatusOK, usage)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="mcp-gateway-usage-%s-%s.csv"`,
+		from.Format(storage.DayFormat), to.Format(storage.DayFormat)))
+	c.Response().WriteHeader(http.StatusOK)
+	w := csv.NewWriter(c.Response())
+	_ = w.Write([]string{"day", "identity", "proxy", "tool", "calls", "errors", "duration_ms"})
+	for _, u := range usage {
+		_ = w.Write([]string{u.Day, u.Identity, u.Proxy, u.Tool,
+			strconv.FormatInt(u.Calls, 10), strconv.FormatInt(u.Errors, 10), strconv.FormatInt(u.DurationMs, 10)})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// @Summary		Stream the gateway events
+// @Description	Server-sent events stream of the tool calls, proxy health checks and admin changes, in real time
+// @Tags			events
+// @Produce		text/event-stream
+// @Param			types	query		string	false	"Comma-separated event types to receive (tool_call, proxy_health, admin_mutation)"
+// @Success		200		{object}	events.Event
+// @Failure		400		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/events [get]
+func (s *Server) streamEvents(c echo.Context) error {
+	types := map[events.Type]bool{}
+	if raw := c.QueryParam("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			eventType := events.Type(strings.TrimSpace(t))
+			if !eventType.IsValid() {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("unknown event type %q", eventType)})
+			}
+			types[eventType] = true
+		}
+	}
+
+	ch, unsubscribe := s.eventBroker.Subscribe()
+	defer unsubscribe()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			w.Flush()
+		case event, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if len(types) > 0 && !types[event.Type] {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				s.Logger.Error("Failed to encode the event", zap.Error(err))
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
+
+// ImportResponse lists the changes made by an import.
+type ImportResponse struct {
+	Changes []manifest.Change `json:"changes"`
+	DryRun  bool              `json:"dryRun"`
+}
+
+// @Summary		Export a snapshot
+// @Description	Export the proxies, roles and attribute-to-roles mappings, in the manifest format of the apply command
+// @Tags			snapshot
+// @Produce		json
+// @Param			secrets	query		bool	false	"Include the header values, OAuth client secrets and HMAC secrets of the proxies"	default(false)
+// @Success		200		{object}	manifest.Manifest
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/export [get]
+func (s *Server) exportSnapshot(c echo.Context) error {
+	secrets, err := boolQueryParam(c, "secrets")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	snapshot, err := manifest.Export(c.Request().Context(), s.Storage, secrets)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, snapshot)
+}
+
+// @Summary		Import a snapshot
+// @Description	Create and update the objects of a snapshot. An empty header value or client secret keeps the value of the backend.
+// @Tags			snapshot
+// @Accept			json
+// @Produce		json
+// @Param			snapshot	body		manifest.Manifest	true	"Snapshot"
+// @Param			prune		query		bool				false	"Delete the objects missing from the snapshot"	default(false)
+// @Param			dryRun		query		bool				false	"Return the changes without applying them"		default(false)
+// @Success		200			{object}	ImportResponse
+// @Failure		400			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Security		Authentication
+// @Router			/v1/admin/import [post]
+func (s *Server) importSnapshot(c echo.Context) error {
+	prune, err := boolQueryParam(c, "prune")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	dryRun, err := boolQueryParam(c, "dryRun")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	snapshot := &manifest.Manifest{}
+	if err := c.Bind(snapshot); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := snapshot.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	if err := manifest.KeepSecrets(ctx, s.Storage, snapshot); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	changes, err := manifest.Plan(ctx, s.Storage, snapshot, prune)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if !dryRun {
+		if err := manifest.Apply(ctx, s.Storage, changes); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+	if changes == nil {
+		changes = []manifest.Change{}
+	}
+	return c.JSON(http.StatusOK, ImportResponse{Changes: changes, DryRun: dryRun})
+}
+
+// boolQueryParam parses a boolean query parameter, false if missing.
+func boolQueryParam(c echo.Context, name string) (bool, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,401 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/events"
+	"github.com/matthisholleville/mcp-gateway/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCallToolContext creates an echo context for a test call of a proxy tool.
+func newCallToolContext(srv *Server, proxy, tool, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/proxies/"+proxy+"/tools/"+tool+"/call", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := srv.Router.NewContext(req, rec)
+	c.SetParamNames("name", "tool")
+	c.SetParamValues(proxy, tool)
+	return c, rec
+}
+
+func TestCallProxyTool(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.tools = newToolRegistry()
+	srv.tools.set("github", []server.ServerTool{
+		{
+			Tool: mcp.NewTool("github:echo"),
+			Handler: func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(req.GetString("message", "")), nil
+			},
+		},
+		{
+			Tool: mcp.NewTool("github:broken"),
+			Handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return nil, errors.New("upstream unavailable")
+			},
+		},
+	}, time.Now())
+
+	for _, test := range []struct {
+		name         string
+		tool         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "success", tool: "echo", body: `{"arguments":{"message":"hello"}}`, expectedCode: http.StatusOK, expectedBody: `"text":"hello"`},
+		{name: "unknown tool", tool: "missing", body: `{}`, expectedCode: http.StatusNotFound},
+		{name: "upstream error", tool: "broken", body: `{}`, expectedCode: http.StatusBadGateway, expectedBody: "upstream unavailable"},
+		{name: "invalid body", tool: "echo", body: `{`, expectedCode: http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c, rec := newCallToolContext(srv, "github", test.tool, test.body)
+			require.NoError(t, srv.callProxyTool(c))
+			assert.Equal(t, test.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), test.expectedBody)
+		})
+	}
+}
+
+func TestGetProxies(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Storage = storage.NewMemoryStorage("")
+	for name, labels := range map[string]map[string]string{
+		"billing":         {"team": "payments", "env": "production"},
+		"billing-staging": {"team": "payments", "env": "staging"},
+		"search":          nil,
+	} {
+		require.NoError(t, srv.Storage.SetProxy(t.Context(), &storage.ProxyConfig{
+			Name: name, Type: storage.ProxyTypeStreamableHTTP, AuthType: storage.ProxyAuthTypeHeader, Labels: labels,
+		}, false))
+	}
+
+	for _, test := range []struct {
+		name            string
+		query           string
+		expectedCode    int
+		expectedProxies []string
+	}{
+		{name: "all", expectedCode: http.StatusOK, expectedProxies: []string{"billing", "billing-staging", "search"}},
+		{name: "label", query: "?label=team=payments", expectedCode: http.StatusOK, expectedProxies: []string{"billing", "billing-staging"}},
+		{name: "labels", query: "?label=team=payments&label=env=staging", expectedCode: http.StatusOK,
+			expectedProxies: []string{"billing-staging"}},
+		{name: "no match", query: "?label=team=search", expectedCode: http.StatusOK, expectedProxies: []string{}},
+		{name: "invalid label", query: "?label=team", expectedCode: http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin/proxies"+test.query, nil)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, srv.getProxies(srv.Router.NewContext(req, rec)))
+			require.Equal(t, test.expectedCode, rec.Code)
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+			var proxies []storage.ProxyConfig
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &proxies))
+			names := []string{}
+			for _, proxy := range proxies {
+				names = append(names, proxy.Name)
+			}
+			assert.ElementsMatch(t, test.expectedProxies, names)
+		})
+	}
+}
+
+func TestUpsertProxy(t *testing.T) {
+	upstream := httptest.NewServer(server.NewStreamableHTTPServer(server.NewMCPServer("GitHub MCP", "2.3.0")))
+	defer upstream.Close()
+	srv := createTestServer(false, &MockProvider{})
+	srv.Config.Proxy = cfg.DefaultConfig().Proxy
+	srv.Storage = storage.NewMemoryStorage("")
+
+	for _, test := range []struct {
+		name         string
+		query        string
+		url          string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "without validation", url: "http://127.0.0.1:1/mcp", expectedCode: http.StatusOK},
+		{name: "reachable", query: "?validate=connect", url: upstream.URL, expectedCode: http.StatusOK},
+		{name: "unreachable", query: "?validate=connect", url: "http://127.0.0.1:1/mcp", expectedCode: http.StatusBadRequest,
+			expectedBody: "unable to connect to the MCP server"},
+		{name: "unknown validation", query: "?validate=tools", url: upstream.URL, expectedCode: http.StatusBadRequest,
+			expectedBody: "must be connect"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			name := strings.ReplaceAll(test.name, " ", "-")
+			body := `{"name":"` + name + `","type":"streamable-http","url":"` + test.url + `","authType":"header","timeout":5}`
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/proxies/"+name+test.query, strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, srv.upsertProxy(srv.Router.NewContext(req, rec)))
+			assert.Equal(t, test.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), test.expectedBody)
+			_, err := srv.Storage.GetProxy(t.Context(), name, false)
+			assert.Equal(t, test.expectedCode == http.StatusOK, err == nil)
+		})
+	}
+}
+
+func TestCheckAuthz(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		provider     *MockProvider
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "claims", provider: &MockProvider{shouldVerifyPermissions: true},
+			body:         `{"claims":{"groups":["dev"]},"objectType":"tools","proxy":"github","objectName":"search"}`,
+			expectedCode: http.StatusOK, expectedBody: `"allowed":true`},
+		{name: "token", provider: &MockProvider{shouldVerifyToken: true},
+			body:         `{"token":"Bearer abc","objectType":"tools","proxy":"github","objectName":"search"}`,
+			expectedCode: http.StatusOK, expectedBody: `"allowed":false`},
+		{name: "invalid token", provider: &MockProvider{},
+			body:         `{"token":"abc","objectType":"tools","proxy":"github","objectName":"search"}`,
+			expectedCode: http.StatusBadRequest},
+		{name: "no identity", provider: &MockProvider{},
+			body:         `{"objectType":"tools","proxy":"github","objectName":"search"}`,
+			expectedCode: http.StatusBadRequest, expectedBody: "claims or token is required"},
+		{name: "missing object", provider: &MockProvider{},
+			body:         `{"claims":{"groups":["dev"]}}`,
+			expectedCode: http.StatusBadRequest, expectedBody: "objectType, proxy and objectName are required"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			srv := createTestServer(false, test.provider)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/authz/check", strings.NewReader(test.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, srv.checkAuthz(srv.Router.NewContext(req, rec)))
+			assert.Equal(t, test.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), test.expectedBody)
+		})
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Storage = storage.NewMemoryStorage("")
+	require.NoError(t, srv.Storage.RecordToolCall(t.Context(), storage.ToolCallRecord{
+		Proxy: "github", Tool: "search", Identity: "alice", CalledAt: time.Now(),
+	}))
+
+	for _, test := range []struct {
+		name          string
+		query         string
+		expectedCode  int
+		expectedCalls int64
+	}{
+		{name: "default window", expectedCode: http.StatusOK, expectedCalls: 1},
+		{name: "explicit window", query: "?window=7d&limit=5", expectedCode: http.StatusOK, expectedCalls: 1},
+		{name: "invalid window", query: "?window=2d", expectedCode: http.StatusBadRequest},
+		{name: "invalid limit", query: "?limit=0", expectedCode: http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin/stats"+test.query, nil)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, srv.getStats(srv.Router.NewContext(req, rec)))
+			assert.Equal(t, test.expectedCode, rec.Code)
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+			var stats storage.UsageStats
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+			assert.Equal(t, test.expectedCalls, stats.TotalCalls)
+		})
+	}
+}
+
+func TestGetUsage(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Storage = storage.NewMemoryStorage("")
+	for _, record := range []storage.ToolCallRecord{
+		{Proxy: "github", Tool: "search", Identity: "alice", CalledAt: time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC), Duration: 200 * time.Millisecond},
+		{Proxy: "github", Tool: "search", Identity: "alice", CalledAt: time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC), Duration: time.Second, IsError: true},
+		{Proxy: "github", Tool: "search", Identity: "alice", CalledAt: time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)},
+	} {
+		require.NoError(t, srv.Storage.RecordToolCall(t.Context(), record))
+	}
+
+	for _, test := range []struct {
+		name         string
+		query        string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "json", query: "?from=2025-06-01&to=2025-06-01", expectedCode: http.StatusOK,
+			expectedBody: `[{"day":"2025-06-01","identity":"alice","proxy":"github","tool":"search","calls":2,"errors":1,"durationMs":1200}]`},
+		{name: "csv", query: "?from=2025-06-01&to=2025-06-02&format=csv", expectedCode: http.StatusOK,
+			expectedBody: "day,identity,proxy,tool,calls,errors,duration_ms\n" +
+				"2025-06-01,alice,github,search,2,1,1200\n" +
+				"2025-06-02,alice,github,search,1,0,0\n"},
+		{name: "default range", query: "?to=2025-06-30", expectedCode: http.StatusOK, expectedBody: `"day":"2025-06-02"`},
+		{name: "empty range", query: "?from=2025-07-01&to=2025-07-02", expectedCode: http.StatusOK, expectedBody: `[]`},
+		{name: "invalid day", query: "?from=06/01/2025", expectedCode: http.StatusBadRequest},
+		{name: "inverted range", query: "?from=2025-06-02&to=2025-06-01", expectedCode: http.StatusBadRequest},
+		{name: "too long range", query: "?from=2024-01-01&to=2025-06-01", expectedCode: http.StatusBadRequest},
+		{name: "invalid format", query: "?format=xml", expectedCode: http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin/usage"+test.query, nil)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, srv.getUsage(srv.Router.NewContext(req, rec)))
+			assert.Equal(t, test.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), test.expectedBody)
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Storage = storage.NewMemoryStorage("")
+	require.NoError(t, srv.Storage.SetProxy(t.Context(), &storage.ProxyConfig{
+		Name: "github", Type: storage.ProxyTypeStreamableHTTP, URL: "https://github.example.com/mcp",
+		AuthType: storage.ProxyAuthTypeHeader, Headers: []storage.ProxyHeader{{Key: "Authorization", Value: "Bearer secret"}},
+	}, false))
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, srv.exportSnapshot(srv.Router.NewContext(httptest.NewRequest(http.MethodGet, "/v1/admin/export", nil), rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "Bearer secret")
+	snapshot := rec.Body.String()
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/export?secrets=maybe", nil)
+	require.NoError(t, srv.exportSnapshot(srv.Router.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	for _, test := range []struct {
+		name            string
+		query           string
+		body            string
+		expectedCode    int
+		expectedChanges string
+	}{
+		{name: "unchanged", body: snapshot, expectedCode: http.StatusOK, expectedChanges: `[]`},
+		{name: "dry run", query: "?dryRun=true", body: `{"roles":[{"name":"developer"}]}`, expectedCode: http.StatusOK,
+			expectedChanges: `[{"kind":"role","name":"developer","action":"create"}]`},
+		{name: "prune", query: "?prune=true", body: `{}`, expectedCode: http.StatusOK,
+			expectedChanges: `[{"kind":"proxy","name":"github","action":"delete"}]`},
+		{name: "invalid", body: `{"roles":[{"name":""}]}`, expectedCode: http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/import"+test.query, strings.NewReader(test.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, srv.importSnapshot(srv.Router.NewContext(req, rec)))
+			assert.Equal(t, test.expectedCode, rec.Code)
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+			var response struct {
+				Changes json.RawMessage `json:"changes"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.JSONEq(t, test.expectedChanges, string(response.Changes))
+		})
+	}
+
+	roles, err := srv.Storage.ListRoles(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+}
+
+func TestPlanAttributeToRoles(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.Storage = storage.NewMemoryStorage("")
+	require.NoError(t, srv.Storage.SetRole(t.Context(), storage.RoleConfig{Name: "developer"}))
+	require.NoError(t, srv.Storage.SetAttributeToRoles(t.Context(), storage.AttributeToRolesConfig{
+		AttributeKey: "groups", AttributeValue: "dev", Roles: []string{"developer"},
+	}))
+
+	for _, test := range []struct {
+		name            string
+		body            string
+		expectedCode    int
+		expectedChanges string
+	}{
+		{name: "unchanged", body: `[{"attribute_key":"groups","attribute_value":"dev","roles":["developer"]}]`,
+			expectedCode: http.StatusOK, expectedChanges: `[]`},
+		{name: "replace", body: `[{"attribute_key":"groups","attribute_value":"ops","roles":["developer"]}]`, expectedCode: http.StatusOK,
+			expectedChanges: `[{"attribute_key":"groups","attribute_value":"dev","action":"delete","added_roles":[],"removed_roles":["developer"]},` +
+				`{"attribute_key":"groups","attribute_value":"ops","action":"create","added_roles":["developer"],"removed_roles":[]}]`},
+		{name: "duplicate", body: `[{"attribute_key":"groups","attribute_value":"dev"},{"attribute_key":"groups","attribute_value":"dev"}]`,
+			expectedCode: http.StatusBadRequest},
+		{name: "without value", body: `[{"attribute_key":"groups"}]`, expectedCode: http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/attribute-to-roles:plan", strings.NewReader(test.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, srv.planAttributeToRoles(srv.Router.NewContext(req, rec)))
+			assert.Equal(t, test.expectedCode, rec.Code, rec.Body.String())
+			if test.expectedCode == http.StatusOK {
+				assert.JSONEq(t, test.expectedChanges, rec.Body.String())
+			}
+		})
+	}
+
+	mappings, err := srv.Storage.ListAttributeToRoles(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, mappings, 1)
+}
+
+func TestIdentityFromContext(t *testing.T) {
+	assert.Equal(t, anonymousIdentity, identityFromContext(context.Background()))
+	//nolint:staticcheck,revive // We need to use the key as a string
+	ctx := context.WithValue(context.Background(), "claims", map[string]interface{}{"sub": "alice"})
+	assert.Equal(t, "alice", identityFromContext(ctx))
+}
+
+func TestStreamEvents(t *testing.T) {
+	srv := createTestServer(false, &MockProvider{})
+	srv.eventBroker = events.NewBroker(10)
+	srv.Router.GET("/v1/admin/events", srv.streamEvents)
+	ts := httptest.NewServer(srv.Router)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/v1/admin/events?types=unknown")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + "/v1/admin/events?types=tool_call")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get(echo.HeaderContentType))
+	require.Eventually(t, func() bool { return srv.eventBroker.Subscribers() == 1 }, time.Second, 10*time.Millisecond)
+
+	srv.eventBroker.Publish(events.TypeProxyHealth, events.ProxyHealth{Proxy: "github", Healthy: true})
+	srv.eventBroker.Publish(events.TypeToolCall, events.ToolCall{Proxy: "github", Tool: "search", Identity: "alice"})
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: tool_call\n", line)
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"data":{"proxy":"github","tool":"search","identity":"alice","isError":false}`)
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrApprovalDecided is returned when deciding an approval which is no longer pending.
+var ErrApprovalDecided = errors.New("the approval is no longer pending")
+
+// ToolPolicyConfig marks a tool as sensitive: its calls are parked until an approver approves them. Tool accepts
+// the "*" wildcard, for every tool of the proxy without its own policy, and Proxy too, for every tool of the gateway.
+type ToolPolicyConfig struct {
+	Proxy            string `json:"proxy"`
+	Tool             string `json:"tool"`
+	RequiresApproval bool   `json:"requiresApproval"`
+}
+
+// Validate checks the tool policy before it is stored.
+func (p *ToolPolicyConfig) Validate() error {
+	return validateToolPattern("tool policy", p.Proxy, p.Tool)
+}
+
+// RequiresApproval returns true if the calls of the tool must be approved, from the most specific policy matching it.
+func RequiresApproval(policies []ToolPolicyConfig, proxy, tool string) bool {
+	required, specificity := false, -1
+	for _, p := range policies {
+		if s := toolSpecificity(p.Proxy, p.Tool, proxy, tool); s > specificity {
+			required, specificity = p.RequiresApproval, s
+		}
+	}
+	return required
+}
+
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  ApprovalStatus = "pending"
+	ApprovalStatusApproved ApprovalStatus = "approved"
+	ApprovalStatusDenied   ApprovalStatus = "denied"
+	// ApprovalStatusExpired is the status of the approvals not decided in time.
+	ApprovalStatusExpired ApprovalStatus = "expired"
+	// ApprovalStatusCancelled is the status of the approvals whose caller went away before the decision.
+	ApprovalStatusCancelled ApprovalStatus = "cancelled"
+)
+
+func (s ApprovalStatus) IsValid() bool {
+	switch s {
+	case ApprovalStatusPending, ApprovalStatusApproved, ApprovalStatusDenied, ApprovalStatusExpired, ApprovalStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// ApprovalRequest is a parked tool call awaiting a decision, kept once decided as an audit record.
+type ApprovalRequest struct {
+	ID       string `json:"id"`
+	Proxy    string `json:"proxy"`
+	Tool     string `json:"tool"`
+	Identity string `json:"identity"`
+	// Arguments are the arguments of the call, redacted like in the logs.
+	Arguments   json.RawMessage `json:"arguments,omitempty"`
+	Status      ApprovalStatus  `json:"status"`
+	RequestedAt time.Time       `json:"requestedAt"`
+	ExpiresAt   time.Time       `json:"expiresAt"`
+	DecidedAt   *time.Time      `json:"decidedAt,omitempty"`
+	DecidedBy   string          `json:"decidedBy,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+}
+
+type ApprovalInterface interface {
+	ListToolPolicies(ctx context.Context) ([]ToolPolicyConfig, error)
+	SetToolPolicy(ctx context.Context, policy ToolPolicyConfig) error
+	DeleteToolPolicy(ctx context.Context, proxy, tool string) error
+	CreateApproval(ctx context.Context, approval ApprovalRequest) error
+	GetApproval(ctx context.Context, id string) (ApprovalRequest, error)
+	// ListApprovals lists the approvals with the status, or all of them if empty, the latest first.
+	ListApprovals(ctx context.Context, status ApprovalStatus) ([]ApprovalRequest, error)
+	// DecideApproval records the decision of a pending approval and returns it. It returns ErrApprovalDecided if the
+	// approval was already decided.
+	DecideApproval(ctx context.Context, id string, status ApprovalStatus, decidedBy, reason string, now time.Time) (ApprovalRequest, error)
+}
+
+// validateDecision checks the status of a decision.
+func validateDecision(status ApprovalStatus) error {
+	if !status.IsValid() || status == ApprovalStatusPending {
+		return fmt.Errorf("invalid approval decision: %s", status)
+	}
+	return nil
+}
@@ -8,9 +8,18 @@ type AttributeToRolesConfig struct {
 	Roles          []string `json:"roles"`
 }
 
+// AttributePair is an attribute of the claims of a caller, with one of its values.
+type AttributePair struct {
+	Key   string
+	Value string
+}
+
 type AttributeToRolesInterface interface {
 	ListAttributeToRoles(ctx context.Context) ([]AttributeToRolesConfig, error)
 	SetAttributeToRoles(ctx context.Context, attributeToRoles AttributeToRolesConfig) error
 	GetAttributeToRoles(ctx context.Context, attributeKey, attributeValue string) (AttributeToRolesConfig, error)
+	// GetAttributeToRolesForClaims gets the mappings of the attribute pairs in a single lookup. The pairs mapped
+	// to no role are skipped.
+	GetAttributeToRolesForClaims(ctx context.Context, pairs []AttributePair) ([]AttributeToRolesConfig, error)
 	DeleteAttributeToRoles(ctx context.Context, attributeKey, attributeValue string) error
 }
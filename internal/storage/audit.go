@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditEntry is a record of the tamper-evident audit log. Each entry includes the hash of the previous one, so
+// altering, inserting or removing an entry breaks the chain from there.
+type AuditEntry struct {
+	// Seq is the position of the entry in the log, starting at 1 with no gap.
+	Seq  int64  `json:"seq"`
+	Type string `json:"type"`
+	// Data is the JSON record, e.g. a tool call or an admin mutation, hashed as is.
+	Data       json.RawMessage `json:"data"`
+	RecordedAt time.Time       `json:"recordedAt"`
+	// PrevHash is the hash of the previous entry, empty for the first one.
+	PrevHash string `json:"prevHash"`
+	Hash     string `json:"hash"`
+}
+
+// ComputeHash returns the SHA-256 digest of the entry, chained to the hash of the previous entry.
+func (e AuditEntry) ComputeHash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n%s\n%s\n", e.Seq, e.Type, e.RecordedAt.UTC().Format(time.RFC3339Nano), e.PrevHash)
+	h.Write(e.Data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// newAuditEntry chains a record to the last entry of the log, nil if empty. The time is truncated to the
+// microsecond, the precision of the databases, so the stored entries hash the same.
+func newAuditEntry(last *AuditEntry, entryType string, data json.RawMessage, recordedAt time.Time) AuditEntry {
+	entry := AuditEntry{Seq: 1, Type: entryType, Data: data, RecordedAt: recordedAt.UTC().Truncate(time.Microsecond)}
+	if last != nil {
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	}
+	entry.Hash = entry.ComputeHash()
+	return entry
+}
+
+type AuditInterface interface {
+	// AppendAuditEntry chains a record to the end of the audit log and returns its entry. Concurrent appends,
+	// from any replica, are serialized.
+	AppendAuditEntry(ctx context.Context, entryType string, data json.RawMessage, recordedAt time.Time) (AuditEntry, error)
+	// ListAuditEntries lists at most limit entries following the sequence number after, in order.
+	ListAuditEntries(ctx context.Context, after int64, limit int) ([]AuditEntry, error)
+}
+
+// AuditVerification is the result of the verification of the audit log.
+type AuditVerification struct {
+	Valid   bool  `json:"valid"`
+	Entries int64 `json:"entries"`
+	// LastHash is the hash of the last verified entry. Keeping it outside of the gateway also proves that no entry
+	// was removed from the end of the log.
+	LastHash string `json:"lastHash,omitempty"`
+	// BrokenAt is the sequence number of the first entry breaking the chain.
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// VerifyAuditLog reads the whole audit log, batchSize entries at once, and checks that each entry chains to the
+// previous one and matches its hash.
+func VerifyAuditLog(ctx context.Context, store AuditInterface, batchSize int) (AuditVerification, error) {
+	if batchSize <= 0 {
+		return AuditVerification{}, fmt.Errorf("the batch size must be positive")
+	}
+	result := AuditVerification{Valid: true}
+	var last *AuditEntry
+	for {
+		entries, err := store.ListAuditEntries(ctx, result.Entries, batchSize)
+		if err != nil {
+			return result, err
+		}
+		for i := range entries {
+			entry := entries[i]
+			if reason := verifyAuditEntry(last, entry); reason != "" {
+				result.Valid = false
+				result.BrokenAt = entry.Seq
+				result.Reason = reason
+				return result, nil
+			}
+			last = &entry
+			result.Entries = entry.Seq
+			result.LastHash = entry.Hash
+		}
+		if len(entries) < batchSize {
+			return result, nil
+		}
+	}
+}
+
+func verifyAuditEntry(last *AuditEntry, entry AuditEntry) string {
+	expectedSeq, expectedPrevHash := int64(1), ""
+	if last != nil {
+		expectedSeq, expectedPrevHash = last.Seq+1, last.Hash
+	}
+	switch {
+	case entry.Seq != expectedSeq:
+		return fmt.Sprintf("expected the entry %d, found the entry %d", expectedSeq, entry.Seq)
+	case entry.PrevHash != expectedPrevHash:
+		return "the previous hash does not match the hash of the previous entry"
+	case entry.Hash != entry.ComputeHash():
+		return "the hash does not match the content of the entry"
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultToolCost is the cost of the tools without a cost annotation.
+const DefaultToolCost = 1
+
+// ToolCostConfig is the cost weight of the calls of a tool, counted against the budgets. Tool accepts the "*"
+// wildcard, for every tool of the proxy without its own cost, and Proxy too, for every tool of the gateway.
+type ToolCostConfig struct {
+	Proxy string `json:"proxy"`
+	Tool  string `json:"tool"`
+	Cost  int64  `json:"cost"`
+}
+
+// Validate checks the tool cost before it is stored.
+func (c *ToolCostConfig) Validate() error {
+	if err := validateToolPattern("tool cost", c.Proxy, c.Tool); err != nil {
+		return err
+	}
+	if c.Cost < 0 {
+		return fmt.Errorf("tool cost must not be negative")
+	}
+	return nil
+}
+
+// ToolCost returns the cost of a call of the tool: the cost of the tool, else of its proxy, else the default
+// cost of the gateway.
+func ToolCost(costs []ToolCostConfig, proxy, tool string) int64 {
+	cost, specificity := int64(DefaultToolCost), -1
+	for _, c := range costs {
+		if s := toolSpecificity(c.Proxy, c.Tool, proxy, tool); s > specificity {
+			cost, specificity = c.Cost, s
+		}
+	}
+	return cost
+}
+
+// validateToolPattern checks the proxy and tool patterns of a setting applying to a tool, the tools of a proxy
+// with the "*" tool, or every tool with the "*" proxy.
+func validateToolPattern(kind, proxy, tool string) error {
+	if proxy == "" || tool == "" {
+		return fmt.Errorf("%s proxy and tool are required, use '*' to match any", kind)
+	}
+	if proxy == "*" && tool != "*" {
+		return fmt.Errorf("the tool of a %s must be '*' when its proxy is '*'", kind)
+	}
+	return nil
+}
+
+// toolSpecificity returns how specifically the proxy and tool patterns match a tool: 2 for the tool itself,
+// 1 for the tools of its proxy, 0 for every tool, and -1 if they do not match.
+func toolSpecificity(proxyPattern, toolPattern, proxy, tool string) int {
+	switch {
+	case proxyPattern == proxy && toolPattern == tool:
+		return 2
+	case proxyPattern == proxy && toolPattern == "*":
+		return 1
+	case proxyPattern == "*":
+		return 0
+	default:
+		return -1
+	}
+}
+
+// BudgetAction is what happens to the calls once a budget is exhausted.
+type BudgetAction string
+
+const (
+	// BudgetActionDeny rejects the calls which would exceed the budget.
+	BudgetActionDeny BudgetAction = "deny"
+	// BudgetActionWarn lets the calls through, logging a warning.
+	BudgetActionWarn BudgetAction = "warn"
+)
+
+func (a BudgetAction) IsValid() bool {
+	return a == BudgetActionDeny || a == BudgetActionWarn
+}
+
+// BudgetConfig limits the cost of the calls granted by a role over a window, shared by all the subjects
+// holding the role.
+type BudgetConfig struct {
+	Name   string       `json:"name"`
+	Role   string       `json:"role"`
+	Window QuotaWindow  `json:"window"`
+	Limit  int64        `json:"limit"`
+	Action BudgetAction `json:"action"`
+}
+
+// Validate checks the budget before it is stored.
+func (b *BudgetConfig) Validate() error {
+	if b.Name == "" || b.Role == "" {
+		return fmt.Errorf("budget name and role are required")
+	}
+	if !b.Window.IsValid() {
+		return fmt.Errorf("invalid budget window: %s", b.Window)
+	}
+	if b.Limit < 0 {
+		return fmt.Errorf("budget limit must not be negative")
+	}
+	if !b.Action.IsValid() {
+		return fmt.Errorf("invalid budget action: %s", b.Action)
+	}
+	return nil
+}
+
+// BudgetUsage is the cost consumed from a budget in its current window.
+type BudgetUsage struct {
+	Budget      string    `json:"budget"`
+	Role        string    `json:"role"`
+	Consumed    int64     `json:"consumed"`
+	Limit       int64     `json:"limit"`
+	Remaining   int64     `json:"remaining"`
+	WindowStart time.Time `json:"windowStart"`
+	ResetsAt    time.Time `json:"resetsAt"`
+}
+
+type BudgetInterface interface {
+	ListToolCosts(ctx context.Context) ([]ToolCostConfig, error)
+	SetToolCost(ctx context.Context, cost ToolCostConfig) error
+	DeleteToolCost(ctx context.Context, proxy, tool string) error
+	ListBudgets(ctx context.Context) ([]BudgetConfig, error)
+	SetBudget(ctx context.Context, budget BudgetConfig) error
+	GetBudget(ctx context.Context, name string) (BudgetConfig, error)
+	// DeleteBudget deletes a budget and its usage.
+	DeleteBudget(ctx context.Context, name string) error
+	// ConsumeBudget counts the cost of a call in the current window of the budget. A deny budget does not count
+	// a cost it can not cover, a warn budget always does. It returns the usage, and false if the budget did not
+	// cover the cost.
+	ConsumeBudget(ctx context.Context, budget BudgetConfig, cost int64, now time.Time) (BudgetUsage, bool, error)
+	// GetBudgetUsage returns the usage of the budget in its current window.
+	GetBudgetUsage(ctx context.Context, budget BudgetConfig, now time.Time) (BudgetUsage, error)
+	// ResetBudgetUsage resets the usage of the budget.
+	ResetBudgetUsage(ctx context.Context, name string) error
+}
+
+func newBudgetUsage(budget BudgetConfig, windowStart time.Time, consumed int64) BudgetUsage {
+	return BudgetUsage{
+		Budget:      budget.Name,
+		Role:        budget.Role,
+		Consumed:    consumed,
+		Limit:       budget.Limit,
+		Remaining:   max(budget.Limit-consumed, 0),
+		WindowStart: windowStart,
+		ResetsAt:    budget.Window.End(windowStart),
+	}
+}
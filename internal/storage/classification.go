@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// Classification is the sensitivity level of the data handled by a tool. A role may only call the tools
+// classified at or below its clearance.
+type Classification string
+
+const (
+	ClassificationPublic       Classification = "public"
+	ClassificationInternal     Classification = "internal"
+	ClassificationConfidential Classification = "confidential"
+)
+
+// classificationRanks orders the classification levels, the unclassified tools and the roles without clearance
+// being public.
+var classificationRanks = map[Classification]int{
+	"":                         0,
+	ClassificationPublic:       0,
+	ClassificationInternal:     1,
+	ClassificationConfidential: 2,
+}
+
+func (c Classification) IsValid() bool {
+	return c == ClassificationPublic || c == ClassificationInternal || c == ClassificationConfidential
+}
+
+// Allows returns true if a clearance c covers the classification level.
+func (c Classification) Allows(level Classification) bool {
+	return classificationRanks[c] >= classificationRanks[level]
+}
+
+// ClassificationConfig labels a tool with a classification level. Tool accepts the "*" wildcard, labelling the
+// proxy, and Proxy too, labelling every tool of the gateway.
+type ClassificationConfig struct {
+	Proxy string         `json:"proxy"`
+	Tool  string         `json:"tool"`
+	Level Classification `json:"level"`
+}
+
+// Validate checks the classification label before it is stored.
+func (c *ClassificationConfig) Validate() error {
+	if err := validateToolPattern("classification", c.Proxy, c.Tool); err != nil {
+		return err
+	}
+	if !c.Level.IsValid() {
+		return fmt.Errorf("invalid classification level %q, must be one of public, internal or confidential", c.Level)
+	}
+	return nil
+}
+
+// ClassificationOf returns the classification level of a tool from the most specific label matching it, public if
+// none does.
+func ClassificationOf(labels []ClassificationConfig, proxy, tool string) Classification {
+	level, specificity := ClassificationPublic, -1
+	for _, label := range labels {
+		if s := toolSpecificity(label.Proxy, label.Tool, proxy, tool); s > specificity {
+			level, specificity = label.Level, s
+		}
+	}
+	return level
+}
+
+type ClassificationInterface interface {
+	ListClassifications(ctx context.Context) ([]ClassificationConfig, error)
+	SetClassification(ctx context.Context, classification ClassificationConfig) error
+	DeleteClassification(ctx context.Context, proxy, tool string) error
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	compositeNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	// compositeReferencePattern matches the references of the step arguments, ${args.<name>} or
+	// ${steps.<id>[.<field or index>...]}.
+	compositeReferencePattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+)
+
+// CompositeToolConfig is a tool of the gateway calling a sequence of upstream tools, exposed to the clients as a
+// single tool. The steps run in order, the parallel steps together with the step before them.
+type CompositeToolConfig struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// InputSchema is the JSON schema of the arguments of the tool, an object accepting any argument if empty.
+	InputSchema json.RawMessage `json:"inputSchema,omitempty" swaggertype:"object"`
+	Steps       []CompositeStep `json:"steps"`
+}
+
+// CompositeStep is a call of an upstream tool by a composite tool. The string values of its arguments may reference
+// the arguments of the composite tool, as ${args.<name>}, and the result of an earlier step, as ${steps.<id>}: the
+// text of the result, or its JSON value whose fields and items are referenced as ${steps.<id>.<field>.<index>}.
+// A value which is a single reference is replaced by the referenced value, whatever its type.
+type CompositeStep struct {
+	ID        string         `json:"id"`
+	Proxy     string         `json:"proxy"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// Parallel runs the step concurrently with the previous step, which it cannot reference.
+	Parallel bool `json:"parallel,omitempty"`
+}
+
+// Validate checks the composite tool before it is stored.
+func (t *CompositeToolConfig) Validate() error {
+	if !compositeNamePattern.MatchString(t.Name) {
+		return fmt.Errorf("invalid composite tool name %q, must be 1 to 64 letters, digits, '_' or '-'", t.Name)
+	}
+	if len(t.InputSchema) > 0 {
+		var schema map[string]any
+		if err := json.Unmarshal(t.InputSchema, &schema); err != nil {
+			return fmt.Errorf("invalid input schema of composite tool %q, must be a JSON object: %w", t.Name, err)
+		}
+	}
+	if len(t.Steps) == 0 {
+		return fmt.Errorf("composite tool %q has no step", t.Name)
+	}
+	// completed are the steps of the earlier stages, which the arguments may reference
+	completed := make(map[string]bool, len(t.Steps))
+	var stage []string
+	for i, step := range t.Steps {
+		if !compositeNamePattern.MatchString(step.ID) {
+			return fmt.Errorf("invalid step id %q, must be 1 to 64 letters, digits, '_' or '-'", step.ID)
+		}
+		if completed[step.ID] || slices.Contains(stage, step.ID) {
+			return fmt.Errorf("duplicate step id %q", step.ID)
+		}
+		if step.Proxy == "" || step.Tool == "" {
+			return fmt.Errorf("step %q: proxy and tool are required", step.ID)
+		}
+		if step.Proxy == CompositeProxy {
+			return fmt.Errorf("step %q: a composite tool cannot call a composite tool", step.ID)
+		}
+		if i > 0 && !step.Parallel {
+			for _, id := range stage {
+				completed[id] = true
+			}
+			stage = nil
+		}
+		stage = append(stage, step.ID)
+		for _, reference := range compositeReferences(step.Arguments) {
+			if err := validateCompositeReference(reference, completed); err != nil {
+				return fmt.Errorf("step %q: %w", step.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Stages returns the steps grouped by stage, the steps of a stage running concurrently.
+func (t *CompositeToolConfig) Stages() [][]CompositeStep {
+	var stages [][]CompositeStep
+	for i, step := range t.Steps {
+		if i == 0 || !step.Parallel {
+			stages = append(stages, nil)
+		}
+		stages[len(stages)-1] = append(stages[len(stages)-1], step)
+	}
+	return stages
+}
+
+// ResolveArguments returns the arguments of the step with their references replaced by their values in scope,
+// which maps args to the arguments of the composite tool and steps to the results of the earlier steps by id.
+func (s *CompositeStep) ResolveArguments(scope map[string]any) (map[string]any, error) {
+	resolved, err := resolveCompositeValue(s.Arguments, scope)
+	if err != nil {
+		return nil, err
+	}
+	arguments, _ := resolved.(map[string]any)
+	return arguments, nil
+}
+
+func resolveCompositeValue(value any, scope map[string]any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if match := compositeReferencePattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			return lookupCompositeReference(match[1], scope)
+		}
+		var err error
+		text := compositeReferencePattern.ReplaceAllStringFunc(v, func(reference string) string {
+			referenced, lookupErr := lookupCompositeReference(reference[2:len(reference)-1], scope)
+			if lookupErr != nil {
+				err = lookupErr
+				return ""
+			}
+			if s, ok := referenced.(string); ok {
+				return s
+			}
+			encoded, _ := json.Marshal(referenced)
+			return string(encoded)
+		})
+		return text, err
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, item := range v {
+			r, err := resolveCompositeValue(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+	case []any:
+		resolved := make([]any, 0, len(v))
+		for _, item := range v {
+			r, err := resolveCompositeValue(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			resolved = append(resolved, r)
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
+
+// lookupCompositeReference returns the value of a reference in scope, following its fields and indexes.
+func lookupCompositeReference(reference string, scope map[string]any) (any, error) {
+	var value any = scope
+	for _, segment := range strings.Split(reference, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			item, ok := v[segment]
+			if !ok {
+				return nil, fmt.Errorf("reference ${%s} not found", reference)
+			}
+			value = item
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("reference ${%s} not found", reference)
+			}
+			value = v[index]
+		default:
+			return nil, fmt.Errorf("reference ${%s} not found", reference)
+		}
+	}
+	return value, nil
+}
+
+// compositeReferences returns the references of the string values of the arguments, without ${}.
+func compositeReferences(value any) []string {
+	var references []string
+	switch v := value.(type) {
+	case string:
+		for _, match := range compositeReferencePattern.FindAllStringSubmatch(v, -1) {
+			references = append(references, match[1])
+		}
+	case map[string]any:
+		for _, item := range v {
+			references = append(references, compositeReferences(item)...)
+		}
+	case []any:
+		for _, item := range v {
+			references = append(references, compositeReferences(item)...)
+		}
+	}
+	return references
+}
+
+// validateCompositeReference checks that a reference is to an argument or to a completed step.
+func validateCompositeReference(reference string, completed map[string]bool) error {
+	segments := strings.Split(reference, ".")
+	switch {
+	case segments[0] == "args" && len(segments) > 1:
+		return nil
+	case segments[0] == "steps" && len(segments) > 1:
+		if !completed[segments[1]] {
+			return fmt.Errorf("reference ${%s} is not to an earlier step", reference)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid reference ${%s}, must be ${args.<name>} or ${steps.<id>}", reference)
+	}
+}
+
+type CompositeToolInterface interface {
+	ListCompositeTools(ctx context.Context) ([]CompositeToolConfig, error)
+	SetCompositeTool(ctx context.Context, tool CompositeToolConfig) error
+	DeleteCompositeTool(ctx context.Context, name string) error
+}
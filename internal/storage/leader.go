@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ProxySyncLeadership is the leadership of the proxy sync loop, held by a single replica of the gateways sharing
+// the storage.
+const ProxySyncLeadership = "proxy-sync"
+
+// ProxyTools are the tools and resource templates of a proxy, as listed from its upstream server by the leader of
+// the proxy sync loop and shared with the other replicas. Both are the JSON encoded MCP definitions.
+type ProxyTools struct {
+	Proxy             string          `json:"proxy"`
+	Tools             json.RawMessage `json:"tools"`
+	ResourceTemplates json.RawMessage `json:"resourceTemplates,omitempty"`
+	SyncedAt          time.Time       `json:"syncedAt"`
+}
+
+type LeaderInterface interface {
+	// TryLeadership acquires the leadership of name, or keeps it, and returns whether the replica holds it.
+	TryLeadership(ctx context.Context, name string) (bool, error)
+	// ReleaseLeadership releases the leadership of name, if the replica holds it.
+	ReleaseLeadership(ctx context.Context, name string) error
+	ListProxyTools(ctx context.Context) ([]ProxyTools, error)
+	SetProxyTools(ctx context.Context, tools ProxyTools) error
+}
@@ -2,7 +2,12 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 type MemoryStorage struct {
@@ -10,6 +15,38 @@ type MemoryStorage struct {
 	proxies          map[string]ProxyConfig
 	roles            map[string]RoleConfig
 	attributeToRoles map[string]AttributeToRolesConfig
+
+	usageMu    sync.Mutex
+	toolCalls  []ToolCallRecord
+	dailyUsage map[DailyUsage]*DailyUsage
+
+	traceMu sync.Mutex
+	traces  []ToolCallTrace
+
+	quotaMu    sync.Mutex
+	quotas     map[string]QuotaConfig
+	quotaUsage map[string]map[string]QuotaUsage
+
+	budgetMu    sync.Mutex
+	toolCosts   map[[2]string]ToolCostConfig
+	budgets     map[string]BudgetConfig
+	budgetUsage map[string]BudgetUsage
+
+	approvalMu   sync.Mutex
+	toolPolicies map[[2]string]ToolPolicyConfig
+	approvals    map[string]ApprovalRequest
+
+	auditMu  sync.Mutex
+	auditLog []AuditEntry
+
+	classificationMu sync.Mutex
+	classifications  map[[2]string]ClassificationConfig
+
+	proxyToolsMu sync.Mutex
+	proxyTools   map[string]ProxyTools
+
+	compositeToolsMu sync.Mutex
+	compositeTools   map[string]CompositeToolConfig
 }
 
 func NewMemoryStorage(defaultScope string) *MemoryStorage {
@@ -20,6 +57,17 @@ func NewMemoryStorage(defaultScope string) *MemoryStorage {
 		proxies:          make(map[string]ProxyConfig),
 		roles:            make(map[string]RoleConfig),
 		attributeToRoles: make(map[string]AttributeToRolesConfig),
+		dailyUsage:       make(map[DailyUsage]*DailyUsage),
+		quotas:           make(map[string]QuotaConfig),
+		quotaUsage:       make(map[string]map[string]QuotaUsage),
+		toolCosts:        make(map[[2]string]ToolCostConfig),
+		budgets:          make(map[string]BudgetConfig),
+		budgetUsage:      make(map[string]BudgetUsage),
+		toolPolicies:     make(map[[2]string]ToolPolicyConfig),
+		approvals:        make(map[string]ApprovalRequest),
+		classifications:  make(map[[2]string]ClassificationConfig),
+		proxyTools:       make(map[string]ProxyTools),
+		compositeTools:   make(map[string]CompositeToolConfig),
 	}
 }
 
@@ -34,11 +82,32 @@ func (s *MemoryStorage) GetProxy(_ context.Context, proxy string, _ bool) (Proxy
 
 // SetProxy sets a proxy in the memory storage.
 func (s *MemoryStorage) SetProxy(_ context.Context, proxy *ProxyConfig, _ bool) error {
+	if err := validateProxyName(proxy); err != nil {
+		return err
+	}
 	if !proxy.Type.IsValid() {
 		return fmt.Errorf("invalid proxy type: %s", proxy.Type)
 	}
-	if !proxy.AuthType.IsValid() {
-		return fmt.Errorf("invalid proxy auth type: %s", proxy.AuthType)
+	if err := validateAuth(proxy); err != nil {
+		return err
+	}
+	if err := validateSecretReferences(proxy); err != nil {
+		return err
+	}
+	if err := validateLabels(proxy.Labels); err != nil {
+		return err
+	}
+	if err := validateDocsURL(proxy); err != nil {
+		return err
+	}
+	if err := validateEgressProxy(proxy); err != nil {
+		return err
+	}
+	if err := validateResponseSize(proxy); err != nil {
+		return err
+	}
+	if err := validateKeepalive(proxy); err != nil {
+		return err
 	}
 
 	s.proxies[proxy.Name] = *proxy
@@ -62,6 +131,12 @@ func (s *MemoryStorage) ListProxies(_ context.Context, _ bool) ([]ProxyConfig, e
 
 // SetRole sets a role in the memory storage.
 func (s *MemoryStorage) SetRole(_ context.Context, role RoleConfig) error {
+	if err := validateClearance(&role); err != nil {
+		return err
+	}
+	if err := validateProxySelectors(&role); err != nil {
+		return err
+	}
 	for _, permission := range role.Permissions {
 		if !permission.ObjectType.IsValid() {
 			return fmt.Errorf("invalid object type: %s", permission.ObjectType)
@@ -74,14 +149,14 @@ func (s *MemoryStorage) SetRole(_ context.Context, role RoleConfig) error {
 	}
 
 	for _, permission := range role.Permissions {
-		if permission.Proxy == "*" {
+		if permission.Proxy == "*" || strings.HasPrefix(permission.Proxy, ProxyLabelPrefix) {
 			continue
 		}
 		_, ok := s.proxies[permission.Proxy]
 		if !ok {
 			return fmt.Errorf("proxy %s not found", permission.Proxy)
 		}
-		if permission.ObjectType != ObjectTypeAll && permission.ObjectType != ObjectTypeTools {
+		if !permission.ObjectType.IsValid() {
 			return fmt.Errorf("invalid object type")
 		}
 	}
@@ -99,6 +174,20 @@ func (s *MemoryStorage) GetRole(_ context.Context, role string) (RoleConfig, err
 	return roleConfig, nil
 }
 
+// GetRoles gets the roles of the names from the memory storage.
+func (s *MemoryStorage) GetRoles(_ context.Context, names []string) ([]RoleConfig, error) {
+	roles := []RoleConfig{}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if role, ok := s.roles[name]; ok && !seen[name] {
+			seen[name] = true
+			roles = append(roles, role)
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
 // DeleteRole deletes a role from the memory storage.
 func (s *MemoryStorage) DeleteRole(_ context.Context, role string) error {
 	delete(s.roles, role)
@@ -154,3 +243,548 @@ func (s *MemoryStorage) GetAttributeToRoles(_ context.Context, attributeKey, att
 	}
 	return attributeToRoles, nil
 }
+
+// GetAttributeToRolesForClaims gets the mappings of the attribute pairs from the memory storage.
+func (s *MemoryStorage) GetAttributeToRolesForClaims(_ context.Context, pairs []AttributePair) ([]AttributeToRolesConfig, error) {
+	mappings := []AttributeToRolesConfig{}
+	seen := make(map[AttributePair]bool, len(pairs))
+	for _, pair := range pairs {
+		mapping, ok := s.attributeToRoles[fmt.Sprintf("%s:%s", pair.Key, pair.Value)]
+		if ok && !seen[pair] {
+			seen[pair] = true
+			mappings = append(mappings, mapping)
+		}
+	}
+	return mappings, nil
+}
+
+// RecordToolCall records a tool call in the memory storage, and adds it to its daily rollup. Calls older than
+// UsageRetention are dropped, the rollups are kept.
+func (s *MemoryStorage) RecordToolCall(_ context.Context, record ToolCallRecord) error {
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+
+	key := DailyUsage{Day: record.CalledAt.UTC().Format(DayFormat), Identity: record.Identity, Proxy: record.Proxy, Tool: record.Tool}
+	rollup, ok := s.dailyUsage[key]
+	if !ok {
+		rollup = &DailyUsage{Day: key.Day, Identity: key.Identity, Proxy: key.Proxy, Tool: key.Tool}
+		s.dailyUsage[key] = rollup
+	}
+	rollup.Calls++
+	if record.IsError {
+		rollup.Errors++
+	}
+	rollup.DurationMs += record.Duration.Milliseconds()
+
+	cutoff := time.Now().Add(-UsageRetention)
+	kept := 0
+	for kept < len(s.toolCalls) && s.toolCalls[kept].CalledAt.Before(cutoff) {
+		kept++
+	}
+	s.toolCalls = append(s.toolCalls[kept:], record)
+	return nil
+}
+
+// GetUsageStats summarizes the tool calls recorded in the memory storage.
+func (s *MemoryStorage) GetUsageStats(_ context.Context, since time.Time, limit int) (UsageStats, error) {
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+	return summarizeUsage(s.toolCalls, since, limit), nil
+}
+
+// GetDailyUsage lists the daily rollups of the memory storage.
+func (s *MemoryStorage) GetDailyUsage(_ context.Context, from, to time.Time) ([]DailyUsage, error) {
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+
+	fromDay, toDay := from.UTC().Format(DayFormat), to.UTC().Format(DayFormat)
+	usage := []DailyUsage{}
+	for _, rollup := range s.dailyUsage {
+		if rollup.Day >= fromDay && rollup.Day <= toDay {
+			usage = append(usage, *rollup)
+		}
+	}
+	sortDailyUsage(usage)
+	return usage, nil
+}
+
+// RecordTrace records the trace of a tool call in the memory storage.
+func (s *MemoryStorage) RecordTrace(_ context.Context, trace ToolCallTrace) error {
+	s.traceMu.Lock()
+	defer s.traceMu.Unlock()
+	s.traces = append(s.traces, trace)
+	return nil
+}
+
+// GetTrace gets the trace of a tool call from the memory storage.
+func (s *MemoryStorage) GetTrace(_ context.Context, id string) (ToolCallTrace, error) {
+	s.traceMu.Lock()
+	defer s.traceMu.Unlock()
+	for _, trace := range s.traces {
+		if trace.ID == id {
+			return trace, nil
+		}
+	}
+	return ToolCallTrace{}, fmt.Errorf("trace not found")
+}
+
+// ListTraces lists the traces of the tool calls from the memory storage, the latest first.
+func (s *MemoryStorage) ListTraces(_ context.Context, filter TraceFilter) ([]ToolCallTrace, error) {
+	s.traceMu.Lock()
+	defer s.traceMu.Unlock()
+	traces := []ToolCallTrace{}
+	for i := range s.traces {
+		if filter.matches(&s.traces[i]) {
+			traces = append(traces, s.traces[i])
+		}
+	}
+	sort.SliceStable(traces, func(i, j int) bool { return traces[i].CalledAt.After(traces[j].CalledAt) })
+	if filter.Limit > 0 {
+		traces = traces[:min(filter.Limit, len(traces))]
+	}
+	return traces, nil
+}
+
+// PurgeTraces deletes the traces of the tool calls made before the given time from the memory storage.
+func (s *MemoryStorage) PurgeTraces(_ context.Context, before time.Time) (int64, error) {
+	s.traceMu.Lock()
+	defer s.traceMu.Unlock()
+	kept := s.traces[:0]
+	for _, trace := range s.traces {
+		if !trace.CalledAt.Before(before) {
+			kept = append(kept, trace)
+		}
+	}
+	purged := int64(len(s.traces) - len(kept))
+	s.traces = kept
+	return purged, nil
+}
+
+// ListQuotas lists all quotas from the memory storage.
+func (s *MemoryStorage) ListQuotas(_ context.Context) ([]QuotaConfig, error) {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	quotas := make([]QuotaConfig, 0, len(s.quotas))
+	for _, quota := range s.quotas {
+		quotas = append(quotas, quota)
+	}
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].Name < quotas[j].Name })
+	return quotas, nil
+}
+
+// SetQuota creates or updates a quota in the memory storage. The usage is kept.
+func (s *MemoryStorage) SetQuota(_ context.Context, quota QuotaConfig) error {
+	if err := quota.Validate(); err != nil {
+		return err
+	}
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	s.quotas[quota.Name] = quota
+	return nil
+}
+
+// GetQuota gets a quota from the memory storage.
+func (s *MemoryStorage) GetQuota(_ context.Context, name string) (QuotaConfig, error) {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	quota, ok := s.quotas[name]
+	if !ok {
+		return QuotaConfig{}, fmt.Errorf("quota not found")
+	}
+	return quota, nil
+}
+
+// DeleteQuota deletes a quota and its usage from the memory storage.
+func (s *MemoryStorage) DeleteQuota(_ context.Context, name string) error {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	delete(s.quotas, name)
+	delete(s.quotaUsage, name)
+	return nil
+}
+
+// ConsumeQuota counts a call against a quota in the memory storage.
+func (s *MemoryStorage) ConsumeQuota(_ context.Context, quota QuotaConfig, subject string, now time.Time) (QuotaUsage, bool, error) {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	windowStart := quota.Window.Start(now)
+	usage, ok := s.quotaUsage[quota.Name][subject]
+	if !ok || usage.WindowStart.Before(windowStart) {
+		usage = newQuotaUsage(quota, subject, windowStart, 0)
+	}
+	usage.Limit = quota.Limit
+	if usage.Calls >= quota.Limit {
+		return usage, false, nil
+	}
+	usage.Calls++
+	if s.quotaUsage[quota.Name] == nil {
+		s.quotaUsage[quota.Name] = make(map[string]QuotaUsage)
+	}
+	s.quotaUsage[quota.Name][subject] = usage
+	return usage, true, nil
+}
+
+// ListQuotaUsage lists the usage of a quota in its current window from the memory storage.
+func (s *MemoryStorage) ListQuotaUsage(_ context.Context, quota QuotaConfig, now time.Time) ([]QuotaUsage, error) {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	windowStart := quota.Window.Start(now)
+	usages := []QuotaUsage{}
+	for subject, usage := range s.quotaUsage[quota.Name] {
+		if !usage.WindowStart.Equal(windowStart) {
+			continue
+		}
+		usages = append(usages, newQuotaUsage(quota, subject, usage.WindowStart, usage.Calls))
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Subject < usages[j].Subject })
+	return usages, nil
+}
+
+// ResetQuotaUsage resets the usage of a quota in the memory storage.
+func (s *MemoryStorage) ResetQuotaUsage(_ context.Context, quota, subject string) error {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	if subject == "" {
+		delete(s.quotaUsage, quota)
+		return nil
+	}
+	delete(s.quotaUsage[quota], subject)
+	return nil
+}
+
+// ListToolCosts lists all tool costs from the memory storage.
+func (s *MemoryStorage) ListToolCosts(_ context.Context) ([]ToolCostConfig, error) {
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+	costs := make([]ToolCostConfig, 0, len(s.toolCosts))
+	for _, cost := range s.toolCosts {
+		costs = append(costs, cost)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].Proxy != costs[j].Proxy {
+			return costs[i].Proxy < costs[j].Proxy
+		}
+		return costs[i].Tool < costs[j].Tool
+	})
+	return costs, nil
+}
+
+// SetToolCost creates or updates a tool cost in the memory storage.
+func (s *MemoryStorage) SetToolCost(_ context.Context, cost ToolCostConfig) error {
+	if err := cost.Validate(); err != nil {
+		return err
+	}
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+	s.toolCosts[[2]string{cost.Proxy, cost.Tool}] = cost
+	return nil
+}
+
+// DeleteToolCost deletes a tool cost from the memory storage.
+func (s *MemoryStorage) DeleteToolCost(_ context.Context, proxy, tool string) error {
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+	delete(s.toolCosts, [2]string{proxy, tool})
+	return nil
+}
+
+// ListBudgets lists all budgets from the memory storage.
+func (s *MemoryStorage) ListBudgets(_ context.Context) ([]BudgetConfig, error) {
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+	budgets := make([]BudgetConfig, 0, len(s.budgets))
+	for _, budget := range s.budgets {
+		budgets = append(budgets, budget)
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Name < budgets[j].Name })
+	return budgets, nil
+}
+
+// SetBudget creates or updates a budget in the memory storage. The usage is kept.
+func (s *MemoryStorage) SetBudget(_ context.Context, budget BudgetConfig) error {
+	if err := budget.Validate(); err != nil {
+		return err
+	}
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+	s.budgets[budget.Name] = budget
+	return nil
+}
+
+// GetBudget gets a budget from the memory storage.
+func (s *MemoryStorage) GetBudget(_ context.Context, name string) (BudgetConfig, error) {
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+	budget, ok := s.budgets[name]
+	if !ok {
+		return BudgetConfig{}, fmt.Errorf("budget not found")
+	}
+	return budget, nil
+}
+
+// DeleteBudget deletes a budget and its usage from the memory storage.
+func (s *MemoryStorage) DeleteBudget(_ context.Context, name string) error {
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+	delete(s.budgets, name)
+	delete(s.budgetUsage, name)
+	return nil
+}
+
+// ConsumeBudget counts the cost of a call against a budget in the memory storage.
+func (s *MemoryStorage) ConsumeBudget(_ context.Context, budget BudgetConfig, cost int64, now time.Time) (BudgetUsage, bool, error) {
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+
+	windowStart := budget.Window.Start(now)
+	consumed := int64(0)
+	if usage, ok := s.budgetUsage[budget.Name]; ok && usage.WindowStart.Equal(windowStart) {
+		consumed = usage.Consumed
+	}
+	covered := consumed+cost <= budget.Limit
+	if covered || budget.Action == BudgetActionWarn {
+		consumed += cost
+	}
+	usage := newBudgetUsage(budget, windowStart, consumed)
+	s.budgetUsage[budget.Name] = usage
+	return usage, covered, nil
+}
+
+// GetBudgetUsage returns the usage of a budget from the memory storage.
+func (s *MemoryStorage) GetBudgetUsage(_ context.Context, budget BudgetConfig, now time.Time) (BudgetUsage, error) {
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+
+	windowStart := budget.Window.Start(now)
+	usage, ok := s.budgetUsage[budget.Name]
+	if !ok || !usage.WindowStart.Equal(windowStart) {
+		return newBudgetUsage(budget, windowStart, 0), nil
+	}
+	return newBudgetUsage(budget, windowStart, usage.Consumed), nil
+}
+
+// ResetBudgetUsage resets the usage of a budget in the memory storage.
+func (s *MemoryStorage) ResetBudgetUsage(_ context.Context, name string) error {
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+	delete(s.budgetUsage, name)
+	return nil
+}
+
+// ListToolPolicies lists all tool policies from the memory storage.
+func (s *MemoryStorage) ListToolPolicies(_ context.Context) ([]ToolPolicyConfig, error) {
+	s.approvalMu.Lock()
+	defer s.approvalMu.Unlock()
+	policies := make([]ToolPolicyConfig, 0, len(s.toolPolicies))
+	for _, policy := range s.toolPolicies {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].Proxy != policies[j].Proxy {
+			return policies[i].Proxy < policies[j].Proxy
+		}
+		return policies[i].Tool < policies[j].Tool
+	})
+	return policies, nil
+}
+
+// SetToolPolicy creates or updates a tool policy in the memory storage.
+func (s *MemoryStorage) SetToolPolicy(_ context.Context, policy ToolPolicyConfig) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	s.approvalMu.Lock()
+	defer s.approvalMu.Unlock()
+	s.toolPolicies[[2]string{policy.Proxy, policy.Tool}] = policy
+	return nil
+}
+
+// DeleteToolPolicy deletes a tool policy from the memory storage.
+func (s *MemoryStorage) DeleteToolPolicy(_ context.Context, proxy, tool string) error {
+	s.approvalMu.Lock()
+	defer s.approvalMu.Unlock()
+	delete(s.toolPolicies, [2]string{proxy, tool})
+	return nil
+}
+
+// CreateApproval stores a pending approval in the memory storage.
+func (s *MemoryStorage) CreateApproval(_ context.Context, approval ApprovalRequest) error {
+	s.approvalMu.Lock()
+	defer s.approvalMu.Unlock()
+	if _, ok := s.approvals[approval.ID]; ok {
+		return fmt.Errorf("approval already exists")
+	}
+	s.approvals[approval.ID] = approval
+	return nil
+}
+
+// GetApproval gets an approval from the memory storage.
+func (s *MemoryStorage) GetApproval(_ context.Context, id string) (ApprovalRequest, error) {
+	s.approvalMu.Lock()
+	defer s.approvalMu.Unlock()
+	approval, ok := s.approvals[id]
+	if !ok {
+		return ApprovalRequest{}, fmt.Errorf("approval not found")
+	}
+	return approval, nil
+}
+
+// ListApprovals lists the approvals from the memory storage, the latest first.
+func (s *MemoryStorage) ListApprovals(_ context.Context, status ApprovalStatus) ([]ApprovalRequest, error) {
+	s.approvalMu.Lock()
+	defer s.approvalMu.Unlock()
+	approvals := make([]ApprovalRequest, 0)
+	for _, approval := range s.approvals {
+		if status == "" || approval.Status == status {
+			approvals = append(approvals, approval)
+		}
+	}
+	sort.Slice(approvals, func(i, j int) bool { return approvals[i].RequestedAt.After(approvals[j].RequestedAt) })
+	return approvals, nil
+}
+
+// DecideApproval records the decision of a pending approval in the memory storage.
+func (s *MemoryStorage) DecideApproval(_ context.Context, id string, status ApprovalStatus, decidedBy, reason string, now time.Time) (ApprovalRequest, error) {
+	if err := validateDecision(status); err != nil {
+		return ApprovalRequest{}, err
+	}
+	s.approvalMu.Lock()
+	defer s.approvalMu.Unlock()
+	approval, ok := s.approvals[id]
+	if !ok {
+		return ApprovalRequest{}, fmt.Errorf("approval not found")
+	}
+	if approval.Status != ApprovalStatusPending {
+		return approval, ErrApprovalDecided
+	}
+	approval.Status = status
+	approval.DecidedAt = &now
+	approval.DecidedBy = decidedBy
+	approval.Reason = reason
+	s.approvals[id] = approval
+	return approval, nil
+}
+
+// AppendAuditEntry chains a record to the end of the audit log of the memory storage.
+func (s *MemoryStorage) AppendAuditEntry(_ context.Context, entryType string, data json.RawMessage, recordedAt time.Time) (AuditEntry, error) {
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+	var last *AuditEntry
+	if len(s.auditLog) > 0 {
+		last = &s.auditLog[len(s.auditLog)-1]
+	}
+	entry := newAuditEntry(last, entryType, data, recordedAt)
+	s.auditLog = append(s.auditLog, entry)
+	return entry, nil
+}
+
+// ListAuditEntries lists the audit entries following a sequence number from the memory storage.
+func (s *MemoryStorage) ListAuditEntries(_ context.Context, after int64, limit int) ([]AuditEntry, error) {
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+	// The sequence numbers of the memory log start at 1 with no gap
+	start := min(max(after, 0), int64(len(s.auditLog)))
+	end := min(start+int64(limit), int64(len(s.auditLog)))
+	return append([]AuditEntry{}, s.auditLog[start:end]...), nil
+}
+
+// ListClassifications lists all classification labels from the memory storage.
+func (s *MemoryStorage) ListClassifications(_ context.Context) ([]ClassificationConfig, error) {
+	s.classificationMu.Lock()
+	defer s.classificationMu.Unlock()
+	classifications := make([]ClassificationConfig, 0, len(s.classifications))
+	for _, classification := range s.classifications {
+		classifications = append(classifications, classification)
+	}
+	sort.Slice(classifications, func(i, j int) bool {
+		if classifications[i].Proxy != classifications[j].Proxy {
+			return classifications[i].Proxy < classifications[j].Proxy
+		}
+		return classifications[i].Tool < classifications[j].Tool
+	})
+	return classifications, nil
+}
+
+// SetClassification creates or updates a classification label in the memory storage.
+func (s *MemoryStorage) SetClassification(_ context.Context, classification ClassificationConfig) error {
+	if err := classification.Validate(); err != nil {
+		return err
+	}
+	s.classificationMu.Lock()
+	defer s.classificationMu.Unlock()
+	s.classifications[[2]string{classification.Proxy, classification.Tool}] = classification
+	return nil
+}
+
+// DeleteClassification deletes a classification label from the memory storage.
+func (s *MemoryStorage) DeleteClassification(_ context.Context, proxy, tool string) error {
+	s.classificationMu.Lock()
+	defer s.classificationMu.Unlock()
+	delete(s.classifications, [2]string{proxy, tool})
+	return nil
+}
+
+// TryLeadership always grants the leadership: the memory storage is not shared, so the replica is alone.
+func (s *MemoryStorage) TryLeadership(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+
+// ReleaseLeadership does nothing, the leadership of the memory storage is never contended.
+func (s *MemoryStorage) ReleaseLeadership(_ context.Context, _ string) error {
+	return nil
+}
+
+// ListProxyTools lists the tools of the proxies from the memory storage.
+func (s *MemoryStorage) ListProxyTools(_ context.Context) ([]ProxyTools, error) {
+	s.proxyToolsMu.Lock()
+	defer s.proxyToolsMu.Unlock()
+	tools := make([]ProxyTools, 0, len(s.proxyTools))
+	for _, t := range s.proxyTools {
+		tools = append(tools, t)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Proxy < tools[j].Proxy })
+	return tools, nil
+}
+
+// SetProxyTools replaces the tools of a proxy in the memory storage.
+func (s *MemoryStorage) SetProxyTools(_ context.Context, tools ProxyTools) error {
+	s.proxyToolsMu.Lock()
+	defer s.proxyToolsMu.Unlock()
+	s.proxyTools[tools.Proxy] = tools
+	return nil
+}
+
+// ListCompositeTools lists all composite tools from the memory storage.
+func (s *MemoryStorage) ListCompositeTools(_ context.Context) ([]CompositeToolConfig, error) {
+	s.compositeToolsMu.Lock()
+	defer s.compositeToolsMu.Unlock()
+	tools := make([]CompositeToolConfig, 0, len(s.compositeTools))
+	for _, tool := range s.compositeTools {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+	return tools, nil
+}
+
+// SetCompositeTool creates or updates a composite tool in the memory storage.
+func (s *MemoryStorage) SetCompositeTool(_ context.Context, tool CompositeToolConfig) error {
+	if err := tool.Validate(); err != nil {
+		return err
+	}
+	s.compositeToolsMu.Lock()
+	defer s.compositeToolsMu.Unlock()
+	s.compositeTools[tool.Name] = tool
+	return nil
+}
+
+// DeleteCompositeTool deletes a composite tool from the memory storage.
+func (s *MemoryStorage) DeleteCompositeTool(_ context.Context, name string) error {
+	s.compositeToolsMu.Lock()
+	defer s.compositeToolsMu.Unlock()
+	delete(s.compositeTools, name)
+	return nil
+}
@@ -2,9 +2,12 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMemoryProxyStorage(t *testing.T) {
@@ -24,6 +27,118 @@ func TestMemoryProxyStorage(t *testing.T) {
 	assert.Equal(t, proxy.Name, "")
 }
 
+func TestMemoryProxyStorageSecretReferences(t *testing.T) {
+	storage := NewMemoryStorage("")
+	proxy := ProxyConfig{Name: "test", Type: ProxyTypeStreamableHTTP, AuthType: ProxyAuthTypeHeader, Headers: []ProxyHeader{
+		{Key: "Authorization", Value: "vault:kv/data/mcp#token"},
+	}}
+	assert.NoError(t, storage.SetProxy(context.Background(), &proxy, false))
+
+	proxy.Headers[0].Value = "vault:kv/data/mcp"
+	assert.ErrorContains(t, storage.SetProxy(context.Background(), &proxy, false), "header Authorization: invalid vault reference")
+}
+
+func TestMemoryProxyStorageLabels(t *testing.T) {
+	storage := NewMemoryStorage("")
+	proxy := ProxyConfig{Name: "billing", Type: ProxyTypeStreamableHTTP, AuthType: ProxyAuthTypeHeader,
+		Labels: map[string]string{"team": "payments", "env": "staging"}}
+	assert.NoError(t, storage.SetProxy(context.Background(), &proxy, false))
+	stored, err := storage.GetProxy(context.Background(), "billing", false)
+	assert.NoError(t, err)
+	assert.Equal(t, proxy.Labels, stored.Labels)
+
+	proxy.Labels = map[string]string{"team": "payments,billing"}
+	assert.ErrorContains(t, storage.SetProxy(context.Background(), &proxy, false), `invalid label value "payments,billing"`)
+	proxy.Labels = map[string]string{"": "payments"}
+	assert.ErrorContains(t, storage.SetProxy(context.Background(), &proxy, false), `invalid label key ""`)
+
+	role := RoleConfig{Name: "staging", Permissions: []PermissionConfig{
+		{ObjectType: ObjectTypeTools, Proxy: ProxyLabelPrefix + "env=staging", ObjectName: "*"},
+	}}
+	assert.NoError(t, storage.SetRole(context.Background(), role))
+	role.Name, role.Permissions[0].Proxy = "invalid", ProxyLabelPrefix+"env"
+	assert.ErrorContains(t, storage.SetRole(context.Background(), role), "invalid label selector")
+}
+
+func TestMemoryProxyStorageMetadata(t *testing.T) {
+	storage := NewMemoryStorage("")
+	proxy := ProxyConfig{Name: "github", Type: ProxyTypeStreamableHTTP, AuthType: ProxyAuthTypeHeader,
+		Description: "GitHub repositories and issues", Owner: "platform@example.com", DocsURL: "https://wiki.example.com/github-mcp"}
+	assert.NoError(t, storage.SetProxy(context.Background(), &proxy, false))
+	stored, err := storage.GetProxy(context.Background(), "github", false)
+	assert.NoError(t, err)
+	assert.Equal(t, proxy, stored)
+
+	proxy.DocsURL = "wiki.example.com/github-mcp"
+	assert.ErrorContains(t, storage.SetProxy(context.Background(), &proxy, false), "invalid docs URL")
+}
+
+func TestMemoryProxyStorageEgressProxy(t *testing.T) {
+	storage := NewMemoryStorage("")
+	proxy := ProxyConfig{Name: "github", Type: ProxyTypeStreamableHTTP, AuthType: ProxyAuthTypeHeader,
+		EgressProxy: "socks5://egress.internal:1080"}
+	assert.NoError(t, storage.SetProxy(context.Background(), &proxy, false))
+	proxy.EgressProxy = "direct"
+	assert.NoError(t, storage.SetProxy(context.Background(), &proxy, false))
+
+	proxy.EgressProxy = "egress.internal:3128"
+	assert.ErrorContains(t, storage.SetProxy(context.Background(), &proxy, false), "egress proxy URL")
+}
+
+func TestMemoryProxyStorageResponseSize(t *testing.T) {
+	storage := NewMemoryStorage("")
+	proxy := ProxyConfig{Name: "github", Type: ProxyTypeStreamableHTTP, AuthType: ProxyAuthTypeHeader,
+		MaxResponseBytes: 1 << 20, ResponseSizePolicy: "reject"}
+	assert.NoError(t, storage.SetProxy(context.Background(), &proxy, false))
+
+	proxy.ResponseSizePolicy = "drop"
+	assert.ErrorContains(t, storage.SetProxy(context.Background(), &proxy, false), "invalid response size policy")
+	proxy.ResponseSizePolicy = ""
+	proxy.MaxResponseBytes = -1
+	assert.ErrorContains(t, storage.SetProxy(context.Background(), &proxy, false), "invalid max response bytes")
+}
+
+func TestMemoryProxyStorageKeepalive(t *testing.T) {
+	storage := NewMemoryStorage("")
+	proxy := ProxyConfig{Name: "github", Type: ProxyTypeStreamableHTTP, AuthType: ProxyAuthTypeHeader,
+		KeepaliveInterval: 30 * time.Second}
+	assert.NoError(t, storage.SetProxy(context.Background(), &proxy, false))
+
+	proxy.KeepaliveInterval = -time.Second
+	assert.ErrorContains(t, storage.SetProxy(context.Background(), &proxy, false), "invalid keepalive interval")
+}
+
+func TestLabelSelector(t *testing.T) {
+	selector, err := ParseLabelSelector("team=payments, env=staging")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments", "env": "staging"}, selector)
+	assert.True(t, MatchLabels(map[string]string{"team": "payments", "env": "staging", "tier": "1"}, selector))
+	assert.False(t, MatchLabels(map[string]string{"team": "payments", "env": "production"}, selector))
+	assert.False(t, MatchLabels(map[string]string{"team": "payments"}, selector))
+	assert.False(t, MatchLabels(nil, selector))
+
+	selector, err = ParseLabelSelector("env=")
+	require.NoError(t, err)
+	assert.True(t, MatchLabels(map[string]string{"env": ""}, selector))
+
+	for _, invalid := range []string{"", "team", "team=payments,", "=payments", "team=pay=ments"} {
+		_, err := ParseLabelSelector(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMemoryProxyStorageHMAC(t *testing.T) {
+	storage := NewMemoryStorage("")
+	proxy := ProxyConfig{Name: "test", Type: ProxyTypeStreamableHTTP, AuthType: ProxyAuthTypeHMAC}
+	assert.ErrorContains(t, storage.SetProxy(context.Background(), &proxy, false), "the hmac auth type requires a secret")
+
+	proxy.HMAC = &ProxyHMAC{Secret: "vault:kv/data/mcp"}
+	assert.ErrorContains(t, storage.SetProxy(context.Background(), &proxy, false), "hmac secret: invalid vault reference")
+
+	proxy.HMAC = &ProxyHMAC{Secret: "vault:kv/data/mcp#hmac"}
+	assert.NoError(t, storage.SetProxy(context.Background(), &proxy, false))
+}
+
 func TestMemoryStorageRoles(t *testing.T) {
 	storage := NewMemoryStorage("")
 	role := RoleConfig{Name: "admin", Permissions: []PermissionConfig{
@@ -55,6 +170,9 @@ func TestMemoryStorageRoles(t *testing.T) {
 	roles, err := storage.ListRoles(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, roles, []RoleConfig{role})
+	roles, err = storage.GetRoles(context.Background(), []string{"unknown", "admin", "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, []RoleConfig{role}, roles)
 	err = storage.DeleteRole(context.Background(), role.Name)
 	assert.NoError(t, err)
 	roles, err = storage.ListRoles(context.Background())
@@ -83,6 +201,443 @@ func TestMemoryStorageClaimToRoles(t *testing.T) {
 	attributeToRolesList, err := storage.ListAttributeToRoles(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, attributeToRolesList, []AttributeToRolesConfig{attributeToRoles})
+	attributeToRolesList, err = storage.GetAttributeToRolesForClaims(context.Background(), []AttributePair{
+		{Key: "email", Value: "test@test.com"}, {Key: "groups", Value: "dev"}, {Key: "email", Value: "test@test.com"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []AttributeToRolesConfig{attributeToRoles}, attributeToRolesList)
 	err = storage.DeleteAttributeToRoles(context.Background(), attributeToRoles.AttributeKey, attributeToRoles.AttributeValue)
 	assert.NoError(t, err)
 }
+
+func TestMemoryStorageUsageStats(t *testing.T) {
+	storage := NewMemoryStorage("")
+	now := time.Now()
+	for _, record := range []ToolCallRecord{
+		{Proxy: "github", Tool: "search", Identity: "alice", CalledAt: now},
+		{Proxy: "github", Tool: "search", Identity: "bob", IsError: true, CalledAt: now},
+		{Proxy: "github", Tool: "search", Identity: "alice", CalledAt: now},
+		{Proxy: "n8n", Tool: "run", Identity: "alice", CalledAt: now},
+		{Proxy: "n8n", Tool: "run", Identity: "bob", CalledAt: now.Add(-2 * time.Hour)},
+	} {
+		assert.NoError(t, storage.RecordToolCall(context.Background(), record))
+	}
+
+	stats, err := storage.GetUsageStats(context.Background(), now.Add(-time.Hour), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), stats.TotalCalls)
+	assert.Equal(t, int64(1), stats.ErrorCalls)
+	assert.Equal(t, 0.25, stats.ErrorRate)
+	assert.Equal(t, []ToolUsage{{Proxy: "github", Tool: "search", Calls: 3, Errors: 1, ErrorRate: float64(1) / 3}}, stats.TopTools)
+	assert.Equal(t, []IdentityUsage{{Identity: "alice", Calls: 3}}, stats.TopIdentities)
+
+	stats, err = storage.GetUsageStats(context.Background(), now.Add(-24*time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), stats.TotalCalls)
+	assert.Len(t, stats.TopTools, 2)
+	assert.Len(t, stats.TopIdentities, 2)
+}
+
+func TestMemoryStorageDailyUsage(t *testing.T) {
+	storage := NewMemoryStorage("")
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, record := range []ToolCallRecord{
+		{Proxy: "github", Tool: "search", Identity: "bob", CalledAt: day.Add(time.Hour), Duration: 300 * time.Millisecond},
+		{Proxy: "github", Tool: "search", Identity: "alice", CalledAt: day.Add(2 * time.Hour), Duration: time.Second, IsError: true},
+		{Proxy: "github", Tool: "search", Identity: "alice", CalledAt: day.Add(3 * time.Hour), Duration: 500 * time.Millisecond},
+		{Proxy: "github", Tool: "search", Identity: "alice", CalledAt: day.Add(25 * time.Hour)},
+	} {
+		assert.NoError(t, storage.RecordToolCall(context.Background(), record))
+	}
+
+	usage, err := storage.GetDailyUsage(context.Background(), day, day)
+	assert.NoError(t, err)
+	assert.Equal(t, []DailyUsage{
+		{Day: "2025-06-01", Identity: "alice", Proxy: "github", Tool: "search", Calls: 2, Errors: 1, DurationMs: 1500},
+		{Day: "2025-06-01", Identity: "bob", Proxy: "github", Tool: "search", Calls: 1, DurationMs: 300},
+	}, usage)
+
+	usage, err = storage.GetDailyUsage(context.Background(), day, day.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+	assert.Len(t, usage, 3)
+}
+
+func TestMemoryStorageTraces(t *testing.T) {
+	storage := NewMemoryStorage("")
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	for i, trace := range []ToolCallTrace{
+		{ID: "first", Proxy: "github", Tool: "search", Identity: "alice", CalledAt: now, Duration: time.Second,
+			AuthDuration: time.Millisecond, UpstreamDuration: 900 * time.Millisecond, Arguments: json.RawMessage(`{"query":"mcp"}`)},
+		{ID: "second", Proxy: "github", Tool: "search", Identity: "bob", CalledAt: now.Add(time.Hour), IsError: true},
+		{ID: "third", Proxy: "jira", Tool: "get_issue", Identity: "alice", CalledAt: now.Add(2 * time.Hour), ResultSize: 42},
+	} {
+		assert.NoError(t, storage.RecordTrace(ctx, trace), i)
+	}
+
+	trace, err := storage.GetTrace(ctx, "first")
+	assert.NoError(t, err)
+	assert.Equal(t, 900*time.Millisecond, trace.UpstreamDuration)
+	_, err = storage.GetTrace(ctx, "missing")
+	assert.Error(t, err)
+
+	for _, test := range []struct {
+		filter   TraceFilter
+		expected []string
+	}{
+		{filter: TraceFilter{}, expected: []string{"third", "second", "first"}},
+		{filter: TraceFilter{Proxy: "github"}, expected: []string{"second", "first"}},
+		{filter: TraceFilter{Identity: "alice", Tool: "search"}, expected: []string{"first"}},
+		{filter: TraceFilter{ErrorsOnly: true}, expected: []string{"second"}},
+		{filter: TraceFilter{Since: now.Add(time.Hour), Until: now.Add(2 * time.Hour)}, expected: []string{"second"}},
+		{filter: TraceFilter{Limit: 1}, expected: []string{"third"}},
+	} {
+		traces, err := storage.ListTraces(ctx, test.filter)
+		assert.NoError(t, err)
+		ids := []string{}
+		for _, trace := range traces {
+			ids = append(ids, trace.ID)
+		}
+		assert.Equal(t, test.expected, ids, test.filter)
+	}
+
+	purged, err := storage.PurgeTraces(ctx, now.Add(90*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	traces, err := storage.ListTraces(ctx, TraceFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, traces, 1)
+}
+
+func TestMemoryStorageQuotas(t *testing.T) {
+	storage := NewMemoryStorage("")
+	ctx := context.Background()
+	quota := QuotaConfig{Name: "search", SubjectType: QuotaSubjectTypeRole, Subject: "reader", Proxy: "github", Tool: "*",
+		Window: QuotaWindowHour, Limit: 2}
+	assert.NoError(t, storage.SetQuota(ctx, quota))
+	assert.Error(t, storage.SetQuota(ctx, QuotaConfig{Name: "invalid", SubjectType: "team"}))
+
+	now := time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)
+	for _, expected := range []bool{true, true, false} {
+		usage, allowed, err := storage.ConsumeQuota(ctx, quota, "alice", now)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, allowed)
+		assert.Equal(t, int64(2), usage.Limit)
+		assert.Equal(t, time.Date(2025, 6, 1, 11, 0, 0, 0, time.UTC), usage.ResetsAt)
+	}
+	_, allowed, err := storage.ConsumeQuota(ctx, quota, "bob", now)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	usages, err := storage.ListQuotaUsage(ctx, quota, now)
+	assert.NoError(t, err)
+	assert.Len(t, usages, 2)
+	assert.Equal(t, int64(2), usages[0].Calls)
+
+	// The calls are counted again in the next window
+	usage, allowed, err := storage.ConsumeQuota(ctx, quota, "alice", now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, int64(1), usage.Calls)
+
+	assert.NoError(t, storage.ResetQuotaUsage(ctx, "search", "bob"))
+	usages, err = storage.ListQuotaUsage(ctx, quota, now)
+	assert.NoError(t, err)
+	assert.Empty(t, usages, "alice's usage belongs to the next window")
+
+	assert.NoError(t, storage.DeleteQuota(ctx, "search"))
+	_, err = storage.GetQuota(ctx, "search")
+	assert.Error(t, err)
+}
+
+func TestQuotaWindows(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 6, 18, 10, 30, 0, 0, time.UTC)
+	for _, test := range []struct {
+		window        QuotaWindow
+		expectedStart time.Time
+		expectedEnd   time.Time
+	}{
+		{QuotaWindowHour, time.Date(2025, 6, 18, 10, 0, 0, 0, time.UTC), time.Date(2025, 6, 18, 11, 0, 0, 0, time.UTC)},
+		{QuotaWindowDay, time.Date(2025, 6, 18, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 19, 0, 0, 0, 0, time.UTC)},
+		{QuotaWindowWeek, time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 23, 0, 0, 0, 0, time.UTC)},
+		{QuotaWindowMonth, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		start := test.window.Start(now)
+		assert.Equal(t, test.expectedStart, start, test.window)
+		assert.Equal(t, test.expectedEnd, test.window.End(start), test.window)
+	}
+	// Sunday belongs to the week started on Monday
+	assert.Equal(t, time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC), QuotaWindowWeek.Start(time.Date(2025, 6, 22, 23, 0, 0, 0, time.UTC)))
+}
+
+func TestMemoryStorageBudgets(t *testing.T) {
+	storage := NewMemoryStorage("")
+	ctx := context.Background()
+	assert.NoError(t, storage.SetToolCost(ctx, ToolCostConfig{Proxy: "github", Tool: "*", Cost: 5}))
+	assert.NoError(t, storage.SetToolCost(ctx, ToolCostConfig{Proxy: "github", Tool: "search", Cost: 2}))
+	assert.Error(t, storage.SetToolCost(ctx, ToolCostConfig{Proxy: "*", Tool: "search", Cost: 2}))
+	costs, err := storage.ListToolCosts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), ToolCost(costs, "github", "search"))
+	assert.Equal(t, int64(5), ToolCost(costs, "github", "create_issue"))
+	assert.Equal(t, int64(DefaultToolCost), ToolCost(costs, "jira", "search"))
+
+	deny := BudgetConfig{Name: "readers", Role: "reader", Window: QuotaWindowMonth, Limit: 5, Action: BudgetActionDeny}
+	warn := BudgetConfig{Name: "writers", Role: "writer", Window: QuotaWindowMonth, Limit: 5, Action: BudgetActionWarn}
+	assert.NoError(t, storage.SetBudget(ctx, deny))
+	assert.NoError(t, storage.SetBudget(ctx, warn))
+	assert.Error(t, storage.SetBudget(ctx, BudgetConfig{Name: "invalid", Role: "reader", Window: QuotaWindowDay, Action: "block"}))
+
+	now := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+	for _, budget := range []BudgetConfig{deny, warn} {
+		for _, expected := range []bool{true, true, false} {
+			_, covered, err := storage.ConsumeBudget(ctx, budget, 2, now)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, covered)
+		}
+	}
+	usage, err := storage.GetBudgetUsage(ctx, deny, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), usage.Consumed, "a deny budget is not charged what it can not cover")
+	assert.Equal(t, int64(1), usage.Remaining)
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), usage.ResetsAt)
+	usage, err = storage.GetBudgetUsage(ctx, warn, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), usage.Consumed)
+	assert.Equal(t, int64(0), usage.Remaining)
+
+	// The budget is renewed in the next window
+	usage, covered, err := storage.ConsumeBudget(ctx, deny, 2, now.AddDate(0, 1, 0))
+	assert.NoError(t, err)
+	assert.True(t, covered)
+	assert.Equal(t, int64(2), usage.Consumed)
+
+	assert.NoError(t, storage.ResetBudgetUsage(ctx, "writers"))
+	usage, err = storage.GetBudgetUsage(ctx, warn, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), usage.Consumed)
+
+	assert.NoError(t, storage.DeleteBudget(ctx, "readers"))
+	_, err = storage.GetBudget(ctx, "readers")
+	assert.Error(t, err)
+}
+
+func TestMemoryStorageApprovals(t *testing.T) {
+	storage := NewMemoryStorage("")
+	ctx := context.Background()
+	assert.NoError(t, storage.SetToolPolicy(ctx, ToolPolicyConfig{Proxy: "github", Tool: "*", RequiresApproval: true}))
+	assert.NoError(t, storage.SetToolPolicy(ctx, ToolPolicyConfig{Proxy: "github", Tool: "search", RequiresApproval: false}))
+	assert.Error(t, storage.SetToolPolicy(ctx, ToolPolicyConfig{Proxy: "github"}))
+	policies, err := storage.ListToolPolicies(ctx)
+	assert.NoError(t, err)
+	assert.True(t, RequiresApproval(policies, "github", "delete_repository"))
+	assert.False(t, RequiresApproval(policies, "github", "search"))
+	assert.False(t, RequiresApproval(policies, "jira", "search"))
+
+	now := time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)
+	for i, id := range []string{"first", "second"} {
+		assert.NoError(t, storage.CreateApproval(ctx, ApprovalRequest{ID: id, Proxy: "github", Tool: "delete_repository",
+			Identity: "alice", Status: ApprovalStatusPending, RequestedAt: now.Add(time.Duration(i) * time.Minute),
+			ExpiresAt: now.Add(time.Hour)}))
+	}
+	assert.Error(t, storage.CreateApproval(ctx, ApprovalRequest{ID: "first"}))
+
+	approval, err := storage.DecideApproval(ctx, "first", ApprovalStatusApproved, "bob", "looks good", now)
+	assert.NoError(t, err)
+	assert.Equal(t, ApprovalStatusApproved, approval.Status)
+	assert.Equal(t, "bob", approval.DecidedBy)
+	_, err = storage.DecideApproval(ctx, "first", ApprovalStatusDenied, "carol", "", now)
+	assert.ErrorIs(t, err, ErrApprovalDecided)
+	_, err = storage.DecideApproval(ctx, "second", ApprovalStatusPending, "carol", "", now)
+	assert.Error(t, err)
+	_, err = storage.DecideApproval(ctx, "missing", ApprovalStatusDenied, "carol", "", now)
+	assert.Error(t, err)
+
+	approvals, err := storage.ListApprovals(ctx, "")
+	assert.NoError(t, err)
+	require.Len(t, approvals, 2)
+	assert.Equal(t, "second", approvals[0].ID, "the latest approval is listed first")
+	approvals, err = storage.ListApprovals(ctx, ApprovalStatusPending)
+	assert.NoError(t, err)
+	require.Len(t, approvals, 1)
+	assert.Equal(t, "second", approvals[0].ID)
+}
+
+func TestMemoryStorageAuditLog(t *testing.T) {
+	storage := NewMemoryStorage("")
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 10, 30, 0, 123456789, time.UTC)
+
+	verification, err := VerifyAuditLog(ctx, storage, 2)
+	require.NoError(t, err)
+	assert.Equal(t, AuditVerification{Valid: true}, verification)
+
+	var hashes []string
+	for i := range 5 {
+		entry, err := storage.AppendAuditEntry(ctx, "tool_call", json.RawMessage(`{"proxy":"github","tool":"search"}`), now.Add(time.Duration(i)*time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), entry.Seq)
+		assert.Equal(t, now.Add(time.Duration(i)*time.Second).Truncate(time.Microsecond), entry.RecordedAt)
+		if i > 0 {
+			assert.Equal(t, hashes[i-1], entry.PrevHash)
+		}
+		hashes = append(hashes, entry.Hash)
+	}
+
+	entries, err := storage.ListAuditEntries(ctx, 3, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(4), entries[0].Seq)
+
+	// Each batch is chained to the previous one
+	verification, err = VerifyAuditLog(ctx, storage, 2)
+	require.NoError(t, err)
+	assert.Equal(t, AuditVerification{Valid: true, Entries: 5, LastHash: hashes[4]}, verification)
+
+	storage.auditLog[2].Data = json.RawMessage(`{"proxy":"github","tool":"delete_repository"}`)
+	verification, err = VerifyAuditLog(ctx, storage, 2)
+	require.NoError(t, err)
+	assert.False(t, verification.Valid)
+	assert.Equal(t, int64(3), verification.BrokenAt)
+	assert.Equal(t, int64(2), verification.Entries)
+
+	// Recomputing the hash of the altered entry breaks the next one
+	storage.auditLog[2].Hash = storage.auditLog[2].ComputeHash()
+	verification, err = VerifyAuditLog(ctx, storage, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), verification.BrokenAt)
+
+	storage.auditLog = append(storage.auditLog[:1], storage.auditLog[2:]...)
+	verification, err = VerifyAuditLog(ctx, storage, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), verification.BrokenAt)
+	assert.Contains(t, verification.Reason, "expected the entry 2")
+}
+
+func TestMemoryStorageClassifications(t *testing.T) {
+	storage := NewMemoryStorage("")
+	ctx := context.Background()
+	assert.NoError(t, storage.SetClassification(ctx, ClassificationConfig{Proxy: "*", Tool: "*", Level: ClassificationInternal}))
+	assert.NoError(t, storage.SetClassification(ctx, ClassificationConfig{Proxy: "github", Tool: "*", Level: ClassificationConfidential}))
+	assert.NoError(t, storage.SetClassification(ctx, ClassificationConfig{Proxy: "github", Tool: "search", Level: ClassificationPublic}))
+	assert.Error(t, storage.SetClassification(ctx, ClassificationConfig{Proxy: "github", Tool: "search", Level: "secret"}))
+	labels, err := storage.ListClassifications(ctx)
+	assert.NoError(t, err)
+	require.Len(t, labels, 3)
+	assert.Equal(t, "*", labels[0].Proxy)
+	assert.Equal(t, ClassificationPublic, ClassificationOf(labels, "github", "search"))
+	assert.Equal(t, ClassificationConfidential, ClassificationOf(labels, "github", "delete_repository"))
+	assert.Equal(t, ClassificationInternal, ClassificationOf(labels, "jira", "search"))
+	assert.Equal(t, ClassificationPublic, ClassificationOf(nil, "jira", "search"))
+
+	assert.True(t, ClassificationConfidential.Allows(ClassificationInternal))
+	assert.False(t, ClassificationInternal.Allows(ClassificationConfidential))
+	assert.True(t, Classification("").Allows(ClassificationPublic))
+	assert.False(t, Classification("").Allows(ClassificationInternal))
+
+	assert.Error(t, storage.SetRole(ctx, RoleConfig{Name: "reader", Clearance: "secret"}))
+	assert.NoError(t, storage.SetRole(ctx, RoleConfig{Name: "reader", Clearance: ClassificationInternal}))
+	role, err := storage.GetRole(ctx, "reader")
+	assert.NoError(t, err)
+	assert.Equal(t, ClassificationInternal, role.Clearance)
+
+	assert.NoError(t, storage.DeleteClassification(ctx, "*", "*"))
+	labels, err = storage.ListClassifications(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, labels, 2)
+}
+
+func TestMemoryStorageCompositeTools(t *testing.T) {
+	storage := NewMemoryStorage("")
+	ctx := context.Background()
+	step := func(id string, parallel bool, arguments map[string]any) CompositeStep {
+		return CompositeStep{ID: id, Proxy: "github", Tool: id, Arguments: arguments, Parallel: parallel}
+	}
+
+	for _, test := range []struct {
+		name     string
+		tool     CompositeToolConfig
+		expected string
+	}{
+		{name: "invalid name", tool: CompositeToolConfig{Name: "triage issues", Steps: []CompositeStep{step("a", false, nil)}},
+			expected: "invalid composite tool name"},
+		{name: "no step", tool: CompositeToolConfig{Name: "triage"}, expected: "has no step"},
+		{name: "invalid input schema", tool: CompositeToolConfig{Name: "triage", InputSchema: json.RawMessage(`[]`),
+			Steps: []CompositeStep{step("a", false, nil)}}, expected: "invalid input schema"},
+		{name: "duplicate step", tool: CompositeToolConfig{Name: "triage",
+			Steps: []CompositeStep{step("a", false, nil), step("a", true, nil)}}, expected: "duplicate step id"},
+		{name: "composite step", tool: CompositeToolConfig{Name: "triage",
+			Steps: []CompositeStep{{ID: "a", Proxy: CompositeProxy, Tool: "other"}}}, expected: "cannot call a composite tool"},
+		{name: "reference to a parallel step", tool: CompositeToolConfig{Name: "triage",
+			Steps: []CompositeStep{step("a", false, nil), step("b", true, map[string]any{"q": "${steps.a}"})}},
+			expected: "is not to an earlier step"},
+		{name: "invalid reference", tool: CompositeToolConfig{Name: "triage",
+			Steps: []CompositeStep{step("a", false, map[string]any{"q": "${query}"})}}, expected: "invalid reference"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.ErrorContains(t, storage.SetCompositeTool(ctx, test.tool), test.expected)
+		})
+	}
+
+	tool := CompositeToolConfig{Name: "triage", Steps: []CompositeStep{
+		step("search", false, map[string]any{"query": "${args.query}", "limit": "${args.limit}"}),
+		step("labels", true, nil),
+		step("comment", false, map[string]any{
+			"issue": "${steps.search.items.0.id}",
+			"body":  "Labels: ${steps.labels}, first of ${steps.search.total}",
+			"tags":  []any{"${args.query}", 42},
+		}),
+	}}
+	require.NoError(t, storage.SetCompositeTool(ctx, tool))
+	require.NoError(t, storage.SetCompositeTool(ctx, CompositeToolConfig{Name: "audit", Steps: []CompositeStep{step("a", false, nil)}}))
+	tools, err := storage.ListCompositeTools(ctx)
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	assert.Equal(t, "audit", tools[0].Name)
+
+	stages := tool.Stages()
+	require.Len(t, stages, 2)
+	assert.Len(t, stages[0], 2)
+	assert.Equal(t, "comment", stages[1][0].ID)
+
+	scope := map[string]any{
+		"args": map[string]any{"query": "bug", "limit": float64(5)},
+		"steps": map[string]any{
+			"search": map[string]any{"total": float64(3), "items": []any{map[string]any{"id": float64(7)}}},
+			"labels": "p1",
+		},
+	}
+	arguments, err := tool.Steps[0].ResolveArguments(scope)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"query": "bug", "limit": float64(5)}, arguments)
+	arguments, err = tool.Steps[2].ResolveArguments(scope)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"issue": float64(7), "body": "Labels: p1, first of 3", "tags": []any{"bug", 42}}, arguments)
+	_, err = tool.Steps[2].ResolveArguments(map[string]any{"args": map[string]any{}, "steps": map[string]any{}})
+	assert.ErrorContains(t, err, "not found")
+
+	assert.ErrorContains(t, storage.SetProxy(ctx, &ProxyConfig{Name: CompositeProxy, Type: ProxyTypeStreamableHTTP}, true), "reserved")
+
+	require.NoError(t, storage.DeleteCompositeTool(ctx, "audit"))
+	tools, err = storage.ListCompositeTools(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tools, 1)
+}
+
+func TestMemoryStorageLeadership(t *testing.T) {
+	storage := NewMemoryStorage("")
+	ctx := context.Background()
+	leader, err := storage.TryLeadership(ctx, ProxySyncLeadership)
+	assert.NoError(t, err)
+	assert.True(t, leader)
+	assert.NoError(t, storage.ReleaseLeadership(ctx, ProxySyncLeadership))
+
+	assert.NoError(t, storage.SetProxyTools(ctx, ProxyTools{Proxy: "jira", Tools: json.RawMessage(`[]`)}))
+	assert.NoError(t, storage.SetProxyTools(ctx, ProxyTools{Proxy: "github", Tools: json.RawMessage(`[{"name":"search"}]`)}))
+	shared, err := storage.ListProxyTools(ctx)
+	assert.NoError(t, err)
+	require.Len(t, shared, 2)
+	assert.Equal(t, "github", shared[0].Proxy)
+	assert.JSONEq(t, `[{"name":"search"}]`, string(shared[0].Tools))
+}
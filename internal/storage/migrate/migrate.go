@@ -3,13 +3,15 @@ package migrate
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file" // import file source
-	_ "github.com/lib/pq"                                // import postgres driver
+	_ "github.com/lib/pq" // import postgres driver
+	config "github.com/matthisholleville/mcp-gateway/internal/cfg"
 	"github.com/matthisholleville/mcp-gateway/internal/storage/utils"
 	"github.com/matthisholleville/mcp-gateway/pkg/logger"
 	"go.uber.org/zap"
@@ -21,16 +23,42 @@ type MigrationConfig struct {
 	URI          string        // connection string for the target database
 	Username     string        // username for the target database
 	Password     string        // password for the target database
+	Schema       string        // schema of the tables (empty means "mcp_gateway")
 	Logger       logger.Logger // structured logger implementation
 	Timeout      time.Duration // advisory lock timeout
 	Verbose      bool          // enable verbose output on migrate CLI
 	Version      int           // target version (0 means "latest")
+	Steps        int           // number of migrations to apply, or to roll back with Down (0 means all)
+	Down         bool          // roll back the migrations, keeping the schema
 	Drop         bool          // drop all objects before migrating
 	MigrationDir string        // filesystem path that contains *.sql files
 }
 
+// Validate checks that a single mode is requested: drop, down, steps or version.
+func (cfg *MigrationConfig) Validate() error {
+	if cfg.Schema != "" && !config.ValidSchema(cfg.Schema) {
+		return fmt.Errorf("schema must be a lowercase Postgres identifier of at most 63 characters, got %q", cfg.Schema)
+	}
+	if cfg.Steps < 0 {
+		return fmt.Errorf("steps must be positive, use down to roll back")
+	}
+	modes := 0
+	for _, set := range []bool{cfg.Drop, cfg.Down || cfg.Steps > 0, cfg.Version != 0} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return fmt.Errorf("drop, down or steps, and target version are mutually exclusive")
+	}
+	return nil
+}
+
 // RunMigrations orchestrates the migration workflow according to cfg.
 func RunMigrations(cfg *MigrationConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
 	m, err := newMigrator(cfg)
 	if err != nil {
 		return err
@@ -45,6 +73,12 @@ func RunMigrations(cfg *MigrationConfig) error {
 	case cfg.Drop:
 		return applyDrop(m, cfg.Logger)
 
+	case cfg.Down:
+		return applyDown(m, cfg.Steps, cfg.Logger)
+
+	case cfg.Steps > 0:
+		return applySteps(m, cfg.Steps, cfg.Logger)
+
 	case cfg.Version == 0:
 		// No explicit version: migrate to the most recent.
 		return applyUp(m, cfg.Logger)
@@ -80,18 +114,19 @@ func newMigrator(cfg *MigrationConfig) (*migrate.Migrate, error) {
 		}
 
 		driver, err := postgres.WithInstance(db, &postgres.Config{
-			MigrationsTable: "migrations",
+			MigrationsTable: migrationsTable(cfg.schema()),
 			SchemaName:      "public",
 		})
 		if err != nil {
 			return nil, fmt.Errorf("create driver: %w", err)
 		}
 
-		m, err := migrate.NewWithDatabaseInstance(
-			"file://"+cfg.MigrationDir,
-			"postgres",
-			driver,
-		)
+		src, err := utils.OpenMigrations(cfg.MigrationDir, cfg.schema())
+		if err != nil {
+			return nil, fmt.Errorf("open migrations: %w", err)
+		}
+
+		m, err := migrate.NewWithInstance("file", src, "postgres", driver)
 		if err != nil {
 			return nil, fmt.Errorf("create migrator: %w", err)
 		}
@@ -106,6 +141,24 @@ func newMigrator(cfg *MigrationConfig) (*migrate.Migrate, error) {
 	}
 }
 
+// schema returns the schema of the tables, the default one if none is set.
+func (cfg *MigrationConfig) schema() string {
+	if cfg.Schema == "" {
+		return config.DefaultBackendSchema
+	}
+	return cfg.Schema
+}
+
+// migrationsTable returns the table, in the public schema, recording the migrations of the tables of a schema.
+// The migrations of each schema are recorded apart, so the gateways of a shared database migrate independently,
+// and the first migration may drop the schema when rolled back.
+func migrationsTable(schema string) string {
+	if schema == config.DefaultBackendSchema {
+		return "migrations"
+	}
+	return schema + "_migrations"
+}
+
 // applyDrop drops every migration then drops the schema itself.
 // It is destructive and should only be used in development / CI.
 func applyDrop(m *migrate.Migrate, log logger.Logger) error {
@@ -128,6 +181,47 @@ func applyUp(m *migrate.Migrate, log logger.Logger) error {
 	return nil
 }
 
+// applyDown rolls back the given number of migrations, or all of them if steps is 0.
+// Unlike applyDrop, the schema and the migrations table are kept.
+func applyDown(m *migrate.Migrate, steps int, log logger.Logger) error {
+	if steps == 0 {
+		log.Info("rolling back all migrations (down)")
+		if err := m.Down(); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("down: %w", err)
+		}
+		log.Info("migrations rolled back")
+		return nil
+	}
+
+	log.Info("rolling back migrations (down)", zap.Int("steps", steps))
+	if err := m.Steps(-steps); err != nil {
+		return stepsError("down", steps, err)
+	}
+	log.Info("migrations rolled back")
+	return nil
+}
+
+// applySteps applies the given number of migrations from the current version.
+func applySteps(m *migrate.Migrate, steps int, log logger.Logger) error {
+	log.Info("running migrations (up)", zap.Int("steps", steps))
+
+	if err := m.Steps(steps); err != nil {
+		return stepsError("up", steps, err)
+	}
+	log.Info("migrations completed")
+	return nil
+}
+
+// stepsError explains the error of a step-wise migration. The available migrations are applied
+// when fewer than the requested steps exist.
+func stepsError(direction string, steps int, err error) error {
+	var short migrate.ErrShortLimit
+	if errors.As(err, &short) {
+		return fmt.Errorf("%s %d: only %d migration(s) were available", direction, steps, uint(steps)-short.Short) //nolint:gosec // G115: steps is positive
+	}
+	return fmt.Errorf("%s %d: %w", direction, steps, err)
+}
+
 // applyVersion migrates up or down until the requested target version is reached.
 func applyVersion(m *migrate.Migrate, target int, log logger.Logger) error {
 	current, dirty, err := m.Version()
@@ -150,3 +244,44 @@ func applyVersion(m *migrate.Migrate, target int, log logger.Logger) error {
 	log.Info("migrations completed")
 	return nil
 }
+
+// Status is the migration state of a database.
+type Status struct {
+	Current uint // version of the database schema, 0 when no migration was applied
+	Latest  uint // latest version available in the migration directory
+	Dirty   bool // a migration failed midway
+}
+
+// GetStatus returns the migration state of the database, or nil for engines that do not require migrations.
+func GetStatus(cfg *MigrationConfig) (*Status, error) {
+	m, err := newMigrator(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, nil
+	}
+	defer m.Close() //nolint:errcheck // nothing interesting to do with the error
+
+	status := &Status{}
+	status.Current, status.Dirty, err = m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return nil, fmt.Errorf("current version: %w", err)
+	}
+
+	src, err := utils.OpenMigrations(cfg.MigrationDir, cfg.schema())
+	if err != nil {
+		return nil, fmt.Errorf("open migrations: %w", err)
+	}
+	defer src.Close() //nolint:errcheck // nothing interesting to do with the error
+
+	version, err := src.First()
+	for err == nil {
+		status.Latest = version
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	return status, nil
+}
@@ -82,3 +82,99 @@ func TestMigrateUpDownDrop(t *testing.T) {
 	}
 
 }
+
+func TestMigrateStepsDown(t *testing.T) {
+	uri, logger, err := setupFixtures(t, "postgres")
+	assert.NoError(t, err)
+
+	cfg := &MigrationConfig{
+		Engine:       "postgres",
+		URI:          uri,
+		Logger:       logger,
+		Timeout:      10 * time.Second,
+		MigrationDir: "../../../assets/migrations/postgres",
+	}
+	status := func() *Status {
+		status, err := GetStatus(cfg)
+		assert.NoError(t, err)
+		return status
+	}
+
+	cfg.Steps = 1
+	assert.NoError(t, RunMigrations(cfg))
+	assert.Equal(t, uint(1), status().Current)
+
+	cfg.Steps = 0
+	assert.NoError(t, RunMigrations(cfg))
+	latest := status().Latest
+	assert.Equal(t, latest, status().Current)
+
+	cfg.Down, cfg.Steps = true, 1
+	assert.NoError(t, RunMigrations(cfg))
+	assert.Equal(t, latest-1, status().Current)
+
+	// The migrations table is kept, unlike with drop.
+	cfg.Steps = 0
+	assert.NoError(t, RunMigrations(cfg))
+	assert.Equal(t, uint(0), status().Current)
+
+	cfg.Down, cfg.Steps = false, 100
+	assert.ErrorContains(t, RunMigrations(cfg), "only")
+	assert.Equal(t, latest, status().Current)
+}
+
+func TestMigrateCustomSchema(t *testing.T) {
+	uri, logger, err := setupFixtures(t, "postgres")
+	assert.NoError(t, err)
+
+	cfg := &MigrationConfig{
+		Engine:       "postgres",
+		URI:          uri,
+		Logger:       logger,
+		Timeout:      10 * time.Second,
+		MigrationDir: "../../../assets/migrations/postgres",
+	}
+	custom := *cfg
+	custom.Schema = "team_gateway"
+	assert.NoError(t, RunMigrations(cfg))
+	assert.NoError(t, RunMigrations(&custom))
+
+	db, err := sql.Open("postgres", uri)
+	assert.NoError(t, err)
+	defer db.Close()
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM team_gateway.proxy").Scan(&count))
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM public.team_gateway_migrations").Scan(&count))
+
+	// Rolling back the migrations of a schema keeps the tables of the others.
+	custom.Down = true
+	assert.NoError(t, RunMigrations(&custom))
+	assert.Error(t, db.QueryRow("SELECT COUNT(*) FROM team_gateway.proxy").Scan(&count))
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM mcp_gateway.proxy").Scan(&count))
+}
+
+func TestMigrationConfigValidate(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		cfg      MigrationConfig
+		expected string
+	}{
+		{name: "up"},
+		{name: "steps", cfg: MigrationConfig{Steps: 2}},
+		{name: "down steps", cfg: MigrationConfig{Down: true, Steps: 2}},
+		{name: "negative steps", cfg: MigrationConfig{Steps: -1}, expected: "steps must be positive"},
+		{name: "drop and down", cfg: MigrationConfig{Drop: true, Down: true}, expected: "mutually exclusive"},
+		{name: "steps and version", cfg: MigrationConfig{Steps: 1, Version: 2}, expected: "mutually exclusive"},
+		{name: "schema", cfg: MigrationConfig{Schema: "team_gateway"}},
+		{name: "invalid schema", cfg: MigrationConfig{Schema: "Team-Gateway"}, expected: "schema must be a lowercase Postgres identifier"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.cfg.Validate()
+			if test.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, test.expected)
+		})
+	}
+}
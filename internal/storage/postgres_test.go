@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -13,9 +14,14 @@ import (
 )
 
 func testPostgresStorage(t *testing.T) (*PostgresStorage, error) {
+	return testPostgresStorageWithSchema(t, "")
+}
+
+func testPostgresStorageWithSchema(t *testing.T, schema string) (*PostgresStorage, error) {
 	logger := logger.MustNewLogger("json", "debug", "")
 	postgresOpts := &testsFixtures.PostgresTestContainerOptions{
 		MigrationsDir: "../../assets/migrations/postgres",
+		Schema:        schema,
 	}
 
 	encryptor, err := aescipher.New("0123456789abcdeffedcba9876543210cafebabefacefeeddeadbeef00112233")
@@ -28,6 +34,7 @@ func testPostgresStorage(t *testing.T) (*PostgresStorage, error) {
 		BackendConfig: &cfg.BackendConfig{
 			Engine: "postgres",
 			URI:    db.GetConnectionURI(true),
+			Schema: schema,
 		},
 	}
 	return NewPostgresStorage("test", logger, testConfig, encryptor)
@@ -100,6 +107,80 @@ func TestProxyStorage(t *testing.T) {
 	})
 }
 
+func TestProxyStorageHMAC(t *testing.T) {
+	storage, err := testPostgresStorage(t)
+	assert.NoError(t, err)
+
+	proxy := ProxyConfig{
+		Name:     "signed",
+		Type:     ProxyTypeStreamableHTTP,
+		URL:      "https://example.com",
+		AuthType: ProxyAuthTypeHMAC,
+		HMAC:     &ProxyHMAC{Secret: "s3cr3t"},
+	}
+	assert.NoError(t, storage.SetProxy(context.Background(), &proxy, true))
+
+	stored, err := storage.GetProxy(context.Background(), "signed", false)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "s3cr3t", stored.HMAC.Secret, "the secret must be encrypted")
+	stored, err = storage.GetProxy(context.Background(), "signed", true)
+	assert.NoError(t, err)
+	assert.Equal(t, &ProxyHMAC{Secret: "s3cr3t"}, stored.HMAC)
+	proxies, err := storage.ListProxies(context.Background(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, &ProxyHMAC{Secret: "s3cr3t"}, proxies[0].HMAC)
+
+	stored.AuthType, stored.HMAC = ProxyAuthTypeHeader, nil
+	assert.NoError(t, storage.SetProxy(context.Background(), &stored, true))
+	stored, err = storage.GetProxy(context.Background(), "signed", true)
+	assert.NoError(t, err)
+	assert.Nil(t, stored.HMAC)
+}
+
+func TestProxyStorageLabelsAndMetadata(t *testing.T) {
+	storage, err := testPostgresStorage(t)
+	assert.NoError(t, err)
+
+	proxy := ProxyConfig{
+		Name:        "billing",
+		Type:        ProxyTypeStreamableHTTP,
+		URL:         "https://example.com",
+		AuthType:    ProxyAuthTypeHeader,
+		Labels:      map[string]string{"team": "payments", "env": "staging"},
+		Description: "Billing and refunds",
+		Owner:       "payments@example.com",
+		DocsURL:     "https://wiki.example.com/billing-mcp",
+		EgressProxy: "http://egress.internal:3128",
+
+		MaxResponseBytes:   1 << 20,
+		ResponseSizePolicy: "reject",
+		KeepaliveInterval:  30 * time.Second,
+	}
+	assert.NoError(t, storage.SetProxy(context.Background(), &proxy, true))
+	stored, err := storage.GetProxy(context.Background(), "billing", false)
+	assert.NoError(t, err)
+	assert.Equal(t, proxy.Labels, stored.Labels)
+	assert.Equal(t, "Billing and refunds", stored.Description)
+	assert.Equal(t, "payments@example.com", stored.Owner)
+	assert.Equal(t, "https://wiki.example.com/billing-mcp", stored.DocsURL)
+	assert.Equal(t, "http://egress.internal:3128", stored.EgressProxy)
+	assert.Equal(t, 1<<20, stored.MaxResponseBytes)
+	assert.Equal(t, "reject", stored.ResponseSizePolicy)
+	assert.Equal(t, 30*time.Second, stored.KeepaliveInterval)
+
+	stored.Labels = map[string]string{"team": "billing"}
+	assert.NoError(t, storage.SetProxy(context.Background(), &stored, false))
+	proxies, err := storage.ListProxies(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "billing"}, proxies[0].Labels)
+
+	stored.Labels = nil
+	assert.NoError(t, storage.SetProxy(context.Background(), &stored, false))
+	stored, err = storage.GetProxy(context.Background(), "billing", false)
+	assert.NoError(t, err)
+	assert.Nil(t, stored.Labels)
+}
+
 func TestRoleStorage(t *testing.T) {
 	storage, err := testPostgresStorage(t)
 	assert.NoError(t, err)
@@ -132,6 +213,13 @@ func TestRoleStorage(t *testing.T) {
 		assert.Equal(t, "test", roles[0].Name)
 	})
 
+	t.Run("ensure get roles returns the known roles with their permissions", func(t *testing.T) {
+		roles, err := storage.GetRoles(context.Background(), []string{"unknown", "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(roles))
+		assert.Equal(t, []PermissionConfig{{ObjectType: ObjectTypeTools, ObjectName: "*", Proxy: "*"}}, roles[0].Permissions)
+	})
+
 	t.Run("delete role", func(t *testing.T) {
 		err := storage.DeleteRole(context.Background(), "test")
 		assert.NoError(t, err)
@@ -210,6 +298,14 @@ func TestAttributeToRolesStorage(t *testing.T) {
 		assert.Equal(t, "test", attributeToRoles.AttributeValue)
 	})
 
+	t.Run("ensure the mappings of the claims are fetched at once", func(t *testing.T) {
+		mappings, err := storage.GetAttributeToRolesForClaims(context.Background(), []AttributePair{
+			{Key: "test", Value: "test"}, {Key: "test", Value: "other"}, {Key: "groups", Value: "test"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []AttributeToRolesConfig{{AttributeKey: "test", AttributeValue: "test", Roles: []string{"test"}}}, mappings)
+	})
+
 	t.Run("delete attribute to roles", func(t *testing.T) {
 		err := storage.DeleteAttributeToRoles(context.Background(), "test", "test")
 		assert.NoError(t, err)
@@ -220,3 +316,481 @@ func TestAttributeToRolesStorage(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestUsageStorage(t *testing.T) {
+	storage, err := testPostgresStorage(t)
+	assert.NoError(t, err)
+
+	t.Run("record tool calls", func(t *testing.T) {
+		for _, record := range []ToolCallRecord{
+			{Proxy: "test", Tool: "search", Identity: "alice", CalledAt: time.Now()},
+			{Proxy: "test", Tool: "search", Identity: "bob", IsError: true, CalledAt: time.Now()},
+			{Proxy: "test", Tool: "run", Identity: "alice", CalledAt: time.Now().Add(-2 * time.Hour)},
+		} {
+			err := storage.RecordToolCall(context.Background(), record)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("ensure usage stats are computed over the window", func(t *testing.T) {
+		stats, err := storage.GetUsageStats(context.Background(), time.Now().Add(-time.Hour), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), stats.TotalCalls)
+		assert.Equal(t, int64(1), stats.ErrorCalls)
+		assert.Equal(t, 0.5, stats.ErrorRate)
+		assert.Equal(t, []ToolUsage{{Proxy: "test", Tool: "search", Calls: 2, Errors: 1, ErrorRate: 0.5}}, stats.TopTools)
+		assert.Len(t, stats.TopIdentities, 2)
+	})
+
+	t.Run("ensure tool calls are rolled up by day", func(t *testing.T) {
+		day := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+		for range 2 {
+			err := storage.RecordToolCall(context.Background(), ToolCallRecord{
+				Proxy: "test", Tool: "search", Identity: "carol", CalledAt: day, Duration: 250 * time.Millisecond,
+			})
+			assert.NoError(t, err)
+		}
+		usage, err := storage.GetDailyUsage(context.Background(), day, day)
+		assert.NoError(t, err)
+		assert.Equal(t, []DailyUsage{{Day: "2025-06-01", Identity: "carol", Proxy: "test", Tool: "search", Calls: 2, DurationMs: 500}}, usage)
+	})
+}
+
+func TestQuotaStorage(t *testing.T) {
+	storage, err := testPostgresStorage(t)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	quota := QuotaConfig{Name: "search", SubjectType: QuotaSubjectTypeSubject, Subject: "*", Proxy: "test", Tool: "search",
+		Window: QuotaWindowDay, Limit: 2}
+	now := time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)
+
+	t.Run("insert quota", func(t *testing.T) {
+		assert.NoError(t, storage.SetQuota(ctx, quota))
+		quotas, err := storage.ListQuotas(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []QuotaConfig{quota}, quotas)
+	})
+
+	t.Run("ensure calls are counted up to the limit", func(t *testing.T) {
+		for _, expected := range []bool{true, true, false} {
+			usage, allowed, err := storage.ConsumeQuota(ctx, quota, "alice", now)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, allowed)
+			assert.Equal(t, int64(2), usage.Calls)
+		}
+	})
+
+	t.Run("ensure calls are counted again in the next window", func(t *testing.T) {
+		usage, allowed, err := storage.ConsumeQuota(ctx, quota, "alice", now.Add(24*time.Hour))
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, int64(1), usage.Calls)
+	})
+
+	t.Run("reset and delete quota", func(t *testing.T) {
+		assert.NoError(t, storage.ResetQuotaUsage(ctx, "search", ""))
+		usages, err := storage.ListQuotaUsage(ctx, quota, now)
+		assert.NoError(t, err)
+		assert.Empty(t, usages)
+		assert.NoError(t, storage.DeleteQuota(ctx, "search"))
+		_, err = storage.GetQuota(ctx, "search")
+		assert.Error(t, err)
+	})
+}
+
+func TestBudgetStorage(t *testing.T) {
+	storage, err := testPostgresStorage(t)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	budget := BudgetConfig{Name: "readers", Role: "reader", Window: QuotaWindowWeek, Limit: 5, Action: BudgetActionDeny}
+	now := time.Date(2025, 6, 4, 10, 30, 0, 0, time.UTC)
+
+	t.Run("insert tool cost and budget", func(t *testing.T) {
+		cost := ToolCostConfig{Proxy: "test", Tool: "search", Cost: 2}
+		assert.NoError(t, storage.SetToolCost(ctx, cost))
+		costs, err := storage.ListToolCosts(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []ToolCostConfig{cost}, costs)
+		assert.NoError(t, storage.SetBudget(ctx, budget))
+		budgets, err := storage.ListBudgets(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []BudgetConfig{budget}, budgets)
+	})
+
+	t.Run("ensure costs are charged up to the limit", func(t *testing.T) {
+		for _, expected := range []bool{true, true, false} {
+			_, covered, err := storage.ConsumeBudget(ctx, budget, 2, now)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, covered)
+		}
+		usage, err := storage.GetBudgetUsage(ctx, budget, now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), usage.Consumed)
+		assert.Equal(t, time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC), usage.ResetsAt)
+	})
+
+	t.Run("ensure a warn budget is charged past the limit", func(t *testing.T) {
+		budget := budget
+		budget.Action = BudgetActionWarn
+		usage, covered, err := storage.ConsumeBudget(ctx, budget, 2, now)
+		assert.NoError(t, err)
+		assert.False(t, covered)
+		assert.Equal(t, int64(6), usage.Consumed)
+	})
+
+	t.Run("ensure the budget is renewed in the next window", func(t *testing.T) {
+		usage, covered, err := storage.ConsumeBudget(ctx, budget, 2, now.AddDate(0, 0, 7))
+		assert.NoError(t, err)
+		assert.True(t, covered)
+		assert.Equal(t, int64(2), usage.Consumed)
+	})
+
+	t.Run("reset and delete budget", func(t *testing.T) {
+		assert.NoError(t, storage.ResetBudgetUsage(ctx, "readers"))
+		assert.NoError(t, storage.DeleteBudget(ctx, "readers"))
+		_, err := storage.GetBudget(ctx, "readers")
+		assert.Error(t, err)
+		assert.NoError(t, storage.DeleteToolCost(ctx, "test", "search"))
+	})
+}
+
+func TestApprovalStorage(t *testing.T) {
+	storage, err := testPostgresStorage(t)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)
+	approval := ApprovalRequest{ID: "first", Proxy: "test", Tool: "delete", Identity: "alice",
+		Arguments: json.RawMessage(`{"name":"repo"}`), Status: ApprovalStatusPending, RequestedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	t.Run("insert tool policy", func(t *testing.T) {
+		policy := ToolPolicyConfig{Proxy: "test", Tool: "delete", RequiresApproval: true}
+		assert.NoError(t, storage.SetToolPolicy(ctx, policy))
+		policies, err := storage.ListToolPolicies(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []ToolPolicyConfig{policy}, policies)
+	})
+
+	t.Run("insert and list approval", func(t *testing.T) {
+		assert.NoError(t, storage.CreateApproval(ctx, approval))
+		approvals, err := storage.ListApprovals(ctx, ApprovalStatusPending)
+		assert.NoError(t, err)
+		assert.Equal(t, []ApprovalRequest{approval}, approvals)
+	})
+
+	t.Run("ensure an approval is decided once", func(t *testing.T) {
+		decided, err := storage.DecideApproval(ctx, "first", ApprovalStatusDenied, "bob", "too risky", now)
+		assert.NoError(t, err)
+		assert.Equal(t, ApprovalStatusDenied, decided.Status)
+		assert.Equal(t, now, *decided.DecidedAt)
+		_, err = storage.DecideApproval(ctx, "first", ApprovalStatusApproved, "carol", "", now)
+		assert.ErrorIs(t, err, ErrApprovalDecided)
+		approval, err := storage.GetApproval(ctx, "first")
+		assert.NoError(t, err)
+		assert.Equal(t, "bob", approval.DecidedBy)
+	})
+
+	t.Run("delete tool policy", func(t *testing.T) {
+		assert.NoError(t, storage.DeleteToolPolicy(ctx, "test", "delete"))
+		policies, err := storage.ListToolPolicies(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, policies)
+	})
+}
+
+func TestTraceStorage(t *testing.T) {
+	storage, err := testPostgresStorage(t)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)
+	first := ToolCallTrace{ID: "first", Proxy: "test", Tool: "search", Identity: "alice", CalledAt: now, Duration: time.Second,
+		AuthDuration: time.Millisecond, StorageDuration: 2 * time.Millisecond, UpstreamDuration: 900 * time.Millisecond,
+		Arguments: json.RawMessage(`{"query":"mcp"}`), ResultSize: 42}
+	second := ToolCallTrace{ID: "second", Proxy: "test", Tool: "delete", Identity: "bob", CalledAt: now.Add(time.Hour), IsError: true}
+
+	t.Run("insert and get trace", func(t *testing.T) {
+		assert.NoError(t, storage.RecordTrace(ctx, first))
+		assert.NoError(t, storage.RecordTrace(ctx, second))
+		trace, err := storage.GetTrace(ctx, "first")
+		assert.NoError(t, err)
+		assert.Equal(t, first, trace)
+		_, err = storage.GetTrace(ctx, "missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("list traces", func(t *testing.T) {
+		traces, err := storage.ListTraces(ctx, TraceFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, []ToolCallTrace{second, first}, traces)
+		traces, err = storage.ListTraces(ctx, TraceFilter{Identity: "alice", Since: now, Until: now.Add(time.Minute)})
+		assert.NoError(t, err)
+		assert.Equal(t, []ToolCallTrace{first}, traces)
+		traces, err = storage.ListTraces(ctx, TraceFilter{ErrorsOnly: true, Limit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []ToolCallTrace{second}, traces)
+	})
+
+	t.Run("purge traces", func(t *testing.T) {
+		purged, err := storage.PurgeTraces(ctx, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+		traces, err := storage.ListTraces(ctx, TraceFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, []ToolCallTrace{second}, traces)
+	})
+}
+
+func TestAuditLogStorage(t *testing.T) {
+	storage, err := testPostgresStorage(t)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 10, 30, 0, 123456789, time.UTC)
+
+	first, err := storage.AppendAuditEntry(ctx, "admin_mutation", json.RawMessage(`{"api":"http","operation":"PUT /v1/admin/roles"}`), now)
+	assert.NoError(t, err)
+	second, err := storage.AppendAuditEntry(ctx, "tool_call", json.RawMessage(`{"proxy":"test","tool":"search"}`), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), second.Seq)
+	assert.Equal(t, first.Hash, second.PrevHash)
+
+	entries, err := storage.ListAuditEntries(ctx, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []AuditEntry{first, second}, entries)
+
+	verification, err := VerifyAuditLog(ctx, storage, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, AuditVerification{Valid: true, Entries: 2, LastHash: second.Hash}, verification)
+
+	assert.NoError(t, storage.db.Exec(`UPDATE mcp_gateway.audit_log SET data = '{"proxy":"test","tool":"delete"}' WHERE seq = 2`).Error)
+	verification, err = VerifyAuditLog(ctx, storage, 1)
+	assert.NoError(t, err)
+	assert.False(t, verification.Valid)
+	assert.Equal(t, int64(2), verification.BrokenAt)
+}
+
+func TestReencryptStorage(t *testing.T) {
+	log := logger.MustNewLogger("json", "debug", "")
+	db := testsFixtures.NewPostgresTestContainer(&testsFixtures.PostgresTestContainerOptions{
+		MigrationsDir: "../../assets/migrations/postgres",
+	}).RunPostgresTestContainer(t)
+	testConfig := &cfg.Config{BackendConfig: &cfg.BackendConfig{Engine: "postgres", URI: db.GetConnectionURI(true)}}
+	oldKey := aescipher.Key{ID: "old", Key: "0123456789abcdeffedcba9876543210cafebabefacefeeddeadbeef00112233"}
+	newKey := aescipher.Key{ID: "new", Key: "fedcba98765432100123456789abcdefdeadbeeffacefeedcafebabe33221100"}
+
+	oldEncryptor, _ := aescipher.NewKeyring(oldKey)
+	storage, err := NewPostgresStorage("test", log, testConfig, oldEncryptor)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.SetProxy(context.Background(), &ProxyConfig{
+		Name: "test", Type: ProxyTypeStreamableHTTP, URL: "https://example.com", Timeout: 10 * time.Second,
+		AuthType: ProxyAuthTypeHMAC, Headers: []ProxyHeader{{Key: "a", Value: "secret-a"}, {Key: "b", Value: "secret-b"}},
+		HMAC: &ProxyHMAC{Secret: "secret-hmac"},
+	}, true))
+	assert.NoError(t, storage.SetProxy(context.Background(), &ProxyConfig{
+		Name: "plain", Type: ProxyTypeStreamableHTTP, URL: "https://example.com", Timeout: 10 * time.Second,
+		AuthType: ProxyAuthTypeHeader, Headers: []ProxyHeader{{Key: "c", Value: "secret-c"}},
+	}, false))
+
+	keyring, _ := aescipher.NewKeyring(newKey, oldKey)
+	storage, err = NewPostgresStorage("test", log, testConfig, keyring)
+	assert.NoError(t, err)
+
+	t.Run("dry run", func(t *testing.T) {
+		result, err := storage.Reencrypt(context.Background(), ReencryptOptions{DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, ReencryptResult{Total: 4, Reencrypted: 3, Encrypted: 1, Failed: []string{}}, result)
+	})
+
+	t.Run("reencrypt", func(t *testing.T) {
+		var progress [][2]int
+		result, err := storage.Reencrypt(context.Background(), ReencryptOptions{BatchSize: 2, Progress: func(done, total int) {
+			progress = append(progress, [2]int{done, total})
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, ReencryptResult{Total: 4, Reencrypted: 3, Encrypted: 1, Failed: []string{}}, result)
+		assert.Equal(t, [][2]int{{2, 4}, {3, 4}, {4, 4}}, progress)
+	})
+
+	t.Run("ensure the headers are decrypted with the new key only", func(t *testing.T) {
+		newEncryptor, _ := aescipher.NewKeyring(newKey)
+		storage, err := NewPostgresStorage("test", log, testConfig, newEncryptor)
+		assert.NoError(t, err)
+		proxies, err := storage.ListProxies(context.Background(), true)
+		assert.NoError(t, err)
+		assert.Equal(t, []ProxyHeader{{Key: "c", Value: "secret-c"}}, proxies[0].Headers)
+		assert.Equal(t, []ProxyHeader{{Key: "a", Value: "secret-a"}, {Key: "b", Value: "secret-b"}}, proxies[1].Headers)
+		assert.Equal(t, &ProxyHMAC{Secret: "secret-hmac"}, proxies[1].HMAC)
+
+		result, err := storage.Reencrypt(context.Background(), ReencryptOptions{DryRun: true})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Failed)
+	})
+}
+
+func TestClassificationStorage(t *testing.T) {
+	storage, err := testPostgresStorage(t)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("insert role with clearance", func(t *testing.T) {
+		role := RoleConfig{Name: "security", Permissions: []PermissionConfig{}, Clearance: ClassificationConfidential}
+		assert.NoError(t, storage.SetRole(ctx, role))
+		assert.Error(t, storage.SetRole(ctx, RoleConfig{Name: "security", Clearance: "secret"}))
+		got, err := storage.GetRole(ctx, "security")
+		assert.NoError(t, err)
+		assert.Equal(t, ClassificationConfidential, got.Clearance)
+		roles, err := storage.ListRoles(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, ClassificationConfidential, roles[0].Clearance)
+	})
+
+	t.Run("upsert classification", func(t *testing.T) {
+		assert.NoError(t, storage.SetClassification(ctx, ClassificationConfig{Proxy: "test", Tool: "*", Level: ClassificationInternal}))
+		label := ClassificationConfig{Proxy: "test", Tool: "*", Level: ClassificationConfidential}
+		assert.NoError(t, storage.SetClassification(ctx, label))
+		assert.Error(t, storage.SetClassification(ctx, ClassificationConfig{Proxy: "test", Tool: "*", Level: "secret"}))
+		classifications, err := storage.ListClassifications(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []ClassificationConfig{label}, classifications)
+	})
+
+	t.Run("delete classification", func(t *testing.T) {
+		assert.NoError(t, storage.DeleteClassification(ctx, "test", "*"))
+		classifications, err := storage.ListClassifications(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, classifications)
+	})
+}
+
+func TestCompositeToolStorage(t *testing.T) {
+	storage, err := testPostgresStorage(t)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	tool := CompositeToolConfig{
+		Name:        "triage",
+		Description: "Search the issues and comment the first one",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"query":{"type":"string"}}}`),
+		Steps: []CompositeStep{
+			{ID: "search", Proxy: "jira", Tool: "search", Arguments: map[string]any{"jql": "${args.query}"}},
+			{ID: "comment", Proxy: "jira", Tool: "comment", Arguments: map[string]any{"issue": "${steps.search.0.key}"}},
+		},
+	}
+
+	t.Run("upsert composite tool", func(t *testing.T) {
+		assert.NoError(t, storage.SetCompositeTool(ctx, CompositeToolConfig{Name: "triage", Steps: tool.Steps[:1]}))
+		assert.NoError(t, storage.SetCompositeTool(ctx, tool))
+		assert.Error(t, storage.SetCompositeTool(ctx, CompositeToolConfig{Name: "empty"}))
+		tools, err := storage.ListCompositeTools(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []CompositeToolConfig{tool}, tools)
+	})
+
+	t.Run("reject a proxy named after the composite tools", func(t *testing.T) {
+		assert.ErrorContains(t, storage.SetProxy(ctx, &ProxyConfig{
+			Name: CompositeProxy, Type: ProxyTypeStreamableHTTP, URL: "https://example.com", AuthType: ProxyAuthTypeHeader,
+		}, true), "reserved")
+	})
+
+	t.Run("delete composite tool", func(t *testing.T) {
+		assert.NoError(t, storage.DeleteCompositeTool(ctx, "triage"))
+		tools, err := storage.ListCompositeTools(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, tools)
+	})
+}
+
+func TestLeaderStorage(t *testing.T) {
+	log := logger.MustNewLogger("json", "debug", "")
+	db := testsFixtures.NewPostgresTestContainer(&testsFixtures.PostgresTestContainerOptions{
+		MigrationsDir: "../../assets/migrations/postgres",
+	}).RunPostgresTestContainer(t)
+	testConfig := &cfg.Config{BackendConfig: &cfg.BackendConfig{Engine: "postgres", URI: db.GetConnectionURI(true)}}
+	encryptor, _ := aescipher.New("0123456789abcdeffedcba9876543210cafebabefacefeeddeadbeef00112233")
+	replica1, err := NewPostgresStorage("test", log, testConfig, encryptor)
+	assert.NoError(t, err)
+	replica2, err := NewPostgresStorage("test", log, testConfig, encryptor)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("a single replica leads", func(t *testing.T) {
+		leader, err := replica1.TryLeadership(ctx, ProxySyncLeadership)
+		assert.NoError(t, err)
+		assert.True(t, leader)
+		leader, err = replica1.TryLeadership(ctx, ProxySyncLeadership)
+		assert.NoError(t, err)
+		assert.True(t, leader)
+		leader, err = replica2.TryLeadership(ctx, ProxySyncLeadership)
+		assert.NoError(t, err)
+		assert.False(t, leader)
+	})
+
+	t.Run("another replica takes over once released", func(t *testing.T) {
+		assert.NoError(t, replica1.ReleaseLeadership(ctx, ProxySyncLeadership))
+		leader, err := replica2.TryLeadership(ctx, ProxySyncLeadership)
+		assert.NoError(t, err)
+		assert.True(t, leader)
+		leader, err = replica1.TryLeadership(ctx, ProxySyncLeadership)
+		assert.NoError(t, err)
+		assert.False(t, leader)
+	})
+
+	t.Run("the gateways of another schema elect their own leader", func(t *testing.T) {
+		otherConfig := &cfg.Config{BackendConfig: &cfg.BackendConfig{
+			Engine: "postgres", URI: db.GetConnectionURI(true), Schema: "team_gateway",
+		}}
+		other1, err := NewPostgresStorage("test", log, otherConfig, encryptor)
+		assert.NoError(t, err)
+		other2, err := NewPostgresStorage("test", log, otherConfig, encryptor)
+		assert.NoError(t, err)
+		leader, err := other1.TryLeadership(ctx, ProxySyncLeadership)
+		assert.NoError(t, err)
+		assert.True(t, leader, "the leader of the default schema must not lead the other schema")
+		leader, err = other2.TryLeadership(ctx, ProxySyncLeadership)
+		assert.NoError(t, err)
+		assert.False(t, leader)
+		assert.NoError(t, other1.ReleaseLeadership(ctx, ProxySyncLeadership))
+	})
+
+	t.Run("share the proxy tools", func(t *testing.T) {
+		assert.NoError(t, replica1.SetProxy(ctx, &ProxyConfig{
+			Name: "test", Type: ProxyTypeStreamableHTTP, URL: "https://example.com", Timeout: 10 * time.Second,
+			AuthType: ProxyAuthTypeHeader,
+		}, true))
+		syncedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		tools := ProxyTools{Proxy: "test", Tools: json.RawMessage(`[{"name":"search"}]`), SyncedAt: syncedAt}
+		assert.NoError(t, replica2.SetProxyTools(ctx, tools))
+		tools.Tools = json.RawMessage(`[{"name":"search"},{"name":"create"}]`)
+		assert.NoError(t, replica2.SetProxyTools(ctx, tools))
+		shared, err := replica1.ListProxyTools(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, shared, 1)
+		assert.JSONEq(t, string(tools.Tools), string(shared[0].Tools))
+		assert.Empty(t, shared[0].ResourceTemplates)
+		assert.True(t, syncedAt.Equal(shared[0].SyncedAt))
+	})
+
+	t.Run("the tools are deleted with their proxy", func(t *testing.T) {
+		assert.NoError(t, replica1.DeleteProxy(ctx, "test"))
+		shared, err := replica1.ListProxyTools(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, shared)
+	})
+}
+
+func TestCustomSchemaStorage(t *testing.T) {
+	storage, err := testPostgresStorageWithSchema(t, "team_gateway")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, storage.SetProxy(ctx, &ProxyConfig{
+		Name: "test", Type: ProxyTypeStreamableHTTP, URL: "https://example.com", Timeout: 10 * time.Second,
+		AuthType: ProxyAuthTypeHeader, Headers: []ProxyHeader{{Key: "test", Value: "test"}},
+	}, true))
+	proxies, err := storage.ListProxies(ctx, true)
+	assert.NoError(t, err)
+	assert.Len(t, proxies, 1)
+	assert.Equal(t, "test", proxies[0].Headers[0].Value)
+
+	var schemas []string
+	assert.NoError(t, storage.db.Raw(`SELECT table_schema FROM information_schema.tables WHERE table_name = 'proxy'`).Scan(&schemas).Error)
+	assert.Equal(t, []string{"team_gateway"}, schemas)
+}
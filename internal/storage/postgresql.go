@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/lib/pq"
@@ -24,9 +26,16 @@ type PostgresStorage struct {
 	db        *gorm.DB
 	encryptor aescipher.Cryptor
 	logger    logger.Logger
+	// schema is the schema of the tables, mcp_gateway when empty.
+	schema string
+
+	// leaders are the connections holding the advisory locks of the leaderships of the replica
+	leaderMu sync.Mutex
+	leaders  map[string]*sql.Conn
 }
 
-// NewPostgresStorage creates a new Postgres storage instance.
+// NewPostgresStorage creates a new Postgres storage instance. The connections are opened when used: Ping checks
+// that the database is reachable.
 //
 //nolint:gocritic // we need to keep logger as a parameter for the function
 func NewPostgresStorage(defaultScope string, logger logger.Logger, cfg *cfg.Config, encryptor aescipher.Cryptor) (*PostgresStorage, error) {
@@ -37,8 +46,14 @@ func NewPostgresStorage(defaultScope string, logger logger.Logger, cfg *cfg.Conf
 	if err != nil {
 		return nil, err
 	}
-	db, err := gorm.Open(postgres.Open(uri), &gorm.Config{
-		Logger: gormLogger,
+	// Behind a transaction pooler, consecutive statements may reach different server connections, which
+	// do not know the statements prepared by the others.
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DSN:                  uri,
+		PreferSimpleProtocol: cfg.BackendConfig.PgBouncer,
+	}), &gorm.Config{
+		Logger:               gormLogger,
+		DisableAutomaticPing: true,
 	})
 	if err != nil {
 		return nil, err
@@ -61,9 +76,29 @@ func NewPostgresStorage(defaultScope string, logger logger.Logger, cfg *cfg.Conf
 		db:          db,
 		encryptor:   encryptor,
 		logger:      logger,
+		schema:      cfg.BackendConfig.Schema,
+		leaders:     make(map[string]*sql.Conn),
 	}, nil
 }
 
+// qualify returns a query, written with the tables of the mcp_gateway schema, with the tables of the
+// configured schema.
+func (s *PostgresStorage) qualify(query string) string {
+	if s.schema == "" || s.schema == cfg.DefaultBackendSchema {
+		return query
+	}
+	return strings.ReplaceAll(query, cfg.DefaultBackendSchema+".", s.schema+".")
+}
+
+// Ping checks that the database is reachable.
+func (s *PostgresStorage) Ping(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 // GetDefaultScope gets the default scope from the Postgres storage.
 func (s *PostgresStorage) GetDefaultScope(_ context.Context) string {
 	return s.defaultScope
@@ -72,15 +107,24 @@ func (s *PostgresStorage) GetDefaultScope(_ context.Context) string {
 // GetProxy gets a proxy from the Postgres storage.
 func (s *PostgresStorage) GetProxy(ctx context.Context, name string, decrypt bool) (ProxyConfig, error) {
 	s.logger.Debug("GetProxy", zap.String("name", name), zap.Bool("decrypt", decrypt))
-	const q = `
+	q := s.qualify(`
 		SELECT
 			p.name,
 			p.type,
 			p.url,
 			p.timeout,
 			p.authtype,
+			p.description,
+			p.owner,
+			p.docsurl,
+			p.egressproxy,
+			p.maxresponsebytes,
+			p.responsesizepolicy,
+			p.keepaliveinterval,
 			COALESCE(ph.headers, '[]') AS headers_json,
-			po.oauth                   AS oauth_json
+			po.oauth                   AS oauth_json,
+			pm.secret                  AS hmac_secret,
+			pl.labels                  AS labels_json
 		FROM mcp_gateway.proxy p
 		LEFT JOIN LATERAL (
 			SELECT json_agg(
@@ -100,17 +144,32 @@ func (s *PostgresStorage) GetProxy(ctx context.Context, name string, decrypt boo
 			FROM mcp_gateway.proxy_oauth
 			WHERE proxyname = p.name
 		) po ON TRUE
+		LEFT JOIN mcp_gateway.proxy_hmac pm ON pm.proxyname = p.name
+		LEFT JOIN LATERAL (
+			SELECT json_object_agg(labelkey, labelvalue ORDER BY labelkey) AS labels
+			FROM mcp_gateway.proxy_label
+			WHERE proxyname = p.name
+		) pl ON TRUE
 		WHERE p.name = $1;
-	`
+	`)
 
 	var row struct {
-		Name        string
-		Type        string
-		URL         string
-		Timeout     int64
-		AuthType    string `gorm:"column:authtype"`
-		HeadersJSON []byte
-		OAuthJSON   []byte
+		Name               string
+		Type               string
+		URL                string
+		Timeout            int64
+		AuthType           string `gorm:"column:authtype"`
+		HeadersJSON        []byte
+		OAuthJSON          []byte
+		HMACSecret         sql.NullString
+		LabelsJSON         []byte
+		Description        string
+		Owner              string
+		DocsURL            string `gorm:"column:docsurl"`
+		EgressProxy        string `gorm:"column:egressproxy"`
+		MaxResponseBytes   int    `gorm:"column:maxresponsebytes"`
+		ResponseSizePolicy string `gorm:"column:responsesizepolicy"`
+		KeepaliveInterval  int64  `gorm:"column:keepaliveinterval"`
 	}
 
 	if err := s.db.WithContext(ctx).Raw(q, name).Scan(&row).Error; err != nil {
@@ -137,29 +196,55 @@ func (s *PostgresStorage) GetProxy(ctx context.Context, name string, decrypt boo
 		_ = json.Unmarshal(row.OAuthJSON, oauth)
 	}
 
+	var labels map[string]string
+	_ = json.Unmarshal(row.LabelsJSON, &labels)
+
+	hmac, err := s.proxyHMAC(row.HMACSecret, decrypt)
+	if err != nil {
+		return ProxyConfig{}, err
+	}
+
 	return ProxyConfig{
-		Name:     row.Name,
-		Type:     ProxyType(row.Type),
-		URL:      row.URL,
-		Timeout:  time.Duration(row.Timeout) * time.Second,
-		AuthType: ProxyAuthType(row.AuthType),
-		Headers:  hdrs,
-		OAuth:    oauth,
+		Name:               row.Name,
+		Type:               ProxyType(row.Type),
+		URL:                row.URL,
+		Timeout:            time.Duration(row.Timeout) * time.Second,
+		AuthType:           ProxyAuthType(row.AuthType),
+		Headers:            hdrs,
+		OAuth:              oauth,
+		HMAC:               hmac,
+		Labels:             labels,
+		Description:        row.Description,
+		Owner:              row.Owner,
+		DocsURL:            row.DocsURL,
+		EgressProxy:        row.EgressProxy,
+		MaxResponseBytes:   row.MaxResponseBytes,
+		ResponseSizePolicy: row.ResponseSizePolicy,
+		KeepaliveInterval:  time.Duration(row.KeepaliveInterval) * time.Second,
 	}, nil
 }
 
 // ListProxies lists all proxies from the Postgres storage.
 func (s *PostgresStorage) ListProxies(ctx context.Context, decrypt bool) ([]ProxyConfig, error) {
 	s.logger.Debug("ListProxies", zap.Bool("decrypt", decrypt))
-	const q = `
+	q := s.qualify(`
 		SELECT
 			p.name,
 			p.type,
 			p.url,
 			p.timeout,
 			p.authtype,
+			p.description,
+			p.owner,
+			p.docsurl,
+			p.egressproxy,
+			p.maxresponsebytes,
+			p.responsesizepolicy,
+			p.keepaliveinterval,
 			COALESCE(ph.headers, '[]')   AS headers_json,
-			po.oauth                     AS oauth_json
+			po.oauth                     AS oauth_json,
+			pm.secret                    AS hmac_secret,
+			pl.labels                    AS labels_json
 		FROM mcp_gateway.proxy p
 		LEFT JOIN LATERAL (
 			SELECT json_agg(
@@ -179,17 +264,32 @@ func (s *PostgresStorage) ListProxies(ctx context.Context, decrypt bool) ([]Prox
 			FROM mcp_gateway.proxy_oauth
 			WHERE proxyname = p.name
 		) po ON TRUE
+		LEFT JOIN mcp_gateway.proxy_hmac pm ON pm.proxyname = p.name
+		LEFT JOIN LATERAL (
+			SELECT json_object_agg(labelkey, labelvalue ORDER BY labelkey) AS labels
+			FROM mcp_gateway.proxy_label
+			WHERE proxyname = p.name
+		) pl ON TRUE
 		ORDER BY p.name;
-	`
+	`)
 
 	type row struct {
-		Name        string
-		Type        string
-		URL         string
-		Timeout     int64
-		AuthType    string
-		HeadersJSON []byte
-		OAuthJSON   []byte
+		Name               string
+		Type               string
+		URL                string
+		Timeout            int64
+		AuthType           string
+		HeadersJSON        []byte
+		OAuthJSON          []byte
+		HMACSecret         sql.NullString
+		LabelsJSON         []byte
+		Description        string
+		Owner              string
+		DocsURL            string `gorm:"column:docsurl"`
+		EgressProxy        string `gorm:"column:egressproxy"`
+		MaxResponseBytes   int    `gorm:"column:maxresponsebytes"`
+		ResponseSizePolicy string `gorm:"column:responsesizepolicy"`
+		KeepaliveInterval  int64  `gorm:"column:keepaliveinterval"`
 	}
 
 	var rows []row
@@ -208,14 +308,31 @@ func (s *PostgresStorage) ListProxies(ctx context.Context, decrypt bool) ([]Prox
 			_ = json.Unmarshal(r.OAuthJSON, oauth)
 		}
 
+		var labels map[string]string
+		_ = json.Unmarshal(r.LabelsJSON, &labels)
+
+		hmac, err := s.proxyHMAC(r.HMACSecret, decrypt)
+		if err != nil {
+			return nil, err
+		}
+
 		out = append(out, ProxyConfig{
-			Name:     r.Name,
-			Type:     ProxyType(r.Type),
-			URL:      r.URL,
-			Timeout:  time.Duration(r.Timeout) * time.Second,
-			AuthType: ProxyAuthType(r.AuthType),
-			Headers:  hdrs,
-			OAuth:    oauth,
+			Name:               r.Name,
+			Type:               ProxyType(r.Type),
+			URL:                r.URL,
+			Timeout:            time.Duration(r.Timeout) * time.Second,
+			AuthType:           ProxyAuthType(r.AuthType),
+			Headers:            hdrs,
+			OAuth:              oauth,
+			HMAC:               hmac,
+			Labels:             labels,
+			Description:        r.Description,
+			Owner:              r.Owner,
+			DocsURL:            r.DocsURL,
+			EgressProxy:        r.EgressProxy,
+			MaxResponseBytes:   r.MaxResponseBytes,
+			ResponseSizePolicy: r.ResponseSizePolicy,
+			KeepaliveInterval:  time.Duration(r.KeepaliveInterval) * time.Second,
 		})
 	}
 
@@ -244,6 +361,21 @@ func (s *PostgresStorage) decryptHeaders(headers []ProxyHeader) ([]ProxyHeader,
 	return headers, nil
 }
 
+// proxyHMAC returns the HMAC settings of a proxy from its stored secret, nil if the proxy has none.
+func (s *PostgresStorage) proxyHMAC(secret sql.NullString, decrypt bool) (*ProxyHMAC, error) {
+	if !secret.Valid {
+		return nil, nil
+	}
+	if !decrypt {
+		return &ProxyHMAC{Secret: secret.String}, nil
+	}
+	value, err := s.decryptIfNeeded(secret.String)
+	if err != nil {
+		return nil, err
+	}
+	return &ProxyHMAC{Secret: value}, nil
+}
+
 // SetProxy sets a proxy in the Postgres storage.
 func (s *PostgresStorage) SetProxy(ctx context.Context, p *ProxyConfig, encrypt bool) error {
 	s.logger.Debug("SetProxy", zap.Any("proxy", p.Name), zap.Bool("encrypt", encrypt))
@@ -259,18 +391,35 @@ func (s *PostgresStorage) SetProxy(ctx context.Context, p *ProxyConfig, encrypt
 			}
 			p.Headers[i].Value = value
 		}
+		if p.HMAC != nil {
+			secret, err := s.encryptIfNeeded(p.HMAC.Secret)
+			if err != nil {
+				return err
+			}
+			p.HMAC = &ProxyHMAC{Secret: secret}
+		}
 	}
 
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Exec(`
-			INSERT INTO mcp_gateway.proxy (name, type, url, timeout, authtype)
-			VALUES ($1,$2,$3,$4,$5)
+		if err := tx.Exec(s.qualify(`
+			INSERT INTO mcp_gateway.proxy (name, type, url, timeout, authtype, description, owner, docsurl, egressproxy,
+			    maxresponsebytes, responsesizepolicy, keepaliveinterval)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
 			ON CONFLICT (name) DO UPDATE SET
-			    type     = EXCLUDED.type,
-			    url      = EXCLUDED.url,
-			    timeout  = EXCLUDED.timeout,
-			    authtype = EXCLUDED.authtype
-		`, p.Name, string(p.Type), p.URL, int64(p.Timeout/time.Second), string(p.AuthType)).Error; err != nil {
+			    type               = EXCLUDED.type,
+			    url                = EXCLUDED.url,
+			    timeout            = EXCLUDED.timeout,
+			    authtype           = EXCLUDED.authtype,
+			    description        = EXCLUDED.description,
+			    owner              = EXCLUDED.owner,
+			    docsurl            = EXCLUDED.docsurl,
+			    egressproxy        = EXCLUDED.egressproxy,
+			    maxresponsebytes   = EXCLUDED.maxresponsebytes,
+			    responsesizepolicy = EXCLUDED.responsesizepolicy,
+			    keepaliveinterval  = EXCLUDED.keepaliveinterval
+		`), p.Name, string(p.Type), p.URL, int64(p.Timeout/time.Second), string(p.AuthType),
+			p.Description, p.Owner, p.DocsURL, p.EgressProxy, p.MaxResponseBytes, p.ResponseSizePolicy,
+			int64(p.KeepaliveInterval/time.Second)).Error; err != nil {
 			return err
 		}
 
@@ -280,7 +429,7 @@ func (s *PostgresStorage) SetProxy(ctx context.Context, p *ProxyConfig, encrypt
 			keys[i], values[i] = h.Key, h.Value
 		}
 
-		if err := tx.Exec(`
+		if err := tx.Exec(s.qualify(`
 			WITH data AS (
 				SELECT
 					$1::text AS proxyname,
@@ -296,12 +445,50 @@ func (s *PostgresStorage) SetProxy(ctx context.Context, p *ProxyConfig, encrypt
 			DELETE FROM mcp_gateway.proxy_header
 			WHERE proxyname = $1
 			  AND headerkey NOT IN (SELECT headerkey FROM up)
-		`, p.Name, pq.Array(keys), pq.Array(values)).Error; err != nil {
+		`), p.Name, pq.Array(keys), pq.Array(values)).Error; err != nil {
+			return err
+		}
+
+		labelKeys := make([]string, 0, len(p.Labels))
+		labelValues := make([]string, 0, len(p.Labels))
+		for key, value := range p.Labels {
+			labelKeys, labelValues = append(labelKeys, key), append(labelValues, value)
+		}
+
+		if err := tx.Exec(s.qualify(`
+			WITH data AS (
+				SELECT
+					$1::text AS proxyname,
+					unnest(COALESCE($2::text[], ARRAY[]::text[])) AS labelkey,
+					unnest(COALESCE($3::text[], ARRAY[]::text[])) AS labelvalue
+			), up AS (
+				INSERT INTO mcp_gateway.proxy_label (proxyname, labelkey, labelvalue)
+				SELECT proxyname, labelkey, labelvalue FROM data
+				ON CONFLICT (proxyname, labelkey)
+				     DO UPDATE SET labelvalue = EXCLUDED.labelvalue
+				RETURNING labelkey
+			)
+			DELETE FROM mcp_gateway.proxy_label
+			WHERE proxyname = $1
+			  AND labelkey NOT IN (SELECT labelkey FROM up)
+		`), p.Name, pq.Array(labelKeys), pq.Array(labelValues)).Error; err != nil {
+			return err
+		}
+
+		if p.HMAC != nil {
+			if err := tx.Exec(s.qualify(`
+				INSERT INTO mcp_gateway.proxy_hmac (proxyname, secret)
+				VALUES ($1,$2)
+				ON CONFLICT (proxyname) DO UPDATE SET secret = EXCLUDED.secret
+			`), p.Name, p.HMAC.Secret).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Exec(s.qualify(`DELETE FROM mcp_gateway.proxy_hmac WHERE proxyname = $1`), p.Name).Error; err != nil {
 			return err
 		}
 
 		if p.OAuth != nil {
-			return tx.Exec(`
+			return tx.Exec(s.qualify(`
 				INSERT INTO mcp_gateway.proxy_oauth (proxyname, clientid, clientsecret,
 				                                     tokenendpoint, scopes)
 				VALUES ($1,$2,$3,$4,$5)
@@ -310,21 +497,39 @@ func (s *PostgresStorage) SetProxy(ctx context.Context, p *ProxyConfig, encrypt
 				      clientsecret  = EXCLUDED.clientsecret,
 				      tokenendpoint = EXCLUDED.tokenendpoint,
 				      scopes        = EXCLUDED.scopes
-			`, p.Name, p.OAuth.ClientID, p.OAuth.ClientSecret,
+			`), p.Name, p.OAuth.ClientID, p.OAuth.ClientSecret,
 				p.OAuth.TokenEndpoint, p.OAuth.Scopes).Error
 		}
-		return tx.Exec(`DELETE FROM mcp_gateway.proxy_oauth WHERE proxyname = $1`, p.Name).Error
+		return tx.Exec(s.qualify(`DELETE FROM mcp_gateway.proxy_oauth WHERE proxyname = $1`), p.Name).Error
 	})
 }
 
 func (s *PostgresStorage) validateSetProxy(p *ProxyConfig) error {
+	if err := validateProxyName(p); err != nil {
+		return err
+	}
 	if !p.Type.IsValid() {
 		return fmt.Errorf("invalid proxy type: %s", p.Type)
 	}
-	if !p.AuthType.IsValid() {
-		return fmt.Errorf("invalid proxy auth type: %s", p.AuthType)
+	if err := validateAuth(p); err != nil {
+		return err
 	}
-	return nil
+	if err := validateSecretReferences(p); err != nil {
+		return err
+	}
+	if err := validateLabels(p.Labels); err != nil {
+		return err
+	}
+	if err := validateDocsURL(p); err != nil {
+		return err
+	}
+	if err := validateEgressProxy(p); err != nil {
+		return err
+	}
+	if err := validateResponseSize(p); err != nil {
+		return err
+	}
+	return validateKeepalive(p)
 }
 
 // DeleteProxy deletes a proxy from the Postgres storage.
@@ -336,9 +541,9 @@ func (s *PostgresStorage) DeleteProxy(ctx context.Context, proxy string) error {
 	}
 	defer tx.Rollback()
 
-	tx = tx.Exec(`
+	tx = tx.Exec(s.qualify(`
         DELETE FROM mcp_gateway.proxy WHERE name = $1
-    `, proxy)
+    `), proxy)
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -349,9 +554,10 @@ func (s *PostgresStorage) DeleteProxy(ctx context.Context, proxy string) error {
 // GetRole gets a role from the Postgres storage.
 func (s *PostgresStorage) GetRole(ctx context.Context, role string) (RoleConfig, error) {
 	s.logger.Debug("GetRole", zap.String("role", role))
-	query := `
+	query := s.qualify(`
 		SELECT 
 			r.name,
+			r.clearance,
 			rp.objecttype,
 			rp.proxyname,
 			rp.objectname
@@ -359,7 +565,7 @@ func (s *PostgresStorage) GetRole(ctx context.Context, role string) (RoleConfig,
 		LEFT JOIN mcp_gateway.role_permission rp ON r.name = rp.rolename
 		WHERE r.name = $1
 		ORDER BY rp.objecttype ASC, rp.proxyname ASC, rp.objectname ASC
-	`
+	`)
 
 	rows, err := s.db.WithContext(ctx).Raw(query, role).Rows()
 	if err != nil {
@@ -373,17 +579,17 @@ func (s *PostgresStorage) GetRole(ctx context.Context, role string) (RoleConfig,
 
 	for rows.Next() {
 		var (
-			name                          string
+			name, clearance               string
 			objectType, proxy, objectName sql.NullString
 		)
 
-		if err := rows.Scan(&name, &objectType, &proxy, &objectName); err != nil {
+		if err := rows.Scan(&name, &clearance, &objectType, &proxy, &objectName); err != nil {
 			return RoleConfig{}, err
 		}
 
 		// Fill the main data (once)
 		if firstRow {
-			result = RoleConfig{Name: name}
+			result = RoleConfig{Name: name, Clearance: Classification(clearance)}
 			firstRow = false
 		}
 
@@ -417,21 +623,27 @@ func (s *PostgresStorage) SetRole(ctx context.Context, role RoleConfig) error {
 			return fmt.Errorf("invalid object type: %s", p.ObjectType)
 		}
 	}
+	if err := validateClearance(&role); err != nil {
+		return err
+	}
+	if err := validateProxySelectors(&role); err != nil {
+		return err
+	}
 
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Exec(`
-			INSERT INTO mcp_gateway.role (name)
-			VALUES ($1)
-			ON CONFLICT (name) DO NOTHING
-		`, role.Name).Error; err != nil {
+		if err := tx.Exec(s.qualify(`
+			INSERT INTO mcp_gateway.role (name, clearance)
+			VALUES ($1, $2)
+			ON CONFLICT (name) DO UPDATE SET clearance = EXCLUDED.clearance
+		`), role.Name, string(role.Clearance)).Error; err != nil {
 			return err
 		}
 
 		if len(role.Permissions) == 0 {
-			return tx.Exec(`
+			return tx.Exec(s.qualify(`
 				DELETE FROM mcp_gateway.role_permission
 				WHERE rolename = $1
-			`, role.Name).Error
+			`), role.Name).Error
 		}
 
 		objTypes := make([]string, len(role.Permissions))
@@ -443,7 +655,7 @@ func (s *PostgresStorage) SetRole(ctx context.Context, role RoleConfig) error {
 			objNames[i] = p.ObjectName
 		}
 
-		return tx.Exec(`
+		return tx.Exec(s.qualify(`
 			WITH data AS (
 				SELECT
 					$1::varchar AS rolename,
@@ -461,7 +673,7 @@ func (s *PostgresStorage) SetRole(ctx context.Context, role RoleConfig) error {
 			WHERE rolename = $1
 			  AND (objecttype, proxyname, objectname)
 			      NOT IN (SELECT objecttype, proxyname, objectname FROM up)
-		`, role.Name,
+		`), role.Name,
 			pq.Array(objTypes), pq.Array(proxies), pq.Array(objNames)).Error
 	})
 }
@@ -475,7 +687,7 @@ func (s *PostgresStorage) DeleteRole(ctx context.Context, role string) error {
 	}
 	defer tx.Rollback()
 
-	tx = tx.Exec(`DELETE FROM mcp_gateway.role WHERE name = $1`, role)
+	tx = tx.Exec(s.qualify(`DELETE FROM mcp_gateway.role WHERE name = $1`), role)
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -485,28 +697,45 @@ func (s *PostgresStorage) DeleteRole(ctx context.Context, role string) error {
 
 func (s *PostgresStorage) ListRoles(ctx context.Context) ([]RoleConfig, error) {
 	s.logger.Debug("ListRoles")
-	const q = `
+	return s.queryRoles(ctx, "")
+}
+
+// GetRoles gets the roles of the names from the Postgres storage, in a single query.
+func (s *PostgresStorage) GetRoles(ctx context.Context, names []string) ([]RoleConfig, error) {
+	s.logger.Debug("GetRoles", zap.Strings("roles", names))
+	if len(names) == 0 {
+		return []RoleConfig{}, nil
+	}
+	return s.queryRoles(ctx, "WHERE r.name = ANY($1)", pq.Array(names))
+}
+
+// queryRoles queries the roles matching a WHERE clause, if any, with their permissions, sorted by name.
+func (s *PostgresStorage) queryRoles(ctx context.Context, where string, args ...any) ([]RoleConfig, error) {
+	q := s.qualify(`
 		SELECT
 			r.name,
+			r.clearance,
 			COALESCE(json_agg(
 				json_build_object(
-					'objectType', rp.objecttype,
-					'proxy',      rp.proxyname,
-					'objectName', rp.objectname
+					'object_type', rp.objecttype,
+					'proxy',       rp.proxyname,
+					'object_name', rp.objectname
 				)
 				ORDER BY rp.objecttype, rp.proxyname, rp.objectname
 			) FILTER (WHERE rp.objecttype IS NOT NULL), '[]') AS perms_json
 		FROM mcp_gateway.role r
 		LEFT JOIN mcp_gateway.role_permission rp ON rp.rolename = r.name
-		GROUP BY r.name
+		` + where + `
+		GROUP BY r.name, r.clearance
 		ORDER BY r.name;
-	`
+	`)
 
 	var rows []struct {
 		Name      string
+		Clearance string
 		PermsJSON []byte
 	}
-	if err := s.db.WithContext(ctx).Raw(q).Scan(&rows).Error; err != nil {
+	if err := s.db.WithContext(ctx).Raw(q, args...).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
 
@@ -517,6 +746,7 @@ func (s *PostgresStorage) ListRoles(ctx context.Context) ([]RoleConfig, error) {
 		out = append(out, RoleConfig{
 			Name:        r.Name,
 			Permissions: perms,
+			Clearance:   Classification(r.Clearance),
 		})
 	}
 	return out, nil
@@ -526,7 +756,7 @@ func (s *PostgresStorage) ListRoles(ctx context.Context) ([]RoleConfig, error) {
 func (s *PostgresStorage) SetAttributeToRoles(ctx context.Context, at AttributeToRolesConfig) error {
 	s.logger.Debug("SetAttributeToRoles", zap.Any("attributeToRoles", at))
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		return tx.Exec(`
+		return tx.Exec(s.qualify(`
 			WITH data AS (
 				SELECT
 					$1::text  AS attributekey,
@@ -543,19 +773,19 @@ func (s *PostgresStorage) SetAttributeToRoles(ctx context.Context, at AttributeT
 			WHERE attributekey  = $1
 			  AND attributevalue = $2
 			  AND rolename NOT IN (SELECT rolename FROM up)
-		`, at.AttributeKey, at.AttributeValue, pq.Array(at.Roles)).Error
+		`), at.AttributeKey, at.AttributeValue, pq.Array(at.Roles)).Error
 	})
 }
 
 // GetAttributeToRoles gets an attribute to roles from the Postgres storage.
 func (s *PostgresStorage) GetAttributeToRoles(ctx context.Context, attributeKey, attributeValue string) (AttributeToRolesConfig, error) {
 	s.logger.Debug("GetAttributeToRoles", zap.String("attributeKey", attributeKey), zap.String("attributeValue", attributeValue))
-	query := `
+	query := s.qualify(`
 		SELECT rolename 
 		FROM mcp_gateway.attribute_to_roles 
 		WHERE attributekey = $1 AND attributevalue = $2
 		ORDER BY rolename ASC
-	`
+	`)
 
 	rows, err := s.db.WithContext(ctx).Raw(query, attributeKey, attributeValue).Rows()
 	if err != nil {
@@ -590,16 +820,45 @@ func (s *PostgresStorage) GetAttributeToRoles(ctx context.Context, attributeKey,
 // ListAttributeToRoles lists all attribute to roles from the Postgres storage.
 func (s *PostgresStorage) ListAttributeToRoles(ctx context.Context) ([]AttributeToRolesConfig, error) {
 	s.logger.Debug("ListAttributeToRoles")
-	query := `
+	query := s.qualify(`
 		SELECT attributekey, attributevalue, rolename 
 		FROM mcp_gateway.attribute_to_roles 
 		ORDER BY attributekey ASC, attributevalue ASC, rolename ASC
-	`
+	`)
 
 	rows, err := s.db.WithContext(ctx).Raw(query).Rows()
 	if err != nil {
 		return nil, err
 	}
+	return scanAttributeToRoles(rows)
+}
+
+// GetAttributeToRolesForClaims gets the mappings of the attribute pairs from the Postgres storage, in a single query.
+func (s *PostgresStorage) GetAttributeToRolesForClaims(ctx context.Context, pairs []AttributePair) ([]AttributeToRolesConfig, error) {
+	s.logger.Debug("GetAttributeToRolesForClaims", zap.Int("pairs", len(pairs)))
+	if len(pairs) == 0 {
+		return []AttributeToRolesConfig{}, nil
+	}
+	keys, values := make([]string, len(pairs)), make([]string, len(pairs))
+	for i, pair := range pairs {
+		keys[i], values[i] = pair.Key, pair.Value
+	}
+	query := s.qualify(`
+		SELECT attributekey, attributevalue, rolename
+		FROM mcp_gateway.attribute_to_roles
+		WHERE (attributekey, attributevalue) IN (SELECT * FROM unnest($1::text[], $2::text[]))
+		ORDER BY attributekey ASC, attributevalue ASC, rolename ASC
+	`)
+
+	rows, err := s.db.WithContext(ctx).Raw(query, pq.Array(keys), pq.Array(values)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	return scanAttributeToRoles(rows)
+}
+
+// scanAttributeToRoles groups the rows of attribute key, value and role, sorted by attribute, by mapping.
+func scanAttributeToRoles(rows *sql.Rows) ([]AttributeToRolesConfig, error) {
 	defer rows.Close() //nolint:errcheck // no need to check the error here
 
 	var attributeToRoles []AttributeToRolesConfig
@@ -657,10 +916,10 @@ func (s *PostgresStorage) DeleteAttributeToRoles(ctx context.Context, attributeK
 	}
 	defer tx.Rollback()
 
-	tx = tx.Exec(`
+	tx = tx.Exec(s.qualify(`
 		DELETE FROM mcp_gateway.attribute_to_roles 
 		WHERE attributekey = $1 AND attributevalue = $2
-	`, attributeKey, attributeValue)
+	`), attributeKey, attributeValue)
 
 	if tx.Error != nil {
 		return tx.Error
@@ -669,6 +928,1005 @@ func (s *PostgresStorage) DeleteAttributeToRoles(ctx context.Context, attributeK
 	return tx.Commit().Error
 }
 
+// RecordToolCall records a tool call in the Postgres storage, and adds it to its daily rollup.
+func (s *PostgresStorage) RecordToolCall(ctx context.Context, record ToolCallRecord) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(s.qualify(`
+			INSERT INTO mcp_gateway.tool_call (proxyname, toolname, identity, iserror, calledat, durationms)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`), record.Proxy, record.Tool, record.Identity, record.IsError, record.CalledAt, record.Duration.Milliseconds()).Error; err != nil {
+			return err
+		}
+		var errorCount int64
+		if record.IsError {
+			errorCount = 1
+		}
+		return tx.Exec(s.qualify(`
+			INSERT INTO mcp_gateway.usage_daily AS u (day, identity, proxyname, toolname, calls, errors, durationms)
+			VALUES ($1::date, $2, $3, $4, 1, $5, $6)
+			ON CONFLICT (day, identity, proxyname, toolname) DO UPDATE SET
+				calls      = u.calls + 1,
+				errors     = u.errors + EXCLUDED.errors,
+				durationms = u.durationms + EXCLUDED.durationms
+		`), record.CalledAt.UTC().Format(DayFormat), record.Identity, record.Proxy, record.Tool,
+			errorCount, record.Duration.Milliseconds()).Error
+	})
+}
+
+// GetDailyUsage lists the daily rollups of the Postgres storage.
+func (s *PostgresStorage) GetDailyUsage(ctx context.Context, from, to time.Time) ([]DailyUsage, error) {
+	s.logger.Debug("GetDailyUsage", zap.Time("from", from), zap.Time("to", to))
+	var rows []struct {
+		Day        string
+		Identity   string
+		ProxyName  string `gorm:"column:proxyname"`
+		ToolName   string `gorm:"column:toolname"`
+		Calls      int64
+		Errors     int64
+		DurationMs int64 `gorm:"column:durationms"`
+	}
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT to_char(day, 'YYYY-MM-DD') AS day, identity, proxyname, toolname, calls, errors, durationms
+		FROM mcp_gateway.usage_daily
+		WHERE day BETWEEN $1::date AND $2::date
+		ORDER BY day, identity, proxyname, toolname
+	`), from.UTC().Format(DayFormat), to.UTC().Format(DayFormat)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	usage := make([]DailyUsage, 0, len(rows))
+	for _, row := range rows {
+		usage = append(usage, DailyUsage{
+			Day:        row.Day,
+			Identity:   row.Identity,
+			Proxy:      row.ProxyName,
+			Tool:       row.ToolName,
+			Calls:      row.Calls,
+			Errors:     row.Errors,
+			DurationMs: row.DurationMs,
+		})
+	}
+	return usage, nil
+}
+
+// GetUsageStats summarizes the tool calls recorded in the Postgres storage.
+func (s *PostgresStorage) GetUsageStats(ctx context.Context, since time.Time, limit int) (UsageStats, error) {
+	s.logger.Debug("GetUsageStats", zap.Time("since", since), zap.Int("limit", limit))
+	stats := UsageStats{Since: since}
+	db := s.db.WithContext(ctx)
+
+	var totals struct {
+		Calls  int64
+		Errors int64
+	}
+	if err := db.Raw(s.qualify(`
+		SELECT
+			COUNT(*)                           AS calls,
+			COUNT(*) FILTER (WHERE iserror)    AS errors
+		FROM mcp_gateway.tool_call
+		WHERE calledat >= $1
+	`), since).Scan(&totals).Error; err != nil {
+		return UsageStats{}, err
+	}
+	stats.TotalCalls = totals.Calls
+	stats.ErrorCalls = totals.Errors
+	stats.ErrorRate = errorRate(totals.Errors, totals.Calls)
+
+	var tools []struct {
+		ProxyName string `gorm:"column:proxyname"`
+		ToolName  string `gorm:"column:toolname"`
+		Calls     int64
+		Errors    int64
+	}
+	if err := db.Raw(s.qualify(`
+		SELECT
+			proxyname,
+			toolname,
+			COUNT(*)                           AS calls,
+			COUNT(*) FILTER (WHERE iserror)    AS errors
+		FROM mcp_gateway.tool_call
+		WHERE calledat >= $1
+		GROUP BY proxyname, toolname
+		ORDER BY calls DESC, proxyname, toolname
+		LIMIT $2
+	`), since, limit).Scan(&tools).Error; err != nil {
+		return UsageStats{}, err
+	}
+	stats.TopTools = make([]ToolUsage, 0, len(tools))
+	for _, t := range tools {
+		stats.TopTools = append(stats.TopTools, ToolUsage{
+			Proxy:     t.ProxyName,
+			Tool:      t.ToolName,
+			Calls:     t.Calls,
+			Errors:    t.Errors,
+			ErrorRate: errorRate(t.Errors, t.Calls),
+		})
+	}
+
+	var identities []struct {
+		Identity string
+		Calls    int64
+		Errors   int64
+	}
+	if err := db.Raw(s.qualify(`
+		SELECT
+			identity,
+			COUNT(*)                           AS calls,
+			COUNT(*) FILTER (WHERE iserror)    AS errors
+		FROM mcp_gateway.tool_call
+		WHERE calledat >= $1
+		GROUP BY identity
+		ORDER BY calls DESC, identity
+		LIMIT $2
+	`), since, limit).Scan(&identities).Error; err != nil {
+		return UsageStats{}, err
+	}
+	stats.TopIdentities = make([]IdentityUsage, 0, len(identities))
+	for _, i := range identities {
+		stats.TopIdentities = append(stats.TopIdentities, IdentityUsage{
+			Identity:  i.Identity,
+			Calls:     i.Calls,
+			Errors:    i.Errors,
+			ErrorRate: errorRate(i.Errors, i.Calls),
+		})
+	}
+
+	return stats, nil
+}
+
+// quotaRow is a row of the quota table.
+type quotaRow struct {
+	Name        string
+	SubjectType string `gorm:"column:subjecttype"`
+	Subject     string
+	ProxyName   string `gorm:"column:proxyname"`
+	ToolName    string `gorm:"column:toolname"`
+	QuotaWindow string `gorm:"column:quotawindow"`
+	QuotaLimit  int64  `gorm:"column:quotalimit"`
+}
+
+func (r *quotaRow) toConfig() QuotaConfig {
+	return QuotaConfig{
+		Name:        r.Name,
+		SubjectType: QuotaSubjectType(r.SubjectType),
+		Subject:     r.Subject,
+		Proxy:       r.ProxyName,
+		Tool:        r.ToolName,
+		Window:      QuotaWindow(r.QuotaWindow),
+		Limit:       r.QuotaLimit,
+	}
+}
+
+const traceColumns = `id, proxyname, toolname, identity, iserror, calledat, durationns, authns, storagens, upstreamns, arguments, resultsize`
+
+type traceRow struct {
+	ID         string
+	ProxyName  string `gorm:"column:proxyname"`
+	ToolName   string `gorm:"column:toolname"`
+	Identity   string
+	IsError    bool      `gorm:"column:iserror"`
+	CalledAt   time.Time `gorm:"column:calledat"`
+	DurationNs int64     `gorm:"column:durationns"`
+	AuthNs     int64     `gorm:"column:authns"`
+	StorageNs  int64     `gorm:"column:storagens"`
+	UpstreamNs int64     `gorm:"column:upstreamns"`
+	Arguments  string
+	ResultSize int `gorm:"column:resultsize"`
+}
+
+func (r *traceRow) toTrace() ToolCallTrace {
+	trace := ToolCallTrace{
+		ID:               r.ID,
+		Proxy:            r.ProxyName,
+		Tool:             r.ToolName,
+		Identity:         r.Identity,
+		IsError:          r.IsError,
+		CalledAt:         r.CalledAt.UTC(),
+		Duration:         time.Duration(r.DurationNs),
+		AuthDuration:     time.Duration(r.AuthNs),
+		StorageDuration:  time.Duration(r.StorageNs),
+		UpstreamDuration: time.Duration(r.UpstreamNs),
+		ResultSize:       r.ResultSize,
+	}
+	if r.Arguments != "" {
+		trace.Arguments = json.RawMessage(r.Arguments)
+	}
+	return trace
+}
+
+// RecordTrace records the trace of a tool call in the Postgres storage.
+func (s *PostgresStorage) RecordTrace(ctx context.Context, trace ToolCallTrace) error {
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		INSERT INTO mcp_gateway.tool_call_trace (`+traceColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`), trace.ID, trace.Proxy, trace.Tool, trace.Identity, trace.IsError, trace.CalledAt, int64(trace.Duration),
+		int64(trace.AuthDuration), int64(trace.StorageDuration), int64(trace.UpstreamDuration), string(trace.Arguments),
+		trace.ResultSize).Error
+}
+
+// GetTrace gets the trace of a tool call from the Postgres storage.
+func (s *PostgresStorage) GetTrace(ctx context.Context, id string) (ToolCallTrace, error) {
+	var rows []traceRow
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT `+traceColumns+`
+		FROM mcp_gateway.tool_call_trace
+		WHERE id = $1
+	`), id).Scan(&rows).Error; err != nil {
+		return ToolCallTrace{}, err
+	}
+	if len(rows) == 0 {
+		return ToolCallTrace{}, fmt.Errorf("trace not found")
+	}
+	return rows[0].toTrace(), nil
+}
+
+// ListTraces lists the traces of the tool calls from the Postgres storage, the latest first.
+func (s *PostgresStorage) ListTraces(ctx context.Context, filter TraceFilter) ([]ToolCallTrace, error) {
+	s.logger.Debug("ListTraces", zap.Any("filter", filter))
+	var since, until *time.Time
+	if !filter.Since.IsZero() {
+		since = &filter.Since
+	}
+	if !filter.Until.IsZero() {
+		until = &filter.Until
+	}
+	var rows []traceRow
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT `+traceColumns+`
+		FROM mcp_gateway.tool_call_trace
+		WHERE ($1 = '' OR proxyname = $1)
+		AND ($2 = '' OR toolname = $2)
+		AND ($3 = '' OR identity = $3)
+		AND (NOT $4 OR iserror)
+		AND ($5::timestamptz IS NULL OR calledat >= $5)
+		AND ($6::timestamptz IS NULL OR calledat < $6)
+		ORDER BY calledat DESC
+		LIMIT NULLIF($7, 0)
+	`), filter.Proxy, filter.Tool, filter.Identity, filter.ErrorsOnly, since, until, filter.Limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	traces := make([]ToolCallTrace, 0, len(rows))
+	for _, row := range rows {
+		traces = append(traces, row.toTrace())
+	}
+	return traces, nil
+}
+
+// PurgeTraces deletes the traces of the tool calls made before the given time from the Postgres storage.
+func (s *PostgresStorage) PurgeTraces(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Exec(s.qualify(`DELETE FROM mcp_gateway.tool_call_trace WHERE calledat < $1`), before)
+	return result.RowsAffected, result.Error
+}
+
+// ListQuotas lists all quotas from the Postgres storage.
+func (s *PostgresStorage) ListQuotas(ctx context.Context) ([]QuotaConfig, error) {
+	s.logger.Debug("ListQuotas")
+	var rows []quotaRow
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT name, subjecttype, subject, proxyname, toolname, quotawindow, quotalimit
+		FROM mcp_gateway.quota
+		ORDER BY name
+	`)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	quotas := make([]QuotaConfig, 0, len(rows))
+	for _, row := range rows {
+		quotas = append(quotas, row.toConfig())
+	}
+	return quotas, nil
+}
+
+// SetQuota creates or updates a quota in the Postgres storage. The usage is kept.
+func (s *PostgresStorage) SetQuota(ctx context.Context, quota QuotaConfig) error {
+	s.logger.Debug("SetQuota", zap.Any("quota", quota))
+	if err := quota.Validate(); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		INSERT INTO mcp_gateway.quota (name, subjecttype, subject, proxyname, toolname, quotawindow, quotalimit)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (name) DO UPDATE SET
+			subjecttype = EXCLUDED.subjecttype,
+			subject     = EXCLUDED.subject,
+			proxyname   = EXCLUDED.proxyname,
+			toolname    = EXCLUDED.toolname,
+			quotawindow = EXCLUDED.quotawindow,
+			quotalimit  = EXCLUDED.quotalimit
+	`), quota.Name, quota.SubjectType, quota.Subject, quota.Proxy, quota.Tool, quota.Window, quota.Limit).Error
+}
+
+// GetQuota gets a quota from the Postgres storage.
+func (s *PostgresStorage) GetQuota(ctx context.Context, name string) (QuotaConfig, error) {
+	s.logger.Debug("GetQuota", zap.String("quota", name))
+	var rows []quotaRow
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT name, subjecttype, subject, proxyname, toolname, quotawindow, quotalimit
+		FROM mcp_gateway.quota
+		WHERE name = $1
+	`), name).Scan(&rows).Error; err != nil {
+		return QuotaConfig{}, err
+	}
+	if len(rows) == 0 {
+		return QuotaConfig{}, fmt.Errorf("quota not found")
+	}
+	return rows[0].toConfig(), nil
+}
+
+// DeleteQuota deletes a quota and its usage from the Postgres storage.
+func (s *PostgresStorage) DeleteQuota(ctx context.Context, name string) error {
+	s.logger.Debug("DeleteQuota", zap.String("quota", name))
+	return s.db.WithContext(ctx).Exec(s.qualify(`DELETE FROM mcp_gateway.quota WHERE name = $1`), name).Error
+}
+
+// ConsumeQuota counts a call against a quota in the Postgres storage. The counter is reset when a new window
+// starts, and only incremented under the limit, in a single statement shared by all the gateway replicas.
+func (s *PostgresStorage) ConsumeQuota(ctx context.Context, quota QuotaConfig, subject string, now time.Time) (QuotaUsage, bool, error) {
+	windowStart := quota.Window.Start(now)
+	db := s.db.WithContext(ctx)
+
+	var counted []struct {
+		WindowStart time.Time `gorm:"column:windowstart"`
+		Calls       int64
+	}
+	if err := db.Raw(s.qualify(`
+		INSERT INTO mcp_gateway.quota_usage AS u (quotaname, subject, windowstart, calls)
+		SELECT $1::text, $2::text, $3::timestamptz, 1 WHERE $4::bigint > 0
+		ON CONFLICT (quotaname, subject) DO UPDATE SET
+			windowstart = EXCLUDED.windowstart,
+			calls       = CASE WHEN u.windowstart < EXCLUDED.windowstart THEN 1 ELSE u.calls + 1 END
+		WHERE u.windowstart < EXCLUDED.windowstart OR u.calls < $4::bigint
+		RETURNING windowstart, calls
+	`), quota.Name, subject, windowStart, quota.Limit).Scan(&counted).Error; err != nil {
+		return QuotaUsage{}, false, err
+	}
+	if len(counted) > 0 {
+		return newQuotaUsage(quota, subject, windowStart, counted[0].Calls), true, nil
+	}
+
+	// The limit is reached
+	var calls []int64
+	if err := db.Raw(s.qualify(`
+		SELECT calls FROM mcp_gateway.quota_usage
+		WHERE quotaname = $1 AND subject = $2 AND windowstart = $3
+	`), quota.Name, subject, windowStart).Scan(&calls).Error; err != nil {
+		return QuotaUsage{}, false, err
+	}
+	usage := newQuotaUsage(quota, subject, windowStart, 0)
+	if len(calls) > 0 {
+		usage.Calls = calls[0]
+	}
+	return usage, false, nil
+}
+
+// ListQuotaUsage lists the usage of a quota in its current window from the Postgres storage.
+func (s *PostgresStorage) ListQuotaUsage(ctx context.Context, quota QuotaConfig, now time.Time) ([]QuotaUsage, error) {
+	s.logger.Debug("ListQuotaUsage", zap.String("quota", quota.Name))
+	var rows []struct {
+		Subject     string
+		WindowStart time.Time `gorm:"column:windowstart"`
+		Calls       int64
+	}
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT subject, windowstart, calls
+		FROM mcp_gateway.quota_usage
+		WHERE quotaname = $1 AND windowstart = $2
+		ORDER BY subject
+	`), quota.Name, quota.Window.Start(now)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	usages := make([]QuotaUsage, 0, len(rows))
+	for _, row := range rows {
+		usages = append(usages, newQuotaUsage(quota, row.Subject, row.WindowStart.UTC(), row.Calls))
+	}
+	return usages, nil
+}
+
+// ResetQuotaUsage resets the usage of a quota in the Postgres storage.
+func (s *PostgresStorage) ResetQuotaUsage(ctx context.Context, quota, subject string) error {
+	s.logger.Debug("ResetQuotaUsage", zap.String("quota", quota), zap.String("subject", subject))
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		DELETE FROM mcp_gateway.quota_usage
+		WHERE quotaname = $1 AND ($2 = '' OR subject = $2)
+	`), quota, subject).Error
+}
+
+// ListToolCosts lists all tool costs from the Postgres storage.
+func (s *PostgresStorage) ListToolCosts(ctx context.Context) ([]ToolCostConfig, error) {
+	s.logger.Debug("ListToolCosts")
+	var rows []struct {
+		ProxyName string `gorm:"column:proxyname"`
+		ToolName  string `gorm:"column:toolname"`
+		Cost      int64
+	}
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT proxyname, toolname, cost
+		FROM mcp_gateway.tool_cost
+		ORDER BY proxyname, toolname
+	`)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	costs := make([]ToolCostConfig, 0, len(rows))
+	for _, row := range rows {
+		costs = append(costs, ToolCostConfig{Proxy: row.ProxyName, Tool: row.ToolName, Cost: row.Cost})
+	}
+	return costs, nil
+}
+
+// SetToolCost creates or updates a tool cost in the Postgres storage.
+func (s *PostgresStorage) SetToolCost(ctx context.Context, cost ToolCostConfig) error {
+	s.logger.Debug("SetToolCost", zap.Any("cost", cost))
+	if err := cost.Validate(); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		INSERT INTO mcp_gateway.tool_cost (proxyname, toolname, cost)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (proxyname, toolname) DO UPDATE SET cost = EXCLUDED.cost
+	`), cost.Proxy, cost.Tool, cost.Cost).Error
+}
+
+// DeleteToolCost deletes a tool cost from the Postgres storage.
+func (s *PostgresStorage) DeleteToolCost(ctx context.Context, proxy, tool string) error {
+	s.logger.Debug("DeleteToolCost", zap.String("proxy", proxy), zap.String("tool", tool))
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		DELETE FROM mcp_gateway.tool_cost WHERE proxyname = $1 AND toolname = $2
+	`), proxy, tool).Error
+}
+
+type budgetRow struct {
+	Name         string
+	RoleName     string `gorm:"column:rolename"`
+	BudgetWindow string `gorm:"column:budgetwindow"`
+	BudgetLimit  int64  `gorm:"column:budgetlimit"`
+	Action       string
+}
+
+func (r *budgetRow) toConfig() BudgetConfig {
+	return BudgetConfig{
+		Name:   r.Name,
+		Role:   r.RoleName,
+		Window: QuotaWindow(r.BudgetWindow),
+		Limit:  r.BudgetLimit,
+		Action: BudgetAction(r.Action),
+	}
+}
+
+// ListBudgets lists all budgets from the Postgres storage.
+func (s *PostgresStorage) ListBudgets(ctx context.Context) ([]BudgetConfig, error) {
+	s.logger.Debug("ListBudgets")
+	var rows []budgetRow
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT name, rolename, budgetwindow, budgetlimit, action
+		FROM mcp_gateway.budget
+		ORDER BY name
+	`)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	budgets := make([]BudgetConfig, 0, len(rows))
+	for _, row := range rows {
+		budgets = append(budgets, row.toConfig())
+	}
+	return budgets, nil
+}
+
+// SetBudget creates or updates a budget in the Postgres storage. The usage is kept.
+func (s *PostgresStorage) SetBudget(ctx context.Context, budget BudgetConfig) error {
+	s.logger.Debug("SetBudget", zap.Any("budget", budget))
+	if err := budget.Validate(); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		INSERT INTO mcp_gateway.budget (name, rolename, budgetwindow, budgetlimit, action)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO UPDATE SET
+			rolename     = EXCLUDED.rolename,
+			budgetwindow = EXCLUDED.budgetwindow,
+			budgetlimit  = EXCLUDED.budgetlimit,
+			action       = EXCLUDED.action
+	`), budget.Name, budget.Role, budget.Window, budget.Limit, budget.Action).Error
+}
+
+// GetBudget gets a budget from the Postgres storage.
+func (s *PostgresStorage) GetBudget(ctx context.Context, name string) (BudgetConfig, error) {
+	s.logger.Debug("GetBudget", zap.String("budget", name))
+	var rows []budgetRow
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT name, rolename, budgetwindow, budgetlimit, action
+		FROM mcp_gateway.budget
+		WHERE name = $1
+	`), name).Scan(&rows).Error; err != nil {
+		return BudgetConfig{}, err
+	}
+	if len(rows) == 0 {
+		return BudgetConfig{}, fmt.Errorf("budget not found")
+	}
+	return rows[0].toConfig(), nil
+}
+
+// DeleteBudget deletes a budget and its usage from the Postgres storage.
+func (s *PostgresStorage) DeleteBudget(ctx context.Context, name string) error {
+	s.logger.Debug("DeleteBudget", zap.String("budget", name))
+	return s.db.WithContext(ctx).Exec(s.qualify(`DELETE FROM mcp_gateway.budget WHERE name = $1`), name).Error
+}
+
+// ConsumeBudget counts the cost of a call against a budget in the Postgres storage. The consumption is reset
+// when a new window starts, and a deny budget is only charged what it can cover, in a single statement shared
+// by all the gateway replicas.
+func (s *PostgresStorage) ConsumeBudget(ctx context.Context, budget BudgetConfig, cost int64, now time.Time) (BudgetUsage, bool, error) {
+	windowStart := budget.Window.Start(now)
+	db := s.db.WithContext(ctx)
+
+	var counted []int64
+	if err := db.Raw(s.qualify(`
+		INSERT INTO mcp_gateway.budget_usage AS u (budgetname, windowstart, consumed)
+		SELECT $1::text, $2::timestamptz, $3::bigint WHERE $5::boolean OR $3::bigint <= $4::bigint
+		ON CONFLICT (budgetname) DO UPDATE SET
+			windowstart = EXCLUDED.windowstart,
+			consumed    = CASE WHEN u.windowstart < EXCLUDED.windowstart THEN 0 ELSE u.consumed END + $3::bigint
+		WHERE $5::boolean
+			OR CASE WHEN u.windowstart < EXCLUDED.windowstart THEN 0 ELSE u.consumed END + $3::bigint <= $4::bigint
+		RETURNING consumed
+	`), budget.Name, windowStart, cost, budget.Limit, budget.Action == BudgetActionWarn).Scan(&counted).Error; err != nil {
+		return BudgetUsage{}, false, err
+	}
+	if len(counted) > 0 {
+		usage := newBudgetUsage(budget, windowStart, counted[0])
+		// A warn budget is charged past its limit
+		return usage, usage.Consumed <= budget.Limit, nil
+	}
+
+	// The budget can not cover the cost
+	usage, err := s.GetBudgetUsage(ctx, budget, now)
+	return usage, false, err
+}
+
+// GetBudgetUsage returns the usage of a budget in its current window from the Postgres storage.
+func (s *PostgresStorage) GetBudgetUsage(ctx context.Context, budget BudgetConfig, now time.Time) (BudgetUsage, error) {
+	windowStart := budget.Window.Start(now)
+	var consumed []int64
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT consumed FROM mcp_gateway.budget_usage
+		WHERE budgetname = $1 AND windowstart = $2
+	`), budget.Name, windowStart).Scan(&consumed).Error; err != nil {
+		return BudgetUsage{}, err
+	}
+	if len(consumed) == 0 {
+		return newBudgetUsage(budget, windowStart, 0), nil
+	}
+	return newBudgetUsage(budget, windowStart, consumed[0]), nil
+}
+
+// ResetBudgetUsage resets the usage of a budget in the Postgres storage.
+func (s *PostgresStorage) ResetBudgetUsage(ctx context.Context, name string) error {
+	s.logger.Debug("ResetBudgetUsage", zap.String("budget", name))
+	return s.db.WithContext(ctx).Exec(s.qualify(`DELETE FROM mcp_gateway.budget_usage WHERE budgetname = $1`), name).Error
+}
+
+// ListToolPolicies lists all tool policies from the Postgres storage.
+func (s *PostgresStorage) ListToolPolicies(ctx context.Context) ([]ToolPolicyConfig, error) {
+	s.logger.Debug("ListToolPolicies")
+	var rows []struct {
+		ProxyName        string `gorm:"column:proxyname"`
+		ToolName         string `gorm:"column:toolname"`
+		RequiresApproval bool   `gorm:"column:requiresapproval"`
+	}
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT proxyname, toolname, requiresapproval
+		FROM mcp_gateway.tool_policy
+		ORDER BY proxyname, toolname
+	`)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	policies := make([]ToolPolicyConfig, 0, len(rows))
+	for _, row := range rows {
+		policies = append(policies, ToolPolicyConfig{Proxy: row.ProxyName, Tool: row.ToolName, RequiresApproval: row.RequiresApproval})
+	}
+	return policies, nil
+}
+
+// SetToolPolicy creates or updates a tool policy in the Postgres storage.
+func (s *PostgresStorage) SetToolPolicy(ctx context.Context, policy ToolPolicyConfig) error {
+	s.logger.Debug("SetToolPolicy", zap.Any("policy", policy))
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		INSERT INTO mcp_gateway.tool_policy (proxyname, toolname, requiresapproval)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (proxyname, toolname) DO UPDATE SET requiresapproval = EXCLUDED.requiresapproval
+	`), policy.Proxy, policy.Tool, policy.RequiresApproval).Error
+}
+
+// DeleteToolPolicy deletes a tool policy from the Postgres storage.
+func (s *PostgresStorage) DeleteToolPolicy(ctx context.Context, proxy, tool string) error {
+	s.logger.Debug("DeleteToolPolicy", zap.String("proxy", proxy), zap.String("tool", tool))
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		DELETE FROM mcp_gateway.tool_policy WHERE proxyname = $1 AND toolname = $2
+	`), proxy, tool).Error
+}
+
+// ListClassifications lists all classification labels from the Postgres storage.
+func (s *PostgresStorage) ListClassifications(ctx context.Context) ([]ClassificationConfig, error) {
+	s.logger.Debug("ListClassifications")
+	var rows []struct {
+		ProxyName string `gorm:"column:proxyname"`
+		ToolName  string `gorm:"column:toolname"`
+		Level     string `gorm:"column:level"`
+	}
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT proxyname, toolname, level
+		FROM mcp_gateway.classification
+		ORDER BY proxyname, toolname
+	`)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	classifications := make([]ClassificationConfig, 0, len(rows))
+	for _, row := range rows {
+		classifications = append(classifications, ClassificationConfig{
+			Proxy: row.ProxyName,
+			Tool:  row.ToolName,
+			Level: Classification(row.Level),
+		})
+	}
+	return classifications, nil
+}
+
+// SetClassification creates or updates a classification label in the Postgres storage.
+func (s *PostgresStorage) SetClassification(ctx context.Context, classification ClassificationConfig) error {
+	s.logger.Debug("SetClassification", zap.Any("classification", classification))
+	if err := classification.Validate(); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		INSERT INTO mcp_gateway.classification (proxyname, toolname, level)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (proxyname, toolname) DO UPDATE SET level = EXCLUDED.level
+	`), classification.Proxy, classification.Tool, string(classification.Level)).Error
+}
+
+// DeleteClassification deletes a classification label from the Postgres storage.
+func (s *PostgresStorage) DeleteClassification(ctx context.Context, proxy, tool string) error {
+	s.logger.Debug("DeleteClassification", zap.String("proxy", proxy), zap.String("tool", tool))
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		DELETE FROM mcp_gateway.classification WHERE proxyname = $1 AND toolname = $2
+	`), proxy, tool).Error
+}
+
+// ListCompositeTools lists all composite tools from the Postgres storage.
+func (s *PostgresStorage) ListCompositeTools(ctx context.Context) ([]CompositeToolConfig, error) {
+	s.logger.Debug("ListCompositeTools")
+	var rows []struct {
+		Name        string
+		Description string
+		InputSchema string `gorm:"column:inputschema"`
+		Steps       string
+	}
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT name, description, inputschema, steps
+		FROM mcp_gateway.composite_tool
+		ORDER BY name
+	`)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	tools := make([]CompositeToolConfig, 0, len(rows))
+	for _, row := range rows {
+		tool := CompositeToolConfig{Name: row.Name, Description: row.Description}
+		if row.InputSchema != "" {
+			tool.InputSchema = json.RawMessage(row.InputSchema)
+		}
+		if err := json.Unmarshal([]byte(row.Steps), &tool.Steps); err != nil {
+			return nil, fmt.Errorf("invalid steps of composite tool %s: %w", row.Name, err)
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// SetCompositeTool creates or updates a composite tool in the Postgres storage.
+func (s *PostgresStorage) SetCompositeTool(ctx context.Context, tool CompositeToolConfig) error {
+	s.logger.Debug("SetCompositeTool", zap.String("name", tool.Name))
+	if err := tool.Validate(); err != nil {
+		return err
+	}
+	steps, err := json.Marshal(tool.Steps)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		INSERT INTO mcp_gateway.composite_tool (name, description, inputschema, steps)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE
+		SET description = EXCLUDED.description, inputschema = EXCLUDED.inputschema, steps = EXCLUDED.steps
+	`), tool.Name, tool.Description, string(tool.InputSchema), string(steps)).Error
+}
+
+// DeleteCompositeTool deletes a composite tool from the Postgres storage.
+func (s *PostgresStorage) DeleteCompositeTool(ctx context.Context, name string) error {
+	s.logger.Debug("DeleteCompositeTool", zap.String("name", name))
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		DELETE FROM mcp_gateway.composite_tool WHERE name = $1
+	`), name).Error
+}
+
+// TryLeadership acquires the leadership of name with a session-level advisory lock, held by a dedicated
+// connection: the lock is released by Postgres if the replica dies or loses its connection. The lock is keyed by
+// the schema too, so the gateways sharing the database with other schemas elect their own leaders.
+func (s *PostgresStorage) TryLeadership(ctx context.Context, name string) (bool, error) {
+	s.leaderMu.Lock()
+	defer s.leaderMu.Unlock()
+	if conn, ok := s.leaders[name]; ok {
+		if err := conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		s.logger.Warn("Lost the connection holding the leadership", zap.String("leadership", name))
+		s.releaseLeadership(ctx, name, conn)
+	}
+
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1), hashtext($2))`,
+		s.lockSchema(), name).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		return false, err
+	}
+	if !acquired {
+		return false, conn.Close()
+	}
+	s.leaders[name] = conn
+	return true, nil
+}
+
+// ReleaseLeadership releases the advisory lock of the leadership of name, if held.
+func (s *PostgresStorage) ReleaseLeadership(ctx context.Context, name string) error {
+	s.leaderMu.Lock()
+	defer s.leaderMu.Unlock()
+	if conn, ok := s.leaders[name]; ok {
+		s.releaseLeadership(ctx, name, conn)
+	}
+	return nil
+}
+
+// releaseLeadership unlocks the advisory lock before the connection returns to the pool. If the connection is
+// broken, the lock is already released with the session.
+func (s *PostgresStorage) releaseLeadership(ctx context.Context, name string, conn *sql.Conn) {
+	_, _ = conn.ExecContext(ctx, `SELECT pg_advisory_unlock(hashtext($1), hashtext($2))`, s.lockSchema(), name)
+	_ = conn.Close()
+	delete(s.leaders, name)
+}
+
+// lockSchema returns the schema keying the advisory locks, mcp_gateway when empty like the tables.
+func (s *PostgresStorage) lockSchema() string {
+	if s.schema == "" {
+		return cfg.DefaultBackendSchema
+	}
+	return s.schema
+}
+
+// ListProxyTools lists the tools of the proxies from the Postgres storage.
+func (s *PostgresStorage) ListProxyTools(ctx context.Context) ([]ProxyTools, error) {
+	s.logger.Debug("ListProxyTools")
+	var rows []struct {
+		ProxyName         string    `gorm:"column:proxyname"`
+		Tools             string    `gorm:"column:tools"`
+		ResourceTemplates string    `gorm:"column:resourcetemplates"`
+		SyncedAt          time.Time `gorm:"column:syncedat"`
+	}
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT proxyname, tools, resourcetemplates, syncedat
+		FROM mcp_gateway.proxy_tools
+		ORDER BY proxyname
+	`)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	tools := make([]ProxyTools, 0, len(rows))
+	for _, row := range rows {
+		t := ProxyTools{Proxy: row.ProxyName, Tools: json.RawMessage(row.Tools), SyncedAt: row.SyncedAt}
+		if row.ResourceTemplates != "" {
+			t.ResourceTemplates = json.RawMessage(row.ResourceTemplates)
+		}
+		tools = append(tools, t)
+	}
+	return tools, nil
+}
+
+// SetProxyTools replaces the tools of a proxy in the Postgres storage.
+func (s *PostgresStorage) SetProxyTools(ctx context.Context, tools ProxyTools) error {
+	s.logger.Debug("SetProxyTools", zap.String("proxy", tools.Proxy))
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		INSERT INTO mcp_gateway.proxy_tools (proxyname, tools, resourcetemplates, syncedat)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (proxyname) DO UPDATE
+		SET tools = EXCLUDED.tools, resourcetemplates = EXCLUDED.resourcetemplates, syncedat = EXCLUDED.syncedat
+	`), tools.Proxy, string(tools.Tools), string(tools.ResourceTemplates), tools.SyncedAt).Error
+}
+
+const approvalColumns = `id, proxyname, toolname, identity, arguments, status, requestedat, expiresat, decidedat, decidedby, reason`
+
+type approvalRow struct {
+	ID          string
+	ProxyName   string `gorm:"column:proxyname"`
+	ToolName    string `gorm:"column:toolname"`
+	Identity    string
+	Arguments   string
+	Status      string
+	RequestedAt time.Time  `gorm:"column:requestedat"`
+	ExpiresAt   time.Time  `gorm:"column:expiresat"`
+	DecidedAt   *time.Time `gorm:"column:decidedat"`
+	DecidedBy   string     `gorm:"column:decidedby"`
+	Reason      string
+}
+
+func (r *approvalRow) toApproval() ApprovalRequest {
+	approval := ApprovalRequest{
+		ID:          r.ID,
+		Proxy:       r.ProxyName,
+		Tool:        r.ToolName,
+		Identity:    r.Identity,
+		Status:      ApprovalStatus(r.Status),
+		RequestedAt: r.RequestedAt.UTC(),
+		ExpiresAt:   r.ExpiresAt.UTC(),
+		DecidedBy:   r.DecidedBy,
+		Reason:      r.Reason,
+	}
+	if r.Arguments != "" {
+		approval.Arguments = json.RawMessage(r.Arguments)
+	}
+	if r.DecidedAt != nil {
+		decidedAt := r.DecidedAt.UTC()
+		approval.DecidedAt = &decidedAt
+	}
+	return approval
+}
+
+// CreateApproval stores a pending approval in the Postgres storage.
+func (s *PostgresStorage) CreateApproval(ctx context.Context, approval ApprovalRequest) error {
+	s.logger.Debug("CreateApproval", zap.String("approval", approval.ID))
+	return s.db.WithContext(ctx).Exec(s.qualify(`
+		INSERT INTO mcp_gateway.approval (id, proxyname, toolname, identity, arguments, status, requestedat, expiresat, decidedby, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, '', '')
+	`), approval.ID, approval.Proxy, approval.Tool, approval.Identity, string(approval.Arguments), approval.Status,
+		approval.RequestedAt, approval.ExpiresAt).Error
+}
+
+// GetApproval gets an approval from the Postgres storage.
+func (s *PostgresStorage) GetApproval(ctx context.Context, id string) (ApprovalRequest, error) {
+	var rows []approvalRow
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT `+approvalColumns+`
+		FROM mcp_gateway.approval
+		WHERE id = $1
+	`), id).Scan(&rows).Error; err != nil {
+		return ApprovalRequest{}, err
+	}
+	if len(rows) == 0 {
+		return ApprovalRequest{}, fmt.Errorf("approval not found")
+	}
+	return rows[0].toApproval(), nil
+}
+
+// ListApprovals lists the approvals from the Postgres storage, the latest first.
+func (s *PostgresStorage) ListApprovals(ctx context.Context, status ApprovalStatus) ([]ApprovalRequest, error) {
+	s.logger.Debug("ListApprovals", zap.String("status", string(status)))
+	var rows []approvalRow
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT `+approvalColumns+`
+		FROM mcp_gateway.approval
+		WHERE $1 = '' OR status = $1
+		ORDER BY requestedat DESC
+	`), status).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	approvals := make([]ApprovalRequest, 0, len(rows))
+	for _, row := range rows {
+		approvals = append(approvals, row.toApproval())
+	}
+	return approvals, nil
+}
+
+// DecideApproval records the decision of a pending approval in the Postgres storage. Only the first decision is
+// recorded when several replicas decide the approval concurrently.
+func (s *PostgresStorage) DecideApproval(ctx context.Context, id string, status ApprovalStatus, decidedBy, reason string, now time.Time) (ApprovalRequest, error) {
+	s.logger.Debug("DecideApproval", zap.String("approval", id), zap.String("status", string(status)))
+	if err := validateDecision(status); err != nil {
+		return ApprovalRequest{}, err
+	}
+	var rows []approvalRow
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		UPDATE mcp_gateway.approval
+		SET status = $2, decidedat = $3, decidedby = $4, reason = $5
+		WHERE id = $1 AND status = $6
+		RETURNING `)+approvalColumns,
+		id, status, now, decidedBy, reason, ApprovalStatusPending).Scan(&rows).Error; err != nil {
+		return ApprovalRequest{}, err
+	}
+	if len(rows) > 0 {
+		return rows[0].toApproval(), nil
+	}
+	approval, err := s.GetApproval(ctx, id)
+	if err != nil {
+		return ApprovalRequest{}, err
+	}
+	return approval, ErrApprovalDecided
+}
+
+type auditRow struct {
+	Seq        int64
+	Type       string
+	Data       string
+	RecordedAt time.Time `gorm:"column:recordedat"`
+	PrevHash   string    `gorm:"column:prevhash"`
+	Hash       string
+}
+
+func (r *auditRow) toAuditEntry() AuditEntry {
+	return AuditEntry{
+		Seq:        r.Seq,
+		Type:       r.Type,
+		Data:       json.RawMessage(r.Data),
+		RecordedAt: r.RecordedAt.UTC(),
+		PrevHash:   r.PrevHash,
+		Hash:       r.Hash,
+	}
+}
+
+// AppendAuditEntry chains a record to the end of the audit log in the Postgres storage. The table is locked
+// until the entry is inserted, so the appends of every replica are serialized.
+func (s *PostgresStorage) AppendAuditEntry(ctx context.Context, entryType string, data json.RawMessage, recordedAt time.Time) (AuditEntry, error) {
+	s.logger.Debug("AppendAuditEntry", zap.String("type", entryType))
+	var entry AuditEntry
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(s.qualify(`LOCK TABLE mcp_gateway.audit_log IN EXCLUSIVE MODE`)).Error; err != nil {
+			return err
+		}
+		var rows []auditRow
+		if err := tx.Raw(s.qualify(`
+			SELECT seq, type, data, recordedat, prevhash, hash
+			FROM mcp_gateway.audit_log
+			ORDER BY seq DESC
+			LIMIT 1
+		`)).Scan(&rows).Error; err != nil {
+			return err
+		}
+		var last *AuditEntry
+		if len(rows) > 0 {
+			lastEntry := rows[0].toAuditEntry()
+			last = &lastEntry
+		}
+		entry = newAuditEntry(last, entryType, data, recordedAt)
+		return tx.Exec(s.qualify(`
+			INSERT INTO mcp_gateway.audit_log (seq, type, data, recordedat, prevhash, hash)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`), entry.Seq, entry.Type, string(entry.Data), entry.RecordedAt, entry.PrevHash, entry.Hash).Error
+	})
+	return entry, err
+}
+
+// ListAuditEntries lists the audit entries following a sequence number from the Postgres storage.
+func (s *PostgresStorage) ListAuditEntries(ctx context.Context, after int64, limit int) ([]AuditEntry, error) {
+	s.logger.Debug("ListAuditEntries", zap.Int64("after", after), zap.Int("limit", limit))
+	var rows []auditRow
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT seq, type, data, recordedat, prevhash, hash
+		FROM mcp_gateway.audit_log
+		WHERE seq > $1
+		ORDER BY seq
+		LIMIT $2
+	`), after, limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	entries := make([]AuditEntry, 0, len(rows))
+	for i := range rows {
+		entries = append(entries, rows[i].toAuditEntry())
+	}
+	return entries, nil
+}
+
 // encryptIfNeeded encrypts a value if needed.
 func (s *PostgresStorage) encryptIfNeeded(value string) (string, error) {
 	if s.encryptor.IsEncryptedString(value) {
@@ -3,7 +3,14 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/matthisholleville/mcp-gateway/internal/cfg"
+	"github.com/matthisholleville/mcp-gateway/internal/secrets"
 )
 
 type ProxyType string
@@ -13,6 +20,7 @@ const (
 	ProxyTypeStreamableHTTP ProxyType     = "streamable-http"
 	ProxyAuthTypeHeader     ProxyAuthType = "header"
 	ProxyAuthTypeOAuth      ProxyAuthType = "oauth"
+	ProxyAuthTypeHMAC       ProxyAuthType = "hmac"
 )
 
 func (p ProxyType) IsValid() bool {
@@ -20,7 +28,7 @@ func (p ProxyType) IsValid() bool {
 }
 
 func (p ProxyAuthType) IsValid() bool {
-	return p == ProxyAuthTypeHeader || p == ProxyAuthTypeOAuth
+	return p == ProxyAuthTypeHeader || p == ProxyAuthTypeOAuth || p == ProxyAuthTypeHMAC
 }
 
 type ProxyConfig struct {
@@ -31,6 +39,25 @@ type ProxyConfig struct {
 	AuthType ProxyAuthType `json:"authType"`
 	Headers  []ProxyHeader `json:"headers"`
 	OAuth    *ProxyOAuth   `json:"oauth"`
+	HMAC     *ProxyHMAC    `json:"hmac,omitempty"`
+	// Labels are free-form key=value pairs, e.g. team=payments, to list the proxies and grant permissions on them.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Description tells what the upstream server is.
+	Description string `json:"description,omitempty"`
+	// Owner is who to contact about the upstream server, e.g. an email.
+	Owner string `json:"owner,omitempty"`
+	// DocsURL is the link to the documentation of the upstream server.
+	DocsURL string `json:"docsUrl,omitempty"`
+	// EgressProxy is the URL of the HTTP, HTTPS or SOCKS5 proxy through which the gateway connects to the
+	// upstream server, instead of the egress proxy of the gateway, or direct to connect without proxy.
+	EgressProxy string `json:"egressProxy,omitempty"`
+	// MaxResponseBytes caps the size of the tool results, instead of the maximum of the gateway, and
+	// ResponseSizePolicy tells whether the larger ones are truncated or rejected, instead of the policy of the gateway.
+	MaxResponseBytes   int    `json:"maxResponseBytes,omitempty"`
+	ResponseSizePolicy string `json:"responseSizePolicy,omitempty"`
+	// KeepaliveInterval is how long an upstream session of a client is unused before it is pinged, instead of the
+	// keepalive interval of the gateway.
+	KeepaliveInterval time.Duration `json:"keepaliveInterval,omitempty"`
 }
 
 type ProxyHeader struct {
@@ -45,6 +72,149 @@ type ProxyOAuth struct {
 	Scopes        string `json:"scopes"`
 }
 
+// ProxyHMAC is the shared secret with which the gateway signs the requests sent to the upstream server, with the
+// hmac auth type, so the server can verify they come from the gateway.
+type ProxyHMAC struct {
+	Secret string `json:"secret"`
+}
+
+// validateAuth checks that the proxy has the settings of its auth type.
+func validateAuth(p *ProxyConfig) error {
+	if !p.AuthType.IsValid() {
+		return fmt.Errorf("invalid proxy auth type: %s", p.AuthType)
+	}
+	if p.AuthType == ProxyAuthTypeHMAC && (p.HMAC == nil || p.HMAC.Secret == "") {
+		return fmt.Errorf("the hmac auth type requires a secret")
+	}
+	return nil
+}
+
+// The reserved proxies of the tools of the gateway: no proxy can be named after them, and the permissions of the
+// roles grant their tools on them.
+const (
+	// CompositeProxy is the proxy of the composite tools, exposed as composite:<name>.
+	CompositeProxy = "composite"
+	// MetaProxy is the proxy of the built-in tools describing the gateway, e.g. gateway:whoami.
+	MetaProxy = "gateway"
+)
+
+// validateProxyName checks that the proxy is not named after a reserved proxy of the tools of the gateway.
+func validateProxyName(p *ProxyConfig) error {
+	if p.Name == CompositeProxy || p.Name == MetaProxy {
+		return fmt.Errorf("proxy name %q is reserved for the tools of the gateway", p.Name)
+	}
+	return nil
+}
+
+// validateDocsURL checks that the documentation link of the proxy, if any, is an absolute http or https URL.
+func validateDocsURL(p *ProxyConfig) error {
+	if p.DocsURL == "" {
+		return nil
+	}
+	docs, err := url.Parse(p.DocsURL)
+	if err != nil || (docs.Scheme != "http" && docs.Scheme != "https") || docs.Host == "" {
+		return fmt.Errorf("invalid docs URL %q, must be an absolute http or https URL", p.DocsURL)
+	}
+	return nil
+}
+
+// validateEgressProxy checks that the egress proxy of the proxy, if any, is direct or a proxy URL.
+func validateEgressProxy(p *ProxyConfig) error {
+	if p.EgressProxy == "" || p.EgressProxy == cfg.EgressProxyDirect {
+		return nil
+	}
+	_, err := cfg.ParseEgressProxy(p.EgressProxy)
+	return err
+}
+
+// validateResponseSize checks that the maximum response size of the proxy is not negative and its policy, if
+// any, is truncate or reject.
+func validateResponseSize(p *ProxyConfig) error {
+	if p.MaxResponseBytes < 0 {
+		return fmt.Errorf("invalid max response bytes %d, must not be negative", p.MaxResponseBytes)
+	}
+	if p.ResponseSizePolicy != "" && !cfg.ValidResponseSizePolicy(p.ResponseSizePolicy) {
+		return fmt.Errorf("invalid response size policy %q, must be truncate or reject", p.ResponseSizePolicy)
+	}
+	return nil
+}
+
+// validateKeepalive checks that the keepalive interval of the proxy is not negative.
+func validateKeepalive(p *ProxyConfig) error {
+	if p.KeepaliveInterval < 0 {
+		return fmt.Errorf("invalid keepalive interval %s, must not be negative", p.KeepaliveInterval)
+	}
+	return nil
+}
+
+// ProxyLabelPrefix prefixes the proxy of the permissions granted on the proxies having some labels, e.g.
+// label:env=staging, rather than on a proxy name.
+const ProxyLabelPrefix = "label:"
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+	labelValuePattern = regexp.MustCompile(`^[A-Za-z0-9._/-]*$`)
+)
+
+// validateLabels checks that the labels can be written in a label selector.
+func validateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid label key %q, must be alphanumeric with '.', '_', '/' or '-'", key)
+		}
+		if !labelValuePattern.MatchString(value) {
+			return fmt.Errorf("invalid label value %q of %s, must be alphanumeric with '.', '_', '/' or '-'", value, key)
+		}
+	}
+	return nil
+}
+
+// ParseLabelSelector parses a comma-separated list of key=value labels, e.g. team=payments,env=staging.
+func ParseLabelSelector(selector string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, label := range strings.Split(selector, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(label), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label selector %q, must be key=value pairs separated by commas", selector)
+		}
+		labels[key] = value
+	}
+	if err := validateLabels(labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// MatchLabels reports whether the labels contain all the labels of the selector, with the same values.
+func MatchLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if actual, ok := labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// validateSecretReferences checks the syntax of the secret references stored in place of the proxy secrets.
+func validateSecretReferences(p *ProxyConfig) error {
+	for _, header := range p.Headers {
+		if err := secrets.Validate(header.Value); err != nil {
+			return fmt.Errorf("header %s: %w", header.Key, err)
+		}
+	}
+	if p.OAuth != nil {
+		if err := secrets.Validate(p.OAuth.ClientSecret); err != nil {
+			return fmt.Errorf("oauth client secret: %w", err)
+		}
+	}
+	if p.HMAC != nil {
+		if err := secrets.Validate(p.HMAC.Secret); err != nil {
+			return fmt.Errorf("hmac secret: %w", err)
+		}
+	}
+	return nil
+}
+
 type ProxyInterface interface {
 	GetProxy(ctx context.Context, proxy string, decrypt bool) (ProxyConfig, error)
 	ListProxies(ctx context.Context, decrypt bool) ([]ProxyConfig, error)
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// QuotaSubjectType is what a quota applies to: the subjects holding a role, or a subject.
+type QuotaSubjectType string
+
+const (
+	QuotaSubjectTypeRole    QuotaSubjectType = "role"
+	QuotaSubjectTypeSubject QuotaSubjectType = "subject"
+)
+
+func (t QuotaSubjectType) IsValid() bool {
+	return t == QuotaSubjectTypeRole || t == QuotaSubjectTypeSubject
+}
+
+// QuotaWindow is the period over which the calls are counted, aligned on UTC hours, days, weeks starting on
+// Monday or months.
+type QuotaWindow string
+
+const (
+	QuotaWindowHour  QuotaWindow = "hour"
+	QuotaWindowDay   QuotaWindow = "day"
+	QuotaWindowWeek  QuotaWindow = "week"
+	QuotaWindowMonth QuotaWindow = "month"
+)
+
+func (w QuotaWindow) IsValid() bool {
+	return w == QuotaWindowHour || w == QuotaWindowDay || w == QuotaWindowWeek || w == QuotaWindowMonth
+}
+
+// Start returns the start of the window containing the given time.
+func (w QuotaWindow) Start(t time.Time) time.Time {
+	t = t.UTC()
+	switch w {
+	case QuotaWindowDay:
+		return t.Truncate(24 * time.Hour)
+	case QuotaWindowWeek:
+		day := t.Truncate(24 * time.Hour)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case QuotaWindowMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return t.Truncate(time.Hour)
+	}
+}
+
+// End returns the end of the window starting at the given time, when its calls are reset.
+func (w QuotaWindow) End(start time.Time) time.Time {
+	switch w {
+	case QuotaWindowDay:
+		return start.AddDate(0, 0, 1)
+	case QuotaWindowWeek:
+		return start.AddDate(0, 0, 7)
+	case QuotaWindowMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.Add(time.Hour)
+	}
+}
+
+// QuotaConfig limits the number of tool calls of each subject over a window. A role quota limits every subject
+// holding the role separately. Subject, Proxy and Tool accept the "*" wildcard.
+type QuotaConfig struct {
+	Name        string           `json:"name"`
+	SubjectType QuotaSubjectType `json:"subject_type"`
+	// Subject is the role name or the JWT subject the quota applies to.
+	Subject string      `json:"subject"`
+	Proxy   string      `json:"proxy"`
+	Tool    string      `json:"tool"`
+	Window  QuotaWindow `json:"window"`
+	Limit   int64       `json:"limit"`
+}
+
+// Validate checks the quota before it is stored.
+func (q *QuotaConfig) Validate() error {
+	if q.Name == "" {
+		return fmt.Errorf("quota name is required")
+	}
+	if !q.SubjectType.IsValid() {
+		return fmt.Errorf("invalid quota subject type: %s", q.SubjectType)
+	}
+	if q.Subject == "" || q.Proxy == "" || q.Tool == "" {
+		return fmt.Errorf("quota subject, proxy and tool are required, use '*' to match any")
+	}
+	if !q.Window.IsValid() {
+		return fmt.Errorf("invalid quota window: %s", q.Window)
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("quota limit must not be negative")
+	}
+	return nil
+}
+
+// Matches returns true if the quota applies to a call of the tool by the subject holding the roles.
+func (q *QuotaConfig) Matches(proxy, tool, subject string, roles []string) bool {
+	if !matchQuota(q.Proxy, proxy) || !matchQuota(q.Tool, tool) {
+		return false
+	}
+	if q.SubjectType == QuotaSubjectTypeSubject {
+		return matchQuota(q.Subject, subject)
+	}
+	return (q.Subject == "*" && len(roles) > 0) || slices.Contains(roles, q.Subject)
+}
+
+func matchQuota(pattern, value string) bool {
+	return pattern == "*" || pattern == value
+}
+
+// QuotaUsage is the number of calls a subject made against a quota in the current window.
+type QuotaUsage struct {
+	Quota       string    `json:"quota"`
+	Subject     string    `json:"subject"`
+	Calls       int64     `json:"calls"`
+	Limit       int64     `json:"limit"`
+	WindowStart time.Time `json:"windowStart"`
+	ResetsAt    time.Time `json:"resetsAt"`
+}
+
+type QuotaInterface interface {
+	ListQuotas(ctx context.Context) ([]QuotaConfig, error)
+	SetQuota(ctx context.Context, quota QuotaConfig) error
+	GetQuota(ctx context.Context, name string) (QuotaConfig, error)
+	// DeleteQuota deletes a quota and its usage.
+	DeleteQuota(ctx context.Context, name string) error
+	// ConsumeQuota counts a call of the subject in the current window of the quota, unless its limit is reached.
+	// It returns the usage, and false if the call is over the limit and was not counted.
+	ConsumeQuota(ctx context.Context, quota QuotaConfig, subject string, now time.Time) (QuotaUsage, bool, error)
+	// ListQuotaUsage lists the usage of the subjects which called the quota in its current window.
+	ListQuotaUsage(ctx context.Context, quota QuotaConfig, now time.Time) ([]QuotaUsage, error)
+	// ResetQuotaUsage resets the usage of a subject, or of every subject if empty.
+	ResetQuotaUsage(ctx context.Context, quota, subject string) error
+}
+
+func newQuotaUsage(quota QuotaConfig, subject string, windowStart time.Time, calls int64) QuotaUsage {
+	return QuotaUsage{
+		Quota:       quota.Name,
+		Subject:     subject,
+		Calls:       calls,
+		Limit:       quota.Limit,
+		WindowStart: windowStart,
+		ResetsAt:    quota.Window.End(windowStart),
+	}
+}
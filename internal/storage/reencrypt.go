@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/matthisholleville/mcp-gateway/pkg/aescipher"
+	"go.uber.org/zap"
+)
+
+// ReencryptOptions configures a re-encryption.
+type ReencryptOptions struct {
+	// DryRun checks that every value can be decrypted, without writing.
+	DryRun bool
+	// BatchSize is the number of values read and written at once.
+	BatchSize int
+	// Progress, if not nil, is called after each batch with the number of values processed and the total.
+	Progress func(done, total int)
+}
+
+// ReencryptResult counts the values processed by a re-encryption.
+type ReencryptResult struct {
+	Total int `json:"total"`
+	// Reencrypted are the values decrypted and encrypted again with the current key.
+	Reencrypted int `json:"reencrypted"`
+	// Encrypted are the values stored in plaintext, now encrypted.
+	Encrypted int `json:"encrypted"`
+	// Changed are the values updated by another process during the re-encryption, left untouched.
+	Changed int `json:"changed"`
+	// Failed lists the values which cannot be decrypted with the configured keys, as "PROXY/HEADER: ERROR", or
+	// "PROXY/hmac: ERROR" for the HMAC secrets.
+	Failed []string `json:"failed"`
+}
+
+// Reencrypter is implemented by the storages encrypting their data.
+type Reencrypter interface {
+	// Reencrypt decrypts every encrypted value with the configured keys and encrypts it again with the current key,
+	// so the previous keys can be removed once it succeeds.
+	Reencrypt(ctx context.Context, opts ReencryptOptions) (ReencryptResult, error)
+}
+
+const defaultReencryptBatchSize = 100
+
+// errUndecryptable is returned for a ciphertext of a key which is not configured.
+var errUndecryptable = errors.New("the value cannot be decrypted with the configured keys")
+
+// reencryptValue returns the value encrypted with the current key, once checked that it decrypts back to
+// the value's plaintext, and whether the value was stored in plaintext.
+func reencryptValue(encryptor aescipher.Cryptor, value string) (reencrypted string, wasPlaintext bool, err error) {
+	plaintext := value
+	switch {
+	case encryptor.IsEncryptedString(value):
+		if plaintext, err = encryptor.DecryptString(value); err != nil {
+			return "", false, err
+		}
+	case aescipher.IsCiphertextString(value):
+		return "", false, errUndecryptable
+	default:
+		wasPlaintext = true
+	}
+
+	if reencrypted, err = encryptor.EncryptString(plaintext); err != nil {
+		return "", false, err
+	}
+	verified, err := encryptor.DecryptString(reencrypted)
+	if err != nil {
+		return "", false, fmt.Errorf("the re-encrypted value cannot be decrypted: %w", err)
+	}
+	if verified != plaintext {
+		return "", false, errors.New("the re-encrypted value does not decrypt to the original value")
+	}
+	return reencrypted, wasPlaintext, nil
+}
+
+// Reencrypt re-encrypts the proxy header values and HMAC secrets, in batches ordered by proxy and header. Each
+// value is only replaced if it did not change since it was read, so the gateway can keep running.
+func (s *PostgresStorage) Reencrypt(ctx context.Context, opts ReencryptOptions) (ReencryptResult, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultReencryptBatchSize
+	}
+	result := ReencryptResult{Failed: []string{}}
+
+	var total int64
+	if err := s.db.WithContext(ctx).Raw(s.qualify(`
+		SELECT (SELECT COUNT(*) FROM mcp_gateway.proxy_header) + (SELECT COUNT(*) FROM mcp_gateway.proxy_hmac)
+	`)).Scan(&total).Error; err != nil {
+		return result, err
+	}
+	result.Total = int(total)
+
+	done := 0
+	if err := s.reencryptHeaders(ctx, opts, &result, &done); err != nil {
+		return result, err
+	}
+	if err := s.reencryptHMACSecrets(ctx, opts, &result, &done); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (s *PostgresStorage) reencryptHeaders(ctx context.Context, opts ReencryptOptions, result *ReencryptResult, done *int) error {
+	type row struct {
+		ProxyName   string
+		HeaderKey   string
+		HeaderValue string
+	}
+	var lastProxy, lastKey string
+	for {
+		var rows []row
+		if err := s.db.WithContext(ctx).Raw(s.qualify(`
+			SELECT proxyname, headerkey, headervalue
+			FROM mcp_gateway.proxy_header
+			WHERE (proxyname, headerkey) > ($1, $2)
+			ORDER BY proxyname, headerkey
+			LIMIT $3
+		`), lastProxy, lastKey, opts.BatchSize).Scan(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		for _, r := range rows {
+			value, wasPlaintext, err := reencryptValue(s.encryptor, r.HeaderValue)
+			if err != nil {
+				result.Failed = append(result.Failed, fmt.Sprintf("%s/%s: %v", r.ProxyName, r.HeaderKey, err))
+				continue
+			}
+			if !opts.DryRun {
+				update := s.db.WithContext(ctx).Exec(s.qualify(`
+					UPDATE mcp_gateway.proxy_header SET headervalue = $1
+					WHERE proxyname = $2 AND headerkey = $3 AND headervalue = $4
+				`), value, r.ProxyName, r.HeaderKey, r.HeaderValue)
+				if update.Error != nil {
+					return update.Error
+				}
+				if update.RowsAffected == 0 {
+					s.logger.Warn("The header changed during the re-encryption",
+						zap.String("proxy", r.ProxyName), zap.String("header", r.HeaderKey))
+					result.Changed++
+					continue
+				}
+			}
+			result.count(wasPlaintext)
+		}
+
+		*done += len(rows)
+		lastProxy, lastKey = rows[len(rows)-1].ProxyName, rows[len(rows)-1].HeaderKey
+		if opts.Progress != nil {
+			opts.Progress(*done, max(*done, result.Total))
+		}
+	}
+}
+
+// reencryptHMACSecrets re-encrypts the HMAC secrets of the proxies, reported as "PROXY/hmac" when they fail.
+func (s *PostgresStorage) reencryptHMACSecrets(ctx context.Context, opts ReencryptOptions, result *ReencryptResult, done *int) error {
+	type row struct {
+		ProxyName string
+		Secret    string
+	}
+	var lastProxy string
+	for {
+		var rows []row
+		if err := s.db.WithContext(ctx).Raw(s.qualify(`
+			SELECT proxyname, secret
+			FROM mcp_gateway.proxy_hmac
+			WHERE proxyname > $1
+			ORDER BY proxyname
+			LIMIT $2
+		`), lastProxy, opts.BatchSize).Scan(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		for _, r := range rows {
+			value, wasPlaintext, err := reencryptValue(s.encryptor, r.Secret)
+			if err != nil {
+				result.Failed = append(result.Failed, fmt.Sprintf("%s/hmac: %v", r.ProxyName, err))
+				continue
+			}
+			if !opts.DryRun {
+				update := s.db.WithContext(ctx).Exec(s.qualify(`
+					UPDATE mcp_gateway.proxy_hmac SET secret = $1
+					WHERE proxyname = $2 AND secret = $3
+				`), value, r.ProxyName, r.Secret)
+				if update.Error != nil {
+					return update.Error
+				}
+				if update.RowsAffected == 0 {
+					s.logger.Warn("The HMAC secret changed during the re-encryption", zap.String("proxy", r.ProxyName))
+					result.Changed++
+					continue
+				}
+			}
+			result.count(wasPlaintext)
+		}
+
+		*done += len(rows)
+		lastProxy = rows[len(rows)-1].ProxyName
+		if opts.Progress != nil {
+			opts.Progress(*done, max(*done, result.Total))
+		}
+	}
+}
+
+// count counts a value re-encrypted, or encrypted if it was stored in plaintext.
+func (r *ReencryptResult) count(wasPlaintext bool) {
+	if wasPlaintext {
+		r.Encrypted++
+	} else {
+		r.Reencrypted++
+	}
+}
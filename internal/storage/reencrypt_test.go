@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/matthisholleville/mcp-gateway/pkg/aescipher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReencryptValue(t *testing.T) {
+	oldKey := aescipher.Key{ID: "old", Key: "0123456789abcdeffedcba9876543210cafebabefacefeeddeadbeef00112233"}
+	newKey := aescipher.Key{ID: "new", Key: "fedcba98765432100123456789abcdefdeadbeeffacefeedcafebabe33221100"}
+	previous, _ := aescipher.NewKeyring(oldKey)
+	current, _ := aescipher.NewKeyring(newKey)
+	keyring, _ := aescipher.NewKeyring(newKey, oldKey)
+	encrypted, _ := previous.EncryptString("secret")
+
+	value, wasPlaintext, err := reencryptValue(keyring, encrypted)
+	require.NoError(t, err)
+	assert.False(t, wasPlaintext)
+	plaintext, err := current.DecryptString(value)
+	require.NoError(t, err, "the value must be encrypted with the current key")
+	assert.Equal(t, "secret", plaintext)
+
+	value, wasPlaintext, err = reencryptValue(keyring, "Bearer token")
+	require.NoError(t, err)
+	assert.True(t, wasPlaintext)
+	plaintext, _ = current.DecryptString(value)
+	assert.Equal(t, "Bearer token", plaintext)
+
+	_, _, err = reencryptValue(current, encrypted)
+	assert.ErrorIs(t, err, errUndecryptable, "a ciphertext of an unknown key must not be taken for a plaintext")
+}
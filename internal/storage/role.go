@@ -1,32 +1,65 @@
 package storage
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
 
 type RoleConfig struct {
 	Name        string             `json:"name"`
 	Permissions []PermissionConfig `json:"permissions"`
+	// Clearance is the highest classification level of the tools the role may call, public if empty.
+	Clearance Classification `json:"clearance,omitempty"`
+}
+
+// validateClearance checks the clearance of the role, which may be empty.
+func validateClearance(role *RoleConfig) error {
+	if role.Clearance != "" && !role.Clearance.IsValid() {
+		return fmt.Errorf("invalid clearance %q, must be one of public, internal or confidential", role.Clearance)
+	}
+	return nil
+}
+
+// validateProxySelectors checks the label selectors of the permissions granted on the proxies having some labels.
+func validateProxySelectors(role *RoleConfig) error {
+	for _, permission := range role.Permissions {
+		if selector, ok := strings.CutPrefix(permission.Proxy, ProxyLabelPrefix); ok {
+			if _, err := ParseLabelSelector(selector); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 type ObjectType string
 
 const (
 	ObjectTypeTools ObjectType = "tools"
-	ObjectTypeAll   ObjectType = "*"
+	// ObjectTypeResources grants the reads of the templated resources, named by their URI without the proxy prefix.
+	ObjectTypeResources ObjectType = "resources"
+	// ObjectTypePrompts grants the argument completions of the prompts, named without the proxy prefix.
+	ObjectTypePrompts ObjectType = "prompts"
+	ObjectTypeAll     ObjectType = "*"
 )
 
 func (o ObjectType) IsValid() bool {
-	return o == ObjectTypeTools || o == ObjectTypeAll
+	return o == ObjectTypeTools || o == ObjectTypeResources || o == ObjectTypePrompts || o == ObjectTypeAll
 }
 
 type PermissionConfig struct {
 	ObjectType ObjectType `json:"object_type"`
-	Proxy      string     `json:"proxy"`
-	ObjectName string     `json:"object_name"`
+	// Proxy is a proxy name, * for all the proxies, or label:KEY=VALUE[,KEY=VALUE] for the proxies having the labels.
+	Proxy      string `json:"proxy"`
+	ObjectName string `json:"object_name"`
 }
 
 type RoleInterface interface {
 	ListRoles(ctx context.Context) ([]RoleConfig, error)
 	SetRole(ctx context.Context, role RoleConfig) error
 	GetRole(ctx context.Context, role string) (RoleConfig, error)
+	// GetRoles gets the roles of the names in a single lookup, sorted by name. The unknown names are skipped.
+	GetRoles(ctx context.Context, names []string) ([]RoleConfig, error)
 	DeleteRole(ctx context.Context, role string) error
 }
@@ -28,6 +28,15 @@ type Interface interface {
 	ProxyInterface
 	RoleInterface
 	AttributeToRolesInterface
+	UsageInterface
+	TraceInterface
+	QuotaInterface
+	BudgetInterface
+	ApprovalInterface
+	AuditInterface
+	ClassificationInterface
+	CompositeToolInterface
+	LeaderInterface
 }
 
 // NewStorage creates a new storage instance.
@@ -17,9 +17,9 @@ import (
 	"github.com/docker/go-connections/nat"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file" // need to import the file source
-	_ "github.com/jackc/pgx/v5/stdlib"                   // need to import the PostgreSQL driver.
-	_ "github.com/lib/pq"                                // need to import the PostgreSQL driver.
+	_ "github.com/jackc/pgx/v5/stdlib" // need to import the PostgreSQL driver.
+	_ "github.com/lib/pq"              // need to import the PostgreSQL driver.
+	"github.com/matthisholleville/mcp-gateway/internal/storage/utils"
 	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/require"
 )
@@ -32,6 +32,8 @@ const (
 // PostgresTestContainerOptions are the options for the PostgresTestContainer.
 type PostgresTestContainerOptions struct {
 	MigrationsDir string
+	// Schema is the schema of the tables created by the migrations, mcp_gateway when empty.
+	Schema string
 }
 
 // PostgresTestContainer is a test container for Postgres.
@@ -196,9 +198,13 @@ func (p *PostgresTestContainer) executeMigrationsIfNeeded(t testing.TB, db *sql.
 		t.Logf("executing migrations from %s", p.opts.MigrationsDir)
 		driver, err := postgres.WithInstance(db, &postgres.Config{})
 		require.NoError(t, err)
-		migrateInstance, err := migrate.NewWithDatabaseInstance(
-			fmt.Sprintf("file://%s", p.opts.MigrationsDir),
-			"postgres", driver)
+		schema := p.opts.Schema
+		if schema == "" {
+			schema = "mcp_gateway"
+		}
+		src, err := utils.OpenMigrations(p.opts.MigrationsDir, schema)
+		require.NoError(t, err)
+		migrateInstance, err := migrate.NewWithInstance("file", src, "postgres", driver)
 		require.NoError(t, err)
 		err = migrateInstance.Up()
 		require.NoError(t, err)
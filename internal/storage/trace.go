@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ToolCallTrace is the detailed trace of a tool call, kept for the retention of the traces for the postmortems.
+type ToolCallTrace struct {
+	// ID is the correlation ID of the call, found in its logs.
+	ID       string    `json:"id"`
+	Proxy    string    `json:"proxy"`
+	Tool     string    `json:"tool"`
+	Identity string    `json:"identity"`
+	IsError  bool      `json:"isError"`
+	CalledAt time.Time `json:"calledAt"`
+	// Duration is how long the call took from the start of its request.
+	Duration time.Duration `json:"duration"`
+	// AuthDuration is the time spent verifying the token and the permissions of the request.
+	AuthDuration time.Duration `json:"authDuration"`
+	// StorageDuration is the time spent counting the request against the quotas and budgets.
+	StorageDuration time.Duration `json:"storageDuration"`
+	// UpstreamDuration is the time spent waiting for the upstream server.
+	UpstreamDuration time.Duration `json:"upstreamDuration"`
+	// Arguments are the arguments of the call, redacted like in the logs.
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	// ResultSize is the size of the JSON result, in bytes.
+	ResultSize int `json:"resultSize"`
+}
+
+// TraceFilter selects the traces to list. The empty fields select all the traces.
+type TraceFilter struct {
+	Proxy    string
+	Tool     string
+	Identity string
+	// ErrorsOnly selects the failed calls only.
+	ErrorsOnly bool
+	// Since and Until bound the time of the calls, Until excluded.
+	Since time.Time
+	Until time.Time
+	// Limit is the maximum number of traces, unlimited if 0.
+	Limit int
+}
+
+// matches returns true if the filter selects the trace.
+func (f *TraceFilter) matches(trace *ToolCallTrace) bool {
+	return (f.Proxy == "" || trace.Proxy == f.Proxy) &&
+		(f.Tool == "" || trace.Tool == f.Tool) &&
+		(f.Identity == "" || trace.Identity == f.Identity) &&
+		(!f.ErrorsOnly || trace.IsError) &&
+		(f.Since.IsZero() || !trace.CalledAt.Before(f.Since)) &&
+		(f.Until.IsZero() || trace.CalledAt.Before(f.Until))
+}
+
+type TraceInterface interface {
+	RecordTrace(ctx context.Context, trace ToolCallTrace) error
+	GetTrace(ctx context.Context, id string) (ToolCallTrace, error)
+	// ListTraces lists the traces selected by the filter, the latest first.
+	ListTraces(ctx context.Context, filter TraceFilter) ([]ToolCallTrace, error)
+	// PurgeTraces deletes the traces of the calls made before the given time, and returns how many were deleted.
+	PurgeTraces(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// UsageRetention is how long the tool calls are kept by the memory storage.
+const UsageRetention = 30 * 24 * time.Hour
+
+// ToolCallRecord is a tool call handled by the gateway.
+type ToolCallRecord struct {
+	Proxy    string    `json:"proxy"`
+	Tool     string    `json:"tool"`
+	Identity string    `json:"identity"`
+	IsError  bool      `json:"isError"`
+	CalledAt time.Time `json:"calledAt"`
+	// Duration is how long the call took, 0 if unknown.
+	Duration time.Duration `json:"duration"`
+}
+
+// DailyUsage rolls up the calls of an identity to a tool over a UTC day, for the chargeback.
+type DailyUsage struct {
+	// Day is the UTC day of the calls, as YYYY-MM-DD.
+	Day        string `json:"day"`
+	Identity   string `json:"identity"`
+	Proxy      string `json:"proxy"`
+	Tool       string `json:"tool"`
+	Calls      int64  `json:"calls"`
+	Errors     int64  `json:"errors"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// DayFormat is the format of the days of the usage rollups.
+const DayFormat = "2006-01-02"
+
+// UsageStats summarizes the tool calls over a window.
+type UsageStats struct {
+	Since         time.Time       `json:"since"`
+	TotalCalls    int64           `json:"totalCalls"`
+	ErrorCalls    int64           `json:"errorCalls"`
+	ErrorRate     float64         `json:"errorRate"`
+	TopTools      []ToolUsage     `json:"topTools"`
+	TopIdentities []IdentityUsage `json:"topIdentities"`
+}
+
+// ToolUsage is the number of calls of a tool.
+type ToolUsage struct {
+	Proxy     string  `json:"proxy"`
+	Tool      string  `json:"tool"`
+	Calls     int64   `json:"calls"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+}
+
+// IdentityUsage is the number of calls made by an identity.
+type IdentityUsage struct {
+	Identity  string  `json:"identity"`
+	Calls     int64   `json:"calls"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+}
+
+type UsageInterface interface {
+	RecordToolCall(ctx context.Context, record ToolCallRecord) error
+	// GetUsageStats summarizes the calls made since the given time, with at most limit top tools and identities.
+	GetUsageStats(ctx context.Context, since time.Time, limit int) (UsageStats, error)
+	// GetDailyUsage lists the daily rollups of the UTC days from and to, both included, by day, identity, proxy and tool.
+	GetDailyUsage(ctx context.Context, from, to time.Time) ([]DailyUsage, error)
+}
+
+func errorRate(errors, calls int64) float64 {
+	if calls == 0 {
+		return 0
+	}
+	return float64(errors) / float64(calls)
+}
+
+// summarizeUsage builds the usage stats from the raw tool calls.
+func summarizeUsage(records []ToolCallRecord, since time.Time, limit int) UsageStats {
+	stats := UsageStats{Since: since}
+	tools := make(map[[2]string]*ToolUsage)
+	identities := make(map[string]*IdentityUsage)
+
+	for _, r := range records {
+		if r.CalledAt.Before(since) {
+			continue
+		}
+		var errorCount int64
+		if r.IsError {
+			errorCount = 1
+		}
+		stats.TotalCalls++
+		stats.ErrorCalls += errorCount
+
+		toolKey := [2]string{r.Proxy, r.Tool}
+		tool, ok := tools[toolKey]
+		if !ok {
+			tool = &ToolUsage{Proxy: r.Proxy, Tool: r.Tool}
+			tools[toolKey] = tool
+		}
+		tool.Calls++
+		tool.Errors += errorCount
+
+		identity, ok := identities[r.Identity]
+		if !ok {
+			identity = &IdentityUsage{Identity: r.Identity}
+			identities[r.Identity] = identity
+		}
+		identity.Calls++
+		identity.Errors += errorCount
+	}
+	stats.ErrorRate = errorRate(stats.ErrorCalls, stats.TotalCalls)
+
+	stats.TopTools = make([]ToolUsage, 0, len(tools))
+	for _, tool := range tools {
+		tool.ErrorRate = errorRate(tool.Errors, tool.Calls)
+		stats.TopTools = append(stats.TopTools, *tool)
+	}
+	sort.Slice(stats.TopTools, func(i, j int) bool {
+		a, b := stats.TopTools[i], stats.TopTools[j]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		if a.Proxy != b.Proxy {
+			return a.Proxy < b.Proxy
+		}
+		return a.Tool < b.Tool
+	})
+
+	stats.TopIdentities = make([]IdentityUsage, 0, len(identities))
+	for _, identity := range identities {
+		identity.ErrorRate = errorRate(identity.Errors, identity.Calls)
+		stats.TopIdentities = append(stats.TopIdentities, *identity)
+	}
+	sort.Slice(stats.TopIdentities, func(i, j int) bool {
+		a, b := stats.TopIdentities[i], stats.TopIdentities[j]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return a.Identity < b.Identity
+	})
+
+	if limit > 0 {
+		stats.TopTools = stats.TopTools[:min(limit, len(stats.TopTools))]
+		stats.TopIdentities = stats.TopIdentities[:min(limit, len(stats.TopIdentities))]
+	}
+	return stats
+}
+
+// sortDailyUsage sorts the rollups by day, identity, proxy and tool.
+func sortDailyUsage(usage []DailyUsage) {
+	sort.Slice(usage, func(i, j int) bool {
+		a, b := usage[i], usage[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Identity != b.Identity {
+			return a.Identity < b.Identity
+		}
+		if a.Proxy != b.Proxy {
+			return a.Proxy < b.Proxy
+		}
+		return a.Tool < b.Tool
+	})
+}
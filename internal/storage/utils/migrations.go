@@ -0,0 +1,60 @@
+package utils //nolint:revive // this is a utility package and we need to keep the package name short
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file" // import file source
+)
+
+// OpenMigrations opens the migrations of a directory, written as templates referencing the schema of the
+// tables as {{.Schema}}, rendered with the given schema when read.
+func OpenMigrations(dir, schema string) (source.Driver, error) {
+	driver, err := source.Open("file://" + dir)
+	if err != nil {
+		return nil, err
+	}
+	return &schemaMigrations{Driver: driver, schema: schema}, nil
+}
+
+// schemaMigrations renders the migrations of a source with a schema.
+type schemaMigrations struct {
+	source.Driver
+	schema string
+}
+
+func (m *schemaMigrations) ReadUp(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := m.Driver.ReadUp(version)
+	if err != nil {
+		return nil, identifier, err
+	}
+	return m.render(r, identifier)
+}
+
+func (m *schemaMigrations) ReadDown(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := m.Driver.ReadDown(version)
+	if err != nil {
+		return nil, identifier, err
+	}
+	return m.render(r, identifier)
+}
+
+func (m *schemaMigrations) render(r io.ReadCloser, identifier string) (io.ReadCloser, string, error) {
+	defer r.Close() //nolint:errcheck // nothing interesting to do with the error
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return nil, identifier, err
+	}
+	tmpl, err := template.New(identifier).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, identifier, fmt.Errorf("parse migration %s: %w", identifier, err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, struct{ Schema string }{m.schema}); err != nil {
+		return nil, identifier, fmt.Errorf("render migration %s: %w", identifier, err)
+	}
+	return io.NopCloser(&rendered), identifier, nil
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenMigrations(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "000001_proxy.up.sql"),
+		[]byte("CREATE TABLE {{.Schema}}.proxy (name TEXT);"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "000001_proxy.down.sql"),
+		[]byte("DROP TABLE {{.Schema}}.proxy;"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "000002_invalid.up.sql"),
+		[]byte("SELECT {{.Table}};"), 0o600))
+
+	src, err := OpenMigrations(dir, "team_gateway")
+	require.NoError(t, err)
+	defer src.Close() //nolint:errcheck // nothing interesting to do with the error
+
+	read := func(r io.ReadCloser, _ string, err error) string {
+		require.NoError(t, err)
+		text, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(text)
+	}
+	assert.Equal(t, "CREATE TABLE team_gateway.proxy (name TEXT);", read(src.ReadUp(1)))
+	assert.Equal(t, "DROP TABLE team_gateway.proxy;", read(src.ReadDown(1)))
+
+	_, _, err = src.ReadUp(2)
+	assert.ErrorContains(t, err, "render migration")
+	_, _, err = src.ReadDown(2)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
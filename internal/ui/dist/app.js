@@ -0,0 +1,227 @@
+"use strict";
+
+const API = "/v1/admin";
+const storageKey = "mcp-gateway-api-key";
+const $ = (selector, root = document) => root.querySelector(selector);
+
+function apiKey() {
+  return sessionStorage.getItem(storageKey);
+}
+
+function showError(message) {
+  const error = $("#error");
+  error.textContent = message;
+  error.hidden = !message;
+}
+
+async function call(method, path, body) {
+  const response = await fetch(API + path, {
+    method,
+    headers: { "X-API-Key": apiKey(), "Content-Type": "application/json" },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (response.status === 401) {
+    signOut();
+    throw new Error("Invalid API key");
+  }
+  const text = await response.text();
+  const data = text ? JSON.parse(text) : null;
+  if (!response.ok) {
+    throw new Error((data && (data.error || data.message)) || response.statusText);
+  }
+  return data;
+}
+
+function cell(row, content) {
+  const td = document.createElement("td");
+  if (content instanceof Node) {
+    td.appendChild(content);
+  } else {
+    td.textContent = content;
+  }
+  row.appendChild(td);
+  return td;
+}
+
+function deleteButton(label, onConfirm) {
+  const button = document.createElement("button");
+  button.textContent = "Delete";
+  button.addEventListener("click", async () => {
+    if (!confirm(`Delete ${label}?`)) {
+      return;
+    }
+    try {
+      await onConfirm();
+      await refresh();
+    } catch (err) {
+      showError(err.message);
+    }
+  });
+  return button;
+}
+
+async function proxyHealth(name) {
+  const span = document.createElement("span");
+  try {
+    const tools = await call("GET", `/proxies/${encodeURIComponent(name)}/tools`);
+    if (tools.syncedAt) {
+      span.className = "ok";
+      span.textContent = `${tools.tools.length} tools, synced ${new Date(tools.syncedAt).toLocaleTimeString()}`;
+    } else {
+      span.className = "ko";
+      span.textContent = "not synced";
+    }
+  } catch (err) {
+    span.className = "ko";
+    span.textContent = err.message;
+  }
+  return span;
+}
+
+async function loadProxies() {
+  const proxies = (await call("GET", "/proxies")) || [];
+  const tbody = $("#proxies tbody");
+  tbody.replaceChildren();
+  proxies.sort((a, b) => a.name.localeCompare(b.name));
+  for (const proxy of proxies) {
+    const row = document.createElement("tr");
+    cell(row, proxy.name);
+    cell(row, proxy.url);
+    cell(row, proxy.authType);
+    // The API returns the timeout in nanoseconds.
+    cell(row, `${proxy.timeout / 1e9}s`);
+    const health = cell(row, "…");
+    cell(row, deleteButton(`proxy ${proxy.name}`, () => call("DELETE", `/proxies/${encodeURIComponent(proxy.name)}`)));
+    tbody.appendChild(row);
+    proxyHealth(proxy.name).then((span) => health.replaceChildren(span));
+  }
+}
+
+async function loadRoles() {
+  const roles = (await call("GET", "/roles")) || [];
+  const tbody = $("#roles tbody");
+  tbody.replaceChildren();
+  roles.sort((a, b) => a.name.localeCompare(b.name));
+  for (const role of roles) {
+    const row = document.createElement("tr");
+    cell(row, role.name);
+    cell(row, (role.permissions || []).map((p) => `${p.object_type} ${p.proxy} ${p.object_name}`).join("\n"));
+    cell(row, deleteButton(`role ${role.name}`, () => call("DELETE", `/roles/${encodeURIComponent(role.name)}`)));
+    tbody.appendChild(row);
+  }
+}
+
+async function loadMappings() {
+  const mappings = (await call("GET", "/attribute-to-roles")) || [];
+  const tbody = $("#mappings tbody");
+  tbody.replaceChildren();
+  for (const mapping of mappings) {
+    const row = document.createElement("tr");
+    cell(row, mapping.attribute_key);
+    cell(row, mapping.attribute_value);
+    cell(row, (mapping.roles || []).join(", "));
+    const path = `/attribute-to-roles/${encodeURIComponent(mapping.attribute_key)}/${encodeURIComponent(mapping.attribute_value)}`;
+    cell(row, deleteButton(`mapping ${mapping.attribute_key}=${mapping.attribute_value}`, () => call("DELETE", path)));
+    tbody.appendChild(row);
+  }
+}
+
+async function refresh() {
+  showError("");
+  try {
+    await Promise.all([loadProxies(), loadRoles(), loadMappings()]);
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+function onSubmit(selector, toRequest) {
+  $(selector).addEventListener("submit", async (event) => {
+    event.preventDefault();
+    const form = event.target;
+    try {
+      const [method, path, body] = toRequest(new FormData(form));
+      await call(method, path, body);
+      form.reset();
+      await refresh();
+    } catch (err) {
+      showError(err.message);
+    }
+  });
+}
+
+onSubmit("#proxy-form", (data) => {
+  const headers = data.get("headers").split("\n").filter((line) => line.includes(":")).map((line) => {
+    const index = line.indexOf(":");
+    return { key: line.slice(0, index).trim(), value: line.slice(index + 1).trim() };
+  });
+  const name = data.get("name").trim();
+  return ["PUT", `/proxies/${encodeURIComponent(name)}`, {
+    name,
+    type: "streamable-http",
+    url: data.get("url").trim(),
+    // The API expects the timeout in seconds.
+    timeout: Number(data.get("timeout")),
+    authType: data.get("authType"),
+    headers,
+  }];
+});
+
+onSubmit("#role-form", (data) => {
+  const permissions = data.get("permissions").split("\n").map((line) => line.trim()).filter(Boolean).map((line) => {
+    const [objectType, proxy, objectName] = line.split(/\s+/);
+    if (!objectName) {
+      throw new Error(`Invalid permission "${line}"`);
+    }
+    return { object_type: objectType, proxy, object_name: objectName };
+  });
+  return ["PUT", "/roles", { name: data.get("name").trim(), permissions }];
+});
+
+onSubmit("#mapping-form", (data) => ["PUT", "/attribute-to-roles", {
+  attribute_key: data.get("attributeKey").trim(),
+  attribute_value: data.get("attributeValue").trim(),
+  roles: data.get("roles").split(",").map((role) => role.trim()).filter(Boolean),
+}]);
+
+function showTab(name) {
+  for (const button of document.querySelectorAll("#tabs [data-tab]")) {
+    button.classList.toggle("active", button.dataset.tab === name);
+  }
+  for (const section of document.querySelectorAll(".tab")) {
+    section.hidden = section.id !== name;
+  }
+}
+
+function signIn() {
+  $("#login").hidden = true;
+  $("#tabs").hidden = false;
+  showTab("proxies");
+  refresh();
+}
+
+function signOut() {
+  sessionStorage.removeItem(storageKey);
+  $("#login").hidden = false;
+  $("#tabs").hidden = true;
+  for (const section of document.querySelectorAll(".tab")) {
+    section.hidden = true;
+  }
+}
+
+$("#login-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  sessionStorage.setItem(storageKey, new FormData(event.target).get("apiKey"));
+  event.target.reset();
+  signIn();
+});
+
+$("#logout").addEventListener("click", signOut);
+
+for (const button of document.querySelectorAll("#tabs [data-tab]")) {
+  button.addEventListener("click", () => showTab(button.dataset.tab));
+}
+
+if (apiKey()) {
+  signIn();
+}
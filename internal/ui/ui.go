@@ -0,0 +1,20 @@
+// Package ui embeds the web admin UI of the MCP Gateway.
+package ui
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed dist
+var dist embed.FS
+
+// FS returns the static files of the admin UI.
+func FS() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		// dist is embedded at build time, so this can only fail on a programming error.
+		panic(err)
+	}
+	return sub
+}
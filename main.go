@@ -5,8 +5,20 @@ import (
 	"os"
 
 	"github.com/matthisholleville/mcp-gateway/cmd"
+	"github.com/matthisholleville/mcp-gateway/cmd/apply"
+	"github.com/matthisholleville/mcp-gateway/cmd/audit"
+	"github.com/matthisholleville/mcp-gateway/cmd/config"
+	"github.com/matthisholleville/mcp-gateway/cmd/doctor"
+	"github.com/matthisholleville/mcp-gateway/cmd/genkey"
+	"github.com/matthisholleville/mcp-gateway/cmd/hashkey"
+	"github.com/matthisholleville/mcp-gateway/cmd/mapping"
 	"github.com/matthisholleville/mcp-gateway/cmd/migrate"
+	"github.com/matthisholleville/mcp-gateway/cmd/reencrypt"
+	"github.com/matthisholleville/mcp-gateway/cmd/role"
+	"github.com/matthisholleville/mcp-gateway/cmd/seed"
 	"github.com/matthisholleville/mcp-gateway/cmd/serve"
+	"github.com/matthisholleville/mcp-gateway/cmd/snapshot"
+	"github.com/matthisholleville/mcp-gateway/cmd/version"
 )
 
 func main() {
@@ -14,6 +26,19 @@ func main() {
 
 	rootCmd.AddCommand(serve.NewRunCommand())
 	rootCmd.AddCommand(migrate.NewMigrateCommand())
+	rootCmd.AddCommand(role.NewRoleCommand())
+	rootCmd.AddCommand(mapping.NewMappingCommand())
+	rootCmd.AddCommand(config.NewConfigCommand())
+	rootCmd.AddCommand(apply.NewApplyCommand())
+	rootCmd.AddCommand(version.NewVersionCommand())
+	rootCmd.AddCommand(genkey.NewGenKeyCommand())
+	rootCmd.AddCommand(hashkey.NewHashKeyCommand())
+	rootCmd.AddCommand(reencrypt.NewReencryptCommand())
+	rootCmd.AddCommand(audit.NewAuditCommand())
+	rootCmd.AddCommand(doctor.NewDoctorCommand())
+	rootCmd.AddCommand(seed.NewSeedCommand())
+	rootCmd.AddCommand(snapshot.NewExportCommand())
+	rootCmd.AddCommand(snapshot.NewImportCommand())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -4,38 +4,110 @@ package aescipher
 
 import (
 	"bytes"
-	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
-	"io"
+	"fmt"
+	"strings"
 )
 
 const (
 	// NonceSizeGCM is the recommended size for AES-GCM nonces.
-	NonceSizeGCM  = 12
-	versionPrefix = "v1" // 2-byte marker identifying ciphertexts of this library
+	NonceSizeGCM       = 12
+	versionPrefix      = "v1" // 2-byte marker identifying ciphertexts of this library
+	versionPrefixKeyID = "v2" // 2-byte marker identifying ciphertexts embedding the ID of their key
+	maxKeyIDLength     = 255  // the length of the key ID is encoded on a byte
 )
 
 // Cryptor defines the minimal interface for an authenticated symmetric cipher.
 type Cryptor interface {
 	Encrypt(plaintext []byte) ([]byte, error)
 	Decrypt(ciphertext []byte) ([]byte, error)
-
 	IsEncryptedString(b64 string) bool
 	EncryptString(plain string) (string, error)
 	DecryptString(b64 string) (string, error)
 }
 
-type gcmCryptor struct {
+// Key is a hex-encoded AES key, identified by an ID embedded in its ciphertexts.
+type Key struct {
+	ID  string
+	Key string
+}
+
+// ParseKey parses a key written as "ID:HEXKEY".
+func ParseKey(s string) (Key, error) {
+	id, key, ok := strings.Cut(s, ":")
+	if !ok || id == "" {
+		return Key{}, errors.New("aescipher: key must be written as ID:HEXKEY")
+	}
+	return Key{ID: id, Key: key}, nil
+}
+
+type keyAEAD struct {
+	id   string
 	aead cipher.AEAD
 }
 
+type gcmCryptor struct {
+	// keys decrypt the ciphertexts, the first one encrypts.
+	keys []keyAEAD
+}
+
+// GenerateKey returns a random hex-encoded key of size bytes, which must be 16, 24, or 32.
+func GenerateKey(size int) (string, error) {
+	if size != 16 && size != 24 && size != 32 {
+		return "", errors.New("aescipher: key length must be 16, 24, or 32 bytes")
+	}
+	key := make([]byte, size)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
 // New returns a Cryptor backed by AES-GCM.
 // Key must be 16, 24, or 32 bytes long (hex-encoded).
 func New(key string) (Cryptor, error) {
+	return NewKeyring(Key{Key: key})
+}
+
+// NewKeyring returns a Cryptor backed by the AES-GCM of the standard backend, see NewKeyringWithBackend.
+func NewKeyring(keys ...Key) (Cryptor, error) {
+	return NewKeyringWithBackend(standardBackend{}, keys...)
+}
+
+// NewKeyringWithBackend returns a Cryptor backed by the AES-GCM of backend, encrypting with the first key and decrypting with any of them,
+// so the keys can be rotated without downtime: the new key is put first and the previous ones are kept.
+// The ciphertexts of a key with an ID embed it; those of a key without ID are decrypted by trying every key.
+func NewKeyringWithBackend(backend Backend, keys ...Key) (Cryptor, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("aescipher: at least one key is required")
+	}
+	ids := make(map[string]bool, len(keys))
+	g := &gcmCryptor{keys: make([]keyAEAD, 0, len(keys))}
+	for _, key := range keys {
+		if len(key.ID) > maxKeyIDLength {
+			return nil, fmt.Errorf("aescipher: key ID %q is longer than %d bytes", key.ID, maxKeyIDLength)
+		}
+		if ids[key.ID] {
+			return nil, fmt.Errorf("aescipher: key ID %q is used more than once", key.ID)
+		}
+		ids[key.ID] = true
+		aead, err := newAEAD(backend, key.Key)
+		if err != nil {
+			if key.ID != "" {
+				return nil, fmt.Errorf("key %q: %w", key.ID, err)
+			}
+			return nil, err
+		}
+		g.keys = append(g.keys, keyAEAD{id: key.ID, aead: aead})
+	}
+	return g, nil
+}
+
+func newAEAD(backend Backend, key string) (cipher.AEAD, error) {
 	keyDecoded, err := hex.DecodeString(key)
 	if err != nil {
 		return nil, err
@@ -44,18 +116,7 @@ func New(key string) (Cryptor, error) {
 	if keyLen != 16 && keyLen != 24 && keyLen != 32 {
 		return nil, errors.New("aescipher: key length must be 16, 24, or 32 bytes")
 	}
-
-	block, err := aes.NewCipher(keyDecoded)
-	if err != nil {
-		return nil, err
-	}
-
-	aead, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
-	}
-
-	return &gcmCryptor{aead: aead}, nil
+	return backend.NewAEAD(keyDecoded)
 }
 
 // EncryptString encrypts a UTF-8 string and returns Base64.
@@ -80,64 +141,91 @@ func (g *gcmCryptor) DecryptString(b64 string) (string, error) {
 	return string(pt), nil
 }
 
-// Encrypt encrypts plaintext.
-// Layout : "v1" | nonce (12) | ciphertext+tag (Seal output).
+// Encrypt encrypts plaintext with the first key.
+// Layout : "v1" | nonce (12) | ciphertext+tag (Seal output), for a key without ID.
+// Layout : "v2" | key ID length (1) | key ID | nonce (12) | ciphertext+tag (Seal output), for a key with an ID.
 func (g *gcmCryptor) Encrypt(plaintext []byte) ([]byte, error) {
-	nonce := make([]byte, NonceSizeGCM)
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
+	key := g.keys[0]
+	header := []byte(versionPrefix)
+	if key.id != "" {
+		header = append([]byte(versionPrefixKeyID), byte(len(key.id)))
+		header = append(header, key.id...)
 	}
-
-	// Seal with AAD = versionPrefix.
-	enc := g.aead.Seal(nil, nonce, plaintext, []byte(versionPrefix))
-
-	out := make([]byte, 0, len(versionPrefix)+len(nonce)+len(enc))
-	out = append(out, versionPrefix...)
-	out = append(out, nonce...)
-	out = append(out, enc...)
-	return out, nil
+	// Seal with AAD = header, so the key ID cannot be altered.
+	out := make([]byte, 0, len(header)+key.aead.Overhead()+len(plaintext))
+	out = append(out, header...)
+	return key.aead.Seal(out, nil, plaintext, header), nil
 }
 
-// Decrypt decrypts data created by Encrypt.
+// Decrypt decrypts data created by Encrypt, with the key it was encrypted with.
 func (g *gcmCryptor) Decrypt(ciphertext []byte) ([]byte, error) {
-	minLen := len(versionPrefix) + NonceSizeGCM + g.aead.Overhead()
-	if len(ciphertext) < minLen {
-		return nil, errors.New("aescipher: ciphertext too short")
-	}
-	if !bytes.HasPrefix(ciphertext, []byte(versionPrefix)) {
+	switch {
+	case bytes.HasPrefix(ciphertext, []byte(versionPrefixKeyID)):
+		offset := len(versionPrefixKeyID)
+		if len(ciphertext) <= offset {
+			return nil, errors.New("aescipher: ciphertext too short")
+		}
+		idEnd := offset + 1 + int(ciphertext[offset])
+		if len(ciphertext) < idEnd {
+			return nil, errors.New("aescipher: ciphertext too short")
+		}
+		id := string(ciphertext[offset+1 : idEnd])
+		for _, key := range g.keys {
+			if key.id == id {
+				return open(key.aead, ciphertext[:idEnd], ciphertext[idEnd:])
+			}
+		}
+		return nil, fmt.Errorf("aescipher: unknown key ID %q", id)
+
+	case bytes.HasPrefix(ciphertext, []byte(versionPrefix)):
+		// The ciphertext does not identify its key: every key is tried.
+		var err error
+		for _, key := range g.keys {
+			var plaintext []byte
+			if plaintext, err = open(key.aead, ciphertext[:len(versionPrefix)], ciphertext[len(versionPrefix):]); err == nil {
+				return plaintext, nil
+			}
+		}
+		return nil, err
+
+	default:
+		if len(ciphertext) < len(versionPrefix) {
+			return nil, errors.New("aescipher: ciphertext too short")
+		}
 		return nil, errors.New("aescipher: invalid prefix")
 	}
+}
 
-	offset := len(versionPrefix)
-	nonce := ciphertext[offset : offset+NonceSizeGCM]
-	enc := ciphertext[offset+NonceSizeGCM:]
-
-	plaintext, err := g.aead.Open(nil, nonce, enc, []byte(versionPrefix))
-	if err != nil {
-		return nil, err
+// open decrypts the nonce and sealed data following the header, authenticated as AAD.
+func open(aead cipher.AEAD, header, data []byte) ([]byte, error) {
+	if len(data) < aead.Overhead() {
+		return nil, errors.New("aescipher: ciphertext too short")
 	}
-	return plaintext, nil
+	return aead.Open(nil, nil, data, header)
 }
 
 // IsEncryptedString returns true if the string is an encrypted string
-// produced by Encrypt (false positive probability ≈ 2⁻¹²⁸).
+// produced by Encrypt with one of the keys (false positive probability ≈ 2⁻¹²⁸).
 func (g *gcmCryptor) IsEncryptedString(b64 string) bool {
 	ct, err := base64.StdEncoding.DecodeString(b64)
 	if err != nil {
 		return false
 	}
+	_, err = g.Decrypt(ct)
+	return err == nil
+}
 
-	minLen := len(versionPrefix) + NonceSizeGCM + g.aead.Overhead()
-	if len(ct) < minLen || !bytes.HasPrefix(ct, []byte(versionPrefix)) {
+// IsCiphertextString returns true if the string has the layout of a ciphertext of this package, whether or not
+// it can be decrypted with the available keys, to tell the ciphertexts of unknown keys from plaintexts.
+func IsCiphertextString(b64 string) bool {
+	ct, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil || len(ct) < len(versionPrefix)+NonceSizeGCM {
 		return false
 	}
-
-	offset := len(versionPrefix)
-	nonce := ct[offset : offset+NonceSizeGCM]
-	enc := ct[offset+NonceSizeGCM:]
-
-	if _, err = g.aead.Open(nil, nonce, enc, []byte(versionPrefix)); err != nil {
-		return false
+	for _, prefix := range []string{versionPrefix, versionPrefixKeyID, versionPrefixEnvelope} {
+		if bytes.HasPrefix(ct, []byte(prefix)) {
+			return true
+		}
 	}
-	return true
+	return false
 }
@@ -94,3 +94,93 @@ func BenchmarkDecrypt(b *testing.B) {
 		enc.Decrypt(ct)
 	}
 }
+
+// TestGenerateKey expects the generated keys to be accepted by New, and invalid sizes to be rejected.
+func TestGenerateKey(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		key, err := GenerateKey(size)
+		if err != nil {
+			t.Fatalf("GenerateKey(%d): %v", size, err)
+		}
+		if len(key) != size*2 {
+			t.Fatalf("GenerateKey(%d): want %d hex characters, got %d", size, size*2, len(key))
+		}
+		if _, err := New(key); err != nil {
+			t.Fatalf("New(GenerateKey(%d)): %v", size, err)
+		}
+	}
+	if _, err := GenerateKey(20); err == nil {
+		t.Fatal("expected error for invalid key length, got nil")
+	}
+}
+
+// TestKeyRotation expects a keyring to decrypt the ciphertexts of its previous keys, including those
+// without key ID, and to encrypt with its first key.
+func TestKeyRotation(t *testing.T) {
+	oldKey := hex.EncodeToString(randomKey(t))
+	newKey := hex.EncodeToString(randomKey(t))
+	legacy, _ := New(oldKey)
+	legacyCT, _ := legacy.Encrypt([]byte("legacy"))
+
+	keyring, err := NewKeyring(Key{ID: "2025-10", Key: newKey}, Key{ID: "2025-01", Key: oldKey})
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	if got, err := keyring.Decrypt(legacyCT); err != nil || string(got) != "legacy" {
+		t.Fatalf("Decrypt legacy ciphertext: got %q, %v", got, err)
+	}
+
+	ct, _ := keyring.Encrypt([]byte("rotated"))
+	if !bytes.HasPrefix(ct, []byte("v2\x072025-10")) {
+		t.Fatalf("expected the ciphertext to embed the ID of the first key, got %q", ct[:10])
+	}
+	if got, err := keyring.Decrypt(ct); err != nil || string(got) != "rotated" {
+		t.Fatalf("Decrypt: got %q, %v", got, err)
+	}
+
+	previous, _ := NewKeyring(Key{ID: "2025-01", Key: oldKey})
+	if _, err := previous.Decrypt(ct); err == nil || err.Error() != `aescipher: unknown key ID "2025-10"` {
+		t.Fatalf("expected unknown key ID error, got %v", err)
+	}
+
+	// The key ID is authenticated.
+	tampered := bytes.Clone(ct)
+	copy(tampered[3:], "2025-01")
+	if _, err := keyring.Decrypt(tampered); err == nil {
+		t.Fatal("expected authentication error, got nil")
+	}
+}
+
+// TestNewKeyringErrors expects invalid keyrings to be rejected.
+func TestNewKeyringErrors(t *testing.T) {
+	key := hex.EncodeToString(randomKey(t))
+	for name, keys := range map[string][]Key{
+		"no key":       nil,
+		"duplicate ID": {{ID: "a", Key: key}, {ID: "a", Key: key}},
+		"invalid key":  {{ID: "a", Key: key}, {ID: "b", Key: "abc"}},
+	} {
+		if _, err := NewKeyring(keys...); err == nil {
+			t.Fatalf("%s: expected error, got nil", name)
+		}
+	}
+	if _, err := ParseKey("no-id"); err == nil {
+		t.Fatal("ParseKey: expected error for a key without ID, got nil")
+	}
+	if parsed, err := ParseKey("2025-01:" + key); err != nil || parsed != (Key{ID: "2025-01", Key: key}) {
+		t.Fatalf("ParseKey: got %+v, %v", parsed, err)
+	}
+}
+
+// TestIsCiphertextString expects the ciphertexts to be recognized without their key.
+func TestIsCiphertextString(t *testing.T) {
+	enc, _ := NewKeyring(Key{ID: "2025-10", Key: hex.EncodeToString(randomKey(t))})
+	ct, _ := enc.EncryptString("secret")
+	if !IsCiphertextString(ct) {
+		t.Fatalf("expected %q to be a ciphertext", ct)
+	}
+	for _, value := range []string{"", "secret", "djE=", "Bearer token"} {
+		if IsCiphertextString(value) {
+			t.Fatalf("expected %q not to be a ciphertext", value)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package aescipher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/fips140"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+const (
+	// BackendStandard is the AES-GCM of the Go standard library.
+	BackendStandard = "standard"
+	// BackendFIPS is the AES-GCM of the Go Cryptographic Module, FIPS 140-3 validated. It requires the module to
+	// be enabled, with GODEBUG=fips140=on or a binary built with GOFIPS140.
+	BackendFIPS = "fips"
+)
+
+// Backend builds the AES-GCM ciphers of the Cryptors, so the cryptographic implementation can be selected.
+type Backend interface {
+	// NewAEAD returns an AES-GCM cipher of key generating its random nonces: the nonce passed to Seal and Open is
+	// empty, Seal prepends the 12-byte nonce to its output, which Open expects.
+	NewAEAD(key []byte) (cipher.AEAD, error)
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Backend{
+		BackendStandard: standardBackend{},
+		BackendFIPS:     fipsBackend{},
+	}
+)
+
+// RegisterBackend makes a backend available under name, e.g. the binding of an HSM, replacing the backend with
+// the same name if any.
+func RegisterBackend(name string, backend Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[name] = backend
+}
+
+// LookupBackend returns the backend registered under name.
+func LookupBackend(name string) (Backend, error) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	backend, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("aescipher: unknown backend %q", name)
+	}
+	return backend, nil
+}
+
+// Backends returns the names of the registered backends, sorted.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+type standardBackend struct{}
+
+func (standardBackend) NewAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return randomNonceAEAD{aead}, nil
+}
+
+// randomNonceAEAD generates the nonces of an AES-GCM cipher, with the layout of cipher.NewGCMWithRandomNonce.
+type randomNonceAEAD struct {
+	aead cipher.AEAD
+}
+
+func (a randomNonceAEAD) NonceSize() int { return 0 }
+
+func (a randomNonceAEAD) Overhead() int { return NonceSizeGCM + a.aead.Overhead() }
+
+func (a randomNonceAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != 0 {
+		panic("aescipher: the nonce is generated by the cipher")
+	}
+	nonce = make([]byte, NonceSizeGCM)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		panic(fmt.Sprintf("aescipher: generate nonce: %v", err))
+	}
+	dst = append(dst, nonce...)
+	return a.aead.Seal(dst, nonce, plaintext, additionalData)
+}
+
+func (a randomNonceAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != 0 {
+		return nil, errors.New("aescipher: the nonce is read from the ciphertext")
+	}
+	if len(ciphertext) < a.Overhead() {
+		return nil, errors.New("aescipher: ciphertext too short")
+	}
+	return a.aead.Open(dst, ciphertext[:NonceSizeGCM], ciphertext[NonceSizeGCM:], additionalData)
+}
+
+type fipsBackend struct{}
+
+// NewAEAD uses the approved AES-GCM service of the module, which generates the nonces itself.
+func (fipsBackend) NewAEAD(key []byte) (cipher.AEAD, error) {
+	if !fips140.Enabled() {
+		return nil, errors.New("aescipher: the fips backend requires the Go Cryptographic Module, enabled with GODEBUG=fips140=on")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithRandomNonce(block)
+}
@@ -0,0 +1,93 @@
+package aescipher
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/fips140"
+	"encoding/hex"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestStandardBackendLayout checks that the standard backend reads the layout of cipher.NewGCMWithRandomNonce,
+// used by the fips backend, so the ciphertexts of both backends are interchangeable.
+func TestStandardBackendLayout(t *testing.T) {
+	key := randomKey(t)
+	block, _ := aes.NewCipher(key)
+	randomNonce, err := cipher.NewGCMWithRandomNonce(block)
+	if err != nil {
+		t.Fatalf("NewGCMWithRandomNonce: %v", err)
+	}
+	standard, err := standardBackend{}.NewAEAD(key)
+	if err != nil {
+		t.Fatalf("NewAEAD: %v", err)
+	}
+	if standard.NonceSize() != randomNonce.NonceSize() || standard.Overhead() != randomNonce.Overhead() {
+		t.Fatalf("standard backend sizes %d/%d, want %d/%d",
+			standard.NonceSize(), standard.Overhead(), randomNonce.NonceSize(), randomNonce.Overhead())
+	}
+
+	plain, aad := []byte("Hello, world!"), []byte("v1")
+	pt, err := standard.Open(nil, nil, randomNonce.Seal(nil, nil, plain, aad), aad)
+	if err != nil || !bytes.Equal(pt, plain) {
+		t.Fatalf("open ciphertext of NewGCMWithRandomNonce: %q, %v", pt, err)
+	}
+	pt, err = randomNonce.Open(nil, nil, standard.Seal(nil, nil, plain, aad), aad)
+	if err != nil || !bytes.Equal(pt, plain) {
+		t.Fatalf("open ciphertext of the standard backend: %q, %v", pt, err)
+	}
+	if _, err := standard.Open(nil, nil, plain[:NonceSizeGCM], aad); err == nil {
+		t.Fatal("expected an error for a short ciphertext")
+	}
+}
+
+func TestFIPSBackend(t *testing.T) {
+	backend, err := LookupBackend(BackendFIPS)
+	if err != nil {
+		t.Fatalf("LookupBackend: %v", err)
+	}
+	key := hex.EncodeToString(randomKey(t))
+	enc, err := NewKeyringWithBackend(backend, Key{ID: "k1", Key: key})
+	if !fips140.Enabled() {
+		if err == nil {
+			t.Fatal("expected an error without the Go Cryptographic Module")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("NewKeyringWithBackend: %v", err)
+	}
+	ct, err := enc.EncryptString("secret")
+	if err != nil {
+		t.Fatalf("EncryptString: %v", err)
+	}
+	standard, _ := NewKeyring(Key{ID: "k1", Key: key})
+	if pt, err := standard.DecryptString(ct); err != nil || pt != "secret" {
+		t.Fatalf("decrypt with the standard backend: %q, %v", pt, err)
+	}
+}
+
+func TestRegisterBackend(t *testing.T) {
+	if _, err := LookupBackend("hsm"); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+	RegisterBackend("hsm", standardBackend{})
+	t.Cleanup(func() {
+		backendsMu.Lock()
+		defer backendsMu.Unlock()
+		delete(backends, "hsm")
+	})
+	if !slices.Equal(Backends(), []string{BackendFIPS, "hsm", BackendStandard}) {
+		t.Fatalf("Backends() = %v", Backends())
+	}
+	backend, err := LookupBackend("hsm")
+	if err != nil {
+		t.Fatalf("LookupBackend: %v", err)
+	}
+	master, _ := New(hex.EncodeToString(randomKey(t)))
+	if _, err := NewEnvelopeWithBackend(backend, &fakeWrapper{master: master}, time.Second, nil); err != nil {
+		t.Fatalf("NewEnvelopeWithBackend: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package aescipher
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	versionPrefixEnvelope = "v3" // 2-byte marker identifying ciphertexts embedding their wrapped data key
+	dataKeySize           = 32
+	maxWrappedKeyLength   = 1<<16 - 1 // the length of the wrapped data key is encoded on 2 bytes
+)
+
+// KeyWrapper wraps and unwraps data keys with a master key which never leaves it, e.g. a KMS key.
+type KeyWrapper interface {
+	WrapKey(ctx context.Context, key []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+type envelopeCryptor struct {
+	backend  Backend
+	wrapper  KeyWrapper
+	timeout  time.Duration
+	fallback Cryptor
+
+	// header is "v3" | wrapped data key length (2) | wrapped data key, and aead the data key of the process.
+	header []byte
+	aead   cipher.AEAD
+
+	mu sync.Mutex
+	// unwrapped caches the data keys of the other processes, by wrapped data key.
+	unwrapped map[string]cipher.AEAD
+}
+
+// NewEnvelope returns a Cryptor backed by AES-GCM with envelope encryption: the data is encrypted with a random
+// data key, wrapped by the wrapper and embedded in the ciphertexts. Each wrapped data key is unwrapped once per
+// process, with calls bounded by timeout.
+// The ciphertexts of the other layouts are decrypted by fallback, if not nil, to migrate from local keys.
+func NewEnvelope(wrapper KeyWrapper, timeout time.Duration, fallback Cryptor) (Cryptor, error) {
+	return NewEnvelopeWithBackend(standardBackend{}, wrapper, timeout, fallback)
+}
+
+// NewEnvelopeWithBackend returns a Cryptor like NewEnvelope, backed by the AES-GCM of backend.
+func NewEnvelopeWithBackend(backend Backend, wrapper KeyWrapper, timeout time.Duration, fallback Cryptor) (Cryptor, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	aead, err := backend.NewAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	wrapped, err := wrapper.WrapKey(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("aescipher: wrap data key: %w", err)
+	}
+	if len(wrapped) > maxWrappedKeyLength {
+		return nil, errors.New("aescipher: wrapped data key too long")
+	}
+
+	header := make([]byte, 0, len(versionPrefixEnvelope)+2+len(wrapped))
+	header = append(header, versionPrefixEnvelope...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped))) //nolint:gosec // G115: checked above
+	header = append(header, wrapped...)
+	return &envelopeCryptor{
+		backend:   backend,
+		wrapper:   wrapper,
+		timeout:   timeout,
+		fallback:  fallback,
+		header:    header,
+		aead:      aead,
+		unwrapped: map[string]cipher.AEAD{string(wrapped): aead},
+	}, nil
+}
+
+// EncryptString encrypts a UTF-8 string and returns Base64.
+func (e *envelopeCryptor) EncryptString(plain string) (string, error) {
+	ct, err := e.Encrypt([]byte(plain))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ct), nil
+}
+
+// DecryptString decrypts Base64 and returns UTF-8.
+func (e *envelopeCryptor) DecryptString(b64 string) (string, error) {
+	ct, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return "", err
+	}
+	pt, err := e.Decrypt(ct)
+	if err != nil {
+		return "", err
+	}
+	return string(pt), nil
+}
+
+// Encrypt encrypts plaintext with the data key of the process.
+// Layout : "v3" | wrapped data key length (2) | wrapped data key | nonce (12) | ciphertext+tag (Seal output).
+func (e *envelopeCryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	// Seal with AAD = header, so the wrapped data key cannot be swapped.
+	out := make([]byte, 0, len(e.header)+e.aead.Overhead()+len(plaintext))
+	out = append(out, e.header...)
+	return e.aead.Seal(out, nil, plaintext, e.header), nil
+}
+
+// Decrypt decrypts data created by Encrypt, unwrapping its data key if it was encrypted by another process.
+func (e *envelopeCryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if !bytes.HasPrefix(ciphertext, []byte(versionPrefixEnvelope)) {
+		if e.fallback == nil {
+			return nil, errors.New("aescipher: invalid prefix")
+		}
+		return e.fallback.Decrypt(ciphertext)
+	}
+
+	offset := len(versionPrefixEnvelope)
+	if len(ciphertext) < offset+2 {
+		return nil, errors.New("aescipher: ciphertext too short")
+	}
+	headerEnd := offset + 2 + int(binary.BigEndian.Uint16(ciphertext[offset:]))
+	if len(ciphertext) < headerEnd {
+		return nil, errors.New("aescipher: ciphertext too short")
+	}
+	aead, err := e.dataKey(ciphertext[offset+2 : headerEnd])
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, ciphertext[:headerEnd], ciphertext[headerEnd:])
+}
+
+// dataKey returns the data key of a wrapped data key, unwrapped once.
+func (e *envelopeCryptor) dataKey(wrapped []byte) (cipher.AEAD, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if aead, ok := e.unwrapped[string(wrapped)]; ok {
+		return aead, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	key, err := e.wrapper.UnwrapKey(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("aescipher: unwrap data key: %w", err)
+	}
+	aead, err := e.backend.NewAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("aescipher: unwrap data key: %w", err)
+	}
+	e.unwrapped[string(wrapped)] = aead
+	return aead, nil
+}
+
+// IsEncryptedString returns true if the string is an encrypted string
+// produced by Encrypt, or by the fallback (false positive probability ≈ 2⁻¹²⁸).
+func (e *envelopeCryptor) IsEncryptedString(b64 string) bool {
+	ct, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return false
+	}
+	_, err = e.Decrypt(ct)
+	return err == nil
+}
@@ -0,0 +1,92 @@
+package aescipher
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeWrapper wraps the data keys with a local keyring, counting the unwraps.
+type fakeWrapper struct {
+	master  Cryptor
+	unwraps int
+}
+
+func (f *fakeWrapper) WrapKey(_ context.Context, key []byte) ([]byte, error) {
+	return f.master.Encrypt(key)
+}
+
+func (f *fakeWrapper) UnwrapKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	f.unwraps++
+	return f.master.Decrypt(wrapped)
+}
+
+// TestEnvelope expects the envelope ciphertexts to be decrypted by the other processes sharing the master
+// key, with an unwrap per data key, and the ciphertexts of the local keys to be decrypted by the fallback.
+func TestEnvelope(t *testing.T) {
+	master, _ := New(hex.EncodeToString(randomKey(t)))
+	local, _ := New(hex.EncodeToString(randomKey(t)))
+	legacyCT, _ := local.Encrypt([]byte("legacy"))
+
+	wrapper := &fakeWrapper{master: master}
+	first, err := NewEnvelope(wrapper, time.Second, local)
+	if err != nil {
+		t.Fatalf("NewEnvelope: %v", err)
+	}
+	ct, err := first.EncryptString("secret")
+	if err != nil {
+		t.Fatalf("EncryptString: %v", err)
+	}
+	if got, err := first.DecryptString(ct); err != nil || got != "secret" || wrapper.unwraps != 0 {
+		t.Fatalf("DecryptString: got %q, %v, %d unwraps", got, err, wrapper.unwraps)
+	}
+
+	second, _ := NewEnvelope(wrapper, time.Second, nil)
+	for range 2 {
+		if got, err := second.DecryptString(ct); err != nil || got != "secret" {
+			t.Fatalf("DecryptString by another process: got %q, %v", got, err)
+		}
+	}
+	if wrapper.unwraps != 1 {
+		t.Fatalf("expected the data key to be unwrapped once, got %d unwraps", wrapper.unwraps)
+	}
+	if !second.IsEncryptedString(ct) {
+		t.Fatal("IsEncryptedString: expected true")
+	}
+
+	if got, err := first.Decrypt(legacyCT); err != nil || string(got) != "legacy" {
+		t.Fatalf("Decrypt legacy ciphertext: got %q, %v", got, err)
+	}
+	if _, err := second.Decrypt(legacyCT); err == nil {
+		t.Fatal("expected error without fallback, got nil")
+	}
+
+	// The ciphertext is authenticated.
+	raw, _ := first.Encrypt([]byte("secret"))
+	tampered := bytes.Clone(raw)
+	tampered[len(raw)-1] ^= 0xff
+	if _, err := first.Decrypt(tampered); err == nil {
+		t.Fatal("expected authentication error, got nil")
+	}
+}
+
+type failingWrapper struct{}
+
+func (failingWrapper) WrapKey(context.Context, []byte) ([]byte, error) {
+	return nil, errors.New("access denied")
+}
+
+func (failingWrapper) UnwrapKey(context.Context, []byte) ([]byte, error) {
+	return nil, errors.New("access denied")
+}
+
+// TestNewEnvelopeError expects the envelope to fail when the KMS cannot wrap its data key.
+func TestNewEnvelopeError(t *testing.T) {
+	if _, err := NewEnvelope(failingWrapper{}, time.Second, nil); err == nil ||
+		err.Error() != "aescipher: wrap data key: access denied" {
+		t.Fatalf("expected wrap error, got %v", err)
+	}
+}
@@ -0,0 +1,1574 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: admin/v1/admin.proto
+
+package adminv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Proxy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// type is the transport of the upstream server (e.g. "streamable-http").
+	Type    string               `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Url     string               `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Timeout *durationpb.Duration `protobuf:"bytes,4,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// auth_type is "header", "oauth" or "hmac".
+	AuthType string         `protobuf:"bytes,5,opt,name=auth_type,json=authType,proto3" json:"auth_type,omitempty"`
+	Headers  []*ProxyHeader `protobuf:"bytes,6,rep,name=headers,proto3" json:"headers,omitempty"`
+	Oauth    *ProxyOAuth    `protobuf:"bytes,7,opt,name=oauth,proto3" json:"oauth,omitempty"`
+	// labels are free-form key=value pairs, e.g. team=payments, to list the proxies and grant permissions on them.
+	Labels map[string]string `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Hmac   *ProxyHMAC        `protobuf:"bytes,9,opt,name=hmac,proto3" json:"hmac,omitempty"`
+	// description tells what the upstream server is.
+	Description string `protobuf:"bytes,10,opt,name=description,proto3" json:"description,omitempty"`
+	// owner is who to contact about the upstream server, e.g. an email.
+	Owner string `protobuf:"bytes,11,opt,name=owner,proto3" json:"owner,omitempty"`
+	// docs_url is the link to the documentation of the upstream server.
+	DocsUrl string `protobuf:"bytes,12,opt,name=docs_url,json=docsUrl,proto3" json:"docs_url,omitempty"`
+	// egress_proxy is the URL of the HTTP, HTTPS or SOCKS5 proxy through which the gateway connects to the upstream
+	// server, instead of the egress proxy of the gateway, or "direct" to connect without proxy.
+	EgressProxy string `protobuf:"bytes,13,opt,name=egress_proxy,json=egressProxy,proto3" json:"egress_proxy,omitempty"`
+	// max_response_bytes caps the size of the tool results, instead of the maximum of the gateway, and
+	// response_size_policy, "truncate" or "reject", tells what to do with the larger ones, instead of the policy of
+	// the gateway.
+	MaxResponseBytes   int64  `protobuf:"varint,14,opt,name=max_response_bytes,json=maxResponseBytes,proto3" json:"max_response_bytes,omitempty"`
+	ResponseSizePolicy string `protobuf:"bytes,15,opt,name=response_size_policy,json=responseSizePolicy,proto3" json:"response_size_policy,omitempty"`
+	// keepalive_interval is how long an upstream session of a client is unused before it is pinged, instead of the
+	// keepalive interval of the gateway.
+	KeepaliveInterval *durationpb.Duration `protobuf:"bytes,16,opt,name=keepalive_interval,json=keepaliveInterval,proto3" json:"keepalive_interval,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Proxy) Reset() {
+	*x = Proxy{}
+	mi := &file_admin_v1_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Proxy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Proxy) ProtoMessage() {}
+
+func (x *Proxy) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Proxy.ProtoReflect.Descriptor instead.
+func (*Proxy) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Proxy) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Proxy) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Proxy) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Proxy) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Proxy) GetAuthType() string {
+	if x != nil {
+		return x.AuthType
+	}
+	return ""
+}
+
+func (x *Proxy) GetHeaders() []*ProxyHeader {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Proxy) GetOauth() *ProxyOAuth {
+	if x != nil {
+		return x.Oauth
+	}
+	return nil
+}
+
+func (x *Proxy) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Proxy) GetHmac() *ProxyHMAC {
+	if x != nil {
+		return x.Hmac
+	}
+	return nil
+}
+
+func (x *Proxy) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Proxy) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Proxy) GetDocsUrl() string {
+	if x != nil {
+		return x.DocsUrl
+	}
+	return ""
+}
+
+func (x *Proxy) GetEgressProxy() string {
+	if x != nil {
+		return x.EgressProxy
+	}
+	return ""
+}
+
+func (x *Proxy) GetMaxResponseBytes() int64 {
+	if x != nil {
+		return x.MaxResponseBytes
+	}
+	return 0
+}
+
+func (x *Proxy) GetResponseSizePolicy() string {
+	if x != nil {
+		return x.ResponseSizePolicy
+	}
+	return ""
+}
+
+func (x *Proxy) GetKeepaliveInterval() *durationpb.Duration {
+	if x != nil {
+		return x.KeepaliveInterval
+	}
+	return nil
+}
+
+type ProxyHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProxyHeader) Reset() {
+	*x = ProxyHeader{}
+	mi := &file_admin_v1_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProxyHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProxyHeader) ProtoMessage() {}
+
+func (x *ProxyHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProxyHeader.ProtoReflect.Descriptor instead.
+func (*ProxyHeader) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProxyHeader) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ProxyHeader) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type ProxyOAuth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ClientSecret  string                 `protobuf:"bytes,2,opt,name=client_secret,json=clientSecret,proto3" json:"client_secret,omitempty"`
+	TokenEndpoint string                 `protobuf:"bytes,3,opt,name=token_endpoint,json=tokenEndpoint,proto3" json:"token_endpoint,omitempty"`
+	Scopes        string                 `protobuf:"bytes,4,opt,name=scopes,proto3" json:"scopes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProxyOAuth) Reset() {
+	*x = ProxyOAuth{}
+	mi := &file_admin_v1_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProxyOAuth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProxyOAuth) ProtoMessage() {}
+
+func (x *ProxyOAuth) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProxyOAuth.ProtoReflect.Descriptor instead.
+func (*ProxyOAuth) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProxyOAuth) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *ProxyOAuth) GetClientSecret() string {
+	if x != nil {
+		return x.ClientSecret
+	}
+	return ""
+}
+
+func (x *ProxyOAuth) GetTokenEndpoint() string {
+	if x != nil {
+		return x.TokenEndpoint
+	}
+	return ""
+}
+
+func (x *ProxyOAuth) GetScopes() string {
+	if x != nil {
+		return x.Scopes
+	}
+	return ""
+}
+
+// ProxyHMAC is the shared secret with which the gateway signs the requests sent to the upstream server, with the
+// hmac auth type.
+type ProxyHMAC struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Secret        string                 `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProxyHMAC) Reset() {
+	*x = ProxyHMAC{}
+	mi := &file_admin_v1_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProxyHMAC) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProxyHMAC) ProtoMessage() {}
+
+func (x *ProxyHMAC) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProxyHMAC.ProtoReflect.Descriptor instead.
+func (*ProxyHMAC) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProxyHMAC) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+type ListProxiesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProxiesRequest) Reset() {
+	*x = ListProxiesRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProxiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProxiesRequest) ProtoMessage() {}
+
+func (x *ListProxiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProxiesRequest.ProtoReflect.Descriptor instead.
+func (*ListProxiesRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{4}
+}
+
+type ListProxiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Proxies       []*Proxy               `protobuf:"bytes,1,rep,name=proxies,proto3" json:"proxies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProxiesResponse) Reset() {
+	*x = ListProxiesResponse{}
+	mi := &file_admin_v1_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProxiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProxiesResponse) ProtoMessage() {}
+
+func (x *ListProxiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProxiesResponse.ProtoReflect.Descriptor instead.
+func (*ListProxiesResponse) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListProxiesResponse) GetProxies() []*Proxy {
+	if x != nil {
+		return x.Proxies
+	}
+	return nil
+}
+
+type GetProxyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProxyRequest) Reset() {
+	*x = GetProxyRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProxyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProxyRequest) ProtoMessage() {}
+
+func (x *GetProxyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProxyRequest.ProtoReflect.Descriptor instead.
+func (*GetProxyRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetProxyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type UpsertProxyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Proxy         *Proxy                 `protobuf:"bytes,1,opt,name=proxy,proto3" json:"proxy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertProxyRequest) Reset() {
+	*x = UpsertProxyRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertProxyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertProxyRequest) ProtoMessage() {}
+
+func (x *UpsertProxyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertProxyRequest.ProtoReflect.Descriptor instead.
+func (*UpsertProxyRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpsertProxyRequest) GetProxy() *Proxy {
+	if x != nil {
+		return x.Proxy
+	}
+	return nil
+}
+
+type DeleteProxyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProxyRequest) Reset() {
+	*x = DeleteProxyRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProxyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProxyRequest) ProtoMessage() {}
+
+func (x *DeleteProxyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProxyRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProxyRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteProxyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteProxyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProxyResponse) Reset() {
+	*x = DeleteProxyResponse{}
+	mi := &file_admin_v1_admin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProxyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProxyResponse) ProtoMessage() {}
+
+func (x *DeleteProxyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProxyResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProxyResponse) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{9}
+}
+
+type Role struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Permissions   []*Permission          `protobuf:"bytes,2,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Role) Reset() {
+	*x = Role{}
+	mi := &file_admin_v1_admin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Role) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Role) ProtoMessage() {}
+
+func (x *Role) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Role.ProtoReflect.Descriptor instead.
+func (*Role) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Role) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Role) GetPermissions() []*Permission {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+type Permission struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// object_type is "tools", "resources", "prompts" or "*".
+	ObjectType    string `protobuf:"bytes,1,opt,name=object_type,json=objectType,proto3" json:"object_type,omitempty"`
+	Proxy         string `protobuf:"bytes,2,opt,name=proxy,proto3" json:"proxy,omitempty"`
+	ObjectName    string `protobuf:"bytes,3,opt,name=object_name,json=objectName,proto3" json:"object_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Permission) Reset() {
+	*x = Permission{}
+	mi := &file_admin_v1_admin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Permission) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Permission) ProtoMessage() {}
+
+func (x *Permission) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Permission.ProtoReflect.Descriptor instead.
+func (*Permission) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Permission) GetObjectType() string {
+	if x != nil {
+		return x.ObjectType
+	}
+	return ""
+}
+
+func (x *Permission) GetProxy() string {
+	if x != nil {
+		return x.Proxy
+	}
+	return ""
+}
+
+func (x *Permission) GetObjectName() string {
+	if x != nil {
+		return x.ObjectName
+	}
+	return ""
+}
+
+type ListRolesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRolesRequest) Reset() {
+	*x = ListRolesRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRolesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRolesRequest) ProtoMessage() {}
+
+func (x *ListRolesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRolesRequest.ProtoReflect.Descriptor instead.
+func (*ListRolesRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{12}
+}
+
+type ListRolesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Roles         []*Role                `protobuf:"bytes,1,rep,name=roles,proto3" json:"roles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRolesResponse) Reset() {
+	*x = ListRolesResponse{}
+	mi := &file_admin_v1_admin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRolesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRolesResponse) ProtoMessage() {}
+
+func (x *ListRolesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRolesResponse.ProtoReflect.Descriptor instead.
+func (*ListRolesResponse) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListRolesResponse) GetRoles() []*Role {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+type UpsertRoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Role          *Role                  `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertRoleRequest) Reset() {
+	*x = UpsertRoleRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertRoleRequest) ProtoMessage() {}
+
+func (x *UpsertRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertRoleRequest.ProtoReflect.Descriptor instead.
+func (*UpsertRoleRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UpsertRoleRequest) GetRole() *Role {
+	if x != nil {
+		return x.Role
+	}
+	return nil
+}
+
+type DeleteRoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRoleRequest) Reset() {
+	*x = DeleteRoleRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRoleRequest) ProtoMessage() {}
+
+func (x *DeleteRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRoleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRoleRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DeleteRoleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteRoleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRoleResponse) Reset() {
+	*x = DeleteRoleResponse{}
+	mi := &file_admin_v1_admin_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRoleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRoleResponse) ProtoMessage() {}
+
+func (x *DeleteRoleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRoleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteRoleResponse) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{16}
+}
+
+type AttributeToRoles struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AttributeKey   string                 `protobuf:"bytes,1,opt,name=attribute_key,json=attributeKey,proto3" json:"attribute_key,omitempty"`
+	AttributeValue string                 `protobuf:"bytes,2,opt,name=attribute_value,json=attributeValue,proto3" json:"attribute_value,omitempty"`
+	Roles          []string               `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AttributeToRoles) Reset() {
+	*x = AttributeToRoles{}
+	mi := &file_admin_v1_admin_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeToRoles) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeToRoles) ProtoMessage() {}
+
+func (x *AttributeToRoles) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeToRoles.ProtoReflect.Descriptor instead.
+func (*AttributeToRoles) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AttributeToRoles) GetAttributeKey() string {
+	if x != nil {
+		return x.AttributeKey
+	}
+	return ""
+}
+
+func (x *AttributeToRoles) GetAttributeValue() string {
+	if x != nil {
+		return x.AttributeValue
+	}
+	return ""
+}
+
+func (x *AttributeToRoles) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+type ListAttributeToRolesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAttributeToRolesRequest) Reset() {
+	*x = ListAttributeToRolesRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAttributeToRolesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAttributeToRolesRequest) ProtoMessage() {}
+
+func (x *ListAttributeToRolesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAttributeToRolesRequest.ProtoReflect.Descriptor instead.
+func (*ListAttributeToRolesRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{18}
+}
+
+type ListAttributeToRolesResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AttributeToRoles []*AttributeToRoles    `protobuf:"bytes,1,rep,name=attribute_to_roles,json=attributeToRoles,proto3" json:"attribute_to_roles,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListAttributeToRolesResponse) Reset() {
+	*x = ListAttributeToRolesResponse{}
+	mi := &file_admin_v1_admin_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAttributeToRolesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAttributeToRolesResponse) ProtoMessage() {}
+
+func (x *ListAttributeToRolesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAttributeToRolesResponse.ProtoReflect.Descriptor instead.
+func (*ListAttributeToRolesResponse) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListAttributeToRolesResponse) GetAttributeToRoles() []*AttributeToRoles {
+	if x != nil {
+		return x.AttributeToRoles
+	}
+	return nil
+}
+
+type UpsertAttributeToRolesRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AttributeToRoles *AttributeToRoles      `protobuf:"bytes,1,opt,name=attribute_to_roles,json=attributeToRoles,proto3" json:"attribute_to_roles,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *UpsertAttributeToRolesRequest) Reset() {
+	*x = UpsertAttributeToRolesRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertAttributeToRolesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertAttributeToRolesRequest) ProtoMessage() {}
+
+func (x *UpsertAttributeToRolesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertAttributeToRolesRequest.ProtoReflect.Descriptor instead.
+func (*UpsertAttributeToRolesRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UpsertAttributeToRolesRequest) GetAttributeToRoles() *AttributeToRoles {
+	if x != nil {
+		return x.AttributeToRoles
+	}
+	return nil
+}
+
+type DeleteAttributeToRolesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AttributeKey   string                 `protobuf:"bytes,1,opt,name=attribute_key,json=attributeKey,proto3" json:"attribute_key,omitempty"`
+	AttributeValue string                 `protobuf:"bytes,2,opt,name=attribute_value,json=attributeValue,proto3" json:"attribute_value,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeleteAttributeToRolesRequest) Reset() {
+	*x = DeleteAttributeToRolesRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAttributeToRolesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAttributeToRolesRequest) ProtoMessage() {}
+
+func (x *DeleteAttributeToRolesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAttributeToRolesRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAttributeToRolesRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteAttributeToRolesRequest) GetAttributeKey() string {
+	if x != nil {
+		return x.AttributeKey
+	}
+	return ""
+}
+
+func (x *DeleteAttributeToRolesRequest) GetAttributeValue() string {
+	if x != nil {
+		return x.AttributeValue
+	}
+	return ""
+}
+
+type DeleteAttributeToRolesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAttributeToRolesResponse) Reset() {
+	*x = DeleteAttributeToRolesResponse{}
+	mi := &file_admin_v1_admin_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAttributeToRolesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAttributeToRolesResponse) ProtoMessage() {}
+
+func (x *DeleteAttributeToRolesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAttributeToRolesResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAttributeToRolesResponse) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{22}
+}
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_admin_v1_admin_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{23}
+}
+
+type Status struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Live          bool                   `protobuf:"varint,1,opt,name=live,proto3" json:"live,omitempty"`
+	Ready         bool                   `protobuf:"varint,2,opt,name=ready,proto3" json:"ready,omitempty"`
+	Proxies       []*ProxyStatus         `protobuf:"bytes,3,rep,name=proxies,proto3" json:"proxies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_admin_v1_admin_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *Status) GetLive() bool {
+	if x != nil {
+		return x.Live
+	}
+	return false
+}
+
+func (x *Status) GetReady() bool {
+	if x != nil {
+		return x.Ready
+	}
+	return false
+}
+
+func (x *Status) GetProxies() []*ProxyStatus {
+	if x != nil {
+		return x.Proxies
+	}
+	return nil
+}
+
+type ProxyStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// tools is the number of tools currently exposed for the proxy.
+	Tools int32 `protobuf:"varint,2,opt,name=tools,proto3" json:"tools,omitempty"`
+	// synced is false until the tools of the proxy have been fetched once.
+	Synced        bool `protobuf:"varint,3,opt,name=synced,proto3" json:"synced,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProxyStatus) Reset() {
+	*x = ProxyStatus{}
+	mi := &file_admin_v1_admin_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProxyStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProxyStatus) ProtoMessage() {}
+
+func (x *ProxyStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_v1_admin_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProxyStatus.ProtoReflect.Descriptor instead.
+func (*ProxyStatus) Descriptor() ([]byte, []int) {
+	return file_admin_v1_admin_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ProxyStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProxyStatus) GetTools() int32 {
+	if x != nil {
+		return x.Tools
+	}
+	return 0
+}
+
+func (x *ProxyStatus) GetSynced() bool {
+	if x != nil {
+		return x.Synced
+	}
+	return false
+}
+
+var File_admin_v1_admin_proto protoreflect.FileDescriptor
+
+const file_admin_v1_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x14admin/v1/admin.proto\x12\x13mcpgateway.admin.v1\x1a\x1egoogle/protobuf/duration.proto\"\xd5\x05\n" +
+	"\x05Proxy\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x123\n" +
+	"\atimeout\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12\x1b\n" +
+	"\tauth_type\x18\x05 \x01(\tR\bauthType\x12:\n" +
+	"\aheaders\x18\x06 \x03(\v2 .mcpgateway.admin.v1.ProxyHeaderR\aheaders\x125\n" +
+	"\x05oauth\x18\a \x01(\v2\x1f.mcpgateway.admin.v1.ProxyOAuthR\x05oauth\x12>\n" +
+	"\x06labels\x18\b \x03(\v2&.mcpgateway.admin.v1.Proxy.LabelsEntryR\x06labels\x122\n" +
+	"\x04hmac\x18\t \x01(\v2\x1e.mcpgateway.admin.v1.ProxyHMACR\x04hmac\x12 \n" +
+	"\vdescription\x18\n" +
+	" \x01(\tR\vdescription\x12\x14\n" +
+	"\x05owner\x18\v \x01(\tR\x05owner\x12\x19\n" +
+	"\bdocs_url\x18\f \x01(\tR\adocsUrl\x12!\n" +
+	"\fegress_proxy\x18\r \x01(\tR\vegressProxy\x12,\n" +
+	"\x12max_response_bytes\x18\x0e \x01(\x03R\x10maxResponseBytes\x120\n" +
+	"\x14response_size_policy\x18\x0f \x01(\tR\x12responseSizePolicy\x12H\n" +
+	"\x12keepalive_interval\x18\x10 \x01(\v2\x19.google.protobuf.DurationR\x11keepaliveInterval\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"5\n" +
+	"\vProxyHeader\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\x8d\x01\n" +
+	"\n" +
+	"ProxyOAuth\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12#\n" +
+	"\rclient_secret\x18\x02 \x01(\tR\fclientSecret\x12%\n" +
+	"\x0etoken_endpoint\x18\x03 \x01(\tR\rtokenEndpoint\x12\x16\n" +
+	"\x06scopes\x18\x04 \x01(\tR\x06scopes\"#\n" +
+	"\tProxyHMAC\x12\x16\n" +
+	"\x06secret\x18\x01 \x01(\tR\x06secret\"\x14\n" +
+	"\x12ListProxiesRequest\"K\n" +
+	"\x13ListProxiesResponse\x124\n" +
+	"\aproxies\x18\x01 \x03(\v2\x1a.mcpgateway.admin.v1.ProxyR\aproxies\"%\n" +
+	"\x0fGetProxyRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"F\n" +
+	"\x12UpsertProxyRequest\x120\n" +
+	"\x05proxy\x18\x01 \x01(\v2\x1a.mcpgateway.admin.v1.ProxyR\x05proxy\"(\n" +
+	"\x12DeleteProxyRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x15\n" +
+	"\x13DeleteProxyResponse\"]\n" +
+	"\x04Role\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12A\n" +
+	"\vpermissions\x18\x02 \x03(\v2\x1f.mcpgateway.admin.v1.PermissionR\vpermissions\"d\n" +
+	"\n" +
+	"Permission\x12\x1f\n" +
+	"\vobject_type\x18\x01 \x01(\tR\n" +
+	"objectType\x12\x14\n" +
+	"\x05proxy\x18\x02 \x01(\tR\x05proxy\x12\x1f\n" +
+	"\vobject_name\x18\x03 \x01(\tR\n" +
+	"objectName\"\x12\n" +
+	"\x10ListRolesRequest\"D\n" +
+	"\x11ListRolesResponse\x12/\n" +
+	"\x05roles\x18\x01 \x03(\v2\x19.mcpgateway.admin.v1.RoleR\x05roles\"B\n" +
+	"\x11UpsertRoleRequest\x12-\n" +
+	"\x04role\x18\x01 \x01(\v2\x19.mcpgateway.admin.v1.RoleR\x04role\"'\n" +
+	"\x11DeleteRoleRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x14\n" +
+	"\x12DeleteRoleResponse\"v\n" +
+	"\x10AttributeToRoles\x12#\n" +
+	"\rattribute_key\x18\x01 \x01(\tR\fattributeKey\x12'\n" +
+	"\x0fattribute_value\x18\x02 \x01(\tR\x0eattributeValue\x12\x14\n" +
+	"\x05roles\x18\x03 \x03(\tR\x05roles\"\x1d\n" +
+	"\x1bListAttributeToRolesRequest\"s\n" +
+	"\x1cListAttributeToRolesResponse\x12S\n" +
+	"\x12attribute_to_roles\x18\x01 \x03(\v2%.mcpgateway.admin.v1.AttributeToRolesR\x10attributeToRoles\"t\n" +
+	"\x1dUpsertAttributeToRolesRequest\x12S\n" +
+	"\x12attribute_to_roles\x18\x01 \x01(\v2%.mcpgateway.admin.v1.AttributeToRolesR\x10attributeToRoles\"m\n" +
+	"\x1dDeleteAttributeToRolesRequest\x12#\n" +
+	"\rattribute_key\x18\x01 \x01(\tR\fattributeKey\x12'\n" +
+	"\x0fattribute_value\x18\x02 \x01(\tR\x0eattributeValue\" \n" +
+	"\x1eDeleteAttributeToRolesResponse\"\x12\n" +
+	"\x10GetStatusRequest\"n\n" +
+	"\x06Status\x12\x12\n" +
+	"\x04live\x18\x01 \x01(\bR\x04live\x12\x14\n" +
+	"\x05ready\x18\x02 \x01(\bR\x05ready\x12:\n" +
+	"\aproxies\x18\x03 \x03(\v2 .mcpgateway.admin.v1.ProxyStatusR\aproxies\"O\n" +
+	"\vProxyStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05tools\x18\x02 \x01(\x05R\x05tools\x12\x16\n" +
+	"\x06synced\x18\x03 \x01(\bR\x06synced2\xc7\b\n" +
+	"\fAdminService\x12`\n" +
+	"\vListProxies\x12'.mcpgateway.admin.v1.ListProxiesRequest\x1a(.mcpgateway.admin.v1.ListProxiesResponse\x12L\n" +
+	"\bGetProxy\x12$.mcpgateway.admin.v1.GetProxyRequest\x1a\x1a.mcpgateway.admin.v1.Proxy\x12R\n" +
+	"\vUpsertProxy\x12'.mcpgateway.admin.v1.UpsertProxyRequest\x1a\x1a.mcpgateway.admin.v1.Proxy\x12`\n" +
+	"\vDeleteProxy\x12'.mcpgateway.admin.v1.DeleteProxyRequest\x1a(.mcpgateway.admin.v1.DeleteProxyResponse\x12Z\n" +
+	"\tListRoles\x12%.mcpgateway.admin.v1.ListRolesRequest\x1a&.mcpgateway.admin.v1.ListRolesResponse\x12O\n" +
+	"\n" +
+	"UpsertRole\x12&.mcpgateway.admin.v1.UpsertRoleRequest\x1a\x19.mcpgateway.admin.v1.Role\x12]\n" +
+	"\n" +
+	"DeleteRole\x12&.mcpgateway.admin.v1.DeleteRoleRequest\x1a'.mcpgateway.admin.v1.DeleteRoleResponse\x12{\n" +
+	"\x14ListAttributeToRoles\x120.mcpgateway.admin.v1.ListAttributeToRolesRequest\x1a1.mcpgateway.admin.v1.ListAttributeToRolesResponse\x12s\n" +
+	"\x16UpsertAttributeToRoles\x122.mcpgateway.admin.v1.UpsertAttributeToRolesRequest\x1a%.mcpgateway.admin.v1.AttributeToRoles\x12\x81\x01\n" +
+	"\x16DeleteAttributeToRoles\x122.mcpgateway.admin.v1.DeleteAttributeToRolesRequest\x1a3.mcpgateway.admin.v1.DeleteAttributeToRolesResponse\x12O\n" +
+	"\tGetStatus\x12%.mcpgateway.admin.v1.GetStatusRequest\x1a\x1b.mcpgateway.admin.v1.StatusBCZAgithub.com/matthisholleville/mcp-gateway/pkg/api/admin/v1;adminv1b\x06proto3"
+
+var (
+	file_admin_v1_admin_proto_rawDescOnce sync.Once
+	file_admin_v1_admin_proto_rawDescData []byte
+)
+
+func file_admin_v1_admin_proto_rawDescGZIP() []byte {
+	file_admin_v1_admin_proto_rawDescOnce.Do(func() {
+		file_admin_v1_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_admin_v1_admin_proto_rawDesc), len(file_admin_v1_admin_proto_rawDesc)))
+	})
+	return file_admin_v1_admin_proto_rawDescData
+}
+
+var file_admin_v1_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_admin_v1_admin_proto_goTypes = []any{
+	(*Proxy)(nil),                          // 0: mcpgateway.admin.v1.Proxy
+	(*ProxyHeader)(nil),                    // 1: mcpgateway.admin.v1.ProxyHeader
+	(*ProxyOAuth)(nil),                     // 2: mcpgateway.admin.v1.ProxyOAuth
+	(*ProxyHMAC)(nil),                      // 3: mcpgateway.admin.v1.ProxyHMAC
+	(*ListProxiesRequest)(nil),             // 4: mcpgateway.admin.v1.ListProxiesRequest
+	(*ListProxiesResponse)(nil),            // 5: mcpgateway.admin.v1.ListProxiesResponse
+	(*GetProxyRequest)(nil),                // 6: mcpgateway.admin.v1.GetProxyRequest
+	(*UpsertProxyRequest)(nil),             // 7: mcpgateway.admin.v1.UpsertProxyRequest
+	(*DeleteProxyRequest)(nil),             // 8: mcpgateway.admin.v1.DeleteProxyRequest
+	(*DeleteProxyResponse)(nil),            // 9: mcpgateway.admin.v1.DeleteProxyResponse
+	(*Role)(nil),                           // 10: mcpgateway.admin.v1.Role
+	(*Permission)(nil),                     // 11: mcpgateway.admin.v1.Permission
+	(*ListRolesRequest)(nil),               // 12: mcpgateway.admin.v1.ListRolesRequest
+	(*ListRolesResponse)(nil),              // 13: mcpgateway.admin.v1.ListRolesResponse
+	(*UpsertRoleRequest)(nil),              // 14: mcpgateway.admin.v1.UpsertRoleRequest
+	(*DeleteRoleRequest)(nil),              // 15: mcpgateway.admin.v1.DeleteRoleRequest
+	(*DeleteRoleResponse)(nil),             // 16: mcpgateway.admin.v1.DeleteRoleResponse
+	(*AttributeToRoles)(nil),               // 17: mcpgateway.admin.v1.AttributeToRoles
+	(*ListAttributeToRolesRequest)(nil),    // 18: mcpgateway.admin.v1.ListAttributeToRolesRequest
+	(*ListAttributeToRolesResponse)(nil),   // 19: mcpgateway.admin.v1.ListAttributeToRolesResponse
+	(*UpsertAttributeToRolesRequest)(nil),  // 20: mcpgateway.admin.v1.UpsertAttributeToRolesRequest
+	(*DeleteAttributeToRolesRequest)(nil),  // 21: mcpgateway.admin.v1.DeleteAttributeToRolesRequest
+	(*DeleteAttributeToRolesResponse)(nil), // 22: mcpgateway.admin.v1.DeleteAttributeToRolesResponse
+	(*GetStatusRequest)(nil),               // 23: mcpgateway.admin.v1.GetStatusRequest
+	(*Status)(nil),                         // 24: mcpgateway.admin.v1.Status
+	(*ProxyStatus)(nil),                    // 25: mcpgateway.admin.v1.ProxyStatus
+	nil,                                    // 26: mcpgateway.admin.v1.Proxy.LabelsEntry
+	(*durationpb.Duration)(nil),            // 27: google.protobuf.Duration
+}
+var file_admin_v1_admin_proto_depIdxs = []int32{
+	27, // 0: mcpgateway.admin.v1.Proxy.timeout:type_name -> google.protobuf.Duration
+	1,  // 1: mcpgateway.admin.v1.Proxy.headers:type_name -> mcpgateway.admin.v1.ProxyHeader
+	2,  // 2: mcpgateway.admin.v1.Proxy.oauth:type_name -> mcpgateway.admin.v1.ProxyOAuth
+	26, // 3: mcpgateway.admin.v1.Proxy.labels:type_name -> mcpgateway.admin.v1.Proxy.LabelsEntry
+	3,  // 4: mcpgateway.admin.v1.Proxy.hmac:type_name -> mcpgateway.admin.v1.ProxyHMAC
+	27, // 5: mcpgateway.admin.v1.Proxy.keepalive_interval:type_name -> google.protobuf.Duration
+	0,  // 6: mcpgateway.admin.v1.ListProxiesResponse.proxies:type_name -> mcpgateway.admin.v1.Proxy
+	0,  // 7: mcpgateway.admin.v1.UpsertProxyRequest.proxy:type_name -> mcpgateway.admin.v1.Proxy
+	11, // 8: mcpgateway.admin.v1.Role.permissions:type_name -> mcpgateway.admin.v1.Permission
+	10, // 9: mcpgateway.admin.v1.ListRolesResponse.roles:type_name -> mcpgateway.admin.v1.Role
+	10, // 10: mcpgateway.admin.v1.UpsertRoleRequest.role:type_name -> mcpgateway.admin.v1.Role
+	17, // 11: mcpgateway.admin.v1.ListAttributeToRolesResponse.attribute_to_roles:type_name -> mcpgateway.admin.v1.AttributeToRoles
+	17, // 12: mcpgateway.admin.v1.UpsertAttributeToRolesRequest.attribute_to_roles:type_name -> mcpgateway.admin.v1.AttributeToRoles
+	25, // 13: mcpgateway.admin.v1.Status.proxies:type_name -> mcpgateway.admin.v1.ProxyStatus
+	4,  // 14: mcpgateway.admin.v1.AdminService.ListProxies:input_type -> mcpgateway.admin.v1.ListProxiesRequest
+	6,  // 15: mcpgateway.admin.v1.AdminService.GetProxy:input_type -> mcpgateway.admin.v1.GetProxyRequest
+	7,  // 16: mcpgateway.admin.v1.AdminService.UpsertProxy:input_type -> mcpgateway.admin.v1.UpsertProxyRequest
+	8,  // 17: mcpgateway.admin.v1.AdminService.DeleteProxy:input_type -> mcpgateway.admin.v1.DeleteProxyRequest
+	12, // 18: mcpgateway.admin.v1.AdminService.ListRoles:input_type -> mcpgateway.admin.v1.ListRolesRequest
+	14, // 19: mcpgateway.admin.v1.AdminService.UpsertRole:input_type -> mcpgateway.admin.v1.UpsertRoleRequest
+	15, // 20: mcpgateway.admin.v1.AdminService.DeleteRole:input_type -> mcpgateway.admin.v1.DeleteRoleRequest
+	18, // 21: mcpgateway.admin.v1.AdminService.ListAttributeToRoles:input_type -> mcpgateway.admin.v1.ListAttributeToRolesRequest
+	20, // 22: mcpgateway.admin.v1.AdminService.UpsertAttributeToRoles:input_type -> mcpgateway.admin.v1.UpsertAttributeToRolesRequest
+	21, // 23: mcpgateway.admin.v1.AdminService.DeleteAttributeToRoles:input_type -> mcpgateway.admin.v1.DeleteAttributeToRolesRequest
+	23, // 24: mcpgateway.admin.v1.AdminService.GetStatus:input_type -> mcpgateway.admin.v1.GetStatusRequest
+	5,  // 25: mcpgateway.admin.v1.AdminService.ListProxies:output_type -> mcpgateway.admin.v1.ListProxiesResponse
+	0,  // 26: mcpgateway.admin.v1.AdminService.GetProxy:output_type -> mcpgateway.admin.v1.Proxy
+	0,  // 27: mcpgateway.admin.v1.AdminService.UpsertProxy:output_type -> mcpgateway.admin.v1.Proxy
+	9,  // 28: mcpgateway.admin.v1.AdminService.DeleteProxy:output_type -> mcpgateway.admin.v1.DeleteProxyResponse
+	13, // 29: mcpgateway.admin.v1.AdminService.ListRoles:output_type -> mcpgateway.admin.v1.ListRolesResponse
+	10, // 30: mcpgateway.admin.v1.AdminService.UpsertRole:output_type -> mcpgateway.admin.v1.Role
+	16, // 31: mcpgateway.admin.v1.AdminService.DeleteRole:output_type -> mcpgateway.admin.v1.DeleteRoleResponse
+	19, // 32: mcpgateway.admin.v1.AdminService.ListAttributeToRoles:output_type -> mcpgateway.admin.v1.ListAttributeToRolesResponse
+	17, // 33: mcpgateway.admin.v1.AdminService.UpsertAttributeToRoles:output_type -> mcpgateway.admin.v1.AttributeToRoles
+	22, // 34: mcpgateway.admin.v1.AdminService.DeleteAttributeToRoles:output_type -> mcpgateway.admin.v1.DeleteAttributeToRolesResponse
+	24, // 35: mcpgateway.admin.v1.AdminService.GetStatus:output_type -> mcpgateway.admin.v1.Status
+	25, // [25:36] is the sub-list for method output_type
+	14, // [14:25] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_admin_v1_admin_proto_init() }
+func file_admin_v1_admin_proto_init() {
+	if File_admin_v1_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_admin_v1_admin_proto_rawDesc), len(file_admin_v1_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   27,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_admin_v1_admin_proto_goTypes,
+		DependencyIndexes: file_admin_v1_admin_proto_depIdxs,
+		MessageInfos:      file_admin_v1_admin_proto_msgTypes,
+	}.Build()
+	File_admin_v1_admin_proto = out.File
+	file_admin_v1_admin_proto_goTypes = nil
+	file_admin_v1_admin_proto_depIdxs = nil
+}
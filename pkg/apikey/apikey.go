@@ -0,0 +1,154 @@
+// Package apikey hashes and verifies API keys, so the configuration holds a hash instead of a usable key.
+// The hashes are Argon2id hashes in the PHC string format, or bcrypt hashes.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The Argon2id parameters of Hash, the minimum recommended by OWASP: they are computed on every verification
+// of a new key.
+const (
+	argon2Memory  = 19 * 1024 // KiB
+	argon2Time    = 2
+	argon2Threads = 1
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+// hashSlots bounds the concurrent hash computations, which are expensive in memory and CPU by design.
+var hashSlots = make(chan struct{}, runtime.NumCPU())
+
+// Verifier verifies API keys against a key or a hash, in constant time.
+type Verifier struct {
+	verify func(key []byte) bool
+
+	mu sync.Mutex
+	// verified is the SHA-256 digest of the last key verified against the hash, so the hash is not computed
+	// on every request.
+	verified    [sha256.Size]byte
+	hasVerified bool
+}
+
+// Hash returns the Argon2id hash of the key, in the PHC string format.
+func Hash(key string) (string, error) {
+	if key == "" {
+		return "", errors.New("apikey: empty key")
+	}
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	hash := argon2.IDKey([]byte(key), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+// NewVerifier returns a Verifier of the keys against the hash, or against the key when the hash is empty.
+func NewVerifier(key, hash string) (*Verifier, error) {
+	if hash == "" {
+		if key == "" {
+			return nil, errors.New("apikey: a key or a hash is required")
+		}
+		digest := sha256.Sum256([]byte(key))
+		return &Verifier{verify: func(candidate []byte) bool {
+			candidateDigest := sha256.Sum256(candidate)
+			return subtle.ConstantTimeCompare(digest[:], candidateDigest[:]) == 1
+		}}, nil
+	}
+
+	verify, err := parseHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{verify: func(candidate []byte) bool {
+		hashSlots <- struct{}{}
+		defer func() { <-hashSlots }()
+		return verify(candidate)
+	}}, nil
+}
+
+// ValidateHash checks that the hash is a valid Argon2id or bcrypt hash.
+func ValidateHash(hash string) error {
+	_, err := parseHash(hash)
+	return err
+}
+
+// Verify returns true if the key matches.
+func (v *Verifier) Verify(key string) bool {
+	digest := sha256.Sum256([]byte(key))
+	v.mu.Lock()
+	cached := v.hasVerified && subtle.ConstantTimeCompare(digest[:], v.verified[:]) == 1
+	v.mu.Unlock()
+	if cached {
+		return true
+	}
+
+	if !v.verify([]byte(key)) {
+		return false
+	}
+	v.mu.Lock()
+	v.verified, v.hasVerified = digest, true
+	v.mu.Unlock()
+	return true
+}
+
+// parseHash returns the verification function of an Argon2id or bcrypt hash.
+func parseHash(hash string) (func(key []byte) bool, error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return parseArgon2id(hash)
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("apikey: invalid bcrypt hash: %w", err)
+		}
+		return func(key []byte) bool {
+			return bcrypt.CompareHashAndPassword([]byte(hash), key) == nil
+		}, nil
+	default:
+		return nil, errors.New("apikey: the hash must be an Argon2id ($argon2id$...) or bcrypt ($2b$...) hash")
+	}
+}
+
+// parseArgon2id parses a $argon2id$v=19$m=MEMORY,t=TIME,p=THREADS$SALT$HASH hash.
+func parseArgon2id(hash string) (func(key []byte) bool, error) {
+	invalid := errors.New("apikey: invalid Argon2id hash, expected $argon2id$v=19$m=MEMORY,t=TIME,p=THREADS$SALT$HASH")
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return nil, invalid
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, invalid
+	}
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil ||
+		memory == 0 || time == 0 || threads == 0 {
+		return nil, invalid
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, invalid
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(expected) == 0 {
+		return nil, invalid
+	}
+	return func(key []byte) bool {
+		//nolint:gosec // G115: the length of the hash is small
+		actual := argon2.IDKey(key, salt, time, memory, threads, uint32(len(expected)))
+		return subtle.ConstantTimeCompare(actual, expected) == 1
+	}, nil
+}
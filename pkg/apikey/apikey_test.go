@@ -0,0 +1,65 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestVerifier(t *testing.T) {
+	argon2Hash, err := Hash("s3cret")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(argon2Hash, "$argon2id$v=19$m=19456,t=2,p=1$") {
+		t.Fatalf("unexpected hash format %q", argon2Hash)
+	}
+	bcryptHash, _ := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+
+	for name, hash := range map[string]string{"plaintext": "", "argon2id": argon2Hash, "bcrypt": string(bcryptHash)} {
+		verifier, err := NewVerifier("s3cret", hash)
+		if err != nil {
+			t.Fatalf("%s: NewVerifier: %v", name, err)
+		}
+		// The second verification of the key is cached.
+		for range 2 {
+			if !verifier.Verify("s3cret") {
+				t.Fatalf("%s: expected the key to be verified", name)
+			}
+		}
+		for _, key := range []string{"", "s3cre", "s3cret "} {
+			if verifier.Verify(key) {
+				t.Fatalf("%s: expected %q to be refused", name, key)
+			}
+		}
+	}
+
+	// The hash takes precedence over the key.
+	verifier, _ := NewVerifier("other", argon2Hash)
+	if verifier.Verify("other") || !verifier.Verify("s3cret") {
+		t.Fatal("expected the key to be verified against the hash only")
+	}
+}
+
+func TestInvalidHash(t *testing.T) {
+	for _, hash := range []string{
+		"s3cret",
+		"$argon2i$v=19$m=19456,t=2,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=18$m=19456,t=2,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=0,t=2,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=19456,t=2,p=1$c2FsdA",
+		"$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$",
+		"$2b$10$short",
+	} {
+		if err := ValidateHash(hash); err == nil {
+			t.Fatalf("expected %q to be refused", hash)
+		}
+	}
+	if err := ValidateHash("$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$aGFzaA"); err != nil {
+		t.Fatalf("ValidateHash: %v", err)
+	}
+	if _, err := NewVerifier("", ""); err == nil {
+		t.Fatal("expected an error without key nor hash")
+	}
+}
@@ -0,0 +1,63 @@
+// Package fips restricts the TLS connections of the gateway to the FIPS 140-3 approved versions, cipher suites
+// and key exchanges, when the FIPS mode is enabled.
+package fips
+
+import (
+	"crypto/fips140"
+	"crypto/tls"
+	"net/http"
+	"sync/atomic"
+)
+
+// CipherSuites are the approved TLS 1.2 cipher suites. The TLS 1.3 suites are not configurable, the Go
+// Cryptographic Module only negotiates the AES-GCM ones when enabled.
+var CipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// CurvePreferences are the approved key exchanges, without X25519.
+var CurvePreferences = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+
+var enabled atomic.Bool
+
+// Enable turns the FIPS mode on: the TLS configurations returned by TLSConfig are restricted, as well as the one
+// of http.DefaultTransport, used by the HTTP clients of the gateway.
+func Enable() {
+	enabled.Store(true)
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport.TLSClientConfig = TLSConfig(transport.TLSClientConfig)
+	}
+}
+
+// Enabled returns whether the FIPS mode is on.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// ModuleEnabled returns whether the Go Cryptographic Module is enabled, with GODEBUG=fips140=on or a binary
+// built with GOFIPS140.
+func ModuleEnabled() bool {
+	return fips140.Enabled()
+}
+
+// TLSConfig returns a copy of config, which may be nil, restricted to TLS 1.2 and above with the approved cipher
+// suites and curves when the FIPS mode is on. Otherwise config is returned as is.
+func TLSConfig(config *tls.Config) *tls.Config {
+	if !Enabled() {
+		return config
+	}
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+	if config.MinVersion < tls.VersionTLS12 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	config.CipherSuites = CipherSuites
+	config.CurvePreferences = CurvePreferences
+	return config
+}
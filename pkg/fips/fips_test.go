@@ -0,0 +1,35 @@
+package fips
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSConfig(t *testing.T) {
+	config := &tls.Config{ServerName: "nats", MinVersion: tls.VersionTLS10}
+	assert.Same(t, config, TLSConfig(config), "the configuration must be left as is outside of the FIPS mode")
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	require.True(t, ok)
+	previous := transport.TLSClientConfig
+	t.Cleanup(func() {
+		enabled.Store(false)
+		transport.TLSClientConfig = previous
+	})
+	Enable()
+	assert.True(t, Enabled())
+
+	restricted := TLSConfig(config)
+	assert.Equal(t, "nats", restricted.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), restricted.MinVersion)
+	assert.Equal(t, CipherSuites, restricted.CipherSuites)
+	assert.Equal(t, CurvePreferences, restricted.CurvePreferences)
+	assert.Equal(t, uint16(tls.VersionTLS10), config.MinVersion, "the configuration must be copied")
+
+	assert.Equal(t, uint16(tls.VersionTLS13), TLSConfig(&tls.Config{MinVersion: tls.VersionTLS13}).MinVersion)
+	assert.Equal(t, CipherSuites, transport.TLSClientConfig.CipherSuites)
+}
@@ -0,0 +1,191 @@
+package kms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	awsService        = "kms"
+	awsSigningAlgo    = "AWS4-HMAC-SHA256"
+	awsAmzDateFormat  = "20060102T150405Z"
+	awsDateFormat     = "20060102"
+	awsJSONContent    = "application/x-amz-json-1.1"
+	awsTargetEncrypt  = "TrentService.Encrypt"
+	awsTargetDecrypt  = "TrentService.Decrypt"
+	awsHeaderDate     = "X-Amz-Date"
+	awsHeaderTarget   = "X-Amz-Target"
+	awsHeaderSecurity = "X-Amz-Security-Token"
+)
+
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// AWS wraps the data keys with the Encrypt and Decrypt actions of AWS KMS.
+type AWS struct {
+	keyARN   string
+	region   string
+	endpoint string
+	client   *http.Client
+	now      func() time.Time
+	// credentials returns the credentials signing the requests.
+	credentials func() (awsCredentials, error)
+}
+
+// NewAWS returns the KeyWrapper of the AWS KMS key ARN (arn:aws:kms:REGION:ACCOUNT:key/ID).
+// The requests are signed with the credentials of the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables.
+func NewAWS(keyARN string) (*AWS, error) {
+	region, err := parseAWSKeyARN(keyARN)
+	if err != nil {
+		return nil, err
+	}
+	return &AWS{
+		keyARN:      keyARN,
+		region:      region,
+		endpoint:    fmt.Sprintf("https://kms.%s.amazonaws.com/", region),
+		client:      http.DefaultClient,
+		now:         time.Now,
+		credentials: awsEnvCredentials,
+	}, nil
+}
+
+// parseAWSKeyARN checks a KMS key or alias ARN and returns its region.
+func parseAWSKeyARN(keyARN string) (string, error) {
+	parts := strings.SplitN(keyARN, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != awsService || parts[3] == "" ||
+		(!strings.HasPrefix(parts[5], "key/") && !strings.HasPrefix(parts[5], "alias/")) {
+		return "", errors.New("kms: AWS key must be a KMS key ARN (arn:aws:kms:REGION:ACCOUNT:key/ID)")
+	}
+	return parts[3], nil
+}
+
+func awsEnvCredentials() (awsCredentials, error) {
+	credentials := awsCredentials{
+		accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if credentials.accessKeyID == "" || credentials.secretAccessKey == "" {
+		return awsCredentials{}, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required")
+	}
+	return credentials, nil
+}
+
+// WrapKey encrypts the data key with the KMS key.
+func (a *AWS) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	var out struct {
+		CiphertextBlob []byte
+	}
+	in := map[string]any{"KeyId": a.keyARN, "Plaintext": key}
+	if err := a.call(ctx, awsTargetEncrypt, in, &out); err != nil {
+		return nil, err
+	}
+	return out.CiphertextBlob, nil
+}
+
+// UnwrapKey decrypts the data key with the KMS key.
+func (a *AWS) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Plaintext []byte
+	}
+	in := map[string]any{"KeyId": a.keyARN, "CiphertextBlob": wrapped}
+	if err := a.call(ctx, awsTargetDecrypt, in, &out); err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
+
+// call sends the signed action to the KMS endpoint.
+func (a *AWS) call(ctx context.Context, target string, in, out any) error {
+	credentials, err := a.credentials()
+	if err != nil {
+		return fmt.Errorf("kms: %w", err)
+	}
+	req, payload, err := newJSONRequest(ctx, a.endpoint, in)
+	if err != nil {
+		return fmt.Errorf("kms: %w", err)
+	}
+	req.Header.Set("Content-Type", awsJSONContent)
+	req.Header.Set(awsHeaderTarget, target)
+	a.sign(req, payload, credentials)
+	if err := doJSON(a.client, req, out); err != nil {
+		return fmt.Errorf("kms: %w", err)
+	}
+	return nil
+}
+
+// sign adds the Signature Version 4 headers to the request.
+func (a *AWS) sign(req *http.Request, payload []byte, credentials awsCredentials) {
+	now := a.now().UTC()
+	amzDate := now.Format(awsAmzDateFormat)
+	req.Header.Set(awsHeaderDate, amzDate)
+	if credentials.sessionToken != "" {
+		req.Header.Set(awsHeaderSecurity, credentials.sessionToken)
+	}
+
+	headers := map[string]string{
+		"content-type": req.Header.Get("Content-Type"),
+		"host":         req.URL.Host,
+		"x-amz-date":   amzDate,
+		"x-amz-target": req.Header.Get(awsHeaderTarget),
+	}
+	names := []string{"content-type", "host", "x-amz-date"}
+	if credentials.sessionToken != "" {
+		headers["x-amz-security-token"] = credentials.sessionToken
+		names = append(names, "x-amz-security-token")
+	}
+	names = append(names, "x-amz-target")
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(payload),
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(awsDateFormat), a.region, awsService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{awsSigningAlgo, amzDate, scope, hexSHA256([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+credentials.secretAccessKey), now.Format(awsDateFormat))
+	for _, part := range []string{a.region, awsService, "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsSigningAlgo, credentials.accessKeyID, scope, signedHeaders, signature))
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
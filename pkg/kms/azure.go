@@ -0,0 +1,211 @@
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	azureAPIVersion      = "7.4"
+	azureWrapAlgorithm   = "RSA-OAEP-256"
+	azureIMDSTokenURL    = "http://169.254.169.254/metadata/identity/oauth2/token"
+	azureIMDSAPIVersion  = "2018-02-01"
+	azureAuthorityHost   = "https://login.microsoftonline.com/"
+	azureAssertionType   = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	maxAzureKeyIDLength  = 1<<16 - 1 // the length of the key ID is encoded on 2 bytes
+	azureKeyIDLengthSize = 2
+)
+
+// Azure wraps the data keys with the wrapkey and unwrapkey operations of an Azure Key Vault RSA key.
+type Azure struct {
+	// keyURL is the key URL without version, the key ID returned by wrapkey identifies the version.
+	keyURL string
+	// version is the version of the key wrapping the data keys, the current version when empty.
+	version string
+	client  *http.Client
+	tokens  *tokenSource
+}
+
+// NewAzure returns the KeyWrapper of the Azure Key Vault key URL (https://VAULT.vault.azure.net/keys/NAME[/VERSION]).
+// The requests are authorized with the workload identity when AZURE_FEDERATED_TOKEN_FILE is set (AKS), with the
+// managed identity of the instance metadata service otherwise. AZURE_CLIENT_ID selects a user-assigned identity.
+func NewAzure(keyURL string) (*Azure, error) {
+	u, err := parseAzureKeyURL(keyURL)
+	if err != nil {
+		return nil, err
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	a := &Azure{
+		keyURL: u.Scheme + "://" + u.Host + "/keys/" + segments[1],
+		client: http.DefaultClient,
+	}
+	if len(segments) == 3 {
+		a.version = segments[2]
+	}
+
+	// The token audience is the vault domain, e.g. https://vault.azure.net for https://VAULT.vault.azure.net.
+	_, domain, _ := strings.Cut(u.Hostname(), ".")
+	resource := "https://" + domain
+	a.tokens = &tokenSource{fetch: func(ctx context.Context) (string, time.Duration, error) {
+		if tokenFile := os.Getenv("AZURE_FEDERATED_TOKEN_FILE"); tokenFile != "" {
+			return a.workloadIdentityToken(ctx, tokenFile, resource)
+		}
+		return a.managedIdentityToken(ctx, resource)
+	}}
+	return a, nil
+}
+
+func parseAzureKeyURL(keyURL string) (*url.URL, error) {
+	invalid := errors.New("kms: Azure key must be a Key Vault key URL (https://VAULT.vault.azure.net/keys/NAME[/VERSION])")
+	u, err := url.Parse(keyURL)
+	if err != nil || u.Scheme != "https" || !strings.Contains(u.Hostname(), ".") {
+		return nil, invalid
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if (len(segments) != 2 && len(segments) != 3) || segments[0] != "keys" || segments[1] == "" {
+		return nil, invalid
+	}
+	return u, nil
+}
+
+// managedIdentityToken fetches an access token of the managed identity from the instance metadata service.
+func (a *Azure) managedIdentityToken(ctx context.Context, resource string) (string, time.Duration, error) {
+	query := url.Values{"api-version": {azureIMDSAPIVersion}, "resource": {resource}}
+	if clientID := os.Getenv("AZURE_CLIENT_ID"); clientID != "" {
+		query.Set("client_id", clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, azureIMDSTokenURL+"?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Metadata", "true")
+	return a.token(req)
+}
+
+// workloadIdentityToken exchanges the federated token of the workload identity for an access token.
+func (a *Azure) workloadIdentityToken(ctx context.Context, tokenFile, resource string) (string, time.Duration, error) {
+	assertion, err := os.ReadFile(tokenFile) //nolint:gosec // G304: the path is set by the workload identity webhook
+	if err != nil {
+		return "", 0, err
+	}
+	authorityHost := os.Getenv("AZURE_AUTHORITY_HOST")
+	if authorityHost == "" {
+		authorityHost = azureAuthorityHost
+	}
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {os.Getenv("AZURE_CLIENT_ID")},
+		"scope":                 {resource + "/.default"},
+		"client_assertion_type": {azureAssertionType},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+	}
+	tokenURL := strings.TrimSuffix(authorityHost, "/") + "/" + url.PathEscape(os.Getenv("AZURE_TENANT_ID")) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return a.token(req)
+}
+
+// token sends the token request. The instance metadata service returns expires_in as a string.
+func (a *Azure) token(req *http.Request) (string, time.Duration, error) {
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   any    `json:"expires_in"`
+	}
+	if err := doJSON(a.client, req, &out); err != nil {
+		return "", 0, err
+	}
+	var seconds int
+	switch expiresIn := out.ExpiresIn.(type) {
+	case float64:
+		seconds = int(expiresIn)
+	case string:
+		seconds, _ = strconv.Atoi(expiresIn)
+	}
+	return out.AccessToken, time.Duration(seconds) * time.Second, nil
+}
+
+// WrapKey wraps the data key with the key. The wrapped data key is prefixed by the versioned key ID,
+// as the data keys must be unwrapped by the version which wrapped them.
+// Layout : key ID length (2) | key ID | wrapped data key.
+func (a *Azure) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	keyURL := a.keyURL
+	if a.version != "" {
+		keyURL += "/" + a.version
+	}
+	out, err := a.call(ctx, keyURL+"/wrapkey", key)
+	if err != nil {
+		return nil, err
+	}
+	if len(out.KeyID) > maxAzureKeyIDLength {
+		return nil, errors.New("kms: key ID too long")
+	}
+	wrapped := binary.BigEndian.AppendUint16(nil, uint16(len(out.KeyID))) //nolint:gosec // G115: checked above
+	wrapped = append(wrapped, out.KeyID...)
+	return append(wrapped, out.Value...), nil
+}
+
+// UnwrapKey unwraps the data key with the key version which wrapped it.
+func (a *Azure) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < azureKeyIDLengthSize {
+		return nil, errors.New("kms: wrapped key too short")
+	}
+	end := azureKeyIDLengthSize + int(binary.BigEndian.Uint16(wrapped))
+	if len(wrapped) < end {
+		return nil, errors.New("kms: wrapped key too short")
+	}
+	// The key ID is read from the stored data: it must be a version of the key, so the access token
+	// is never sent to another host.
+	keyID := string(wrapped[azureKeyIDLengthSize:end])
+	version, ok := strings.CutPrefix(keyID, a.keyURL+"/")
+	if !ok || version == "" || strings.ContainsAny(version, "/?#") {
+		return nil, fmt.Errorf("kms: data key wrapped by another key %q", keyID)
+	}
+	out, err := a.call(ctx, keyID+"/unwrapkey", wrapped[end:])
+	if err != nil {
+		return nil, err
+	}
+	return out.Value, nil
+}
+
+type azureKeyOperationResult struct {
+	KeyID string
+	Value []byte
+}
+
+// call sends the key operation, authorized with an access token. Key Vault encodes values as unpadded base64url.
+func (a *Azure) call(ctx context.Context, operationURL string, value []byte) (azureKeyOperationResult, error) {
+	token, err := a.tokens.Token(ctx)
+	if err != nil {
+		return azureKeyOperationResult{}, fmt.Errorf("kms: %w", err)
+	}
+	in := map[string]string{"alg": azureWrapAlgorithm, "value": base64.RawURLEncoding.EncodeToString(value)}
+	req, _, err := newJSONRequest(ctx, operationURL+"?api-version="+azureAPIVersion, in)
+	if err != nil {
+		return azureKeyOperationResult{}, fmt.Errorf("kms: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var out struct {
+		KeyID string `json:"kid"`
+		Value string `json:"value"`
+	}
+	if err := doJSON(a.client, req, &out); err != nil {
+		return azureKeyOperationResult{}, fmt.Errorf("kms: %w", err)
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(out.Value, "="))
+	if err != nil {
+		return azureKeyOperationResult{}, fmt.Errorf("kms: decode key operation result: %w", err)
+	}
+	return azureKeyOperationResult{KeyID: out.KeyID, Value: decoded}, nil
+}
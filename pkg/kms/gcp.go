@@ -0,0 +1,114 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	gcpEndpoint     = "https://cloudkms.googleapis.com/v1/"
+	gcpMetadataHost = "metadata.google.internal"
+	gcpTokenPath    = "/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// GCP wraps the data keys with the encrypt and decrypt methods of a GCP KMS CryptoKey.
+type GCP struct {
+	keyName  string
+	endpoint string
+	client   *http.Client
+	tokens   *tokenSource
+}
+
+// NewGCP returns the KeyWrapper of the GCP KMS CryptoKey resource name
+// (projects/PROJECT/locations/LOCATION/keyRings/RING/cryptoKeys/KEY).
+// The requests are authorized with the service account of the metadata server (GCE, GKE workload identity),
+// whose host can be overridden with the GCE_METADATA_HOST environment variable.
+func NewGCP(keyName string) (*GCP, error) {
+	if err := validateGCPKeyName(keyName); err != nil {
+		return nil, err
+	}
+	metadataHost := os.Getenv("GCE_METADATA_HOST")
+	if metadataHost == "" {
+		metadataHost = gcpMetadataHost
+	}
+	g := &GCP{
+		keyName:  keyName,
+		endpoint: gcpEndpoint,
+		client:   http.DefaultClient,
+	}
+	g.tokens = &tokenSource{fetch: func(ctx context.Context) (string, time.Duration, error) {
+		return g.metadataToken(ctx, "http://"+metadataHost+gcpTokenPath)
+	}}
+	return g, nil
+}
+
+func validateGCPKeyName(keyName string) error {
+	parts := strings.Split(keyName, "/")
+	if len(parts) != 8 || parts[0] != "projects" || parts[2] != "locations" || parts[4] != "keyRings" ||
+		parts[6] != "cryptoKeys" || parts[1] == "" || parts[3] == "" || parts[5] == "" || parts[7] == "" {
+		return errors.New("kms: GCP key must be a CryptoKey resource name " +
+			"(projects/PROJECT/locations/LOCATION/keyRings/RING/cryptoKeys/KEY)")
+	}
+	return nil
+}
+
+// metadataToken fetches an access token of the default service account from the metadata server.
+func (g *GCP) metadataToken(ctx context.Context, tokenURL string) (string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, http.NoBody)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := doJSON(g.client, req, &out); err != nil {
+		return "", 0, err
+	}
+	return out.AccessToken, time.Duration(out.ExpiresIn) * time.Second, nil
+}
+
+// WrapKey encrypts the data key with the primary version of the CryptoKey.
+func (g *GCP) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	var out struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	if err := g.call(ctx, "encrypt", map[string]any{"plaintext": key}, &out); err != nil {
+		return nil, err
+	}
+	return out.Ciphertext, nil
+}
+
+// UnwrapKey decrypts the data key with the CryptoKey version which encrypted it.
+func (g *GCP) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	if err := g.call(ctx, "decrypt", map[string]any{"ciphertext": wrapped}, &out); err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
+
+// call sends the method of the CryptoKey, authorized with an access token.
+func (g *GCP) call(ctx context.Context, method string, in, out any) error {
+	token, err := g.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("kms: %w", err)
+	}
+	req, _, err := newJSONRequest(ctx, g.endpoint+g.keyName+":"+method, in)
+	if err != nil {
+		return fmt.Errorf("kms: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if err := doJSON(g.client, req, out); err != nil {
+		return fmt.Errorf("kms: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Package kms wraps data keys with cloud key management services (AWS KMS, GCP KMS, Azure Key Vault),
+// so the master key never leaves them. The services are called through their REST APIs.
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/pkg/aescipher"
+)
+
+const (
+	// ProviderAWS wraps the data keys with an AWS KMS key, identified by its ARN.
+	ProviderAWS = "aws-kms"
+	// ProviderGCP wraps the data keys with a GCP KMS CryptoKey, identified by its resource name.
+	ProviderGCP = "gcp-kms"
+	// ProviderAzure wraps the data keys with an Azure Key Vault key, identified by its URL.
+	ProviderAzure = "azure-keyvault"
+
+	// maxErrorBodySize bounds the part of an error response reported in errors.
+	maxErrorBodySize = 1024
+	// tokenExpiryMargin renews the access tokens before they expire.
+	tokenExpiryMargin = time.Minute
+)
+
+// Providers lists the supported providers.
+var Providers = []string{ProviderAWS, ProviderGCP, ProviderAzure}
+
+// New returns the KeyWrapper of the provider for the key.
+func New(provider, key string) (aescipher.KeyWrapper, error) {
+	switch provider {
+	case ProviderAWS:
+		return NewAWS(key)
+	case ProviderGCP:
+		return NewGCP(key)
+	case ProviderAzure:
+		return NewAzure(key)
+	default:
+		return nil, fmt.Errorf("kms: unknown provider %q", provider)
+	}
+}
+
+// ValidateKey checks the key identifier of the provider, without calling it.
+func ValidateKey(provider, key string) error {
+	switch provider {
+	case ProviderAWS:
+		_, err := parseAWSKeyARN(key)
+		return err
+	case ProviderGCP:
+		return validateGCPKeyName(key)
+	case ProviderAzure:
+		_, err := parseAzureKeyURL(key)
+		return err
+	default:
+		return fmt.Errorf("kms: unknown provider %q", provider)
+	}
+}
+
+// doJSON sends the request and decodes the JSON response into out, reporting the error responses.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Redacted(), resp.Status, bytes.TrimSpace(body))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// newJSONRequest returns a POST request with body encoded as JSON.
+func newJSONRequest(ctx context.Context, url string, body any) (*http.Request, []byte, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, payload, nil
+}
+
+// tokenSource caches an access token until it expires.
+type tokenSource struct {
+	fetch func(ctx context.Context) (token string, expiresIn time.Duration, err error)
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Token returns the cached access token, fetching a new one if it expires soon.
+func (t *tokenSource) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" && time.Now().Before(t.expiry.Add(-tokenExpiryMargin)) {
+		return t.token, nil
+	}
+	token, expiresIn, err := t.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("fetch access token: %w", err)
+	}
+	t.token, t.expiry = token, time.Now().Add(expiresIn)
+	return token, nil
+}
@@ -0,0 +1,214 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testDataKey     = []byte("0123456789abcdef0123456789abcdef")
+	testWrapPrefix  = []byte("wrapped:")
+	testAccessToken = "access-token"
+)
+
+// fakeWrap and fakeUnwrap stand for the KMS key.
+func fakeWrap(key []byte) []byte {
+	return append(bytes.Clone(testWrapPrefix), key...)
+}
+
+func fakeUnwrap(t *testing.T, wrapped []byte) []byte {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(wrapped, testWrapPrefix), "unexpected wrapped key %q", wrapped)
+	return wrapped[len(testWrapPrefix):]
+}
+
+func staticTokens(token string) *tokenSource {
+	return &tokenSource{fetch: func(context.Context) (string, time.Duration, error) {
+		return token, time.Hour, nil
+	}}
+}
+
+func TestAWS(t *testing.T) {
+	const keyARN = "arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, awsJSONContent, r.Header.Get("Content-Type"))
+		assert.Equal(t, "20251016T120000Z", r.Header.Get("X-Amz-Date"))
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKID/20251016/eu-west-1/kms/aws4_request, "+
+				"SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature="))
+
+		var in struct {
+			KeyID          string `json:"KeyId"`
+			Plaintext      []byte
+			CiphertextBlob []byte
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		assert.Equal(t, keyARN, in.KeyID)
+		switch r.Header.Get("X-Amz-Target") {
+		case awsTargetEncrypt:
+			_ = json.NewEncoder(w).Encode(map[string]any{"CiphertextBlob": fakeWrap(in.Plaintext), "KeyId": keyARN})
+		case awsTargetDecrypt:
+			_ = json.NewEncoder(w).Encode(map[string]any{"Plaintext": fakeUnwrap(t, in.CiphertextBlob), "KeyId": keyARN})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"UnknownOperationException"}`))
+		}
+	}))
+	defer server.Close()
+
+	wrapper, err := NewAWS(keyARN)
+	require.NoError(t, err)
+	wrapper.endpoint = server.URL + "/"
+	wrapper.now = func() time.Time { return time.Date(2025, 10, 16, 12, 0, 0, 0, time.UTC) }
+	wrapper.credentials = func() (awsCredentials, error) {
+		return awsCredentials{accessKeyID: "AKID", secretAccessKey: "secret", sessionToken: "session"}, nil
+	}
+
+	wrapped, err := wrapper.WrapKey(context.Background(), testDataKey)
+	require.NoError(t, err)
+	assert.Equal(t, fakeWrap(testDataKey), wrapped)
+	key, err := wrapper.UnwrapKey(context.Background(), wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, testDataKey, key)
+}
+
+// TestAWSSignature checks the Signature Version 4 of a request.
+func TestAWSSignature(t *testing.T) {
+	wrapper := &AWS{region: "us-east-1", now: func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) }}
+	req := httptest.NewRequest(http.MethodPost, "https://kms.us-east-1.amazonaws.com/", http.NoBody)
+	req.Header.Set("Content-Type", awsJSONContent)
+	req.Header.Set(awsHeaderTarget, awsTargetEncrypt)
+	wrapper.sign(req, []byte("{}"), awsCredentials{accessKeyID: "AKIDEXAMPLE", secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"})
+
+	// The signature was computed independently, following the documented signing steps.
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/kms/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-date;x-amz-target, "+
+		"Signature=3bf264285472dd613263ae8c8ac212c4d4222d5a4c982f37d8acc994231aaf45", req.Header.Get("Authorization"))
+}
+
+func TestGCP(t *testing.T) {
+	const keyName = "projects/p/locations/global/keyRings/r/cryptoKeys/k"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == gcpTokenPath {
+			assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+			_, _ = w.Write([]byte(`{"access_token":"access-token","expires_in":3599,"token_type":"Bearer"}`))
+			return
+		}
+		assert.Equal(t, "Bearer "+testAccessToken, r.Header.Get("Authorization"))
+		var in struct {
+			Plaintext  []byte `json:"plaintext"`
+			Ciphertext []byte `json:"ciphertext"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		switch r.URL.Path {
+		case "/v1/" + keyName + ":encrypt":
+			_ = json.NewEncoder(w).Encode(map[string]any{"ciphertext": fakeWrap(in.Plaintext), "name": keyName + "/cryptoKeyVersions/1"})
+		case "/v1/" + keyName + ":decrypt":
+			_ = json.NewEncoder(w).Encode(map[string]any{"plaintext": fakeUnwrap(t, in.Ciphertext)})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(server.URL, "http://"))
+	wrapper, err := NewGCP(keyName)
+	require.NoError(t, err)
+	wrapper.endpoint = server.URL + "/v1/"
+
+	wrapped, err := wrapper.WrapKey(context.Background(), testDataKey)
+	require.NoError(t, err)
+	key, err := wrapper.UnwrapKey(context.Background(), wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, testDataKey, key)
+}
+
+func TestAzure(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer "+testAccessToken, r.Header.Get("Authorization"))
+		assert.Equal(t, azureAPIVersion, r.URL.Query().Get("api-version"))
+		var in struct {
+			Algorithm string `json:"alg"`
+			Value     string `json:"value"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		assert.Equal(t, azureWrapAlgorithm, in.Algorithm)
+		value, err := base64.RawURLEncoding.DecodeString(in.Value)
+		require.NoError(t, err)
+		switch r.URL.Path {
+		case "/keys/gateway/wrapkey":
+			value = fakeWrap(value)
+		case "/keys/gateway/v2/unwrapkey":
+			value = fakeUnwrap(t, value)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"kid":   server.URL + "/keys/gateway/v2",
+			"value": base64.RawURLEncoding.EncodeToString(value),
+		})
+	}))
+	defer server.Close()
+
+	wrapper := &Azure{keyURL: server.URL + "/keys/gateway", client: server.Client(), tokens: staticTokens(testAccessToken)}
+	wrapped, err := wrapper.WrapKey(context.Background(), testDataKey)
+	require.NoError(t, err)
+	key, err := wrapper.UnwrapKey(context.Background(), wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, testDataKey, key)
+
+	// The key ID read from the data must be a version of the key.
+	other := &Azure{keyURL: "https://vault.vault.azure.net/keys/gateway", client: server.Client(), tokens: staticTokens(testAccessToken)}
+	_, err = other.UnwrapKey(context.Background(), wrapped)
+	assert.ErrorContains(t, err, "data key wrapped by another key")
+}
+
+func TestKMSErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":403,"message":"Permission denied"}}`))
+	}))
+	defer server.Close()
+
+	wrapper := &GCP{keyName: "projects/p/locations/global/keyRings/r/cryptoKeys/k", endpoint: server.URL + "/v1/",
+		client: server.Client(), tokens: staticTokens(testAccessToken)}
+	_, err := wrapper.WrapKey(context.Background(), testDataKey)
+	assert.ErrorContains(t, err, "403 Forbidden")
+	assert.ErrorContains(t, err, "Permission denied")
+}
+
+func TestValidateKey(t *testing.T) {
+	for _, test := range []struct {
+		provider string
+		key      string
+		valid    bool
+	}{
+		{ProviderAWS, "arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab", true},
+		{ProviderAWS, "arn:aws:kms:eu-west-1:123456789012:alias/gateway", true},
+		{ProviderAWS, "alias/gateway", false},
+		{ProviderGCP, "projects/p/locations/global/keyRings/r/cryptoKeys/k", true},
+		{ProviderGCP, "projects/p/locations/global/keyRings/r", false},
+		{ProviderAzure, "https://vault.vault.azure.net/keys/gateway", true},
+		{ProviderAzure, "https://vault.vault.azure.net/keys/gateway/0123456789abcdef", true},
+		{ProviderAzure, "http://vault.vault.azure.net/keys/gateway", false},
+		{ProviderAzure, "https://vault.vault.azure.net/secrets/gateway", false},
+		{"vault", "key", false},
+	} {
+		err := ValidateKey(test.provider, test.key)
+		assert.Equal(t, test.valid, err == nil, "%s %s: %v", test.provider, test.key, err)
+	}
+}
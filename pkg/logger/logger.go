@@ -6,6 +6,7 @@ package logger
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -36,7 +37,7 @@ type Logger interface {
 // NewNoopLogger provides a noop logger.
 func NewNoopLogger() *ZapLogger {
 	return &ZapLogger{
-		zap.NewNop(),
+		Logger: zap.NewNop(),
 	}
 }
 
@@ -44,6 +45,9 @@ func NewNoopLogger() *ZapLogger {
 // It provides additional methods such as ones that logs based on context.
 type ZapLogger struct {
 	*zap.Logger
+
+	// level is shared with the child loggers, so changing it applies to all of them. It is nil for the noop logger.
+	level *zap.AtomicLevel
 }
 
 var _ Logger = (*ZapLogger)(nil)
@@ -52,7 +56,32 @@ var _ Logger = (*ZapLogger)(nil)
 // to the child don't affect the parent, and vice versa. Any fields that
 // require evaluation (such as Objects) are evaluated upon invocation of With.
 func (l *ZapLogger) With(fields ...zap.Field) Logger {
-	return &ZapLogger{l.Logger.With(fields...)}
+	return &ZapLogger{Logger: l.Logger.With(fields...), level: l.level}
+}
+
+// SetLevel changes the log level of the logger and its children (e.g. 'none', 'debug', or 'info').
+func (l *ZapLogger) SetLevel(level string) error {
+	if l.level == nil {
+		return fmt.Errorf("the level of a noop logger cannot be changed")
+	}
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.level.SetLevel(parsed.Level())
+	return nil
+}
+
+// parseLevel parses a log level, 'none' disabling all the logs.
+func parseLevel(level string) (zap.AtomicLevel, error) {
+	if level == "none" {
+		return zap.NewAtomicLevelAt(zapcore.InvalidLevel), nil
+	}
+	parsed, err := zap.ParseAtomicLevel(level)
+	if err != nil {
+		return parsed, fmt.Errorf("unknown log level: %s, error: %w", level, err)
+	}
+	return parsed, nil
 }
 
 //nolint:revive // need to match the interface
@@ -124,12 +153,20 @@ func (l *ZapLogger) FatalWithContext(ctx context.Context, msg string, fields ...
 	l.Logger.Fatal(msg, fields...)
 }
 
+const (
+	defaultSamplingInitial    = 100
+	defaultSamplingThereafter = 100
+)
+
 // OptionsLogger implements options for logger.
 type OptionsLogger struct {
 	format          string
 	level           string
 	timestampFormat string
 	outputPaths     []string
+	sampling        *zap.SamplingConfig
+	rotation        *RotationOptions
+	syslog          *SyslogOptions
 }
 
 // OptionLogger is a function that sets an option for the logger.
@@ -174,31 +211,46 @@ func WithOutputPaths(paths ...string) OptionLogger {
 	}
 }
 
-// NewLogger creates a new logger with the given options.
+// WithSampling samples the log entries: each second, the first initial entries with the same level and message
+// are logged, then every thereafter-th one. An initial of 0 disables the sampling.
+//
+// Defaults to 100 initial entries, then every 100th one.
+func WithSampling(initial, thereafter int) OptionLogger {
+	return func(ol *OptionsLogger) {
+		ol.sampling = nil
+		if initial > 0 {
+			ol.sampling = &zap.SamplingConfig{Initial: initial, Thereafter: thereafter}
+		}
+	}
+}
+
+// NewLogger creates a new logger with the given options. The values of the sensitive fields (e.g. authorization,
+// clientSecret) and the bearer tokens are always redacted.
 func NewLogger(options ...OptionLogger) (*ZapLogger, error) {
 	logOptions := &OptionsLogger{
 		level:           "info",
 		format:          "text",
 		timestampFormat: "ISO8601",
 		outputPaths:     []string{"stdout"},
+		sampling:        &zap.SamplingConfig{Initial: defaultSamplingInitial, Thereafter: defaultSamplingThereafter},
 	}
 
 	for _, opt := range options {
 		opt(logOptions)
 	}
 
-	if logOptions.level == "none" {
-		return NewNoopLogger(), nil
-	}
-
-	level, err := zap.ParseAtomicLevel(logOptions.level)
+	// The 'none' level disables the logs, which can still be enabled by changing the level.
+	level, err := parseLevel(logOptions.level)
 	if err != nil {
-		return nil, fmt.Errorf("unknown log level: %s, error: %w", logOptions.level, err)
+		return nil, err
 	}
 
 	cfg := zap.NewProductionConfig()
 	cfg.Level = level
-	cfg.OutputPaths = logOptions.outputPaths
+	openedPaths, rotatedPaths := logOptions.rotatedPaths()
+	cfg.OutputPaths = openedPaths
+	// The sampling is applied on top of the redaction, instead of by the config, so it still applies.
+	cfg.Sampling = nil
 	cfg.EncoderConfig.TimeKey = "timestamp"
 	cfg.EncoderConfig.CallerKey = "" // remove the "caller" field
 	cfg.DisableStacktrace = true
@@ -215,12 +267,23 @@ func NewLogger(options ...OptionLogger) (*ZapLogger, error) {
 		}
 	}
 
-	log, err := cfg.Build()
+	extraCores, err := logOptions.extraCores(cfg, rotatedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	log, err := cfg.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		core = newRedactingCore(zapcore.NewTee(append([]zapcore.Core{core}, extraCores...)...))
+		if sampling := logOptions.sampling; sampling != nil {
+			core = zapcore.NewSamplerWithOptions(core, time.Second, sampling.Initial, sampling.Thereafter)
+		}
+		return core
+	}))
 	if err != nil {
 		return nil, err
 	}
 
-	return &ZapLogger{log}, nil
+	return &ZapLogger{Logger: log, level: &level}, nil
 }
 
 // MustNewLogger creates a new logger with the given format, level, and timestamp format.
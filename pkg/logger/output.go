@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// RotationOptions rotates the output files.
+type RotationOptions struct {
+	// MaxSizeMB is the size in megabytes at which a file is rotated. 0 disables the rotation.
+	MaxSizeMB int
+	// MaxAgeDays is the number of days the rotated files are kept. 0 keeps them regardless of their age.
+	MaxAgeDays int
+	// MaxBackups is the number of rotated files kept. 0 keeps them all, within MaxAgeDays.
+	MaxBackups int
+	// Compress gzips the rotated files.
+	Compress bool
+}
+
+// SyslogOptions sends the logs to a syslog daemon, or to journald through its syslog socket.
+type SyslogOptions struct {
+	// Network and Address are the syslog daemon to dial (e.g. 'udp' and 'syslog:514'). The local daemon
+	// is used when Address is empty.
+	Network string
+	Address string
+	// Tag prefixes the messages, 'mcp-gateway' when empty.
+	Tag string
+	// Facility is the syslog facility of the messages (e.g. 'daemon' or 'local0'), 'daemon' when empty.
+	Facility string
+}
+
+// SyslogFacilities are the supported syslog facilities.
+var SyslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+const defaultSyslogTag = "mcp-gateway"
+
+// WithRotation rotates the output files by size and age. The "stdout" and "stderr" outputs are not rotated.
+func WithRotation(rotation RotationOptions) OptionLogger {
+	return func(ol *OptionsLogger) {
+		ol.rotation = &rotation
+	}
+}
+
+// WithSyslog also sends the logs to syslog, with the priority of their level.
+func WithSyslog(syslog SyslogOptions) OptionLogger {
+	return func(ol *OptionsLogger) {
+		ol.syslog = &syslog
+	}
+}
+
+// rotatedPaths splits the output paths between the ones opened by zap and the files rotated with lumberjack.
+func (ol *OptionsLogger) rotatedPaths() (opened, rotated []string) {
+	if ol.rotation == nil || ol.rotation.MaxSizeMB <= 0 {
+		return ol.outputPaths, nil
+	}
+	for _, path := range ol.outputPaths {
+		if slices.Contains([]string{"stdout", "stderr"}, path) {
+			opened = append(opened, path)
+		} else {
+			rotated = append(rotated, strings.TrimPrefix(path, "file://"))
+		}
+	}
+	return opened, rotated
+}
+
+// extraCores returns the cores writing to the rotated files and to syslog, encoding without colors.
+func (ol *OptionsLogger) extraCores(config zap.Config, rotated []string) ([]zapcore.Core, error) {
+	encoderConfig := config.EncoderConfig
+	if encoderConfig.EncodeLevel != nil && config.Encoding == "console" {
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	}
+	newEncoder := func() zapcore.Encoder {
+		if config.Encoding == "console" {
+			return zapcore.NewConsoleEncoder(encoderConfig)
+		}
+		return zapcore.NewJSONEncoder(encoderConfig)
+	}
+
+	var cores []zapcore.Core
+	for _, path := range rotated {
+		cores = append(cores, zapcore.NewCore(newEncoder(), zapcore.AddSync(&lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    ol.rotation.MaxSizeMB,
+			MaxAge:     ol.rotation.MaxAgeDays,
+			MaxBackups: ol.rotation.MaxBackups,
+			Compress:   ol.rotation.Compress,
+			LocalTime:  true,
+		}), config.Level))
+	}
+	if ol.syslog != nil {
+		// syslog timestamps the messages itself
+		encoderConfig.TimeKey = ""
+		core, err := newSyslogCore(*ol.syslog, newEncoder(), config.Level)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, core)
+	}
+	return cores, nil
+}
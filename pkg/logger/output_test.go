@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gateway.log")
+	log, err := NewLogger(WithFormat("json"), WithOutputPaths(path), WithSampling(0, 0),
+		WithRotation(RotationOptions{MaxSizeMB: 1, MaxBackups: 1}))
+	require.NoError(t, err)
+
+	message := strings.Repeat("x", 1024)
+	for range 1500 {
+		log.Info(message)
+	}
+	require.NoError(t, log.Sync())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(1024*1024), "the file must be rotated at its max size")
+	backups, err := filepath.Glob(filepath.Join(filepath.Dir(path), "gateway-*.log"))
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestSyslog(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	log, err := NewLogger(WithFormat("json"), WithOutputPaths(filepath.Join(t.TempDir(), "gateway.log")),
+		WithSyslog(SyslogOptions{Network: "udp", Address: listener.LocalAddr().String(), Facility: "local0"}))
+	require.NoError(t, err)
+	log.Warn("Upstream unreachable")
+
+	buffer := make([]byte, 4096)
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := listener.ReadFrom(buffer)
+	require.NoError(t, err)
+	message := string(buffer[:n])
+	// local0 (16) * 8 + warning (4)
+	assert.Contains(t, message, "<132>")
+	assert.Contains(t, message, "mcp-gateway")
+	assert.Contains(t, message, `"msg":"Upstream unreachable"`)
+	assert.NotContains(t, message, `"ts"`)
+}
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces the values of the sensitive fields and the bearer tokens.
+const redactedValue = "[REDACTED]"
+
+// sensitiveFieldNames are the names of the fields whose values are never logged, lowercased without dashes
+// and underscores.
+var sensitiveFieldNames = map[string]bool{
+	"authorization": true,
+	"headers":       true,
+	"clientsecret":  true,
+	"privatekey":    true,
+	"password":      true,
+	"secret":        true,
+	"token":         true,
+	"accesstoken":   true,
+	"refreshtoken":  true,
+	"apikey":        true,
+	"adminapikey":   true,
+}
+
+// bearerTokenPattern matches the bearer tokens in the messages and string values.
+var bearerTokenPattern = regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`)
+
+// redactingCore masks the values of the sensitive fields and strips the bearer tokens from the messages and
+// string values before they are written, whatever logs them.
+type redactingCore struct {
+	zapcore.Core
+}
+
+func newRedactingCore(core zapcore.Core) zapcore.Core {
+	return &redactingCore{Core: core}
+}
+
+//nolint:revive // need to match the interface
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+//nolint:revive // need to match the interface
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+//nolint:revive // need to match the interface
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = stripBearerTokens(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+// redactFields returns the fields with the sensitive ones masked, copying them only if one changed.
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, field := range fields {
+		if replacement, changed := redactField(field); changed {
+			if redacted == nil {
+				redacted = slices.Clone(fields)
+			}
+			redacted[i] = replacement
+		}
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+func redactField(field zapcore.Field) (zapcore.Field, bool) {
+	if field.Type != zapcore.SkipType && field.Type != zapcore.NamespaceType && isSensitiveFieldName(field.Key) {
+		return zap.String(field.Key, redactedValue), true
+	}
+	switch field.Type {
+	case zapcore.StringType:
+		if stripped := stripBearerTokens(field.String); stripped != field.String {
+			return zap.String(field.Key, stripped), true
+		}
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok && err != nil {
+			message := err.Error()
+			if stripped := stripBearerTokens(message); stripped != message {
+				return zap.String(field.Key, stripped), true
+			}
+		}
+	}
+	return field, false
+}
+
+func isSensitiveFieldName(name string) bool {
+	name = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	return sensitiveFieldNames[name]
+}
+
+func stripBearerTokens(s string) string {
+	if !strings.Contains(strings.ToLower(s), "bearer") {
+		return s
+	}
+	return bearerTokenPattern.ReplaceAllString(s, "$1 "+redactedValue)
+}
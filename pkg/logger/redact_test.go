@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newFileLogger returns a JSON logger writing to a temporary file, and a function reading it.
+func newFileLogger(t *testing.T, options ...OptionLogger) (*ZapLogger, func() string) {
+	path := filepath.Join(t.TempDir(), "gateway.log")
+	log, err := NewLogger(append([]OptionLogger{WithFormat("json"), WithOutputPaths(path)}, options...)...)
+	require.NoError(t, err)
+	return log, func() string {
+		_ = log.Sync()
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	log, read := newFileLogger(t)
+
+	log.With(zap.String("client_secret", "s3cr3t")).Info("Calling with Bearer eyJhbGciOi.payload.sig",
+		zap.String("Authorization", "Basic dXNlcjpwYXNz"),
+		zap.Any("headers", map[string]string{"X-Api-Key": "key"}),
+		zap.String("url", "https://example.com/mcp"),
+		zap.String("request", "authorization: bearer abc123=="),
+		zap.Error(errors.New("upstream rejected Bearer abc123")))
+
+	output := read()
+	for _, secret := range []string{"s3cr3t", "eyJhbGciOi", "dXNlcjpwYXNz", "X-Api-Key", "abc123"} {
+		assert.NotContains(t, output, secret)
+	}
+	assert.Contains(t, output, `"msg":"Calling with Bearer [REDACTED]"`)
+	assert.Contains(t, output, `"client_secret":"[REDACTED]"`)
+	assert.Contains(t, output, `"headers":"[REDACTED]"`)
+	assert.Contains(t, output, `"url":"https://example.com/mcp"`)
+	assert.Contains(t, output, `"request":"authorization: bearer [REDACTED]"`)
+	assert.Contains(t, output, `"error":"upstream rejected Bearer [REDACTED]"`)
+}
+
+func TestSampling(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		options  []OptionLogger
+		expected int
+	}{
+		// The first entries, then every 100th one: the 200th and the 300th.
+		{name: "default", expected: 102},
+		{name: "sampled", options: []OptionLogger{WithSampling(2, 100)}, expected: 4},
+		{name: "disabled", options: []OptionLogger{WithSampling(0, 0)}, expected: 300},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			log, read := newFileLogger(t, test.options...)
+			for range 300 {
+				log.Info("Tool called")
+			}
+			assert.Equal(t, test.expected, strings.Count(read(), "Tool called"))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern": syslog.LOG_KERN, "user": syslog.LOG_USER, "mail": syslog.LOG_MAIL, "daemon": syslog.LOG_DAEMON,
+	"auth": syslog.LOG_AUTH, "syslog": syslog.LOG_SYSLOG, "lpr": syslog.LOG_LPR, "news": syslog.LOG_NEWS,
+	"uucp": syslog.LOG_UUCP, "cron": syslog.LOG_CRON, "authpriv": syslog.LOG_AUTHPRIV, "ftp": syslog.LOG_FTP,
+	"local0": syslog.LOG_LOCAL0, "local1": syslog.LOG_LOCAL1, "local2": syslog.LOG_LOCAL2, "local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4, "local5": syslog.LOG_LOCAL5, "local6": syslog.LOG_LOCAL6, "local7": syslog.LOG_LOCAL7,
+}
+
+// syslogCore writes the entries to syslog, with the priority of their level.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	writer  *syslog.Writer
+}
+
+func newSyslogCore(options SyslogOptions, encoder zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, error) {
+	facility := syslog.LOG_DAEMON
+	if options.Facility != "" {
+		var ok bool
+		if facility, ok = syslogFacilities[options.Facility]; !ok {
+			return nil, fmt.Errorf("unknown syslog facility: %s", options.Facility)
+		}
+	}
+	tag := options.Tag
+	if tag == "" {
+		tag = defaultSyslogTag
+	}
+	network := options.Network
+	if options.Address == "" {
+		network = ""
+	}
+	writer, err := syslog.Dial(network, options.Address, facility|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &syslogCore{LevelEnabler: level, encoder: encoder, writer: writer}, nil
+}
+
+//nolint:revive // need to match the interface
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	encoder := c.encoder.Clone()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	return &syslogCore{LevelEnabler: c.LevelEnabler, encoder: encoder, writer: c.writer}
+}
+
+//nolint:revive // need to match the interface
+func (c *syslogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+//nolint:revive // need to match the interface
+func (c *syslogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buffer, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	message := strings.TrimSuffix(buffer.String(), "\n")
+	buffer.Free()
+
+	switch {
+	case entry.Level >= zapcore.DPanicLevel:
+		return c.writer.Crit(message)
+	case entry.Level == zapcore.ErrorLevel:
+		return c.writer.Err(message)
+	case entry.Level == zapcore.WarnLevel:
+		return c.writer.Warning(message)
+	case entry.Level == zapcore.InfoLevel:
+		return c.writer.Info(message)
+	default:
+		return c.writer.Debug(message)
+	}
+}
+
+//nolint:revive // need to match the interface
+func (c *syslogCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,253 @@
+// Package redis is a minimal Redis client, running the commands with the RESP2 protocol on a single connection and
+// receiving the messages of a channel. It is enough for the caches and stores of the gateway shared by its replicas.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/pkg/fips"
+)
+
+const defaultPort = "6379"
+
+// Error is an error reply of the server. Unlike the network errors, it leaves the connection usable.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Client runs commands on a single connection to a Redis server. The connection is dialed on the first command and
+// again after any network error.
+type Client struct {
+	address  string
+	tls      bool
+	username string
+	password string
+	db       int
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewClient creates a client of the server of a redis:// or rediss:// (TLS) URL, whose path selects the database.
+// The password overrides the password of the URL if not empty. The timeout bounds the connection and each command.
+func NewClient(rawURL, password string, timeout time.Duration) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), defaultPort)
+	}
+	client := &Client{
+		address:  address,
+		tls:      u.Scheme == "rediss",
+		username: u.User.Username(),
+		password: password,
+		timeout:  timeout,
+	}
+	if password, ok := u.User.Password(); ok && client.password == "" {
+		client.password = password
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if client.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid Redis database %q", db)
+		}
+	}
+	return client, nil
+}
+
+// Do runs a command and returns its reply: a string, an int64, a []byte or nil for a bulk string, or a []any.
+func (c *Client) Do(ctx context.Context, args ...string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		conn, reader, err := c.dial(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		c.conn, c.reader = conn, reader
+	}
+	setDeadline(ctx, c.conn, c.timeout)
+	reply, err := command(c.conn, c.reader, args...)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		_ = c.conn.Close()
+		c.conn, c.reader = nil, nil
+	}
+	return reply, err
+}
+
+// Close closes the connection. The next command dials it again.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn, c.reader = nil, nil
+	}
+	return nil
+}
+
+// Receive subscribes to a channel on a dedicated connection and passes its messages to onMessage, until the
+// context is done or the connection fails. onSubscribe is called once subscribed.
+func (c *Client) Receive(ctx context.Context, channel string, onSubscribe func(), onMessage func(string)) error {
+	conn, reader, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() //nolint:errcheck // nothing interesting to do with the error
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	setDeadline(ctx, conn, c.timeout)
+	if _, err := command(conn, reader, "SUBSCRIBE", channel); err != nil {
+		return err
+	}
+	// The messages are awaited without deadline
+	_ = conn.SetDeadline(time.Time{})
+	onSubscribe()
+	for {
+		reply, err := ReadReply(reader)
+		if err != nil {
+			return err
+		}
+		message, ok := reply.([]any)
+		if !ok || len(message) != 3 {
+			continue
+		}
+		kind, _ := message[0].([]byte)
+		payload, ok := message[2].([]byte)
+		if string(kind) == "message" && ok {
+			onMessage(string(payload))
+		}
+	}
+}
+
+// dial connects to the server, over TLS for the rediss scheme, then authenticates and selects the database.
+func (c *Client) dial(ctx context.Context) (net.Conn, *bufio.Reader, error) {
+	dialer := &net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.tls {
+		host, _, _ := net.SplitHostPort(c.address)
+		tlsConn := tls.Client(conn, fips.TLSConfig(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}))
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, nil, fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+	reader := bufio.NewReader(conn)
+	setDeadline(ctx, conn, c.timeout)
+
+	var setup [][]string
+	switch {
+	case c.username != "" && c.password != "":
+		setup = append(setup, []string{"AUTH", c.username, c.password})
+	case c.password != "":
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	for _, args := range setup {
+		if _, err := command(conn, reader, args...); err != nil {
+			_ = conn.Close()
+			return nil, nil, fmt.Errorf("%s: %w", args[0], err)
+		}
+	}
+	return conn, reader, nil
+}
+
+func command(w io.Writer, r *bufio.Reader, args ...string) (any, error) {
+	if err := writeCommand(w, args...); err != nil {
+		return nil, err
+	}
+	return ReadReply(r)
+}
+
+// writeCommand writes a command as an array of bulk strings.
+func writeCommand(w io.Writer, args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ReadReply reads a reply, or a command sent as an array of bulk strings.
+func ReadReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty Redis reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		items := make([]any, size)
+		for i := range items {
+			if items[i], err = ReadReply(r); err != nil {
+				var replyErr Error
+				if !errors.As(err, &replyErr) {
+					return nil, err
+				}
+				items[i] = replyErr
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unexpected Redis reply %q", line)
+}
+
+// setDeadline bounds the next reads and writes by the timeout and the context's deadline.
+func setDeadline(ctx context.Context, conn net.Conn, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+}
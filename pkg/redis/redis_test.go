@@ -0,0 +1,75 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/pkg/redis"
+	"github.com/matthisholleville/mcp-gateway/pkg/redis/redistest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	_, err := redis.NewClient("redis://redis/db", "", time.Second)
+	assert.ErrorContains(t, err, "invalid Redis database")
+	_, err = redis.NewClient("redis://%zz", "", time.Second)
+	assert.ErrorContains(t, err, "invalid Redis URL")
+}
+
+func TestClient(t *testing.T) {
+	server := redistest.NewServer(t, "secret")
+	ctx := context.Background()
+
+	t.Run("the password overrides the password of the URL", func(t *testing.T) {
+		client, err := redis.NewClient(server.URL, "wrong", time.Second)
+		require.NoError(t, err)
+		defer client.Close() //nolint:errcheck // test
+		_, err = client.Do(ctx, "GET", "key")
+		assert.ErrorContains(t, err, "WRONGPASS")
+	})
+
+	client, err := redis.NewClient(server.URL, "", time.Second)
+	require.NoError(t, err)
+	defer client.Close() //nolint:errcheck // test
+
+	t.Run("the replies are decoded", func(t *testing.T) {
+		reply, err := client.Do(ctx, "SET", "key", "value")
+		require.NoError(t, err)
+		assert.Equal(t, "OK", reply)
+		reply, err = client.Do(ctx, "GET", "key")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), reply)
+		reply, err = client.Do(ctx, "DEL", "key", "missing")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), reply)
+		reply, err = client.Do(ctx, "GET", "key")
+		require.NoError(t, err)
+		assert.Nil(t, reply)
+	})
+
+	t.Run("an error reply leaves the connection usable", func(t *testing.T) {
+		_, err := client.Do(ctx, "UNKNOWN")
+		var replyErr redis.Error
+		assert.ErrorAs(t, err, &replyErr)
+		_, err = client.Do(ctx, "GET", "key")
+		assert.NoError(t, err)
+	})
+
+	t.Run("the messages of a channel are received", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		subscribed := make(chan struct{})
+		messages := make(chan string, 1)
+		done := make(chan error)
+		go func() {
+			done <- client.Receive(ctx, "channel", func() { close(subscribed) }, func(m string) { messages <- m })
+		}()
+		<-subscribed
+		_, err := client.Do(ctx, "PUBLISH", "channel", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "hello", <-messages)
+		cancel()
+		assert.Error(t, <-done)
+	})
+}
@@ -0,0 +1,179 @@
+// Package redistest provides a Redis server for the tests, keeping the strings in memory. It implements the commands
+// used by the gateway: AUTH, SELECT, GET, SET with PX, DEL, PEXPIRE, PUBLISH and SUBSCRIBE.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matthisholleville/mcp-gateway/pkg/redis"
+)
+
+// Server is a Redis server listening on the loopback interface until the end of the test.
+type Server struct {
+	// URL is the redis:// URL of the server, with its password.
+	URL string
+
+	password string
+	listener net.Listener
+
+	mu          sync.Mutex
+	values      map[string]value
+	subscribers map[string][]net.Conn
+	conns       map[net.Conn]bool
+}
+
+type value struct {
+	data      string
+	expiresAt time.Time
+}
+
+// NewServer starts a server requiring the password, if not empty.
+func NewServer(t testing.TB, password string) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &Server{
+		URL:         "redis://:" + password + "@" + listener.Addr().String() + "/1",
+		password:    password,
+		listener:    listener,
+		values:      make(map[string]value),
+		subscribers: make(map[string][]net.Conn),
+		conns:       make(map[net.Conn]bool),
+	}
+	t.Cleanup(s.Close)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns[conn] = true
+			s.mu.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// Close stops the server and closes its connections.
+func (s *Server) Close() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+// Get returns the value of a key, if set and not expired.
+func (s *Server) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.get(key)
+	return v.data, ok
+}
+
+func (s *Server) get(key string) (value, bool) {
+	v, ok := s.values[key]
+	if ok && !v.expiresAt.IsZero() && !time.Now().Before(v.expiresAt) {
+		delete(s.values, key)
+		return value{}, false
+	}
+	return v, ok
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+	reader := bufio.NewReader(conn)
+	authenticated := s.password == ""
+	for {
+		reply, err := redis.ReadReply(reader)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			data, _ := item.([]byte)
+			args[i] = string(data)
+		}
+		if len(args) == 0 {
+			return
+		}
+		if args[0] != "AUTH" && !authenticated {
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		s.mu.Lock()
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			authenticated = args[len(args)-1] == s.password
+			if authenticated {
+				fmt.Fprint(conn, "+OK\r\n")
+			} else {
+				fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+			}
+		case "SELECT":
+			fmt.Fprint(conn, "+OK\r\n")
+		case "GET":
+			if v, ok := s.get(args[1]); ok {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(v.data), v.data)
+			} else {
+				fmt.Fprint(conn, "$-1\r\n")
+			}
+		case "SET":
+			v := value{data: args[2]}
+			if len(args) == 5 && strings.EqualFold(args[3], "PX") {
+				ms, _ := strconv.Atoi(args[4])
+				v.expiresAt = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+			s.values[args[1]] = v
+			fmt.Fprint(conn, "+OK\r\n")
+		case "DEL":
+			deleted := 0
+			for _, key := range args[1:] {
+				if _, ok := s.get(key); ok {
+					delete(s.values, key)
+					deleted++
+				}
+			}
+			fmt.Fprintf(conn, ":%d\r\n", deleted)
+		case "PEXPIRE":
+			v, ok := s.get(args[1])
+			if ok {
+				ms, _ := strconv.Atoi(args[2])
+				v.expiresAt = time.Now().Add(time.Duration(ms) * time.Millisecond)
+				s.values[args[1]] = v
+				fmt.Fprint(conn, ":1\r\n")
+			} else {
+				fmt.Fprint(conn, ":0\r\n")
+			}
+		case "PUBLISH":
+			for _, subscriber := range s.subscribers[args[1]] {
+				fmt.Fprintf(subscriber, "*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(args[1]), args[1], len(args[2]), args[2])
+			}
+			fmt.Fprintf(conn, ":%d\r\n", len(s.subscribers[args[1]]))
+		case "SUBSCRIBE":
+			s.subscribers[args[1]] = append(s.subscribers[args[1]], conn)
+			fmt.Fprintf(conn, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(args[1]), args[1])
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+		}
+		s.mu.Unlock()
+	}
+}
@@ -28,3 +28,21 @@ func SetupSignalHandler() (stopCh <-chan struct{}) {
 
 	return stop
 }
+
+// SetupReloadHandler registered for SIGHUP. A channel is returned which receives a value on each of
+// these signals. The signals received while a reload is pending are coalesced.
+func SetupReloadHandler() <-chan struct{} {
+	reload := make(chan struct{}, 1)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, reloadSignals...)
+	go func() {
+		for range c {
+			select {
+			case reload <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return reload
+}
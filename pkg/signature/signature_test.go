@@ -0,0 +1,74 @@
+package signature
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport_RoundTrip(t *testing.T) {
+	var verifyErr error
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		verifyErr = Verify(r, "s3cr3t", DefaultMaxSkew)
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: NewTransport("s3cr3t", nil)}
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"method":"tools/call"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.NoError(t, verifyErr)
+	assert.Equal(t, `{"method":"tools/call"}`, received, "the body must be left readable")
+
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.NoError(t, verifyErr)
+
+	client.Transport = NewTransport("other", nil)
+	resp, err = client.Post(server.URL, "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.ErrorIs(t, verifyErr, ErrInvalidSignature)
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now().Unix()
+	for _, test := range []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		expected  error
+	}{
+		{name: "valid", secret: "s3cr3t", timestamp: now, body: `{"id":1}`},
+		{name: "wrong secret", secret: "other", timestamp: now, body: `{"id":1}`, expected: ErrInvalidSignature},
+		{name: "tampered body", secret: "s3cr3t", timestamp: now, body: `{"id":2}`, expected: ErrInvalidSignature},
+		{name: "expired", secret: "s3cr3t", timestamp: now - 600, body: `{"id":1}`, expected: ErrExpiredSignature},
+		{name: "future", secret: "s3cr3t", timestamp: now + 600, body: `{"id":1}`, expected: ErrExpiredSignature},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"id":1}`))
+			timestamp := strconv.FormatInt(test.timestamp, 10)
+			r.Header.Set(TimestampHeader, timestamp)
+			r.Header.Set(SignatureHeader, Sign([]byte(test.secret), timestamp, []byte(test.body)))
+			assert.ErrorIs(t, Verify(r, "s3cr3t", DefaultMaxSkew), test.expected)
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{}`))
+	assert.ErrorIs(t, Verify(r, "s3cr3t", DefaultMaxSkew), ErrMissingSignature)
+	r.Header.Set(TimestampHeader, "yesterday")
+	r.Header.Set(SignatureHeader, "sha256=00")
+	assert.ErrorIs(t, Verify(r, "s3cr3t", DefaultMaxSkew), ErrInvalidSignature)
+}
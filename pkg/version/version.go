@@ -0,0 +1,46 @@
+// Package version provides the build metadata of the MCP Gateway, injected at build time with ldflags:
+//
+//	go build -ldflags "-X github.com/matthisholleville/mcp-gateway/pkg/version.VERSION=1.2.3 \
+//	  -X github.com/matthisholleville/mcp-gateway/pkg/version.REVISION=$(git rev-parse --short HEAD) \
+//	  -X github.com/matthisholleville/mcp-gateway/pkg/version.BUILD_DATE=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+//nolint:revive,stylecheck // the variables are named after the build arguments
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+var (
+	// VERSION is the semantic version of the build.
+	VERSION = "dev"
+
+	// REVISION is the git SHA of the build.
+	REVISION = "unknown"
+
+	// BUILD_DATE is the date of the build.
+	BUILD_DATE = "unknown"
+)
+
+// Info is the build metadata.
+type Info struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the build metadata.
+func Get() Info {
+	return Info{
+		Version:   VERSION,
+		Revision:  REVISION,
+		BuildDate: BUILD_DATE,
+		GoVersion: runtime.Version(),
+	}
+}
+
+func (i Info) String() string {
+	return fmt.Sprintf("mcp-gateway %s (revision %s, built %s, %s)", i.Version, i.Revision, i.BuildDate, i.GoVersion)
+}
@@ -18,14 +18,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/v1/admin/attribute-to-roles": {
+        "/v1/admin/approvals": {
             "get": {
                 "security": [
                     {
                         "Authentication": []
                     }
                 ],
-                "description": "Get all attribute to roles",
+                "description": "Get the approvals of the tool calls, the latest first, pending or decided",
                 "consumes": [
                     "application/json"
                 ],
@@ -33,16 +33,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "attribute to roles"
+                    "approvals"
+                ],
+                "summary": "Get the approvals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Status of the approvals (pending, approved, denied, expired or cancelled), all when empty",
+                        "name": "status",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all attribute to roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/storage.AttributeToRolesConfig"
+                                "$ref": "#/definitions/storage.ApprovalRequest"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -56,14 +73,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/v1/admin/approvals/{id}": {
+            "get": {
                 "security": [
                     {
                         "Authentication": []
                     }
                 ],
-                "description": "Upsert a attribute to role",
+                "description": "Get the approval of a tool call, with its redacted arguments",
                 "consumes": [
                     "application/json"
                 ],
@@ -71,38 +90,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "attribute to roles"
+                    "approvals"
                 ],
-                "summary": "Upsert a attribute to role",
+                "summary": "Get an approval",
                 "parameters": [
                     {
-                        "description": "Attribute to role",
-                        "name": "attributeToRole",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/storage.AttributeToRolesConfig"
-                        }
+                        "type": "string",
+                        "description": "Approval ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/storage.AttributeToRolesConfig"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/storage.ApprovalRequest"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -113,14 +121,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/admin/attribute-to-roles/{attributeKey}/{attributeValue}": {
-            "delete": {
+        "/v1/admin/approvals/{id}/approve": {
+            "post": {
                 "security": [
                     {
                         "Authentication": []
                     }
                 ],
-                "description": "Delete a attribute to role",
+                "description": "Approve a pending tool call, which is then forwarded upstream",
                 "consumes": [
                     "application/json"
                 ],
@@ -128,28 +136,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "attribute to roles"
+                    "approvals"
                 ],
-                "summary": "Delete a attribute to role",
+                "summary": "Approve a tool call",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Attribute key",
-                        "name": "attributeKey",
+                        "description": "Approval ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Attribute value",
-                        "name": "attributeValue",
-                        "in": "path",
-                        "required": true
+                        "description": "Decision",
+                        "name": "decision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.ApprovalDecisionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.ApprovalRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -157,8 +173,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -178,14 +203,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/admin/proxies": {
-            "get": {
+        "/v1/admin/approvals/{id}/deny": {
+            "post": {
                 "security": [
                     {
                         "Authentication": []
                     }
                 ],
-                "description": "Get all proxies",
+                "description": "Deny a pending tool call, which then fails with the reason",
                 "consumes": [
                     "application/json"
                 ],
@@ -193,16 +218,58 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "proxies"
+                    "approvals"
+                ],
+                "summary": "Deny a tool call",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Approval ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Decision",
+                        "name": "decision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.ApprovalDecisionRequest"
+                        }
+                    }
                 ],
-                "summary": "Get all proxies",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/storage.ProxyConfig"
+                            "$ref": "#/definitions/storage.ApprovalRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -218,14 +285,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/admin/proxies/{name}": {
+        "/v1/admin/attribute-to-roles": {
             "get": {
                 "security": [
                     {
                         "Authentication": []
                     }
                 ],
-                "description": "Get a proxy",
+                "description": "Get all attribute to roles",
                 "consumes": [
                     "application/json"
                 ],
@@ -233,23 +300,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "proxies"
-                ],
-                "summary": "Get a proxy",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Proxy name",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
-                    }
+                    "attribute to roles"
                 ],
+                "summary": "Get all attribute to roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/storage.ProxyConfig"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.AttributeToRolesConfig"
+                            }
                         }
                     },
                     "500": {
@@ -269,7 +330,7 @@ const docTemplate = `{
                         "Authentication": []
                     }
                 ],
-                "description": "Upsert a proxy",
+                "description": "Upsert a attribute to role",
                 "consumes": [
                     "application/json"
                 ],
@@ -277,17 +338,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "proxies"
+                    "attribute to roles"
                 ],
-                "summary": "Upsert a proxy",
+                "summary": "Upsert a attribute to role",
                 "parameters": [
                     {
-                        "description": "Proxy",
-                        "name": "proxy",
+                        "description": "Attribute to role",
+                        "name": "attributeToRole",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/storage.ProxyConfig"
+                            "$ref": "#/definitions/storage.AttributeToRolesConfig"
                         }
                     }
                 ],
@@ -295,7 +356,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/storage.ProxyConfig"
+                            "$ref": "#/definitions/storage.AttributeToRolesConfig"
                         }
                     },
                     "400": {
@@ -317,14 +378,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/v1/admin/attribute-to-roles/{attributeKey}/{attributeValue}": {
             "delete": {
                 "security": [
                     {
                         "Authentication": []
                     }
                 ],
-                "description": "Delete a proxy",
+                "description": "Delete a attribute to role",
                 "consumes": [
                     "application/json"
                 ],
@@ -332,14 +395,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "proxies"
+                    "attribute to roles"
                 ],
-                "summary": "Delete a proxy",
+                "summary": "Delete a attribute to role",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Proxy name",
-                        "name": "name",
+                        "description": "Attribute key",
+                        "name": "attributeKey",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attribute value",
+                        "name": "attributeValue",
                         "in": "path",
                         "required": true
                     }
@@ -354,6 +424,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -366,14 +445,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/admin/roles": {
-            "get": {
+        "/v1/admin/attribute-to-roles:plan": {
+            "post": {
                 "security": [
                     {
                         "Authentication": []
                     }
                 ],
-                "description": "Get all roles",
+                "description": "Compare a full set of attribute-to-roles mappings with the current ones and return the roles that replacing them would add and remove, without applying anything. The current mappings missing from the set are deleted, and the unchanged ones are omitted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -381,16 +460,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
+                    "attribute to roles"
+                ],
+                "summary": "Plan attribute to roles",
+                "parameters": [
+                    {
+                        "description": "Desired attribute to roles",
+                        "name": "attributeToRoles",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.AttributeToRolesConfig"
+                            }
+                        }
+                    }
                 ],
-                "summary": "Get all roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/storage.RoleConfig"
+                                "$ref": "#/definitions/manifest.MappingChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -404,14 +506,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/v1/admin/audit": {
+            "get": {
                 "security": [
                     {
                         "Authentication": []
                     }
                 ],
-                "description": "Upsert a role",
+                "description": "Get the entries of the tamper-evident audit log following a sequence number, in order",
                 "consumes": [
                     "application/json"
                 ],
@@ -419,25 +523,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
+                    "audit"
                 ],
-                "summary": "Upsert a role",
+                "summary": "Get the audit log",
                 "parameters": [
                     {
-                        "description": "Role",
-                        "name": "role",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/storage.RoleConfig"
-                        }
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Sequence number of the last entry already read",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum number of entries",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/storage.RoleConfig"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.AuditEntry"
+                            }
                         }
                     },
                     "400": {
@@ -461,14 +573,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/admin/roles/{role}": {
-            "delete": {
+        "/v1/admin/audit/verify": {
+            "get": {
                 "security": [
                     {
                         "Authentication": []
                     }
                 ],
-                "description": "Delete a role",
+                "description": "Check that every entry of the audit log chains to the previous one and matches its hash, proving the log was not altered",
                 "consumes": [
                     "application/json"
                 ],
@@ -476,28 +588,147 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
-                ],
-                "summary": "Delete a role",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Role",
-                        "name": "role",
-                        "in": "path",
-                        "required": true
-                    }
+                    "audit"
                 ],
+                "summary": "Verify the audit log",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.AuditVerification"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    },
+                    }
+                }
+            }
+        },
+        "/v1/admin/authz/check": {
+            "post": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Check whether the given claims (or the claims of a token) grant access to an object, and which role and rule decided it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authz"
+                ],
+                "summary": "Simulate a permission check",
+                "parameters": [
+                    {
+                        "description": "Permission check",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.AuthzCheckRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.PermissionDecision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/budgets": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the budgets limiting the cost of the tool calls of each role per window",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Get all budgets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.BudgetConfig"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Create or update a budget, shared by every subject holding the role. The usage is kept on update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Upsert a budget",
+                "parameters": [
+                    {
+                        "description": "Budget",
+                        "name": "budget",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/storage.BudgetConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -518,34 +749,3103 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "storage.AttributeToRolesConfig": {
+        },
+        "/v1/admin/budgets/{name}": {
+            "delete": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Delete a budget and its usage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Delete a budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/budgets/{name}/usage": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the cost consumed and left in the current window of a budget",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Get the usage of a budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.BudgetUsage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Reset the cost consumed in the current window of a budget",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Reset the usage of a budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/classifications": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the classification levels of the tools, compared to the clearance of the roles on each call",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classifications"
+                ],
+                "summary": "Get all classification labels",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.ClassificationConfig"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Create or update the classification level of a tool, of the tools of a proxy with the '*' tool, or of every tool with the '*' proxy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classifications"
+                ],
+                "summary": "Upsert a classification label",
+                "parameters": [
+                    {
+                        "description": "Classification label",
+                        "name": "classification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/storage.ClassificationConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/classifications/{proxy}/{tool}": {
+            "delete": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Delete the classification label of a tool",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classifications"
+                ],
+                "summary": "Delete a classification label",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Proxy name",
+                        "name": "proxy",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tool name",
+                        "name": "tool",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/composite-tools": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the composite tools, exposed to the clients as composite:\u003cname\u003e and calling a sequence of upstream tools",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "composite-tools"
+                ],
+                "summary": "Get all composite tools",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.CompositeToolConfig"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Create or update a composite tool. Its steps call upstream tools in order, the parallel steps together with the step before them, and their arguments reference the arguments of the tool as ${args.\u003cname\u003e} and the results of the earlier steps as ${steps.\u003cid\u003e}. It is registered with the next proxy refresh.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "composite-tools"
+                ],
+                "summary": "Upsert a composite tool",
+                "parameters": [
+                    {
+                        "description": "Composite tool",
+                        "name": "tool",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/storage.CompositeToolConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/composite-tools/{name}": {
+            "delete": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Delete a composite tool, unregistered with the next proxy refresh",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "composite-tools"
+                ],
+                "summary": "Delete a composite tool",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Composite tool name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/config": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the configuration the server runs with, resolved from the flags, the environment, the config file and the defaults, with the secrets redacted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "config"
+                ],
+                "summary": "Get the effective configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/events": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Server-sent events stream of the tool calls, proxy health checks and admin changes, in real time",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream the gateway events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated event types to receive (tool_call, proxy_health, admin_mutation)",
+                        "name": "types",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/events.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/export": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Export the proxies, roles and attribute-to-roles mappings, in the manifest format of the apply command",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshot"
+                ],
+                "summary": "Export a snapshot",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include the header values, OAuth client secrets and HMAC secrets of the proxies",
+                        "name": "secrets",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/manifest.Manifest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/import": {
+            "post": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Create and update the objects of a snapshot. An empty header value or client secret keeps the value of the backend.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshot"
+                ],
+                "summary": "Import a snapshot",
+                "parameters": [
+                    {
+                        "description": "Snapshot",
+                        "name": "snapshot",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/manifest.Manifest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Delete the objects missing from the snapshot",
+                        "name": "prune",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Return the changes without applying them",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/maintenance": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get whether the tool calls are rejected for a maintenance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "config"
+                ],
+                "summary": "Get the maintenance mode",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.MaintenanceStatus"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Enable or disable the maintenance mode of the gateway replica serving the request. While enabled, the tool calls are rejected with the message; tools/list and the admin APIs keep working.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "config"
+                ],
+                "summary": "Set the maintenance mode",
+                "parameters": [
+                    {
+                        "description": "Maintenance mode",
+                        "name": "maintenance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.MaintenanceStatus"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.MaintenanceStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/proxies": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get all proxies, or those having all the labels of the label parameters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "proxies"
+                ],
+                "summary": "Get all proxies",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "key=value labels of the proxies, e.g. team=payments",
+                        "name": "label",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.ProxyConfig"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/proxies/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get a proxy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "proxies"
+                ],
+                "summary": "Get a proxy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Proxy name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.ProxyConfig"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Upsert a proxy. With validate=connect, the gateway first connects to the upstream server and rejects the proxy if it can not.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "proxies"
+                ],
+                "summary": "Upsert a proxy",
+                "parameters": [
+                    {
+                        "description": "Proxy",
+                        "name": "proxy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/storage.ProxyConfig"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "connect"
+                        ],
+                        "type": "string",
+                        "description": "connect to check that the URL and credentials work before saving",
+                        "name": "validate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.ProxyConfig"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Delete a proxy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "proxies"
+                ],
+                "summary": "Delete a proxy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Proxy name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/proxies/{name}/tools": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the tools currently registered by the gateway for a proxy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "proxies"
+                ],
+                "summary": "Get the tools of a proxy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Proxy name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.ProxyToolsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/proxies/{name}/tools/{tool}/call": {
+            "post": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Call a tool of a proxy with the given arguments under the admin identity and return the raw result",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "proxies"
+                ],
+                "summary": "Call a tool",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Proxy name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tool name, without the proxy prefix",
+                        "name": "tool",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tool arguments",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.CallToolRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/proxies:discover": {
+            "post": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Connect to an MCP server given its URL and auth, and return the proxy configuration pre-populated from the server name, with its version, capabilities and tools. The proxy is not saved. The name is derived from the server name if empty, and the timeout is in seconds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "proxies"
+                ],
+                "summary": "Discover a proxy",
+                "parameters": [
+                    {
+                        "description": "URL and auth of the proxy",
+                        "name": "proxy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/storage.ProxyConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.DiscoverProxyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/quotas": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the quotas limiting the tool calls of each subject per hour, day, week or month",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotas"
+                ],
+                "summary": "Get all quotas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.QuotaConfig"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Create or update a quota. A role quota limits every subject holding the role separately, the usage is kept on update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotas"
+                ],
+                "summary": "Upsert a quota",
+                "parameters": [
+                    {
+                        "description": "Quota",
+                        "name": "quota",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/storage.QuotaConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/quotas/{name}": {
+            "delete": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Delete a quota and its usage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotas"
+                ],
+                "summary": "Delete a quota",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quota name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/quotas/{name}/usage": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the calls of each subject in the current window of a quota",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotas"
+                ],
+                "summary": "Get the usage of a quota",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quota name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.QuotaUsage"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Reset the calls of a subject, or of every subject, in the current window of a quota",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quotas"
+                ],
+                "summary": "Reset the usage of a quota",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quota name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subject to reset, every subject when empty",
+                        "name": "subject",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/reload": {
+            "post": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Reload the configuration and apply its reloadable settings (log level, CORS, proxy cache TTL, maintenance mode) without restarting",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "config"
+                ],
+                "summary": "Reload the configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.ReloadResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/roles": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get all roles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Get all roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.RoleConfig"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Upsert a role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Upsert a role",
+                "parameters": [
+                    {
+                        "description": "Role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/storage.RoleConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.RoleConfig"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/roles/from-template": {
+            "post": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Create a role from a built-in template: admin, operator, read-only or per-proxy-user. The role must not exist.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Create a role from a template",
+                "parameters": [
+                    {
+                        "description": "Template, role name and proxy",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.RoleFromTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/storage.RoleConfig"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/roles/templates": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "List the built-in role templates, instantiated with POST /v1/admin/roles/from-template",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List the role templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/server.RoleTemplate"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/roles/{role}": {
+            "delete": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Delete a role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Delete a role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/search": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Search case-insensitively the proxy names, URLs, labels, descriptions and owners, the role names, the proxies and object names of their permissions, and the attributes and roles of the attribute-to-roles mappings. A permission granted with a wildcard or a label selector only matches the query if its text does: use /v1/admin/authz/check for the effective decision.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Search the configuration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Text to search",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/server.SearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/stats": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Summarize the tool calls, error rates, top tools and top identities over a window",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get usage statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "24h",
+                        "description": "Window (1h, 24h, 7d, 30d)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of top tools and identities",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.UsageStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/tool-costs": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the cost weights of the tool calls charged to the budgets. The tools without a cost cost 1.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Get all tool costs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.ToolCostConfig"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Create or update the cost of a tool, or of the tools of a proxy with the '*' tool",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Upsert a tool cost",
+                "parameters": [
+                    {
+                        "description": "Tool cost",
+                        "name": "cost",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/storage.ToolCostConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/tool-costs/{proxy}/{tool}": {
+            "delete": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Delete the cost of a tool",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Delete a tool cost",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Proxy name",
+                        "name": "proxy",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tool name",
+                        "name": "tool",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/tool-policies": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the policies marking the tools whose calls must be approved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Get all tool policies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.ToolPolicyConfig"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Create or update the policy of a tool, or of the tools of a proxy with the '*' tool",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Upsert a tool policy",
+                "parameters": [
+                    {
+                        "description": "Tool policy",
+                        "name": "policy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/storage.ToolPolicyConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/tool-policies/{proxy}/{tool}": {
+            "delete": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Delete the policy of a tool",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Delete a tool policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Proxy name",
+                        "name": "proxy",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tool name",
+                        "name": "tool",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/traces": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the detailed traces of the tool calls kept for the retention, the latest first, with the timings of their authorization, storage and upstream stages, their redacted arguments and their result size. The durations are in nanoseconds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "traces"
+                ],
+                "summary": "Get the tool call traces",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Proxy of the calls",
+                        "name": "proxy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tool of the calls",
+                        "name": "tool",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Identity of the callers",
+                        "name": "identity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only the failed calls",
+                        "name": "errors",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time of the first calls",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time of the end of the calls, excluded",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum number of traces",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.ToolCallTrace"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/traces/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the detailed trace of a tool call given its ID, the correlation_id of its logs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "traces"
+                ],
+                "summary": "Get a tool call trace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Trace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.ToolCallTrace"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/usage": {
+            "get": {
+                "security": [
+                    {
+                        "Authentication": []
+                    }
+                ],
+                "description": "Get the tool call counts and durations rolled up by UTC day, identity, proxy and tool, for the chargeback",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get the daily usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD (default 29 days before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD (default today)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "Response format (json, csv)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.DailyUsage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.PermissionDecision": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "classification": {
+                    "description": "Classification is the classification level of the object, which the clearance of the matched role covers.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/storage.Classification"
+                        }
+                    ]
+                },
+                "matchedPermission": {
+                    "$ref": "#/definitions/storage.PermissionConfig"
+                },
+                "matchedRole": {
+                    "description": "MatchedRole and MatchedPermission are the role and rule that granted the access, if any.",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "roles": {
+                    "description": "Roles are the roles resolved from the claims.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "events.Event": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/events.Type"
+                }
+            }
+        },
+        "events.Type": {
+            "type": "string",
+            "enum": [
+                "tool_call",
+                "proxy_health"
+            ],
+            "x-enum-varnames": [
+                "TypeToolCall",
+                "TypeProxyHealth"
+            ]
+        },
+        "manifest.Action": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "ActionCreate",
+                "ActionUpdate",
+                "ActionDelete"
+            ]
+        },
+        "manifest.Change": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/manifest.Action"
+                },
+                "kind": {
+                    "$ref": "#/definitions/manifest.Kind"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "manifest.Kind": {
+            "type": "string",
+            "enum": [
+                "proxy",
+                "role",
+                "mapping"
+            ],
+            "x-enum-varnames": [
+                "KindProxy",
+                "KindRole",
+                "KindAttributeToRoles"
+            ]
+        },
+        "manifest.Manifest": {
+            "type": "object",
+            "properties": {
+                "attributeToRoles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.AttributeToRolesConfig"
+                    }
+                },
+                "proxies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/manifest.Proxy"
+                    }
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.RoleConfig"
+                    }
+                }
+            }
+        },
+        "manifest.MappingChange": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/manifest.Action"
+                },
+                "added_roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "attribute_key": {
+                    "type": "string"
+                },
+                "attribute_value": {
+                    "type": "string"
+                },
+                "removed_roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "manifest.Proxy": {
+            "type": "object",
+            "properties": {
+                "authType": {
+                    "$ref": "#/definitions/storage.ProxyAuthType"
+                },
+                "description": {
+                    "description": "Description tells what the upstream server is.",
+                    "type": "string"
+                },
+                "docsUrl": {
+                    "description": "DocsURL is the link to the documentation of the upstream server.",
+                    "type": "string"
+                },
+                "egressProxy": {
+                    "description": "EgressProxy is the URL of the HTTP, HTTPS or SOCKS5 proxy through which the gateway connects to the\nupstream server, instead of the egress proxy of the gateway, or direct to connect without proxy.",
+                    "type": "string"
+                },
+                "headers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.ProxyHeader"
+                    }
+                },
+                "keepaliveInterval": {
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Labels are free-form key=value pairs, e.g. team=payments, to list the proxies and grant permissions on them.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "maxResponseBytes": {
+                    "description": "MaxResponseBytes caps the size of the tool results, instead of the maximum of the gateway, and\nResponseSizePolicy tells whether the larger ones are truncated or rejected, instead of the policy of the gateway.",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "oauth": {
+                    "$ref": "#/definitions/storage.ProxyOAuth"
+                },
+                "owner": {
+                    "description": "Owner is who to contact about the upstream server, e.g. an email.",
+                    "type": "string"
+                },
+                "responseSizePolicy": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/storage.ProxyType"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "mcp.Implementation": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "mcp.ServerCapabilities": {
+            "type": "object",
+            "properties": {
+                "experimental": {
+                    "description": "Experimental, non-standard capabilities that the server supports.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "logging": {
+                    "description": "Present if the server supports sending log messages to the client.",
+                    "type": "object"
+                },
+                "prompts": {
+                    "description": "Present if the server offers any prompt templates.",
+                    "type": "object",
+                    "properties": {
+                        "listChanged": {
+                            "description": "Whether this server supports notifications for changes to the prompt list.",
+                            "type": "boolean"
+                        }
+                    }
+                },
+                "resources": {
+                    "description": "Present if the server offers any resources to read.",
+                    "type": "object",
+                    "properties": {
+                        "listChanged": {
+                            "description": "Whether this server supports notifications for changes to the resource list.",
+                            "type": "boolean"
+                        },
+                        "subscribe": {
+                            "description": "Whether this server supports subscribing to resource updates.",
+                            "type": "boolean"
+                        }
+                    }
+                },
+                "tools": {
+                    "description": "Present if the server offers any tools to call.",
+                    "type": "object",
+                    "properties": {
+                        "listChanged": {
+                            "description": "Whether this server supports notifications for changes to the tool list.",
+                            "type": "boolean"
+                        }
+                    }
+                }
+            }
+        },
+        "server.ApprovalDecisionRequest": {
+            "type": "object",
+            "properties": {
+                "approver": {
+                    "description": "Approver is who decided, recorded in the approval.",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.AuthzCheckRequest": {
+            "type": "object",
+            "properties": {
+                "claims": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "objectName": {
+                    "type": "string"
+                },
+                "objectType": {
+                    "type": "string"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.CallToolRequest": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "server.DiscoverProxyResponse": {
+            "type": "object",
+            "properties": {
+                "capabilities": {
+                    "$ref": "#/definitions/mcp.ServerCapabilities"
+                },
+                "instructions": {
+                    "type": "string"
+                },
+                "protocolVersion": {
+                    "type": "string"
+                },
+                "proxy": {
+                    "$ref": "#/definitions/storage.ProxyConfig"
+                },
+                "serverInfo": {
+                    "$ref": "#/definitions/mcp.Implementation"
+                },
+                "stateful": {
+                    "description": "Stateful is true if the server opened a session.",
+                    "type": "boolean"
+                },
+                "tools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.ToolSummary"
+                    }
+                },
+                "warnings": {
+                    "description": "Warnings are the points of the configuration to review.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "server.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/manifest.Change"
+                    }
+                },
+                "dryRun": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "server.InputSchemaSummary": {
+            "type": "object",
+            "properties": {
+                "properties": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "required": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.MaintenanceStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "description": "Message is the error returned to the rejected tool calls.",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt is when the maintenance mode was last set.",
+                    "type": "string"
+                }
+            }
+        },
+        "server.ProxyToolsResponse": {
+            "type": "object",
+            "properties": {
+                "proxy": {
+                    "type": "string"
+                },
+                "syncedAt": {
+                    "description": "SyncedAt is the last time the tools were fetched from the upstream server, null if never.",
+                    "type": "string"
+                },
+                "tools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.ToolSummary"
+                    }
+                }
+            }
+        },
+        "server.ReloadResult": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "Applied are the reloadable settings that changed and were applied.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "restartRequired": {
+                    "description": "RestartRequired are the settings that changed but are only applied on restart.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "server.RoleFromTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "clearance": {
+                    "description": "Clearance overrides the clearance of the template.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/storage.Classification"
+                        }
+                    ]
+                },
+                "name": {
+                    "description": "Name is the name of the role, which must not exist.",
+                    "type": "string"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "template": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.RoleTemplate": {
+            "type": "object",
+            "properties": {
+                "clearance": {
+                    "description": "Clearance is the default clearance of the instantiated roles.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/storage.Classification"
+                        }
+                    ]
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "requiresProxy": {
+                    "description": "RequiresProxy is true if the template is instantiated for a proxy, optional if false.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "server.SearchResult": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field is the matching field, e.g. url, labels.team or object_name.",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "Kind is proxy, role, permission or attribute_to_roles.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the proxy or role name, or the attribute_key=attribute_value of a mapping.",
+                    "type": "string"
+                },
+                "permission": {
+                    "description": "Permission is the matching permission of the role, for the permission results.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/storage.PermissionConfig"
+                        }
+                    ]
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.ToolSummary": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "inputSchema": {
+                    "$ref": "#/definitions/server.InputSchemaSummary"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.ApprovalRequest": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "description": "Arguments are the arguments of the call, redacted like in the logs.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "decidedAt": {
+                    "type": "string"
+                },
+                "decidedBy": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "identity": {
+                    "type": "string"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "requestedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/storage.ApprovalStatus"
+                },
+                "tool": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.ApprovalStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "denied",
+                "expired",
+                "cancelled"
+            ],
+            "x-enum-comments": {
+                "ApprovalStatusCancelled": "ApprovalStatusCancelled is the status of the approvals whose caller went away before the decision.",
+                "ApprovalStatusExpired": "ApprovalStatusExpired is the status of the approvals not decided in time."
+            },
+            "x-enum-varnames": [
+                "ApprovalStatusPending",
+                "ApprovalStatusApproved",
+                "ApprovalStatusDenied",
+                "ApprovalStatusExpired",
+                "ApprovalStatusCancelled"
+            ]
+        },
+        "storage.AttributeToRolesConfig": {
+            "type": "object",
+            "properties": {
+                "attribute_key": {
+                    "type": "string"
+                },
+                "attribute_value": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "storage.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Data is the JSON record, e.g. a tool call or an admin mutation, hashed as is.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "prevHash": {
+                    "description": "PrevHash is the hash of the previous entry, empty for the first one.",
+                    "type": "string"
+                },
+                "recordedAt": {
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "Seq is the position of the entry in the log, starting at 1 with no gap.",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.AuditVerification": {
+            "type": "object",
+            "properties": {
+                "brokenAt": {
+                    "description": "BrokenAt is the sequence number of the first entry breaking the chain.",
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "integer"
+                },
+                "lastHash": {
+                    "description": "LastHash is the hash of the last verified entry. Keeping it outside of the gateway also proves that no entry\nwas removed from the end of the log.",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "storage.BudgetAction": {
+            "type": "string",
+            "enum": [
+                "deny",
+                "warn"
+            ],
+            "x-enum-comments": {
+                "BudgetActionDeny": "BudgetActionDeny rejects the calls which would exceed the budget.",
+                "BudgetActionWarn": "BudgetActionWarn lets the calls through, logging a warning."
+            },
+            "x-enum-varnames": [
+                "BudgetActionDeny",
+                "BudgetActionWarn"
+            ]
+        },
+        "storage.BudgetConfig": {
             "type": "object",
             "properties": {
-                "attribute_key": {
+                "action": {
+                    "$ref": "#/definitions/storage.BudgetAction"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "attribute_value": {
+                "role": {
                     "type": "string"
                 },
-                "roles": {
+                "window": {
+                    "$ref": "#/definitions/storage.QuotaWindow"
+                }
+            }
+        },
+        "storage.BudgetUsage": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "type": "string"
+                },
+                "consumed": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "remaining": {
+                    "type": "integer"
+                },
+                "resetsAt": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "windowStart": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.Classification": {
+            "type": "string",
+            "enum": [
+                "public",
+                "internal",
+                "confidential"
+            ],
+            "x-enum-varnames": [
+                "ClassificationPublic",
+                "ClassificationInternal",
+                "ClassificationConfidential"
+            ]
+        },
+        "storage.ClassificationConfig": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "$ref": "#/definitions/storage.Classification"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "tool": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.CompositeStep": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parallel": {
+                    "description": "Parallel runs the step concurrently with the previous step, which it cannot reference.",
+                    "type": "boolean"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "tool": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.CompositeToolConfig": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "inputSchema": {
+                    "description": "InputSchema is the JSON schema of the arguments of the tool, an object accepting any argument if empty.",
+                    "type": "object"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "steps": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/storage.CompositeStep"
                     }
                 }
             }
         },
+        "storage.DailyUsage": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer"
+                },
+                "day": {
+                    "description": "Day is the UTC day of the calls, as YYYY-MM-DD.",
+                    "type": "string"
+                },
+                "durationMs": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "identity": {
+                    "type": "string"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "tool": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.IdentityUsage": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer"
+                },
+                "errorRate": {
+                    "type": "number"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "identity": {
+                    "type": "string"
+                }
+            }
+        },
         "storage.ObjectType": {
             "type": "string",
             "enum": [
                 "tools",
+                "resources",
+                "prompts",
                 "*"
             ],
+            "x-enum-comments": {
+                "ObjectTypePrompts": "ObjectTypePrompts grants the argument completions of the prompts, named without the proxy prefix.",
+                "ObjectTypeResources": "ObjectTypeResources grants the reads of the templated resources, named by their URI without the proxy prefix."
+            },
             "x-enum-varnames": [
                 "ObjectTypeTools",
+                "ObjectTypeResources",
+                "ObjectTypePrompts",
                 "ObjectTypeAll"
             ]
         },
@@ -559,6 +3859,7 @@ const docTemplate = `{
                     "$ref": "#/definitions/storage.ObjectType"
                 },
                 "proxy": {
+                    "description": "Proxy is a proxy name, * for all the proxies, or label:KEY=VALUE[,KEY=VALUE] for the proxies having the labels.",
                     "type": "string"
                 }
             }
@@ -567,11 +3868,13 @@ const docTemplate = `{
             "type": "string",
             "enum": [
                 "header",
-                "oauth"
+                "oauth",
+                "hmac"
             ],
             "x-enum-varnames": [
                 "ProxyAuthTypeHeader",
-                "ProxyAuthTypeOAuth"
+                "ProxyAuthTypeOAuth",
+                "ProxyAuthTypeHMAC"
             ]
         },
         "storage.ProxyConfig": {
@@ -580,18 +3883,59 @@ const docTemplate = `{
                 "authType": {
                     "$ref": "#/definitions/storage.ProxyAuthType"
                 },
+                "description": {
+                    "description": "Description tells what the upstream server is.",
+                    "type": "string"
+                },
+                "docsUrl": {
+                    "description": "DocsURL is the link to the documentation of the upstream server.",
+                    "type": "string"
+                },
+                "egressProxy": {
+                    "description": "EgressProxy is the URL of the HTTP, HTTPS or SOCKS5 proxy through which the gateway connects to the\nupstream server, instead of the egress proxy of the gateway, or direct to connect without proxy.",
+                    "type": "string"
+                },
                 "headers": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/storage.ProxyHeader"
                     }
                 },
+                "hmac": {
+                    "$ref": "#/definitions/storage.ProxyHMAC"
+                },
+                "keepaliveInterval": {
+                    "description": "KeepaliveInterval is how long an upstream session of a client is unused before it is pinged, instead of the\nkeepalive interval of the gateway.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "labels": {
+                    "description": "Labels are free-form key=value pairs, e.g. team=payments, to list the proxies and grant permissions on them.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "maxResponseBytes": {
+                    "description": "MaxResponseBytes caps the size of the tool results, instead of the maximum of the gateway, and\nResponseSizePolicy tells whether the larger ones are truncated or rejected, instead of the policy of the gateway.",
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 },
                 "oauth": {
                     "$ref": "#/definitions/storage.ProxyOAuth"
                 },
+                "owner": {
+                    "description": "Owner is who to contact about the upstream server, e.g. an email.",
+                    "type": "string"
+                },
+                "responseSizePolicy": {
+                    "type": "string"
+                },
                 "timeout": {
                     "$ref": "#/definitions/time.Duration"
                 },
@@ -603,6 +3947,14 @@ const docTemplate = `{
                 }
             }
         },
+        "storage.ProxyHMAC": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
         "storage.ProxyHeader": {
             "type": "object",
             "properties": {
@@ -640,9 +3992,93 @@ const docTemplate = `{
                 "ProxyTypeStreamableHTTP"
             ]
         },
+        "storage.QuotaConfig": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "Subject is the role name or the JWT subject the quota applies to.",
+                    "type": "string"
+                },
+                "subject_type": {
+                    "$ref": "#/definitions/storage.QuotaSubjectType"
+                },
+                "tool": {
+                    "type": "string"
+                },
+                "window": {
+                    "$ref": "#/definitions/storage.QuotaWindow"
+                }
+            }
+        },
+        "storage.QuotaSubjectType": {
+            "type": "string",
+            "enum": [
+                "role",
+                "subject"
+            ],
+            "x-enum-varnames": [
+                "QuotaSubjectTypeRole",
+                "QuotaSubjectTypeSubject"
+            ]
+        },
+        "storage.QuotaUsage": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "quota": {
+                    "type": "string"
+                },
+                "resetsAt": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "windowStart": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.QuotaWindow": {
+            "type": "string",
+            "enum": [
+                "hour",
+                "day",
+                "week",
+                "month"
+            ],
+            "x-enum-varnames": [
+                "QuotaWindowHour",
+                "QuotaWindowDay",
+                "QuotaWindowWeek",
+                "QuotaWindowMonth"
+            ]
+        },
         "storage.RoleConfig": {
             "type": "object",
             "properties": {
+                "clearance": {
+                    "description": "Clearance is the highest classification level of the tools the role may call, public if empty.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/storage.Classification"
+                        }
+                    ]
+                },
                 "name": {
                     "type": "string"
                 },
@@ -654,8 +4090,153 @@ const docTemplate = `{
                 }
             }
         },
+        "storage.ToolCallTrace": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "description": "Arguments are the arguments of the call, redacted like in the logs.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "authDuration": {
+                    "description": "AuthDuration is the time spent verifying the token and the permissions of the request.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "calledAt": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "Duration is how long the call took from the start of its request.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "ID is the correlation ID of the call, found in its logs.",
+                    "type": "string"
+                },
+                "identity": {
+                    "type": "string"
+                },
+                "isError": {
+                    "type": "boolean"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "resultSize": {
+                    "description": "ResultSize is the size of the JSON result, in bytes.",
+                    "type": "integer"
+                },
+                "storageDuration": {
+                    "description": "StorageDuration is the time spent counting the request against the quotas and budgets.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                },
+                "tool": {
+                    "type": "string"
+                },
+                "upstreamDuration": {
+                    "description": "UpstreamDuration is the time spent waiting for the upstream server.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/time.Duration"
+                        }
+                    ]
+                }
+            }
+        },
+        "storage.ToolCostConfig": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "integer"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "tool": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.ToolPolicyConfig": {
+            "type": "object",
+            "properties": {
+                "proxy": {
+                    "type": "string"
+                },
+                "requiresApproval": {
+                    "type": "boolean"
+                },
+                "tool": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.ToolUsage": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer"
+                },
+                "errorRate": {
+                    "type": "number"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "tool": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.UsageStats": {
+            "type": "object",
+            "properties": {
+                "errorCalls": {
+                    "type": "integer"
+                },
+                "errorRate": {
+                    "type": "number"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "topIdentities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.IdentityUsage"
+                    }
+                },
+                "topTools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.ToolUsage"
+                    }
+                },
+                "totalCalls": {
+                    "type": "integer"
+                }
+            }
+        },
         "time.Duration": {
             "type": "integer",
+            "format": "int64",
             "enum": [
                 -9223372036854775808,
                 9223372036854775807,